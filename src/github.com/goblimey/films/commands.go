@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+
+	userModel "github.com/goblimey/films/models/user"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	"github.com/goblimey/films/utilities/bulk"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/migrations"
+	"golang.org/x/term"
+)
+
+// The commands that can be given after the settings on the command line, instead
+// of running the server.  Each returns the exit status - 0 for success, 1 if the
+// command failed and 2 if it was used wrongly.
+var commands = map[string]func(args []string) int{
+	"import":  importCommand,
+	"export":  exportCommand,
+	"migrate": migrateCommand,
+	"user":    userCommand,
+}
+
+// runCommand runs the command named by the first argument, passing it the rest,
+// and returns its exit status.
+func runCommand(args []string) int {
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %s - expected import, export, migrate or user\n", args[0])
+		return 2
+	}
+	err := settings.ValidateDatabase()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 2
+	}
+	err = setUpLog()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 2
+	}
+	return command(args[1:])
+}
+
+// importCommand handles "films import [-format csv|jsonl] [-dry-run]
+// [-all-or-nothing] file" - import people from the file, or from the standard
+// input if the file is "-", and print a report on the rows.  The exit status is
+// 1 if any row could not be imported.
+func importCommand(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "csv or jsonl - by default, taken from the file name")
+	dryRun := flags.Bool("dry-run", false, "check the rows but don't create anyone")
+	allOrNothing := flags.Bool("all-or-nothing", false,
+		"create everyone in one transaction, and only if every row is valid")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: films [settings] import [flags] file")
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	fileName := flags.Arg(0)
+	if *format == "" {
+		*format, err = bulk.FormatFromName(fileName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 2
+		}
+	}
+
+	var reader io.Reader = os.Stdin
+	if fileName != "-" {
+		file, err := os.Open(fileName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	session, err := dbsession.MakeDBSession(settings.Dialect, settings.DSN)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open the %s database - %s\n", settings.Dialect, err.Error())
+		return 1
+	}
+	defer session.Close()
+
+	options := bulk.Options{DryRun: *dryRun, AllOrNothing: *allOrNothing}
+	repo := peopleRepo.MakeRepo(session).WithUser(commandUser())
+	report, err := bulk.ImportPeople(reader, *format, repo, options)
+	if report != nil {
+		fmt.Print(report.String())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	if !report.OK() {
+		if *allOrNothing && !*dryRun {
+			fmt.Println("nobody was imported")
+		}
+		return 1
+	}
+	return 0
+}
+
+// exportCommand handles "films export [-format csv|jsonl] [file]" - write all
+// of the valid people to the file, or to the standard output if there is no file.
+func exportCommand(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "", "csv or jsonl - by default, taken from the file name, or csv")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: films [settings] export [flags] [file]")
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	var writer io.Writer = os.Stdout
+	if flags.NArg() == 1 {
+		fileName := flags.Arg(0)
+		if *format == "" {
+			*format, err = bulk.FormatFromName(fileName)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				return 2
+			}
+		}
+		file, err := os.Create(fileName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		defer file.Close()
+		writer = file
+	}
+	if *format == "" {
+		*format = bulk.FormatCSV
+	}
+
+	session, err := dbsession.MakeDBSession(settings.Dialect, settings.DSN)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open the %s database - %s\n", settings.Dialect, err.Error())
+		return 1
+	}
+	defer session.Close()
+
+	err = bulk.ExportPeople(writer, *format, peopleRepo.MakeRepo(session))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+// migrateCommand handles "films migrate up|down|status" - apply the pending
+// migrations to the database, undo the last one applied, or list them all,
+// showing which have been applied.
+func migrateCommand(args []string) int {
+	if len(args) != 1 || (args[0] != "up" && args[0] != "down" && args[0] != "status") {
+		fmt.Fprintln(os.Stderr, "usage: films [settings] migrate up|down|status")
+		return 2
+	}
+
+	migrator, err := dbsession.MakeMigrator(settings.Dialect, settings.DSN)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot migrate the %s database - %s\n", settings.Dialect, err.Error())
+		return 1
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up()
+		for _, migration := range done {
+			fmt.Printf("applied %d %s\n", migration.ID, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		if len(done) == 0 {
+			fmt.Println("the schema is up to date")
+		}
+	case "down":
+		migration, err := migrator.Down()
+		if err == migrations.ErrNothingToUndo {
+			fmt.Println(err.Error())
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		fmt.Printf("undid %d %s\n", migration.ID, migration.Name)
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		pending := 0
+		for _, s := range status {
+			applied := "pending"
+			if s.Applied() {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			} else {
+				pending++
+			}
+			fmt.Printf("%3d %-28s %s\n", s.Migration.ID, s.Migration.Name, applied)
+		}
+		version, err := migrator.Version()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		fmt.Printf("schema version %d, %d pending\n", version, pending)
+	}
+	return 0
+}
+
+// userUsage is the usage message of the user command.
+const userUsage = `usage: films [settings] user add [-role viewer|editor|admin] username
+       films [settings] user role username viewer|editor|admin`
+
+// userCommand handles "films user add [-role role] username" - create a user
+// who can log in - and "films user role username role" - change what a user
+// can do.  A new user is a viewer unless the -role flag says otherwise.  The
+// password is read from the terminal, or if the standard input is not a
+// terminal, from its first line.
+func userCommand(args []string) int {
+	if len(args) == 0 || (args[0] != "add" && args[0] != "role") {
+		fmt.Fprintln(os.Stderr, userUsage)
+		return 2
+	}
+	if settings.Dialect == dbsession.DialectMemory {
+		fmt.Fprintln(os.Stderr, "cannot change the users in the memory database - it's lost when the command ends")
+		return 2
+	}
+	if args[0] == "role" {
+		return userRoleCommand(args[1:])
+	}
+
+	flags := flag.NewFlagSet("user add", flag.ContinueOnError)
+	role := flags.String("role", userModel.RoleViewer, "viewer, editor or admin")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), userUsage)
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args[1:])
+	if err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	username := flags.Arg(0)
+	if !userModel.ValidRole(*role) {
+		fmt.Fprintf(os.Stderr, "there is no role %s - expected viewer, editor or admin\n", *role)
+		return 2
+	}
+
+	password, err := readPassword(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	session, err := dbsession.MakeDBSession(settings.Dialect, settings.DSN)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open the %s database - %s\n", settings.Dialect, err.Error())
+		return 1
+	}
+	defer session.Close()
+
+	added, err := usersRepo.MakeRepo(session).Add(username, password, *role)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot add user %s - %s\n", username, err.Error())
+		return 1
+	}
+	fmt.Printf("added user %s with role %s\n", added.Username(), added.Role())
+	return 0
+}
+
+// userRoleCommand handles "films user role username role" - give the user a
+// different role.
+func userRoleCommand(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, userUsage)
+		return 2
+	}
+	username, role := args[0], args[1]
+	if !userModel.ValidRole(role) {
+		fmt.Fprintf(os.Stderr, "there is no role %s - expected viewer, editor or admin\n", role)
+		return 2
+	}
+
+	session, err := dbsession.MakeDBSession(settings.Dialect, settings.DSN)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open the %s database - %s\n", settings.Dialect, err.Error())
+		return 1
+	}
+	defer session.Close()
+
+	changed, err := usersRepo.MakeRepo(session).SetRole(username, role)
+	if err == sql.ErrNoRows {
+		fmt.Fprintf(os.Stderr, "there is no user %s\n", username)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot change the role of user %s - %s\n", username, err.Error())
+		return 1
+	}
+	fmt.Printf("user %s now has role %s\n", changed.Username(), changed.Role())
+	return 0
+}
+
+// readPassword reads a new password.  If the file is a terminal, it prompts for
+// the password twice without echoing it, and checks that both are the same.
+// Otherwise it reads the first line, so that a script can supply the password.
+func readPassword(file *os.File) (string, error) {
+	fd := int(file.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(file).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "password again: ")
+	again, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(password) != string(again) {
+		return "", errors.New("the passwords are not the same")
+	}
+	return string(password), nil
+}
+
+// commandUser returns the name recorded in the audit log for changes made by a
+// command - the login name of the user running it.
+func commandUser() string {
+	current, err := user.Current()
+	if err != nil {
+		return "command line"
+	}
+	return current.Username
+}
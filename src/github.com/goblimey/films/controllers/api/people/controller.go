@@ -0,0 +1,714 @@
+// Package people provides the JSON API for the people resource.  It offers the
+// same Create, Read, Update and Delete (CRUD) operations as the HTML controller,
+// using the same repository, but it takes and returns JSON so that scripts can
+// manage the data without scraping web pages:
+//
+//    GET /api/v1/people - runs Index() to list the valid people, a page at a time
+//    POST /api/v1/people - runs Create() to create a person
+//    GET /api/v1/people/n - runs Show() to fetch the person with ID n
+//    PUT /api/v1/people/n - runs Update() to replace the person with ID n
+//    PATCH /api/v1/people/n - runs Patch() to change some fields of the person with ID n
+//    DELETE /api/v1/people/n - runs Delete() to move the person with ID n to the trash
+//    POST /api/v1/people/import - runs Import() to create people from CSV or JSON Lines
+//    GET /api/v1/people/export - runs Export() to fetch all the people as CSV or JSON Lines
+//    GET /api/v1/people/n/history - runs History() to fetch the changes made to the person with ID n
+//    POST /api/v1/people/n/history/e/revert - runs Revert() to restore the values in history entry e
+//
+// A person looks like this:
+//
+//    {"id": 1, "forename": "Orson", "surname": "Welles"}
+//
+// A request that changes the data must carry the session cookie given by logging
+// in at /login, otherwise it fails with status 401.  If the user's role doesn't
+// allow the change - an editor can create and change people and an admin can
+// also delete them - it fails with status 403.
+//
+// Errors are returned with a suitable status and a body like this:
+//
+//    {"error": "no person with ID 42"}
+//
+// If the data is invalid the status is 422 and the body also contains an error
+// message for each bad field:
+//
+//    {"error": "invalid person", "fieldErrors": {"surname": "you must specify the Surname"}}
+//
+// A history entry looks like this:
+//
+//    {"id": 7, "action": "update", "version": 2, "changedBy": "admin",
+//     "changedAt": "2024-05-01T12:00:00Z", "before": {"forename": "Orsen", "surname": "Welles"},
+//     "after": {"forename": "Orson", "surname": "Welles"}}
+//
+// The responses that contain a person have an ETag header holding the version of
+// the stored record, for example "3".  A client can send that back in an If-Match
+// header with a PUT, PATCH or DELETE.  If the person has been changed since, the
+// request fails with status 412 (Precondition Failed) and nothing is changed.
+// Without If-Match, a request that clashes with a simultaneous change fails with
+// status 409 (Conflict).
+package people
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/people"
+	auditModel "github.com/goblimey/films/models/audit"
+	personModel "github.com/goblimey/films/models/person"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/bulk"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// RootPath is the URI of the people collection in the API.
+const RootPath = "/api/v1/people"
+
+// The content types of the formats for importing and exporting people.
+const (
+	MIMECSV   = "text/csv"
+	MIMEJSONL = "application/x-ndjson"
+)
+
+// Person is the JSON representation of a person.
+type Person struct {
+	ID       uint64 `json:"id"`
+	Forename string `json:"forename"`
+	Surname  string `json:"surname"`
+}
+
+// PersonPatch is the body of a PATCH request.  A field that's missing from the
+// request is nil and the stored value is left as it is.
+type PersonPatch struct {
+	Forename *string `json:"forename"`
+	Surname  *string `json:"surname"`
+}
+
+// HistoryEntry is the JSON representation of an entry in the audit log.  Before is
+// missing for a create and After is missing for a delete.
+type HistoryEntry struct {
+	ID        uint64            `json:"id"`
+	Action    string            `json:"action"`
+	Version   int64             `json:"version"`
+	ChangedBy string            `json:"changedBy"`
+	ChangedAt time.Time         `json:"changedAt"`
+	Before    map[string]string `json:"before,omitempty"`
+	After     map[string]string `json:"after,omitempty"`
+}
+
+// ErrorResponse is the body of a response that reports an error.  FieldErrors
+// is only present when the data in the request is invalid.
+type ErrorResponse struct {
+	Error       string            `json:"error"`
+	FieldErrors map[string]string `json:"fieldErrors,omitempty"`
+}
+
+// Controller handles the API requests.
+type Controller struct {
+	services services.Services
+}
+
+// MakeController is a factory that creates a people API controller.
+func MakeController(services services.Services) Controller {
+	var controller Controller
+	controller.SetServices(services)
+	return controller
+}
+
+// MakeWebService creates the web service that routes the API requests to a
+// controller.  servicesFilter attaches the services to each request - see
+// services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON).
+		Filter(servicesFilter)
+
+	ws.Route(ws.GET("").To(handle(Controller.Index)))
+	ws.Route(ws.POST("").To(handle(Controller.Create)))
+	ws.Route(ws.POST("/import").Consumes(MIMECSV, MIMEJSONL).To(handle(Controller.Import)))
+	ws.Route(ws.GET("/export").Produces(MIMECSV, MIMEJSONL).To(handle(Controller.Export)))
+	ws.Route(ws.GET("/{id}").To(handle(Controller.Show)))
+	ws.Route(ws.PUT("/{id}").To(handle(Controller.Update)))
+	ws.Route(ws.PATCH("/{id}").To(handle(Controller.Patch)))
+	ws.Route(ws.DELETE("/{id}").To(handle(Controller.Delete)))
+	ws.Route(ws.GET("/{id}/history").To(handle(Controller.History)))
+	// A revert has no body, so the request needn't say what type it is.
+	ws.Route(ws.POST("/{id}/history/{entryID}/revert").
+		AllowedMethodsWithoutContentType([]string{"POST"}).To(handle(Controller.Revert)))
+	return ws
+}
+
+// ServiceUnavailable sends the response for a request that can't be handled
+// because the services are not available.
+func ServiceUnavailable(resp *restful.Response) {
+	writeError(resp, http.StatusServiceUnavailable, "the database is not available")
+}
+
+// Unauthorised sends the response for a request that changes the data when
+// nobody is logged in.
+func Unauthorised(req *restful.Request, resp *restful.Response) {
+	writeError(resp, http.StatusUnauthorized, "you must log in to make changes")
+}
+
+// allowed returns true if the viewer can do what they asked, according to
+// permitted, which is one of the Can methods of auth.Viewer.  If not, it sends
+// a 403 response.
+func allowed(req *restful.Request, resp *restful.Response,
+	permitted func(auth.Viewer) bool, what string) bool {
+
+	logger := logging.FromRequest(req.Request)
+	viewer := auth.ViewerFrom(req.Request)
+	if permitted(viewer) {
+		return true
+	}
+	em := viewer.Refusal(what)
+	logger.Error(em)
+	writeError(resp, http.StatusForbidden, em)
+	return false
+}
+
+// handle returns a route function that makes a controller using the services
+// attached to the request and passes the request to the given controller method.
+func handle(method func(Controller, *restful.Request, *restful.Response)) restful.RouteFunction {
+	return func(req *restful.Request, resp *restful.Response) {
+		method(MakeController(services.FromRequest(req)), req, resp)
+	}
+}
+
+// Index responds to GET /api/v1/people with a page of valid people.  It takes the
+// same parameters as the HTML index page, for example "?page=2&sort=forename".
+// The number of people on all pages is in the X-Total-Count header and the
+// links to the next and previous pages are in the Link header.
+func (c Controller) Index(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	query := forms.ParseListQuery(req.Request.URL.Query())
+	people, total, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindPage(query.PeopleQuery())
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of people - %s", err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusInternalServerError, em)
+		return
+	}
+
+	list := make([]Person, 0, len(people))
+	for _, person := range people {
+		list = append(list, toJSON(person))
+	}
+
+	// Work out the links from a list form, as the HTML page does.
+	var form forms.ConcreteListForm
+	form.SetQuery(query)
+	form.SetTotal(total)
+	links := make([]string, 0, 2)
+	if form.PreviousLink() != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"prev\"", apiLink(form.PreviousLink())))
+	}
+	if form.NextLink() != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"next\"", apiLink(form.NextLink())))
+	}
+	if len(links) > 0 {
+		resp.AddHeader("Link", strings.Join(links, ", "))
+	}
+	resp.AddHeader("X-Total-Count", strconv.FormatInt(total, 10))
+	resp.WriteHeaderAndJson(http.StatusOK, list, restful.MIME_JSON)
+}
+
+// Import responds to POST /api/v1/people/import by importing the people in the
+// body, which is CSV or JSON Lines according to the Content-Type or the format
+// parameter.  The parameters dryRun=true and allOrNothing=true set the options -
+// see bulk.Options.  The response is a report on the rows.  The status is 422 if
+// an all-or-nothing import was rejected because of invalid rows, 400 if the body
+// can't be read and 200 otherwise.
+func (c Controller) Import(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !allowed(req, resp, auth.Viewer.CanEdit, "import people") {
+		return
+	}
+
+	format := req.QueryParameter("format")
+	if format == "" {
+		format = bulk.FormatCSV
+		if strings.HasPrefix(req.HeaderParameter("Content-Type"), MIMEJSONL) {
+			format = bulk.FormatJSONL
+		}
+	}
+	options := bulk.Options{
+		DryRun:       req.QueryParameter("dryRun") == "true",
+		AllOrNothing: req.QueryParameter("allOrNothing") == "true",
+	}
+	report, err := bulk.ImportPeople(req.Request.Body, format,
+		c.repository(req), options)
+	if report == nil {
+		logger.Error(err.Error())
+		writeError(resp, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error(err.Error())
+		writeError(resp, http.StatusInternalServerError, err.Error())
+		return
+	}
+	status := http.StatusOK
+	if options.AllOrNothing && !report.OK() {
+		status = http.StatusUnprocessableEntity
+	}
+	resp.WriteHeaderAndJson(status, report, restful.MIME_JSON)
+}
+
+// Export responds to GET /api/v1/people/export with all of the valid people as a
+// CSV file, or as JSON Lines if the format parameter is "jsonl".
+func (c Controller) Export(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	format := req.QueryParameter("format")
+	contentType := MIMECSV
+	switch format {
+	case "", bulk.FormatCSV:
+		format = bulk.FormatCSV
+	case bulk.FormatJSONL:
+		contentType = MIMEJSONL
+	default:
+		writeError(resp, http.StatusBadRequest,
+			fmt.Sprintf("the format must be %s or %s", bulk.FormatCSV, bulk.FormatJSONL))
+		return
+	}
+
+	// Write to a buffer first, so that an error can still be reported.
+	var buffer bytes.Buffer
+	err := bulk.ExportPeople(&buffer, format, c.services.GetPeopleRepository().WithContext(req.Request.Context()))
+	if err != nil {
+		logger.Error(err.Error())
+		writeError(resp, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp.AddHeader("Content-Type", contentType)
+	resp.AddHeader("Content-Disposition", fmt.Sprintf("attachment; filename=\"people.%s\"", format))
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(buffer.Bytes())
+}
+
+// Show responds to GET /api/v1/people/n with the person with ID n.
+func (c Controller) Show(req *restful.Request, resp *restful.Response) {
+
+	person, ok := c.findPerson(req, resp)
+	if !ok {
+		return
+	}
+	resp.AddHeader("ETag", etag(person))
+	resp.WriteHeaderAndJson(http.StatusOK, toJSON(person), restful.MIME_JSON)
+}
+
+// Create responds to POST /api/v1/people.  It creates a person from the JSON in
+// the body and returns it with status 201 and its URI in the Location header.
+// Any ID in the body is ignored.
+func (c Controller) Create(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !allowed(req, resp, auth.Viewer.CanEdit, "create people") {
+		return
+	}
+
+	var body Person
+	err := req.ReadEntity(&body)
+	if err != nil {
+		em := fmt.Sprintf("cannot read the person - %s", err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusBadRequest, em)
+		return
+	}
+
+	person := gorpPersonModel.MakeInitialisedPerson(0, body.Forename, body.Surname)
+	if !validate(req, resp, person) {
+		return
+	}
+
+	created, err := c.repository(req).Create(person)
+	if err != nil {
+		em := fmt.Sprintf("could not create person %s - %s", person.String(), err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusInternalServerError, em)
+		return
+	}
+
+	logger.Info("created new person", "person", created.String())
+	resp.AddHeader("Location", fmt.Sprintf("%s/%d", RootPath, created.ID()))
+	resp.AddHeader("ETag", etag(created))
+	resp.WriteHeaderAndJson(http.StatusCreated, toJSON(created), restful.MIME_JSON)
+}
+
+// Update responds to PUT /api/v1/people/n.  It replaces the data of the person
+// with ID n with the JSON in the body.  Any ID in the body is ignored.  If the
+// request has an If-Match header, it must match the person's current ETag.
+func (c Controller) Update(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !allowed(req, resp, auth.Viewer.CanEdit, "edit people") {
+		return
+	}
+
+	person, ok := c.findPerson(req, resp)
+	if !ok || !checkIfMatch(req, resp, person) {
+		return
+	}
+
+	var body Person
+	err := req.ReadEntity(&body)
+	if err != nil {
+		em := fmt.Sprintf("cannot read the person - %s", err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusBadRequest, em)
+		return
+	}
+
+	person.SetForename(body.Forename)
+	person.SetSurname(body.Surname)
+	c.save(req, resp, person)
+}
+
+// Patch responds to PATCH /api/v1/people/n.  It changes the fields of the person
+// with ID n that are given in the JSON in the body and leaves the others alone.
+// Any If-Match header is checked as for Update.
+func (c Controller) Patch(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !allowed(req, resp, auth.Viewer.CanEdit, "edit people") {
+		return
+	}
+
+	person, ok := c.findPerson(req, resp)
+	if !ok || !checkIfMatch(req, resp, person) {
+		return
+	}
+
+	var body PersonPatch
+	err := req.ReadEntity(&body)
+	if err != nil {
+		em := fmt.Sprintf("cannot read the changes - %s", err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusBadRequest, em)
+		return
+	}
+
+	if body.Forename != nil {
+		person.SetForename(*body.Forename)
+	}
+	if body.Surname != nil {
+		person.SetSurname(*body.Surname)
+	}
+	c.save(req, resp, person)
+}
+
+// Delete responds to DELETE /api/v1/people/n.  It moves the person with ID n to the
+// trash, where they can be restored from the people pages, and returns status 204
+// with no body.  Any If-Match header is checked as for Update.
+func (c Controller) Delete(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !allowed(req, resp, auth.Viewer.CanDelete, "delete people") {
+		return
+	}
+
+	person, ok := c.findPerson(req, resp)
+	if !ok || !checkIfMatch(req, resp, person) {
+		return
+	}
+
+	_, err := c.repository(req).DeleteByID(person.ID())
+	if err == peopleRepo.ErrConflict {
+		writeConflict(req, resp, person)
+		return
+	}
+	if err != nil {
+		em := fmt.Sprintf("cannot delete person with ID %d - %s", person.ID(), err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusInternalServerError, em)
+		return
+	}
+
+	logger.Info("moved person to the trash", "id", person.ID())
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// History responds to GET /api/v1/people/n/history with the changes made to the
+// person with ID n, newest first.  The history of a deleted person is still
+// available.  If there's no such person and no history, the status is 404.
+func (c Controller) History(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	idStr := req.PathParameter("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		writeError(resp, http.StatusNotFound, fmt.Sprintf("no person with ID %s", idStr))
+		return
+	}
+	repo := c.services.GetPeopleRepository().WithContext(req.Request.Context())
+	entries, err := repo.History(id)
+	if err != nil {
+		em := fmt.Sprintf("error getting the history of person %d - %s", id, err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusInternalServerError, em)
+		return
+	}
+	if len(entries) == 0 {
+		_, ok := c.findPerson(req, resp)
+		if !ok {
+			return
+		}
+	}
+
+	body := make([]HistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		body = append(body, historyJSON(entry))
+	}
+	resp.WriteHeaderAndJson(http.StatusOK, body, restful.MIME_JSON)
+}
+
+// Revert responds to POST /api/v1/people/n/history/e/revert.  It restores the
+// person with ID n to the values after the change recorded by history entry e
+// and sends back the person.  Any If-Match header is checked as for Update.
+func (c Controller) Revert(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !allowed(req, resp, auth.Viewer.CanEdit, "revert people") {
+		return
+	}
+
+	person, ok := c.findPerson(req, resp)
+	if !ok || !checkIfMatch(req, resp, person) {
+		return
+	}
+	entryStr := req.PathParameter("entryID")
+	entryID, err := strconv.ParseUint(entryStr, 10, 64)
+	if err != nil {
+		writeError(resp, http.StatusNotFound, fmt.Sprintf("no history entry %s", entryStr))
+		return
+	}
+
+	reverted, err := c.repository(req).Revert(person.ID(), entryID, person.Version())
+	switch err {
+	case nil:
+	case peopleRepo.ErrConflict:
+		writeConflict(req, resp, person)
+		return
+	case peopleRepo.ErrNoSuchEntry:
+		writeError(resp, http.StatusNotFound,
+			fmt.Sprintf("person %d has no history entry %d", person.ID(), entryID))
+		return
+	case peopleRepo.ErrCannotRevert:
+		writeError(resp, http.StatusUnprocessableEntity, err.Error())
+		return
+	default:
+		em := fmt.Sprintf("could not revert person - %s", err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusInternalServerError, em)
+		return
+	}
+
+	logger.Info("reverted person", "person", reverted.String())
+	resp.AddHeader("ETag", etag(reverted))
+	resp.WriteHeaderAndJson(http.StatusOK, toJSON(reverted), restful.MIME_JSON)
+}
+
+// repository returns the people repository, set up to record the maker of the
+// request in the audit log.
+func (c Controller) repository(req *restful.Request) peopleRepo.Repository {
+	return c.services.GetPeopleRepository().WithContext(req.Request.Context()).WithUser(utilities.Requester(req.Request))
+}
+
+// SetServices sets the services.
+func (c *Controller) SetServices(services services.Services) {
+	c.services = services
+}
+
+// findPerson fetches the person with the ID given in the URI.  If the ID is not a
+// number or there is no such person, it sends a 404 response.  If the lookup
+// fails for any other reason, it sends a 500 response.  It returns false if it
+// has sent a response.
+func (c Controller) findPerson(req *restful.Request,
+	resp *restful.Response) (personModel.Person, bool) {
+
+	logger := logging.FromRequest(req.Request)
+	idStr := req.PathParameter("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("no person with ID %s", idStr)
+		logger.Error(em)
+		writeError(resp, http.StatusNotFound, em)
+		return nil, false
+	}
+
+	person, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindByID(id)
+	if err == sql.ErrNoRows {
+		em := fmt.Sprintf("no person with ID %d", id)
+		logger.Error(em)
+		writeError(resp, http.StatusNotFound, em)
+		return nil, false
+	}
+	if err != nil {
+		em := fmt.Sprintf("error searching for person with ID %d - %s", id, err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusInternalServerError, em)
+		return nil, false
+	}
+	return person, true
+}
+
+// save validates the changed person, updates the database and sends the
+// person back.
+func (c Controller) save(req *restful.Request, resp *restful.Response,
+	person personModel.Person) {
+
+	logger := logging.FromRequest(req.Request)
+	if !validate(req, resp, person) {
+		return
+	}
+
+	_, err := c.repository(req).Update(person)
+	if err == peopleRepo.ErrConflict {
+		writeConflict(req, resp, person)
+		return
+	}
+	if err != nil {
+		em := fmt.Sprintf("could not update person - %s", err.Error())
+		logger.Error(em)
+		writeError(resp, http.StatusInternalServerError, em)
+		return
+	}
+
+	logger.Info("updated person", "person", person.String())
+	resp.AddHeader("ETag", etag(person))
+	resp.WriteHeaderAndJson(http.StatusOK, toJSON(person), restful.MIME_JSON)
+}
+
+// checkIfMatch checks the If-Match header of the request, if there is one,
+// against the ETag of the person as stored.  If none of the given tags match, it
+// sends a 412 response with the current ETag and returns false.
+func checkIfMatch(req *restful.Request, resp *restful.Response,
+	person personModel.Person) bool {
+
+	logger := logging.FromRequest(req.Request)
+	header := req.HeaderParameter("If-Match")
+	if header == "" {
+		return true
+	}
+	current := etag(person)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	em := fmt.Sprintf("person with ID %d has been changed - the current ETag is %s",
+		person.ID(), current)
+	logger.Error(em)
+	resp.AddHeader("ETag", current)
+	writeError(resp, http.StatusPreconditionFailed, em)
+	return false
+}
+
+// writeConflict sends the response for an update or delete that the repository
+// refused because someone else changed the person at the same time.  If the
+// client gave an If-Match header, the precondition has failed, otherwise the
+// request conflicts with the other change.
+func writeConflict(req *restful.Request, resp *restful.Response,
+	person personModel.Person) {
+
+	logger := logging.FromRequest(req.Request)
+	status := http.StatusConflict
+	if req.HeaderParameter("If-Match") != "" {
+		status = http.StatusPreconditionFailed
+	}
+	em := fmt.Sprintf("person with ID %d was changed by someone else - fetch it and try again",
+		person.ID())
+	logger.Error(em)
+	writeError(resp, status, em)
+}
+
+// validate checks the person using the same rules as the HTML forms.  If the
+// person is invalid, it sends a 422 response with an error for each bad field,
+// named as in the JSON, and returns false.
+func validate(req *restful.Request, resp *restful.Response, person personModel.Person) bool {
+	var form forms.ConcretePersonForm
+	form.SetPerson(person)
+	if form.Validate() {
+		return true
+	}
+
+	fieldErrors := make(map[string]string)
+	for field, message := range form.FieldErrors() {
+		fieldErrors[jsonName(field)] = message
+	}
+	logging.FromRequest(req.Request).Info("invalid person", "person", person.String())
+	resp.WriteHeaderAndJson(http.StatusUnprocessableEntity,
+		ErrorResponse{Error: "invalid person", FieldErrors: fieldErrors},
+		restful.MIME_JSON)
+	return false
+}
+
+// jsonName converts the name of a field in the form ("Forename") to its name in
+// the JSON ("forename").
+func jsonName(field string) string {
+	if field == "" {
+		return field
+	}
+	return strings.ToLower(field[:1]) + field[1:]
+}
+
+// apiLink converts a link to a page of the HTML index to the same page of the API.
+func apiLink(link string) string {
+	return RootPath + strings.TrimPrefix(link, "/people")
+}
+
+// etag returns the entity tag of the person, which is the version of the stored
+// record in quotes.
+func etag(person personModel.Person) string {
+	return strconv.Quote(strconv.FormatInt(person.Version(), 10))
+}
+
+// toJSON converts a person to its JSON representation.
+func toJSON(person personModel.Person) Person {
+	return Person{ID: person.ID(), Forename: person.Forename(), Surname: person.Surname()}
+}
+
+// historyJSON converts an audit log entry to its JSON representation.
+func historyJSON(entry auditModel.Entry) HistoryEntry {
+	history := HistoryEntry{
+		ID:        entry.ID(),
+		Action:    entry.Action(),
+		Version:   entry.Version(),
+		ChangedBy: entry.ChangedBy(),
+		ChangedAt: entry.ChangedAt(),
+	}
+	if entry.Before() != "" {
+		history.Before = auditModel.DecodeValues(entry.Before())
+	}
+	if entry.After() != "" {
+		history.After = auditModel.DecodeValues(entry.After())
+	}
+	return history
+}
+
+// writeError sends an error response with the given status.
+func writeError(resp *restful.Response, status int, message string) {
+	resp.WriteHeaderAndJson(status, ErrorResponse{Error: message}, restful.MIME_JSON)
+}
@@ -0,0 +1,374 @@
+package people
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	restful "github.com/emicklei/go-restful"
+	userModel "github.com/goblimey/films/models/user"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// makeContainer creates a container holding the API web service, backed by the
+// in-memory repository.  Every request is made by an administrator.
+func makeContainer() *restful.Container {
+	return makeContainerFor(auth.Viewer{Username: "admin", Role: userModel.RoleAdmin})
+}
+
+// makeContainerFor creates a container like makeContainer's, where every request
+// is made by the given viewer.
+func makeContainerFor(viewer auth.Viewer) *restful.Container {
+	var svc services.ConcreteServices
+	svc.SetPeopleRepository(peopleRepo.MakeMemoryRepo())
+	getServices := func() (services.Services, error) {
+		return &svc, nil
+	}
+	viewerFilter := func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		req.Request = auth.WithViewer(req.Request, viewer)
+		chain.ProcessFilter(req, resp)
+	}
+	container := restful.NewContainer()
+	container.Add(MakeWebService(services.Filter(getServices, ServiceUnavailable)).Filter(viewerFilter))
+	return container
+}
+
+// send sends a request with an optional JSON body to the container and returns
+// the recorded response.
+func send(container *restful.Container, method, uri, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, uri, strings.NewReader(body))
+	if body != "" {
+		request.Header.Set("Content-Type", restful.MIME_JSON)
+	}
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// TestUnitCreateAndShow checks that a POST creates a person and returns 201 with
+// the URI of the new person, and that a GET of that URI fetches it back.
+func TestUnitCreateAndShow(t *testing.T) {
+	container := makeContainer()
+
+	recorder := send(container, "POST", "/api/v1/people",
+		`{"forename": " Orson ", "surname": "Welles"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d - %s", recorder.Code, recorder.Body.String())
+	}
+	location := recorder.Header().Get("Location")
+	if location != "/api/v1/people/1" {
+		t.Errorf("Expected location /api/v1/people/1, got %s", location)
+	}
+
+	recorder = send(container, "GET", location, "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	var person Person
+	err := json.Unmarshal(recorder.Body.Bytes(), &person)
+	if err != nil {
+		t.Fatalf("cannot parse the response - %s", err.Error())
+	}
+	if person.ID != 1 || person.Forename != "Orson" || person.Surname != "Welles" {
+		t.Errorf("Expected person 1 Orson Welles, got %v", person)
+	}
+}
+
+// TestUnitCreateInvalid checks that a POST with missing fields returns 422 with
+// an error for each of them.
+func TestUnitCreateInvalid(t *testing.T) {
+	container := makeContainer()
+
+	recorder := send(container, "POST", "/api/v1/people", `{"surname": "  "}`)
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", recorder.Code)
+	}
+	var body ErrorResponse
+	json.Unmarshal(recorder.Body.Bytes(), &body)
+	if body.FieldErrors["forename"] == "" || body.FieldErrors["surname"] == "" {
+		t.Errorf("Expected errors for forename and surname, got %v", body.FieldErrors)
+	}
+
+	recorder = send(container, "GET", "/api/v1/people", "")
+	if strings.TrimSpace(recorder.Body.String()) != "[]" {
+		t.Errorf("Expected no people, got %s", recorder.Body.String())
+	}
+}
+
+// TestUnitPatchPutAndDelete checks that PATCH changes just the given fields, PUT
+// replaces them all and DELETE removes the person.
+func TestUnitPatchPutAndDelete(t *testing.T) {
+	container := makeContainer()
+	send(container, "POST", "/api/v1/people", `{"forename": "Joseph", "surname": "Cotten"}`)
+
+	recorder := send(container, "PATCH", "/api/v1/people/1", `{"forename": "Joe"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from PATCH, got %d", recorder.Code)
+	}
+	var person Person
+	json.Unmarshal(recorder.Body.Bytes(), &person)
+	if person.Forename != "Joe" || person.Surname != "Cotten" {
+		t.Errorf("Expected Joe Cotten, got %v", person)
+	}
+
+	recorder = send(container, "PUT", "/api/v1/people/1", `{"forename": "Alida"}`)
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 from PUT with no surname, got %d", recorder.Code)
+	}
+
+	recorder = send(container, "PUT", "/api/v1/people/1", `{"forename": "Alida", "surname": "Valli"}`)
+	json.Unmarshal(recorder.Body.Bytes(), &person)
+	if recorder.Code != http.StatusOK || person.Surname != "Valli" {
+		t.Errorf("Expected status 200 and Alida Valli from PUT, got %d and %v",
+			recorder.Code, person)
+	}
+
+	recorder = send(container, "DELETE", "/api/v1/people/1", "")
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204 from DELETE, got %d", recorder.Code)
+	}
+
+	recorder = send(container, "GET", "/api/v1/people/1", "")
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after the delete, got %d", recorder.Code)
+	}
+}
+
+// TestUnitETagAndIfMatch checks that the responses carry the version of the person
+// as an ETag and that a request with a stale If-Match header is refused.
+func TestUnitETagAndIfMatch(t *testing.T) {
+	container := makeContainer()
+	recorder := send(container, "POST", "/api/v1/people", `{"forename": "Joseph", "surname": "Cotten"}`)
+	if etag := recorder.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf("Expected ETag \"1\" from POST, got %s", etag)
+	}
+
+	request := httptest.NewRequest("PATCH", "/api/v1/people/1", strings.NewReader(`{"forename": "Joe"}`))
+	request.Header.Set("Content-Type", restful.MIME_JSON)
+	request.Header.Set("If-Match", `"1"`)
+	recorder = httptest.NewRecorder()
+	container.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from PATCH, got %d - %s", recorder.Code, recorder.Body.String())
+	}
+	if etag := recorder.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("Expected ETag \"2\" from PATCH, got %s", etag)
+	}
+
+	// Someone else's copy is now out of date.
+	for _, method := range []string{"PUT", "DELETE"} {
+		request = httptest.NewRequest(method, "/api/v1/people/1",
+			strings.NewReader(`{"forename": "Alida", "surname": "Valli"}`))
+		request.Header.Set("Content-Type", restful.MIME_JSON)
+		request.Header.Set("If-Match", `"1"`)
+		recorder = httptest.NewRecorder()
+		container.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusPreconditionFailed {
+			t.Errorf("Expected status 412 from %s, got %d", method, recorder.Code)
+		}
+		if etag := recorder.Header().Get("ETag"); etag != `"2"` {
+			t.Errorf("Expected the current ETag \"2\" from %s, got %s", method, etag)
+		}
+	}
+
+	recorder = send(container, "GET", "/api/v1/people/1", "")
+	var person Person
+	json.Unmarshal(recorder.Body.Bytes(), &person)
+	if person.Forename != "Joe" || person.Surname != "Cotten" {
+		t.Errorf("Expected Joe Cotten, got %v", person)
+	}
+	if etag := recorder.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("Expected ETag \"2\" from GET, got %s", etag)
+	}
+}
+
+// TestUnitHistoryAndRevert checks that the history lists the changes to a person,
+// newest first, and that a revert restores the values in an entry.
+func TestUnitHistoryAndRevert(t *testing.T) {
+	container := makeContainer()
+	send(container, "POST", "/api/v1/people", `{"forename": "Joseph", "surname": "Cotten"}`)
+	send(container, "PATCH", "/api/v1/people/1", `{"forename": "Joe"}`)
+
+	recorder := send(container, "GET", "/api/v1/people/1/history", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from the history, got %d", recorder.Code)
+	}
+	var history []HistoryEntry
+	json.Unmarshal(recorder.Body.Bytes(), &history)
+	if len(history) != 2 || history[0].Action != "update" || history[1].Action != "create" {
+		t.Fatalf("Expected an update and a create, got %v", history)
+	}
+	if history[0].Before["forename"] != "Joseph" || history[0].After["forename"] != "Joe" {
+		t.Errorf("Expected the forename to change from Joseph to Joe, got %v", history[0])
+	}
+	if history[1].Before != nil {
+		t.Errorf("Expected no values before the create, got %v", history[1].Before)
+	}
+
+	recorder = send(container, "POST", "/api/v1/people/1/history/99/revert", "")
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 reverting to a missing entry, got %d", recorder.Code)
+	}
+
+	uri := fmt.Sprintf("/api/v1/people/1/history/%d/revert", history[1].ID)
+	recorder = send(container, "POST", uri, "")
+	var person Person
+	json.Unmarshal(recorder.Body.Bytes(), &person)
+	if recorder.Code != http.StatusOK || person.Forename != "Joseph" {
+		t.Errorf("Expected status 200 and Joseph from the revert, got %d and %v",
+			recorder.Code, person)
+	}
+
+	send(container, "DELETE", "/api/v1/people/1", "")
+	recorder = send(container, "GET", "/api/v1/people/1/history", "")
+	history = nil
+	json.Unmarshal(recorder.Body.Bytes(), &history)
+	if recorder.Code != http.StatusOK || len(history) != 4 || history[0].Action != "delete" {
+		t.Errorf("Expected the history of the deleted person, got %d and %v",
+			recorder.Code, history)
+	}
+
+	recorder = send(container, "GET", "/api/v1/people/2/history", "")
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for the history of nobody, got %d", recorder.Code)
+	}
+}
+
+// TestUnitIndexPaging checks that the index returns the page asked for, with the
+// total in X-Total-Count and links to the other pages in the Link header.
+func TestUnitIndexPaging(t *testing.T) {
+	container := makeContainer()
+	send(container, "POST", "/api/v1/people", `{"forename": "Orson", "surname": "Welles"}`)
+	send(container, "POST", "/api/v1/people", `{"forename": "Joseph", "surname": "Cotten"}`)
+	send(container, "POST", "/api/v1/people", `{"forename": "Agnes", "surname": "Moorehead"}`)
+
+	recorder := send(container, "GET", "/api/v1/people?page=2&size=1", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	var people []Person
+	json.Unmarshal(recorder.Body.Bytes(), &people)
+	if len(people) != 1 || people[0].Surname != "Moorehead" {
+		t.Errorf("Expected just Moorehead, got %v", people)
+	}
+	if recorder.Header().Get("X-Total-Count") != "3" {
+		t.Errorf("Expected a total of 3, got %s", recorder.Header().Get("X-Total-Count"))
+	}
+	expected := `</api/v1/people?dir=asc&page=1&size=1&sort=surname>; rel="prev", ` +
+		`</api/v1/people?dir=asc&page=3&size=1&sort=surname>; rel="next"`
+	if recorder.Header().Get("Link") != expected {
+		t.Errorf("Expected links %s, got %s", expected, recorder.Header().Get("Link"))
+	}
+}
+
+// TestUnitImportAndExport checks that people can be imported as CSV and exported
+// as JSON Lines, and that /export and /import aren't taken as IDs.
+func TestUnitImportAndExport(t *testing.T) {
+	container := makeContainer()
+
+	csv := "forename,surname\nOrson,Welles\nJoseph,\n"
+	request := httptest.NewRequest("POST", "/api/v1/people/import?allOrNothing=true",
+		strings.NewReader(csv))
+	request.Header.Set("Content-Type", MIMECSV)
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 from the all-or-nothing import, got %d - %s",
+			recorder.Code, recorder.Body.String())
+	}
+
+	request = httptest.NewRequest("POST", "/api/v1/people/import", strings.NewReader(csv))
+	request.Header.Set("Content-Type", MIMECSV)
+	recorder = httptest.NewRecorder()
+	container.ServeHTTP(recorder, request)
+	var report struct {
+		Created   int
+		RowErrors []struct{ Line int }
+	}
+	json.Unmarshal(recorder.Body.Bytes(), &report)
+	if recorder.Code != http.StatusOK || report.Created != 1 || len(report.RowErrors) != 1 ||
+		report.RowErrors[0].Line != 3 {
+
+		t.Errorf("Expected status 200, one person created and an error on line 3, got %d - %s",
+			recorder.Code, recorder.Body.String())
+	}
+
+	recorder = send(container, "GET", "/api/v1/people/export?format=jsonl", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from the export, got %d", recorder.Code)
+	}
+	if recorder.Header().Get("Content-Type") != MIMEJSONL {
+		t.Errorf("Expected content type %s, got %s", MIMEJSONL, recorder.Header().Get("Content-Type"))
+	}
+	expected := `{"id":1,"forename":"Orson","surname":"Welles"}`
+	if strings.TrimSpace(recorder.Body.String()) != expected {
+		t.Errorf("Expected %s, got %s", expected, recorder.Body.String())
+	}
+}
+
+// TestUnitNotFoundAndBadRequest checks the responses to a missing person, a
+// non-numeric ID and a body that is not JSON.
+func TestUnitNotFoundAndBadRequest(t *testing.T) {
+	container := makeContainer()
+
+	for _, uri := range []string{"/api/v1/people/42", "/api/v1/people/junk"} {
+		recorder := send(container, "PATCH", uri, `{}`)
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status 404, got %d", uri, recorder.Code)
+		}
+	}
+
+	recorder := send(container, "POST", "/api/v1/people", `{"forename":`)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", recorder.Code)
+	}
+}
+
+// TestUnitForbidden checks that a viewer can't create a person and an editor
+// can't delete one, and that each gets a 403 response.
+func TestUnitForbidden(t *testing.T) {
+	viewer := makeContainerFor(auth.Viewer{Username: "vera", Role: userModel.RoleViewer})
+	recorder := send(viewer, "POST", "/api/v1/people", `{"forename":"Anna","surname":"Schmidt"}`)
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("viewer create: expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+	recorder = send(viewer, "GET", "/api/v1/people", "")
+	if recorder.Code != http.StatusOK {
+		t.Errorf("viewer index: expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	editor := makeContainerFor(auth.Viewer{Username: "rita", Role: userModel.RoleEditor})
+	recorder = send(editor, "POST", "/api/v1/people", `{"forename":"Anna","surname":"Schmidt"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("editor create: expected status %d, got %d", http.StatusCreated, recorder.Code)
+	}
+	uri := recorder.Header().Get("Location")
+	recorder = send(editor, "DELETE", uri, "")
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("editor delete: expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+	if !strings.Contains(recorder.Body.String(), "rita is an editor") {
+		t.Errorf("expected the refusal in the body, got %s", recorder.Body.String())
+	}
+}
+
+// TestUnitDatabaseUnavailable checks that the API returns 503 if the services
+// can't be created.
+func TestUnitDatabaseUnavailable(t *testing.T) {
+	getServices := func() (services.Services, error) {
+		return nil, errors.New("connection refused")
+	}
+	container := restful.NewContainer()
+	container.Add(MakeWebService(services.Filter(getServices, ServiceUnavailable)))
+
+	recorder := send(container, "GET", "/api/v1/people", "")
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,84 @@
+// Package search provides the JSON API for searching the people and films:
+//
+//    GET /api/v1/search?q=welles&limit=10 - runs Index() to list the people and films matching "welles"
+//
+// Each result looks like this, best match first:
+//
+//    {"kind": "person", "id": 1, "title": "Orson Welles", "uri": "/api/v1/people/1", "score": 3}
+//
+// The URI of a film is the URI of its HTML page, as films are not in the API yet.
+package search
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	peopleAPI "github.com/goblimey/films/controllers/api/people"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/search"
+)
+
+// RootPath is the URI of the search in the API.
+const RootPath = "/api/v1/search"
+
+// DefaultLimit is the number of results if the request doesn't say.
+const DefaultLimit = 20
+
+// MaxLimit is the largest number of results.
+const MaxLimit = 100
+
+// Result is the JSON representation of a search result.
+type Result struct {
+	Kind  string  `json:"kind"`
+	ID    uint64  `json:"id"`
+	Title string  `json:"title"`
+	URI   string  `json:"uri"`
+	Score float64 `json:"score"`
+}
+
+// MakeWebService creates the web service that routes search requests to Index.
+// servicesFilter attaches the services to each request - see services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Produces(restful.MIME_JSON).Filter(servicesFilter)
+	ws.Route(ws.GET("").To(Index))
+	return ws
+}
+
+// Index responds to GET /api/v1/search?q=... with the people and films that
+// match the query.  The limit parameter gives the number of results.  A missing
+// query gives an empty list and a silly limit gives a 400 response.
+func Index(req *restful.Request, resp *restful.Response) {
+	limit := DefaultLimit
+	limitStr := req.QueryParameter("limit")
+	if limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 || n > MaxLimit {
+			em := fmt.Sprintf("the limit must be a number from 1 to %d", MaxLimit)
+			resp.WriteHeaderAndJson(http.StatusBadRequest, peopleAPI.ErrorResponse{Error: em},
+				restful.MIME_JSON)
+			return
+		}
+		limit = n
+	}
+
+	index := services.FromRequest(req).GetSearchIndex()
+	results := index.Search(strings.TrimSpace(req.QueryParameter("q")), limit)
+	list := make([]Result, 0, len(results))
+	for _, result := range results {
+		list = append(list, toJSON(result))
+	}
+	resp.WriteHeaderAndJson(http.StatusOK, list, restful.MIME_JSON)
+}
+
+// toJSON converts a search result to its JSON representation.
+func toJSON(result search.Result) Result {
+	uri := result.URI()
+	if result.Kind == search.KindPerson {
+		uri = fmt.Sprintf("%s/%d", peopleAPI.RootPath, result.ID)
+	}
+	return Result{result.Kind, result.ID, result.Title, uri, result.Score}
+}
@@ -0,0 +1,56 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	restful "github.com/emicklei/go-restful"
+	peopleAPI "github.com/goblimey/films/controllers/api/people"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/search"
+)
+
+// TestUnitSearch checks that the API finds a person created through the indexed
+// repository, and rejects a silly limit.
+func TestUnitSearch(t *testing.T) {
+	session := dbsession.MakeMemoryDBSession()
+	index := search.MakeIndex()
+	repo := peopleRepo.MakeIndexedRepo(session, index)
+	repo.Create(gorpPersonModel.MakeInitialisedPerson(0, "Orson", "Welles"))
+	repo.Create(gorpPersonModel.MakeInitialisedPerson(0, "Joseph", "Cotten"))
+
+	var svc services.ConcreteServices
+	svc.SetSearchIndex(index)
+	getServices := func() (services.Services, error) {
+		return &svc, nil
+	}
+	container := restful.NewContainer()
+	container.Add(MakeWebService(services.Filter(getServices, peopleAPI.ServiceUnavailable)))
+
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/search?q=welels", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	var results []Result
+	err := json.Unmarshal(recorder.Body.Bytes(), &results)
+	if err != nil {
+		t.Fatalf("cannot parse the response - %s", err.Error())
+	}
+	if len(results) != 1 || results[0].Title != "Orson Welles" ||
+		results[0].URI != "/api/v1/people/1" {
+
+		t.Errorf("Expected Orson Welles, got %v", results)
+	}
+
+	recorder = httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/search?q=o&limit=0", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a limit of 0, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,579 @@
+// Package awards provides the controller for the awards resource - the award
+// ceremonies, their categories and the films and people nominated in them:
+//
+//    GET awards/ - runs Index() to list the ceremonies, most recent first
+//    GET awards/n - runs Show() to display the ceremony with ID n, its categories and their nominees
+//    GET awards/create - runs New() to display the page to create a ceremony
+//    PUT awards - runs Create() to create a new ceremony using the data in the supplied form
+//    GET awards/n/edit - runs Edit() to display the page to edit the ceremony with ID n
+//    PUT awards/n - runs Update() to update the ceremony with ID n using the data in the form
+//    DELETE awards/n - runs Delete() to delete the ceremony with ID n, its categories and nominations
+//    PUT awards/n/categories - runs AddCategory() to add a category to the ceremony with ID n
+//    DELETE awards/n/categories/c - runs RemoveCategory() to remove the category with ID c and its nominations
+//    PUT awards/n/nominations - runs AddNomination() to nominate a film, and optionally a person, in a category
+//    PUT awards/n/nominations/m - runs UpdateNomination() to mark the nomination with ID m as a winner or not
+//    DELETE awards/n/nominations/m - runs RemoveNomination() to remove the nomination with ID m
+//
+// The awards of a film or a person are listed on its page - see the films and
+// people controllers.
+package awards
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/awards"
+	awardModel "github.com/goblimey/films/models/award"
+	gorpAwardModel "github.com/goblimey/films/models/award/gorpmysql"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+type Controller struct {
+	services services.Services
+}
+
+// MakeController is a factory that creates an awards controller
+func MakeController(services services.Services) Controller {
+	var controller Controller
+	controller.SetServices(services)
+	return controller
+}
+
+// Index fetches all of the ceremonies and displays the index page.
+func (c Controller) Index(req *restful.Request, resp *restful.Response,
+	form forms.ListForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+
+	ceremonies, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).FindAllCeremonies()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of award ceremonies - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	} else if len(ceremonies) == 0 && form.Notice() == "" {
+		form.SetNotice("there are no award ceremonies currently set up")
+	}
+	form.SetCeremonies(ceremonies)
+
+	utilities.Display(req, resp, c.services, "AwardIndex", form, form.SetViewer)
+}
+
+// Show displays the ceremony with the ID given in the form, each of its
+// categories and the nominees in them, along with the films and people that
+// could be nominated.
+func (c Controller) Show(req *restful.Request, resp *restful.Response,
+	form forms.CeremonyForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+
+	repo := c.services.GetAwardRepository().WithContext(req.Request.Context())
+	ceremony, err := repo.FindCeremonyByID(form.Ceremony().ID())
+	if err != nil {
+		// no such ceremony.  Display index page with error message
+		em := "no such award ceremony"
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	form.SetCeremony(ceremony)
+
+	// If any of the lists can't be fetched, display the page anyway, with an
+	// error.
+	categories, err := repo.FindCategoriesByCeremony(ceremony.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the categories of the ceremony - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetCategories(categories)
+
+	nominations, err := repo.FindNominationsByCeremony(ceremony.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the nominations of the ceremony - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetNominations(nominations)
+
+	films, err := c.services.GetFilmRepository().WithContext(req.Request.Context()).FindAll()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of films - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetFilms(films)
+
+	people, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindAll()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of people - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetPeople(people)
+
+	utilities.Display(req, resp, c.services, "AwardShow", form, form.SetViewer)
+}
+
+// New displays the page to create a new ceremony.
+func (c Controller) New(req *restful.Request, resp *restful.Response,
+	form forms.CeremonyForm) {
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "create award ceremonies") {
+		return
+	}
+	utilities.Display(req, resp, c.services, "AwardCreate", form, form.SetViewer)
+}
+
+// Create creates a new ceremony using the data from the HTTP form displayed by a
+// previous New request.  If the data is invalid, it displays the create page
+// again with error messages.
+func (c Controller) Create(req *restful.Request, resp *restful.Response,
+	form forms.CeremonyForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "create award ceremonies") {
+		return
+	}
+
+	if !form.Validate() {
+		// validation errors.  Return to create screen with error messages in the form data
+		utilities.Display(req, resp, c.services, "AwardCreate", form, form.SetViewer)
+		return
+	}
+
+	ceremony, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).CreateCeremony(form.Ceremony())
+	if err != nil {
+		em := fmt.Sprintf("Could not create award ceremony %s - %s", form.Ceremony().String(), err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	// Success! Ceremony created.  Redirect to its page, so that the categories
+	// can be added.
+	notice := fmt.Sprintf("created new award ceremony %s", ceremony.String())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), ceremonyPath(ceremony.ID()), notice)
+}
+
+// Edit fetches the ceremony with the ID given in the URI and displays the edit
+// page, populated with its data.
+func (c Controller) Edit(req *restful.Request, resp *restful.Response,
+	form forms.CeremonyForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "edit award ceremonies") {
+		return
+	}
+
+	id := req.PathParameter("id")
+	ceremony, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).FindCeremonyByIDStr(id)
+	if err != nil {
+		// No such ceremony.  Display index page with error message.
+		em := err.Error()
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	// If the data is invalid, continue - the user may be trying to fix it.
+	form.SetCeremony(ceremony)
+	if !form.Validate() {
+		logger.Error("invalid record in the award ceremonies database", "ceremony", ceremony.String())
+	}
+
+	utilities.Display(req, resp, c.services, "AwardEdit", form, form.SetViewer)
+}
+
+// Update responds to a PUT request such as PUT /awards/1, invoked by the form
+// displayed by a previous Edit request.  If the data is valid, it updates the
+// ceremony and redirects to its page, otherwise it displays the edit page again
+// with error messages.
+func (c Controller) Update(req *restful.Request, resp *restful.Response,
+	form forms.CeremonyForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "edit award ceremonies") {
+		return
+	}
+
+	if !form.Validate() {
+		utilities.Display(req, resp, c.services, "AwardEdit", form, form.SetViewer)
+		return
+	}
+
+	repo := c.services.GetAwardRepository().WithContext(req.Request.Context())
+	// Check that the ceremony exists - an update of a missing record is not an
+	// error at the database level.
+	_, err := repo.FindCeremonyByID(form.Ceremony().ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot update award ceremony with id %d - %s", form.Ceremony().ID(), err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	_, err = repo.UpdateCeremony(form.Ceremony())
+	if err != nil {
+		em := fmt.Sprintf("Could not update award ceremony %s - %s", form.Ceremony().String(), err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	notice := fmt.Sprintf("updated award ceremony %s", form.Ceremony().String())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), ceremonyPath(form.Ceremony().ID()),
+		notice)
+}
+
+// Delete responds to a DELETE request such as DELETE /awards/1.  It deletes the
+// ceremony, its categories and their nominations and redirects to the index page.
+func (c Controller) Delete(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanDelete, "delete award ceremonies") {
+		return
+	}
+
+	id := req.PathParameter("id")
+	rows, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).DeleteCeremonyByIDStr(id)
+	if err == nil && rows == 0 {
+		err = fmt.Errorf("no such award ceremony")
+	}
+	if err != nil {
+		em := fmt.Sprintf("Cannot delete award ceremony with id %s - %s", id, err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	notice := fmt.Sprintf("deleted award ceremony with ID %s", id)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
+}
+
+// AddCategory responds to a PUT request such as PUT /awards/1/categories.  It
+// adds a category with the name given in the form to the ceremony and redirects
+// to the ceremony's page.
+func (c Controller) AddCategory(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change award categories") {
+		return
+	}
+
+	ceremony := c.findCeremony(req, resp, "Cannot add category")
+	if ceremony == nil {
+		return
+	}
+
+	name := strings.TrimSpace(req.Request.FormValue("name"))
+	if name == "" || len(name) > forms.MaxNameLength {
+		em := fmt.Sprintf("Cannot add category - the name must be between 1 and %d characters",
+			forms.MaxNameLength)
+		logger.Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return
+	}
+
+	category, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).AddCategory(
+		gorpAwardModel.MakeInitialisedCategory(0, ceremony.ID(), name))
+	if err != nil {
+		em := fmt.Sprintf("Cannot add category %s - %s", name, err.Error())
+		logger.Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("added category %s to %s", category.Name(), ceremony.String())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), ceremonyPath(ceremony.ID()), notice)
+}
+
+// RemoveCategory responds to a DELETE request such as DELETE
+// /awards/1/categories/2.  It removes the category with the given ID and its
+// nominations and redirects to the ceremony's page.
+func (c Controller) RemoveCategory(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change award categories") {
+		return
+	}
+
+	ceremony := c.findCeremony(req, resp, "Cannot remove category")
+	if ceremony == nil {
+		return
+	}
+
+	repo := c.services.GetAwardRepository().WithContext(req.Request.Context())
+	categoryIDStr := req.PathParameter("categoryID")
+	categoryID, _ := strconv.ParseUint(categoryIDStr, 10, 64)
+	category, err := repo.FindCategoryByID(categoryID)
+	if err != nil || category.CeremonyID() != ceremony.ID() {
+		// The category does not exist or belongs to another ceremony.
+		em := fmt.Sprintf("Cannot remove category - the ceremony has no category with ID %s",
+			categoryIDStr)
+		logger.Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return
+	}
+
+	_, err = repo.RemoveCategory(category.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove category with ID %s - %s", categoryIDStr, err.Error())
+		logger.Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed category %s from %s", category.Name(), ceremony.String())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), ceremonyPath(ceremony.ID()), notice)
+}
+
+// AddNomination responds to a PUT request such as PUT /awards/1/nominations.  It
+// nominates the film given in the form, and the person if one is given, in one
+// of the ceremony's categories and redirects to the ceremony's page.  If the won
+// box is ticked, the nomination is a winner.
+func (c Controller) AddNomination(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change nominations") {
+		return
+	}
+
+	ceremony := c.findCeremony(req, resp, "Cannot add nomination")
+	if ceremony == nil {
+		return
+	}
+
+	repo := c.services.GetAwardRepository().WithContext(req.Request.Context())
+	categoryIDStr := req.Request.FormValue("categoryID")
+	categoryID, _ := strconv.ParseUint(categoryIDStr, 10, 64)
+	category, err := repo.FindCategoryByID(categoryID)
+	if err != nil || category.CeremonyID() != ceremony.ID() {
+		em := fmt.Sprintf("Cannot add nomination - the ceremony has no category with ID %s",
+			categoryIDStr)
+		logger.Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return
+	}
+
+	filmIDStr := req.Request.FormValue("filmID")
+	filmID, _ := strconv.ParseUint(filmIDStr, 10, 64)
+	film, err := c.services.GetFilmRepository().WithContext(req.Request.Context()).FindByID(filmID)
+	if err != nil {
+		em := fmt.Sprintf("Cannot add nomination - no film with ID %s", filmIDStr)
+		logger.Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return
+	}
+
+	// The person is optional - some awards go to a film, not to anybody in it.
+	nominees := film.Title()
+	var personID uint64
+	personIDStr := strings.TrimSpace(req.Request.FormValue("personID"))
+	if personIDStr != "" && personIDStr != "0" {
+		personID, _ = strconv.ParseUint(personIDStr, 10, 64)
+		person, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindByID(personID)
+		if err != nil {
+			em := fmt.Sprintf("Cannot add nomination - no person with ID %s", personIDStr)
+			logger.Error(em)
+			c.showCeremony(req, resp, ceremony.ID(), "", em)
+			return
+		}
+		nominees = fmt.Sprintf("%s %s for %s", person.Forename(), person.Surname(), film.Title())
+	}
+
+	won := req.Request.FormValue("won") != ""
+	_, err = repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0, category.ID(),
+		film.ID(), personID, won))
+	if err != nil {
+		em := fmt.Sprintf("Cannot nominate %s - %s", nominees, err.Error())
+		logger.Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("nominated %s for %s", nominees, category.Name())
+	if won {
+		notice = fmt.Sprintf("%s won %s", nominees, category.Name())
+	}
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), ceremonyPath(ceremony.ID()), notice)
+}
+
+// UpdateNomination responds to a PUT request such as PUT /awards/1/nominations/2.
+// It marks the nomination with the given ID as a winner if the form has a won
+// value, otherwise as just nominated, and redirects to the ceremony's page.
+func (c Controller) UpdateNomination(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change nominations") {
+		return
+	}
+
+	ceremony := c.findCeremony(req, resp, "Cannot update nomination")
+	if ceremony == nil {
+		return
+	}
+
+	nomination := c.findNomination(req, resp, ceremony, "Cannot update nomination")
+	if nomination == nil {
+		return
+	}
+
+	nomination.SetWon(req.Request.FormValue("won") != "")
+	_, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).UpdateNomination(nomination)
+	if err != nil {
+		em := fmt.Sprintf("Cannot update nomination %s - %s", nomination.String(), err.Error())
+		logger.Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("%s is now just a nominee for %s", nomination.FilmTitle(),
+		nomination.CategoryName())
+	if nomination.Won() {
+		notice = fmt.Sprintf("%s is now a winner of %s", nomination.FilmTitle(),
+			nomination.CategoryName())
+	}
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), ceremonyPath(ceremony.ID()), notice)
+}
+
+// RemoveNomination responds to a DELETE request such as DELETE
+// /awards/1/nominations/2.  It removes the nomination with the given ID and
+// redirects to the ceremony's page.
+func (c Controller) RemoveNomination(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change nominations") {
+		return
+	}
+
+	ceremony := c.findCeremony(req, resp, "Cannot remove nomination")
+	if ceremony == nil {
+		return
+	}
+
+	nomination := c.findNomination(req, resp, ceremony, "Cannot remove nomination")
+	if nomination == nil {
+		return
+	}
+
+	_, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).RemoveNomination(nomination.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove nomination with ID %d - %s", nomination.ID(), err.Error())
+		logger.Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed the nomination of %s for %s", nomination.FilmTitle(),
+		nomination.CategoryName())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), ceremonyPath(ceremony.ID()), notice)
+}
+
+// ErrorHandler displays the index page with an error message
+func (c Controller) ErrorHandler(req *restful.Request, resp *restful.Response,
+	errormessage string) {
+
+	var form forms.ConcreteListForm
+	form.SetErrorMessage(errormessage)
+	c.Index(req, resp, &form)
+}
+
+// SetServices sets the services.
+func (c *Controller) SetServices(services services.Services) {
+	c.services = services
+}
+
+// findCeremony parses the form data and fetches the ceremony with the ID given in
+// the URI.  If either fails, it displays the index page with an error message
+// that starts with the given text and returns nil.
+func (c Controller) findCeremony(req *restful.Request, resp *restful.Response,
+	what string) awardModel.Ceremony {
+
+	logger := logging.FromRequest(req.Request)
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("Internal error - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+
+	ceremony, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).FindCeremonyByIDStr(req.PathParameter("id"))
+	if err != nil {
+		em := fmt.Sprintf("%s - %s", what, err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+	return ceremony
+}
+
+// findNomination fetches the nomination with the ID given in the URI.  If there
+// is no such nomination at the given ceremony, it displays the ceremony's page
+// with an error message that starts with the given text and returns nil.
+func (c Controller) findNomination(req *restful.Request, resp *restful.Response,
+	ceremony awardModel.Ceremony, what string) awardModel.Nomination {
+
+	nominationIDStr := req.PathParameter("nominationID")
+	nominationID, _ := strconv.ParseUint(nominationIDStr, 10, 64)
+	nomination, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).FindNominationByID(nominationID)
+	if err != nil || nomination.CeremonyID() != ceremony.ID() {
+		// The nomination does not exist or is at another ceremony.
+		em := fmt.Sprintf("%s - the ceremony has no nomination with ID %s", what,
+			nominationIDStr)
+		logging.FromRequest(req.Request).Error(em)
+		c.showCeremony(req, resp, ceremony.ID(), "", em)
+		return nil
+	}
+	return nomination
+}
+
+// showCeremony displays the page for the ceremony with the given ID, with a
+// notice and an error message, either of which may be empty.
+func (c Controller) showCeremony(req *restful.Request, resp *restful.Response,
+	ceremonyID uint64, notice string, errorMessage string) {
+
+	var form forms.ConcreteCeremonyForm
+	ceremony := gorpAwardModel.MakeCeremony()
+	ceremony.SetID(ceremonyID)
+	form.SetCeremony(ceremony)
+	form.SetNotice(notice)
+	form.SetErrorMessage(errorMessage)
+	c.Show(req, resp, &form)
+}
+
+// ceremonyPath returns the URI of the page for the ceremony with the given ID.
+func ceremonyPath(id uint64) string {
+	return fmt.Sprintf("%s/%d", RootPath, id)
+}
@@ -0,0 +1,288 @@
+package awards
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goblimey/films/controllers/internal/controllertest"
+	forms "github.com/goblimey/films/forms/awards"
+	mocks "github.com/goblimey/films/mocks/gomock"
+	gorpAwardModel "github.com/goblimey/films/models/award/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+	userModel "github.com/goblimey/films/models/user"
+	awardsRepo "github.com/goblimey/films/repositories/awards"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/golang/mock/gomock"
+)
+
+// TestUnitCreateCeremonyAndNominate checks that an editor can create a ceremony,
+// add a category, nominate a film and a person and mark the winner, that a
+// viewer who is not logged in can't and that a nomination can't be changed via
+// another ceremony.
+func TestUnitCreateCeremonyAndNominate(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	film, err := filmsRepo.MakeRepo(session).Create(
+		filmModel.MakeInitialisedFilm(0, "Forrest Gump", 1994, 142, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	person, err := peopleRepo.MakeRepo(session).Create(
+		gorpPersonModel.MakeInitialisedPerson(0, "Tom", "Hanks"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	mockCreate := mocks.NewMockTemplate(mockCtrl)
+	mockShow := mocks.NewMockTemplate(mockCtrl)
+	mockForbidden := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{
+		"AwardCreate": mockCreate,
+		"AwardShow":   mockShow,
+		"Forbidden":   mockForbidden,
+	}
+	editor := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "rita", Role: userModel.RoleEditor})
+	anonymous := controllertest.MakeHandler(MakeWebService, session, page, auth.Viewer{})
+	repo := awardsRepo.MakeRepo(session)
+
+	// A year that is not a number displays the create page again.
+	var ceremonyForm forms.CeremonyForm
+	mockCreate.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			ceremonyForm = data.(forms.CeremonyForm)
+		}).Return(nil)
+	controllertest.Put(editor, "/awards", "_method=PUT&name=Academy+Awards&year=soon")
+	if ceremonyForm == nil {
+		t.Fatalf("expected the create page to be displayed again")
+	}
+	if ceremonyForm.ErrorForField("Year") != "the Year must be a number" {
+		t.Errorf("expected an error for the Year, got %q", ceremonyForm.ErrorForField("Year"))
+	}
+
+	var tests = []struct {
+		uri      string
+		body     string
+		location string
+	}{
+		{"/awards", "_method=PUT&name=Academy+Awards&year=1995", "/awards/1"},
+		{"/awards/1/categories", "_method=PUT&name=Best+Picture", "/awards/1"},
+		{"/awards/1/categories", "_method=PUT&name=Best+Actor", "/awards/1"},
+		{"/awards/1/nominations", "_method=PUT&categoryID=1&filmID=1&won=true", "/awards/1"},
+		{"/awards/1/nominations", "_method=PUT&categoryID=2&filmID=1&personID=1", "/awards/1"},
+		{"/awards/1/nominations/2", "_method=PUT&won=true", "/awards/1"},
+	}
+	for _, test := range tests {
+		recorder := controllertest.Put(editor, test.uri, test.body)
+		if recorder.Code != http.StatusSeeOther {
+			t.Fatalf("%s: expected status %d, got %d", test.uri, http.StatusSeeOther, recorder.Code)
+		}
+		if recorder.Header().Get("Location") != test.location {
+			t.Errorf("%s: expected a redirect to %s, got %s", test.uri, test.location,
+				recorder.Header().Get("Location"))
+		}
+	}
+
+	nominations, err := repo.FindNominationsByPerson(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(nominations) != 1 || !nominations[0].Won() ||
+		nominations[0].CategoryName() != "Best Actor" || nominations[0].FilmID() != film.ID() {
+
+		t.Errorf("expected Tom Hanks to win Best Actor, got %v", nominations)
+	}
+
+	// Somebody who is not logged in can't nominate anything.
+	mockForbidden.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil)
+	recorder := controllertest.Put(anonymous, "/awards/1/nominations",
+		"_method=PUT&categoryID=1&filmID=1")
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("anonymous: expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+
+	// A nomination at one ceremony can't be removed via another.
+	_, err = repo.CreateCeremony(gorpAwardModel.MakeInitialisedCeremony(0, "BAFTA", 1995))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	var showForm forms.CeremonyForm
+	mockShow.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			showForm = data.(forms.CeremonyForm)
+		}).Return(nil)
+	controllertest.Put(editor, "/awards/2/nominations/1/delete", "_method=DELETE")
+	if showForm == nil || showForm.ErrorMessage() !=
+		"Cannot remove nomination - the ceremony has no nomination with ID 1" {
+
+		t.Errorf("expected the ceremony page with an error")
+	}
+	_, err = repo.FindNominationByID(1)
+	if err != nil {
+		t.Errorf("expected the nomination to survive - %s", err.Error())
+	}
+}
+
+// TestUnitShowListsNomineesByCategory checks that the page of a ceremony lists the
+// nominees in each of its categories with the winner first, and that a missing
+// ceremony displays the index page with an error.
+func TestUnitShowListsNomineesByCategory(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	films := filmsRepo.MakeRepo(session)
+	pulpFiction, err := films.Create(filmModel.MakeInitialisedFilm(0, "Pulp Fiction", 1994, 154, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	gump, err := films.Create(filmModel.MakeInitialisedFilm(0, "Forrest Gump", 1994, 142, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	repo := awardsRepo.MakeRepo(session)
+	oscars, err := repo.CreateCeremony(gorpAwardModel.MakeInitialisedCeremony(0, "Academy Awards", 1995))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	bestPicture, err := repo.AddCategory(gorpAwardModel.MakeInitialisedCategory(0, oscars.ID(), "Best Picture"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.AddCategory(gorpAwardModel.MakeInitialisedCategory(0, oscars.ID(), "Best Director"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0, bestPicture.ID(),
+		pulpFiction.ID(), 0, false))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0, bestPicture.ID(),
+		gump.ID(), 0, true))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	mockIndex := mocks.NewMockTemplate(mockCtrl)
+	mockShow := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"AwardIndex": mockIndex, "AwardShow": mockShow}
+	handler := controllertest.MakeHandler(MakeWebService, session, page, auth.Viewer{})
+
+	var showForm *forms.ConcreteCeremonyForm
+	mockShow.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			showForm = data.(*forms.ConcreteCeremonyForm)
+		}).Return(nil)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/awards/1", nil))
+	if showForm == nil {
+		t.Fatalf("expected the page of the ceremony to be displayed")
+	}
+	categories := showForm.Categories()
+	if len(categories) != 2 || categories[0].Name() != "Best Director" {
+		t.Fatalf("expected two categories in order of name, got %v", categories)
+	}
+	if len(showForm.Nominees(categories[0])) != 0 {
+		t.Errorf("expected no nominees for Best Director, got %v",
+			showForm.Nominees(categories[0]))
+	}
+	nominees := showForm.Nominees(categories[1])
+	if len(nominees) != 2 || !nominees[0].Won() || nominees[0].FilmTitle() != "Forrest Gump" ||
+		nominees[1].Won() || nominees[1].FilmTitle() != "Pulp Fiction" {
+
+		t.Errorf("expected the winner of Best Picture first, got %v", nominees)
+	}
+
+	var listForm forms.ListForm
+	mockIndex.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			listForm = data.(forms.ListForm)
+		}).Return(nil)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/awards/99", nil))
+	if listForm == nil || listForm.ErrorMessage() != "no such award ceremony" {
+		t.Errorf("expected the index page with an error")
+	}
+	if len(listForm.Ceremonies()) != 1 {
+		t.Errorf("expected the index page to list one ceremony, got %d",
+			len(listForm.Ceremonies()))
+	}
+}
+
+// TestUnitShowKeepsPurgedNominee checks that when a nominee is purged from the
+// trash, the page of the ceremony still lists the film that they were nominated
+// for.
+func TestUnitShowKeepsPurgedNominee(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	film, err := filmsRepo.MakeRepo(session).Create(
+		filmModel.MakeInitialisedFilm(0, "Odd Man Out", 1947, 116, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	people := peopleRepo.MakeRepo(session)
+	person, err := people.Create(gorpPersonModel.MakeInitialisedPerson(0, "Carol", "Reed"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	repo := awardsRepo.MakeRepo(session)
+	bafta, err := repo.CreateCeremony(gorpAwardModel.MakeInitialisedCeremony(0, "BAFTA Awards", 1948))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	bestDirector, err := repo.AddCategory(gorpAwardModel.MakeInitialisedCategory(0, bafta.ID(),
+		"Best Director"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0, bestDirector.ID(),
+		film.ID(), person.ID(), true))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = people.DeleteByID(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = people.Purge(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	mockShow := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"AwardShow": mockShow}
+	handler := controllertest.MakeHandler(MakeWebService, session, page, auth.Viewer{})
+
+	var showForm *forms.ConcreteCeremonyForm
+	mockShow.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			showForm = data.(*forms.ConcreteCeremonyForm)
+		}).Return(nil)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/awards/1", nil))
+	if showForm == nil {
+		t.Fatalf("expected the page of the ceremony to be displayed")
+	}
+	categories := showForm.Categories()
+	if len(categories) != 1 {
+		t.Fatalf("expected one category, got %v", categories)
+	}
+	nominees := showForm.Nominees(categories[0])
+	if len(nominees) != 1 || nominees[0].FilmTitle() != "Odd Man Out" ||
+		nominees[0].PersonID() != 0 || !nominees[0].Won() {
+
+		t.Errorf("expected Odd Man Out to stay on the ceremony without the person, got %v",
+			nominees)
+	}
+}
@@ -0,0 +1,199 @@
+package awards
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/awards"
+	gorpAwardModel "github.com/goblimey/films/models/award/gorpmysql"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// RootPath is the URI of the awards resource.
+const RootPath = "/awards"
+
+// idParam is the path parameter holding a numeric ID.  Any other ID doesn't
+// match a route, so the request gets a 404 response.
+const idParam = "{id:[0-9]+}"
+
+// MakeWebService creates the web service that routes requests for the awards
+// resource to the controller.  The browser sends a PUT or DELETE as a POST with a
+// "_method" parameter, which must be turned into the real method before the
+// request is routed.  servicesFilter attaches the services to each request - see
+// services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Filter(servicesFilter)
+
+	form := "application/x-www-form-urlencoded"
+	ws.Route(ws.GET("").To(index))
+	ws.Route(ws.GET("/create").To(newCeremony))
+	ws.Route(ws.GET("/" + idParam).To(show))
+	ws.Route(ws.GET("/" + idParam + "/edit").To(edit))
+	ws.Route(ws.PUT("").Consumes(form).To(create))
+	ws.Route(ws.PUT("/" + idParam).Consumes(form).To(update))
+	ws.Route(ws.DELETE("/" + idParam + "/delete").Consumes(form).To(deleteCeremony))
+	ws.Route(ws.PUT("/" + idParam + "/categories").Consumes(form).To(addCategory))
+	ws.Route(ws.DELETE("/" + idParam + "/categories/{categoryID:[0-9]+}/delete").Consumes(form).
+		To(removeCategory))
+	ws.Route(ws.PUT("/" + idParam + "/nominations").Consumes(form).To(addNomination))
+	ws.Route(ws.PUT("/" + idParam + "/nominations/{nominationID:[0-9]+}").Consumes(form).
+		To(updateNomination))
+	ws.Route(ws.DELETE("/" + idParam + "/nominations/{nominationID:[0-9]+}/delete").Consumes(form).
+		To(removeNomination))
+	return ws
+}
+
+// controller makes a controller using the services attached to the request.
+func controller(req *restful.Request) Controller {
+	return MakeController(services.FromRequest(req))
+}
+
+// index handles "GET /awards" - display the list of ceremonies.
+func index(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteListForm
+	controller(req).Index(req, resp, &form)
+}
+
+// newCeremony handles "GET /awards/create" - display the form to create a
+// ceremony.
+func newCeremony(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteCeremonyForm
+	// Create an empty ceremony to get started.
+	form.SetCeremony(gorpAwardModel.MakeCeremony())
+	controller(req).New(req, resp, &form)
+}
+
+// show handles "GET /awards/3" - display ceremony 3, its categories and
+// nominees.
+func show(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	idStr := req.PathParameter("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		// The route only matches digits, so the ID can only be too big.
+		em := fmt.Sprintf("illegal id %s", idStr)
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	var form forms.ConcreteCeremonyForm
+	ceremony := gorpAwardModel.MakeCeremony()
+	ceremony.SetID(id)
+	form.SetCeremony(ceremony)
+	c.Show(req, resp, &form)
+}
+
+// edit handles "GET /awards/3/edit" - display the form to edit ceremony 3.
+func edit(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteCeremonyForm
+	controller(req).Edit(req, resp, &form)
+}
+
+// create handles "PUT /awards" - create a ceremony from the form data in the
+// body.
+func create(req *restful.Request, resp *restful.Response) {
+	c := controller(req)
+	form := ceremonyFormFromRequest(req, resp, c)
+	if form == nil {
+		return
+	}
+	c.Create(req, resp, form)
+}
+
+// update handles "PUT /awards/3" - update ceremony 3 using the form data in the
+// body.
+func update(req *restful.Request, resp *restful.Response) {
+	c := controller(req)
+	form := ceremonyFormFromRequest(req, resp, c)
+	if form == nil {
+		return
+	}
+	c.Update(req, resp, form)
+}
+
+// deleteCeremony handles "DELETE /awards/3/delete" - delete ceremony 3.
+func deleteCeremony(req *restful.Request, resp *restful.Response) {
+	controller(req).Delete(req, resp)
+}
+
+// addCategory handles "PUT /awards/1/categories" - add the category named in the
+// form to ceremony 1.
+func addCategory(req *restful.Request, resp *restful.Response) {
+	controller(req).AddCategory(req, resp)
+}
+
+// removeCategory handles "DELETE /awards/1/categories/2/delete" - remove
+// category 2 from ceremony 1.
+func removeCategory(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveCategory(req, resp)
+}
+
+// addNomination handles "PUT /awards/1/nominations" - nominate the film and
+// person given in the form in one of the categories of ceremony 1.
+func addNomination(req *restful.Request, resp *restful.Response) {
+	controller(req).AddNomination(req, resp)
+}
+
+// updateNomination handles "PUT /awards/1/nominations/2" - mark nomination 2 as
+// a winner or not.
+func updateNomination(req *restful.Request, resp *restful.Response) {
+	controller(req).UpdateNomination(req, resp)
+}
+
+// removeNomination handles "DELETE /awards/1/nominations/2/delete" - remove
+// nomination 2 from ceremony 1.
+func removeNomination(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveNomination(req, resp)
+}
+
+// ceremonyFormFromRequest gets the ceremony data from the request, creates a
+// GorpMysqlCeremony and returns it in a CeremonyForm.  A year that is not a number
+// gives the form a field error, which causes the validation to fail later on.  If
+// the request can't be handled at all, it displays the index page with an error
+// message and returns nil.
+func ceremonyFormFromRequest(req *restful.Request, resp *restful.Response,
+	c Controller) forms.CeremonyForm {
+
+	logger := logging.FromRequest(req.Request)
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("cannot parse form - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+	var form forms.ConcreteCeremonyForm
+	var ceremony gorpAwardModel.GorpMysqlCeremony
+	idStr := req.PathParameter("id")
+	if idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			em := fmt.Sprintf("invalid id %v in request - should be numeric", idStr)
+			logger.Error(em)
+			c.ErrorHandler(req, resp, em)
+			return nil
+		}
+		ceremony.SetID(id)
+	}
+	ceremony.SetName(req.Request.FormValue("name"))
+
+	yearStr := strings.TrimSpace(req.Request.FormValue("year"))
+	if yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			form.SetErrorMessageForField("Year", "the Year must be a number")
+		} else {
+			ceremony.SetYear(year)
+		}
+	}
+
+	form.SetCeremony(&ceremony)
+	logger.Debug("form", "form", form.String())
+	return &form
+}
@@ -0,0 +1,254 @@
+// Package companies provides the controller for the companies resource - the
+// studios and distributors that make and release films:
+//
+//    GET companies/ - runs Index() to list the companies
+//    GET companies/n - runs Show() to display the company with ID n and its films, by year
+//    GET companies/create - runs New() to display the page to create a company
+//    PUT companies - runs Create() to create a new company using the data in the supplied form
+//    GET companies/n/edit - runs Edit() to display the page to edit the company with ID n
+//    PUT companies/n - runs Update() to update the company with ID n using the data in the form
+//    DELETE companies/n - runs Delete() to delete the company with ID n and its links to films
+//
+// Companies are linked to films from the film's page - see the films controller.
+package companies
+
+import (
+	"fmt"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/companies"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+type Controller struct {
+	services services.Services
+}
+
+// MakeController is a factory that creates a companies controller
+func MakeController(services services.Services) Controller {
+	var controller Controller
+	controller.SetServices(services)
+	return controller
+}
+
+// Index fetches all of the companies and displays the index page.
+func (c Controller) Index(req *restful.Request, resp *restful.Response,
+	form forms.ListForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+
+	companies, err := c.services.GetCompanyRepository().WithContext(req.Request.Context()).FindAll()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of companies - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	} else if len(companies) == 0 && form.Notice() == "" {
+		form.SetNotice("there are no companies currently set up")
+	}
+	form.SetCompanies(companies)
+
+	utilities.Display(req, resp, c.services, "CompanyIndex", form, form.SetViewer)
+}
+
+// Show displays the company with the ID given in the form and the films that it
+// made or distributed, in order of release year.
+func (c Controller) Show(req *restful.Request, resp *restful.Response,
+	form forms.CompanyForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+
+	repo := c.services.GetCompanyRepository().WithContext(req.Request.Context())
+	company, err := repo.FindByID(form.Company().ID())
+	if err != nil {
+		// no such company.  Display index page with error message
+		em := "no such company"
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	form.SetCompany(company)
+
+	// If the films can't be fetched, display the page anyway, with an error.
+	links, err := repo.FindLinksByCompany(company.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the films of the company - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetLinks(links)
+
+	utilities.Display(req, resp, c.services, "CompanyShow", form, form.SetViewer)
+}
+
+// New displays the page to create a new company.
+func (c Controller) New(req *restful.Request, resp *restful.Response,
+	form forms.CompanyForm) {
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "create companies") {
+		return
+	}
+	utilities.Display(req, resp, c.services, "CompanyCreate", form, form.SetViewer)
+}
+
+// Create creates a new company using the data from the HTTP form displayed by a
+// previous New request.  If the data is invalid, it displays the create page
+// again with error messages.
+func (c Controller) Create(req *restful.Request, resp *restful.Response,
+	form forms.CompanyForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "create companies") {
+		return
+	}
+
+	if !form.Validate() {
+		// validation errors.  Return to create screen with error messages in the form data
+		utilities.Display(req, resp, c.services, "CompanyCreate", form, form.SetViewer)
+		return
+	}
+
+	company, err := c.services.GetCompanyRepository().WithContext(req.Request.Context()).Create(form.Company())
+	if err != nil {
+		em := fmt.Sprintf("Could not create company %s - %s", form.Company().String(), err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	// Success! Company created.  Redirect to its page, which displays a
+	// confirmation notice.
+	notice := fmt.Sprintf("created new company %s", company.String())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), companyPath(company.ID()), notice)
+}
+
+// Edit fetches the company with the ID given in the URI and displays the edit
+// page, populated with its data.
+func (c Controller) Edit(req *restful.Request, resp *restful.Response,
+	form forms.CompanyForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "edit companies") {
+		return
+	}
+
+	id := req.PathParameter("id")
+	company, err := c.services.GetCompanyRepository().WithContext(req.Request.Context()).FindByIDStr(id)
+	if err != nil {
+		// No such company.  Display index page with error message.
+		em := err.Error()
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	// If the data is invalid, continue - the user may be trying to fix it.
+	form.SetCompany(company)
+	if !form.Validate() {
+		logger.Error("invalid record in the companies database", "company", company.String())
+	}
+
+	utilities.Display(req, resp, c.services, "CompanyEdit", form, form.SetViewer)
+}
+
+// Update responds to a PUT request such as PUT /companies/1, invoked by the form
+// displayed by a previous Edit request.  If the data is valid, it updates the
+// company and redirects to its page, otherwise it displays the edit page again
+// with error messages.
+func (c Controller) Update(req *restful.Request, resp *restful.Response,
+	form forms.CompanyForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "edit companies") {
+		return
+	}
+
+	if !form.Validate() {
+		utilities.Display(req, resp, c.services, "CompanyEdit", form, form.SetViewer)
+		return
+	}
+
+	repo := c.services.GetCompanyRepository().WithContext(req.Request.Context())
+	// Check that the company exists - an update of a missing record is not an
+	// error at the database level.
+	_, err := repo.FindByID(form.Company().ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot update company with id %d - %s", form.Company().ID(), err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	_, err = repo.Update(form.Company())
+	if err != nil {
+		em := fmt.Sprintf("Could not update company %s - %s", form.Company().String(), err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	notice := fmt.Sprintf("updated company %s", form.Company().String())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), companyPath(form.Company().ID()), notice)
+}
+
+// Delete responds to a DELETE request such as DELETE /companies/1.  It deletes
+// the company and its links to films and redirects to the index page.
+func (c Controller) Delete(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanDelete, "delete companies") {
+		return
+	}
+
+	id := req.PathParameter("id")
+	rows, err := c.services.GetCompanyRepository().WithContext(req.Request.Context()).DeleteByIDStr(id)
+	if err == nil && rows == 0 {
+		err = fmt.Errorf("no such company")
+	}
+	if err != nil {
+		em := fmt.Sprintf("Cannot delete company with id %s - %s", id, err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	notice := fmt.Sprintf("deleted company with ID %s", id)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
+}
+
+// ErrorHandler displays the index page with an error message
+func (c Controller) ErrorHandler(req *restful.Request, resp *restful.Response,
+	errormessage string) {
+
+	var form forms.ConcreteListForm
+	form.SetErrorMessage(errormessage)
+	c.Index(req, resp, &form)
+}
+
+// SetServices sets the services.
+func (c *Controller) SetServices(services services.Services) {
+	c.services = services
+}
+
+// companyPath returns the URI of the page for the company with the given ID.
+func companyPath(id uint64) string {
+	return fmt.Sprintf("%s/%d", RootPath, id)
+}
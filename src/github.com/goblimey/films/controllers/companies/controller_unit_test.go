@@ -0,0 +1,167 @@
+package companies
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goblimey/films/controllers/internal/controllertest"
+	forms "github.com/goblimey/films/forms/companies"
+	mocks "github.com/goblimey/films/mocks/gomock"
+	companyModel "github.com/goblimey/films/models/company"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film"
+	userModel "github.com/goblimey/films/models/user"
+	companiesRepo "github.com/goblimey/films/repositories/companies"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/golang/mock/gomock"
+)
+
+// TestUnitCreateUpdateAndDelete checks that an editor can create and update a
+// company, that invalid data displays the create page again with field errors
+// and that only an admin can delete a company.
+func TestUnitCreateUpdateAndDelete(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockCreate := mocks.NewMockTemplate(mockCtrl)
+	mockForbidden := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{
+		"CompanyCreate": mockCreate,
+		"Forbidden":     mockForbidden,
+	}
+
+	session := dbsession.MakeMemoryDBSession()
+	repo := companiesRepo.MakeRepo(session)
+	editor := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "rita", Role: userModel.RoleEditor})
+	admin := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "ada", Role: userModel.RoleAdmin})
+
+	// A year that is not a number displays the create page again.
+	var companyForm forms.CompanyForm
+	mockCreate.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			companyForm = data.(forms.CompanyForm)
+		}).Return(nil)
+	controllertest.Put(editor, "/companies", "_method=PUT&name=Ealing+Studios&founded=early")
+	if companyForm == nil {
+		t.Fatalf("expected the create page to be displayed again")
+	}
+	if companyForm.ErrorForField("Founded") != "the Founded year must be a number" {
+		t.Errorf("expected an error for the Founded year, got %q",
+			companyForm.ErrorForField("Founded"))
+	}
+
+	recorder := controllertest.Put(editor, "/companies",
+		"_method=PUT&name=Ealing+Studios&country=UK&founded=1902")
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("create: expected status %d, got %d", http.StatusSeeOther, recorder.Code)
+	}
+	if recorder.Header().Get("Location") != "/companies/1" {
+		t.Errorf("expected a redirect to the new company, got %s",
+			recorder.Header().Get("Location"))
+	}
+
+	recorder = controllertest.Put(editor, "/companies/1",
+		"_method=PUT&name=Ealing+Studios&country=UK&founded=1902&defunct=1959")
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("update: expected status %d, got %d", http.StatusSeeOther, recorder.Code)
+	}
+	company, err := repo.FindByID(1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if company.Defunct() != 1959 {
+		t.Errorf("expected the company to be updated, got %s", company.String())
+	}
+
+	// An editor can't delete the company, but an admin can.
+	mockForbidden.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil)
+	recorder = controllertest.Put(editor, "/companies/1/delete", "_method=DELETE")
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("editor delete: expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+	recorder = controllertest.Put(admin, "/companies/1/delete", "_method=DELETE")
+	if recorder.Code != http.StatusSeeOther {
+		t.Errorf("admin delete: expected status %d, got %d", http.StatusSeeOther, recorder.Code)
+	}
+	_, err = repo.FindByID(1)
+	if err == nil {
+		t.Errorf("expected the company to be deleted")
+	}
+}
+
+// TestUnitShowListsFilmsByYear checks that the page of a company lists its films
+// in order of release year, and that a missing company displays the index page
+// with an error.
+func TestUnitShowListsFilmsByYear(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	films := filmsRepo.MakeRepo(session)
+	ladykillers, err := films.Create(filmModel.MakeInitialisedFilm(0, "The Ladykillers", 1955, 91, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	passport, err := films.Create(filmModel.MakeInitialisedFilm(0, "Passport to Pimlico", 1949, 84, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	repo := companiesRepo.MakeRepo(session)
+	ealing, err := repo.Create(gorpCompanyModel.MakeInitialisedCompany(0, "Ealing Studios", "UK", 1902, 0))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for _, link := range []companyModel.Link{
+		gorpCompanyModel.MakeInitialisedLink(0, ladykillers.ID(), ealing.ID(),
+			companyModel.RelationshipProduction),
+		gorpCompanyModel.MakeInitialisedLink(0, passport.ID(), ealing.ID(),
+			companyModel.RelationshipProduction),
+	} {
+		_, err = repo.AddLink(link)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	mockIndex := mocks.NewMockTemplate(mockCtrl)
+	mockShow := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"CompanyIndex": mockIndex, "CompanyShow": mockShow}
+	handler := controllertest.MakeHandler(MakeWebService, session, page, auth.Viewer{})
+
+	var companyForm forms.CompanyForm
+	mockShow.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			companyForm = data.(forms.CompanyForm)
+		}).Return(nil)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/companies/1", nil))
+	if companyForm == nil {
+		t.Fatalf("expected the page of the company to be displayed")
+	}
+	links := companyForm.Links()
+	if len(links) != 2 || links[0].FilmTitle() != "Passport to Pimlico" ||
+		links[1].FilmTitle() != "The Ladykillers" {
+		t.Errorf("expected the films in order of year, got %v", links)
+	}
+
+	var listForm forms.ListForm
+	mockIndex.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			listForm = data.(forms.ListForm)
+		}).Return(nil)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/companies/99", nil))
+	if listForm == nil || listForm.ErrorMessage() != "no such company" {
+		t.Errorf("expected the index page with an error")
+	}
+	if len(listForm.Companies()) != 1 {
+		t.Errorf("expected the index page to list one company, got %d",
+			len(listForm.Companies()))
+	}
+}
@@ -0,0 +1,170 @@
+package companies
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/companies"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// RootPath is the URI of the companies resource.
+const RootPath = "/companies"
+
+// idParam is the path parameter holding a numeric ID.  Any other ID doesn't
+// match a route, so the request gets a 404 response.
+const idParam = "{id:[0-9]+}"
+
+// MakeWebService creates the web service that routes requests for the companies
+// resource to the controller.  The browser sends a PUT or DELETE as a POST with a
+// "_method" parameter, which must be turned into the real method before the
+// request is routed.  servicesFilter attaches the services to each request - see
+// services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Filter(servicesFilter)
+
+	form := "application/x-www-form-urlencoded"
+	ws.Route(ws.GET("").To(index))
+	ws.Route(ws.GET("/create").To(newCompany))
+	ws.Route(ws.GET("/" + idParam).To(show))
+	ws.Route(ws.GET("/" + idParam + "/edit").To(edit))
+	ws.Route(ws.PUT("").Consumes(form).To(create))
+	ws.Route(ws.PUT("/" + idParam).Consumes(form).To(update))
+	ws.Route(ws.DELETE("/" + idParam + "/delete").Consumes(form).To(deleteCompany))
+	return ws
+}
+
+// controller makes a controller using the services attached to the request.
+func controller(req *restful.Request) Controller {
+	return MakeController(services.FromRequest(req))
+}
+
+// index handles "GET /companies" - display the list of companies.
+func index(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteListForm
+	controller(req).Index(req, resp, &form)
+}
+
+// newCompany handles "GET /companies/create" - display the form to create a
+// company.
+func newCompany(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteCompanyForm
+	// Create an empty company to get started.
+	form.SetCompany(gorpCompanyModel.MakeCompany())
+	controller(req).New(req, resp, &form)
+}
+
+// show handles "GET /companies/3" - display company 3 and its films.
+func show(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	idStr := req.PathParameter("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		// The route only matches digits, so the ID can only be too big.
+		em := fmt.Sprintf("illegal id %s", idStr)
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	var form forms.ConcreteCompanyForm
+	company := gorpCompanyModel.MakeCompany()
+	company.SetID(id)
+	form.SetCompany(company)
+	c.Show(req, resp, &form)
+}
+
+// edit handles "GET /companies/3/edit" - display the form to edit company 3.
+func edit(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteCompanyForm
+	controller(req).Edit(req, resp, &form)
+}
+
+// create handles "PUT /companies" - create a company from the form data in the
+// body.
+func create(req *restful.Request, resp *restful.Response) {
+	c := controller(req)
+	form := companyFormFromRequest(req, resp, c)
+	if form == nil {
+		return
+	}
+	c.Create(req, resp, form)
+}
+
+// update handles "PUT /companies/3" - update company 3 using the form data in the
+// body.
+func update(req *restful.Request, resp *restful.Response) {
+	c := controller(req)
+	form := companyFormFromRequest(req, resp, c)
+	if form == nil {
+		return
+	}
+	c.Update(req, resp, form)
+}
+
+// deleteCompany handles "DELETE /companies/3/delete" - delete company 3.
+func deleteCompany(req *restful.Request, resp *restful.Response) {
+	controller(req).Delete(req, resp)
+}
+
+// companyFormFromRequest gets the company data from the request, creates a
+// GorpMysqlCompany and returns it in a CompanyForm.  An empty year is left as 0,
+// meaning not known.  A year that is not a number gives the form a field error,
+// which causes the validation to fail later on.  If the request can't be handled
+// at all, it displays the index page with an error message and returns nil.
+func companyFormFromRequest(req *restful.Request, resp *restful.Response,
+	c Controller) forms.CompanyForm {
+
+	logger := logging.FromRequest(req.Request)
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("cannot parse form - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+	var form forms.ConcreteCompanyForm
+	var company gorpCompanyModel.GorpMysqlCompany
+	idStr := req.PathParameter("id")
+	if idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			em := fmt.Sprintf("invalid id %v in request - should be numeric", idStr)
+			logger.Error(em)
+			c.ErrorHandler(req, resp, em)
+			return nil
+		}
+		company.SetID(id)
+	}
+	company.SetName(req.Request.FormValue("name"))
+	company.SetCountry(req.Request.FormValue("country"))
+
+	foundedStr := strings.TrimSpace(req.Request.FormValue("founded"))
+	if foundedStr != "" {
+		founded, err := strconv.Atoi(foundedStr)
+		if err != nil {
+			form.SetErrorMessageForField("Founded", "the Founded year must be a number")
+		} else {
+			company.SetFounded(founded)
+		}
+	}
+	defunctStr := strings.TrimSpace(req.Request.FormValue("defunct"))
+	if defunctStr != "" {
+		defunct, err := strconv.Atoi(defunctStr)
+		if err != nil {
+			form.SetErrorMessageForField("Defunct", "the Defunct year must be a number")
+		} else {
+			company.SetDefunct(defunct)
+		}
+	}
+
+	form.SetCompany(&company)
+	logger.Debug("form", "form", form.String())
+	return &form
+}
@@ -0,0 +1,164 @@
+// Package diary provides the controller for the viewer's diary - the films that
+// they have watched and when:
+//
+//    GET diary/ - runs Index() to list the viewer's diary, most recent viewing first
+//    GET diary/export - runs Export() to download the viewer's diary as CSV
+//    DELETE diary/n - runs Delete() to remove the entry with ID n from the viewer's diary
+//
+// Viewings are logged from the film's page - see the films controller.  Each
+// user has their own diary, so the viewer must be logged in.
+package diary
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/diary"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/bulk"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+type Controller struct {
+	services services.Services
+}
+
+// MakeController is a factory that creates a diary controller
+func MakeController(services services.Services) Controller {
+	var controller Controller
+	controller.SetServices(services)
+	return controller
+}
+
+// Index fetches the viewer's diary and displays it.
+func (c Controller) Index(req *restful.Request, resp *restful.Response,
+	form forms.ListForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "keep a diary") {
+		return
+	}
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+
+	userID, err := c.userID(req)
+	if err == nil {
+		entries, err := c.services.GetDiaryRepository().WithContext(req.Request.Context()).FindByUser(userID)
+		if err == nil {
+			form.SetEntries(entries)
+			if len(entries) == 0 && form.Notice() == "" {
+				form.SetNotice("there are no films in your diary")
+			}
+		}
+	}
+	if err != nil {
+		em := fmt.Sprintf("error getting the diary - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+
+	utilities.Display(req, resp, c.services, "DiaryIndex", form, form.SetViewer)
+}
+
+// Export responds to GET /diary/export.  It sends the viewer's diary as a CSV
+// file - see bulk.ExportDiary.
+func (c Controller) Export(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "keep a diary") {
+		return
+	}
+
+	// Write to a buffer first, so that an error can still be reported.
+	var buffer bytes.Buffer
+	userID, err := c.userID(req)
+	if err == nil {
+		entries, err := c.services.GetDiaryRepository().WithContext(req.Request.Context()).FindByUser(userID)
+		if err == nil {
+			err = bulk.ExportDiary(&buffer, entries)
+		}
+	}
+	if err != nil {
+		em := fmt.Sprintf("Cannot export the diary - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	resp.AddHeader("Content-Type", "text/csv")
+	resp.AddHeader("Content-Disposition", "attachment; filename=\"diary.csv\"")
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(buffer.Bytes())
+}
+
+// Delete responds to a DELETE request such as DELETE /diary/1.  It removes the
+// entry from the viewer's diary and redirects to the diary.  Users can only
+// change their own diaries.
+func (c Controller) Delete(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "keep a diary") {
+		return
+	}
+
+	idStr := req.PathParameter("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	repo := c.services.GetDiaryRepository().WithContext(req.Request.Context())
+	entry, err := repo.FindByID(id)
+	if err == nil {
+		var userID uint64
+		userID, err = c.userID(req)
+		if err == nil && entry.UserID() != userID {
+			// Treat someone else's entry as missing.
+			err = fmt.Errorf("no such entry")
+		}
+	}
+	if err == nil {
+		_, err = repo.DeleteByID(id)
+	}
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove entry with id %s from the diary - %s", idStr, err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed your viewing of %s on %s from your diary", entry.FilmTitle(),
+		entry.WatchedOn().Format("2 Jan 2006"))
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
+}
+
+// ErrorHandler displays the diary with an error message
+func (c Controller) ErrorHandler(req *restful.Request, resp *restful.Response,
+	errormessage string) {
+
+	var form forms.ConcreteListForm
+	form.SetErrorMessage(errormessage)
+	c.Index(req, resp, &form)
+}
+
+// SetServices sets the services.
+func (c *Controller) SetServices(services services.Services) {
+	c.services = services
+}
+
+// userID gets the ID of the user record of the viewer, who must be logged in.
+func (c Controller) userID(req *restful.Request) (uint64, error) {
+	viewer := auth.ViewerFrom(req.Request)
+	user, err := c.services.GetUserRepository().WithContext(req.Request.Context()).FindByUsername(viewer.Username)
+	if err != nil {
+		return 0, err
+	}
+	return user.ID(), nil
+}
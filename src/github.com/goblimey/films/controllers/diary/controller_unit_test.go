@@ -0,0 +1,162 @@
+package diary
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goblimey/films/controllers/internal/controllertest"
+	forms "github.com/goblimey/films/forms/diary"
+	mocks "github.com/goblimey/films/mocks/gomock"
+	gorpDiaryModel "github.com/goblimey/films/models/diary/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film"
+	userModel "github.com/goblimey/films/models/user"
+	diaryRepo "github.com/goblimey/films/repositories/diary"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/golang/mock/gomock"
+)
+
+// setUp creates an in-memory session holding a film that vera and rita have each
+// logged a viewing of in their diaries, vera's first.
+func setUp(t *testing.T) dbsession.DBSession {
+	session := dbsession.MakeMemoryDBSession()
+	film, err := filmsRepo.MakeRepo(session).Create(
+		filmModel.MakeInitialisedFilm(0, "Brief Encounter", 1945, 86, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	users := usersRepo.MakeRepo(session)
+	repo := diaryRepo.MakeRepo(session)
+	watchedOn := time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)
+	for _, username := range []string{"vera", "rita"} {
+		user, err := users.Add(username, "a long password", userModel.RoleViewer)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		_, err = repo.Create(gorpDiaryModel.MakeInitialisedEntry(0, user.ID(), film.ID(),
+			watchedOn, 8, true))
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	return session
+}
+
+// TestUnitLoggedOut checks that somebody who is not logged in can't see, export
+// or change a diary.
+func TestUnitLoggedOut(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := setUp(t)
+	mockIndex := mocks.NewMockTemplate(mockCtrl)
+	mockForbidden := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{
+		"DiaryIndex": mockIndex,
+		"Forbidden":  mockForbidden,
+	}
+	handler := controllertest.MakeHandler(MakeWebService, session, page, auth.Viewer{})
+
+	mockForbidden.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	for _, uri := range []string{"/diary", "/diary/export"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", uri, nil))
+		if recorder.Code != http.StatusForbidden {
+			t.Errorf("%s: expected status %d, got %d", uri, http.StatusForbidden, recorder.Code)
+		}
+	}
+	recorder := controllertest.Put(handler, "/diary/1/delete", "_method=DELETE")
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("delete: expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+	_, err := diaryRepo.MakeRepo(session).FindByID(1)
+	if err != nil {
+		t.Errorf("expected the entry to survive - %s", err.Error())
+	}
+}
+
+// TestUnitExport checks that the export sends the viewer's diary as CSV, leaving
+// out the other users' entries.
+func TestUnitExport(t *testing.T) {
+
+	page := map[string]retroTemplate.Template{}
+	handler := controllertest.MakeHandler(MakeWebService, setUp(t), page,
+		auth.Viewer{Username: "vera", Role: userModel.RoleViewer})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/diary/export", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if recorder.Header().Get("Content-Type") != "text/csv" {
+		t.Errorf("expected CSV, got %q", recorder.Header().Get("Content-Type"))
+	}
+	want := "date,title,year,rating,rewatch\n2024-05-17,Brief Encounter,1945,8,true\n"
+	if recorder.Body.String() != want {
+		t.Errorf("expected %q, got %q", want, recorder.Body.String())
+	}
+}
+
+// TestUnitDelete checks that another user's entry is treated as missing, and that
+// removing one of the viewer's own entries redirects to the diary, which then
+// displays a notice.
+func TestUnitDelete(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := setUp(t)
+	mockIndex := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"DiaryIndex": mockIndex}
+	handler := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "vera", Role: userModel.RoleViewer})
+	repo := diaryRepo.MakeRepo(session)
+
+	// Entry 2 is rita's.
+	var form forms.ListForm
+	mockIndex.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			form = data.(forms.ListForm)
+		}).Return(nil)
+	controllertest.Put(handler, "/diary/2/delete", "_method=DELETE")
+	if form == nil || form.ErrorMessage() !=
+		"Cannot remove entry with id 2 from the diary - no such entry" {
+
+		t.Errorf("expected the diary with an error")
+	}
+	_, err := repo.FindByID(2)
+	if err != nil {
+		t.Errorf("expected rita's entry to survive - %s", err.Error())
+	}
+
+	recorder := controllertest.Put(handler, "/diary/1/delete", "_method=DELETE")
+	if recorder.Code != http.StatusSeeOther || recorder.Header().Get("Location") != RootPath {
+		t.Fatalf("expected a redirect to %s, got %d %q", RootPath, recorder.Code,
+			recorder.Header().Get("Location"))
+	}
+	_, err = repo.FindByID(1)
+	if err == nil {
+		t.Errorf("expected vera's entry to be removed")
+	}
+
+	form = nil
+	mockIndex.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			form = data.(forms.ListForm)
+		}).Return(nil)
+	req := httptest.NewRequest("GET", RootPath, nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	want := "removed your viewing of Brief Encounter on 17 May 2024 from your diary"
+	if form == nil || form.Notice() != want {
+		t.Errorf("expected the notice %q", want)
+	}
+}
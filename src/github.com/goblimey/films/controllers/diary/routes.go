@@ -0,0 +1,52 @@
+package diary
+
+import (
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/diary"
+	"github.com/goblimey/films/services"
+)
+
+// RootPath is the URI of the diary resource.
+const RootPath = "/diary"
+
+// idParam is the path parameter holding a numeric ID.  Any other ID doesn't
+// match a route, so the request gets a 404 response.
+const idParam = "{id:[0-9]+}"
+
+// MakeWebService creates the web service that routes requests for the diary to
+// the controller.  The browser sends a DELETE as a POST with a "_method"
+// parameter, which must be turned into the real method before the request is
+// routed.  servicesFilter attaches the services to each request - see
+// services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Filter(servicesFilter)
+
+	form := "application/x-www-form-urlencoded"
+	ws.Route(ws.GET("").To(index))
+	ws.Route(ws.GET("/export").To(export))
+	ws.Route(ws.DELETE("/" + idParam + "/delete").Consumes(form).To(deleteEntry))
+	return ws
+}
+
+// controller makes a controller using the services attached to the request.
+func controller(req *restful.Request) Controller {
+	return MakeController(services.FromRequest(req))
+}
+
+// index handles "GET /diary" - display the viewer's diary.
+func index(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteListForm
+	controller(req).Index(req, resp, &form)
+}
+
+// export handles "GET /diary/export" - download the viewer's diary as CSV.
+func export(req *restful.Request, resp *restful.Response) {
+	controller(req).Export(req, resp)
+}
+
+// deleteEntry handles "DELETE /diary/3/delete" - remove entry 3 from the
+// viewer's diary.
+func deleteEntry(req *restful.Request, resp *restful.Response) {
+	controller(req).Delete(req, resp)
+}
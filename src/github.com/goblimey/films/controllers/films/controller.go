@@ -0,0 +1,1262 @@
+// Package films provides the controller for the films resource.  It provides a
+// set of action functions that are triggered by HTTP requests and implement the
+// Create, Read, Update and Delete (CRUD) operations on the films resource:
+//
+//    GET films/ - runs Index() to list all films
+//    GET films/n - runs Show() to display the details of the film with ID n
+//    GET films/create - runs New() to display the page to create a film using any data in the form to pre-populate it
+//    PUT films/n - runs Create() to create a new film using the data in the supplied form
+//    GET films/n/edit - runs Edit() to display the page to edit the film with ID n, using any data in the form to pre-populate it
+//    PUT films/n - runs Update() to update the film with ID n using the data in the form
+//    DELETE films/n - runs Delete() to delete the film with id n
+//    PUT films/n/credits - runs AddCredit() to credit a person on the film with ID n
+//    DELETE films/n/credits/c - runs RemoveCredit() to remove the credit with ID c
+//    PUT films/n/genres - runs AddGenre() to put the film with ID n into a genre
+//    DELETE films/n/genres/g - runs RemoveGenre() to take the film out of the genre with ID g
+//    PUT films/n/tags - runs AddTag() to tag the film with ID n
+//    DELETE films/n/tags/t - runs RemoveTag() to remove the tag with ID t from the film
+//    PUT films/n/companies - runs AddCompany() to link a company to the film with ID n
+//    DELETE films/n/companies/l - runs RemoveCompany() to remove the link with ID l
+//    PUT films/n/reviews - runs SaveReview() to rate and review the film with ID n
+//    DELETE films/n/reviews/r - runs RemoveReview() to remove the review with ID r
+//    PUT films/n/watchlist - runs AddToWatchlist() to put the film with ID n on the viewer's watchlist
+//    DELETE films/n/watchlist - runs RemoveFromWatchlist() to take the film with ID n off the viewer's watchlist
+//    PUT films/n/diary - runs LogViewing() to record in the viewer's diary that they watched the film with ID n
+//
+// The index page can be filtered on a genre and a tag, for example
+// GET films/?genre=3&tag=7.  Filtering on a genre includes the genres below it.
+
+package films
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	creditForms "github.com/goblimey/films/forms/credits"
+	diaryForms "github.com/goblimey/films/forms/diary"
+	forms "github.com/goblimey/films/forms/films"
+	reviewForms "github.com/goblimey/films/forms/reviews"
+	companyModel "github.com/goblimey/films/models/company"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film"
+	reviewModel "github.com/goblimey/films/models/review"
+	gorpReviewModel "github.com/goblimey/films/models/review/gorpmysql"
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	gorpWatchlistModel "github.com/goblimey/films/models/watchlist/gorpmysql"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+type Controller struct {
+	services services.Services
+}
+
+// MakeController is a factory that creates a films controller
+func MakeController(services services.Services) Controller {
+	var controller Controller
+	controller.SetServices(services)
+	return controller
+}
+
+// Index fetches a list of the films that match the query in the form and displays
+// the index page.
+func (c Controller) Index(req *restful.Request, resp *restful.Response,
+	form forms.ListForm) {
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+	listFilms(req, resp, form, c.services)
+	return
+}
+
+// Show displays the details of the film with the ID given in the URI.
+func (c Controller) Show(req *restful.Request, resp *restful.Response,
+	form forms.FilmForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+
+	repo := c.services.GetFilmRepository().WithContext(req.Request.Context())
+
+	// Get the details of the film with the given ID.
+	film, err := repo.FindByID(form.Film().ID())
+	if err != nil {
+		// no such film.  Display index page with error message
+		em := "no such film"
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	// The film in the form contains just an ID.  Replace it with the
+	// complete film record that we just fetched.
+	form.SetFilm(film)
+
+	// Add the cast and crew and the people who could be credited on the film.
+	// If either of those fails, display the page anyway, with an error.
+	credits, err := c.services.GetCreditRepository().WithContext(req.Request.Context()).FindByFilm(film.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the credits for the film - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetCredits(credits)
+
+	people, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindAll()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of people - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetPeople(people)
+
+	// Add the film's genres and tags and the genres that it could be put into.
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	genres, err := taxonomyRepo.GenresOf(dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the genres of the film - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetGenres(genres)
+
+	tags, err := taxonomyRepo.TagsOf(dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the tags of the film - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetTags(tags)
+
+	allGenres, err := taxonomyRepo.FindAllGenres()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of genres - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetAllGenres(allGenres)
+
+	// Add the companies that made and distributed the film and the companies that
+	// could be linked to it.
+	companyRepo := c.services.GetCompanyRepository().WithContext(req.Request.Context())
+	companies, err := companyRepo.FindLinksByFilm(film.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the companies of the film - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetCompanies(companies)
+
+	allCompanies, err := companyRepo.FindAll()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of companies - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetAllCompanies(allCompanies)
+
+	// Add the film's award nominations.
+	awards, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).FindNominationsByFilm(film.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the awards of the film - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetAwards(awards)
+
+	// Add the ratings and reviews and, if the viewer is logged in, their own
+	// review, which they can change, and the film's entry on their watchlist.
+	reviewRepo := c.services.GetReviewRepository().WithContext(req.Request.Context())
+	ratings, err := reviewRepo.FindSummary(film.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the ratings of the film - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetRatings(ratings)
+
+	reviews, err := reviewRepo.FindByFilm(film.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the reviews of the film - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetReviews(reviews)
+
+	viewer := auth.ViewerFrom(req.Request)
+	if viewer.LoggedIn() {
+		myReview := gorpReviewModel.MakeReview()
+		userID, err := c.userID(req, viewer)
+		if err == nil {
+			review, err := reviewRepo.FindByFilmAndUser(film.ID(), userID)
+			if err == nil {
+				myReview = review
+			} else if err != sql.ErrNoRows {
+				logger.Error("error getting the viewer's review", "error", err)
+			}
+			entry, err := c.services.GetWatchlistRepository().WithContext(req.Request.Context()).FindByUserAndFilm(userID, film.ID())
+			if err == nil {
+				form.SetWatchlistEntry(entry)
+			} else if err != sql.ErrNoRows {
+				logger.Error("error getting the viewer's watchlist", "error", err)
+			}
+		} else {
+			logger.Error("error getting the viewer's user record", "error", err)
+		}
+		form.SetMyReview(myReview)
+	}
+
+	page := c.services.Template("FilmShow")
+	if page == nil {
+		em := fmt.Sprintf("internal error displaying Show page - no HTML template")
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	form.SetViewer(viewer)
+	err = page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		em := fmt.Sprintf("error displaying page - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	return
+}
+
+// New displays the page to create a new film.
+func (c Controller) New(req *restful.Request, resp *restful.Response,
+	form forms.FilmForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "create films") {
+		return
+	}
+
+	// Display the page.
+	page := c.services.Template("FilmCreate")
+	if page == nil {
+		em := fmt.Sprintf("internal error displaying Create page - no HTML template")
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	err := page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		logger.Error("error displaying new page", "error", err)
+		em := fmt.Sprintf("error displaying page - %s", err.Error())
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+}
+
+// Create creates a new film using the data from the HTTP form displayed
+// by a previous NEW request.
+func (c Controller) Create(req *restful.Request, resp *restful.Response,
+	form forms.FilmForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "create films") {
+		return
+	}
+
+	if !(form.Validate()) {
+		// validation errors.  Return to create screen with error messages in the form data
+		page := c.services.Template("FilmCreate")
+		if page == nil {
+			em := fmt.Sprintf("internal error displaying Create page - no HTML template")
+			logger.Error(em)
+			c.ErrorHandler(req, resp, em)
+			return
+		}
+		form.SetViewer(auth.ViewerFrom(req.Request))
+		err := page.Execute(resp.ResponseWriter, form)
+		if err != nil {
+			em := fmt.Sprintf("Internal error while preparing create form after failed validation - %s",
+				err.Error())
+			logger.Error(em)
+			c.ErrorHandler(req, resp, em)
+			return
+		}
+		return
+	}
+
+	// Create a film in the database using the validated data in the form
+	repo := c.services.GetFilmRepository().WithContext(req.Request.Context())
+
+	createdFilm, err := repo.Create(form.Film())
+	if err != nil {
+		// Failed to create film.  Display index page with error message.
+		em := fmt.Sprintf("Could not create film %s - %s", form.Film().String(), err.Error())
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	// Success! Film created.  Redirect to the index page, which displays a
+	// confirmation notice.
+	notice := fmt.Sprintf("created new film %s", createdFilm.String())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
+	return
+}
+
+// Edit fetches the data for the films record with the given ID and displays
+// the edit page, populated with that data.
+func (c Controller) Edit(req *restful.Request, resp *restful.Response,
+	form forms.FilmForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "edit films") {
+		return
+	}
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		// failed to parse form
+		em := fmt.Sprintf("cannot parse form - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	// Get the ID of the film
+	id := req.PathParameter("id")
+
+	repo := c.services.GetFilmRepository().WithContext(req.Request.Context())
+	// Get the existing data for the film
+	film, err := repo.FindByIDStr(id)
+	if err != nil {
+		// No such film.  Display index page with error message.
+		em := err.Error()
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	// Got the film with the given ID.  Put it into the form and validate it.
+	// If the data is invalid, continue - the user may be trying to fix it.
+
+	form.SetFilm(film)
+	if !form.Validate() {
+		em := fmt.Sprintf("invalid record in the films database - %s",
+			film.String())
+		logger.Error(em)
+	}
+
+	// Display the edit page
+	page := c.services.Template("FilmEdit")
+	if page == nil {
+		em := fmt.Sprintf("internal error displaying Edit page - no HTML template")
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	err = page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		// error while preparing edit page
+		em := fmt.Sprintf("error displaying page - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+	}
+}
+
+// Update responds to a PUT request.  For example:
+// PUT /films/1
+// It's invoked by the form displayed by a previous Edit request.  If the ID in the URI
+// is valid and the request parameters from the form specify valid film data, it updates
+// the record and redirects to the index page with a confirmation message, otherwise it
+// displays the edit page again with the given data and some error messages.
+func (c Controller) Update(req *restful.Request, resp *restful.Response,
+	form forms.FilmForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "edit films") {
+		return
+	}
+
+	if form.Film() == nil {
+		em := fmt.Sprint("internal error - form should contain an updated film record")
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	// Get the film specified in the form from the DB.
+	// (which also validates the id in the form).
+	repo := c.services.GetFilmRepository().WithContext(req.Request.Context())
+	film, err := repo.FindByID(form.Film().ID())
+	if err != nil {
+		// There is no film with this ID.  The ID is chosen by the user from a
+		// supplied list and it should always be valid, so there's something screwy
+		// going on.  Display the index page with an error message.
+		em := fmt.Sprintf("error searching for film with id %d - %s",
+			form.Film().ID(), err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	// Validate the new version of the film in the form.
+	if !form.Validate() {
+		// The data is invalid.  The validator has set error messages.  Return to
+		// the edit screen.
+		c.displayEditPage(req, resp, form)
+		return
+	}
+
+	// we have a valid record and valid new values.  Update.
+	film.SetTitle(form.Film().Title())
+	film.SetReleaseYear(form.Film().ReleaseYear())
+	film.SetRuntime(form.Film().Runtime())
+	film.SetSynopsis(form.Film().Synopsis())
+	logger.Debug("updating film", "film", film.String())
+	_, err = repo.Update(film)
+	if err != nil {
+		// The commit failed.  Display the edit page with an error message
+		em := fmt.Sprintf("Could not update film - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+		c.displayEditPage(req, resp, form)
+		return
+	}
+
+	// Success!  Redirect to the index page, which displays a confirmation
+	// notice.
+	notice := fmt.Sprintf("updated film %s", form.Film().String())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
+	return
+}
+
+// Delete reponds to a DELETE request and deletes the record with the given ID,
+// eg DELETE http://server:port/films/1.
+func (c Controller) Delete(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanDelete, "delete films") {
+		return
+	}
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		// failed - form does not parse
+		em := fmt.Sprintf("Internal error - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	method := req.Request.FormValue("_method")
+	if "DELETE" != method {
+		// failed - _method param is not DELETE
+		em := fmt.Sprintf("Internal error - request type %s must be DELETE", method)
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	id := req.PathParameter("id")
+
+	repo := c.services.GetFilmRepository().WithContext(req.Request.Context())
+	// Attempt the delete
+	_, err = repo.DeleteByIDStr(id)
+	if err != nil {
+		// failed - cannot delete film
+		em := fmt.Sprintf("Cannot delete film with id %s - %s", id, err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	// Success - film deleted.  Redirect to the index view, which displays a
+	// notification.
+	notice := fmt.Sprintf("deleted film with ID %s", id)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
+	return
+}
+
+// AddCredit responds to a PUT request such as PUT /films/1/credits.  It credits
+// the person on the film, in the role given in the form.  If that works, it
+// redirects to the film's page, which displays a notice, otherwise it displays
+// the film's page again with an error message.
+func (c Controller) AddCredit(req *restful.Request, resp *restful.Response,
+	form creditForms.CreditForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change credits") {
+		return
+	}
+
+	filmID := form.Credit().FilmID()
+
+	if !form.Validate() {
+		// The credit is invalid.  Display the film's page with the errors.
+		em := fmt.Sprintf("cannot add the credit - %s",
+			creditForms.FieldErrorSummary(form))
+		logger.Error(em)
+		c.showFilm(req, resp, filmID, "", em)
+		return
+	}
+
+	// Check that the person exists before crediting them on the film.
+	person, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindByID(form.Credit().PersonID())
+	if err != nil {
+		em := fmt.Sprintf("cannot add the credit - no person with ID %d",
+			form.Credit().PersonID())
+		logger.Error(em)
+		c.showFilm(req, resp, filmID, "", em)
+		return
+	}
+
+	_, err = c.services.GetCreditRepository().WithContext(req.Request.Context()).Create(form.Credit())
+	if err != nil {
+		em := fmt.Sprintf("Could not add the credit - %s", err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, filmID, "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("credited %s %s as %s", person.Forename(), person.Surname(),
+		form.Credit().Role())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(filmID), notice)
+}
+
+// RemoveCredit responds to a DELETE request such as DELETE /films/1/credits/2.
+// It removes the credit with the given ID from the film with the given ID and
+// redirects to the film's page.
+func (c Controller) RemoveCredit(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change credits") {
+		return
+	}
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		// failed - form does not parse
+		em := fmt.Sprintf("Internal error - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	method := req.Request.FormValue("_method")
+	if "DELETE" != method {
+		// failed - _method param is not DELETE
+		em := fmt.Sprintf("Internal error - request type %s must be DELETE", method)
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	film, err := c.services.GetFilmRepository().WithContext(req.Request.Context()).FindByIDStr(req.PathParameter("id"))
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove credit - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	creditRepo := c.services.GetCreditRepository().WithContext(req.Request.Context())
+	creditID := req.PathParameter("creditID")
+	credit, err := creditRepo.FindByIDStr(creditID)
+	if err != nil || credit.FilmID() != film.ID() {
+		// The credit does not exist or is on another film.
+		em := fmt.Sprintf("Cannot remove credit - the film has no credit with ID %s",
+			creditID)
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	_, err = creditRepo.DeleteByID(credit.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove credit with ID %s - %s", creditID, err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed credit for %s %s as %s", credit.PersonForename(),
+		credit.PersonSurname(), credit.Role())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// AddGenre responds to a PUT request such as PUT /films/1/genres.  It puts the
+// film into the genre given by the genreID in the form data and redirects to the
+// film's page, or displays the film's page again with an error message.
+func (c Controller) AddGenre(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change genres and tags") {
+		return
+	}
+
+	film := c.findFilm(req, resp, "Cannot add genre")
+	if film == nil {
+		return
+	}
+
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	genreIDStr := req.Request.FormValue("genreID")
+	genreID, _ := strconv.ParseUint(genreIDStr, 10, 64)
+	genre, err := taxonomyRepo.FindGenreByID(genreID)
+	if err != nil {
+		em := fmt.Sprintf("Cannot add genre - no genre with ID %s", genreIDStr)
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	err = taxonomyRepo.AddGenreTo(genre.ID(), dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot add genre %s - %s", genre.Path(), err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("added %s to genre %s", film.Title(), genre.Path())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// RemoveGenre responds to a DELETE request such as DELETE /films/1/genres/2.  It
+// takes the film out of the genre with the given ID and redirects to the film's
+// page.
+func (c Controller) RemoveGenre(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change genres and tags") {
+		return
+	}
+
+	film := c.findFilmToDelete(req, resp, "Cannot remove genre")
+	if film == nil {
+		return
+	}
+
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	genreIDStr := req.PathParameter("genreID")
+	genreID, _ := strconv.ParseUint(genreIDStr, 10, 64)
+	err := taxonomyRepo.RemoveGenreFrom(genreID, dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove genre with ID %s - %s", genreIDStr, err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed %s from genre with ID %s", film.Title(), genreIDStr)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// AddTag responds to a PUT request such as PUT /films/1/tags.  It tags the film
+// with the name given in the form data, creating the tag if nobody has used it
+// before, and redirects to the film's page, or displays the film's page again
+// with an error message.
+func (c Controller) AddTag(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change genres and tags") {
+		return
+	}
+
+	film := c.findFilm(req, resp, "Cannot add tag")
+	if film == nil {
+		return
+	}
+
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	name := strings.TrimSpace(req.Request.FormValue("name"))
+	tag, err := taxonomyRepo.AddTagTo(name, dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot add tag %q - %s", name, err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("tagged %s with %s", film.Title(), tag.Name())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// RemoveTag responds to a DELETE request such as DELETE /films/1/tags/2.  It
+// removes the tag with the given ID from the film and redirects to the film's
+// page.
+func (c Controller) RemoveTag(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change genres and tags") {
+		return
+	}
+
+	film := c.findFilmToDelete(req, resp, "Cannot remove tag")
+	if film == nil {
+		return
+	}
+
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	tagIDStr := req.PathParameter("tagID")
+	tag, err := taxonomyRepo.FindTagByIDStr(tagIDStr)
+	if err == nil {
+		err = taxonomyRepo.RemoveTagFrom(tag.ID(), dbsession.SubjectFilm, film.ID())
+	}
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove tag with ID %s - %s", tagIDStr, err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed tag %s from %s", tag.Name(), film.Title())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// AddCompany responds to a PUT request such as PUT /films/1/companies.  It links
+// the company given by the companyID in the form data to the film, with the
+// relationship given in the form data, and redirects to the film's page, or
+// displays the film's page again with an error message.
+func (c Controller) AddCompany(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change companies") {
+		return
+	}
+
+	film := c.findFilm(req, resp, "Cannot add company")
+	if film == nil {
+		return
+	}
+
+	relationship := strings.TrimSpace(req.Request.FormValue("relationship"))
+	if !companyModel.ValidRelationship(relationship) {
+		em := fmt.Sprintf("Cannot add company - the relationship must be one of %s",
+			strings.Join(companyModel.Relationships, ", "))
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	companyRepo := c.services.GetCompanyRepository().WithContext(req.Request.Context())
+	companyIDStr := req.Request.FormValue("companyID")
+	companyID, _ := strconv.ParseUint(companyIDStr, 10, 64)
+	company, err := companyRepo.FindByID(companyID)
+	if err != nil {
+		em := fmt.Sprintf("Cannot add company - no company with ID %s", companyIDStr)
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	_, err = companyRepo.AddLink(gorpCompanyModel.MakeInitialisedLink(0, film.ID(),
+		company.ID(), relationship))
+	if err != nil {
+		em := fmt.Sprintf("Cannot add company %s - %s", company.Name(), err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("added %s to the %s of %s", company.Name(), relationship,
+		film.Title())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// RemoveCompany responds to a DELETE request such as DELETE
+// /films/1/companies/2.  It removes the link with the given ID between the film
+// and a company and redirects to the film's page.
+func (c Controller) RemoveCompany(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change companies") {
+		return
+	}
+
+	film := c.findFilmToDelete(req, resp, "Cannot remove company")
+	if film == nil {
+		return
+	}
+
+	companyRepo := c.services.GetCompanyRepository().WithContext(req.Request.Context())
+	linkIDStr := req.PathParameter("linkID")
+	linkID, _ := strconv.ParseUint(linkIDStr, 10, 64)
+	link, err := companyRepo.FindLinkByID(linkID)
+	if err != nil || link.FilmID() != film.ID() {
+		// The link does not exist or is on another film.
+		em := fmt.Sprintf("Cannot remove company - the film has no company link with ID %s",
+			linkIDStr)
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	_, err = companyRepo.RemoveLink(link.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove company link with ID %s - %s", linkIDStr, err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed %s from the %s of %s", link.CompanyName(),
+		link.Relationship(), film.Title())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// SaveReview responds to a PUT request such as PUT /films/1/reviews.  It records
+// the viewer's rating of the film and what they wrote about it, replacing their
+// earlier review if they have one, and redirects to the film's page.
+func (c Controller) SaveReview(req *restful.Request, resp *restful.Response,
+	form reviewForms.ReviewForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "rate films") {
+		return
+	}
+
+	filmID := form.Review().FilmID()
+
+	if !form.Validate() {
+		// The review is invalid.  Display the film's page with the errors.
+		em := fmt.Sprintf("cannot save the review - %s",
+			reviewForms.FieldErrorSummary(form))
+		logger.Error(em)
+		c.showFilm(req, resp, filmID, "", em)
+		return
+	}
+
+	film, err := c.services.GetFilmRepository().WithContext(req.Request.Context()).FindByID(filmID)
+	if err != nil {
+		em := fmt.Sprintf("cannot save the review - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	viewer := auth.ViewerFrom(req.Request)
+	userID, err := c.userID(req, viewer)
+	if err != nil {
+		em := fmt.Sprintf("cannot save the review - no user %s", viewer.Username)
+		logger.Error(em)
+		c.showFilm(req, resp, filmID, "", em)
+		return
+	}
+	form.Review().SetUserID(userID)
+
+	review, err := c.services.GetReviewRepository().WithContext(req.Request.Context()).Save(form.Review())
+	if err != nil {
+		em := fmt.Sprintf("Could not save the review - %s", err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, filmID, "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("%s rated %s %d out of %d", viewer.Username, film.Title(),
+		review.Rating(), reviewModel.MaxRating)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(filmID), notice)
+}
+
+// RemoveReview responds to a DELETE request such as DELETE /films/1/reviews/2.
+// It removes the review with the given ID from the film with the given ID and
+// redirects to the film's page.  Users can remove their own reviews and anybody
+// who can delete things can remove anybody's.
+func (c Controller) RemoveReview(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "remove reviews") {
+		return
+	}
+
+	film := c.findFilmToDelete(req, resp, "Cannot remove review")
+	if film == nil {
+		return
+	}
+
+	reviewRepo := c.services.GetReviewRepository().WithContext(req.Request.Context())
+	reviewIDStr := req.PathParameter("reviewID")
+	reviewID, _ := strconv.ParseUint(reviewIDStr, 10, 64)
+	review, err := reviewRepo.FindByID(reviewID)
+	if err != nil || review.FilmID() != film.ID() {
+		// The review does not exist or is of another film.
+		em := fmt.Sprintf("Cannot remove review - the film has no review with ID %s",
+			reviewIDStr)
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	viewer := auth.ViewerFrom(req.Request)
+	if review.Username() != viewer.Username &&
+		!utilities.Allowed(req, resp, c.services, auth.Viewer.CanDelete,
+			"remove other people's reviews") {
+
+		return
+	}
+
+	_, err = reviewRepo.DeleteByID(review.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove review with ID %s - %s", reviewIDStr, err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed the review of %s by %s", film.Title(), review.Username())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// AddToWatchlist responds to a PUT request such as PUT /films/1/watchlist.  It
+// puts the film on the viewer's watchlist and redirects to the film's page.
+func (c Controller) AddToWatchlist(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "keep a watchlist") {
+		return
+	}
+
+	film := c.findFilm(req, resp, "Cannot add film to the watchlist")
+	if film == nil {
+		return
+	}
+
+	viewer := auth.ViewerFrom(req.Request)
+	userID, err := c.userID(req, viewer)
+	if err != nil {
+		em := fmt.Sprintf("Cannot add film to the watchlist - no user %s", viewer.Username)
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	entry := gorpWatchlistModel.MakeInitialisedEntry(0, userID, film.ID(), time.Time{})
+	_, err = c.services.GetWatchlistRepository().WithContext(req.Request.Context()).Add(entry)
+	if err != nil {
+		em := fmt.Sprintf("Cannot add film to the watchlist - %s", err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("added %s to the watchlist of %s", film.Title(), viewer.Username)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// RemoveFromWatchlist responds to a DELETE request such as DELETE
+// /films/1/watchlist.  It takes the film off the viewer's watchlist and redirects
+// to the film's page.
+func (c Controller) RemoveFromWatchlist(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "keep a watchlist") {
+		return
+	}
+
+	film := c.findFilmToDelete(req, resp, "Cannot remove film from the watchlist")
+	if film == nil {
+		return
+	}
+
+	viewer := auth.ViewerFrom(req.Request)
+	watchlistRepo := c.services.GetWatchlistRepository().WithContext(req.Request.Context())
+	userID, err := c.userID(req, viewer)
+	if err == nil {
+		var entry watchlistModel.Entry
+		entry, err = watchlistRepo.FindByUserAndFilm(userID, film.ID())
+		if err == nil {
+			_, err = watchlistRepo.DeleteByID(entry.ID())
+		} else if err == sql.ErrNoRows {
+			err = fmt.Errorf("it's not on the watchlist")
+		}
+	}
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove film from the watchlist - %s", err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, film.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed %s from the watchlist of %s", film.Title(), viewer.Username)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(film.ID()), notice)
+}
+
+// LogViewing responds to a PUT request such as PUT /films/1/diary.  It records in
+// the viewer's diary that they watched the film on the given day, with an
+// optional rating, and redirects to the film's page.
+func (c Controller) LogViewing(req *restful.Request, resp *restful.Response,
+	form diaryForms.EntryForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "keep a diary") {
+		return
+	}
+
+	filmID := form.Entry().FilmID()
+
+	if !form.Validate() {
+		// The entry is invalid.  Display the film's page with the errors.
+		em := fmt.Sprintf("cannot log the viewing - %s", diaryForms.FieldErrorSummary(form))
+		logger.Error(em)
+		c.showFilm(req, resp, filmID, "", em)
+		return
+	}
+
+	film, err := c.services.GetFilmRepository().WithContext(req.Request.Context()).FindByID(filmID)
+	if err != nil {
+		em := fmt.Sprintf("cannot log the viewing - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	viewer := auth.ViewerFrom(req.Request)
+	userID, err := c.userID(req, viewer)
+	if err != nil {
+		em := fmt.Sprintf("cannot log the viewing - no user %s", viewer.Username)
+		logger.Error(em)
+		c.showFilm(req, resp, filmID, "", em)
+		return
+	}
+	form.Entry().SetUserID(userID)
+
+	entry, err := c.services.GetDiaryRepository().WithContext(req.Request.Context()).Create(form.Entry())
+	if err != nil {
+		em := fmt.Sprintf("Could not log the viewing - %s", err.Error())
+		logger.Error(em)
+		c.showFilm(req, resp, filmID, "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("%s watched %s on %s", viewer.Username, film.Title(),
+		entry.WatchedOn().Format("2 Jan 2006"))
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), filmPath(filmID), notice)
+}
+
+// ErrorHandler displays the films index page with an error message
+func (c Controller) ErrorHandler(req *restful.Request, resp *restful.Response,
+	errormessage string) {
+
+	var form forms.ConcreteListForm
+	form.SetErrorMessage(errormessage)
+	listFilms(req, resp, &form, c.services)
+}
+
+// SetServices sets the services.
+func (c *Controller) SetServices(services services.Services) {
+	c.services = services
+}
+
+// filmPath returns the URI of the page for the film with the given ID.
+func filmPath(id uint64) string {
+	return fmt.Sprintf("%s/%d", RootPath, id)
+}
+
+// showFilm displays the page for the film with the given ID, with a notice and
+// an error message, either of which may be empty.
+func (c Controller) showFilm(req *restful.Request, resp *restful.Response,
+	filmID uint64, notice string, errorMessage string) {
+
+	var form forms.ConcreteFilmForm
+	film := filmModel.MakeFilm()
+	film.SetID(filmID)
+	form.SetFilm(film)
+	form.SetNotice(notice)
+	form.SetErrorMessage(errorMessage)
+	c.Show(req, resp, &form)
+}
+
+// userID gets the ID of the user record of the given viewer, who must be logged
+// in.
+func (c Controller) userID(req *restful.Request, viewer auth.Viewer) (uint64, error) {
+	user, err := c.services.GetUserRepository().WithContext(req.Request.Context()).FindByUsername(viewer.Username)
+	if err != nil {
+		return 0, err
+	}
+	return user.ID(), nil
+}
+
+// findFilm parses the form data and fetches the film with the ID given in the
+// URI.  If either fails, it displays the index page with an error message that
+// starts with the given text and returns nil.
+func (c Controller) findFilm(req *restful.Request, resp *restful.Response,
+	what string) filmModel.Film {
+
+	logger := logging.FromRequest(req.Request)
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("Internal error - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+
+	film, err := c.services.GetFilmRepository().WithContext(req.Request.Context()).FindByIDStr(req.PathParameter("id"))
+	if err != nil {
+		em := fmt.Sprintf("%s - %s", what, err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+	return film
+}
+
+// findFilmToDelete is findFilm for a request that deletes something, which must
+// come with a _method parameter of DELETE.
+func (c Controller) findFilmToDelete(req *restful.Request, resp *restful.Response,
+	what string) filmModel.Film {
+
+	film := c.findFilm(req, resp, what)
+	if film == nil {
+		return nil
+	}
+	method := req.Request.FormValue("_method")
+	if "DELETE" != method {
+		// failed - _method param is not DELETE
+		em := fmt.Sprintf("Internal error - request type %s must be DELETE", method)
+		logging.FromRequest(req.Request).Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+	return film
+}
+
+// displayEditPage displays the edit page again, for example after a failed
+// validation or a failed update.  The form contains the error messages.
+func (c Controller) displayEditPage(req *restful.Request, resp *restful.Response,
+	form forms.FilmForm) {
+
+	logger := logging.FromRequest(req.Request)
+	page := c.services.Template("FilmEdit")
+	if page == nil {
+		em := fmt.Sprintf("internal error displaying Edit page - no HTML template")
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	err := page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		em := fmt.Sprintf("error displaying page - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+	}
+}
+
+/*
+ * The listFilms helper function fetches the list of films that match the
+ * query in the form and displays the index page.  It's used to fulfil an index request but the index page is
+ * also used as the last page of a sequence of requests (for example new,
+ * create, index).  If the sequence was successful, the form may contain a
+ * confirmation note.  If the sequence failed, the form should contain an error
+ * message.
+ */
+func listFilms(req *restful.Request, resp *restful.Response, form forms.ListForm,
+	services services.Services) {
+
+	logger := logging.FromRequest(req.Request)
+
+	repo := services.GetFilmRepository().WithContext(req.Request.Context())
+
+	query := form.Query()
+	filmList, err := repo.FindMatching(query.FilmQuery())
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of films - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	} else {
+		logger.Debug("found films", "count", len(filmList))
+		if len(filmList) <= 0 {
+			if query.Filtered() {
+				form.SetNotice("no films match the filter")
+			} else {
+				form.SetNotice("there are no films currently set up")
+			}
+		}
+	}
+	form.SetFilms(filmList)
+
+	// Add the genres and tags that the list can be filtered on.  If that fails,
+	// the page can still be displayed without them.
+	taxonomyRepo := services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	genres, err := taxonomyRepo.FindAllGenres()
+	if err != nil {
+		logger.Error("error getting the list of genres", "error", err)
+	}
+	form.SetGenres(genres)
+	tags, err := taxonomyRepo.FindAllTags()
+	if err != nil {
+		logger.Error("error getting the list of tags", "error", err)
+	}
+	form.SetTags(tags)
+
+	// Display the index page
+	page := services.Template("FilmIndex")
+	if page == nil {
+		utilities.Dead(resp)
+		return
+	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	err = page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		/*
+		 * Error while displaying the index page.  We handle most internal
+		 * errors by displaying the index page.  That's just failed, so
+		 * fall back to the static error page.
+		 */
+		logger.Error(err.Error())
+		page = services.Template("Error")
+		if page == nil {
+			utilities.Dead(resp)
+			return
+		}
+		err = page.Execute(resp.ResponseWriter, form)
+		if err != nil {
+			// Can't display the static error page either.  Bale out.
+			em := fmt.Sprintf("fatal error - failed to display error page for error %s\n", err.Error())
+			logger.Error(em)
+			panic(em)
+		}
+		return
+	}
+}
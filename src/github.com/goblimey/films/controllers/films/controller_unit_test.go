@@ -0,0 +1,302 @@
+package films
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+
+	restful "github.com/emicklei/go-restful"
+	creditForms "github.com/goblimey/films/forms/credits"
+	filmForms "github.com/goblimey/films/forms/films"
+	mocks "github.com/goblimey/films/mocks/gomock"
+	creditModel "github.com/goblimey/films/models/credit"
+	filmModel "github.com/goblimey/films/models/film"
+	personModel "github.com/goblimey/films/models/person"
+	userModel "github.com/goblimey/films/models/user"
+	awardsRepo "github.com/goblimey/films/repositories/awards"
+	companiesRepo "github.com/goblimey/films/repositories/companies"
+	reviewsRepo "github.com/goblimey/films/repositories/reviews"
+	taxonomyRepo "github.com/goblimey/films/repositories/taxonomy"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/golang/mock/gomock"
+)
+
+var expectedID = uint64(42)
+var expectedTitle = "The Third Man"
+var expectedReleaseYear = 1949
+var expectedRuntime = 104
+var expectedSynopsis = "Holly Martins arrives in Vienna."
+
+// TestUnitIndexWithOneFilm checks that FilmController.Index() handles a list of
+// films from FindMatching() containing one film.
+func TestUnitIndexWithOneFilm(t *testing.T) {
+
+	expectedFilm := filmModel.MakeInitialisedFilm(expectedID, expectedTitle,
+		expectedReleaseYear, expectedRuntime, expectedSynopsis)
+	expectedFilmList := []filmModel.Film{expectedFilm}
+
+	// Create the mocks and dummy objects.
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	request := makeRequest("GET", "/films")
+	mockWriter := mocks.NewMockResponseWriter(mockCtrl)
+	var response restful.Response
+	response.ResponseWriter = mockWriter
+	mockTemplate := mocks.NewMockTemplate(mockCtrl)
+	mockRepo := mocks.NewMockFilmRepository(mockCtrl)
+	mockRepo.EXPECT().WithContext(gomock.Any()).Return(mockRepo).AnyTimes()
+	page := make(map[string]retroTemplate.Template)
+	page["FilmIndex"] = mockTemplate
+
+	// Create a service that returns the mock repository and templates.
+	var services services.ConcreteServices
+	services.SetFilmRepository(mockRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetTemplates(&page)
+
+	var form filmForms.ConcreteListForm
+
+	// Expect FindMatching to be called and return the list, then expect the index
+	// template to be executed.
+	mockRepo.EXPECT().FindMatching(dbsession.FilmQuery{}).Return(expectedFilmList, nil)
+	mockTemplate.EXPECT().Execute(mockWriter, &form).Return(nil)
+
+	// Run the test.
+	controller := MakeController(&services)
+	controller.Index(request, &response, &form)
+
+	if len(form.Films()) != 1 {
+		t.Fatalf("Expected a list of 1, got %d", len(form.Films()))
+	}
+
+	if form.Films()[0].ID() != expectedID {
+		t.Errorf("Expected ID %d, got %d", expectedID, form.Films()[0].ID())
+	}
+
+	if form.Films()[0].Title() != expectedTitle {
+		t.Errorf("Expected title %s, got %s", expectedTitle, form.Films()[0].Title())
+	}
+
+	if form.ErrorMessage() != "" {
+		t.Errorf("Expected no error message, got %s", form.ErrorMessage())
+	}
+}
+
+// TestUnitIndexWithErrorWhenFetchingFilms checks that FilmController.Index()
+// handles errors from FindMatching() correctly.
+func TestUnitIndexWithErrorWhenFetchingFilms(t *testing.T) {
+
+	log.SetPrefix("TestUnitIndexWithErrorWhenFetchingFilms ")
+	log.Printf("This test is expected to provoke error messages in the log")
+
+	expectedErrorMessage := "error getting the list of films - Test Error Message"
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	request := makeRequest("GET", "/films")
+	mockWriter := mocks.NewMockResponseWriter(mockCtrl)
+	var response restful.Response
+	response.ResponseWriter = mockWriter
+	mockTemplate := mocks.NewMockTemplate(mockCtrl)
+	mockRepo := mocks.NewMockFilmRepository(mockCtrl)
+	mockRepo.EXPECT().WithContext(gomock.Any()).Return(mockRepo).AnyTimes()
+	page := make(map[string]retroTemplate.Template)
+	page["FilmIndex"] = mockTemplate
+
+	var services services.ConcreteServices
+	services.SetFilmRepository(mockRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetTemplates(&page)
+
+	var form filmForms.ConcreteListForm
+
+	mockRepo.EXPECT().FindMatching(dbsession.FilmQuery{}).Return(nil, errors.New("Test Error Message"))
+	mockTemplate.EXPECT().Execute(mockWriter, &form).Return(nil)
+
+	controller := MakeController(&services)
+	controller.Index(request, &response, &form)
+
+	if form.ErrorMessage() != expectedErrorMessage {
+		t.Errorf("Expected error message to be %s actually %s",
+			expectedErrorMessage, form.ErrorMessage())
+	}
+
+	if form.Films() != nil {
+		t.Errorf("Expected the list of films to be nil.  Actually contains %d entries",
+			len(form.Films()))
+	}
+}
+
+// TestUnitCreateWithInvalidFilm checks that FilmController.Create() displays the
+// create page again when the film in the form is invalid, and does not call the
+// repository.
+func TestUnitCreateWithInvalidFilm(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	request := makeRequest("POST", "/films")
+	mockWriter := mocks.NewMockResponseWriter(mockCtrl)
+	var response restful.Response
+	response.ResponseWriter = mockWriter
+	mockTemplate := mocks.NewMockTemplate(mockCtrl)
+	mockRepo := mocks.NewMockFilmRepository(mockCtrl)
+	mockRepo.EXPECT().WithContext(gomock.Any()).Return(mockRepo).AnyTimes()
+	page := make(map[string]retroTemplate.Template)
+	page["FilmCreate"] = mockTemplate
+
+	var services services.ConcreteServices
+	services.SetFilmRepository(mockRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetTemplates(&page)
+
+	// The film has no title.
+	var form filmForms.ConcreteFilmForm
+	form.SetFilm(filmModel.MakeInitialisedFilm(0, "", expectedReleaseYear,
+		expectedRuntime, expectedSynopsis))
+
+	mockTemplate.EXPECT().Execute(mockWriter, &form).Return(nil)
+
+	controller := MakeController(&services)
+	controller.Create(request, &response, &form)
+
+	if form.ErrorForField("Title") == "" {
+		t.Errorf("Expected an error message for the title")
+	}
+}
+
+// TestUnitShowWithNoSuchFilm checks that FilmController.Show() displays the index
+// page with an error message when the film does not exist.
+func TestUnitShowWithNoSuchFilm(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	request := makeRequest("GET", "/films/42")
+	mockWriter := mocks.NewMockResponseWriter(mockCtrl)
+	var response restful.Response
+	response.ResponseWriter = mockWriter
+	mockShowTemplate := mocks.NewMockTemplate(mockCtrl)
+	mockIndexTemplate := mocks.NewMockTemplate(mockCtrl)
+	mockRepo := mocks.NewMockFilmRepository(mockCtrl)
+	mockRepo.EXPECT().WithContext(gomock.Any()).Return(mockRepo).AnyTimes()
+	page := make(map[string]retroTemplate.Template)
+	page["FilmShow"] = mockShowTemplate
+	page["FilmIndex"] = mockIndexTemplate
+
+	var services services.ConcreteServices
+	services.SetFilmRepository(mockRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetTemplates(&page)
+
+	var form filmForms.ConcreteFilmForm
+	film := filmModel.MakeFilm()
+	film.SetID(expectedID)
+	form.SetFilm(film)
+
+	// Expect the show template not to be used.  The index page is displayed
+	// with an error message instead.
+	var listForm filmForms.ListForm
+	mockRepo.EXPECT().FindByID(expectedID).Return(nil, errors.New("not found"))
+	mockRepo.EXPECT().FindMatching(dbsession.FilmQuery{}).Return([]filmModel.Film{}, nil)
+	mockIndexTemplate.EXPECT().Execute(mockWriter, gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			listForm = data.(filmForms.ListForm)
+		}).Return(nil)
+
+	controller := MakeController(&services)
+	controller.Show(request, &response, &form)
+
+	if listForm == nil {
+		t.Fatalf("Expected the index page to be displayed")
+	}
+	if listForm.ErrorMessage() != "no such film" {
+		t.Errorf("Expected error message \"no such film\", got \"%s\"",
+			listForm.ErrorMessage())
+	}
+}
+
+// TestUnitAddCreditWithInvalidRole checks that FilmController.AddCredit() does not
+// create a credit with an unknown role, and displays the film's page again with
+// an error message.
+func TestUnitAddCreditWithInvalidRole(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	request := makeRequest("POST", "/films/42/credits")
+	mockWriter := mocks.NewMockResponseWriter(mockCtrl)
+	var response restful.Response
+	response.ResponseWriter = mockWriter
+	mockTemplate := mocks.NewMockTemplate(mockCtrl)
+	mockFilmRepo := mocks.NewMockFilmRepository(mockCtrl)
+	mockFilmRepo.EXPECT().WithContext(gomock.Any()).Return(mockFilmRepo).AnyTimes()
+	mockPeopleRepo := mocks.NewMockRepository(mockCtrl)
+	mockPeopleRepo.EXPECT().WithContext(gomock.Any()).Return(mockPeopleRepo).AnyTimes()
+	mockCreditRepo := mocks.NewMockCreditRepository(mockCtrl)
+	mockCreditRepo.EXPECT().WithContext(gomock.Any()).Return(mockCreditRepo).AnyTimes()
+	page := make(map[string]retroTemplate.Template)
+	page["FilmShow"] = mockTemplate
+
+	var services services.ConcreteServices
+	services.SetFilmRepository(mockFilmRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetCompanyRepository(companiesRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetReviewRepository(reviewsRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetAwardRepository(awardsRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetUserRepository(usersRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetPeopleRepository(mockPeopleRepo)
+	services.SetCreditRepository(mockCreditRepo)
+	services.SetTemplates(&page)
+
+	var form creditForms.ConcreteCreditForm
+	form.SetCredit(creditModel.MakeInitialisedCredit(0, 1, expectedID, "gaffer", "", 0))
+
+	film := filmModel.MakeInitialisedFilm(expectedID, expectedTitle,
+		expectedReleaseYear, expectedRuntime, expectedSynopsis)
+
+	// Expect the film's page to be displayed, and no credit to be created.
+	var filmForm filmForms.FilmForm
+	mockFilmRepo.EXPECT().FindByID(expectedID).Return(film, nil)
+	mockCreditRepo.EXPECT().FindByFilm(expectedID).Return([]creditModel.Credit{}, nil)
+	mockPeopleRepo.EXPECT().FindAll().Return([]personModel.Person{}, nil)
+	mockTemplate.EXPECT().Execute(mockWriter, gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			filmForm = data.(filmForms.FilmForm)
+		}).Return(nil)
+
+	controller := MakeController(&services)
+	controller.AddCredit(request, &response, &form)
+
+	if filmForm == nil {
+		t.Fatalf("Expected the film's page to be displayed")
+	}
+	if filmForm.ErrorMessage() == "" {
+		t.Errorf("Expected an error message")
+	}
+	if form.ErrorForField("Role") == "" {
+		t.Errorf("Expected an error message for the role")
+	}
+}
+
+// makeRequest creates a restful request with the given method and URI, made by
+// an editor.
+func makeRequest(method string, uri string) *restful.Request {
+	var url url.URL
+	url.Opaque = uri // url.RequestURI() will return the uri
+	var httpRequest http.Request
+	httpRequest.URL = &url
+	httpRequest.Method = method
+	var request restful.Request
+	request.Request = auth.WithViewer(&httpRequest,
+		auth.Viewer{Username: "alice", Role: userModel.RoleEditor})
+	return &request
+}
@@ -0,0 +1,437 @@
+package films
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	creditForms "github.com/goblimey/films/forms/credits"
+	diaryForms "github.com/goblimey/films/forms/diary"
+	forms "github.com/goblimey/films/forms/films"
+	reviewForms "github.com/goblimey/films/forms/reviews"
+	gorpCreditModel "github.com/goblimey/films/models/credit/gorpmysql"
+	gorpDiaryModel "github.com/goblimey/films/models/diary/gorpmysql"
+	gorpFilmModel "github.com/goblimey/films/models/film/gorpmysql"
+	gorpReviewModel "github.com/goblimey/films/models/review/gorpmysql"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// RootPath is the URI of the films resource.
+const RootPath = "/films"
+
+// idParam is the path parameter holding a numeric ID.  Any other ID doesn't
+// match a route, so the request gets a 404 response.
+const idParam = "{id:[0-9]+}"
+
+// MakeWebService creates the web service that routes requests for the films
+// resource to the controller.  The browser sends a PUT or DELETE as a POST with a
+// "_method" parameter, which must be turned into the real method before the
+// request is routed.  servicesFilter attaches the services to each request - see
+// services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Filter(servicesFilter)
+
+	form := "application/x-www-form-urlencoded"
+	ws.Route(ws.GET("").To(index))
+	ws.Route(ws.GET("/create").To(newFilm))
+	ws.Route(ws.GET("/" + idParam).To(show))
+	ws.Route(ws.GET("/" + idParam + "/edit").To(edit))
+	ws.Route(ws.PUT("").Consumes(form).To(create))
+	ws.Route(ws.PUT("/" + idParam).Consumes(form).To(update))
+	ws.Route(ws.DELETE("/" + idParam + "/delete").Consumes(form).To(deleteFilm))
+	ws.Route(ws.PUT("/" + idParam + "/credits").Consumes(form).To(addCredit))
+	ws.Route(ws.DELETE("/" + idParam + "/credits/{creditID:[0-9]+}/delete").Consumes(form).
+		To(removeCredit))
+	ws.Route(ws.PUT("/" + idParam + "/genres").Consumes(form).To(addGenre))
+	ws.Route(ws.DELETE("/" + idParam + "/genres/{genreID:[0-9]+}/delete").Consumes(form).
+		To(removeGenre))
+	ws.Route(ws.PUT("/" + idParam + "/tags").Consumes(form).To(addTag))
+	ws.Route(ws.DELETE("/" + idParam + "/tags/{tagID:[0-9]+}/delete").Consumes(form).
+		To(removeTag))
+	ws.Route(ws.PUT("/" + idParam + "/companies").Consumes(form).To(addCompany))
+	ws.Route(ws.DELETE("/" + idParam + "/companies/{linkID:[0-9]+}/delete").Consumes(form).
+		To(removeCompany))
+	ws.Route(ws.PUT("/" + idParam + "/reviews").Consumes(form).To(saveReview))
+	ws.Route(ws.DELETE("/" + idParam + "/reviews/{reviewID:[0-9]+}/delete").Consumes(form).
+		To(removeReview))
+	ws.Route(ws.PUT("/" + idParam + "/watchlist").Consumes(form).To(addToWatchlist))
+	ws.Route(ws.DELETE("/" + idParam + "/watchlist/delete").Consumes(form).To(removeFromWatchlist))
+	ws.Route(ws.PUT("/" + idParam + "/diary").Consumes(form).To(logViewing))
+	return ws
+}
+
+// controller makes a controller using the services attached to the request.
+func controller(req *restful.Request) Controller {
+	return MakeController(services.FromRequest(req))
+}
+
+// index handles "GET /films" - fetch the films records that match the genre and
+// tag in the query parameters, if any, and display them.
+func index(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteListForm
+	form.SetQuery(forms.ParseListQuery(req.Request.URL.Query()))
+	controller(req).Index(req, resp, &form)
+}
+
+// newFilm handles "GET /films/create" - display the form to create a new films
+// record.
+func newFilm(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteFilmForm
+	// Create an empty film to get started.
+	form.SetFilm(gorpFilmModel.MakeFilm())
+	controller(req).New(req, resp, &form)
+}
+
+// show handles "GET /films/435" - fetch the films record with ID 435 and
+// display it.
+func show(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	idStr := req.PathParameter("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		// The route only matches digits, so the ID can only be too big.
+		em := fmt.Sprintf("illegal id %s", idStr)
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	var form forms.ConcreteFilmForm
+	film := gorpFilmModel.MakeFilm()
+	film.SetID(id)
+	form.SetFilm(film)
+	c.Show(req, resp, &form)
+}
+
+// edit handles "GET /films/1/edit" - fetch the films record given by the ID in
+// the request and display the form to edit it.
+func edit(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteFilmForm
+	controller(req).Edit(req, resp, &form)
+}
+
+// create handles "PUT /films" - create a new films record from the form data in
+// the body.
+func create(req *restful.Request, resp *restful.Response) {
+	c := controller(req)
+	form := filmFormFromRequest(req, resp, c)
+	if form == nil {
+		return
+	}
+	c.Create(req, resp, form)
+}
+
+// update handles "PUT /films/1" - update the films record with the given ID
+// using the form data in the body.
+func update(req *restful.Request, resp *restful.Response) {
+	c := controller(req)
+	form := filmFormFromRequest(req, resp, c)
+	if form == nil {
+		return
+	}
+	c.Update(req, resp, form)
+}
+
+// deleteFilm handles "DELETE /films/1/delete" - delete the films record with
+// the ID given in the request.
+func deleteFilm(req *restful.Request, resp *restful.Response) {
+	controller(req).Delete(req, resp)
+}
+
+// addCredit handles "PUT /films/1/credits" - credit the person given in the
+// form data on the film with the given ID.
+func addCredit(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	form, err := creditFormFromRequest(req)
+	if err != nil {
+		logger.Error(err.Error())
+		c.ErrorHandler(req, resp, err.Error())
+		return
+	}
+	c.AddCredit(req, resp, form)
+}
+
+// removeCredit handles "DELETE /films/1/credits/2/delete" - remove credit 2
+// from film 1.
+func removeCredit(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveCredit(req, resp)
+}
+
+// addGenre handles "PUT /films/1/genres" - put film 1 into the genre given in the
+// form data.
+func addGenre(req *restful.Request, resp *restful.Response) {
+	controller(req).AddGenre(req, resp)
+}
+
+// removeGenre handles "DELETE /films/1/genres/2/delete" - take film 1 out of
+// genre 2.
+func removeGenre(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveGenre(req, resp)
+}
+
+// addTag handles "PUT /films/1/tags" - tag film 1 with the name given in the form
+// data.
+func addTag(req *restful.Request, resp *restful.Response) {
+	controller(req).AddTag(req, resp)
+}
+
+// removeTag handles "DELETE /films/1/tags/2/delete" - remove tag 2 from film 1.
+func removeTag(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveTag(req, resp)
+}
+
+// addCompany handles "PUT /films/1/companies" - link the company given in the
+// form data to film 1.
+func addCompany(req *restful.Request, resp *restful.Response) {
+	controller(req).AddCompany(req, resp)
+}
+
+// removeCompany handles "DELETE /films/1/companies/2/delete" - remove link 2
+// between film 1 and a company.
+func removeCompany(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveCompany(req, resp)
+}
+
+// saveReview handles "PUT /films/1/reviews" - record the viewer's rating and
+// review of film 1, given in the form data.
+func saveReview(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	form, err := reviewFormFromRequest(req)
+	if err != nil {
+		logger.Error(err.Error())
+		c.ErrorHandler(req, resp, err.Error())
+		return
+	}
+	c.SaveReview(req, resp, form)
+}
+
+// removeReview handles "DELETE /films/1/reviews/2/delete" - remove review 2 of
+// film 1.
+func removeReview(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveReview(req, resp)
+}
+
+// addToWatchlist handles "PUT /films/1/watchlist" - put film 1 on the viewer's
+// watchlist.
+func addToWatchlist(req *restful.Request, resp *restful.Response) {
+	controller(req).AddToWatchlist(req, resp)
+}
+
+// removeFromWatchlist handles "DELETE /films/1/watchlist/delete" - take film 1
+// off the viewer's watchlist.
+func removeFromWatchlist(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveFromWatchlist(req, resp)
+}
+
+// logViewing handles "PUT /films/1/diary" - record in the viewer's diary that
+// they watched film 1, on the day given in the form data.
+func logViewing(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	form, err := diaryFormFromRequest(req)
+	if err != nil {
+		logger.Error(err.Error())
+		c.ErrorHandler(req, resp, err.Error())
+		return
+	}
+	c.LogViewing(req, resp, form)
+}
+
+// filmFormFromRequest gets the film data from the request, creates a
+// GorpMysqlFilm and returns it in a FilmForm.  The release year and runtime
+// arrive as strings.  If either of them is not a number, the form gets a field
+// error, which causes the validation to fail later on.  If the request can't be
+// handled, it displays the index page with an error message and returns nil.
+func filmFormFromRequest(req *restful.Request, resp *restful.Response,
+	c Controller) forms.FilmForm {
+
+	logger := logging.FromRequest(req.Request)
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("cannot parse form - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+	var form forms.ConcreteFilmForm
+	var film gorpFilmModel.GorpMysqlFilm
+	idStr := req.PathParameter("id")
+	if idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			em := fmt.Sprintf("invalid id %v in request - should be numeric", idStr)
+			logger.Error(em)
+			c.ErrorHandler(req, resp, em)
+			return nil
+		}
+		film.SetID(id)
+	}
+	film.SetTitle(strings.TrimSpace(req.Request.FormValue("title")))
+	film.SetSynopsis(strings.TrimSpace(req.Request.FormValue("synopsis")))
+
+	yearStr := strings.TrimSpace(req.Request.FormValue("releaseYear"))
+	if yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			form.SetErrorMessageForField("ReleaseYear", "the Release Year must be a number")
+		} else {
+			film.SetReleaseYear(year)
+		}
+	}
+
+	runtimeStr := strings.TrimSpace(req.Request.FormValue("runtime"))
+	if runtimeStr != "" {
+		runtime, err := strconv.Atoi(runtimeStr)
+		if err != nil {
+			form.SetErrorMessageForField("Runtime", "the Runtime must be a number of minutes")
+		} else {
+			film.SetRuntime(runtime)
+		}
+	}
+
+	form.SetFilm(&film)
+	logger.Debug("form", "form", form.String())
+	return &form
+}
+
+// creditFormFromRequest gets the credit data from the request, creates a
+// GorpMysqlCredit and returns it in a CreditForm.  The film's ID comes from the
+// URI and the person's ID from the form data.  If the person ID or the billing is
+// not a number, the form gets a field error, which causes the validation to fail
+// later on.  An error is only returned if the request cannot be handled at all.
+func creditFormFromRequest(req *restful.Request) (creditForms.CreditForm, error) {
+
+	logger := logging.FromRequest(req.Request)
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse form - %s", err.Error())
+	}
+
+	idStr := req.PathParameter("id")
+	filmID, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id %v in request - should be numeric", idStr)
+	}
+
+	var form creditForms.ConcreteCreditForm
+	var credit gorpCreditModel.GorpMysqlCredit
+	credit.SetFilmID(filmID)
+	// An unparseable ID leaves the person ID zero, which fails validation.
+	personID, _ := strconv.ParseUint(req.Request.FormValue("personID"), 10, 64)
+	credit.SetPersonID(personID)
+	credit.SetRole(req.Request.FormValue("role"))
+	credit.SetCharacter(req.Request.FormValue("character"))
+
+	billingStr := strings.TrimSpace(req.Request.FormValue("billing"))
+	if billingStr != "" {
+		billing, err := strconv.Atoi(billingStr)
+		if err != nil {
+			form.SetErrorMessageForField("Billing", "the Billing must be a number")
+		} else {
+			credit.SetBilling(billing)
+		}
+	}
+
+	form.SetCredit(&credit)
+	logger.Debug("form", "form", form.String())
+	return &form, nil
+}
+
+// reviewFormFromRequest gets the review data from the request, creates a
+// GorpMysqlReview and returns it in a ReviewForm.  The film's ID comes from the
+// URI and the rating, title and body from the form data.  The reviewer is the
+// viewer, who is filled in by the controller.  If the rating is not a number,
+// the form gets a field error, which causes the validation to fail later on.  An
+// error is only returned if the request cannot be handled at all.
+func reviewFormFromRequest(req *restful.Request) (reviewForms.ReviewForm, error) {
+
+	logger := logging.FromRequest(req.Request)
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse form - %s", err.Error())
+	}
+
+	idStr := req.PathParameter("id")
+	filmID, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id %v in request - should be numeric", idStr)
+	}
+
+	var form reviewForms.ConcreteReviewForm
+	var review gorpReviewModel.GorpMysqlReview
+	review.SetFilmID(filmID)
+	rating, err := strconv.Atoi(strings.TrimSpace(req.Request.FormValue("rating")))
+	if err != nil {
+		form.SetErrorMessageForField("Rating", "you must choose the Rating")
+	} else {
+		review.SetRating(rating)
+	}
+	review.SetTitle(req.Request.FormValue("title"))
+	review.SetBody(req.Request.FormValue("body"))
+
+	form.SetReview(&review)
+	logger.Debug("form", "form", form.String())
+	return &form, nil
+}
+
+// diaryFormFromRequest gets the diary data from the request, creates a
+// GorpMysqlEntry and returns it in an EntryForm.  The film's ID comes from the
+// URI and the date, rating and rewatch flag from the form data.  The date is in
+// the form "2006-01-02", as sent by a date input.  An empty rating means none.
+// The user is the viewer, who is filled in by the controller.  If the date or
+// the rating can't be understood, the form gets a field error, which causes the
+// validation to fail later on.  An error is only returned if the request cannot
+// be handled at all.
+func diaryFormFromRequest(req *restful.Request) (diaryForms.EntryForm, error) {
+
+	logger := logging.FromRequest(req.Request)
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse form - %s", err.Error())
+	}
+
+	idStr := req.PathParameter("id")
+	filmID, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id %v in request - should be numeric", idStr)
+	}
+
+	var form diaryForms.ConcreteEntryForm
+	var entry gorpDiaryModel.GorpMysqlEntry
+	entry.SetFilmID(filmID)
+	watchedOnStr := strings.TrimSpace(req.Request.FormValue("watchedOn"))
+	if watchedOnStr == "" {
+		form.SetErrorMessageForField("WatchedOn", "you must say when you watched the film")
+	} else {
+		watchedOn, err := time.Parse("2006-01-02", watchedOnStr)
+		if err != nil {
+			form.SetErrorMessageForField("WatchedOn", "the date must be in the form 2006-01-02")
+		} else {
+			entry.SetWatchedOn(watchedOn)
+		}
+	}
+	ratingStr := strings.TrimSpace(req.Request.FormValue("rating"))
+	if ratingStr != "" {
+		rating, err := strconv.Atoi(ratingStr)
+		if err != nil {
+			form.SetErrorMessageForField("Rating", "the Rating must be a number")
+		} else {
+			entry.SetRating(rating)
+		}
+	}
+	entry.SetRewatch(req.Request.FormValue("rewatch") != "")
+
+	form.SetEntry(&entry)
+	logger.Debug("form", "form", form.String())
+	return &form, nil
+}
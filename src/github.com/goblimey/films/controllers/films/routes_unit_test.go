@@ -0,0 +1,496 @@
+package films
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goblimey/films/controllers/internal/controllertest"
+	forms "github.com/goblimey/films/forms/films"
+	mocks "github.com/goblimey/films/mocks/gomock"
+	companyModel "github.com/goblimey/films/models/company"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film"
+	userModel "github.com/goblimey/films/models/user"
+	companiesRepo "github.com/goblimey/films/repositories/companies"
+	diaryRepo "github.com/goblimey/films/repositories/diary"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	reviewsRepo "github.com/goblimey/films/repositories/reviews"
+	taxonomyRepo "github.com/goblimey/films/repositories/taxonomy"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	watchlistRepo "github.com/goblimey/films/repositories/watchlist"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/golang/mock/gomock"
+)
+
+// TestUnitRoutes checks that requests are routed to the right handler and that
+// requests which don't match a route get a 404 or 405 response without running
+// any handler.
+func TestUnitRoutes(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockIndex := mocks.NewMockTemplate(mockCtrl)
+	mockCreate := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{
+		"FilmIndex":  mockIndex,
+		"FilmCreate": mockCreate,
+	}
+	handler := controllertest.MakeHandler(MakeWebService, dbsession.MakeMemoryDBSession(), page,
+		auth.Viewer{Username: "alice", Role: userModel.RoleEditor})
+
+	// "create" must not be taken as an ID.  A missing film displays the index
+	// page once, with an error.  A simulated PUT with invalid data displays the
+	// create page again.
+	mockCreate.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockIndex.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	var tests = []struct {
+		method string
+		uri    string
+		body   string
+		status int
+	}{
+		{"GET", "/films/create", "", http.StatusOK},
+		{"GET", "/films/42", "", http.StatusOK},
+		{"POST", "/films", "_method=PUT&title=", http.StatusOK},
+		{"GET", "/films/junk", "", http.StatusNotFound},
+		{"GET", "/films/1/nothing", "", http.StatusNotFound},
+		{"POST", "/films/1", "title=Brief+Encounter", http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, test.uri, strings.NewReader(test.body))
+		if test.body != "" {
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != test.status {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.uri,
+				test.status, recorder.Code)
+		}
+	}
+}
+
+// TestUnitForbidden checks that a viewer can't create a film and an editor can't
+// delete one, and that each gets a 403 response with the forbidden page.
+func TestUnitForbidden(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockForbidden := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"Forbidden": mockForbidden}
+	mockForbidden.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+
+	var tests = []struct {
+		role   string
+		method string
+		uri    string
+		body   string
+	}{
+		{userModel.RoleViewer, "GET", "/films/create", ""},
+		{userModel.RoleViewer, "POST", "/films", "_method=PUT&title=Brief+Encounter"},
+		{userModel.RoleEditor, "POST", "/films/1/delete", "_method=DELETE"},
+	}
+
+	for _, test := range tests {
+		handler := controllertest.MakeHandler(MakeWebService, dbsession.MakeMemoryDBSession(), page,
+			auth.Viewer{Username: "rita", Role: test.role})
+		request := httptest.NewRequest(test.method, test.uri, strings.NewReader(test.body))
+		if test.body != "" {
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusForbidden {
+			t.Errorf("%s %s %s: expected status %d, got %d", test.role, test.method,
+				test.uri, http.StatusForbidden, recorder.Code)
+		}
+	}
+}
+
+// TestUnitGenresAndTags checks that an editor can put a film into a genre and tag
+// it, and that the index page can then be filtered on the genre and the tag.
+func TestUnitGenresAndTags(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	film, err := filmsRepo.MakeRepo(session).Create(
+		filmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	taxonomy := taxonomyRepo.MakeRepo(session)
+	thriller, err := taxonomy.AddGenre("Thriller", 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	psychological, err := taxonomy.AddGenre("Psychological Thriller", thriller.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	mockIndex := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"FilmIndex": mockIndex}
+	handler := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "alice", Role: userModel.RoleEditor})
+
+	changes := []string{
+		fmt.Sprintf("/films/%d/genres", film.ID()),
+		fmt.Sprintf("/films/%d/tags", film.ID()),
+	}
+	bodies := []string{
+		fmt.Sprintf("_method=PUT&genreID=%d", psychological.ID()),
+		"_method=PUT&name=Film++Noir",
+	}
+	for i, uri := range changes {
+		request := httptest.NewRequest("POST", uri, strings.NewReader(bodies[i]))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusSeeOther {
+			t.Errorf("%s: expected status %d, got %d", uri, http.StatusSeeOther,
+				recorder.Code)
+		}
+	}
+
+	tags, err := taxonomy.TagsOf(dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(tags) != 1 || tags[0].Name() != "film noir" {
+		t.Fatalf("expected the film to be tagged with film noir, got %v", tags)
+	}
+
+	// Filtering on the parent genre finds the film in the child genre.
+	var tests = []struct {
+		uri      string
+		expected int
+	}{
+		{fmt.Sprintf("/films?genre=%d", thriller.ID()), 1},
+		{fmt.Sprintf("/films?genre=%d&tag=%d", psychological.ID(), tags[0].ID()), 1},
+		{fmt.Sprintf("/films?tag=%d", tags[0].ID()+1), 0},
+	}
+	for _, test := range tests {
+		var listForm forms.ListForm
+		mockIndex.EXPECT().Execute(gomock.Any(), gomock.Any()).
+			Do(func(w interface{}, data interface{}) {
+				listForm = data.(forms.ListForm)
+			}).Return(nil)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", test.uri, nil))
+		if listForm == nil {
+			t.Fatalf("%s: expected the index page to be displayed", test.uri)
+		}
+		if len(listForm.Films()) != test.expected {
+			t.Errorf("%s: expected %d films, got %d", test.uri, test.expected,
+				len(listForm.Films()))
+		}
+	}
+}
+
+// TestUnitCompanies checks that an editor can link a company to a film and remove
+// the link again, and that a link with an unknown relationship is refused.
+func TestUnitCompanies(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	film, err := filmsRepo.MakeRepo(session).Create(
+		filmModel.MakeInitialisedFilm(0, "Kind Hearts and Coronets", 1949, 106, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	companies := companiesRepo.MakeRepo(session)
+	ealing, err := companies.Create(
+		gorpCompanyModel.MakeInitialisedCompany(0, "Ealing Studios", "UK", 1902, 0))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	mockShow := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"FilmShow": mockShow}
+	handler := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "alice", Role: userModel.RoleEditor})
+
+	// A bad relationship displays the film's page with an error.
+	var filmForm forms.FilmForm
+	mockShow.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			filmForm = data.(forms.FilmForm)
+		}).Return(nil)
+	uri := fmt.Sprintf("/films/%d/companies", film.ID())
+	request := httptest.NewRequest("POST", uri, strings.NewReader(
+		fmt.Sprintf("_method=PUT&companyID=%d&relationship=catering", ealing.ID())))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	if filmForm == nil || filmForm.ErrorMessage() == "" {
+		t.Fatalf("expected the film's page to be displayed with an error")
+	}
+
+	request = httptest.NewRequest("POST", uri, strings.NewReader(
+		fmt.Sprintf("_method=PUT&companyID=%d&relationship=%s", ealing.ID(),
+			companyModel.RelationshipProduction)))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("%s: expected status %d, got %d", uri, http.StatusSeeOther, recorder.Code)
+	}
+	links, err := companies.FindLinksByCompany(ealing.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(links) != 1 || links[0].FilmID() != film.ID() {
+		t.Fatalf("expected the company to be linked to the film, got %v", links)
+	}
+
+	uri = fmt.Sprintf("/films/%d/companies/%d/delete", film.ID(), links[0].ID())
+	request = httptest.NewRequest("POST", uri, strings.NewReader("_method=DELETE"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("%s: expected status %d, got %d", uri, http.StatusSeeOther, recorder.Code)
+	}
+	links, err = companies.FindLinksByFilm(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(links) != 0 {
+		t.Errorf("expected the link to be removed, got %v", links)
+	}
+}
+
+// TestUnitReviews checks that a user can rate a film and change their rating,
+// that a rating out of range is refused, and that only the reviewer or an admin
+// can remove the review.
+func TestUnitReviews(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	film, err := filmsRepo.MakeRepo(session).Create(
+		filmModel.MakeInitialisedFilm(0, "The Ladykillers", 1955, 91, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	users := usersRepo.MakeRepo(session)
+	for _, name := range []string{"vera", "rita"} {
+		_, err = users.Add(name, "pw12345678", userModel.RoleViewer)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	reviews := reviewsRepo.MakeRepo(session)
+
+	mockShow := mocks.NewMockTemplate(mockCtrl)
+	mockForbidden := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"FilmShow": mockShow, "Forbidden": mockForbidden}
+	vera := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "vera", Role: userModel.RoleViewer})
+	rita := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "rita", Role: userModel.RoleViewer})
+	ada := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "ada", Role: userModel.RoleAdmin})
+
+	post := func(handler http.Handler, uri string, body string) int {
+		request := httptest.NewRequest("POST", uri, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// Rate the film and then change the rating.  The user still has one review.
+	uri := fmt.Sprintf("/films/%d/reviews", film.ID())
+	for _, rating := range []int{8, 6} {
+		body := fmt.Sprintf("_method=PUT&rating=%d&title=Spiffing&body=Jolly+good", rating)
+		code := post(vera, uri, body)
+		if code != http.StatusSeeOther {
+			t.Fatalf("%s: expected status %d, got %d", uri, http.StatusSeeOther, code)
+		}
+	}
+	filmReviews, err := reviews.FindByFilm(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(filmReviews) != 1 || filmReviews[0].Rating() != 6 ||
+		filmReviews[0].Username() != "vera" {
+
+		t.Fatalf("expected one review by vera with rating 6, got %v", filmReviews)
+	}
+	summary, err := reviews.FindSummary(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if summary.Count() != 1 || summary.Average() != 6 {
+		t.Errorf("expected one rating of 6, got %s", summary.String())
+	}
+
+	// A rating out of range displays the film's page with an error.
+	var filmForm forms.FilmForm
+	mockShow.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			filmForm = data.(forms.FilmForm)
+		}).Return(nil)
+	post(rita, uri, "_method=PUT&rating=11")
+	if filmForm == nil || filmForm.ErrorMessage() == "" {
+		t.Fatalf("expected the film's page to be displayed with an error")
+	}
+	if filmForm.MyReview() == nil || filmForm.MyReview().ID() != 0 {
+		t.Errorf("expected rita to have an empty review, got %v", filmForm.MyReview())
+	}
+
+	// Rita can't remove Vera's review, but an admin can.
+	mockForbidden.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil)
+	uri = fmt.Sprintf("/films/%d/reviews/%d/delete", film.ID(), filmReviews[0].ID())
+	code := post(rita, uri, "_method=DELETE")
+	if code != http.StatusForbidden {
+		t.Fatalf("%s: expected status %d, got %d", uri, http.StatusForbidden, code)
+	}
+	code = post(ada, uri, "_method=DELETE")
+	if code != http.StatusSeeOther {
+		t.Fatalf("%s: expected status %d, got %d", uri, http.StatusSeeOther, code)
+	}
+	summary, err = reviews.FindSummary(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if summary.Count() != 0 {
+		t.Errorf("expected no ratings, got %s", summary.String())
+	}
+}
+
+// TestUnitWatchlistAndDiary checks that a user can put a film on their watchlist
+// and take it off again, that the film's page shows whether it's on the list,
+// and that a viewing can be logged in the diary but not in the future.
+func TestUnitWatchlistAndDiary(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	film, err := filmsRepo.MakeRepo(session).Create(
+		filmModel.MakeInitialisedFilm(0, "Kind Hearts and Coronets", 1949, 106, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	vera, err := usersRepo.MakeRepo(session).Add("vera", "pw12345678", userModel.RoleViewer)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	watchlist := watchlistRepo.MakeRepo(session)
+	diary := diaryRepo.MakeRepo(session)
+
+	mockShow := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"FilmShow": mockShow}
+	handler := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "vera", Role: userModel.RoleViewer})
+
+	post := func(uri string, body string) int {
+		request := httptest.NewRequest("POST", uri, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	uri := fmt.Sprintf("/films/%d/watchlist", film.ID())
+	code := post(uri, "_method=PUT")
+	if code != http.StatusSeeOther {
+		t.Fatalf("%s: expected status %d, got %d", uri, http.StatusSeeOther, code)
+	}
+	entries, err := watchlist.FindByUser(vera.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(entries) != 1 || entries[0].FilmID() != film.ID() {
+		t.Fatalf("expected the film on the watchlist, got %v", entries)
+	}
+
+	// The film's page shows the entry.
+	var filmForm forms.FilmForm
+	mockShow.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			filmForm = data.(forms.FilmForm)
+		}).Return(nil).Times(2)
+	request := httptest.NewRequest("GET", fmt.Sprintf("/films/%d", film.ID()), nil)
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	if filmForm == nil || filmForm.WatchlistEntry() == nil {
+		t.Fatalf("expected the film's page to show the watchlist entry")
+	}
+
+	uri = fmt.Sprintf("/films/%d/watchlist/delete", film.ID())
+	code = post(uri, "_method=DELETE")
+	if code != http.StatusSeeOther {
+		t.Fatalf("%s: expected status %d, got %d", uri, http.StatusSeeOther, code)
+	}
+	entries, err = watchlist.FindByUser(vera.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected an empty watchlist, got %v", entries)
+	}
+
+	// Log a viewing.  A viewing in the future displays the film's page with an
+	// error.
+	uri = fmt.Sprintf("/films/%d/diary", film.ID())
+	code = post(uri, "_method=PUT&watchedOn=2024-05-17&rating=8&rewatch=on")
+	if code != http.StatusSeeOther {
+		t.Fatalf("%s: expected status %d, got %d", uri, http.StatusSeeOther, code)
+	}
+	post(uri, "_method=PUT&watchedOn=2999-01-01")
+	if filmForm.ErrorMessage() == "" {
+		t.Errorf("expected an error about the date")
+	}
+	viewings, err := diary.FindByUser(vera.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(viewings) != 1 || viewings[0].Rating() != 8 || !viewings[0].Rewatch() ||
+		viewings[0].WatchedOn().Format("2006-01-02") != "2024-05-17" {
+
+		t.Errorf("expected one rewatch rated 8 on 2024-05-17, got %v", viewings)
+	}
+}
+
+// TestUnitMethodOverride checks that a POST with a _method parameter is seen as
+// that method, and that other parameters are left alone.
+func TestUnitMethodOverride(t *testing.T) {
+	var tests = []struct {
+		body   string
+		method string
+	}{
+		{"_method=PUT", "PUT"},
+		{"_method=DELETE", "DELETE"},
+		{"_method=GET", "POST"},
+		{"title=x", "POST"},
+	}
+
+	for _, test := range tests {
+		var got string
+		handler := utilities.MethodOverride(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				got = r.Method
+			}))
+		request := httptest.NewRequest("POST", "/films", strings.NewReader(test.body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		if got != test.method {
+			t.Errorf("%s: expected method %s, got %s", test.body, test.method, got)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Package controllertest provides the fixture that the controller tests use to
+// send requests to a web service in the same way as the server, with in-memory
+// repositories and mock templates.
+package controllertest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	awardsRepo "github.com/goblimey/films/repositories/awards"
+	companiesRepo "github.com/goblimey/films/repositories/companies"
+	creditsRepo "github.com/goblimey/films/repositories/credits"
+	diaryRepo "github.com/goblimey/films/repositories/diary"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	reviewsRepo "github.com/goblimey/films/repositories/reviews"
+	taxonomyRepo "github.com/goblimey/films/repositories/taxonomy"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	watchlistRepo "github.com/goblimey/films/repositories/watchlist"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// MakeHandler creates a handler that routes requests to the web service made by
+// makeWebService in the same way as the server, using in-memory repositories on
+// the given session and the given templates.  Every request is made by the given
+// viewer.  Notices are carried across redirects in the flash cookie as usual.
+func MakeHandler(makeWebService func(restful.FilterFunction) *restful.WebService,
+	session dbsession.DBSession, page map[string]retroTemplate.Template,
+	viewer auth.Viewer) http.Handler {
+
+	var svc services.ConcreteServices
+	svc.SetDBSession(session)
+	svc.SetPeopleRepository(peopleRepo.MakeRepo(session))
+	svc.SetFilmRepository(filmsRepo.MakeRepo(session))
+	svc.SetCreditRepository(creditsRepo.MakeRepo(session))
+	svc.SetTaxonomyRepository(taxonomyRepo.MakeRepo(session))
+	svc.SetCompanyRepository(companiesRepo.MakeRepo(session))
+	svc.SetReviewRepository(reviewsRepo.MakeRepo(session))
+	svc.SetWatchlistRepository(watchlistRepo.MakeRepo(session))
+	svc.SetDiaryRepository(diaryRepo.MakeRepo(session))
+	svc.SetAwardRepository(awardsRepo.MakeRepo(session))
+	svc.SetUserRepository(usersRepo.MakeRepo(session))
+	svc.SetTemplates(&page)
+	svc.SetSessions(auth.MakeSessions("", time.Hour, false))
+
+	getServices := func() (services.Services, error) {
+		return &svc, nil
+	}
+	unavailable := func(resp *restful.Response) {
+		resp.WriteHeader(http.StatusServiceUnavailable)
+	}
+	container := restful.NewContainer()
+	viewerFilter := func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		req.Request = auth.WithViewer(req.Request, viewer)
+		chain.ProcessFilter(req, resp)
+	}
+	container.Add(makeWebService(services.Filter(getServices, unavailable)).Filter(viewerFilter))
+	return utilities.MethodOverride(container)
+}
+
+// Put sends a form to the handler as the browser does, as a POST with a
+// "_method" parameter, and returns the response.
+func Put(handler http.Handler, uri string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest("POST", uri, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
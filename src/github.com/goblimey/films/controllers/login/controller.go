@@ -0,0 +1,139 @@
+// Package login provides the controller for logging in and out:
+//
+//    GET login?next=/people/1/edit - runs Show() to display the login page
+//    PUT login - runs Login() to check the username and password and log the user in
+//    DELETE login - runs Logout() to log the user out
+//
+// After logging in, the user is sent to the page given by the "next" parameter,
+// or to the list of people.
+package login
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/login"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// DefaultNext is the page displayed after logging in, when there is no other.
+const DefaultNext = "/people"
+
+type Controller struct {
+	services services.Services
+}
+
+// MakeController is a factory that creates a login controller
+func MakeController(services services.Services) Controller {
+	var controller Controller
+	controller.SetServices(services)
+	return controller
+}
+
+// Show displays the login page.
+func (c Controller) Show(req *restful.Request, resp *restful.Response,
+	form forms.LoginForm) {
+
+	if notice := utilities.Flash(req, resp, c.services.GetSessions()); notice != "" {
+		form.SetNotice(notice)
+	}
+	c.display(req, resp, form)
+}
+
+// Login checks the username in the form and the given password.  If they are
+// right, it sends a session cookie and redirects to the page given in the form,
+// otherwise it displays the login page again with an error.
+func (c Controller) Login(req *restful.Request, resp *restful.Response,
+	form forms.LoginForm, password string) {
+
+	logger := logging.FromRequest(req.Request)
+
+	user, err := c.services.GetUserRepository().WithContext(req.Request.Context()).Authenticate(form.Username(), password)
+	if err != nil {
+		if err != usersRepo.ErrBadLogin {
+			err = fmt.Errorf("cannot log in - %s", err.Error())
+		}
+		logger.Info("login refused", "username", form.Username(), "error", err)
+		form.SetErrorMessage(err.Error())
+		c.display(req, resp, form)
+		return
+	}
+
+	err = c.services.GetSessions().Login(resp.ResponseWriter, user.Username())
+	if err != nil {
+		form.SetErrorMessage(fmt.Sprintf("cannot log in - %s", err.Error()))
+		c.display(req, resp, form)
+		return
+	}
+	logger.Info("logged in", "username", user.Username())
+
+	next := form.Next()
+	if next == "" {
+		next = DefaultNext
+	}
+	http.Redirect(resp.ResponseWriter, req.Request, next, http.StatusSeeOther)
+}
+
+// Logout logs the user out and redirects to the login page, which says who has
+// logged out.
+func (c Controller) Logout(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	viewer := auth.ViewerFrom(req.Request)
+	c.services.GetSessions().Logout(resp.ResponseWriter)
+	notice := ""
+	if viewer.LoggedIn() {
+		logger.Info("logged out", "username", viewer.Username)
+		notice = fmt.Sprintf("%s has logged out", viewer.Username)
+	}
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
+}
+
+// Unauthorised responds to a request that changes the data when nobody is
+// logged in.  It redirects to the login page.  The request can't be repeated by
+// a redirect, so after logging in the user goes back to the page that they
+// were on.
+func Unauthorised(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteLoginForm
+	referer, err := url.Parse(req.Request.Referer())
+	if err == nil && req.Request.Referer() != "" &&
+		(referer.Host == "" || referer.Host == req.Request.Host) {
+
+		form.SetNext(referer.RequestURI())
+	}
+	location := RootPath
+	if form.Next() != "" {
+		location += "?next=" + url.QueryEscape(form.Next())
+	}
+	http.Redirect(resp.ResponseWriter, req.Request, location, http.StatusSeeOther)
+}
+
+// display displays the login page.
+func (c Controller) display(req *restful.Request, resp *restful.Response,
+	form forms.LoginForm) {
+
+	logger := logging.FromRequest(req.Request)
+	page := c.services.Template("Login")
+	if page == nil {
+		utilities.Dead(resp)
+		return
+	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	err := page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		logger.Error("error displaying the login page", "error", err)
+		utilities.Dead(resp)
+	}
+}
+
+// SetServices sets the services.
+func (c *Controller) SetServices(services services.Services) {
+	c.services = services
+}
@@ -0,0 +1,215 @@
+package login
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/login"
+	mocks "github.com/goblimey/films/mocks/gomock"
+	userModel "github.com/goblimey/films/models/user"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/golang/mock/gomock"
+)
+
+// setUp creates a container holding the login web service, with services that
+// have one user, "alice", whose password is "rosebud99".
+func setUp(t *testing.T, mockTemplate retroTemplate.Template) (*restful.Container, auth.Sessions) {
+	page := map[string]retroTemplate.Template{"Login": mockTemplate}
+	repo := usersRepo.MakeRepo(dbsession.MakeMemoryDBSession())
+	_, err := repo.Add("alice", "rosebud99", userModel.RoleEditor)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	sessions := auth.MakeSessions("", time.Hour, false)
+	var svc services.ConcreteServices
+	svc.SetUserRepository(repo)
+	svc.SetSessions(sessions)
+	svc.SetTemplates(&page)
+	getServices := func() (services.Services, error) {
+		return &svc, nil
+	}
+	container := restful.NewContainer()
+	container.Add(MakeWebService(
+		services.Filter(getServices, func(resp *restful.Response) {
+			resp.WriteHeader(http.StatusServiceUnavailable)
+		}),
+		auth.Filter(sessions, findRole, nil)))
+	return container, sessions
+}
+
+// findRole finds the role of a user from the repository in the services.
+func findRole(req *restful.Request, username string) (string, error) {
+	user, err := services.FromRequest(req).GetUserRepository().FindByUsername(username)
+	if err != nil {
+		return "", err
+	}
+	return user.Role(), nil
+}
+
+// loginRequest makes a request to log in with the given details.
+func loginRequest(username, password, next string) *http.Request {
+	body := url.Values{"username": {username}, "password": {password}, "next": {next}}
+	req := httptest.NewRequest("PUT", RootPath, strings.NewReader(body.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// TestUnitLogin checks that the right password logs the user in and redirects to
+// the next page.
+func TestUnitLogin(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	container, sessions := setUp(t, mocks.NewMockTemplate(mockCtrl))
+
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, loginRequest("alice", "rosebud99", "/people/1/edit"))
+
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, recorder.Code)
+	}
+	if recorder.Header().Get("Location") != "/people/1/edit" {
+		t.Errorf("expected a redirect to /people/1/edit, got %q", recorder.Header().Get("Location"))
+	}
+	req := httptest.NewRequest("GET", "/people", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	if sessions.Username(req) != "alice" {
+		t.Errorf("expected alice to be logged in, got %q", sessions.Username(req))
+	}
+}
+
+// TestUnitLoginFailure checks that a wrong password or an unknown user displays
+// the login page again with an error, and no cookie.
+func TestUnitLoginFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockTemplate := mocks.NewMockTemplate(mockCtrl)
+	container, _ := setUp(t, mockTemplate)
+
+	var tests = []struct {
+		username string
+		password string
+	}{
+		{"alice", "wrong-password"},
+		{"bob", "rosebud99"},
+	}
+
+	for _, test := range tests {
+		var form forms.LoginForm
+		mockTemplate.EXPECT().Execute(gomock.Any(), gomock.Any()).
+			Do(func(w interface{}, data interface{}) {
+				form = data.(forms.LoginForm)
+			}).Return(nil)
+		recorder := httptest.NewRecorder()
+		container.ServeHTTP(recorder, loginRequest(test.username, test.password, "/films"))
+		if form == nil {
+			t.Errorf("%s: expected the login page to be displayed", test.username)
+			continue
+		}
+		if form.ErrorMessage() != usersRepo.ErrBadLogin.Error() {
+			t.Errorf("%s: expected error %q, got %q", test.username,
+				usersRepo.ErrBadLogin.Error(), form.ErrorMessage())
+		}
+		if form.Next() != "/films" {
+			t.Errorf("%s: expected the next page to be kept, got %q", test.username, form.Next())
+		}
+		if len(recorder.Result().Cookies()) != 0 {
+			t.Errorf("%s: expected no cookie", test.username)
+		}
+	}
+}
+
+// TestUnitLogout checks that logging out redirects to the login page, which
+// says who has logged out the first time that it's displayed, and not again.
+func TestUnitLogout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockTemplate := mocks.NewMockTemplate(mockCtrl)
+	container, sessions := setUp(t, mockTemplate)
+
+	recorder := httptest.NewRecorder()
+	sessions.Login(recorder, "alice")
+	req := httptest.NewRequest("DELETE", RootPath, nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	recorder = httptest.NewRecorder()
+	container.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusSeeOther || recorder.Header().Get("Location") != RootPath {
+		t.Fatalf("expected a redirect to %s, got %d %q", RootPath, recorder.Code,
+			recorder.Header().Get("Location"))
+	}
+	var flash *http.Cookie
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == auth.CookieName && cookie.MaxAge >= 0 {
+			t.Errorf("expected the session cookie to be removed")
+		}
+		if cookie.Name == auth.FlashCookieName {
+			flash = cookie
+		}
+	}
+	if flash == nil {
+		t.Fatalf("expected a flash cookie")
+	}
+
+	var notices []string
+	mockTemplate.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			notices = append(notices, data.(forms.LoginForm).Notice())
+		}).Return(nil).Times(2)
+	req = httptest.NewRequest("GET", RootPath, nil)
+	req.AddCookie(flash)
+	recorder = httptest.NewRecorder()
+	container.ServeHTTP(recorder, req)
+	removed := false
+	for _, cookie := range recorder.Result().Cookies() {
+		removed = removed || (cookie.Name == auth.FlashCookieName && cookie.MaxAge < 0)
+	}
+	if !removed {
+		t.Errorf("expected the flash cookie to be removed")
+	}
+	container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", RootPath, nil))
+	if len(notices) != 2 || notices[0] != "alice has logged out" || notices[1] != "" {
+		t.Errorf("expected the notice to be displayed once, got %q", notices)
+	}
+}
+
+// TestUnitUnauthorised checks that a request refused because nobody is logged in
+// is redirected to the login page, which then leads back to the page that the
+// user came from.
+func TestUnitUnauthorised(t *testing.T) {
+	var tests = []struct {
+		referer  string
+		location string
+	}{
+		{"http://example.com/people/1/edit", "/login?next=%2Fpeople%2F1%2Fedit"},
+		{"http://elsewhere.com/people/1/edit", "/login"},
+		{"", "/login"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("PUT", "http://example.com/people/1", nil)
+		if test.referer != "" {
+			req.Header.Set("Referer", test.referer)
+		}
+		recorder := httptest.NewRecorder()
+		Unauthorised(restful.NewRequest(req), restful.NewResponse(recorder))
+		if recorder.Code != http.StatusSeeOther {
+			t.Errorf("%q: expected status %d, got %d", test.referer, http.StatusSeeOther, recorder.Code)
+		}
+		if recorder.Header().Get("Location") != test.location {
+			t.Errorf("%q: expected a redirect to %q, got %q", test.referer, test.location,
+				recorder.Header().Get("Location"))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package login
+
+import (
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/login"
+	"github.com/goblimey/films/services"
+)
+
+// RootPath is the URI of the login page.
+const RootPath = "/login"
+
+// MakeWebService creates the web service that routes requests for the login page
+// to the controller.  The browser sends a PUT or DELETE as a POST with a
+// "_method" parameter.  servicesFilter attaches the services to each request -
+// see services.Filter - and viewerFilter attaches the viewer - see auth.Filter.
+// The viewer filter must let every request through, otherwise nobody could log
+// in.
+func MakeWebService(servicesFilter restful.FilterFunction,
+	viewerFilter restful.FilterFunction) *restful.WebService {
+
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Filter(servicesFilter).Filter(viewerFilter)
+
+	form := "application/x-www-form-urlencoded"
+	ws.Route(ws.GET("").To(show))
+	ws.Route(ws.PUT("").Consumes(form).To(login))
+	ws.Route(ws.DELETE("").Consumes(form).To(logout))
+	return ws
+}
+
+// controller makes a controller using the services attached to the request.
+func controller(req *restful.Request) Controller {
+	return MakeController(services.FromRequest(req))
+}
+
+// show handles "GET /login?next=/people/1/edit" - display the login page.
+func show(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteLoginForm
+	form.SetNext(req.QueryParameter("next"))
+	controller(req).Show(req, resp, &form)
+}
+
+// login handles "PUT /login" - log in with the username and password in the
+// form.
+func login(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteLoginForm
+	form.SetUsername(req.Request.FormValue("username"))
+	form.SetNext(req.Request.FormValue("next"))
+	controller(req).Login(req, resp, &form, req.Request.FormValue("password"))
+}
+
+// logout handles "DELETE /login" - log out.
+func logout(req *restful.Request, resp *restful.Response) {
+	controller(req).Logout(req, resp)
+}
@@ -2,24 +2,42 @@
 // set of action functions that are triggered by HTTP requests and implement the
 // Create, Read, Update and Delete (CRUD) operations on the people resource:
 //
-//    GET people/ - runs Index() to list all people
+//    GET people/ - runs Index() to list the people, a page at a time
 //    GET people/n - runs Show() to display the details of the person with ID n
 //    GET people/create - runs New() to display the page to create a person using any data in the form to pre-populate it
 //    PUT people/n - runs Create() to create a new person using the data in the supplied form
 //    GET people/n/edit - runs Edit() to display the page to edit the person with ID n, using any data in the form to pre-populate it
 //    PUT people/n - runs Update() to update the person with ID n using the data in the form
-//    DELETE people/n - runs Delete() to delete the person with id n
+//    DELETE people/n - runs Delete() to move the person with id n to the trash
+//    PUT people/n/credits - runs AddCredit() to credit the person with ID n on a film
+//    DELETE people/n/credits/c - runs RemoveCredit() to remove the credit with ID c
+//    GET people/n/history - runs History() to display the changes made to the person with ID n
+//    PUT people/n/history/e/revert - runs Revert() to restore the person with ID n to the values in history entry e
+//    GET people/trash - runs Trash() to list the people in the trash
+//    PUT people/trash/n/restore - runs Restore() to take the person with ID n out of the trash
+//    DELETE people/trash/n/purge - runs Purge() to remove the person with ID n from the trash for good
+//    PUT people/n/genres - runs AddGenre() to put the person with ID n into a genre
+//    DELETE people/n/genres/g - runs RemoveGenre() to take the person out of the genre with ID g
+//    PUT people/n/tags - runs AddTag() to tag the person with ID n
+//    DELETE people/n/tags/t - runs RemoveTag() to remove the tag with ID t from the person
 
 package people
 
 import (
 	"fmt"
-	"log"
+	"strconv"
+	"strings"
 
 	restful "github.com/emicklei/go-restful"
+	creditForms "github.com/goblimey/films/forms/credits"
 	forms "github.com/goblimey/films/forms/people"
+	personModel "github.com/goblimey/films/models/person"
+	peopleRepo "github.com/goblimey/films/repositories/people"
 	"github.com/goblimey/films/services"
 	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
 )
 
 type Controller struct {
@@ -33,12 +51,15 @@ func MakeController(services services.Services) Controller {
 	return controller
 }
 
-// Index fetches a list of all valid people and displays the index page.
+// Index fetches a page of valid people, as given by the query in the form, and
+// displays the index page.
 func (c Controller) Index(req *restful.Request, resp *restful.Response,
 	form forms.ListForm) {
 
-	log.SetPrefix("Index()")
-
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
 	listPeople(req, resp, form, c.services)
 	return
 }
@@ -47,16 +68,21 @@ func (c Controller) Index(req *restful.Request, resp *restful.Response,
 func (c Controller) Show(req *restful.Request, resp *restful.Response,
 	form forms.PersonForm) {
 
-	log.SetPrefix("Show()")
+	logger := logging.FromRequest(req.Request)
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
 
-	dao := c.services.GetPeopleRepository()
+	dao := c.services.GetPeopleRepository().WithContext(req.Request.Context())
 
 	// Get the details of the person with the given ID.
 	person, err := dao.FindByID(form.Person().ID())
 	if err != nil {
 		// no such person.  Display index page with error message
 		em := "no such person"
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
@@ -65,18 +91,73 @@ func (c Controller) Show(req *restful.Request, resp *restful.Response,
 	// complete person record that we just fetched.
 	form.SetPerson(person)
 
+	// Add the person's filmography and the films that they could be credited
+	// on.  If either of those fails, display the page anyway, with an error.
+	credits, err := c.services.GetCreditRepository().WithContext(req.Request.Context()).FindByPerson(person.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the credits for the person - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetCredits(credits)
+
+	films, err := c.services.GetFilmRepository().WithContext(req.Request.Context()).FindAll()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of films - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetFilms(films)
+
+	// Add the person's genres and tags and the genres that they could be put
+	// into.
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	genres, err := taxonomyRepo.GenresOf(dbsession.SubjectPerson, person.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the genres of the person - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetGenres(genres)
+
+	tags, err := taxonomyRepo.TagsOf(dbsession.SubjectPerson, person.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the tags of the person - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetTags(tags)
+
+	allGenres, err := taxonomyRepo.FindAllGenres()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of genres - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetAllGenres(allGenres)
+
+	// Add the person's award nominations.
+	awards, err := c.services.GetAwardRepository().WithContext(req.Request.Context()).FindNominationsByPerson(person.ID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the awards of the person - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetAwards(awards)
+
 	page := c.services.Template("Show")
 	if page == nil {
 		em := fmt.Sprintf("internal error displaying Show page - no HTML template")
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
 
+	form.SetViewer(auth.ViewerFrom(req.Request))
 	err = page.Execute(resp.ResponseWriter, form)
 	if err != nil {
 		em := fmt.Sprintf("error displaying page - %s", err.Error())
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
@@ -87,19 +168,24 @@ func (c Controller) Show(req *restful.Request, resp *restful.Response,
 func (c Controller) New(req *restful.Request, resp *restful.Response,
 	form forms.PersonForm) {
 
-	log.SetPrefix("New()")
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "create people") {
+		return
+	}
 
 	// Display the page.
 	page := c.services.Template("Create")
 	if page == nil {
 		em := fmt.Sprintf("internal error displaying Create page - no HTML template")
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
 	err := page.Execute(resp.ResponseWriter, form)
 	if err != nil {
-		log.Printf("error displaying new page - %s", err.Error())
+		logger.Error("error displaying new page", "error", err)
 		em := fmt.Sprintf("error displaying page - %s", err.Error())
 		c.ErrorHandler(req, resp, em)
 		return
@@ -111,22 +197,27 @@ func (c Controller) New(req *restful.Request, resp *restful.Response,
 func (c Controller) Create(req *restful.Request, resp *restful.Response,
 	form forms.PersonForm) {
 
-	log.SetPrefix("Create()")
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "create people") {
+		return
+	}
 
 	if !(form.Validate()) {
 		// validation errors.  Return to create screen with error messages in the form data
 		page := c.services.Template("Create")
 		if page == nil {
 			em := fmt.Sprintf("internal error displaying Create page - no HTML template")
-			log.Printf("%s\n", em)
+			logger.Error(em)
 			c.ErrorHandler(req, resp, em)
 			return
 		}
-		err := page.Execute(resp.ResponseWriter, &form)
+		form.SetViewer(auth.ViewerFrom(req.Request))
+		err := page.Execute(resp.ResponseWriter, form)
 		if err != nil {
 			em := fmt.Sprintf("Internal error while preparing create form after failed validation - %s",
 				err.Error())
-			log.Printf("%s\n", em)
+			logger.Error(em)
 			c.ErrorHandler(req, resp, em)
 			return
 		}
@@ -134,7 +225,7 @@ func (c Controller) Create(req *restful.Request, resp *restful.Response,
 	}
 
 	// Create a person in the database using the validated data in the form
-	dao := c.services.GetPeopleRepository()
+	dao := c.repository(req)
 
 	createdPerson, err := dao.Create(form.Person())
 	if err != nil {
@@ -144,12 +235,11 @@ func (c Controller) Create(req *restful.Request, resp *restful.Response,
 		return
 	}
 
-	// Success! Person created.  Display index page with confirmation notice
+	// Success! Person created.  Redirect to the index page, which displays a
+	// confirmation notice.
 	notice := fmt.Sprintf("created new person %s", createdPerson.String())
-	log.Printf("%s\n", notice)
-	var listForm forms.ConcreteListForm
-	listForm.SetNotice(notice)
-	listPeople(req, resp, &listForm, c.services)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
 	return
 }
 
@@ -158,27 +248,31 @@ func (c Controller) Create(req *restful.Request, resp *restful.Response,
 func (c Controller) Edit(req *restful.Request, resp *restful.Response,
 	form forms.PersonForm) {
 
-	log.SetPrefix("Edit() ")
+	logger := logging.FromRequest(req.Request)
 
-	log.Println("parsing form")
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "edit people") {
+		return
+	}
+
+	logger.Debug("parsing form")
 	err := req.Request.ParseForm()
 	if err != nil {
 		// failed to parse form
 		em := fmt.Sprintf("cannot parse form - %s", err.Error())
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
 	// Get the ID of the person
 	id := req.PathParameter("id")
 
-	dao := c.services.GetPeopleRepository()
+	dao := c.services.GetPeopleRepository().WithContext(req.Request.Context())
 	// Get the existing data for the person
 	person, err := dao.FindByIDStr(id)
 	if err != nil {
 		// No such person.  Display index page with error message.
 		em := err.Error()
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
@@ -189,22 +283,23 @@ func (c Controller) Edit(req *restful.Request, resp *restful.Response,
 	if !form.Validate() {
 		em := fmt.Sprintf("invalid record in the people database - %s",
 			person.String())
-		log.Printf("%s\n", em)
+		logger.Error(em)
 	}
 
 	// Display the edit page
 	page := c.services.Template("Edit")
 	if page == nil {
 		em := fmt.Sprintf("internal error displaying Edit page - no HTML template")
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
-	log.Printf("Execute\n")
+	logger.Debug("executing the edit page")
+	form.SetViewer(auth.ViewerFrom(req.Request))
 	err = page.Execute(resp.ResponseWriter, form)
 	if err != nil {
 		// error while preparing edit page
-		log.Printf("%s: error displaying edit page - %s", err.Error())
+		logger.Error("error displaying edit page", "error", err)
 		em := fmt.Sprintf("error displaying page - %s", err.Error())
 		c.ErrorHandler(req, resp, em)
 	}
@@ -214,24 +309,30 @@ func (c Controller) Edit(req *restful.Request, resp *restful.Response,
 // PUT /people/1
 // It's invoked by the form displayed by a previous Edit request.  If the ID in the URI is
 // valid and the request parameters from the form specify valid people data, it updates the
-// record and displays the index page with a confirmation message, otherwise it displays
-// the edit page again with the given data and some error messages.
+// record and redirects to the index page with a confirmation message, otherwise it displays
+// the edit page again with the given data and some error messages.  If someone else has
+// updated the person since the edit page was displayed, the update is refused and the
+// edit page is displayed again with the current data.
 func (c Controller) Update(req *restful.Request, resp *restful.Response,
 	form forms.PersonForm) {
 
-	log.SetPrefix("Update() ")
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "edit people") {
+		return
+	}
 
 	// Get the person specified in the form from the DB.
 	// (which also validates the id in the form).
-	log.Printf("form=%v\n", form)
+	logger.Debug("form", "form", fmt.Sprint(form))
 	if form.Person() == nil {
 		em := fmt.Sprint("internal error - form should contain an updated person record")
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
 
-	dao := c.services.GetPeopleRepository()
+	dao := c.repository(req)
 	person, err := dao.FindByID(form.Person().ID())
 	if err != nil {
 		// There is no person with this ID.  The ID is chosen by the user from a
@@ -239,13 +340,13 @@ func (c Controller) Update(req *restful.Request, resp *restful.Response,
 		// going on.  Display the index page with an error message.
 		em := fmt.Sprintf("error searching for person with id %s - %s",
 			form.Person().ID(), err.Error())
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
 
 	// We have a matching person from the DB.
-	log.Printf("got person %v\n", person)
+	logger.Debug("got person", "person", person.String())
 
 	// Validate the new version of the person in the form.
 	if !form.Validate() {
@@ -254,13 +355,14 @@ func (c Controller) Update(req *restful.Request, resp *restful.Response,
 		page := c.services.Template("Edit")
 		if page == nil {
 			em := fmt.Sprintf("internal error displaying Edit page - no HTML template")
-			log.Printf("%s\n", em)
+			logger.Error(em)
 			c.ErrorHandler(req, resp, em)
 			return
 		}
+		form.SetViewer(auth.ViewerFrom(req.Request))
 		err = page.Execute(resp.ResponseWriter, form)
 		if err != nil {
-			log.Printf("%s: error displaying edit page - %s", err.Error())
+			logger.Error("error displaying edit page", "error", err)
 			em := fmt.Sprintf("error displaying page - %s", err.Error())
 			c.ErrorHandler(req, resp, em)
 			return
@@ -268,55 +370,112 @@ func (c Controller) Update(req *restful.Request, resp *restful.Response,
 		return
 	}
 
+	// The form holds the version of the person that was edited.  If the
+	// record has changed since then, don't overwrite the other changes.
+	if form.Person().Version() != person.Version() {
+		c.editConflict(req, resp, form, person)
+		return
+	}
+
 	// we have a valid record and valid new values.  Update.
 	person.SetForename(form.Person().Forename())
 	person.SetSurname(form.Person().Surname())
-	log.Printf("updating person to %v\n", person)
+	logger.Debug("updating person", "person", person.String())
 	_, err = dao.Update(person)
+	if err == peopleRepo.ErrConflict {
+		// Someone else got in between the read and the update.
+		current, err := dao.FindByID(person.ID())
+		if err != nil {
+			em := fmt.Sprintf("error searching for person with id %d - %s",
+				person.ID(), err.Error())
+			logger.Error(em)
+			c.ErrorHandler(req, resp, em)
+			return
+		}
+		c.editConflict(req, resp, form, current)
+		return
+	}
 	if err != nil {
 		// The commit failed.  Display the edit page with an error message
 		em := fmt.Sprintf("Could not update person - %s", err.Error())
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		form.SetErrorMessage(em)
 
 		page := c.services.Template("Edit")
 		if page == nil {
 			em := fmt.Sprintf("internal error displaying Edit page - no HTML template")
-			log.Printf("%s\n", em)
+			logger.Error(em)
 			c.ErrorHandler(req, resp, em)
 			return
 		}
+		form.SetViewer(auth.ViewerFrom(req.Request))
 		err = page.Execute(resp.ResponseWriter, form)
 		if err != nil {
 			// Error while recovering from another error.  This is looking like a habit!
 			em := fmt.Sprintf("Internal error while preparing edit page after failing to update person in DB - %s", err.Error())
-			log.Printf("%s\n", em)
+			logger.Error(em)
 			c.ErrorHandler(req, resp, em)
 		} else {
 			return
 		}
 	}
 
-	// Success!  Display the index page with a confirmation notice
+	// Success!  Redirect to the index page, which displays a confirmation
+	// notice.
 	notice := fmt.Sprintf("updated person %s", form.Person().String())
-	log.Printf("%s:\n", notice)
-	var listForm forms.ConcreteListForm
-	listForm.SetNotice(notice)
-	listPeople(req, resp, &listForm, c.services)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
 	return
 }
 
-// Delete reponds to a DELETE request and deletes the record with the given ID,
-// eg DELETE http://server:port/people/1.
+// editConflict displays the edit page again after an update is refused because
+// someone else has changed the person.  The page shows the current data, so that
+// the user can make their changes again, and the error message shows the values
+// that they entered.
+func (c Controller) editConflict(req *restful.Request, resp *restful.Response,
+	form forms.PersonForm, current personModel.Person) {
+
+	logger := logging.FromRequest(req.Request)
+	em := fmt.Sprintf("%s %s was changed by someone else while you were editing.  "+
+		"Your changes (forename %s, surname %s) have not been saved.  "+
+		"The current details are shown below.",
+		current.Forename(), current.Surname(),
+		form.Person().Forename(), form.Person().Surname())
+	logger.Error(em)
+	form.SetPerson(current)
+	form.SetErrorMessage(em)
+
+	page := c.services.Template("Edit")
+	if page == nil {
+		em := fmt.Sprintf("internal error displaying Edit page - no HTML template")
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	err := page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		em := fmt.Sprintf("error displaying page - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+	}
+}
+
+// Delete reponds to a DELETE request and moves the person with the given ID to the
+// trash, eg DELETE http://server:port/people/1.
 func (c Controller) Delete(req *restful.Request, resp *restful.Response) {
 
-	log.SetPrefix("Delete()")
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanDelete, "delete people") {
+		return
+	}
 
 	err := req.Request.ParseForm()
 	if err != nil {
 		// failed - form does not parse
 		em := fmt.Sprintf("Internal error - %s", err.Error())
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
@@ -324,31 +483,536 @@ func (c Controller) Delete(req *restful.Request, resp *restful.Response) {
 	if "DELETE" != method {
 		// failed - _method param is not DELETE
 		em := fmt.Sprintf("Internal error - request type %s must be DELETE", method)
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
 	id := req.PathParameter("id")
 
-	dao := c.services.GetPeopleRepository()
+	dao := c.repository(req)
 	// Attempt the delete
 	_, err = dao.DeleteByIDStr(id)
 	if err != nil {
 		// failed - cannot delete person
 		em := fmt.Sprintf("Cannot delete person with id %s - %s", id, err.Error())
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		c.ErrorHandler(req, resp, em)
 		return
 	}
-	// Success - person deleted.  Display the index view with a notification.
-	var form forms.ConcreteListForm
-	notice := fmt.Sprintf("deleted person with ID %s", id)
-	log.Printf("%s:\n", notice)
-	form.SetNotice(notice)
-	listPeople(req, resp, &form, c.services)
+	// Success - person deleted.  Redirect to the index view, which displays a
+	// notification.
+	notice := fmt.Sprintf("moved person with ID %s to the trash", id)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
 	return
 }
 
+// AddCredit responds to a PUT request such as PUT /people/1/credits.  It credits
+// the person on the film, in the role given in the form.  If that works, it
+// redirects to the person's page, which displays a notice, otherwise it displays
+// the person's page again with an error message.
+func (c Controller) AddCredit(req *restful.Request, resp *restful.Response,
+	form creditForms.CreditForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change credits") {
+		return
+	}
+
+	personID := form.Credit().PersonID()
+
+	if !form.Validate() {
+		// The credit is invalid.  Display the person's page with the errors.
+		em := fmt.Sprintf("cannot add the credit - %s",
+			creditForms.FieldErrorSummary(form))
+		logger.Error(em)
+		c.showPerson(req, resp, personID, "", em)
+		return
+	}
+
+	// Check that the film exists before crediting anybody on it.
+	film, err := c.services.GetFilmRepository().WithContext(req.Request.Context()).FindByID(form.Credit().FilmID())
+	if err != nil {
+		em := fmt.Sprintf("cannot add the credit - no film with ID %d",
+			form.Credit().FilmID())
+		logger.Error(em)
+		c.showPerson(req, resp, personID, "", em)
+		return
+	}
+
+	_, err = c.services.GetCreditRepository().WithContext(req.Request.Context()).Create(form.Credit())
+	if err != nil {
+		em := fmt.Sprintf("Could not add the credit - %s", err.Error())
+		logger.Error(em)
+		c.showPerson(req, resp, personID, "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("credited as %s on %s", form.Credit().Role(), film.Title())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), personPath(personID), notice)
+}
+
+// RemoveCredit responds to a DELETE request such as DELETE /people/1/credits/2.
+// It removes the credit with the given ID from the person with the given ID and
+// redirects to the person's page.
+func (c Controller) RemoveCredit(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change credits") {
+		return
+	}
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		// failed - form does not parse
+		em := fmt.Sprintf("Internal error - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	method := req.Request.FormValue("_method")
+	if "DELETE" != method {
+		// failed - _method param is not DELETE
+		em := fmt.Sprintf("Internal error - request type %s must be DELETE", method)
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	person, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindByIDStr(req.PathParameter("id"))
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove credit - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	creditRepo := c.services.GetCreditRepository().WithContext(req.Request.Context())
+	creditID := req.PathParameter("creditID")
+	credit, err := creditRepo.FindByIDStr(creditID)
+	if err != nil || credit.PersonID() != person.ID() {
+		// The credit does not exist or belongs to somebody else.
+		em := fmt.Sprintf("Cannot remove credit - the person has no credit with ID %s",
+			creditID)
+		logger.Error(em)
+		c.showPerson(req, resp, person.ID(), "", em)
+		return
+	}
+
+	_, err = creditRepo.DeleteByID(credit.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove credit with ID %s - %s", creditID, err.Error())
+		logger.Error(em)
+		c.showPerson(req, resp, person.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed credit as %s on %s", credit.Role(), credit.FilmTitle())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), personPath(person.ID()), notice)
+}
+
+// AddGenre responds to a PUT request such as PUT /people/1/genres.  It puts the
+// person into the genre given by the genreID in the form data and redirects to
+// the person's page, or displays the person's page again with an error message.
+func (c Controller) AddGenre(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change genres and tags") {
+		return
+	}
+
+	person := c.findPerson(req, resp, "Cannot add genre")
+	if person == nil {
+		return
+	}
+
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	genreIDStr := req.Request.FormValue("genreID")
+	genreID, _ := strconv.ParseUint(genreIDStr, 10, 64)
+	genre, err := taxonomyRepo.FindGenreByID(genreID)
+	if err != nil {
+		em := fmt.Sprintf("Cannot add genre - no genre with ID %s", genreIDStr)
+		logger.Error(em)
+		c.showPerson(req, resp, person.ID(), "", em)
+		return
+	}
+
+	err = taxonomyRepo.AddGenreTo(genre.ID(), dbsession.SubjectPerson, person.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot add genre %s - %s", genre.Path(), err.Error())
+		logger.Error(em)
+		c.showPerson(req, resp, person.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("added %s %s to genre %s", person.Forename(), person.Surname(),
+		genre.Path())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), personPath(person.ID()), notice)
+}
+
+// RemoveGenre responds to a DELETE request such as DELETE /people/1/genres/2.  It
+// takes the person out of the genre with the given ID and redirects to the
+// person's page.
+func (c Controller) RemoveGenre(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change genres and tags") {
+		return
+	}
+
+	person := c.findPersonToDelete(req, resp, "Cannot remove genre")
+	if person == nil {
+		return
+	}
+
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	genreIDStr := req.PathParameter("genreID")
+	genreID, _ := strconv.ParseUint(genreIDStr, 10, 64)
+	err := taxonomyRepo.RemoveGenreFrom(genreID, dbsession.SubjectPerson, person.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove genre with ID %s - %s", genreIDStr, err.Error())
+		logger.Error(em)
+		c.showPerson(req, resp, person.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed %s %s from genre with ID %s", person.Forename(),
+		person.Surname(), genreIDStr)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), personPath(person.ID()), notice)
+}
+
+// AddTag responds to a PUT request such as PUT /people/1/tags.  It tags the person
+// with the name given in the form data, creating the tag if nobody has used it
+// before, and redirects to the person's page, or displays the person's page
+// again with an error message.
+func (c Controller) AddTag(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change genres and tags") {
+		return
+	}
+
+	person := c.findPerson(req, resp, "Cannot add tag")
+	if person == nil {
+		return
+	}
+
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	name := strings.TrimSpace(req.Request.FormValue("name"))
+	tag, err := taxonomyRepo.AddTagTo(name, dbsession.SubjectPerson, person.ID())
+	if err != nil {
+		em := fmt.Sprintf("Cannot add tag %q - %s", name, err.Error())
+		logger.Error(em)
+		c.showPerson(req, resp, person.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("tagged %s %s with %s", person.Forename(), person.Surname(),
+		tag.Name())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), personPath(person.ID()), notice)
+}
+
+// RemoveTag responds to a DELETE request such as DELETE /people/1/tags/2.  It
+// removes the tag with the given ID from the person and redirects to the
+// person's page.
+func (c Controller) RemoveTag(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "change genres and tags") {
+		return
+	}
+
+	person := c.findPersonToDelete(req, resp, "Cannot remove tag")
+	if person == nil {
+		return
+	}
+
+	taxonomyRepo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	tagIDStr := req.PathParameter("tagID")
+	tag, err := taxonomyRepo.FindTagByIDStr(tagIDStr)
+	if err == nil {
+		err = taxonomyRepo.RemoveTagFrom(tag.ID(), dbsession.SubjectPerson, person.ID())
+	}
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove tag with ID %s - %s", tagIDStr, err.Error())
+		logger.Error(em)
+		c.showPerson(req, resp, person.ID(), "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed tag %s from %s %s", tag.Name(), person.Forename(),
+		person.Surname())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), personPath(person.ID()), notice)
+}
+
+// History displays the history of the person with the ID given in the form - the
+// changes recorded in the audit log, newest first.  The history of a deleted
+// person can still be displayed.
+func (c Controller) History(req *restful.Request, resp *restful.Response,
+	form forms.HistoryForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	dao := c.services.GetPeopleRepository().WithContext(req.Request.Context())
+	entries, err := dao.History(form.PersonID())
+	if err != nil {
+		em := fmt.Sprintf("error getting the history of person %d - %s",
+			form.PersonID(), err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	person, err := dao.FindByID(form.PersonID())
+	if err != nil {
+		// Deleted, or never existed.
+		person = nil
+		if len(entries) == 0 {
+			em := "no such person"
+			logger.Error(em)
+			c.ErrorHandler(req, resp, em)
+			return
+		}
+	}
+	form.SetEntries(entries)
+	form.SetPerson(person)
+
+	page := c.services.Template("History")
+	if page == nil {
+		em := fmt.Sprintf("internal error displaying History page - no HTML template")
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	err = page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		em := fmt.Sprintf("error displaying page - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+	}
+}
+
+// Revert responds to a PUT request such as PUT /people/1/history/5/revert.  It
+// restores person 1 to the values recorded in entry 5 of their history and then
+// redirects to the person's page.  The form data holds the version of the person
+// that the user saw.  If the person has been changed since, or the revert fails
+// for any other reason, it displays the history page again with an error.
+func (c Controller) Revert(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "revert people") {
+		return
+	}
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("Internal error - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	// The routes only match digits, so the IDs can only be too big.
+	id, err := strconv.ParseUint(req.PathParameter("id"), 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("illegal id %s", req.PathParameter("id"))
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	entryID, err := strconv.ParseUint(req.PathParameter("entryID"), 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("illegal history entry %s", req.PathParameter("entryID"))
+		logger.Error(em)
+		c.showHistory(req, resp, id, "", em)
+		return
+	}
+	// A missing version never matches, so the revert is refused.
+	version, _ := strconv.ParseInt(req.Request.FormValue("version"), 10, 64)
+
+	person, err := c.repository(req).Revert(id, entryID, version)
+	if err == peopleRepo.ErrConflict {
+		em := "the person was changed by someone else while you were looking at their " +
+			"history.  The history below includes the change.  Nothing has been reverted."
+		logger.Error(em)
+		c.showHistory(req, resp, id, "", em)
+		return
+	}
+	if err != nil {
+		em := fmt.Sprintf("Could not revert person - %s", err.Error())
+		logger.Error(em)
+		c.showHistory(req, resp, id, "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("reverted %s %s to the values in entry %d of their history",
+		person.Forename(), person.Surname(), entryID)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), personPath(id), notice)
+}
+
+// Trash displays the people in the trash, most recently deleted first, with the
+// time that each of them will be purged.
+func (c Controller) Trash(req *restful.Request, resp *restful.Response,
+	form forms.TrashForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+
+	people, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindTrash()
+	if err != nil {
+		em := fmt.Sprintf("error getting the people in the trash - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	} else if len(people) == 0 && form.Notice() == "" {
+		form.SetNotice("the trash is empty")
+	}
+	form.SetPeople(people)
+	form.SetRetention(c.services.GetTrashRetention())
+
+	page := c.services.Template("Trash")
+	if page == nil {
+		em := fmt.Sprintf("internal error displaying Trash page - no HTML template")
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	err = page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		em := fmt.Sprintf("error displaying page - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+	}
+}
+
+// Restore responds to a PUT request such as PUT /people/trash/1/restore.  It takes
+// person 1 out of the trash and redirects to their page.  If that fails, it displays
+// the trash page again with an error.
+func (c Controller) Restore(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanDelete,
+		"restore people from the trash") {
+
+		return
+	}
+
+	// The route only matches digits, so the ID can only be too big.
+	id, err := strconv.ParseUint(req.PathParameter("id"), 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("illegal id %s", req.PathParameter("id"))
+		logger.Error(em)
+		c.showTrash(req, resp, "", em)
+		return
+	}
+
+	person, err := c.repository(req).Restore(id)
+	if err != nil {
+		em := fmt.Sprintf("Could not restore person with ID %d - %s", id, err.Error())
+		logger.Error(em)
+		c.showTrash(req, resp, "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("restored %s %s from the trash", person.Forename(), person.Surname())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), personPath(id), notice)
+}
+
+// Purge responds to a DELETE request such as DELETE /people/trash/1/purge.  It
+// removes person 1 and their credits for good and redirects to the trash page.
+func (c Controller) Purge(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanDelete,
+		"purge people from the trash") {
+
+		return
+	}
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("Internal error - %s", err.Error())
+		logger.Error(em)
+		c.showTrash(req, resp, "", em)
+		return
+	}
+	method := req.Request.FormValue("_method")
+	if "DELETE" != method {
+		em := fmt.Sprintf("Internal error - request type %s must be DELETE", method)
+		logger.Error(em)
+		c.showTrash(req, resp, "", em)
+		return
+	}
+	id, err := strconv.ParseUint(req.PathParameter("id"), 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("illegal id %s", req.PathParameter("id"))
+		logger.Error(em)
+		c.showTrash(req, resp, "", em)
+		return
+	}
+
+	_, err = c.repository(req).Purge(id)
+	if err != nil {
+		em := fmt.Sprintf("Could not purge person with ID %d - %s", id, err.Error())
+		logger.Error(em)
+		c.showTrash(req, resp, "", em)
+		return
+	}
+
+	notice := fmt.Sprintf("purged person with ID %d", id)
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath+"/trash", notice)
+}
+
+// showTrash displays the trash page with a notice and an error message, either
+// of which may be empty.
+func (c Controller) showTrash(req *restful.Request, resp *restful.Response,
+	notice string, errorMessage string) {
+
+	var form forms.ConcreteTrashForm
+	form.SetNotice(notice)
+	form.SetErrorMessage(errorMessage)
+	c.Trash(req, resp, &form)
+}
+
+// showHistory displays the history page for the person with the given ID, with a
+// notice and an error message, either of which may be empty.
+func (c Controller) showHistory(req *restful.Request, resp *restful.Response,
+	personID uint64, notice string, errorMessage string) {
+
+	var form forms.ConcreteHistoryForm
+	form.SetPersonID(personID)
+	form.SetNotice(notice)
+	form.SetErrorMessage(errorMessage)
+	c.History(req, resp, &form)
+}
+
+// repository returns the people repository, set up to record the maker of the
+// request in the audit log.
+func (c Controller) repository(req *restful.Request) peopleRepo.Repository {
+	return c.services.GetPeopleRepository().WithContext(req.Request.Context()).WithUser(utilities.Requester(req.Request))
+}
+
 // ErrorHandler displays the index page with an error message
 func (c Controller) ErrorHandler(req *restful.Request, resp *restful.Response,
 	errormessage string) {
@@ -363,6 +1027,70 @@ func (c *Controller) SetServices(services services.Services) {
 	c.services = services
 }
 
+// personPath returns the URI of the page for the person with the given ID.
+func personPath(id uint64) string {
+	return fmt.Sprintf("%s/%d", RootPath, id)
+}
+
+// showPerson displays the page for the person with the given ID, with a notice
+// and an error message, either of which may be empty.
+func (c Controller) showPerson(req *restful.Request, resp *restful.Response,
+	personID uint64, notice string, errorMessage string) {
+
+	var form forms.ConcretePersonForm
+	person := personModel.MakePerson()
+	person.SetID(personID)
+	form.SetPerson(person)
+	form.SetNotice(notice)
+	form.SetErrorMessage(errorMessage)
+	c.Show(req, resp, &form)
+}
+
+// findPerson parses the form data and fetches the person with the ID given in the
+// URI.  If either fails, it displays the index page with an error message that
+// starts with the given text and returns nil.
+func (c Controller) findPerson(req *restful.Request, resp *restful.Response,
+	what string) personModel.Person {
+
+	logger := logging.FromRequest(req.Request)
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("Internal error - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+
+	person, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindByIDStr(req.PathParameter("id"))
+	if err != nil {
+		em := fmt.Sprintf("%s - %s", what, err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+	return person
+}
+
+// findPersonToDelete is findPerson for a request that deletes something, which
+// must come with a _method parameter of DELETE.
+func (c Controller) findPersonToDelete(req *restful.Request, resp *restful.Response,
+	what string) personModel.Person {
+
+	person := c.findPerson(req, resp, what)
+	if person == nil {
+		return nil
+	}
+	method := req.Request.FormValue("_method")
+	if "DELETE" != method {
+		// failed - _method param is not DELETE
+		em := fmt.Sprintf("Internal error - request type %s must be DELETE", method)
+		logging.FromRequest(req.Request).Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+	return person
+}
+
 /*
  * The listPeople helper function fetches a list of people and displays the
  * index page.  It's used to fulfil an index request but the index page is
@@ -374,22 +1102,45 @@ func (c *Controller) SetServices(services services.Services) {
 func listPeople(req *restful.Request, resp *restful.Response, form forms.ListForm,
 	services services.Services) {
 
-	log.SetPrefix("Controller.listPeople() ")
+	logger := logging.FromRequest(req.Request)
 
-	dao := services.GetPeopleRepository()
+	dao := services.GetPeopleRepository().WithContext(req.Request.Context())
 
-	peopleList, err := dao.FindAll()
+	// Fetch the page of people given by the query in the form.
+	query := form.Query()
+	peopleList, total, err := dao.FindPage(query.PeopleQuery())
 	if err != nil {
 		em := fmt.Sprintf("error getting the list of people - %s", err.Error())
-		log.Printf("%s\n", em)
+		logger.Error(em)
 		form.SetErrorMessage(em)
 	} else {
-		log.Printf("%d people", len(peopleList))
-		if len(peopleList) <= 0 {
-			form.SetNotice("there are no people currently set up")
+		logger.Debug("found people", "count", len(peopleList), "total", total)
+		if total <= 0 && form.Notice() == "" {
+			if query.Name != "" {
+				form.SetNotice(fmt.Sprintf("there are no people matching \"%s\"", query.Name))
+			} else if query.Genre != 0 || query.Tag != 0 {
+				form.SetNotice("no people match the filter")
+			} else {
+				form.SetNotice("there are no people currently set up")
+			}
 		}
 	}
 	form.SetPeople(peopleList)
+	form.SetTotal(total)
+
+	// Add the genres and tags that the list can be filtered on.  If that fails,
+	// the page can still be displayed without them.
+	taxonomyRepo := services.GetTaxonomyRepository().WithContext(req.Request.Context())
+	genres, err := taxonomyRepo.FindAllGenres()
+	if err != nil {
+		logger.Error("error getting the list of genres", "error", err)
+	}
+	form.SetGenres(genres)
+	tags, err := taxonomyRepo.FindAllTags()
+	if err != nil {
+		logger.Error("error getting the list of tags", "error", err)
+	}
+	form.SetTags(tags)
 
 	// Display the index page
 	page := services.Template("Index")
@@ -397,6 +1148,7 @@ func listPeople(req *restful.Request, resp *restful.Response, form forms.ListFor
 		utilities.Dead(resp)
 		return
 	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
 	err = page.Execute(resp.ResponseWriter, form)
 	if err != nil {
 		/*
@@ -404,7 +1156,7 @@ func listPeople(req *restful.Request, resp *restful.Response, form forms.ListFor
 		 * errors by displaying the index page.  That's just failed, so
 		 * fall back to the static error page.
 		 */
-		log.Printf(err.Error())
+		logger.Error(err.Error())
 		page = services.Template("Error")
 		if page == nil {
 			utilities.Dead(resp)
@@ -414,7 +1166,7 @@ func listPeople(req *restful.Request, resp *restful.Response, form forms.ListFor
 		if err != nil {
 			// Can't display the static error page either.  Bale out.
 			em := fmt.Sprintf("fatal error - failed to display error page for error %s\n", err.Error())
-			log.Printf(em)
+			logger.Error(em)
 			panic(em)
 		}
 		return
@@ -3,12 +3,14 @@ package people
 import (
 	"errors"
 	"fmt"
-	// "html/template"
+	"html/template"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	restful "github.com/emicklei/go-restful"
 	peopleForms "github.com/goblimey/films/forms/people"
@@ -16,8 +18,15 @@ import (
 	"github.com/goblimey/films/mocks/manual"
 	pemocks "github.com/goblimey/films/mocks/pegomock"
 	personModel "github.com/goblimey/films/models/person"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+	userModel "github.com/goblimey/films/models/user"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	taxonomyRepo "github.com/goblimey/films/repositories/taxonomy"
 	retroTemplate "github.com/goblimey/films/retrofit/template"
 	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/csrf"
+	"github.com/goblimey/films/utilities/dbsession"
 	"github.com/golang/mock/gomock"
 	"github.com/petergtz/pegomock"
 )
@@ -25,7 +34,7 @@ import (
 var panicValue string
 
 // TestUnitIndexWithOnePersonPE checks that PeopleHandler.Index() handles a list of
-// people from FindAll() containing one person.  It uses pegomock to create a mock
+// people from FindPage() containing one person.  It uses pegomock to create a mock
 // template.
 func TestUnitIndexWithOnePersonPE(t *testing.T) {
 
@@ -58,6 +67,7 @@ func TestUnitIndexWithOnePersonPE(t *testing.T) {
 	// Create a service that returns the mock repository and templates.
 	var services services.ConcreteServices
 	services.SetPeopleRepository(&mockRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
 	services.SetTemplates(&page)
 
 	// Create the form
@@ -105,7 +115,7 @@ func TestUnitIndexWithOnePersonPE(t *testing.T) {
 }
 
 // TestUnitIndexWithOnePerson checks that PeopleHandler.Index() handles a list of
-// people from FindAll() containing one person.  This is the same as the previous
+// people from FindPage() containing one person.  This is the same as the previous
 // test, but uses gomock rather than pegomock.
 func TestUnitIndexWithOnePerson(t *testing.T) {
 
@@ -139,6 +149,7 @@ func TestUnitIndexWithOnePerson(t *testing.T) {
 	// Create a service that returns the mock repository and templates.
 	var services services.ConcreteServices
 	services.SetPeopleRepository(&mockRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
 	services.SetTemplates(&page)
 
 	// Create the form
@@ -185,8 +196,145 @@ func TestUnitIndexWithOnePerson(t *testing.T) {
 	}
 }
 
+// TestUnitCreateWithMemoryRepo checks that PeopleHandler.Create() stores the person
+// and then redirects to the index page, which contains them and the notice.  It
+// uses the in-memory repository rather than a mock, so the person really is stored
+// and fetched back.
+func TestUnitCreateWithMemoryRepo(t *testing.T) {
+
+	expectedForename := "foo"
+	expectedSurname := "bar"
+
+	// Create the mocks and dummy objects.
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var url url.URL
+	url.Opaque = "/people" // url.RequestURI() will return "/people"
+	var httpRequest http.Request
+	httpRequest.URL = &url
+	httpRequest.Method = "POST"
+	var request restful.Request
+	request.Request = auth.WithViewer(&httpRequest,
+		auth.Viewer{Username: "alice", Role: userModel.RoleEditor})
+	recorder := httptest.NewRecorder()
+	var response restful.Response
+	response.ResponseWriter = recorder
+	mockTemplate := mocks.NewMockTemplate(mockCtrl)
+	page := make(map[string]retroTemplate.Template)
+	page["Index"] = mockTemplate
+
+	// Create a service that returns the in-memory repository, the templates and
+	// the sessions, which carry the notice across the redirect.
+	repo := peopleRepo.MakeMemoryRepo()
+	var services services.ConcreteServices
+	services.SetPeopleRepository(repo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
+	services.SetTemplates(&page)
+	services.SetSessions(auth.MakeSessions("", time.Hour, false))
+
+	// Create the form containing the new person.
+	var form peopleForms.ConcretePersonForm
+	form.SetPerson(gorpPersonModel.MakeInitialisedPerson(0, expectedForename, expectedSurname))
+
+	// Run the test.
+	controller := MakeController(&services)
+	controller.Create(&request, &response, &form)
+
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status %d, got %d", http.StatusSeeOther, recorder.Code)
+	}
+	if recorder.Header().Get("Location") != RootPath {
+		t.Errorf("Expected a redirect to %s, got %s", RootPath, recorder.Header().Get("Location"))
+	}
+
+	// Follow the redirect with the cookies.  Expect the index page to be
+	// displayed and capture the list form.
+	var listForm peopleForms.ListForm
+	mockTemplate.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			listForm = data.(peopleForms.ListForm)
+		}).Return(nil)
+	indexRequest := httptest.NewRequest("GET", RootPath, nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		indexRequest.AddCookie(cookie)
+	}
+	var indexResponse restful.Response
+	indexResponse.ResponseWriter = httptest.NewRecorder()
+	controller.Index(restful.NewRequest(indexRequest), &indexResponse,
+		&peopleForms.ConcreteListForm{})
+
+	if listForm == nil {
+		t.Fatalf("Expected the index page to be displayed")
+	}
+
+	if !strings.HasPrefix(listForm.Notice(), "created new person") {
+		t.Errorf("Expected the notice to be carried across, got %q", listForm.Notice())
+	}
+
+	if len(listForm.People()) != 1 {
+		t.Fatalf("Expected a list of 1, got %d", len(listForm.People()))
+	}
+
+	if listForm.People()[0].ID() != 1 {
+		t.Errorf("Expected ID 1, got %d", listForm.People()[0].ID())
+	}
+
+	if listForm.People()[0].Forename() != expectedForename {
+		t.Errorf("Expected forename %s, got %s",
+			expectedForename, listForm.People()[0].Forename())
+	}
+
+	if listForm.People()[0].Surname() != expectedSurname {
+		t.Errorf("Expected surname %s, got %s",
+			expectedSurname, listForm.People()[0].Surname())
+	}
+
+	// The person should also be in the repository.
+	person, err := repo.FindByID(1)
+	if err != nil {
+		t.Fatalf("Expected to find the person, got error %s", err.Error())
+	}
+	if person.Surname() != expectedSurname {
+		t.Errorf("Expected surname %s, got %s", expectedSurname, person.Surname())
+	}
+}
+
+// TestUnitCreateInvalidKeepsToken checks that when PeopleHandler.Create() finds
+// errors in the form and displays the create page again, the page still carries
+// the CSRF token, so the corrected form can be submitted.
+func TestUnitCreateInvalidKeepsToken(t *testing.T) {
+
+	page := map[string]retroTemplate.Template{
+		"Create": csrf.MakeTemplate(template.Must(template.New("Create").Parse(
+			"<form action='/people' method='post'></form>"))),
+	}
+	var services services.ConcreteServices
+	services.SetPeopleRepository(peopleRepo.MakeMemoryRepo())
+	services.SetTemplates(&page)
+	controller := MakeController(&services)
+
+	httpRequest := httptest.NewRequest("POST", RootPath, nil)
+	request := restful.NewRequest(auth.WithViewer(httpRequest,
+		auth.Viewer{Username: "alice", Role: userModel.RoleEditor, Token: "abc"}))
+	recorder := httptest.NewRecorder()
+	var response restful.Response
+	response.ResponseWriter = recorder
+
+	// The person has no forename, so the form is not valid.
+	var form peopleForms.ConcretePersonForm
+	form.SetPerson(gorpPersonModel.MakeInitialisedPerson(0, "", "Welles"))
+
+	controller.Create(request, &response, &form)
+
+	want := "<input name='" + csrf.FieldName + "' value='abc' type='hidden'/>"
+	if !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("Expected the page to contain %q, got %q", want, recorder.Body.String())
+	}
+}
+
 // TestUnitIndexWithErrorWhenFetchingPeoplePE checks that PeopleHandler.Index()
-// handles errors from FindAll() correctly.  It uses pegomock to provide the
+// handles errors from FindPage() correctly.  It uses pegomock to provide the
 // mocked template.
 func TestUnitIndexWithErrorWhenFetchingPeoplePE(t *testing.T) {
 
@@ -217,6 +365,7 @@ func TestUnitIndexWithErrorWhenFetchingPeoplePE(t *testing.T) {
 	// Create a service that returns the mock repository and templates.
 	var services services.ConcreteServices
 	services.SetPeopleRepository(&mockRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
 	services.SetTemplates(&page)
 
 	// Create the form
@@ -243,7 +392,7 @@ func TestUnitIndexWithErrorWhenFetchingPeoplePE(t *testing.T) {
 }
 
 // TestUnitIndexWithErrorWhenFetchingPeoplePE checks that PeopleHandler.Index()
-// handles errors from FindAll() correctly.  This is the same as the previous test
+// handles errors from FindPage() correctly.  This is the same as the previous test
 // except that uses gomock to provide the mocked template.
 func TestUnitIndexWithErrorWhenFetchingPeople(t *testing.T) {
 
@@ -277,12 +426,14 @@ func TestUnitIndexWithErrorWhenFetchingPeople(t *testing.T) {
 	response.ResponseWriter = mockResponseWriter
 	mockTemplate := mocks.NewMockTemplate(mockCtrl)
 	var mockRepo = mocks.NewMockRepository(mockCtrl)
+	mockRepo.EXPECT().WithContext(gomock.Any()).Return(mockRepo).AnyTimes()
 	page := make(map[string]retroTemplate.Template)
 	page["Index"] = mockTemplate
 
 	// Create a service that returns the mock repository and templates.
 	var services services.ConcreteServices
 	services.SetPeopleRepository(mockRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
 	services.SetTemplates(&page)
 
 	// Create the form
@@ -291,8 +442,8 @@ func TestUnitIndexWithErrorWhenFetchingPeople(t *testing.T) {
 	// The request supplies method "GET" and URI "/people".  Expect
 	// template.Execute to be called and return the expected error.  Expect
 	// Execute to be called and return no error.  Expect the form to contain the
-	// error message from FindAll and a nil list of people.
-	mockRepo.EXPECT().FindAll().Return(nil, expectedErr)
+	// error message from FindPage and a nil list of people.
+	mockRepo.EXPECT().FindPage(gomock.Any()).Return(nil, int64(0), expectedErr)
 	mockTemplate.EXPECT().Execute(mockResponseWriter, &form).Return(nil)
 
 	// Run the test.
@@ -338,6 +489,7 @@ func TestUnitIndexWithManyFailures(t *testing.T) {
 	mockTemplate := mocks.NewMockTemplate(mockCtrl)
 	mockErrorTemplate := mocks.NewMockTemplate(mockCtrl)
 	var mockRepo = mocks.NewMockRepository(mockCtrl)
+	mockRepo.EXPECT().WithContext(gomock.Any()).Return(mockRepo).AnyTimes()
 
 	// Create a template map containing the mock templates
 	page := make(map[string]retroTemplate.Template)
@@ -347,12 +499,13 @@ func TestUnitIndexWithManyFailures(t *testing.T) {
 	// Create a service that returns the mock repository and templates.
 	var services services.ConcreteServices
 	services.SetPeopleRepository(mockRepo)
+	services.SetTaxonomyRepository(taxonomyRepo.MakeRepo(dbsession.MakeMemoryDBSession()))
 	services.SetTemplates(&page)
 
 	var form peopleForms.ConcreteListForm
 
 	// Expectations:
-	// Index will run listPeople which will call repository.FindAll.  Make that
+	// Index will run listPeople which will call repository.FindPage.  Make that
 	// return an error, then listPeople will get the Index page from the template
 	// and call its Execute method.  Make that fail, and the app will get the error
 	// page and call its Execute method.  Make that fails and the app will panic
@@ -371,7 +524,7 @@ func TestUnitIndexWithManyFailures(t *testing.T) {
 	em3 := "final error message"
 	finalError := errors.New(em3)
 
-	mockRepo.EXPECT().FindAll().Return(nil, expectedFirstError)
+	mockRepo.EXPECT().FindPage(gomock.Any()).Return(nil, int64(0), expectedFirstError)
 	// form will now be different (error message added) so don't compare it
 	mockTemplate.EXPECT().Execute(mockResponseWriter, gomock.Any()).Return(expectedSecondError)
 	mockErrorTemplate.EXPECT().Execute(mockResponseWriter, gomock.Any()).Return(finalError)
@@ -420,3 +573,82 @@ func catchPanic() {
 		log.Printf(em)
 	}
 }
+
+// TestUnitTagPerson checks that PeopleHandler.AddTag() tags the person and
+// redirects to their page, and that the index page can then be filtered on the
+// tag.
+func TestUnitTagPerson(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	person, err := peopleRepo.MakeRepo(session).Create(
+		gorpPersonModel.MakeInitialisedPerson(0, "Orson", "Welles"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	taxonomy := taxonomyRepo.MakeRepo(session)
+
+	mockTemplate := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"Index": mockTemplate}
+	var services services.ConcreteServices
+	services.SetPeopleRepository(peopleRepo.MakeRepo(session))
+	services.SetTaxonomyRepository(taxonomy)
+	services.SetTemplates(&page)
+	controller := MakeController(&services)
+
+	uri := fmt.Sprintf("/people/%d/tags", person.ID())
+	httpRequest := httptest.NewRequest("POST", uri, strings.NewReader("name=+Auteur+"))
+	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request := restful.NewRequest(auth.WithViewer(httpRequest,
+		auth.Viewer{Username: "alice", Role: userModel.RoleEditor}))
+	request.PathParameters()["id"] = fmt.Sprintf("%d", person.ID())
+	recorder := httptest.NewRecorder()
+	var response restful.Response
+	response.ResponseWriter = recorder
+
+	controller.AddTag(request, &response)
+
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status %d, got %d", http.StatusSeeOther, recorder.Code)
+	}
+	if recorder.Header().Get("Location") != personPath(person.ID()) {
+		t.Errorf("Expected a redirect to %s, got %s", personPath(person.ID()),
+			recorder.Header().Get("Location"))
+	}
+	tags, err := taxonomy.TagsOf(dbsession.SubjectPerson, person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(tags) != 1 || tags[0].Name() != "auteur" {
+		t.Fatalf("Expected the person to be tagged with auteur, got %v", tags)
+	}
+
+	// Filter the index page on the tag and on a tag that nobody has.
+	var tests = []struct {
+		tag      uint64
+		expected int
+		notice   string
+	}{
+		{tags[0].ID(), 1, ""},
+		{tags[0].ID() + 1, 0, "no people match the filter"},
+	}
+	for _, test := range tests {
+		var listForm peopleForms.ConcreteListForm
+		listForm.SetQuery(peopleForms.ListQuery{Tag: test.tag})
+		mockTemplate.EXPECT().Execute(gomock.Any(), &listForm).Return(nil)
+		var indexResponse restful.Response
+		indexResponse.ResponseWriter = httptest.NewRecorder()
+		controller.Index(restful.NewRequest(httptest.NewRequest("GET", RootPath, nil)),
+			&indexResponse, &listForm)
+		if len(listForm.People()) != test.expected {
+			t.Errorf("tag %d: expected %d people, got %d", test.tag, test.expected,
+				len(listForm.People()))
+		}
+		if listForm.Notice() != test.notice {
+			t.Errorf("tag %d: expected notice %q, got %q", test.tag, test.notice,
+				listForm.Notice())
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package people
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	creditForms "github.com/goblimey/films/forms/credits"
+	forms "github.com/goblimey/films/forms/people"
+	gorpCreditModel "github.com/goblimey/films/models/credit/gorpmysql"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// RootPath is the URI of the people resource.
+const RootPath = "/people"
+
+// idParam is the path parameter holding a numeric ID.  Any other ID doesn't
+// match a route, so the request gets a 404 response.
+const idParam = "{id:[0-9]+}"
+
+// MakeWebService creates the web service that routes requests for the people
+// resource to the controller.  The browser sends a PUT or DELETE as a POST with a
+// "_method" parameter, which must be turned into the real method before the
+// request is routed.  servicesFilter attaches the services to each request - see
+// services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Filter(servicesFilter)
+
+	form := "application/x-www-form-urlencoded"
+	ws.Route(ws.GET("").To(index))
+	ws.Route(ws.GET("/create").To(newPerson))
+	ws.Route(ws.GET("/" + idParam).To(show))
+	ws.Route(ws.GET("/" + idParam + "/edit").To(edit))
+	ws.Route(ws.PUT("").Consumes(form).To(create))
+	ws.Route(ws.PUT("/" + idParam).Consumes(form).To(update))
+	ws.Route(ws.DELETE("/" + idParam + "/delete").Consumes(form).To(deletePerson))
+	ws.Route(ws.GET("/" + idParam + "/history").To(history))
+	ws.Route(ws.PUT("/" + idParam + "/history/{entryID:[0-9]+}/revert").Consumes(form).
+		To(revert))
+	ws.Route(ws.GET("/trash").To(trash))
+	ws.Route(ws.PUT("/trash/" + idParam + "/restore").Consumes(form).To(restore))
+	ws.Route(ws.DELETE("/trash/" + idParam + "/purge").Consumes(form).To(purge))
+	ws.Route(ws.PUT("/" + idParam + "/credits").Consumes(form).To(addCredit))
+	ws.Route(ws.DELETE("/" + idParam + "/credits/{creditID:[0-9]+}/delete").Consumes(form).
+		To(removeCredit))
+	ws.Route(ws.PUT("/" + idParam + "/genres").Consumes(form).To(addGenre))
+	ws.Route(ws.DELETE("/" + idParam + "/genres/{genreID:[0-9]+}/delete").Consumes(form).
+		To(removeGenre))
+	ws.Route(ws.PUT("/" + idParam + "/tags").Consumes(form).To(addTag))
+	ws.Route(ws.DELETE("/" + idParam + "/tags/{tagID:[0-9]+}/delete").Consumes(form).
+		To(removeTag))
+	return ws
+}
+
+// controller makes a controller using the services attached to the request.
+func controller(req *restful.Request) Controller {
+	return MakeController(services.FromRequest(req))
+}
+
+// index handles "GET /people" - fetch a page of the valid people records and
+// display them.  The parameters say which page, how it's sorted and how the
+// people are filtered, for example "GET /people?page=2&sort=forename&genre=3".
+func index(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteListForm
+	form.SetQuery(forms.ParseListQuery(req.Request.URL.Query()))
+	controller(req).Index(req, resp, &form)
+}
+
+// newPerson handles "GET /people/create" - display the form to create a new
+// people record.
+func newPerson(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcretePersonForm
+	// Create an empty person to get started.
+	form.SetPerson(gorpPersonModel.MakePerson())
+	controller(req).New(req, resp, &form)
+}
+
+// show handles "GET /people/435" - fetch the people record with ID 435 and
+// display it.  The ID is passed to the controller in a person in the form.
+func show(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	idStr := req.PathParameter("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		// The route only matches digits, so the ID can only be too big.
+		em := fmt.Sprintf("illegal id %s", idStr)
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	var form forms.ConcretePersonForm
+	person := gorpPersonModel.MakePerson()
+	person.SetID(id)
+	form.SetPerson(person)
+	c.Show(req, resp, &form)
+}
+
+// edit handles "GET /people/1/edit" - fetch the people record given by the ID in
+// the request and display the form to edit it.
+func edit(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcretePersonForm
+	controller(req).Edit(req, resp, &form)
+}
+
+// create handles "PUT /people" - create a new people record from the form data
+// in the body.
+func create(req *restful.Request, resp *restful.Response) {
+	c := controller(req)
+	form := personFormFromRequest(req, resp, c)
+	if form == nil {
+		return
+	}
+	c.Create(req, resp, form)
+}
+
+// update handles "PUT /people/1" - update the people record with the given ID
+// using the form data in the body.
+func update(req *restful.Request, resp *restful.Response) {
+	c := controller(req)
+	form := personFormFromRequest(req, resp, c)
+	if form == nil {
+		return
+	}
+	c.Update(req, resp, form)
+}
+
+// deletePerson handles "DELETE /people/1/delete" - move the person with the ID
+// given in the request to the trash.
+func deletePerson(req *restful.Request, resp *restful.Response) {
+	controller(req).Delete(req, resp)
+}
+
+// history handles "GET /people/1/history" - display the changes made to the
+// person with the given ID.
+func history(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	idStr := req.PathParameter("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		// The route only matches digits, so the ID can only be too big.
+		em := fmt.Sprintf("illegal id %s", idStr)
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	var form forms.ConcreteHistoryForm
+	form.SetPersonID(id)
+	c.History(req, resp, &form)
+}
+
+// revert handles "PUT /people/1/history/5/revert" - restore person 1 to the
+// values recorded by entry 5 of their history.
+func revert(req *restful.Request, resp *restful.Response) {
+	controller(req).Revert(req, resp)
+}
+
+// trash handles "GET /people/trash" - display the people in the trash.
+func trash(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteTrashForm
+	controller(req).Trash(req, resp, &form)
+}
+
+// restore handles "PUT /people/trash/1/restore" - take person 1 out of the
+// trash.
+func restore(req *restful.Request, resp *restful.Response) {
+	controller(req).Restore(req, resp)
+}
+
+// purge handles "DELETE /people/trash/1/purge" - remove person 1 from the trash
+// for good.
+func purge(req *restful.Request, resp *restful.Response) {
+	controller(req).Purge(req, resp)
+}
+
+// addCredit handles "PUT /people/1/credits" - credit the person with the given
+// ID on the film given in the form data.
+func addCredit(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	form, err := creditFormFromRequest(req)
+	if err != nil {
+		logger.Error(err.Error())
+		c.ErrorHandler(req, resp, err.Error())
+		return
+	}
+	c.AddCredit(req, resp, form)
+}
+
+// removeCredit handles "DELETE /people/1/credits/2/delete" - remove credit 2
+// from person 1.
+func removeCredit(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveCredit(req, resp)
+}
+
+// addGenre handles "PUT /people/1/genres" - put person 1 into the genre given in
+// the form data.
+func addGenre(req *restful.Request, resp *restful.Response) {
+	controller(req).AddGenre(req, resp)
+}
+
+// removeGenre handles "DELETE /people/1/genres/2/delete" - take person 1 out of
+// genre 2.
+func removeGenre(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveGenre(req, resp)
+}
+
+// addTag handles "PUT /people/1/tags" - tag person 1 with the name given in the
+// form data.
+func addTag(req *restful.Request, resp *restful.Response) {
+	controller(req).AddTag(req, resp)
+}
+
+// removeTag handles "DELETE /people/1/tags/2/delete" - remove tag 2 from person 1.
+func removeTag(req *restful.Request, resp *restful.Response) {
+	controller(req).RemoveTag(req, resp)
+}
+
+// personFormFromRequest gets the person data from the request, creates a
+// GorpMysqlPerson and returns it in a PersonForm.  If the request can't be
+// handled, it displays the index page with an error message and returns nil.
+func personFormFromRequest(req *restful.Request, resp *restful.Response,
+	c Controller) forms.PersonForm {
+
+	logger := logging.FromRequest(req.Request)
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("cannot parse form - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return nil
+	}
+	var form forms.ConcretePersonForm
+	var person gorpPersonModel.GorpMysqlPerson
+	idStr := req.PathParameter("id")
+	if idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			em := fmt.Sprintf("invalid id %v in request - should be numeric", idStr)
+			logger.Error(em)
+			c.ErrorHandler(req, resp, em)
+			return nil
+		}
+		person.SetID(id)
+	}
+	person.SetForename(strings.TrimSpace(req.Request.FormValue("forename")))
+	person.SetSurname(strings.TrimSpace(req.Request.FormValue("surname")))
+	// The edit page sends the version of the person that was edited.  If it's
+	// missing or junk, the version is left at 0, which never matches.
+	version, err := strconv.ParseInt(req.Request.FormValue("version"), 10, 64)
+	if err == nil {
+		person.SetVersion(version)
+	}
+	form.SetPerson(&person)
+	logger.Debug("form", "form", form.String())
+	return &form
+}
+
+// creditFormFromRequest gets the credit data from the request, creates a
+// GorpMysqlCredit and returns it in a CreditForm.  The person's ID comes from the
+// URI and the film's ID from the form data.  If the film ID or the billing is not
+// a number, the form gets a field error, which causes the validation to fail
+// later on.  An error is only returned if the request cannot be handled at all.
+func creditFormFromRequest(req *restful.Request) (creditForms.CreditForm, error) {
+
+	logger := logging.FromRequest(req.Request)
+
+	err := req.Request.ParseForm()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse form - %s", err.Error())
+	}
+
+	idStr := req.PathParameter("id")
+	personID, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id %v in request - should be numeric", idStr)
+	}
+
+	var form creditForms.ConcreteCreditForm
+	var credit gorpCreditModel.GorpMysqlCredit
+	credit.SetPersonID(personID)
+	// An unparseable ID leaves the film ID zero, which fails validation.
+	filmID, _ := strconv.ParseUint(req.Request.FormValue("filmID"), 10, 64)
+	credit.SetFilmID(filmID)
+	credit.SetRole(req.Request.FormValue("role"))
+	credit.SetCharacter(req.Request.FormValue("character"))
+
+	billingStr := strings.TrimSpace(req.Request.FormValue("billing"))
+	if billingStr != "" {
+		billing, err := strconv.Atoi(billingStr)
+		if err != nil {
+			form.SetErrorMessageForField("Billing", "the Billing must be a number")
+		} else {
+			credit.SetBilling(billing)
+		}
+	}
+
+	form.SetCredit(&credit)
+	logger.Debug("form", "form", form.String())
+	return &form, nil
+}
@@ -0,0 +1,81 @@
+// Package search provides the controller for the search page, which finds people
+// and films by name:
+//
+//    GET search?q=welles - runs Index() to display the people and films matching "welles"
+//
+// The search is done by the full-text index in the services - see the
+// utilities/search package.
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/search"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// MaxResults is the largest number of people and films on the search page.
+const MaxResults = 50
+
+type Controller struct {
+	services services.Services
+}
+
+// MakeController is a factory that creates a search controller
+func MakeController(services services.Services) Controller {
+	var controller Controller
+	controller.SetServices(services)
+	return controller
+}
+
+// Index searches for the query in the form and displays the search page with the
+// results.  With no query, it just displays the page.
+func (c Controller) Index(req *restful.Request, resp *restful.Response,
+	form forms.SearchForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	query := strings.TrimSpace(form.Query())
+	if query != "" {
+		results := c.services.GetSearchIndex().Search(query, MaxResults)
+		logger.Debug("searched", "query", query, "count", len(results))
+		form.SetResults(results)
+		if len(results) == 0 {
+			form.SetNotice(fmt.Sprintf("nothing matches \"%s\"", query))
+		}
+	}
+
+	page := c.services.Template("SearchIndex")
+	if page == nil {
+		utilities.Dead(resp)
+		return
+	}
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	err := page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		// Fall back to the static error page.
+		logger.Error(err.Error())
+		page = c.services.Template("Error")
+		if page == nil {
+			utilities.Dead(resp)
+			return
+		}
+		err = page.Execute(resp.ResponseWriter, form)
+		if err != nil {
+			// Can't display the static error page either.  Bale out.
+			em := fmt.Sprintf("fatal error - failed to display error page for error %s\n", err.Error())
+			logger.Error(em)
+			panic(em)
+		}
+	}
+}
+
+// SetServices sets the services.
+func (c *Controller) SetServices(services services.Services) {
+	c.services = services
+}
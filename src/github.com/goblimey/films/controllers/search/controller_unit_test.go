@@ -0,0 +1,70 @@
+package search
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/search"
+	mocks "github.com/goblimey/films/mocks/gomock"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/search"
+	"github.com/golang/mock/gomock"
+)
+
+// TestUnitIndex checks that the search page gets the results for the query, or
+// a notice if nothing matches.
+func TestUnitIndex(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockTemplate := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"SearchIndex": mockTemplate}
+	index := search.MakeIndex()
+	index.AddPerson(gorpPersonModel.MakeInitialisedPerson(1, "Agnes", "Moorehead"))
+	var svc services.ConcreteServices
+	svc.SetSearchIndex(index)
+	svc.SetTemplates(&page)
+	getServices := func() (services.Services, error) {
+		return &svc, nil
+	}
+	container := restful.NewContainer()
+	container.Add(MakeWebService(services.Filter(getServices, func(resp *restful.Response) {
+		resp.WriteHeader(http.StatusServiceUnavailable)
+	})))
+
+	var tests = []struct {
+		uri     string
+		results int
+		notice  string
+	}{
+		{"/search?q=moor", 1, ""},
+		{"/search?q=hayworth", 0, "nothing matches \"hayworth\""},
+		{"/search", 0, ""},
+	}
+
+	for _, test := range tests {
+		var form forms.SearchForm
+		mockTemplate.EXPECT().Execute(gomock.Any(), gomock.Any()).
+			Do(func(w interface{}, data interface{}) {
+				form = data.(forms.SearchForm)
+			}).Return(nil)
+		recorder := httptest.NewRecorder()
+		container.ServeHTTP(recorder, httptest.NewRequest("GET", test.uri, nil))
+		if form == nil {
+			t.Errorf("%s: expected the search page to be displayed", test.uri)
+			continue
+		}
+		if len(form.Results()) != test.results {
+			t.Errorf("%s: expected %d results, got %d", test.uri, test.results,
+				len(form.Results()))
+		}
+		if form.Notice() != test.notice {
+			t.Errorf("%s: expected notice %q, got %q", test.uri, test.notice, form.Notice())
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package search
+
+import (
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/search"
+	"github.com/goblimey/films/services"
+)
+
+// RootPath is the URI of the search page.
+const RootPath = "/search"
+
+// MakeWebService creates the web service that routes requests for the search
+// page to the controller.  servicesFilter attaches the services to each request -
+// see services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Filter(servicesFilter)
+	ws.Route(ws.GET("").To(index))
+	return ws
+}
+
+// index handles "GET /search?q=welles" - display the people and films that
+// match the query.
+func index(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteSearchForm
+	form.SetQuery(req.QueryParameter("q"))
+	MakeController(services.FromRequest(req)).Index(req, resp, &form)
+}
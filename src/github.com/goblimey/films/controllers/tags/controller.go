@@ -0,0 +1,145 @@
+// Package tags provides the controller for the pages of genres and tags:
+//
+//    GET tags/ - runs Index() to display the tag cloud and the tree of genres
+//    GET tags/n - runs Show() to list the films and people with the tag with ID n
+//    PUT tags/genres - runs AddGenre() to create a genre using the data in the form
+//
+// Films and people are put into genres and tagged from their own pages - see the
+// films and people controllers.
+package tags
+
+import (
+	"fmt"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/tags"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+type Controller struct {
+	services services.Services
+}
+
+// MakeController is a factory that creates a tags controller
+func MakeController(services services.Services) Controller {
+	var controller Controller
+	controller.SetServices(services)
+	return controller
+}
+
+// Index fetches the tags that are in use and the genres and displays the tag
+// cloud page.
+func (c Controller) Index(req *restful.Request, resp *restful.Response,
+	form forms.ListForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+
+	repo := c.services.GetTaxonomyRepository().WithContext(req.Request.Context())
+
+	tags, err := repo.FindAllTags()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of tags - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	} else if len(tags) == 0 && form.Notice() == "" {
+		form.SetNotice("nothing has been tagged yet")
+	}
+	form.SetTags(tags)
+
+	genres, err := repo.FindAllGenres()
+	if err != nil {
+		em := fmt.Sprintf("error getting the list of genres - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetGenres(genres)
+
+	utilities.Display(req, resp, c.services, "TagIndex", form, form.SetViewer)
+}
+
+// Show displays the tag with the ID given in the form and the films and people
+// that have it.
+func (c Controller) Show(req *restful.Request, resp *restful.Response,
+	form forms.TagForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	tag, err := c.services.GetTaxonomyRepository().WithContext(req.Request.Context()).FindTagByID(form.Tag().ID())
+	if err != nil {
+		// no such tag.  Display index page with error message
+		em := "no such tag"
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	form.SetTag(tag)
+
+	// If either list fails, display the page anyway, with an error.
+	films, err := c.services.GetFilmRepository().WithContext(req.Request.Context()).FindMatching(dbsession.FilmQuery{TagID: tag.ID()})
+	if err != nil {
+		em := fmt.Sprintf("error getting the films with the tag - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetFilms(films)
+
+	query := dbsession.PeopleQuery{TagID: tag.ID(), Sort: dbsession.SortBySurname}
+	people, _, err := c.services.GetPeopleRepository().WithContext(req.Request.Context()).FindPage(query)
+	if err != nil {
+		em := fmt.Sprintf("error getting the people with the tag - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+	form.SetPeople(people)
+
+	utilities.Display(req, resp, c.services, "TagShow", form, form.SetViewer)
+}
+
+// AddGenre responds to a PUT request such as PUT /tags/genres.  It creates a genre
+// with the name and parent given in the form data and redirects to the index
+// page, which displays a notice, or displays the index page with an error
+// message.
+func (c Controller) AddGenre(req *restful.Request, resp *restful.Response,
+	name string, parentID uint64) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.CanEdit, "create genres") {
+		return
+	}
+
+	genre, err := c.services.GetTaxonomyRepository().WithContext(req.Request.Context()).AddGenre(name, parentID)
+	if err != nil {
+		em := fmt.Sprintf("Could not create genre %q - %s", name, err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	notice := fmt.Sprintf("created new genre %s", genre.Path())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
+}
+
+// ErrorHandler displays the tag cloud page with an error message
+func (c Controller) ErrorHandler(req *restful.Request, resp *restful.Response,
+	errormessage string) {
+
+	var form forms.ConcreteListForm
+	form.SetErrorMessage(errormessage)
+	c.Index(req, resp, &form)
+}
+
+// SetServices sets the services.
+func (c *Controller) SetServices(services services.Services) {
+	c.services = services
+}
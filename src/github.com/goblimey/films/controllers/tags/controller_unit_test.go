@@ -0,0 +1,138 @@
+package tags
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goblimey/films/controllers/internal/controllertest"
+	forms "github.com/goblimey/films/forms/tags"
+	mocks "github.com/goblimey/films/mocks/gomock"
+	filmModel "github.com/goblimey/films/models/film"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+	userModel "github.com/goblimey/films/models/user"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	taxonomyRepo "github.com/goblimey/films/repositories/taxonomy"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/golang/mock/gomock"
+)
+
+// TestUnitIndexAndShow checks that the tag cloud page gets the tags in use and
+// that the page of a tag gets the films and people that have it.
+func TestUnitIndexAndShow(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := dbsession.MakeMemoryDBSession()
+	film, err := filmsRepo.MakeRepo(session).Create(
+		filmModel.MakeInitialisedFilm(0, "Touch of Evil", 1958, 95, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	person, err := peopleRepo.MakeRepo(session).Create(
+		gorpPersonModel.MakeInitialisedPerson(0, "Orson", "Welles"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	taxonomy := taxonomyRepo.MakeRepo(session)
+	noir, err := taxonomy.AddTagTo("film noir", dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = taxonomy.AddTagTo("Film Noir", dbsession.SubjectPerson, person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	mockIndex := mocks.NewMockTemplate(mockCtrl)
+	mockShow := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"TagIndex": mockIndex, "TagShow": mockShow}
+	handler := controllertest.MakeHandler(MakeWebService, session, page, auth.Viewer{})
+
+	var listForm forms.ListForm
+	mockIndex.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			listForm = data.(forms.ListForm)
+		}).Return(nil).Times(2)
+	var tagForm forms.TagForm
+	mockShow.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			tagForm = data.(forms.TagForm)
+		}).Return(nil)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/tags", nil))
+	if listForm == nil {
+		t.Fatalf("expected the tag cloud page to be displayed")
+	}
+	if len(listForm.Tags()) != 1 || listForm.Tags()[0].Count() != 2 {
+		t.Errorf("expected one tag used twice, got %v", listForm.Tags())
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/tags/1", nil))
+	if tagForm == nil {
+		t.Fatalf("expected the page of the tag to be displayed")
+	}
+	if tagForm.Tag().Name() != noir.Name() || len(tagForm.Films()) != 1 ||
+		len(tagForm.People()) != 1 {
+		t.Errorf("expected the tag with one film and one person, got %s, %d and %d",
+			tagForm.Tag().Name(), len(tagForm.Films()), len(tagForm.People()))
+	}
+
+	// A missing tag displays the tag cloud with an error.
+	listForm = nil
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/tags/99", nil))
+	if listForm == nil || listForm.ErrorMessage() != "no such tag" {
+		t.Errorf("expected the tag cloud page with an error")
+	}
+}
+
+// TestUnitAddGenre checks that an editor can create genres and a viewer can't.
+func TestUnitAddGenre(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockForbidden := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"Forbidden": mockForbidden}
+	mockForbidden.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil)
+
+	session := dbsession.MakeMemoryDBSession()
+	taxonomy := taxonomyRepo.MakeRepo(session)
+
+	var tests = []struct {
+		role   string
+		body   string
+		status int
+	}{
+		{userModel.RoleEditor, "_method=PUT&name=Thriller", http.StatusSeeOther},
+		{userModel.RoleEditor, "_method=PUT&name=Psychological+Thriller&parentID=1",
+			http.StatusSeeOther},
+		{userModel.RoleViewer, "_method=PUT&name=Western", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		handler := controllertest.MakeHandler(MakeWebService, session, page,
+			auth.Viewer{Username: "rita", Role: test.role})
+		request := httptest.NewRequest("POST", "/tags/genres", strings.NewReader(test.body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != test.status {
+			t.Errorf("%s %s: expected status %d, got %d", test.role, test.body,
+				test.status, recorder.Code)
+		}
+	}
+
+	genres, err := taxonomy.FindAllGenres()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(genres) != 2 || genres[1].Path() != "Thriller > Psychological Thriller" {
+		t.Errorf("expected the thriller genres, got %v", genres)
+	}
+}
@@ -0,0 +1,88 @@
+package tags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/tags"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// RootPath is the URI of the tag cloud page.
+const RootPath = "/tags"
+
+// MakeWebService creates the web service that routes requests for the tag and
+// genre pages to the controller.  The browser sends a PUT as a POST with a
+// "_method" parameter, which must be turned into the real method before the
+// request is routed.  servicesFilter attaches the services to each request - see
+// services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Filter(servicesFilter)
+
+	form := "application/x-www-form-urlencoded"
+	ws.Route(ws.GET("").To(index))
+	ws.Route(ws.GET("/{id:[0-9]+}").To(show))
+	ws.Route(ws.PUT("/genres").Consumes(form).To(addGenre))
+	return ws
+}
+
+// controller makes a controller using the services attached to the request.
+func controller(req *restful.Request) Controller {
+	return MakeController(services.FromRequest(req))
+}
+
+// index handles "GET /tags" - display the tag cloud and the genres.
+func index(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteListForm
+	controller(req).Index(req, resp, &form)
+}
+
+// show handles "GET /tags/7" - display the films and people with tag 7.
+func show(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	idStr := req.PathParameter("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		// The route only matches digits, so the ID can only be too big.
+		em := fmt.Sprintf("illegal id %s", idStr)
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	var form forms.ConcreteTagForm
+	form.SetTag(tagModel.MakeInitialisedTag(id, ""))
+	c.Show(req, resp, &form)
+}
+
+// addGenre handles "PUT /tags/genres" - create a genre with the name and the
+// parent ID given in the form data.  A missing parent ID puts the genre at the
+// top of the tree.
+func addGenre(req *restful.Request, resp *restful.Response) {
+	logger := logging.FromRequest(req.Request)
+	c := controller(req)
+	err := req.Request.ParseForm()
+	if err != nil {
+		em := fmt.Sprintf("cannot parse form - %s", err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+	parentStr := strings.TrimSpace(req.Request.FormValue("parentID"))
+	var parentID uint64
+	if parentStr != "" {
+		parentID, err = strconv.ParseUint(parentStr, 10, 64)
+		if err != nil {
+			em := fmt.Sprintf("invalid parent %v in request - should be numeric", parentStr)
+			logger.Error(em)
+			c.ErrorHandler(req, resp, em)
+			return
+		}
+	}
+	c.AddGenre(req, resp, strings.TrimSpace(req.Request.FormValue("name")), parentID)
+}
@@ -0,0 +1,128 @@
+// Package watchlist provides the controller for the viewer's watchlist - the
+// films that they want to watch:
+//
+//    GET watchlist/ - runs Index() to list the films on the viewer's watchlist
+//    DELETE watchlist/n - runs Delete() to take the entry with ID n off the viewer's watchlist
+//
+// Films are put on the watchlist from the film's page - see the films controller.
+// Each user has their own watchlist, so the viewer must be logged in.
+package watchlist
+
+import (
+	"fmt"
+	"strconv"
+
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/watchlist"
+	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+type Controller struct {
+	services services.Services
+}
+
+// MakeController is a factory that creates a watchlist controller
+func MakeController(services services.Services) Controller {
+	var controller Controller
+	controller.SetServices(services)
+	return controller
+}
+
+// Index fetches the viewer's watchlist and displays it.
+func (c Controller) Index(req *restful.Request, resp *restful.Response,
+	form forms.ListForm) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "keep a watchlist") {
+		return
+	}
+
+	notice := utilities.Flash(req, resp, c.services.GetSessions())
+	if notice != "" && form.Notice() == "" {
+		form.SetNotice(notice)
+	}
+
+	userID, err := c.userID(req)
+	if err == nil {
+		entries, err := c.services.GetWatchlistRepository().WithContext(req.Request.Context()).FindByUser(userID)
+		if err == nil {
+			form.SetEntries(entries)
+			if len(entries) == 0 && form.Notice() == "" {
+				form.SetNotice("there are no films on your watchlist")
+			}
+		}
+	}
+	if err != nil {
+		em := fmt.Sprintf("error getting the watchlist - %s", err.Error())
+		logger.Error(em)
+		form.SetErrorMessage(em)
+	}
+
+	utilities.Display(req, resp, c.services, "WatchlistIndex", form, form.SetViewer)
+}
+
+// Delete responds to a DELETE request such as DELETE /watchlist/1.  It takes the
+// entry off the viewer's watchlist and redirects to the watchlist.  Users can
+// only change their own watchlists.
+func (c Controller) Delete(req *restful.Request, resp *restful.Response) {
+
+	logger := logging.FromRequest(req.Request)
+
+	if !utilities.Allowed(req, resp, c.services, auth.Viewer.LoggedIn, "keep a watchlist") {
+		return
+	}
+
+	idStr := req.PathParameter("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	repo := c.services.GetWatchlistRepository().WithContext(req.Request.Context())
+	entry, err := repo.FindByID(id)
+	if err == nil {
+		var userID uint64
+		userID, err = c.userID(req)
+		if err == nil && entry.UserID() != userID {
+			// Treat someone else's entry as missing.
+			err = fmt.Errorf("no such entry")
+		}
+	}
+	if err == nil {
+		_, err = repo.DeleteByID(id)
+	}
+	if err != nil {
+		em := fmt.Sprintf("Cannot remove entry with id %s from the watchlist - %s", idStr, err.Error())
+		logger.Error(em)
+		c.ErrorHandler(req, resp, em)
+		return
+	}
+
+	notice := fmt.Sprintf("removed %s from your watchlist", entry.FilmTitle())
+	logger.Info(notice)
+	utilities.SeeOther(req, resp, c.services.GetSessions(), RootPath, notice)
+}
+
+// ErrorHandler displays the watchlist with an error message
+func (c Controller) ErrorHandler(req *restful.Request, resp *restful.Response,
+	errormessage string) {
+
+	var form forms.ConcreteListForm
+	form.SetErrorMessage(errormessage)
+	c.Index(req, resp, &form)
+}
+
+// SetServices sets the services.
+func (c *Controller) SetServices(services services.Services) {
+	c.services = services
+}
+
+// userID gets the ID of the user record of the viewer, who must be logged in.
+func (c Controller) userID(req *restful.Request) (uint64, error) {
+	viewer := auth.ViewerFrom(req.Request)
+	user, err := c.services.GetUserRepository().WithContext(req.Request.Context()).FindByUsername(viewer.Username)
+	if err != nil {
+		return 0, err
+	}
+	return user.ID(), nil
+}
@@ -0,0 +1,137 @@
+package watchlist
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goblimey/films/controllers/internal/controllertest"
+	forms "github.com/goblimey/films/forms/watchlist"
+	mocks "github.com/goblimey/films/mocks/gomock"
+	filmModel "github.com/goblimey/films/models/film"
+	userModel "github.com/goblimey/films/models/user"
+	gorpWatchlistModel "github.com/goblimey/films/models/watchlist/gorpmysql"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	watchlistRepo "github.com/goblimey/films/repositories/watchlist"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/golang/mock/gomock"
+)
+
+// setUp creates an in-memory session holding a film that vera and rita have each
+// put on their watchlists, vera first.
+func setUp(t *testing.T) dbsession.DBSession {
+	session := dbsession.MakeMemoryDBSession()
+	film, err := filmsRepo.MakeRepo(session).Create(
+		filmModel.MakeInitialisedFilm(0, "Brief Encounter", 1945, 86, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	users := usersRepo.MakeRepo(session)
+	repo := watchlistRepo.MakeRepo(session)
+	for _, username := range []string{"vera", "rita"} {
+		user, err := users.Add(username, "a long password", userModel.RoleViewer)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		_, err = repo.Add(gorpWatchlistModel.MakeInitialisedEntry(0, user.ID(), film.ID(),
+			time.Now()))
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	return session
+}
+
+// TestUnitLoggedOut checks that somebody who is not logged in can't see or change
+// a watchlist.
+func TestUnitLoggedOut(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := setUp(t)
+	mockIndex := mocks.NewMockTemplate(mockCtrl)
+	mockForbidden := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{
+		"WatchlistIndex": mockIndex,
+		"Forbidden":      mockForbidden,
+	}
+	handler := controllertest.MakeHandler(MakeWebService, session, page, auth.Viewer{})
+
+	mockForbidden.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/watchlist", nil))
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("index: expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+	recorder = controllertest.Put(handler, "/watchlist/1/delete", "_method=DELETE")
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("delete: expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+	_, err := watchlistRepo.MakeRepo(session).FindByID(1)
+	if err != nil {
+		t.Errorf("expected the entry to survive - %s", err.Error())
+	}
+}
+
+// TestUnitDelete checks that another user's entry is treated as missing, and that
+// taking one of the viewer's own entries off redirects to the watchlist, which
+// then displays a notice.
+func TestUnitDelete(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	session := setUp(t)
+	mockIndex := mocks.NewMockTemplate(mockCtrl)
+	page := map[string]retroTemplate.Template{"WatchlistIndex": mockIndex}
+	handler := controllertest.MakeHandler(MakeWebService, session, page,
+		auth.Viewer{Username: "vera", Role: userModel.RoleViewer})
+	repo := watchlistRepo.MakeRepo(session)
+
+	// Entry 2 is rita's.
+	var form forms.ListForm
+	mockIndex.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			form = data.(forms.ListForm)
+		}).Return(nil)
+	controllertest.Put(handler, "/watchlist/2/delete", "_method=DELETE")
+	if form == nil || form.ErrorMessage() !=
+		"Cannot remove entry with id 2 from the watchlist - no such entry" {
+
+		t.Errorf("expected the watchlist with an error")
+	}
+	_, err := repo.FindByID(2)
+	if err != nil {
+		t.Errorf("expected rita's entry to survive - %s", err.Error())
+	}
+
+	recorder := controllertest.Put(handler, "/watchlist/1/delete", "_method=DELETE")
+	if recorder.Code != http.StatusSeeOther || recorder.Header().Get("Location") != RootPath {
+		t.Fatalf("expected a redirect to %s, got %d %q", RootPath, recorder.Code,
+			recorder.Header().Get("Location"))
+	}
+	_, err = repo.FindByID(1)
+	if err == nil {
+		t.Errorf("expected vera's entry to be removed")
+	}
+
+	form = nil
+	mockIndex.EXPECT().Execute(gomock.Any(), gomock.Any()).
+		Do(func(w interface{}, data interface{}) {
+			form = data.(forms.ListForm)
+		}).Return(nil)
+	req := httptest.NewRequest("GET", RootPath, nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	want := "removed Brief Encounter from your watchlist"
+	if form == nil || form.Notice() != want {
+		t.Errorf("expected the notice %q", want)
+	}
+}
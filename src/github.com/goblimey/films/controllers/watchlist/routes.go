@@ -0,0 +1,46 @@
+package watchlist
+
+import (
+	restful "github.com/emicklei/go-restful"
+	forms "github.com/goblimey/films/forms/watchlist"
+	"github.com/goblimey/films/services"
+)
+
+// RootPath is the URI of the watchlist resource.
+const RootPath = "/watchlist"
+
+// idParam is the path parameter holding a numeric ID.  Any other ID doesn't
+// match a route, so the request gets a 404 response.
+const idParam = "{id:[0-9]+}"
+
+// MakeWebService creates the web service that routes requests for the watchlist
+// to the controller.  The browser sends a DELETE as a POST with a "_method"
+// parameter, which must be turned into the real method before the request is
+// routed.  servicesFilter attaches the services to each request - see
+// services.Filter.
+func MakeWebService(servicesFilter restful.FilterFunction) *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(RootPath).Filter(servicesFilter)
+
+	form := "application/x-www-form-urlencoded"
+	ws.Route(ws.GET("").To(index))
+	ws.Route(ws.DELETE("/" + idParam + "/delete").Consumes(form).To(deleteEntry))
+	return ws
+}
+
+// controller makes a controller using the services attached to the request.
+func controller(req *restful.Request) Controller {
+	return MakeController(services.FromRequest(req))
+}
+
+// index handles "GET /watchlist" - display the viewer's watchlist.
+func index(req *restful.Request, resp *restful.Response) {
+	var form forms.ConcreteListForm
+	controller(req).Index(req, resp, &form)
+}
+
+// deleteEntry handles "DELETE /watchlist/3/delete" - take entry 3 off the
+// viewer's watchlist.
+func deleteEntry(req *restful.Request, resp *restful.Response) {
+	controller(req).Delete(req, resp)
+}
@@ -1,137 +1,438 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
 	"fmt"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"regexp"
-	"strconv"
-	"strings"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
 
 	restful "github.com/emicklei/go-restful"
+	peopleAPI "github.com/goblimey/films/controllers/api/people"
+	searchAPI "github.com/goblimey/films/controllers/api/search"
+	awardsController "github.com/goblimey/films/controllers/awards"
+	companiesController "github.com/goblimey/films/controllers/companies"
+	diaryController "github.com/goblimey/films/controllers/diary"
+	filmsController "github.com/goblimey/films/controllers/films"
+	loginController "github.com/goblimey/films/controllers/login"
 	peopleController "github.com/goblimey/films/controllers/people"
-	forms "github.com/goblimey/films/forms/people"
-	personModel "github.com/goblimey/films/models/person/gorpmysql"
+	searchController "github.com/goblimey/films/controllers/search"
+	tagsController "github.com/goblimey/films/controllers/tags"
+	watchlistController "github.com/goblimey/films/controllers/watchlist"
+	userModel "github.com/goblimey/films/models/user"
+	awardsRepo "github.com/goblimey/films/repositories/awards"
+	companiesRepo "github.com/goblimey/films/repositories/companies"
+	creditsRepo "github.com/goblimey/films/repositories/credits"
+	diaryRepo "github.com/goblimey/films/repositories/diary"
+	filmsRepo "github.com/goblimey/films/repositories/films"
 	peopleRepo "github.com/goblimey/films/repositories/people"
+	reviewsRepo "github.com/goblimey/films/repositories/reviews"
+	taxonomyRepo "github.com/goblimey/films/repositories/taxonomy"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	watchlistRepo "github.com/goblimey/films/repositories/watchlist"
 	retroTemplate "github.com/goblimey/films/retrofit/template"
 	"github.com/goblimey/films/services"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/config"
+	"github.com/goblimey/films/utilities/csrf"
 	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+	"github.com/goblimey/films/utilities/migrations"
+	"github.com/goblimey/films/utilities/search"
 )
 
-// peopleRequestRE is the regular expression for the URI of any request to be
-// handled by the PeopleController - for example: "/people", "/people/1/delete"
-// and so on.
-var peopleRequestRE = regexp.MustCompile(`^/people$|^/people/.*`)
-
-// The following regular expressions are for specific request URIs, to work out
-// which controller method to call.  For example, a GET request with URI "/people"
-// produces a call to the Index method of the people controller.
-//
-// The requests follow the REST model and therefore carry data such as IDs
-// in the request URI rather than in HTTP parameters, for example:
-//
-//    GET /people/435
-//
-// rather than
-//
-//    GET/people&id=435
-//
-// Only form data is supplied through HTTP parameters
-
-// The peopleDeleteRequestRE is the regular expression for the URI of a delete
-// request containing a numeric ID - for example: "/people/1/delete".
-var peopleDeleteRequestRE = regexp.MustCompile(`^/people/[0-9]+/delete$`)
-
-// The peopleShowRequestRE is the regular expression for the URI of a show
-// request containing a numeric ID - for example: "/people/1".
-var peopleShowRequestRE = regexp.MustCompile(`^/people/[0-9]+$`)
-
-// The peopleEditRequestRE is the regular expression for the URI of an edit
-// request containing a numeric ID - for example: "/people/1/edit".
-var peopleEditRequestRE = regexp.MustCompile(`^/people/[0-9]+/edit$`)
-
-// The peopleUpdateRequestRE is the regular expression for the URI of an update
-// request containing a numeric ID - for example: "/people/1".  The URI
-// is the same as for the show request, but we give it a different name for
-// clarity.
-var peopleUpdateRequestRE = peopleShowRequestRE
-
-// page is a map of html templates, the views for the people resource.
+// page is a map of html templates, the views for the people and films
+// resources.
 var page *map[string]retroTemplate.Template
 
-func main() {
-	log.SetPrefix("main() ")
-	log.Println("startup")
+// settings holds the configuration, which comes from the config file, the
+// environment and the command line.
+var settings *config.Config
+
+// appServices holds the database session with its pool of connections, the
+// repositories and the templates.  It's created once by getServices and then
+// shared by every request.
+var appServices *services.ConcreteServices
+
+// servicesMutex guards appServices.
+var servicesMutex sync.Mutex
 
-	// Nothing is going to work without the templates in the views directory.
-	// If there is no views directory, give up.  Most likely, the user has not
-	// moved to the right directory before running this.
-	fileInfo, err := os.Stat("views")
+// sessions handles the session cookies that say who is logged in.
+var sessions auth.Sessions
+
+func main() {
+	slog.Info("startup")
+
+	// Get the settings and check them.  Nothing is going to work without the
+	// templates in the views directory, so the check includes that.  If it
+	// fails, most likely the user has not moved to the right directory before
+	// running this, or has not set the views directory.
+	var err error
+	var command []string
+	settings, command, err = config.LoadCommand(os.Args[1:], os.Getenv)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// views does not exist
-			em := "cannot find the views directory"
-			log.Println(em)
-			fmt.Fprintln(os.Stderr, em)
-
-		} else if !fileInfo.IsDir() {
-			// views exists but is not a directory
-			em := "the file views must be a directory"
-			log.Println(em)
-			fmt.Fprintln(os.Stderr, em)
-
-		} else {
-			// some other error
-			log.Println(err.Error())
-			fmt.Fprintln(os.Stderr, err.Error())
+		if err == flag.ErrHelp {
+			os.Exit(0)
 		}
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(2)
+	}
+
+	// If there is a command, run it instead of the server.
+	if len(command) > 0 {
+		os.Exit(runCommand(command))
+	}
 
-		os.Exit(-1)
+	err = settings.Validate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(2)
+	}
+	err = setUpLog()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(2)
 	}
+	slog.Info("settings", slog.String("settings", settings.String()))
 
 	// Set up the map of templates.
-	page = createPeopleTemplates()
-
-	// Set up the restful web service.  Send all requests to marshall().
-
-	ws := new(restful.WebService)
-	http.Handle("/stylesheets/", http.StripPrefix("/stylesheets/", http.FileServer(http.Dir("views/stylesheets"))))
-	http.Handle("/html/", http.StripPrefix("/html/", http.FileServer(http.Dir("views/html"))))
-
-	// Tie all expected requests to the marshall.
-	ws.Route(ws.GET("/people").To(marshall))
-	ws.Route(ws.GET("/people/{id}/edit").To(marshall))
-	ws.Route(ws.GET("/people/{id}").To(marshall))
-	ws.Route(ws.GET("/people/create").To(marshall))
-	ws.Route(ws.POST("/people").Consumes("application/x-www-form-urlencoded").To(marshall))
-	ws.Route(ws.POST("/people/{id}").Consumes("application/x-www-form-urlencoded").To(marshall))
-	ws.Route(ws.POST("/people/{id}/delete").Consumes("application/x-www-form-urlencoded").To(marshall))
-	restful.Add(ws)
-
-	log.Println("starting the listener")
-	err = http.ListenAndServe(":4000", nil)
-	log.Println("baling out - " + err.Error())
+	page = createPeopleTemplates(settings.ViewsDir)
+	addFilmTemplates(page, settings.ViewsDir)
+	addSearchTemplates(page, settings.ViewsDir)
+	addTagTemplates(page, settings.ViewsDir)
+	addCompanyTemplates(page, settings.ViewsDir)
+	addWatchlistTemplates(page, settings.ViewsDir)
+	addDiaryTemplates(page, settings.ViewsDir)
+	addAwardTemplates(page, settings.ViewsDir)
+	addLoginTemplates(page, settings.ViewsDir)
+	// Every form that posts carries the CSRF token.
+	for name, tp := range *page {
+		(*page)[name] = csrf.MakeTemplate(tp)
+	}
+
+	// The session cookies are signed and encrypted with the session key.  Without
+	// one, a random key is used and everyone is logged out when the server
+	// restarts.  The lifetime has already been checked by settings.Validate().
+	if settings.SessionKey == "" {
+		slog.Warn("no session key is set - everyone will be logged out when the server stops")
+	}
+	sessionLifetime, _ := settings.SessionLifetimeDuration()
+	sessions = auth.MakeSessions(settings.SessionKey, sessionLifetime, settings.SecureCookies)
+
+	// Open the database.  If it's not available, carry on - each request will
+	// try again and display an error page until it succeeds.
+	if settings.Dialect == dbsession.DialectMemory {
+		slog.Warn("using the in-memory database - the data will be lost when the server stops")
+	}
+	// If the schema is behind, nothing will work until the migrations are applied,
+	// so stop.
+	_, err = getServices()
+	if migrations.IsBehind(err) {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	if err != nil {
+		slog.Error("cannot open the database - will try again when a request arrives",
+			slog.String("dialect", settings.Dialect), slog.Any("error", err))
+	}
+
+	// Serve the stylesheets and the static HTML pages as they are.
+	stylesheets := filepath.Join(settings.StaticDir, "stylesheets")
+	http.Handle("/stylesheets/", http.StripPrefix("/stylesheets/", http.FileServer(http.Dir(stylesheets))))
+	html := filepath.Join(settings.StaticDir, "html")
+	http.Handle("/html/", http.StripPrefix("/html/", http.FileServer(http.Dir(html))))
+
+	// Each resource has a web service that binds its routes to the handlers.
+	// Before a request is handled, a filter attaches the services to it, or
+	// displays an error if the database is unavailable.  Then another filter
+	// finds out who is logged in and what role they have, and refuses any change
+	// if nobody is - the login page has to let everyone through.  The
+	// controllers check that the role allows the change.  For the web pages, a
+	// third filter refuses any change that doesn't carry the CSRF token.  A request that doesn't match any route
+	// gets a 404 or 405 response.
+	htmlFilter := services.Filter(getServices, func(resp *restful.Response) {
+		displayErrorPage(resp, http.StatusServiceUnavailable)
+	})
+	apiFilter := services.Filter(getServices, peopleAPI.ServiceUnavailable)
+	htmlAuthFilter := auth.Filter(sessions, findRole, loginController.Unauthorised)
+	apiAuthFilter := auth.Filter(sessions, findRole, peopleAPI.Unauthorised)
+	csrfFilter := csrf.Filter(sessions, displayForbiddenPage)
+	webServices := []*restful.WebService{
+		peopleController.MakeWebService(htmlFilter).Filter(htmlAuthFilter).Filter(csrfFilter),
+		filmsController.MakeWebService(htmlFilter).Filter(htmlAuthFilter).Filter(csrfFilter),
+		searchController.MakeWebService(htmlFilter).Filter(htmlAuthFilter).Filter(csrfFilter),
+		tagsController.MakeWebService(htmlFilter).Filter(htmlAuthFilter).Filter(csrfFilter),
+		companiesController.MakeWebService(htmlFilter).Filter(htmlAuthFilter).Filter(csrfFilter),
+		watchlistController.MakeWebService(htmlFilter).Filter(htmlAuthFilter).Filter(csrfFilter),
+		diaryController.MakeWebService(htmlFilter).Filter(htmlAuthFilter).Filter(csrfFilter),
+		awardsController.MakeWebService(htmlFilter).Filter(htmlAuthFilter).Filter(csrfFilter),
+		loginController.MakeWebService(htmlFilter, auth.Filter(sessions, findRole, nil)).Filter(csrfFilter),
+		peopleAPI.MakeWebService(apiFilter).Filter(apiAuthFilter),
+		searchAPI.MakeWebService(apiFilter).Filter(apiAuthFilter),
+	}
+	for _, ws := range webServices {
+		restful.Add(ws)
+	}
+	// On a panic, log it and send a 500 response.
+	restful.DefaultContainer.DoNotRecover(false)
+
+	// Purge the people who have been in the trash for too long, now and from
+	// time to time while the server runs.
+	stopPurging := make(chan struct{})
+	var purging sync.WaitGroup
+	retention, _ := settings.TrashRetentionDuration()
+	if retention > 0 {
+		purging.Add(1)
+		go func() {
+			defer purging.Done()
+			purgeTrash(retention, stopPurging)
+		}()
+	}
+
+	// On an interrupt, stop taking requests, wait for the ones in progress to
+	// finish and then close the database.  Each request gets its own logger,
+	// which logs the request when it's done.
+	server := &http.Server{
+		Addr:    settings.ListenAddress,
+		Handler: utilities.MethodOverride(logging.Handler(slog.Default(), http.DefaultServeMux)),
+	}
+	stopped := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		sig := <-signals
+		slog.Info("shutting down", slog.String("signal", sig.String()))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err := server.Shutdown(ctx)
+		if err != nil {
+			slog.Error("shutdown failed", slog.Any("error", err))
+		}
+		close(stopped)
+	}()
+
+	slog.Info("starting the listener", slog.String("address", settings.ListenAddress))
+	err = server.ListenAndServe()
+	if err != http.ErrServerClosed {
+		slog.Error("baling out", slog.Any("error", err))
+	} else {
+		<-stopped
+	}
+	close(stopPurging)
+	purging.Wait()
+	closeServices()
+}
+
+// purgeInterval is how often the server purges the people who have been in the
+// trash for longer than the retention period.
+const purgeInterval = time.Hour
+
+// purgeUser is recorded in the audit log as the user who purged the people.
+const purgeUser = "trash retention"
+
+// purgeTrash purges the people who have been in the trash for longer than the
+// retention period, once straight away and then every purgeInterval, until the
+// stop channel is closed.  If the database is not available, it tries again next
+// time.
+func purgeTrash(retention time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		svc, err := getServices()
+		if err == nil {
+			repo := svc.GetPeopleRepository().WithUser(purgeUser)
+			_, err = repo.PurgeTrash(time.Now().Add(-retention))
+		}
+		if err != nil {
+			slog.Error("cannot purge the trash", slog.Any("error", err))
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// getServices returns the application's services, creating them if that hasn't
+// been done yet.  Creating them opens the database session and its pool of
+// connections, which all of the repositories share.  If the database can't be
+// opened, getServices returns the error and the next call tries again.
+func getServices() (services.Services, error) {
+	servicesMutex.Lock()
+	defer servicesMutex.Unlock()
+
+	if appServices != nil {
+		return appServices, nil
+	}
+
+	// The lifetime has already been checked by settings.Validate().
+	lifetime, _ := settings.ConnMaxLifetimeDuration()
+	limits := dbsession.PoolLimits{
+		MaxOpenConns:    settings.MaxOpenConns,
+		MaxIdleConns:    settings.MaxIdleConns,
+		ConnMaxLifetime: lifetime,
+	}
+	session, err := dbsession.MakeDBSessionWithPool(settings.Dialect, settings.DSN, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	// The search index is built from the database and then kept up to date by
+	// the repositories.
+	index, err := search.Build(session)
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	slog.Info("indexed the people and films for searching", slog.Int("count", index.Len()))
+
+	var svc services.ConcreteServices
+	svc.SetDBSession(session)
+	svc.SetSearchIndex(index)
+	svc.SetPeopleRepository(peopleRepo.MakeIndexedRepo(session, index))
+	svc.SetFilmRepository(filmsRepo.MakeIndexedRepo(session, index))
+	svc.SetCreditRepository(creditsRepo.MakeRepo(session))
+	svc.SetTaxonomyRepository(taxonomyRepo.MakeRepo(session))
+	svc.SetCompanyRepository(companiesRepo.MakeRepo(session))
+	svc.SetReviewRepository(reviewsRepo.MakeRepo(session))
+	svc.SetWatchlistRepository(watchlistRepo.MakeRepo(session))
+	svc.SetDiaryRepository(diaryRepo.MakeRepo(session))
+	svc.SetAwardRepository(awardsRepo.MakeRepo(session))
+	userRepo := usersRepo.MakeRepo(session)
+	if settings.Dialect == dbsession.DialectMemory {
+		err = addMemoryAdmin(userRepo)
+		if err != nil {
+			session.Close()
+			return nil, err
+		}
+	}
+	svc.SetUserRepository(userRepo)
+	svc.SetSessions(sessions)
+	svc.SetTemplates(page)
+	// The retention has already been checked by settings.Validate().
+	retention, _ := settings.TrashRetentionDuration()
+	svc.SetTrashRetention(retention)
+	appServices = &svc
+	return appServices, nil
+}
+
+// memoryAdmin is the user created in the in-memory database.
+const memoryAdmin = "admin"
+
+// addMemoryAdmin adds a user to the in-memory database, which starts empty, so
+// that someone can log in.  The password is random and it's shown on stderr.
+func addMemoryAdmin(repo usersRepo.Repository) error {
+	key := make([]byte, 12)
+	_, err := rand.Read(key)
+	if err != nil {
+		return err
+	}
+	password := base64.RawURLEncoding.EncodeToString(key)
+	_, err = repo.Add(memoryAdmin, password, userModel.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	slog.Info("added a user to the in-memory database", slog.String("username", memoryAdmin))
+	fmt.Fprintf(os.Stderr, "log in as %s with password %s\n", memoryAdmin, password)
+	return nil
+}
+
+// findRole finds the role of the user who is logged in, using the user
+// repository attached to the request by the services filter.  If the user has
+// been removed since they logged in, it returns an error.
+func findRole(req *restful.Request, username string) (string, error) {
+	svc := services.FromRequest(req)
+	if svc == nil {
+		return "", fmt.Errorf("no services attached to the request")
+	}
+	user, err := svc.GetUserRepository().WithContext(req.Request.Context()).FindByUsername(username)
+	if err != nil {
+		return "", err
+	}
+	return user.Role(), nil
+}
+
+// closeServices closes the database session, if it was opened, and releases
+// its connections.
+func closeServices() {
+	servicesMutex.Lock()
+	defer servicesMutex.Unlock()
+
+	if appServices != nil {
+		slog.Info("closing the database")
+		appServices.GetDBSession().Close()
+		appServices = nil
+	}
+}
+
+// displayErrorPage sends the static error page with the given HTTP status.  It's
+// used when a request can't be passed to a controller, for example because the
+// database is unavailable.
+func displayErrorPage(response *restful.Response, status int) {
+	response.WriteHeader(status)
+	errorPage := (*page)["Error"]
+	if errorPage == nil {
+		utilities.Dead(response)
+		return
+	}
+	err := errorPage.Execute(response.ResponseWriter, nil)
+	if err != nil {
+		slog.Error("cannot display the error page", slog.Any("error", err))
+	}
+}
+
+// displayForbiddenPage sends the forbidden page with status 403 and the given
+// error message.  It's used when a filter refuses a request.
+func displayForbiddenPage(req *restful.Request, resp *restful.Response, errormessage string) {
+	utilities.Forbidden(req, resp, utilities.TemplateMap(*page), errormessage)
+}
+
+// setUpLog sets up the default logger on stderr with the level and format
+// from the settings.  At debug level each message shows where it came from.
+// Anything still written with the log package goes to the same place, at info
+// level.
+func setUpLog() error {
+	logger, err := logging.New(os.Stderr, settings.LogFormat, settings.LogLevel)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
 }
 
 // createPeopleTemplates creates a map to serve out the templates for the people
-// controller.  If anything goes wrong, the Must call will panic.  The function
-// assumes that the current directory contains a views directory containing the
-// views.
-func createPeopleTemplates() *map[string]retroTemplate.Template {
+// controller.  If anything goes wrong, the Must call will panic.  The views are
+// in the given directory.
+func createPeopleTemplates(views string) *map[string]retroTemplate.Template {
 
 	templates := make(map[string]retroTemplate.Template)
 
 	// This is the template for the error page, shared by all controllers.
 	errorTP := template.Must(template.ParseFiles(
-		"views/html/error.html"))
+		filepath.Join(views, "html/error.html")))
 
 	templates["Error"] = errorTP
 
+	// This is the page that says an action isn't allowed, also shared.
+	templates["Forbidden"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/forbidden.ghtml"),
+	))
+
 	peopleIndexTP := template.Must(template.ParseFiles(
-		"views/templates/_base.ghtml",
-		"views/templates/people/index.ghtml",
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/people/index.ghtml"),
 	))
 
 	// var peopleIndexTP template.ConcreteTemplate
@@ -140,8 +441,8 @@ func createPeopleTemplates() *map[string]retroTemplate.Template {
 	templates["Index"] = peopleIndexTP
 
 	peopleCreateTP := template.Must(template.ParseFiles(
-		"views/templates/_base.ghtml",
-		"views/templates/people/create.ghtml",
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/people/create.ghtml"),
 	))
 
 	//var peopleCreateTP template.ConcreteTemplate
@@ -149,8 +450,8 @@ func createPeopleTemplates() *map[string]retroTemplate.Template {
 	templates["Create"] = peopleCreateTP
 
 	peopleShowTP := template.Must(template.ParseFiles(
-		"views/templates/_base.ghtml",
-		"views/templates/people/show.ghtml",
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/people/show.ghtml"),
 	))
 
 	//var peopleShowTP template.ConcreteTemplate
@@ -158,187 +459,162 @@ func createPeopleTemplates() *map[string]retroTemplate.Template {
 	templates["Show"] = peopleShowTP
 
 	peopleEditTP := template.Must(template.ParseFiles(
-		"views/templates/_base.ghtml",
-		"views/templates/people/edit.ghtml",
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/people/edit.ghtml"),
 	))
 
 	//var peopleEditTP template.ConcreteTemplate
 	//peopleEditTP.SetHTMLTemplate(tp)
 	templates["Edit"] = peopleEditTP
 
+	templates["History"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/people/history.ghtml"),
+	))
+
+	templates["Trash"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/people/trash.ghtml"),
+	))
+
 	return &templates
 }
 
-// marshall passes the request and response to the appropriate method of the
-// appropriate  controller.
-func marshall(request *restful.Request, response *restful.Response) {
+// addFilmTemplates adds the templates for the films controller to the given
+// map.  Their names are prefixed with "Film" to distinguish them from those of
+// the people controller.  If anything goes wrong, the Must call will panic.  The
+// views are in the given directory.
+func addFilmTemplates(templates *map[string]retroTemplate.Template, views string) {
 
-	log.SetPrefix("main.marshall() ")
+	(*templates)["FilmIndex"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/films/index.ghtml"),
+	))
 
-	defer catchPanic()
+	(*templates)["FilmCreate"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/films/create.ghtml"),
+	))
 
-	// Create a service supplier
-	session, err := dbsession.MakeGorpMysqlDBSession()
-	if err != nil {
-		log.Println(err.Error())
-		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(-1)
-	}
-	var repo peopleRepo.GorpMysqlRepo
-	repo.SetSession(session)
-	var services services.ConcreteServices
-	services.SetPeopleRepository(&repo)
-	services.SetTemplates(page)
-
-	uri := request.Request.URL.RequestURI()
-
-	log.Println("uri=", uri)
-
-	// The REST model uses HTTP requests such as PUT and DELETE.  The standard browsers do not support
-	// these operations, so they are implemented using a POST request with a parameter "_method"
-	// defining the operation.  (A post with a parameter "_method=PUT" simulates a PUT, and so on.)
-
-	method := request.Request.Method
-	if method == "POST" {
-		// handle simulated PUT, DELETE etc via the _method parameter
-		simMethod := request.Request.FormValue("_method")
-		if simMethod == "PUT" || simMethod == "DELETE" {
-			method = simMethod
-		}
-	}
-	log.Printf("method %s", method)
-
-	if peopleRequestRE.MatchString(uri) {
-
-		log.Printf("Sending request %s to PeopleController\n", uri)
-
-		var controller = peopleController.MakeController(&services)
-
-		// Call the appropriate handler for the request
-
-		switch method {
-
-		case "GET":
-
-			if uri == "/people" {
-				// "GET http://server:port/people" - fetch all the valid people
-				// records and display them.
-				var form forms.ConcreteListForm
-				controller.Index(request, response, &form)
-
-			} else if peopleEditRequestRE.MatchString(uri) {
-
-				// "GET http://server:port/people/1/edit" - fetch the people record
-				// given by the ID in the request and display the form to edit it.
-				var form forms.ConcretePersonForm
-				controller.Edit(request, response, &form)
-
-			} else if uri == "/people/create" {
-
-				// "GET http://server:port/people/create" - display the form to
-				// create a new people record.
-				var form forms.ConcretePersonForm
-				// Create an empty person to get started.
-				person := personModel.MakePerson()
-				form.SetPerson(person)
-				controller.New(request, response, &form)
-
-			} else if peopleShowRequestRE.MatchString(uri) {
-
-				// "GET http://server:port/people/435" - fetch the people record
-				// with ID 435 and display it.
-
-				// Pass the ID to the controller via the form - get the ID from
-				// the request, create a person containing (just) that ID, put that
-				// person into the form.
-				var form forms.ConcretePersonForm
-				idStr := request.PathParameter("id")
-				log.Printf("show id=%s", idStr)
-				id, err := strconv.ParseUint(idStr, 10, 64)
-				if err != nil {
-					// This request is normally made from a link in a view.  The
-					// link should always be correct, so this should never happen!
-					em := fmt.Sprintf("illegal id %s", idStr)
-					log.Println(em)
-					controller.ErrorHandler(request, response, em)
-				}
-				person := personModel.MakePerson()
-				person.SetID(id)
-				form.SetPerson(person)
-				controller.Show(request, response, &form)
-			}
-
-		case "PUT":
-			if peopleUpdateRequestRE.MatchString(uri) {
-
-				// POST http://server:port/people/1" - update the people record with
-				// the given ID from the URI using the form data in the body.
-				form := getPersonFormFromRequest(request, response, controller,
-					&services)
-				controller.Update(request, response, form)
-
-			} else if uri == "/people" {
-
-				// POST http://server:port/people" - create a new people record from
-				// the form data in the body.
-				form := getPersonFormFromRequest(request, response, controller,
-					&services)
-				controller.Create(request, response, form)
-			}
-
-		case "DELETE":
-			if peopleDeleteRequestRE.MatchString(uri) {
-
-				// "POST http://server:port/people/1/delete" - delete the people
-				// record with the ID given in the request.
-				controller.Delete(request, response)
-			}
-
-		default:
-			em := fmt.Sprintf("unexpected HTTP method %v", method)
-			log.Println(em)
-			controller.ErrorHandler(request, response, em)
-		}
-	}
+	(*templates)["FilmShow"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/films/show.ghtml"),
+	))
+
+	(*templates)["FilmEdit"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/films/edit.ghtml"),
+	))
 }
 
-// getPersonFormFromRequest gets the person data from the request, creates a
-// GorpMySQLPerson and returns it in a PersonForm.
-func getPersonFormFromRequest(req *restful.Request, resp *restful.Response,
-	c peopleController.Controller, services services.Services) forms.PersonForm {
+// addSearchTemplates adds the template for the search page to the given map.  If
+// anything goes wrong, the Must call will panic.  The views are in the given
+// directory.
+func addSearchTemplates(templates *map[string]retroTemplate.Template, views string) {
 
-	log.SetPrefix("getPersonFormFromRequest() ")
+	(*templates)["SearchIndex"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/search/index.ghtml"),
+	))
+}
 
-	err := req.Request.ParseForm()
-	if err != nil {
-		em := fmt.Sprintf("cannot parse form - %s", err.Error())
-		log.Printf("%s\n", em)
-		c.ErrorHandler(req, resp, em)
-		return nil
-	}
-	var form forms.ConcretePersonForm
-	var person personModel.GorpMysqlPerson
-	idStr := req.PathParameter("id")
-	if idStr != "" {
-		id, err := strconv.ParseUint(idStr, 10, 64)
-		if err != nil {
-			em := fmt.Sprintf("invalid id %v in request - should be numeric", idStr)
-			log.Printf("%s\n", em)
-			c.ErrorHandler(req, resp, em)
-			return nil
-		}
-		person.SetID(id)
-	}
-	person.SetForename(strings.TrimSpace(req.Request.FormValue("forename")))
-	person.SetSurname(strings.TrimSpace(req.Request.FormValue("surname")))
-	form.SetPerson(&person)
-	log.Printf("form %s\n", form.String())
-	return &form
+// addTagTemplates adds the templates for the tag cloud and the page for each tag
+// to the given map.  If anything goes wrong, the Must call will panic.  The views
+// are in the given directory.
+func addTagTemplates(templates *map[string]retroTemplate.Template, views string) {
+
+	(*templates)["TagIndex"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/tags/index.ghtml"),
+	))
+
+	(*templates)["TagShow"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/tags/show.ghtml"),
+	))
 }
 
-// Recover from any panic and log an error.
-func catchPanic() {
-	if p := recover(); p != nil {
-		log.Printf("unrecoverable internal error %v\n", p)
-	}
+// addCompanyTemplates adds the templates for the companies controller to the
+// given map.  Their names are prefixed with "Company".  If anything goes wrong,
+// the Must call will panic.  The views are in the given directory.
+func addCompanyTemplates(templates *map[string]retroTemplate.Template, views string) {
+
+	(*templates)["CompanyIndex"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/companies/index.ghtml"),
+	))
+
+	(*templates)["CompanyCreate"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/companies/create.ghtml"),
+	))
+
+	(*templates)["CompanyShow"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/companies/show.ghtml"),
+	))
+
+	(*templates)["CompanyEdit"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/companies/edit.ghtml"),
+	))
+}
+
+// addWatchlistTemplates adds the template for the watchlist controller to the
+// given map.  If anything goes wrong, the Must call will panic.  The views are in
+// the given directory.
+func addWatchlistTemplates(templates *map[string]retroTemplate.Template, views string) {
+
+	(*templates)["WatchlistIndex"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/watchlist/index.ghtml"),
+	))
+}
+
+// addDiaryTemplates adds the template for the diary controller to the given map.
+// If anything goes wrong, the Must call will panic.  The views are in the given
+// directory.
+func addDiaryTemplates(templates *map[string]retroTemplate.Template, views string) {
+
+	(*templates)["DiaryIndex"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/diary/index.ghtml"),
+	))
+}
+
+// addAwardTemplates adds the templates for the awards controller to the given
+// map.  Their names are prefixed with "Award".  If anything goes wrong, the Must
+// call will panic.  The views are in the given directory.
+func addAwardTemplates(templates *map[string]retroTemplate.Template, views string) {
+
+	(*templates)["AwardIndex"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/awards/index.ghtml"),
+	))
+
+	(*templates)["AwardCreate"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/awards/create.ghtml"),
+	))
+
+	(*templates)["AwardShow"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/awards/show.ghtml"),
+	))
+
+	(*templates)["AwardEdit"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/awards/edit.ghtml"),
+	))
+}
+
+// addLoginTemplates adds the template for the login page to the given map.  If
+// anything goes wrong, the Must call will panic.  The views are in the given
+// directory.
+func addLoginTemplates(templates *map[string]retroTemplate.Template, views string) {
+
+	(*templates)["Login"] = template.Must(template.ParseFiles(
+		filepath.Join(views, "templates/_base.ghtml"),
+		filepath.Join(views, "templates/login/login.ghtml"),
+	))
 }
@@ -0,0 +1,63 @@
+package awards
+
+import (
+	awardModel "github.com/goblimey/films/models/award"
+	filmModel "github.com/goblimey/films/models/film"
+	personModel "github.com/goblimey/films/models/person"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// CeremonyForm holds view data about an award Ceremony.  It's used as a data
+// transfer object (DTO) in particular for use with views that handle a Ceremony.
+// (It's approximately equivalent to a Struts form bean.)  It contains a Ceremony,
+// its categories and their nominations and the films and people who could be
+// nominated; a validator function that validates the data in the Ceremony and sets
+// the various error messages; a general error message (for errors not associated
+// with an individual field of the Ceremony), a notice (for announcements that are
+// not about errors) and a set of error messages about individual fields of the
+// Ceremony.
+type CeremonyForm interface {
+	// Ceremony gets the Ceremony embedded in the form.
+	Ceremony() awardModel.Ceremony
+	// Categories gets the categories of the ceremony.
+	Categories() []awardModel.Category
+	// Nominations gets the nominations in all of the categories.
+	Nominations() []awardModel.Nomination
+	// Films gets the films that could be nominated.
+	Films() []filmModel.Film
+	// People gets the people who could be nominated.
+	People() []personModel.Person
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// FieldErrors returns all the field errors as a map.
+	FieldErrors() map[string]string
+	// ErrorForField returns the error message about a field (may be an empty string).
+	ErrorForField(key string) string
+	// String returns a string version of the CeremonyForm.
+	String() string
+	// SetCeremony sets the Ceremony in the form.
+	SetCeremony(ceremony awardModel.Ceremony)
+	// SetCategories sets the categories of the ceremony.
+	SetCategories(categories []awardModel.Category)
+	// SetNominations sets the nominations in all of the categories.
+	SetNominations(nominations []awardModel.Nomination)
+	// SetFilms sets the films that could be nominated.
+	SetFilms(films []filmModel.Film)
+	// SetPeople sets the people who could be nominated.
+	SetPeople(people []personModel.Person)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	//SetErrorMessage sets the general error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+	// SetErrorMessageForField sets the error message for a named field
+	SetErrorMessageForField(fieldname, errormessage string)
+	// Validate validates the data in the Ceremony and sets the various error
+	// messages.  It returns true if the data is valid, false if there are errors.
+	Validate() bool
+}
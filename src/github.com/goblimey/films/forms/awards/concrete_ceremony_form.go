@@ -0,0 +1,192 @@
+package awards
+
+import (
+	"fmt"
+
+	awardModel "github.com/goblimey/films/models/award"
+	filmModel "github.com/goblimey/films/models/film"
+	personModel "github.com/goblimey/films/models/person"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// MinYear is the earliest year that the form accepts for a ceremony.  The first
+// film awards were given out in the 1920s.
+const MinYear = 1900
+
+// MaxYear is the latest year that the form accepts.
+const MaxYear = 2100
+
+// MaxNameLength is the longest name of a ceremony or a category that the tables
+// can hold.
+const MaxNameLength = 255
+
+// ConcreteCeremonyForm satisfies the CeremonyForm interface.
+type ConcreteCeremonyForm struct {
+	ceremony     awardModel.Ceremony
+	categories   []awardModel.Category
+	nominations  []awardModel.Nomination
+	films        []filmModel.Film
+	people       []personModel.Person
+	errorMessage string
+	viewer       auth.Viewer
+	notice       string
+	fieldError   map[string]string
+}
+
+// Getters
+
+// Ceremony gets the Ceremony embedded in the form.
+func (cf ConcreteCeremonyForm) Ceremony() awardModel.Ceremony {
+	return cf.ceremony
+}
+
+// Categories gets the categories of the ceremony.
+func (cf ConcreteCeremonyForm) Categories() []awardModel.Category {
+	return cf.categories
+}
+
+// Nominations gets the nominations in all of the categories.
+func (cf ConcreteCeremonyForm) Nominations() []awardModel.Nomination {
+	return cf.nominations
+}
+
+// Films gets the films that could be nominated.
+func (cf ConcreteCeremonyForm) Films() []filmModel.Film {
+	return cf.films
+}
+
+// People gets the people who could be nominated.
+func (cf ConcreteCeremonyForm) People() []personModel.Person {
+	return cf.people
+}
+
+// Notice gets the notice.
+func (cf ConcreteCeremonyForm) Notice() string {
+	return cf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (cf ConcreteCeremonyForm) ErrorMessage() string {
+	return cf.errorMessage
+}
+
+// FieldErrors returns all the field errors as a map.
+func (cf ConcreteCeremonyForm) FieldErrors() map[string]string {
+	return cf.fieldError
+}
+
+// ErrorForField returns the error message about a field (may be an empty string).
+func (cf ConcreteCeremonyForm) ErrorForField(key string) string {
+	if cf.fieldError == nil {
+		// The field error map has not been set up.
+		return ""
+	}
+	return cf.fieldError[key]
+}
+
+// String returns a string version of the CeremonyForm.
+func (cf ConcreteCeremonyForm) String() string {
+	return fmt.Sprintf("ConcreteCeremonyForm={ceremony=%s, notice=%s,errorMessage=%s,fieldError=%s}",
+		cf.ceremony,
+		cf.notice,
+		cf.errorMessage,
+		utilities.Map2String(cf.fieldError))
+}
+
+// Nominees is a helper for the show page.  It returns the nominations in the
+// given category, in the order that they are held in the form, which puts the
+// winners first.
+func (cf ConcreteCeremonyForm) Nominees(category awardModel.Category) []awardModel.Nomination {
+	nominees := make([]awardModel.Nomination, 0)
+	for _, nomination := range cf.nominations {
+		if nomination.CategoryID() == category.ID() {
+			nominees = append(nominees, nomination)
+		}
+	}
+	return nominees
+}
+
+// Setters
+
+// SetCeremony sets the Ceremony in the form.
+func (cf *ConcreteCeremonyForm) SetCeremony(ceremony awardModel.Ceremony) {
+	cf.ceremony = ceremony
+}
+
+// SetCategories sets the categories of the ceremony.
+func (cf *ConcreteCeremonyForm) SetCategories(categories []awardModel.Category) {
+	cf.categories = categories
+}
+
+// SetNominations sets the nominations in all of the categories.
+func (cf *ConcreteCeremonyForm) SetNominations(nominations []awardModel.Nomination) {
+	cf.nominations = nominations
+}
+
+// SetFilms sets the films that could be nominated.
+func (cf *ConcreteCeremonyForm) SetFilms(films []filmModel.Film) {
+	cf.films = films
+}
+
+// SetPeople sets the people who could be nominated.
+func (cf *ConcreteCeremonyForm) SetPeople(people []personModel.Person) {
+	cf.people = people
+}
+
+// SetNotice sets the notice.
+func (cf *ConcreteCeremonyForm) SetNotice(notice string) {
+	cf.notice = notice
+}
+
+// SetErrorMessage sets the general error message.
+func (cf *ConcreteCeremonyForm) SetErrorMessage(errorMessage string) {
+	cf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (cf ConcreteCeremonyForm) Viewer() auth.Viewer {
+	return cf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (cf *ConcreteCeremonyForm) SetViewer(viewer auth.Viewer) {
+	cf.viewer = viewer
+}
+
+// SetErrorMessageForField sets the error message for a named field
+func (cf *ConcreteCeremonyForm) SetErrorMessageForField(fieldname, errormessage string) {
+	if cf.fieldError == nil {
+		cf.fieldError = make(map[string]string)
+	}
+	cf.fieldError[fieldname] = errormessage
+}
+
+// Validate validates the data in the Ceremony and sets the various error messages.
+// It returns true if the data is valid, false if there are errors.  Any field
+// errors already recorded (for example, a year in the HTTP request that could not
+// be converted to a number) also cause the validation to fail.
+func (cf *ConcreteCeremonyForm) Validate() bool {
+	ceremony := cf.Ceremony()
+	// trim all string items
+	ceremony.SetName(utilities.Trim(ceremony.Name()))
+	// validate
+	valid := len(cf.fieldError) == 0
+
+	if len(ceremony.Name()) <= 0 {
+		cf.SetErrorMessageForField("Name", "you must specify the Name")
+		valid = false
+	} else if len(ceremony.Name()) > MaxNameLength {
+		cf.SetErrorMessageForField("Name",
+			fmt.Sprintf("the Name must be no more than %d characters", MaxNameLength))
+		valid = false
+	}
+	if cf.ErrorForField("Year") == "" &&
+		(ceremony.Year() < MinYear || ceremony.Year() > MaxYear) {
+
+		cf.SetErrorMessageForField("Year",
+			fmt.Sprintf("the Year must be between %d and %d", MinYear, MaxYear))
+		valid = false
+	}
+	return valid
+}
@@ -0,0 +1,98 @@
+package awards
+
+import (
+	"strings"
+	"testing"
+
+	awardModel "github.com/goblimey/films/models/award"
+	model "github.com/goblimey/films/models/award/gorpmysql"
+)
+
+var expectedID uint64 = 42
+var expectedName = "Academy Awards"
+var expectedYear = 1995
+
+// Create a ceremony and a ConcreteCeremonyForm containing it.  Retrieve the
+// ceremony.
+func TestUnitCreateCeremonyFormAndRetrieveCeremony(t *testing.T) {
+	form := CreateCeremonyForm(expectedID, " "+expectedName+" ", expectedYear)
+	if form.Ceremony().ID() != expectedID {
+		t.Errorf("Expected ID to be %d actually %d", expectedID, form.Ceremony().ID())
+	}
+	if !form.Validate() {
+		t.Errorf("Expected the validation to succeed, got errors %v", form.FieldErrors())
+	}
+	if form.Ceremony().Name() != expectedName {
+		t.Errorf("Expected name to be %s actually %s", expectedName, form.Ceremony().Name())
+	}
+}
+
+// Check the limits on the name and the year.
+func TestUnitCreateCeremonyBadFields(t *testing.T) {
+	var tests = []struct {
+		name    string
+		year    int
+		field   string
+		message string
+	}{
+		{"  ", expectedYear, "Name", "you must specify the Name"},
+		{strings.Repeat("x", MaxNameLength+1), expectedYear, "Name",
+			"the Name must be no more than 255 characters"},
+		{expectedName, 0, "Year", "the Year must be between 1900 and 2100"},
+		{expectedName, 3000, "Year", "the Year must be between 1900 and 2100"},
+	}
+	for _, test := range tests {
+		form := CreateCeremonyForm(expectedID, test.name, test.year)
+		if form.Validate() {
+			t.Errorf("%s: expected the validation to fail", test.field)
+		}
+		if form.ErrorForField(test.field) != test.message {
+			t.Errorf("%s: expected \"%s\", got \"%s\"", test.field, test.message,
+				form.ErrorForField(test.field))
+		}
+		if len(form.FieldErrors()) != 1 {
+			t.Errorf("%s: expected 1 error, got %d", test.field, len(form.FieldErrors()))
+		}
+	}
+}
+
+// A field error recorded before validation (for example, a year that was not a
+// number) causes the validation to fail and is not overwritten.
+func TestUnitCreateCeremonyWithPreviousFieldError(t *testing.T) {
+	expectedError := "the Year must be a number"
+	form := CreateCeremonyForm(expectedID, expectedName, 0)
+	form.SetErrorMessageForField("Year", expectedError)
+	if form.Validate() {
+		t.Errorf("Expected the validation to fail - previous field error")
+	}
+	if form.ErrorForField("Year") != expectedError {
+		t.Errorf("Expected \"%s\", got \"%s\"", expectedError, form.ErrorForField("Year"))
+	}
+}
+
+// Nominees returns the nominations in the given category, in their order in the
+// form.
+func TestUnitNominees(t *testing.T) {
+	form := CreateCeremonyForm(expectedID, expectedName, expectedYear)
+	actor := model.MakeInitialisedCategory(1, expectedID, "Best Actor")
+	picture := model.MakeInitialisedCategory(2, expectedID, "Best Picture")
+	form.SetNominations([]awardModel.Nomination{
+		model.MakeInitialisedNomination(1, actor.ID(), 7, 3, true),
+		model.MakeInitialisedNomination(2, picture.ID(), 7, 0, true),
+		model.MakeInitialisedNomination(3, picture.ID(), 8, 0, false),
+	})
+	nominees := form.Nominees(picture)
+	if len(nominees) != 2 || nominees[0].ID() != 2 || nominees[1].ID() != 3 {
+		t.Errorf("Expected nominations 2 and 3, got %v", nominees)
+	}
+	if len(form.Nominees(model.MakeInitialisedCategory(3, expectedID, "Best Director"))) != 0 {
+		t.Errorf("Expected no nominees for a category with no nominations")
+	}
+}
+
+func CreateCeremonyForm(id uint64, name string, year int) ConcreteCeremonyForm {
+	ceremony := model.MakeInitialisedCeremony(id, name, year)
+	var form ConcreteCeremonyForm
+	form.SetCeremony(ceremony)
+	return form
+}
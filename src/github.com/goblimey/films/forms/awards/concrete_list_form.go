@@ -0,0 +1,56 @@
+package awards
+
+import (
+	awardModel "github.com/goblimey/films/models/award"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteListForm satisfies the ListForm interface and holds view data
+// including a list of award ceremonies.  It's approximately equivalent to a Struts
+// form bean.
+type ConcreteListForm struct {
+	ceremonies   []awardModel.Ceremony
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// Ceremonies returns the list of Ceremony objects from the form
+func (clf *ConcreteListForm) Ceremonies() []awardModel.Ceremony {
+	return clf.ceremonies
+}
+
+// Notice gets the notice.
+func (clf *ConcreteListForm) Notice() string {
+	return clf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (clf *ConcreteListForm) ErrorMessage() string {
+	return clf.errorMessage
+}
+
+// SetCeremonies sets the list of Ceremonies.
+func (clf *ConcreteListForm) SetCeremonies(ceremonies []awardModel.Ceremony) {
+	clf.ceremonies = ceremonies
+}
+
+// SetNotice sets the notice.
+func (clf *ConcreteListForm) SetNotice(notice string) {
+	clf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (clf *ConcreteListForm) SetErrorMessage(errorMessage string) {
+	clf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (clf *ConcreteListForm) Viewer() auth.Viewer {
+	return clf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (clf *ConcreteListForm) SetViewer(viewer auth.Viewer) {
+	clf.viewer = viewer
+}
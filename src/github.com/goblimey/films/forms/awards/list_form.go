@@ -0,0 +1,27 @@
+package awards
+
+import (
+	awardModel "github.com/goblimey/films/models/award"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ListForm holds view data including a list of award ceremonies.  It's
+// approximately equivalent to a Struts form bean.
+type ListForm interface {
+	// Ceremonies returns the list of Ceremony objects from the form
+	Ceremonies() []awardModel.Ceremony
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetCeremonies sets the list of Ceremonies in the form.
+	SetCeremonies([]awardModel.Ceremony)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	//SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
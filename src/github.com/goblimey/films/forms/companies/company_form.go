@@ -0,0 +1,47 @@
+package companies
+
+import (
+	companyModel "github.com/goblimey/films/models/company"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// CompanyForm holds view data about a Company.  It's used as a data transfer object
+// (DTO) in particular for use with views that handle a Company.  (It's
+// approximately equivalent to a Struts form bean.)  It contains a Company; a
+// validator function that validates the data in the Company and sets the various
+// error messages; a general error message (for errors not associated with an
+// individual field of the Company), a notice (for announcements that are not about
+// errors) and a set of error messages about individual fields of the Company.
+type CompanyForm interface {
+	// Company gets the Company embedded in the form.
+	Company() companyModel.Company
+	// Links gets the links to the films that the company made or distributed.
+	Links() []companyModel.Link
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// FieldErrors returns all the field errors as a map.
+	FieldErrors() map[string]string
+	// ErrorForField returns the error message about a field (may be an empty string).
+	ErrorForField(key string) string
+	// String returns a string version of the CompanyForm.
+	String() string
+	// SetCompany sets the Company in the form.
+	SetCompany(company companyModel.Company)
+	// SetLinks sets the links to the company's films.
+	SetLinks(links []companyModel.Link)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	//SetErrorMessage sets the general error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+	// SetErrorMessageForField sets the error message for a named field
+	SetErrorMessageForField(fieldname, errormessage string)
+	// Validate validates the data in the Company and sets the various error messages.
+	// It returns true if the data is valid, false if there are errors.
+	Validate() bool
+}
@@ -0,0 +1,172 @@
+package companies
+
+import (
+	"fmt"
+
+	companyModel "github.com/goblimey/films/models/company"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// MinYear is the earliest year that the form accepts for the founding or the
+// closure of a company.  Some film companies started out in other trades, so it's
+// well before the first films.
+const MinYear = 1800
+
+// MaxYear is the latest year that the form accepts.
+const MaxYear = 2100
+
+// MaxNameLength is the longest name that the companies table can hold.
+const MaxNameLength = 255
+
+// MaxCountryLength is the longest country that the companies table can hold.
+const MaxCountryLength = 64
+
+// ConcreteCompanyForm satisfies the CompanyForm interface.
+type ConcreteCompanyForm struct {
+	company      companyModel.Company
+	links        []companyModel.Link
+	errorMessage string
+	viewer       auth.Viewer
+	notice       string
+	fieldError   map[string]string
+}
+
+// Getters
+
+// Company gets the Company embedded in the form.
+func (cf ConcreteCompanyForm) Company() companyModel.Company {
+	return cf.company
+}
+
+// Links gets the links to the films that the company made or distributed.
+func (cf ConcreteCompanyForm) Links() []companyModel.Link {
+	return cf.links
+}
+
+// Notice gets the notice.
+func (cf ConcreteCompanyForm) Notice() string {
+	return cf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (cf ConcreteCompanyForm) ErrorMessage() string {
+	return cf.errorMessage
+}
+
+// FieldErrors returns all the field errors as a map.
+func (cf ConcreteCompanyForm) FieldErrors() map[string]string {
+	return cf.fieldError
+}
+
+// ErrorForField returns the error message about a field (may be an empty string).
+func (cf ConcreteCompanyForm) ErrorForField(key string) string {
+	if cf.fieldError == nil {
+		// The field error map has not been set up.
+		return ""
+	}
+	return cf.fieldError[key]
+}
+
+// String returns a string version of the CompanyForm.
+func (cf ConcreteCompanyForm) String() string {
+	return fmt.Sprintf("ConcreteCompanyForm={company=%s, notice=%s,errorMessage=%s,fieldError=%s}",
+		cf.company,
+		cf.notice,
+		cf.errorMessage,
+		utilities.Map2String(cf.fieldError))
+}
+
+// Setters
+
+// SetCompany sets the Company in the form.
+func (cf *ConcreteCompanyForm) SetCompany(company companyModel.Company) {
+	cf.company = company
+}
+
+// SetLinks sets the links to the company's films.
+func (cf *ConcreteCompanyForm) SetLinks(links []companyModel.Link) {
+	cf.links = links
+}
+
+// SetNotice sets the notice.
+func (cf *ConcreteCompanyForm) SetNotice(notice string) {
+	cf.notice = notice
+}
+
+// SetErrorMessage sets the general error message.
+func (cf *ConcreteCompanyForm) SetErrorMessage(errorMessage string) {
+	cf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (cf ConcreteCompanyForm) Viewer() auth.Viewer {
+	return cf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (cf *ConcreteCompanyForm) SetViewer(viewer auth.Viewer) {
+	cf.viewer = viewer
+}
+
+// SetErrorMessageForField sets the error message for a named field
+func (cf *ConcreteCompanyForm) SetErrorMessageForField(fieldname, errormessage string) {
+	if cf.fieldError == nil {
+		cf.fieldError = make(map[string]string)
+	}
+	cf.fieldError[fieldname] = errormessage
+}
+
+// Validate validates the data in the Company and sets the various error messages.
+// It returns true if the data is valid, false if there are errors.  The years are
+// optional - 0 means not known or, for the year that the company ceased trading,
+// that it's still going.  Any field errors already recorded (for example, a year
+// in the HTTP request that could not be converted to a number) also cause the
+// validation to fail.
+func (cf *ConcreteCompanyForm) Validate() bool {
+	company := cf.Company()
+	// trim all string items
+	company.SetName(utilities.Trim(company.Name()))
+	company.SetCountry(utilities.Trim(company.Country()))
+	// validate
+	valid := len(cf.fieldError) == 0
+
+	if len(company.Name()) <= 0 {
+		cf.SetErrorMessageForField("Name", "you must specify the Name")
+		valid = false
+	} else if len(company.Name()) > MaxNameLength {
+		cf.SetErrorMessageForField("Name",
+			fmt.Sprintf("the Name must be no more than %d characters", MaxNameLength))
+		valid = false
+	}
+	if len(company.Country()) > MaxCountryLength {
+		cf.SetErrorMessageForField("Country",
+			fmt.Sprintf("the Country must be no more than %d characters", MaxCountryLength))
+		valid = false
+	}
+	if cf.ErrorForField("Founded") == "" && !validYear(company.Founded()) {
+		cf.SetErrorMessageForField("Founded",
+			fmt.Sprintf("the Founded year must be between %d and %d", MinYear, MaxYear))
+		valid = false
+	}
+	if cf.ErrorForField("Defunct") == "" && !validYear(company.Defunct()) {
+		cf.SetErrorMessageForField("Defunct",
+			fmt.Sprintf("the Defunct year must be between %d and %d", MinYear, MaxYear))
+		valid = false
+	}
+	if cf.ErrorForField("Founded") == "" && cf.ErrorForField("Defunct") == "" &&
+		company.Founded() != 0 && company.Defunct() != 0 &&
+		company.Defunct() < company.Founded() {
+
+		cf.SetErrorMessageForField("Defunct",
+			"the Defunct year must not be before the Founded year")
+		valid = false
+	}
+	return valid
+}
+
+// validYear returns true if the given year is 0 (not known) or is between MinYear
+// and MaxYear.
+func validYear(year int) bool {
+	return year == 0 || (year >= MinYear && year <= MaxYear)
+}
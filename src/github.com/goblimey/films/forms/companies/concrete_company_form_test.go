@@ -0,0 +1,109 @@
+package companies
+
+import (
+	"strings"
+	"testing"
+
+	model "github.com/goblimey/films/models/company/gorpmysql"
+)
+
+var expectedID uint64 = 42
+var expectedName = "Ealing Studios"
+var expectedCountry = "UK"
+var expectedFounded = 1902
+var expectedDefunct = 1959
+
+// Create a company and a ConcreteCompanyForm containing it.  Retrieve the company.
+func TestUnitCreateCompanyFormAndRetrieveCompany(t *testing.T) {
+	form := CreateCompanyForm(expectedID, expectedName, expectedCountry,
+		expectedFounded, expectedDefunct)
+	if form.Company().ID() != expectedID {
+		t.Errorf("Expected ID to be %d actually %d", expectedID, form.Company().ID())
+	}
+	if form.Company().Name() != expectedName {
+		t.Errorf("Expected name to be %s actually %s", expectedName, form.Company().Name())
+	}
+	if !form.Validate() {
+		t.Errorf("Expected the validation to succeed, got errors %v", form.FieldErrors())
+	}
+}
+
+// The years are optional.
+func TestUnitCreateCompanyNoYears(t *testing.T) {
+	form := CreateCompanyForm(expectedID, expectedName, "", 0, 0)
+	if !form.Validate() {
+		t.Errorf("Expected the validation to succeed, got errors %v", form.FieldErrors())
+	}
+}
+
+// Create a company form containing a company with no name, and validate it.
+func TestUnitCreateCompanyNoName(t *testing.T) {
+	expectedError := "you must specify the Name"
+	form := CreateCompanyForm(expectedID, "  ", expectedCountry, expectedFounded, 0)
+	if form.Validate() {
+		t.Errorf("Expected the validation to fail - no name")
+	}
+	if form.ErrorForField("Name") != expectedError {
+		t.Errorf("Expected \"%s\", got \"%s\"", expectedError, form.ErrorForField("Name"))
+	}
+	if len(form.FieldErrors()) != 1 {
+		t.Errorf("Expected 1 error, got %d", len(form.FieldErrors()))
+	}
+}
+
+// Check the limits on the lengths and the years.
+func TestUnitCreateCompanyBadFields(t *testing.T) {
+	var tests = []struct {
+		name    string
+		country string
+		founded int
+		defunct int
+		field   string
+		message string
+	}{
+		{strings.Repeat("x", MaxNameLength+1), "", 0, 0, "Name",
+			"the Name must be no more than 255 characters"},
+		{expectedName, strings.Repeat("x", MaxCountryLength+1), 0, 0, "Country",
+			"the Country must be no more than 64 characters"},
+		{expectedName, "", 1700, 0, "Founded",
+			"the Founded year must be between 1800 and 2100"},
+		{expectedName, "", 0, 3000, "Defunct",
+			"the Defunct year must be between 1800 and 2100"},
+		{expectedName, "", 1959, 1902, "Defunct",
+			"the Defunct year must not be before the Founded year"},
+	}
+	for _, test := range tests {
+		form := CreateCompanyForm(expectedID, test.name, test.country, test.founded,
+			test.defunct)
+		if form.Validate() {
+			t.Errorf("%s: expected the validation to fail", test.field)
+		}
+		if form.ErrorForField(test.field) != test.message {
+			t.Errorf("%s: expected \"%s\", got \"%s\"", test.field, test.message,
+				form.ErrorForField(test.field))
+		}
+	}
+}
+
+// A field error recorded before validation (for example, a year that was not a
+// number) causes the validation to fail and is not overwritten.
+func TestUnitCreateCompanyWithPreviousFieldError(t *testing.T) {
+	expectedError := "the Founded year must be a number"
+	form := CreateCompanyForm(expectedID, expectedName, expectedCountry, 0, expectedDefunct)
+	form.SetErrorMessageForField("Founded", expectedError)
+	if form.Validate() {
+		t.Errorf("Expected the validation to fail - previous field error")
+	}
+	if form.ErrorForField("Founded") != expectedError {
+		t.Errorf("Expected \"%s\", got \"%s\"", expectedError, form.ErrorForField("Founded"))
+	}
+}
+
+func CreateCompanyForm(id uint64, name string, country string, founded int,
+	defunct int) ConcreteCompanyForm {
+
+	company := model.MakeInitialisedCompany(id, name, country, founded, defunct)
+	var form ConcreteCompanyForm
+	form.SetCompany(company)
+	return form
+}
@@ -0,0 +1,56 @@
+package companies
+
+import (
+	companyModel "github.com/goblimey/films/models/company"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteListForm satisfies the ListForm interface and holds view data
+// including a list of companies.  It's approximately equivalent to a Struts form
+// bean.
+type ConcreteListForm struct {
+	companies    []companyModel.Company
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// Companies returns the list of Company objects from the form
+func (clf *ConcreteListForm) Companies() []companyModel.Company {
+	return clf.companies
+}
+
+// Notice gets the notice.
+func (clf *ConcreteListForm) Notice() string {
+	return clf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (clf *ConcreteListForm) ErrorMessage() string {
+	return clf.errorMessage
+}
+
+// SetCompanies sets the list of Companies.
+func (clf *ConcreteListForm) SetCompanies(companies []companyModel.Company) {
+	clf.companies = companies
+}
+
+// SetNotice sets the notice.
+func (clf *ConcreteListForm) SetNotice(notice string) {
+	clf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (clf *ConcreteListForm) SetErrorMessage(errorMessage string) {
+	clf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (clf *ConcreteListForm) Viewer() auth.Viewer {
+	return clf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (clf *ConcreteListForm) SetViewer(viewer auth.Viewer) {
+	clf.viewer = viewer
+}
@@ -0,0 +1,27 @@
+package companies
+
+import (
+	companyModel "github.com/goblimey/films/models/company"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ListForm holds view data including a list of companies.  It's approximately
+// equivalent to a Struts form bean.
+type ListForm interface {
+	// Companies returns the list of Company objects from the form
+	Companies() []companyModel.Company
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetCompanies sets the list of Companies in the form.
+	SetCompanies([]companyModel.Company)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	//SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
@@ -0,0 +1,150 @@
+package credits
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	creditModel "github.com/goblimey/films/models/credit"
+	"github.com/goblimey/films/utilities"
+)
+
+// MaxCharacterLength is the longest character name that the credits table can hold.
+const MaxCharacterLength = 255
+
+// ConcreteCreditForm satisfies the CreditForm interface.
+type ConcreteCreditForm struct {
+	credit       creditModel.Credit
+	errorMessage string
+	notice       string
+	fieldError   map[string]string
+}
+
+// Getters
+
+// Credit gets the Credit embedded in the form.
+func (cf ConcreteCreditForm) Credit() creditModel.Credit {
+	return cf.credit
+}
+
+// Notice gets the notice.
+func (cf ConcreteCreditForm) Notice() string {
+	return cf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (cf ConcreteCreditForm) ErrorMessage() string {
+	return cf.errorMessage
+}
+
+// FieldErrors returns all the field errors as a map.
+func (cf ConcreteCreditForm) FieldErrors() map[string]string {
+	return cf.fieldError
+}
+
+// ErrorForField returns the error message about a field (may be an empty string).
+func (cf ConcreteCreditForm) ErrorForField(key string) string {
+	if cf.fieldError == nil {
+		// The field error map has not been set up.
+		return ""
+	}
+	return cf.fieldError[key]
+}
+
+// String returns a string version of the CreditForm.
+func (cf ConcreteCreditForm) String() string {
+	return fmt.Sprintf("ConcreteCreditForm={credit=%s, notice=%s,errorMessage=%s,fieldError=%s}",
+		cf.credit,
+		cf.notice,
+		cf.errorMessage,
+		utilities.Map2String(cf.fieldError))
+}
+
+// Setters
+
+// SetCredit sets the Credit in the form.
+func (cf *ConcreteCreditForm) SetCredit(credit creditModel.Credit) {
+	cf.credit = credit
+}
+
+// SetNotice sets the notice.
+func (cf *ConcreteCreditForm) SetNotice(notice string) {
+	cf.notice = notice
+}
+
+// SetErrorMessage sets the general error message.
+func (cf *ConcreteCreditForm) SetErrorMessage(errorMessage string) {
+	cf.errorMessage = errorMessage
+}
+
+// SetErrorMessageForField sets the error message for a named field
+func (cf *ConcreteCreditForm) SetErrorMessageForField(fieldname, errormessage string) {
+	if cf.fieldError == nil {
+		cf.fieldError = make(map[string]string)
+	}
+	cf.fieldError[fieldname] = errormessage
+}
+
+// Validate validates the data in the Credit and sets the various error messages.
+// It returns true if the data is valid, false if there are errors.  Only an actor
+// can have a character name and a billing order.  Any field errors already
+// recorded (for example, a billing in the HTTP request that could not be
+// converted to a number) also cause the validation to fail.
+func (cf *ConcreteCreditForm) Validate() bool {
+	credit := cf.Credit()
+	// trim all string items
+	credit.SetRole(utilities.Trim(credit.Role()))
+	credit.SetCharacter(utilities.Trim(credit.Character()))
+	// validate
+	valid := len(cf.fieldError) == 0
+
+	if credit.PersonID() == 0 {
+		cf.SetErrorMessageForField("Person", "you must choose the Person")
+		valid = false
+	}
+	if credit.FilmID() == 0 {
+		cf.SetErrorMessageForField("Film", "you must choose the Film")
+		valid = false
+	}
+	if !creditModel.ValidRole(credit.Role()) {
+		cf.SetErrorMessageForField("Role", fmt.Sprintf("the Role must be one of %s",
+			strings.Join(creditModel.Roles, ", ")))
+		valid = false
+	}
+	if credit.Role() != creditModel.RoleActor {
+		if len(credit.Character()) > 0 {
+			cf.SetErrorMessageForField("Character", "only an actor can have a Character")
+			valid = false
+		}
+		if cf.ErrorForField("Billing") == "" && credit.Billing() != 0 {
+			cf.SetErrorMessageForField("Billing", "only an actor can have a Billing")
+			valid = false
+		}
+	}
+	if len(credit.Character()) > MaxCharacterLength {
+		cf.SetErrorMessageForField("Character",
+			fmt.Sprintf("the Character must be no more than %d characters", MaxCharacterLength))
+		valid = false
+	}
+	if cf.ErrorForField("Billing") == "" && credit.Billing() < 0 {
+		cf.SetErrorMessageForField("Billing", "the Billing must not be negative")
+		valid = false
+	}
+	return valid
+}
+
+// FieldErrorSummary returns the field errors in the form as a single string,
+// sorted by field name, for display as a general error message on a page that
+// does not show the individual fields.
+func FieldErrorSummary(form CreditForm) string {
+	keys := make([]string, 0, len(form.FieldErrors()))
+	for key := range form.FieldErrors() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	messages := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messages = append(messages, form.FieldErrors()[key])
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,74 @@
+package credits
+
+import (
+	"testing"
+
+	creditModel "github.com/goblimey/films/models/credit"
+	model "github.com/goblimey/films/models/credit/gorpmysql"
+)
+
+var expectedPersonID uint64 = 3
+var expectedFilmID uint64 = 4
+var expectedCharacter = "Harry Lime"
+
+// A valid actor credit passes validation.
+func TestUnitValidateActorCredit(t *testing.T) {
+	form := CreateCreditForm(expectedPersonID, expectedFilmID, creditModel.RoleActor,
+		expectedCharacter, 1)
+	if !form.Validate() {
+		t.Errorf("Expected the validation to succeed, got errors %v", form.FieldErrors())
+	}
+}
+
+// A credit with no person and no film fails validation.
+func TestUnitValidateCreditNoPersonOrFilm(t *testing.T) {
+	form := CreateCreditForm(0, 0, creditModel.RoleDirector, "", 0)
+	if form.Validate() {
+		t.Errorf("Expected the validation to fail - no person or film")
+	}
+	if form.ErrorForField("Person") != "you must choose the Person" {
+		t.Errorf("Expected an error for the person, got \"%s\"", form.ErrorForField("Person"))
+	}
+	if form.ErrorForField("Film") != "you must choose the Film" {
+		t.Errorf("Expected an error for the film, got \"%s\"", form.ErrorForField("Film"))
+	}
+}
+
+// A credit with an unknown role fails validation.
+func TestUnitValidateCreditBadRole(t *testing.T) {
+	expectedError := "the Role must be one of actor, director, writer, producer"
+	form := CreateCreditForm(expectedPersonID, expectedFilmID, "caterer", "", 0)
+	if form.Validate() {
+		t.Errorf("Expected the validation to fail - bad role")
+	}
+	if form.ErrorForField("Role") != expectedError {
+		t.Errorf("Expected \"%s\", got \"%s\"", expectedError, form.ErrorForField("Role"))
+	}
+}
+
+// Only actors can have a character and a billing.
+func TestUnitValidateDirectorWithCharacter(t *testing.T) {
+	form := CreateCreditForm(expectedPersonID, expectedFilmID, creditModel.RoleDirector,
+		expectedCharacter, 1)
+	if form.Validate() {
+		t.Errorf("Expected the validation to fail - director with a character")
+	}
+	errors := form.FieldErrors()
+	if len(errors) != 2 {
+		t.Errorf("Expected 2 errors, got %d", len(errors))
+	}
+	expectedSummary := "only an actor can have a Billing; only an actor can have a Character"
+	if FieldErrorSummary(&form) != expectedSummary {
+		t.Errorf("Expected summary \"%s\", got \"%s\"", expectedSummary,
+			FieldErrorSummary(&form))
+	}
+}
+
+func CreateCreditForm(personID uint64, filmID uint64, role string, character string,
+	billing int) ConcreteCreditForm {
+
+	credit := model.MakeInitialisedCredit(0, personID, filmID, role, character, billing)
+	var form ConcreteCreditForm
+	form.SetCredit(credit)
+	return form
+}
@@ -0,0 +1,36 @@
+package credits
+
+import (
+	creditModel "github.com/goblimey/films/models/credit"
+)
+
+// CreditForm holds view data about a Credit.  It's used as a data transfer object
+// (DTO) when a person is credited on a film.  (It's approximately equivalent to a
+// Struts form bean.)  It contains a Credit; a validator function that validates the
+// data in the Credit and sets the various error messages; a general error message,
+// a notice and a set of error messages about individual fields of the Credit.
+type CreditForm interface {
+	// Credit gets the Credit embedded in the form.
+	Credit() creditModel.Credit
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// FieldErrors returns all the field errors as a map.
+	FieldErrors() map[string]string
+	// ErrorForField returns the error message about a field (may be an empty string).
+	ErrorForField(key string) string
+	// String returns a string version of the CreditForm.
+	String() string
+	// SetCredit sets the Credit in the form.
+	SetCredit(credit creditModel.Credit)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the general error message.
+	SetErrorMessage(errorMessage string)
+	// SetErrorMessageForField sets the error message for a named field
+	SetErrorMessageForField(fieldname, errormessage string)
+	// Validate validates the data in the Credit and sets the various error messages.
+	// It returns true if the data is valid, false if there are errors.
+	Validate() bool
+}
@@ -0,0 +1,136 @@
+package diary
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	diaryModel "github.com/goblimey/films/models/diary"
+	reviewModel "github.com/goblimey/films/models/review"
+	"github.com/goblimey/films/utilities"
+)
+
+// ConcreteEntryForm satisfies the EntryForm interface.
+type ConcreteEntryForm struct {
+	entry        diaryModel.Entry
+	errorMessage string
+	notice       string
+	fieldError   map[string]string
+}
+
+// Getters
+
+// Entry gets the Entry embedded in the form.
+func (ef ConcreteEntryForm) Entry() diaryModel.Entry {
+	return ef.entry
+}
+
+// Notice gets the notice.
+func (ef ConcreteEntryForm) Notice() string {
+	return ef.notice
+}
+
+// ErrorMessage gets the general error message.
+func (ef ConcreteEntryForm) ErrorMessage() string {
+	return ef.errorMessage
+}
+
+// FieldErrors returns all the field errors as a map.
+func (ef ConcreteEntryForm) FieldErrors() map[string]string {
+	return ef.fieldError
+}
+
+// ErrorForField returns the error message about a field (may be an empty string).
+func (ef ConcreteEntryForm) ErrorForField(key string) string {
+	if ef.fieldError == nil {
+		// The field error map has not been set up.
+		return ""
+	}
+	return ef.fieldError[key]
+}
+
+// String returns a string version of the EntryForm.
+func (ef ConcreteEntryForm) String() string {
+	return fmt.Sprintf("ConcreteEntryForm={entry=%s, notice=%s,errorMessage=%s,fieldError=%s}",
+		ef.entry,
+		ef.notice,
+		ef.errorMessage,
+		utilities.Map2String(ef.fieldError))
+}
+
+// Setters
+
+// SetEntry sets the Entry in the form.
+func (ef *ConcreteEntryForm) SetEntry(entry diaryModel.Entry) {
+	ef.entry = entry
+}
+
+// SetNotice sets the notice.
+func (ef *ConcreteEntryForm) SetNotice(notice string) {
+	ef.notice = notice
+}
+
+// SetErrorMessage sets the general error message.
+func (ef *ConcreteEntryForm) SetErrorMessage(errorMessage string) {
+	ef.errorMessage = errorMessage
+}
+
+// SetErrorMessageForField sets the error message for a named field
+func (ef *ConcreteEntryForm) SetErrorMessageForField(fieldname, errormessage string) {
+	if ef.fieldError == nil {
+		ef.fieldError = make(map[string]string)
+	}
+	ef.fieldError[fieldname] = errormessage
+}
+
+// Validate validates the data in the Entry and sets the various error messages.
+// It returns true if the data is valid, false if there are errors.  The film and
+// the date are required and the date can't be in the future (allowing a day for
+// viewers whose time zone is ahead of the server's).  The rating is
+// optional - 0 means none.  Any field errors already recorded (for example, a
+// date in the HTTP request that could not be parsed) also cause the validation
+// to fail.
+func (ef *ConcreteEntryForm) Validate() bool {
+	entry := ef.Entry()
+	valid := len(ef.fieldError) == 0
+
+	if entry.FilmID() == 0 {
+		ef.SetErrorMessageForField("Film", "you must choose the Film")
+		valid = false
+	}
+	if ef.ErrorForField("WatchedOn") == "" {
+		if entry.WatchedOn().IsZero() {
+			ef.SetErrorMessageForField("WatchedOn", "you must say when you watched the film")
+			valid = false
+		} else if entry.WatchedOn().After(time.Now().AddDate(0, 0, 1)) {
+			ef.SetErrorMessageForField("WatchedOn", "the date can't be in the future")
+			valid = false
+		}
+	}
+	if ef.ErrorForField("Rating") == "" && entry.Rating() != 0 &&
+		!reviewModel.ValidRating(entry.Rating()) {
+
+		ef.SetErrorMessageForField("Rating",
+			fmt.Sprintf("the Rating must be between %d and %d",
+				reviewModel.MinRating, reviewModel.MaxRating))
+		valid = false
+	}
+	return valid
+}
+
+// FieldErrorSummary returns the field errors in the form as a single string,
+// sorted by field name, for display as a general error message on a page that
+// does not show the individual fields.
+func FieldErrorSummary(form EntryForm) string {
+	keys := make([]string, 0, len(form.FieldErrors()))
+	for key := range form.FieldErrors() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	messages := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messages = append(messages, form.FieldErrors()[key])
+	}
+	return strings.Join(messages, "; ")
+}
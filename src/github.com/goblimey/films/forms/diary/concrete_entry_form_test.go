@@ -0,0 +1,48 @@
+package diary
+
+import (
+	"testing"
+	"time"
+
+	model "github.com/goblimey/films/models/diary/gorpmysql"
+)
+
+var expectedFilmID uint64 = 4
+var expectedUserID uint64 = 5
+
+// A viewing with a date and no rating passes validation.
+func TestUnitValidateEntryWithoutRating(t *testing.T) {
+	form := CreateEntryForm(expectedFilmID, time.Now().AddDate(0, 0, -1), 0)
+	if !form.Validate() {
+		t.Errorf("Expected the validation to succeed, got errors %v", form.FieldErrors())
+	}
+}
+
+// A viewing with no film, a date in the future and a rating out of range fails
+// validation.
+func TestUnitValidateEntryErrors(t *testing.T) {
+	form := CreateEntryForm(0, time.Now().AddDate(0, 0, 3), 11)
+	if form.Validate() {
+		t.Errorf("Expected the validation to fail")
+	}
+	expectedSummary := "you must choose the Film; the Rating must be between 1 and 10; " +
+		"the date can't be in the future"
+	if FieldErrorSummary(&form) != expectedSummary {
+		t.Errorf("Expected \"%s\", got \"%s\"", expectedSummary, FieldErrorSummary(&form))
+	}
+
+	form = CreateEntryForm(expectedFilmID, time.Time{}, 7)
+	if form.Validate() {
+		t.Errorf("Expected the validation to fail - no date")
+	}
+	if form.ErrorForField("WatchedOn") != "you must say when you watched the film" {
+		t.Errorf("Expected an error about the date, got \"%s\"", form.ErrorForField("WatchedOn"))
+	}
+}
+
+func CreateEntryForm(filmID uint64, watchedOn time.Time, rating int) ConcreteEntryForm {
+	entry := model.MakeInitialisedEntry(0, expectedUserID, filmID, watchedOn, rating, false)
+	var form ConcreteEntryForm
+	form.SetEntry(entry)
+	return form
+}
@@ -0,0 +1,62 @@
+package diary
+
+import (
+	diaryModel "github.com/goblimey/films/models/diary"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteListForm satisfies the ListForm interface and holds view data
+// including the entries in a user's diary.  It's approximately equivalent to a
+// Struts form bean.
+type ConcreteListForm struct {
+	entries      []diaryModel.Entry
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// Entries returns the entries in the diary, most recent viewing first.
+func (clf *ConcreteListForm) Entries() []diaryModel.Entry {
+	return clf.entries
+}
+
+// Notice gets the notice.
+func (clf *ConcreteListForm) Notice() string {
+	return clf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (clf *ConcreteListForm) ErrorMessage() string {
+	return clf.errorMessage
+}
+
+// WatchedOn gets the day on which the film in the entry was watched, for
+// display.
+func (clf *ConcreteListForm) WatchedOn(entry diaryModel.Entry) string {
+	return entry.WatchedOn().Format("2 Jan 2006")
+}
+
+// SetEntries sets the entries in the diary.
+func (clf *ConcreteListForm) SetEntries(entries []diaryModel.Entry) {
+	clf.entries = entries
+}
+
+// SetNotice sets the notice.
+func (clf *ConcreteListForm) SetNotice(notice string) {
+	clf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (clf *ConcreteListForm) SetErrorMessage(errorMessage string) {
+	clf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (clf *ConcreteListForm) Viewer() auth.Viewer {
+	return clf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (clf *ConcreteListForm) SetViewer(viewer auth.Viewer) {
+	clf.viewer = viewer
+}
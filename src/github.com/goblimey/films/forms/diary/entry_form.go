@@ -0,0 +1,36 @@
+package diary
+
+import (
+	diaryModel "github.com/goblimey/films/models/diary"
+)
+
+// EntryForm holds view data about a diary Entry.  It's used as a data transfer
+// object (DTO) when a user logs a viewing of a film.  It contains an Entry; a
+// validator function that validates the data in the Entry and sets the various
+// error messages; a general error message, a notice and a set of error messages
+// about individual fields of the Entry.
+type EntryForm interface {
+	// Entry gets the Entry embedded in the form.
+	Entry() diaryModel.Entry
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// FieldErrors returns all the field errors as a map.
+	FieldErrors() map[string]string
+	// ErrorForField returns the error message about a field (may be an empty string).
+	ErrorForField(key string) string
+	// String returns a string version of the EntryForm.
+	String() string
+	// SetEntry sets the Entry in the form.
+	SetEntry(entry diaryModel.Entry)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the general error message.
+	SetErrorMessage(errorMessage string)
+	// SetErrorMessageForField sets the error message for a named field
+	SetErrorMessageForField(fieldname, errormessage string)
+	// Validate validates the data in the Entry and sets the various error messages.
+	// It returns true if the data is valid, false if there are errors.
+	Validate() bool
+}
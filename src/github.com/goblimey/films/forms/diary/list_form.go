@@ -0,0 +1,27 @@
+package diary
+
+import (
+	diaryModel "github.com/goblimey/films/models/diary"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ListForm holds view data including the entries in a user's diary.  It's
+// approximately equivalent to a Struts form bean.
+type ListForm interface {
+	// Entries returns the entries in the diary, most recent viewing first.
+	Entries() []diaryModel.Entry
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetEntries sets the entries in the diary.
+	SetEntries(entries []diaryModel.Entry)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	//SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
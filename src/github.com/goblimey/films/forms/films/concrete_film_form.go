@@ -0,0 +1,353 @@
+package films
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+
+	awardModel "github.com/goblimey/films/models/award"
+	companyModel "github.com/goblimey/films/models/company"
+	creditModel "github.com/goblimey/films/models/credit"
+	filmModel "github.com/goblimey/films/models/film"
+	genreModel "github.com/goblimey/films/models/genre"
+	personModel "github.com/goblimey/films/models/person"
+	reviewModel "github.com/goblimey/films/models/review"
+	tagModel "github.com/goblimey/films/models/tag"
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/sanitize"
+)
+
+// MinReleaseYear is the earliest release year that the form accepts.  The oldest
+// surviving motion picture dates from 1888.
+const MinReleaseYear = 1888
+
+// MaxReleaseYear is the latest release year that the form accepts.
+const MaxReleaseYear = 2100
+
+// MaxTitleLength is the longest title that the films table can hold.
+const MaxTitleLength = 255
+
+// MaxSynopsisLength is the longest synopsis that the films table can hold.
+const MaxSynopsisLength = 2000
+
+// ConcreteFilmForm satisfies the FilmForm interface.
+type ConcreteFilmForm struct {
+	film           filmModel.Film
+	credits        []creditModel.Credit
+	people         []personModel.Person
+	genres         []genreModel.Genre
+	tags           []tagModel.Tag
+	allGenres      []genreModel.Genre
+	companies      []companyModel.Link
+	allCompanies   []companyModel.Company
+	ratings        reviewModel.Summary
+	reviews        []reviewModel.Review
+	myReview       reviewModel.Review
+	watchlistEntry watchlistModel.Entry
+	awards         []awardModel.Nomination
+	errorMessage   string
+	viewer         auth.Viewer
+	notice         string
+	fieldError     map[string]string
+}
+
+// Getters
+
+// Film gets the Film embedded in the form.
+func (ff ConcreteFilmForm) Film() filmModel.Film {
+	return ff.film
+}
+
+// Credits gets the credits on the film - the cast and crew.
+func (ff ConcreteFilmForm) Credits() []creditModel.Credit {
+	return ff.credits
+}
+
+// People gets the people who can be credited on the film.
+func (ff ConcreteFilmForm) People() []personModel.Person {
+	return ff.people
+}
+
+// Genres gets the genres that the film is in.
+func (ff ConcreteFilmForm) Genres() []genreModel.Genre {
+	return ff.genres
+}
+
+// Tags gets the film's tags.
+func (ff ConcreteFilmForm) Tags() []tagModel.Tag {
+	return ff.tags
+}
+
+// AllGenres gets the genres that the film can be put into.
+func (ff ConcreteFilmForm) AllGenres() []genreModel.Genre {
+	return ff.allGenres
+}
+
+// Companies gets the links to the companies that made or distributed the film.
+func (ff ConcreteFilmForm) Companies() []companyModel.Link {
+	return ff.companies
+}
+
+// AllCompanies gets the companies that can be linked to the film.
+func (ff ConcreteFilmForm) AllCompanies() []companyModel.Company {
+	return ff.allCompanies
+}
+
+// Ratings gets the summary of the users' ratings of the film.
+func (ff ConcreteFilmForm) Ratings() reviewModel.Summary {
+	return ff.ratings
+}
+
+// Reviews gets the users' reviews of the film, most recent first.
+func (ff ConcreteFilmForm) Reviews() []reviewModel.Review {
+	return ff.reviews
+}
+
+// MyReview gets the viewer's own review of the film, which is empty if they
+// haven't reviewed it yet, or nil if they are not logged in.
+func (ff ConcreteFilmForm) MyReview() reviewModel.Review {
+	return ff.myReview
+}
+
+// WatchlistEntry gets the entry for the film on the viewer's watchlist, or nil if
+// it's not on their watchlist.
+func (ff ConcreteFilmForm) WatchlistEntry() watchlistModel.Entry {
+	return ff.watchlistEntry
+}
+
+// Awards gets the film's award nominations, most recent ceremony first.
+func (ff ConcreteFilmForm) Awards() []awardModel.Nomination {
+	return ff.awards
+}
+
+// RatingBar is one bar of the histogram of a film's ratings.  Width is the
+// length of the bar as a percentage of the longest one.
+type RatingBar struct {
+	Rating int
+	Count  int
+	Width  int
+}
+
+// AverageRating gets the average rating of the film to one decimal place, or an
+// empty string if nobody has rated it.
+func (ff ConcreteFilmForm) AverageRating() string {
+	if ff.ratings == nil || ff.ratings.Count() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", ff.ratings.Average())
+}
+
+// RatingBars gets the histogram of the film's ratings as a list of bars, highest
+// rating first.
+func (ff ConcreteFilmForm) RatingBars() []RatingBar {
+	if ff.ratings == nil {
+		return nil
+	}
+	histogram := ff.ratings.Histogram()
+	most := 0
+	for _, count := range histogram {
+		if count > most {
+			most = count
+		}
+	}
+	bars := make([]RatingBar, 0, len(histogram))
+	for i := len(histogram) - 1; i >= 0; i-- {
+		bar := RatingBar{Rating: reviewModel.MinRating + i, Count: histogram[i]}
+		if most > 0 {
+			bar.Width = histogram[i] * 100 / most
+		}
+		bars = append(bars, bar)
+	}
+	return bars
+}
+
+// RatingChoices gets the ratings that a user can give a film, highest first.
+func (ff ConcreteFilmForm) RatingChoices() []int {
+	choices := make([]int, 0, reviewModel.MaxRating-reviewModel.MinRating+1)
+	for rating := reviewModel.MaxRating; rating >= reviewModel.MinRating; rating-- {
+		choices = append(choices, rating)
+	}
+	return choices
+}
+
+// ReviewBody gets the text of the given review as HTML that is safe to display -
+// see sanitize.Paragraphs.
+func (ff ConcreteFilmForm) ReviewBody(review reviewModel.Review) template.HTML {
+	return sanitize.Paragraphs(review.Body())
+}
+
+// Today gets today's date in the form that the date input of the diary form
+// expects, to start it off.
+func (ff ConcreteFilmForm) Today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// Notice gets the notice.
+func (ff ConcreteFilmForm) Notice() string {
+	return ff.notice
+}
+
+// ErrorMessage gets the general error message.
+func (ff ConcreteFilmForm) ErrorMessage() string {
+	return ff.errorMessage
+}
+
+// FieldErrors returns all the field errors as a map.
+func (ff ConcreteFilmForm) FieldErrors() map[string]string {
+	return ff.fieldError
+}
+
+// ErrorForField returns the error message about a field (may be an empty string).
+func (ff ConcreteFilmForm) ErrorForField(key string) string {
+	if ff.fieldError == nil {
+		// The field error map has not been set up.
+		return ""
+	}
+	return ff.fieldError[key]
+}
+
+// String returns a string version of the FilmForm.
+func (ff ConcreteFilmForm) String() string {
+	return fmt.Sprintf("ConcreteFilmForm={film=%s, notice=%s,errorMessage=%s,fieldError=%s}",
+		ff.film,
+		ff.notice,
+		ff.errorMessage,
+		utilities.Map2String(ff.fieldError))
+}
+
+// Setters
+
+// SetFilm sets the Film in the form.
+func (ff *ConcreteFilmForm) SetFilm(film filmModel.Film) {
+	ff.film = film
+}
+
+// SetCredits sets the credits on the film.
+func (ff *ConcreteFilmForm) SetCredits(credits []creditModel.Credit) {
+	ff.credits = credits
+}
+
+// SetPeople sets the people who can be credited on the film.
+func (ff *ConcreteFilmForm) SetPeople(people []personModel.Person) {
+	ff.people = people
+}
+
+// SetGenres sets the genres that the film is in.
+func (ff *ConcreteFilmForm) SetGenres(genres []genreModel.Genre) {
+	ff.genres = genres
+}
+
+// SetTags sets the film's tags.
+func (ff *ConcreteFilmForm) SetTags(tags []tagModel.Tag) {
+	ff.tags = tags
+}
+
+// SetAllGenres sets the genres that the film can be put into.
+func (ff *ConcreteFilmForm) SetAllGenres(genres []genreModel.Genre) {
+	ff.allGenres = genres
+}
+
+// SetCompanies sets the links to the film's companies.
+func (ff *ConcreteFilmForm) SetCompanies(links []companyModel.Link) {
+	ff.companies = links
+}
+
+// SetAllCompanies sets the companies that can be linked to the film.
+func (ff *ConcreteFilmForm) SetAllCompanies(companies []companyModel.Company) {
+	ff.allCompanies = companies
+}
+
+// SetRatings sets the summary of the ratings of the film.
+func (ff *ConcreteFilmForm) SetRatings(ratings reviewModel.Summary) {
+	ff.ratings = ratings
+}
+
+// SetReviews sets the reviews of the film.
+func (ff *ConcreteFilmForm) SetReviews(reviews []reviewModel.Review) {
+	ff.reviews = reviews
+}
+
+// SetMyReview sets the viewer's own review of the film.
+func (ff *ConcreteFilmForm) SetMyReview(review reviewModel.Review) {
+	ff.myReview = review
+}
+
+// SetWatchlistEntry sets the entry for the film on the viewer's watchlist.
+func (ff *ConcreteFilmForm) SetWatchlistEntry(entry watchlistModel.Entry) {
+	ff.watchlistEntry = entry
+}
+
+// SetAwards sets the film's award nominations.
+func (ff *ConcreteFilmForm) SetAwards(awards []awardModel.Nomination) {
+	ff.awards = awards
+}
+
+// SetNotice sets the notice.
+func (ff *ConcreteFilmForm) SetNotice(notice string) {
+	ff.notice = notice
+}
+
+// SetErrorMessage sets the general error message.
+func (ff *ConcreteFilmForm) SetErrorMessage(errorMessage string) {
+	ff.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (ff ConcreteFilmForm) Viewer() auth.Viewer {
+	return ff.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (ff *ConcreteFilmForm) SetViewer(viewer auth.Viewer) {
+	ff.viewer = viewer
+}
+
+// SetErrorMessageForField sets the error message for a named field
+func (ff *ConcreteFilmForm) SetErrorMessageForField(fieldname, errormessage string) {
+	if ff.fieldError == nil {
+		ff.fieldError = make(map[string]string)
+	}
+	ff.fieldError[fieldname] = errormessage
+}
+
+// Validate validates the data in the Film and sets the various error messages.
+// It returns true if the data is valid, false if there are errors.  Any field
+// errors already recorded (for example, a release year in the HTTP request that
+// could not be converted to a number) also cause the validation to fail.
+func (ff *ConcreteFilmForm) Validate() bool {
+	film := ff.Film()
+	// trim all string items
+	film.SetTitle(utilities.Trim(film.Title()))
+	film.SetSynopsis(utilities.Trim(film.Synopsis()))
+	// validate
+	valid := len(ff.fieldError) == 0
+
+	if len(film.Title()) <= 0 {
+		ff.SetErrorMessageForField("Title", "you must specify the Title")
+		valid = false
+	} else if len(film.Title()) > MaxTitleLength {
+		ff.SetErrorMessageForField("Title",
+			fmt.Sprintf("the Title must be no more than %d characters", MaxTitleLength))
+		valid = false
+	}
+	if ff.ErrorForField("ReleaseYear") == "" &&
+		(film.ReleaseYear() < MinReleaseYear || film.ReleaseYear() > MaxReleaseYear) {
+
+		ff.SetErrorMessageForField("ReleaseYear",
+			fmt.Sprintf("the Release Year must be between %d and %d",
+				MinReleaseYear, MaxReleaseYear))
+		valid = false
+	}
+	if ff.ErrorForField("Runtime") == "" && film.Runtime() < 0 {
+		ff.SetErrorMessageForField("Runtime", "the Runtime must not be negative")
+		valid = false
+	}
+	if len(film.Synopsis()) > MaxSynopsisLength {
+		ff.SetErrorMessageForField("Synopsis",
+			fmt.Sprintf("the Synopsis must be no more than %d characters", MaxSynopsisLength))
+		valid = false
+	}
+	return valid
+}
@@ -0,0 +1,142 @@
+package films
+
+import (
+	"strings"
+	"testing"
+
+	model "github.com/goblimey/films/models/film/gorpmysql"
+	reviewModel "github.com/goblimey/films/models/review/gorpmysql"
+)
+
+var expectedID uint64 = 42
+var expectedTitle = "The Third Man"
+var expectedReleaseYear = 1949
+var expectedRuntime = 104
+var expectedSynopsis = "Holly Martins arrives in Vienna."
+
+// Create a film and a ConcreteFilmForm containing it.  Retrieve the film.
+func TestUnitCreateFilmFormAndRetrieveFilm(t *testing.T) {
+	filmForm := CreateFilmForm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+	if filmForm.Film().ID() != expectedID {
+		t.Errorf("Expected ID to be %d actually %d", expectedID, filmForm.Film().ID())
+	}
+	if filmForm.Film().Title() != expectedTitle {
+		t.Errorf("Expected title to be %s actually %s", expectedTitle, filmForm.Film().Title())
+	}
+	if !filmForm.Validate() {
+		t.Errorf("Expected the validation to succeed, got errors %v",
+			filmForm.FieldErrors())
+	}
+}
+
+// Create a film form containing a film with no title, and validate it.
+func TestUnitCreateFilmNoTitle(t *testing.T) {
+	expectedError := "you must specify the Title"
+	filmForm := CreateFilmForm(expectedID, "", expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+	if filmForm.Validate() {
+		t.Errorf("Expected the validation to fail - no title")
+	} else {
+		if filmForm.ErrorForField("Title") != expectedError {
+			t.Errorf("Expected \"%s\", got \"%s\"", expectedError,
+				filmForm.ErrorForField("Title"))
+		}
+	}
+	errors := filmForm.FieldErrors()
+	if len(errors) != 1 {
+		t.Errorf("Expected 1 error, got %d", len(errors))
+	}
+}
+
+// Create a film form containing a film with a release year that is too early.
+func TestUnitCreateFilmBadReleaseYear(t *testing.T) {
+	expectedError := "the Release Year must be between 1888 and 2100"
+	filmForm := CreateFilmForm(expectedID, expectedTitle, 1700,
+		expectedRuntime, expectedSynopsis)
+	if filmForm.Validate() {
+		t.Errorf("Expected the validation to fail - bad release year")
+	} else {
+		if filmForm.ErrorForField("ReleaseYear") != expectedError {
+			t.Errorf("Expected \"%s\", got \"%s\"", expectedError,
+				filmForm.ErrorForField("ReleaseYear"))
+		}
+	}
+}
+
+// Create a film form containing a film with a negative runtime.
+func TestUnitCreateFilmNegativeRuntime(t *testing.T) {
+	filmForm := CreateFilmForm(expectedID, expectedTitle, expectedReleaseYear,
+		-1, expectedSynopsis)
+	if filmForm.Validate() {
+		t.Errorf("Expected the validation to fail - negative runtime")
+	}
+	if filmForm.ErrorForField("Runtime") == "" {
+		t.Errorf("Expected an error message for the runtime")
+	}
+}
+
+// Create a film form containing a film with a synopsis that is too long.
+func TestUnitCreateFilmSynopsisTooLong(t *testing.T) {
+	filmForm := CreateFilmForm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, strings.Repeat("x", MaxSynopsisLength+1))
+	if filmForm.Validate() {
+		t.Errorf("Expected the validation to fail - synopsis too long")
+	}
+	if filmForm.ErrorForField("Synopsis") == "" {
+		t.Errorf("Expected an error message for the synopsis")
+	}
+}
+
+// A field error recorded before validation (for example, a release year that was
+// not a number) causes the validation to fail and is not overwritten.
+func TestUnitCreateFilmWithPreviousFieldError(t *testing.T) {
+	expectedError := "the Release Year must be a number"
+	filmForm := CreateFilmForm(expectedID, expectedTitle, 0,
+		expectedRuntime, expectedSynopsis)
+	filmForm.SetErrorMessageForField("ReleaseYear", expectedError)
+	if filmForm.Validate() {
+		t.Errorf("Expected the validation to fail - previous field error")
+	}
+	if filmForm.ErrorForField("ReleaseYear") != expectedError {
+		t.Errorf("Expected \"%s\", got \"%s\"", expectedError,
+			filmForm.ErrorForField("ReleaseYear"))
+	}
+}
+
+// The ratings histogram is shown highest rating first, with the bars scaled to
+// the longest one.
+func TestUnitRatingBars(t *testing.T) {
+	filmForm := CreateFilmForm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+	if filmForm.AverageRating() != "" || len(filmForm.RatingBars()) != 0 {
+		t.Errorf("Expected no ratings")
+	}
+	filmForm.SetRatings(reviewModel.MakeInitialisedSummary(1, expectedID, 1,
+		[]int{0, 0, 0, 0, 0, 0, 1, 0, 4, 1}))
+	if filmForm.AverageRating() != "8.8" {
+		t.Errorf("Expected average 8.8, got %s", filmForm.AverageRating())
+	}
+	bars := filmForm.RatingBars()
+	if len(bars) != 10 {
+		t.Fatalf("Expected 10 bars, got %d", len(bars))
+	}
+	if bars[0].Rating != 10 || bars[0].Count != 1 || bars[0].Width != 25 {
+		t.Errorf("Expected rating 10 with count 1 and width 25, got %v", bars[0])
+	}
+	if bars[1].Rating != 9 || bars[1].Width != 100 {
+		t.Errorf("Expected rating 9 with width 100, got %v", bars[1])
+	}
+	if bars[9].Rating != 1 || bars[9].Width != 0 {
+		t.Errorf("Expected rating 1 with width 0, got %v", bars[9])
+	}
+}
+
+func CreateFilmForm(id uint64, title string, releaseYear int, runtime int,
+	synopsis string) ConcreteFilmForm {
+
+	film := model.MakeInitialisedFilm(id, title, releaseYear, runtime, synopsis)
+	var filmForm ConcreteFilmForm
+	filmForm.SetFilm(film)
+	return filmForm
+}
@@ -0,0 +1,91 @@
+package films
+
+import (
+	filmModel "github.com/goblimey/films/models/film"
+	genreModel "github.com/goblimey/films/models/genre"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteListForm satisfies the ListForm interface and holds view data
+// including a list of films.  It's approximately equivalent to a Struts form
+// bean.
+type ConcreteListForm struct {
+	films        []filmModel.Film
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+	query        ListQuery
+	genres       []genreModel.Genre
+	tags         []tagModel.Tag
+}
+
+// Films returns the list of Film objects from the form
+func (clf *ConcreteListForm) Films() []filmModel.Film {
+	return clf.films
+}
+
+// Notice gets the notice.
+func (clf *ConcreteListForm) Notice() string {
+	return clf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (clf *ConcreteListForm) ErrorMessage() string {
+	return clf.errorMessage
+}
+
+// SetFilms sets the list of Films.
+func (clf *ConcreteListForm) SetFilms(films []filmModel.Film) {
+	clf.films = films
+}
+
+// SetNotice sets the notice.
+func (clf *ConcreteListForm) SetNotice(notice string) {
+	clf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (clf *ConcreteListForm) SetErrorMessage(errorMessage string) {
+	clf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (clf *ConcreteListForm) Viewer() auth.Viewer {
+	return clf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (clf *ConcreteListForm) SetViewer(viewer auth.Viewer) {
+	clf.viewer = viewer
+}
+
+// Query gets the query that says which films are on the page.
+func (clf *ConcreteListForm) Query() ListQuery {
+	return clf.query
+}
+
+// SetQuery sets the query that says which films are on the page.
+func (clf *ConcreteListForm) SetQuery(query ListQuery) {
+	clf.query = query
+}
+
+// Genres gets the genres that the films can be filtered on.
+func (clf *ConcreteListForm) Genres() []genreModel.Genre {
+	return clf.genres
+}
+
+// SetGenres sets the genres that the films can be filtered on.
+func (clf *ConcreteListForm) SetGenres(genres []genreModel.Genre) {
+	clf.genres = genres
+}
+
+// Tags gets the tags that the films can be filtered on.
+func (clf *ConcreteListForm) Tags() []tagModel.Tag {
+	return clf.tags
+}
+
+// SetTags sets the tags that the films can be filtered on.
+func (clf *ConcreteListForm) SetTags(tags []tagModel.Tag) {
+	clf.tags = tags
+}
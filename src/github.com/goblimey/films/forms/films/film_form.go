@@ -0,0 +1,101 @@
+package films
+
+import (
+	awardModel "github.com/goblimey/films/models/award"
+	companyModel "github.com/goblimey/films/models/company"
+	creditModel "github.com/goblimey/films/models/credit"
+	filmModel "github.com/goblimey/films/models/film"
+	genreModel "github.com/goblimey/films/models/genre"
+	personModel "github.com/goblimey/films/models/person"
+	reviewModel "github.com/goblimey/films/models/review"
+	tagModel "github.com/goblimey/films/models/tag"
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// FilmForm holds view data about a Film.  It's used as a data transfer object (DTO)
+// in particular for use with views that handle a Film.  (It's approximately equivalent
+// to a Struts form bean.)  It contains a Film; a validator function that validates the
+// data in the Film and sets the various error messages; a general error message (for
+// errors not associated with an individual field of the Film), a notice (for
+// announcements that are not about errors) and a set of error messages about
+// individual fields of the Film.
+type FilmForm interface {
+	// Film gets the Film embedded in the form.
+	Film() filmModel.Film
+	// Credits gets the credits on the film - the cast and crew.
+	Credits() []creditModel.Credit
+	// People gets the people who can be credited on the film.
+	People() []personModel.Person
+	// Genres gets the genres that the film is in.
+	Genres() []genreModel.Genre
+	// Tags gets the film's tags.
+	Tags() []tagModel.Tag
+	// AllGenres gets the genres that the film can be put into.
+	AllGenres() []genreModel.Genre
+	// Companies gets the links to the companies that made or distributed the film.
+	Companies() []companyModel.Link
+	// AllCompanies gets the companies that can be linked to the film.
+	AllCompanies() []companyModel.Company
+	// Ratings gets the summary of the users' ratings of the film.
+	Ratings() reviewModel.Summary
+	// Reviews gets the users' reviews of the film, most recent first.
+	Reviews() []reviewModel.Review
+	// MyReview gets the viewer's own review of the film, which is empty if they
+	// haven't reviewed it yet, or nil if they are not logged in.
+	MyReview() reviewModel.Review
+	// WatchlistEntry gets the entry for the film on the viewer's watchlist, or
+	// nil if it's not on their watchlist.
+	WatchlistEntry() watchlistModel.Entry
+	// Awards gets the film's award nominations, most recent ceremony first.
+	Awards() []awardModel.Nomination
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// FieldErrors returns all the field errors as a map.
+	FieldErrors() map[string]string
+	// ErrorForField returns the error message about a field (may be an empty string).
+	ErrorForField(key string) string
+	// String returns a string version of the FilmForm.
+	String() string
+	// SetFilm sets the Film in the form.
+	SetFilm(film filmModel.Film)
+	// SetCredits sets the credits on the film.
+	SetCredits(credits []creditModel.Credit)
+	// SetPeople sets the people who can be credited on the film.
+	SetPeople(people []personModel.Person)
+	// SetGenres sets the genres that the film is in.
+	SetGenres(genres []genreModel.Genre)
+	// SetTags sets the film's tags.
+	SetTags(tags []tagModel.Tag)
+	// SetAllGenres sets the genres that the film can be put into.
+	SetAllGenres(genres []genreModel.Genre)
+	// SetCompanies sets the links to the film's companies.
+	SetCompanies(links []companyModel.Link)
+	// SetAllCompanies sets the companies that can be linked to the film.
+	SetAllCompanies(companies []companyModel.Company)
+	// SetRatings sets the summary of the ratings of the film.
+	SetRatings(ratings reviewModel.Summary)
+	// SetReviews sets the reviews of the film.
+	SetReviews(reviews []reviewModel.Review)
+	// SetMyReview sets the viewer's own review of the film.
+	SetMyReview(review reviewModel.Review)
+	// SetWatchlistEntry sets the entry for the film on the viewer's watchlist.
+	SetWatchlistEntry(entry watchlistModel.Entry)
+	// SetAwards sets the film's award nominations.
+	SetAwards(awards []awardModel.Nomination)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	//SetErrorMessage sets the general error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+	// SetErrorMessageForField sets the error message for a named field
+	SetErrorMessageForField(fieldname, errormessage string)
+	// Validate validates the data in the Film and sets the various error messages.
+	// It returns true if the data is valid, false if there are errors.
+	Validate() bool
+}
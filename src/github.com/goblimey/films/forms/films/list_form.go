@@ -0,0 +1,41 @@
+package films
+
+import (
+	filmModel "github.com/goblimey/films/models/film"
+	genreModel "github.com/goblimey/films/models/genre"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ListForm holds view data including a list of films.  It's approximately
+// equivalent to a Struts form bean.
+type ListForm interface {
+	// Films returns the list of Film objects from the form
+	Films() []filmModel.Film
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetFilms sets the list of Films in the form.
+	SetFilms([]filmModel.Film)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	//SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+	// Query gets the query that says which films are on the page.
+	Query() ListQuery
+	// SetQuery sets the query that says which films are on the page.
+	SetQuery(query ListQuery)
+	// Genres gets the genres that the films can be filtered on.
+	Genres() []genreModel.Genre
+	// SetGenres sets the genres that the films can be filtered on.
+	SetGenres(genres []genreModel.Genre)
+	// Tags gets the tags that the films can be filtered on.
+	Tags() []tagModel.Tag
+	// SetTags sets the tags that the films can be filtered on.
+	SetTags(tags []tagModel.Tag)
+}
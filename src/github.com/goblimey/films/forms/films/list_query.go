@@ -0,0 +1,58 @@
+package films
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// ListQuery says which films the index page shows - the IDs of a genre and a tag
+// to filter on.  The zero value shows all of the films.
+type ListQuery struct {
+	Genre uint64
+	Tag   uint64
+}
+
+// ParseListQuery gets a ListQuery from the parameters of a request, for example
+// "genre=3&tag=7".  A silly ID is ignored, so a hand-edited URI can't cause an
+// error.
+func ParseListQuery(values url.Values) ListQuery {
+	var query ListQuery
+	query.Genre, _ = strconv.ParseUint(values.Get("genre"), 10, 64)
+	query.Tag, _ = strconv.ParseUint(values.Get("tag"), 10, 64)
+	return query
+}
+
+// FilmQuery converts the query to the form that the database session takes.
+func (q ListQuery) FilmQuery() dbsession.FilmQuery {
+	return dbsession.FilmQuery{GenreID: q.Genre, TagID: q.Tag}
+}
+
+// Filtered returns true if the query leaves out any films.
+func (q ListQuery) Filtered() bool {
+	return q.Genre != 0 || q.Tag != 0
+}
+
+// Values converts the query to request parameters - the reverse of
+// ParseListQuery.
+func (q ListQuery) Values() url.Values {
+	values := url.Values{}
+	if q.Genre != 0 {
+		values.Set("genre", strconv.FormatUint(q.Genre, 10))
+	}
+	if q.Tag != 0 {
+		values.Set("tag", strconv.FormatUint(q.Tag, 10))
+	}
+	return values
+}
+
+// URI returns the URI of the page of the given resource that shows the films that
+// the query selects, for example "/films?genre=3".
+func (q ListQuery) URI(path string) string {
+	if !q.Filtered() {
+		return path
+	}
+	return fmt.Sprintf("%s?%s", path, q.Values().Encode())
+}
@@ -0,0 +1,44 @@
+package films
+
+import (
+	"net/url"
+	"testing"
+)
+
+// TestUnitParseListQuery checks that request parameters are parsed and that silly
+// values are ignored.
+func TestUnitParseListQuery(t *testing.T) {
+	var tests = []struct {
+		params   string
+		expected ListQuery
+	}{
+		{"", ListQuery{}},
+		{"genre=3&tag=7", ListQuery{3, 7}},
+		{"genre=-1&tag=x", ListQuery{}},
+	}
+
+	for _, test := range tests {
+		values, _ := url.ParseQuery(test.params)
+		query := ParseListQuery(values)
+		if query != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.params, test.expected, query)
+		}
+	}
+}
+
+// TestUnitListQueryURI checks that the query is converted back to a URI, and
+// to the query that the database session takes.
+func TestUnitListQueryURI(t *testing.T) {
+	if (ListQuery{}).URI("/films") != "/films" {
+		t.Errorf("Expected no parameters, got %s", (ListQuery{}).URI("/films"))
+	}
+	query := ListQuery{Genre: 3, Tag: 7}
+	expected := "/films?genre=3&tag=7"
+	if query.URI("/films") != expected {
+		t.Errorf("Expected %s, got %s", expected, query.URI("/films"))
+	}
+	fq := query.FilmQuery()
+	if fq.GenreID != 3 || fq.TagID != 7 {
+		t.Errorf("Expected genre 3 and tag 7, got %d and %d", fq.GenreID, fq.TagID)
+	}
+}
@@ -0,0 +1,44 @@
+package forbidden
+
+import (
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteForbiddenForm satisfies the ForbiddenForm interface and holds the
+// view data for the forbidden page.  It's approximately equivalent to a Struts
+// form bean.
+type ConcreteForbiddenForm struct {
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// Notice gets the notice.
+func (cff *ConcreteForbiddenForm) Notice() string {
+	return cff.notice
+}
+
+// ErrorMessage gets the error message.
+func (cff *ConcreteForbiddenForm) ErrorMessage() string {
+	return cff.errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (cff *ConcreteForbiddenForm) Viewer() auth.Viewer {
+	return cff.viewer
+}
+
+// SetNotice sets the notice.
+func (cff *ConcreteForbiddenForm) SetNotice(notice string) {
+	cff.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (cff *ConcreteForbiddenForm) SetErrorMessage(errorMessage string) {
+	cff.errorMessage = errorMessage
+}
+
+// SetViewer sets the user looking at the page.
+func (cff *ConcreteForbiddenForm) SetViewer(viewer auth.Viewer) {
+	cff.viewer = viewer
+}
@@ -0,0 +1,23 @@
+package forbidden
+
+import (
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ForbiddenForm holds view data for the page displayed when the viewer is
+// not allowed to do what they asked.  The error message says why.  It's
+// approximately equivalent to a Struts form bean.
+type ForbiddenForm interface {
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the error message.
+	ErrorMessage() string
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
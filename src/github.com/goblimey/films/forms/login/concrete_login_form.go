@@ -0,0 +1,74 @@
+package login
+
+import (
+	"strings"
+
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteLoginForm satisfies the LoginForm interface and holds the view
+// data for the login page.  It's approximately equivalent to a Struts form bean.
+type ConcreteLoginForm struct {
+	username     string
+	next         string
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// Username gets the username.
+func (clf *ConcreteLoginForm) Username() string {
+	return clf.username
+}
+
+// Next gets the local URI of the page to display after logging in.
+func (clf *ConcreteLoginForm) Next() string {
+	return clf.next
+}
+
+// Notice gets the notice.
+func (clf *ConcreteLoginForm) Notice() string {
+	return clf.notice
+}
+
+// ErrorMessage gets the error message.
+func (clf *ConcreteLoginForm) ErrorMessage() string {
+	return clf.errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (clf *ConcreteLoginForm) Viewer() auth.Viewer {
+	return clf.viewer
+}
+
+// SetUsername sets the username.
+func (clf *ConcreteLoginForm) SetUsername(username string) {
+	clf.username = strings.TrimSpace(username)
+}
+
+// SetNext sets the URI of the page to display after logging in, if it's a path
+// on this server.  "//example.com/" is a path to the browser but it leads to
+// another site, and so does "/\example.com/" in some browsers.
+func (clf *ConcreteLoginForm) SetNext(next string) {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") ||
+		strings.HasPrefix(next, "/\\") {
+
+		next = ""
+	}
+	clf.next = next
+}
+
+// SetNotice sets the notice.
+func (clf *ConcreteLoginForm) SetNotice(notice string) {
+	clf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (clf *ConcreteLoginForm) SetErrorMessage(errorMessage string) {
+	clf.errorMessage = errorMessage
+}
+
+// SetViewer sets the user looking at the page.
+func (clf *ConcreteLoginForm) SetViewer(viewer auth.Viewer) {
+	clf.viewer = viewer
+}
@@ -0,0 +1,28 @@
+package login
+
+import (
+	"testing"
+)
+
+func TestUnitLoginFormNext(t *testing.T) {
+	var testData = []struct {
+		next     string
+		expected string
+	}{
+		{"/people/1/edit", "/people/1/edit"},
+		{"/films?page=2", "/films?page=2"},
+		{"", ""},
+		{"people", ""},
+		{"http://example.com/", ""},
+		{"//example.com/", ""},
+		{"/\\example.com/", ""},
+	}
+
+	for _, td := range testData {
+		var form ConcreteLoginForm
+		form.SetNext(td.next)
+		if form.Next() != td.expected {
+			t.Errorf("SetNext(%q) - expected %q, actually %q", td.next, td.expected, form.Next())
+		}
+	}
+}
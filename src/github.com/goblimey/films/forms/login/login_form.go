@@ -0,0 +1,34 @@
+package login
+
+import (
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The LoginForm holds view data for the login page - the username and the page
+// to go to after logging in.  It's approximately equivalent to a Struts form
+// bean.  The password is never put into the form, so it's never sent back to
+// the browser.
+type LoginForm interface {
+	// Username gets the username.
+	Username() string
+	// Next gets the local URI of the page to display after logging in.
+	Next() string
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetUsername sets the username.
+	SetUsername(username string)
+	// SetNext sets the URI of the page to display after logging in.  Only a path
+	// on this server is accepted - anything else is replaced by an empty string,
+	// so that the login page can't be used to send the user to another site.
+	SetNext(next string)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
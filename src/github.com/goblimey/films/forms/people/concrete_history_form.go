@@ -0,0 +1,108 @@
+package people
+
+import (
+	"sort"
+
+	auditModel "github.com/goblimey/films/models/audit"
+	personModel "github.com/goblimey/films/models/person"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteHistoryForm satisfies the HistoryForm interface and holds the view
+// data for the history page of a person.
+type ConcreteHistoryForm struct {
+	personID     uint64
+	person       personModel.Person
+	entries      []auditModel.Entry
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// PersonID gets the ID of the person whose history is shown.
+func (chf *ConcreteHistoryForm) PersonID() uint64 {
+	return chf.personID
+}
+
+// Person gets the person as they are now, or nil if they have been deleted.
+func (chf *ConcreteHistoryForm) Person() personModel.Person {
+	return chf.person
+}
+
+// Entries gets the audit log entries for the person, newest first.
+func (chf *ConcreteHistoryForm) Entries() []auditModel.Entry {
+	return chf.entries
+}
+
+// Changes gets the values of the fields before and after the change recorded by
+// the given entry, in order of field name.
+func (chf *ConcreteHistoryForm) Changes(entry auditModel.Entry) []FieldChange {
+	before := auditModel.DecodeValues(entry.Before())
+	after := auditModel.DecodeValues(entry.After())
+	fields := make([]string, 0, len(before)+len(after))
+	for field := range before {
+		fields = append(fields, field)
+	}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	changes := make([]FieldChange, 0, len(fields))
+	for _, field := range fields {
+		changes = append(changes, FieldChange{Field: field, Before: before[field], After: after[field]})
+	}
+	return changes
+}
+
+// CanRevert returns true if the person still exists, the entry holds the values
+// after the change and the person doesn't already have that version.
+func (chf *ConcreteHistoryForm) CanRevert(entry auditModel.Entry) bool {
+	return chf.person != nil && entry.After() != "" && entry.Version() != chf.person.Version()
+}
+
+// Notice gets the notice.
+func (chf *ConcreteHistoryForm) Notice() string {
+	return chf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (chf *ConcreteHistoryForm) ErrorMessage() string {
+	return chf.errorMessage
+}
+
+// SetPersonID sets the ID of the person whose history is shown.
+func (chf *ConcreteHistoryForm) SetPersonID(id uint64) {
+	chf.personID = id
+}
+
+// SetPerson sets the person as they are now.
+func (chf *ConcreteHistoryForm) SetPerson(person personModel.Person) {
+	chf.person = person
+}
+
+// SetEntries sets the audit log entries for the person.
+func (chf *ConcreteHistoryForm) SetEntries(entries []auditModel.Entry) {
+	chf.entries = entries
+}
+
+// SetNotice sets the notice.
+func (chf *ConcreteHistoryForm) SetNotice(notice string) {
+	chf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (chf *ConcreteHistoryForm) SetErrorMessage(errorMessage string) {
+	chf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (chf *ConcreteHistoryForm) Viewer() auth.Viewer {
+	return chf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (chf *ConcreteHistoryForm) SetViewer(viewer auth.Viewer) {
+	chf.viewer = viewer
+}
@@ -0,0 +1,46 @@
+package people
+
+import (
+	"testing"
+	"time"
+
+	auditModel "github.com/goblimey/films/models/audit"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+)
+
+func TestUnitHistoryFormChanges(t *testing.T) {
+	update := auditModel.MakeInitialisedEntry(2, "people", 1, auditModel.ActionUpdate, 2, "",
+		time.Now(),
+		auditModel.EncodeValues(map[string]string{"forename": "Joseph", "surname": "Cotten"}),
+		auditModel.EncodeValues(map[string]string{"forename": "Joe", "surname": "Cotten"}))
+	create := auditModel.MakeInitialisedEntry(1, "people", 1, auditModel.ActionCreate, 1, "",
+		time.Now(), "",
+		auditModel.EncodeValues(map[string]string{"forename": "Joseph", "surname": "Cotten"}))
+
+	var form ConcreteHistoryForm
+	person := gorpPersonModel.MakeInitialisedPerson(1, "Joe", "Cotten")
+	person.SetVersion(2)
+	form.SetPerson(person)
+	form.SetEntries([]auditModel.Entry{update, create})
+
+	changes := form.Changes(update)
+	if len(changes) != 2 || changes[0].Field != "forename" || changes[1].Field != "surname" {
+		t.Fatalf("expected changes to forename and surname, actually %v", changes)
+	}
+	if !changes[0].Changed() || changes[1].Changed() {
+		t.Errorf("expected just the forename to have changed, actually %v", changes)
+	}
+	changes = form.Changes(create)
+	if len(changes) != 2 || changes[0].Before != "" || changes[0].After != "Joseph" {
+		t.Errorf("expected the forename to be created as Joseph, actually %v", changes)
+	}
+
+	// The person already has the values from the update.
+	if form.CanRevert(update) || !form.CanRevert(create) {
+		t.Errorf("expected to be able to revert to the create but not the update")
+	}
+	form.SetPerson(nil)
+	if form.CanRevert(create) {
+		t.Errorf("expected not to be able to revert a deleted person")
+	}
+}
@@ -1,7 +1,10 @@
 package people
 
 import (
+	genreModel "github.com/goblimey/films/models/genre"
 	personModel "github.com/goblimey/films/models/person"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/auth"
 )
 
 // The ConcreteListForm satisfies the ListForm interface and holds view data 
@@ -11,8 +14,16 @@ type ConcreteListForm struct {
 	people       []personModel.Person
 	notice       string
 	errorMessage string
+	viewer       auth.Viewer
+	query        ListQuery
+	total        int64
+	genres       []genreModel.Genre
+	tags         []tagModel.Tag
 }
 
+// indexPath is the URI of the index page, used to build the links to other pages.
+const indexPath = "/people"
+
 // People returns the list of Person objects from the form
 func (clf *ConcreteListForm) People() []personModel.Person {
 	return clf.people
@@ -42,3 +53,102 @@ func (clf *ConcreteListForm) SetNotice(notice string) {
 func (clf *ConcreteListForm) SetErrorMessage(errorMessage string) {
 	clf.errorMessage = errorMessage
 }
+
+// Viewer gets the user looking at the page.
+func (clf *ConcreteListForm) Viewer() auth.Viewer {
+	return clf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (clf *ConcreteListForm) SetViewer(viewer auth.Viewer) {
+	clf.viewer = viewer
+}
+
+// Query gets the query that says which people are on the page.
+func (clf *ConcreteListForm) Query() ListQuery {
+	return clf.query.Normalised()
+}
+
+// SetQuery sets the query that says which people are on the page.
+func (clf *ConcreteListForm) SetQuery(query ListQuery) {
+	clf.query = query
+}
+
+// Total gets the number of people that match the query, on all pages.
+func (clf *ConcreteListForm) Total() int64 {
+	return clf.total
+}
+
+// SetTotal sets the number of people that match the query, on all pages.
+func (clf *ConcreteListForm) SetTotal(total int64) {
+	clf.total = total
+}
+
+// PageCount gets the number of pages.  There is always at least one page, even
+// if it's empty.
+func (clf *ConcreteListForm) PageCount() int {
+	size := int64(clf.Query().Size)
+	pages := int((clf.total + size - 1) / size)
+	if pages < 1 {
+		return 1
+	}
+	return pages
+}
+
+// PreviousLink gets the URI of the previous page, or "" on the first page.
+func (clf *ConcreteListForm) PreviousLink() string {
+	query := clf.Query()
+	if query.Page <= 1 {
+		return ""
+	}
+	query.Page--
+	if query.Page > clf.PageCount() {
+		// Beyond the end - go back to the last page.
+		query.Page = clf.PageCount()
+	}
+	return query.URI(indexPath)
+}
+
+// NextLink gets the URI of the next page, or "" on the last page.
+func (clf *ConcreteListForm) NextLink() string {
+	query := clf.Query()
+	if query.Page >= clf.PageCount() {
+		return ""
+	}
+	query.Page++
+	return query.URI(indexPath)
+}
+
+// SortLink gets the URI of the first page sorted on the given field.  If the
+// page is already sorted on that field, the link reverses the direction.
+func (clf *ConcreteListForm) SortLink(field string) string {
+	query := clf.Query()
+	if query.Sort == field {
+		query.Descending = !query.Descending
+	} else {
+		query.Sort = field
+		query.Descending = false
+	}
+	query.Page = 1
+	return query.URI(indexPath)
+}
+
+// Genres gets the genres that the people can be filtered on.
+func (clf *ConcreteListForm) Genres() []genreModel.Genre {
+	return clf.genres
+}
+
+// SetGenres sets the genres that the people can be filtered on.
+func (clf *ConcreteListForm) SetGenres(genres []genreModel.Genre) {
+	clf.genres = genres
+}
+
+// Tags gets the tags that the people can be filtered on.
+func (clf *ConcreteListForm) Tags() []tagModel.Tag {
+	return clf.tags
+}
+
+// SetTags sets the tags that the people can be filtered on.
+func (clf *ConcreteListForm) SetTags(tags []tagModel.Tag) {
+	clf.tags = tags
+}
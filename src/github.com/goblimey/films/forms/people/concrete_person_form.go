@@ -3,14 +3,27 @@ package people
 import (
 	"fmt"
 
+	awardModel "github.com/goblimey/films/models/award"
+	creditModel "github.com/goblimey/films/models/credit"
+	filmModel "github.com/goblimey/films/models/film"
+	genreModel "github.com/goblimey/films/models/genre"
 	personModel "github.com/goblimey/films/models/person"
+	tagModel "github.com/goblimey/films/models/tag"
 	"github.com/goblimey/films/utilities"
+	"github.com/goblimey/films/utilities/auth"
 )
 
 // ConcretePersonForm satisfies the PersonForm interface.
 type ConcretePersonForm struct {
 	person       personModel.Person
+	credits      []creditModel.Credit
+	films        []filmModel.Film
+	genres       []genreModel.Genre
+	tags         []tagModel.Tag
+	allGenres    []genreModel.Genre
+	awards       []awardModel.Nomination
 	errorMessage string
+	viewer       auth.Viewer
 	notice       string
 	fieldError   map[string]string
 }
@@ -22,6 +35,36 @@ func (pfd ConcretePersonForm) Person() personModel.Person {
 	return pfd.person
 }
 
+// Credits gets the person's credits - their filmography.
+func (pfd ConcretePersonForm) Credits() []creditModel.Credit {
+	return pfd.credits
+}
+
+// Films gets the films on which the person can be credited.
+func (pfd ConcretePersonForm) Films() []filmModel.Film {
+	return pfd.films
+}
+
+// Genres gets the genres that the person is in.
+func (pfd ConcretePersonForm) Genres() []genreModel.Genre {
+	return pfd.genres
+}
+
+// Tags gets the person's tags.
+func (pfd ConcretePersonForm) Tags() []tagModel.Tag {
+	return pfd.tags
+}
+
+// AllGenres gets the genres that the person can be put into.
+func (pfd ConcretePersonForm) AllGenres() []genreModel.Genre {
+	return pfd.allGenres
+}
+
+// Awards gets the person's award nominations, most recent ceremony first.
+func (pfd ConcretePersonForm) Awards() []awardModel.Nomination {
+	return pfd.awards
+}
+
 // Notice gets the notice.
 func (pfd ConcretePersonForm) Notice() string {
 	return pfd.notice
@@ -62,6 +105,36 @@ func (pfd *ConcretePersonForm) SetPerson(person personModel.Person) {
 	pfd.person = person
 }
 
+// SetCredits sets the person's credits.
+func (pfd *ConcretePersonForm) SetCredits(credits []creditModel.Credit) {
+	pfd.credits = credits
+}
+
+// SetFilms sets the films on which the person can be credited.
+func (pfd *ConcretePersonForm) SetFilms(films []filmModel.Film) {
+	pfd.films = films
+}
+
+// SetGenres sets the genres that the person is in.
+func (pfd *ConcretePersonForm) SetGenres(genres []genreModel.Genre) {
+	pfd.genres = genres
+}
+
+// SetTags sets the person's tags.
+func (pfd *ConcretePersonForm) SetTags(tags []tagModel.Tag) {
+	pfd.tags = tags
+}
+
+// SetAllGenres sets the genres that the person can be put into.
+func (pfd *ConcretePersonForm) SetAllGenres(genres []genreModel.Genre) {
+	pfd.allGenres = genres
+}
+
+// SetAwards sets the person's award nominations.
+func (pfd *ConcretePersonForm) SetAwards(awards []awardModel.Nomination) {
+	pfd.awards = awards
+}
+
 // SetNotice sets the notice.
 func (pfd *ConcretePersonForm) SetNotice(notice string) {
 	pfd.notice = notice
@@ -72,6 +145,16 @@ func (pfd *ConcretePersonForm) SetErrorMessage(errorMessage string) {
 	pfd.errorMessage = errorMessage
 }
 
+// Viewer gets the user looking at the page.
+func (pfd ConcretePersonForm) Viewer() auth.Viewer {
+	return pfd.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (pfd *ConcretePersonForm) SetViewer(viewer auth.Viewer) {
+	pfd.viewer = viewer
+}
+
 // SetErrorMessageForField sets the error message for a named field
 func (pfd *ConcretePersonForm) SetErrorMessageForField(fieldname, errormessage string) {
 	if pfd.fieldError == nil {
@@ -0,0 +1,78 @@
+package people
+
+import (
+	"time"
+
+	personModel "github.com/goblimey/films/models/person"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteTrashForm satisfies the TrashForm interface and holds the view data
+// for the trash page.
+type ConcreteTrashForm struct {
+	people       []personModel.Person
+	retention    time.Duration
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// People gets the people in the trash, most recently deleted first.
+func (ctf *ConcreteTrashForm) People() []personModel.Person {
+	return ctf.people
+}
+
+// Retention gets how long people are kept in the trash before they are purged.
+func (ctf *ConcreteTrashForm) Retention() time.Duration {
+	return ctf.retention
+}
+
+// PurgeTime gets the time that the given person will be purged - the retention
+// period after they were deleted - or the zero time if there is no retention
+// period.  The purge runs from time to time, so it may happen a little later.
+func (ctf *ConcreteTrashForm) PurgeTime(person personModel.Person) time.Time {
+	if ctf.retention <= 0 {
+		return time.Time{}
+	}
+	return person.DeletedAt().Add(ctf.retention)
+}
+
+// Notice gets the notice.
+func (ctf *ConcreteTrashForm) Notice() string {
+	return ctf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (ctf *ConcreteTrashForm) ErrorMessage() string {
+	return ctf.errorMessage
+}
+
+// SetPeople sets the people in the trash.
+func (ctf *ConcreteTrashForm) SetPeople(people []personModel.Person) {
+	ctf.people = people
+}
+
+// SetRetention sets how long people are kept in the trash.
+func (ctf *ConcreteTrashForm) SetRetention(retention time.Duration) {
+	ctf.retention = retention
+}
+
+// SetNotice sets the notice.
+func (ctf *ConcreteTrashForm) SetNotice(notice string) {
+	ctf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (ctf *ConcreteTrashForm) SetErrorMessage(errorMessage string) {
+	ctf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (ctf *ConcreteTrashForm) Viewer() auth.Viewer {
+	return ctf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (ctf *ConcreteTrashForm) SetViewer(viewer auth.Viewer) {
+	ctf.viewer = viewer
+}
@@ -0,0 +1,26 @@
+package people
+
+import (
+	"testing"
+	"time"
+
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+)
+
+func TestUnitTrashFormPurgeTime(t *testing.T) {
+	deletedAt := time.Date(2016, 3, 1, 12, 0, 0, 0, time.UTC)
+	person := gorpPersonModel.MakeInitialisedPerson(1, "Joseph", "Cotten")
+	person.SetDeletedAt(deletedAt)
+
+	var form ConcreteTrashForm
+	if !form.PurgeTime(person).IsZero() {
+		t.Errorf("expected no purge time without a retention period, actually %v",
+			form.PurgeTime(person))
+	}
+
+	form.SetRetention(720 * time.Hour)
+	expected := time.Date(2016, 3, 31, 12, 0, 0, 0, time.UTC)
+	if !form.PurgeTime(person).Equal(expected) {
+		t.Errorf("expected purge time %v, actually %v", expected, form.PurgeTime(person))
+	}
+}
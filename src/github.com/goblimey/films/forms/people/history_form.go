@@ -0,0 +1,56 @@
+package people
+
+import (
+	auditModel "github.com/goblimey/films/models/audit"
+	personModel "github.com/goblimey/films/models/person"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The HistoryForm holds view data for the history page of a person - the entries
+// in the audit log recording the changes to them.  It's approximately equivalent
+// to a Struts form bean.
+type HistoryForm interface {
+	// PersonID gets the ID of the person whose history is shown.
+	PersonID() uint64
+	// Person gets the person as they are now, or nil if they have been deleted.
+	Person() personModel.Person
+	// Entries gets the audit log entries for the person, newest first.
+	Entries() []auditModel.Entry
+	// Changes gets the values of the fields before and after the change
+	// recorded by the given entry.
+	Changes(entry auditModel.Entry) []FieldChange
+	// CanRevert returns true if the person can be reverted to the values after
+	// the change recorded by the given entry.
+	CanRevert(entry auditModel.Entry) bool
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetPersonID sets the ID of the person whose history is shown.
+	SetPersonID(id uint64)
+	// SetPerson sets the person as they are now.
+	SetPerson(person personModel.Person)
+	// SetEntries sets the audit log entries for the person.
+	SetEntries(entries []auditModel.Entry)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
+
+// FieldChange holds the values of one field before and after a change.  Either
+// may be empty, for example when the record was created or deleted.
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// Changed returns true if the change altered the field.
+func (fc FieldChange) Changed() bool {
+	return fc.Before != fc.After
+}
@@ -1,7 +1,10 @@
 package people
 
 import (
+	genreModel "github.com/goblimey/films/models/genre"
 	personModel "github.com/goblimey/films/models/person"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/auth"
 )
 
 // The ListForm holds view data including a list of people.  It's approximately equivalent 
@@ -19,4 +22,33 @@ type ListForm interface {
 	SetNotice(notice string)
 	//SetErrorMessage sets the error message.
 	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+	// Query gets the query that says which people are on the page.
+	Query() ListQuery
+	// SetQuery sets the query that says which people are on the page.
+	SetQuery(query ListQuery)
+	// Total gets the number of people that match the query, on all pages.
+	Total() int64
+	// SetTotal sets the number of people that match the query, on all pages.
+	SetTotal(total int64)
+	// PageCount gets the number of pages.
+	PageCount() int
+	// PreviousLink gets the URI of the previous page, or "" on the first page.
+	PreviousLink() string
+	// NextLink gets the URI of the next page, or "" on the last page.
+	NextLink() string
+	// SortLink gets the URI of the first page sorted on the given field.  If the
+	// page is already sorted on that field, the link reverses the direction.
+	SortLink(field string) string
+	// Genres gets the genres that the people can be filtered on.
+	Genres() []genreModel.Genre
+	// SetGenres sets the genres that the people can be filtered on.
+	SetGenres(genres []genreModel.Genre)
+	// Tags gets the tags that the people can be filtered on.
+	Tags() []tagModel.Tag
+	// SetTags sets the tags that the people can be filtered on.
+	SetTags(tags []tagModel.Tag)
 }
\ No newline at end of file
@@ -0,0 +1,110 @@
+package people
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// DefaultPageSize is the number of people on a page if the request doesn't say.
+const DefaultPageSize = 20
+
+// MaxPageSize is the largest number of people on a page.
+const MaxPageSize = 100
+
+// ListQuery says which people the index page shows - the page number (counting
+// from 1), the number of people on a page, the field to sort on and the direction,
+// a name to filter on and the IDs of a genre and a tag to filter on.  The zero
+// value is the first page, sorted by surname, with nobody filtered out.
+type ListQuery struct {
+	Page       int
+	Size       int
+	Sort       string
+	Descending bool
+	Name       string
+	Genre      uint64
+	Tag        uint64
+}
+
+// ParseListQuery gets a ListQuery from the parameters of a request, for example
+// "page=2&size=10&sort=forename&dir=desc&name=welles&genre=3".  Missing or silly
+// values are replaced by the defaults, so a hand-edited URI can't cause an error.
+func ParseListQuery(values url.Values) ListQuery {
+	var query ListQuery
+	query.Page, _ = strconv.Atoi(values.Get("page"))
+	query.Size, _ = strconv.Atoi(values.Get("size"))
+	query.Sort = values.Get("sort")
+	query.Descending = values.Get("dir") == "desc"
+	query.Name = strings.TrimSpace(values.Get("name"))
+	query.Genre, _ = strconv.ParseUint(values.Get("genre"), 10, 64)
+	query.Tag, _ = strconv.ParseUint(values.Get("tag"), 10, 64)
+	return query.Normalised()
+}
+
+// Normalised returns a copy of the query with any missing or silly values
+// replaced by the defaults.
+func (q ListQuery) Normalised() ListQuery {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Size < 1 {
+		q.Size = DefaultPageSize
+	}
+	if q.Size > MaxPageSize {
+		q.Size = MaxPageSize
+	}
+	switch q.Sort {
+	case dbsession.SortBySurname, dbsession.SortByForename, dbsession.SortByID:
+	default:
+		q.Sort = dbsession.SortBySurname
+	}
+	return q
+}
+
+// PeopleQuery converts the query to the form that the database session takes.
+func (q ListQuery) PeopleQuery() dbsession.PeopleQuery {
+	q = q.Normalised()
+	return dbsession.PeopleQuery{
+		Name:       q.Name,
+		GenreID:    q.Genre,
+		TagID:      q.Tag,
+		Sort:       q.Sort,
+		Descending: q.Descending,
+		Offset:     (q.Page - 1) * q.Size,
+		Limit:      q.Size,
+	}
+}
+
+// Values converts the query to request parameters - the reverse of
+// ParseListQuery.
+func (q ListQuery) Values() url.Values {
+	q = q.Normalised()
+	values := url.Values{}
+	values.Set("page", strconv.Itoa(q.Page))
+	values.Set("size", strconv.Itoa(q.Size))
+	values.Set("sort", q.Sort)
+	if q.Descending {
+		values.Set("dir", "desc")
+	} else {
+		values.Set("dir", "asc")
+	}
+	if q.Name != "" {
+		values.Set("name", q.Name)
+	}
+	if q.Genre != 0 {
+		values.Set("genre", strconv.FormatUint(q.Genre, 10))
+	}
+	if q.Tag != 0 {
+		values.Set("tag", strconv.FormatUint(q.Tag, 10))
+	}
+	return values
+}
+
+// URI returns the URI of the page of the given resource that shows the people
+// that the query selects, for example "/people?dir=asc&page=2&size=20&sort=surname".
+func (q ListQuery) URI(path string) string {
+	return fmt.Sprintf("%s?%s", path, q.Values().Encode())
+}
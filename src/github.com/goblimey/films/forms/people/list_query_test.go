@@ -0,0 +1,93 @@
+package people
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// TestUnitParseListQuery checks that request parameters are parsed and that
+// missing or silly values are replaced by the defaults.
+func TestUnitParseListQuery(t *testing.T) {
+	var tests = []struct {
+		params   string
+		expected ListQuery
+	}{
+		{"", ListQuery{1, DefaultPageSize, dbsession.SortBySurname, false, "", 0, 0}},
+		{"page=3&size=5&sort=forename&dir=desc&name=+orson+&genre=4&tag=7",
+			ListQuery{3, 5, dbsession.SortByForename, true, "orson", 4, 7}},
+		{"page=-1&size=1000&sort=junk&genre=-1",
+			ListQuery{1, MaxPageSize, dbsession.SortBySurname, false, "", 0, 0}},
+		{"page=x&size=y&tag=z", ListQuery{1, DefaultPageSize, dbsession.SortBySurname, false, "", 0, 0}},
+	}
+
+	for _, test := range tests {
+		values, _ := url.ParseQuery(test.params)
+		query := ParseListQuery(values)
+		if query != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.params, test.expected, query)
+		}
+	}
+}
+
+// TestUnitListQueryPeopleQuery checks that the page number and size are converted
+// to an offset and a limit.
+func TestUnitListQueryPeopleQuery(t *testing.T) {
+	query := ListQuery{Page: 3, Size: 10, Name: "welles", Genre: 4, Tag: 7}
+	pq := query.PeopleQuery()
+	if pq.Offset != 20 || pq.Limit != 10 {
+		t.Errorf("Expected offset 20 and limit 10, got %d and %d", pq.Offset, pq.Limit)
+	}
+	if pq.GenreID != 4 || pq.TagID != 7 {
+		t.Errorf("Expected genre 4 and tag 7, got %d and %d", pq.GenreID, pq.TagID)
+	}
+	if pq.Sort != dbsession.SortBySurname || pq.Name != "welles" {
+		t.Errorf("Expected sort surname and name welles, got %s and %s", pq.Sort, pq.Name)
+	}
+}
+
+// TestUnitListFormLinks checks the page count and the links to the previous and
+// next pages and to the sorted pages.
+func TestUnitListFormLinks(t *testing.T) {
+	var form ConcreteListForm
+	form.SetQuery(ListQuery{Page: 2, Size: 10, Name: "o"})
+	form.SetTotal(25)
+
+	if form.PageCount() != 3 {
+		t.Errorf("Expected 3 pages, got %d", form.PageCount())
+	}
+
+	expected := "/people?dir=asc&name=o&page=1&size=10&sort=surname"
+	if form.PreviousLink() != expected {
+		t.Errorf("Expected previous link %s, got %s", expected, form.PreviousLink())
+	}
+	expected = "/people?dir=asc&name=o&page=3&size=10&sort=surname"
+	if form.NextLink() != expected {
+		t.Errorf("Expected next link %s, got %s", expected, form.NextLink())
+	}
+
+	// The filters are kept when the page is sorted.
+	form.SetQuery(ListQuery{Page: 2, Size: 10, Genre: 4})
+	expected = "/people?dir=asc&genre=4&page=1&size=10&sort=forename"
+	if form.SortLink(dbsession.SortByForename) != expected {
+		t.Errorf("Expected sort link %s, got %s", expected, form.SortLink(dbsession.SortByForename))
+	}
+	form.SetQuery(ListQuery{Page: 2, Size: 10, Name: "o"})
+
+	// Sorting on the current field reverses the direction.
+	expected = "/people?dir=desc&name=o&page=1&size=10&sort=surname"
+	if form.SortLink(dbsession.SortBySurname) != expected {
+		t.Errorf("Expected sort link %s, got %s", expected, form.SortLink(dbsession.SortBySurname))
+	}
+
+	// There is no page before the first or after the last.
+	form.SetQuery(ListQuery{Page: 3, Size: 10})
+	if form.NextLink() != "" {
+		t.Errorf("Expected no next link on the last page, got %s", form.NextLink())
+	}
+	form.SetQuery(ListQuery{Page: 1, Size: 10})
+	if form.PreviousLink() != "" {
+		t.Errorf("Expected no previous link on the first page, got %s", form.PreviousLink())
+	}
+}
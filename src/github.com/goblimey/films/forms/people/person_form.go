@@ -1,7 +1,13 @@
 package people
 
 import (
+	awardModel "github.com/goblimey/films/models/award"
+	creditModel "github.com/goblimey/films/models/credit"
+	filmModel "github.com/goblimey/films/models/film"
+	genreModel "github.com/goblimey/films/models/genre"
 	personModel "github.com/goblimey/films/models/person"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/auth"
 )
 
 // PersonForm holds view data about a Person.  It's used as a data transfer object (DTO)
@@ -14,6 +20,18 @@ import (
 type PersonForm interface {
 	// Person gets the Person embedded in the form.
 	Person() personModel.Person
+	// Credits gets the person's credits - their filmography.
+	Credits() []creditModel.Credit
+	// Films gets the films on which the person can be credited.
+	Films() []filmModel.Film
+	// Genres gets the genres that the person is in.
+	Genres() []genreModel.Genre
+	// Tags gets the person's tags.
+	Tags() []tagModel.Tag
+	// AllGenres gets the genres that the person can be put into.
+	AllGenres() []genreModel.Genre
+	// Awards gets the person's award nominations, most recent ceremony first.
+	Awards() []awardModel.Nomination
 	// Notice gets the notice.
 	Notice() string
 	// ErrorMessage gets the general error message.
@@ -26,10 +44,26 @@ type PersonForm interface {
 	String() string
 	// SetPerson sets the Person in the form.
 	SetPerson(person personModel.Person)
+	// SetCredits sets the person's credits.
+	SetCredits(credits []creditModel.Credit)
+	// SetFilms sets the films on which the person can be credited.
+	SetFilms(films []filmModel.Film)
+	// SetGenres sets the genres that the person is in.
+	SetGenres(genres []genreModel.Genre)
+	// SetTags sets the person's tags.
+	SetTags(tags []tagModel.Tag)
+	// SetAllGenres sets the genres that the person can be put into.
+	SetAllGenres(genres []genreModel.Genre)
+	// SetAwards sets the person's award nominations.
+	SetAwards(awards []awardModel.Nomination)
 	// SetNotice sets the notice.
 	SetNotice(notice string)
 	//SetErrorMessage sets the general error message.
 	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
 	// SetErrorMessageForField sets the error message for a named field
 	SetErrorMessageForField(fieldname, errormessage string)
 	// Validate validates the data in the Person and sets the various error messages.
@@ -0,0 +1,37 @@
+package people
+
+import (
+	"time"
+
+	personModel "github.com/goblimey/films/models/person"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The TrashForm holds view data for the trash page - the people who have been
+// deleted but not yet purged.  It's approximately equivalent to a Struts form bean.
+type TrashForm interface {
+	// People gets the people in the trash, most recently deleted first.
+	People() []personModel.Person
+	// Retention gets how long people are kept in the trash before they are
+	// purged.  0 means until they are purged by hand.
+	Retention() time.Duration
+	// PurgeTime gets the time that the given person will be purged, or the
+	// zero time if they will be kept until they are purged by hand.
+	PurgeTime(person personModel.Person) time.Time
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetPeople sets the people in the trash.
+	SetPeople(people []personModel.Person)
+	// SetRetention sets how long people are kept in the trash.
+	SetRetention(retention time.Duration)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
@@ -0,0 +1,142 @@
+package reviews
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	reviewModel "github.com/goblimey/films/models/review"
+	"github.com/goblimey/films/utilities"
+)
+
+// MaxTitleLength is the longest title that the reviews table can hold.
+const MaxTitleLength = 255
+
+// MaxBodyLength is the longest review that a user can write, in characters.
+const MaxBodyLength = 10000
+
+// ConcreteReviewForm satisfies the ReviewForm interface.
+type ConcreteReviewForm struct {
+	review       reviewModel.Review
+	errorMessage string
+	notice       string
+	fieldError   map[string]string
+}
+
+// Getters
+
+// Review gets the Review embedded in the form.
+func (rf ConcreteReviewForm) Review() reviewModel.Review {
+	return rf.review
+}
+
+// Notice gets the notice.
+func (rf ConcreteReviewForm) Notice() string {
+	return rf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (rf ConcreteReviewForm) ErrorMessage() string {
+	return rf.errorMessage
+}
+
+// FieldErrors returns all the field errors as a map.
+func (rf ConcreteReviewForm) FieldErrors() map[string]string {
+	return rf.fieldError
+}
+
+// ErrorForField returns the error message about a field (may be an empty string).
+func (rf ConcreteReviewForm) ErrorForField(key string) string {
+	if rf.fieldError == nil {
+		// The field error map has not been set up.
+		return ""
+	}
+	return rf.fieldError[key]
+}
+
+// String returns a string version of the ReviewForm.
+func (rf ConcreteReviewForm) String() string {
+	return fmt.Sprintf("ConcreteReviewForm={review=%s, notice=%s,errorMessage=%s,fieldError=%s}",
+		rf.review,
+		rf.notice,
+		rf.errorMessage,
+		utilities.Map2String(rf.fieldError))
+}
+
+// Setters
+
+// SetReview sets the Review in the form.
+func (rf *ConcreteReviewForm) SetReview(review reviewModel.Review) {
+	rf.review = review
+}
+
+// SetNotice sets the notice.
+func (rf *ConcreteReviewForm) SetNotice(notice string) {
+	rf.notice = notice
+}
+
+// SetErrorMessage sets the general error message.
+func (rf *ConcreteReviewForm) SetErrorMessage(errorMessage string) {
+	rf.errorMessage = errorMessage
+}
+
+// SetErrorMessageForField sets the error message for a named field
+func (rf *ConcreteReviewForm) SetErrorMessageForField(fieldname, errormessage string) {
+	if rf.fieldError == nil {
+		rf.fieldError = make(map[string]string)
+	}
+	rf.fieldError[fieldname] = errormessage
+}
+
+// Validate validates the data in the Review and sets the various error messages.
+// It returns true if the data is valid, false if there are errors.  The rating is
+// required, the title and the body are not.  Any field errors already recorded
+// (for example, a rating in the HTTP request that could not be converted to a
+// number) also cause the validation to fail.
+func (rf *ConcreteReviewForm) Validate() bool {
+	review := rf.Review()
+	// trim all string items
+	review.SetTitle(utilities.Trim(review.Title()))
+	review.SetBody(strings.TrimSpace(review.Body()))
+	// validate
+	valid := len(rf.fieldError) == 0
+
+	if review.FilmID() == 0 {
+		rf.SetErrorMessageForField("Film", "you must choose the Film")
+		valid = false
+	}
+	if rf.ErrorForField("Rating") == "" && !reviewModel.ValidRating(review.Rating()) {
+		rf.SetErrorMessageForField("Rating",
+			fmt.Sprintf("the Rating must be between %d and %d",
+				reviewModel.MinRating, reviewModel.MaxRating))
+		valid = false
+	}
+	if utf8.RuneCountInString(review.Title()) > MaxTitleLength {
+		rf.SetErrorMessageForField("Title",
+			fmt.Sprintf("the Title must be no more than %d characters", MaxTitleLength))
+		valid = false
+	}
+	if utf8.RuneCountInString(review.Body()) > MaxBodyLength {
+		rf.SetErrorMessageForField("Body",
+			fmt.Sprintf("the Review must be no more than %d characters", MaxBodyLength))
+		valid = false
+	}
+	return valid
+}
+
+// FieldErrorSummary returns the field errors in the form as a single string,
+// sorted by field name, for display as a general error message on a page that
+// does not show the individual fields.
+func FieldErrorSummary(form ReviewForm) string {
+	keys := make([]string, 0, len(form.FieldErrors()))
+	for key := range form.FieldErrors() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	messages := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messages = append(messages, form.FieldErrors()[key])
+	}
+	return strings.Join(messages, "; ")
+}
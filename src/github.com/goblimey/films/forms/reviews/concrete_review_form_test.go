@@ -0,0 +1,64 @@
+package reviews
+
+import (
+	"strings"
+	"testing"
+
+	model "github.com/goblimey/films/models/review/gorpmysql"
+)
+
+var expectedFilmID uint64 = 4
+var expectedUserID uint64 = 5
+
+// A rating with no title or body passes validation.
+func TestUnitValidateRatingOnly(t *testing.T) {
+	form := CreateReviewForm(expectedFilmID, 7, "", "")
+	if !form.Validate() {
+		t.Errorf("Expected the validation to succeed, got errors %v", form.FieldErrors())
+	}
+}
+
+// A rating outside the range fails validation.
+func TestUnitValidateReviewBadRating(t *testing.T) {
+	expectedError := "the Rating must be between 1 and 10"
+	for _, rating := range []int{0, 11, -1} {
+		form := CreateReviewForm(expectedFilmID, rating, "Fine", "")
+		if form.Validate() {
+			t.Errorf("rating %d - expected the validation to fail", rating)
+		}
+		if form.ErrorForField("Rating") != expectedError {
+			t.Errorf("rating %d - expected \"%s\", got \"%s\"", rating, expectedError,
+				form.ErrorForField("Rating"))
+		}
+	}
+}
+
+// An over-long title and body fail validation and the title is trimmed.
+func TestUnitValidateReviewTooLong(t *testing.T) {
+	form := CreateReviewForm(expectedFilmID, 7, strings.Repeat("x", MaxTitleLength+1),
+		strings.Repeat("y", MaxBodyLength+1))
+	if form.Validate() {
+		t.Errorf("Expected the validation to fail - title and body too long")
+	}
+	expectedSummary := "the Review must be no more than 10000 characters; " +
+		"the Title must be no more than 255 characters"
+	if FieldErrorSummary(&form) != expectedSummary {
+		t.Errorf("Expected \"%s\", got \"%s\"", expectedSummary, FieldErrorSummary(&form))
+	}
+
+	form = CreateReviewForm(expectedFilmID, 7, "  Spooky  ", "\n  Very spooky.\n")
+	if !form.Validate() {
+		t.Errorf("Expected the validation to succeed, got errors %v", form.FieldErrors())
+	}
+	if form.Review().Title() != "Spooky" || form.Review().Body() != "Very spooky." {
+		t.Errorf("Expected the title and body to be trimmed, got \"%s\" and \"%s\"",
+			form.Review().Title(), form.Review().Body())
+	}
+}
+
+func CreateReviewForm(filmID uint64, rating int, title string, body string) ConcreteReviewForm {
+	review := model.MakeInitialisedReview(0, filmID, expectedUserID, rating, title, body)
+	var form ConcreteReviewForm
+	form.SetReview(review)
+	return form
+}
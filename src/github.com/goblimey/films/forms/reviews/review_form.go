@@ -0,0 +1,36 @@
+package reviews
+
+import (
+	reviewModel "github.com/goblimey/films/models/review"
+)
+
+// ReviewForm holds view data about a Review.  It's used as a data transfer object
+// (DTO) when a user rates or reviews a film.  It contains a Review; a validator
+// function that validates the data in the Review and sets the various error
+// messages; a general error message, a notice and a set of error messages about
+// individual fields of the Review.
+type ReviewForm interface {
+	// Review gets the Review embedded in the form.
+	Review() reviewModel.Review
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// FieldErrors returns all the field errors as a map.
+	FieldErrors() map[string]string
+	// ErrorForField returns the error message about a field (may be an empty string).
+	ErrorForField(key string) string
+	// String returns a string version of the ReviewForm.
+	String() string
+	// SetReview sets the Review in the form.
+	SetReview(review reviewModel.Review)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the general error message.
+	SetErrorMessage(errorMessage string)
+	// SetErrorMessageForField sets the error message for a named field
+	SetErrorMessageForField(fieldname, errormessage string)
+	// Validate validates the data in the Review and sets the various error messages.
+	// It returns true if the data is valid, false if there are errors.
+	Validate() bool
+}
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/search"
+)
+
+// The ConcreteSearchForm satisfies the SearchForm interface and holds the view
+// data for the search page.  It's approximately equivalent to a Struts form
+// bean.
+type ConcreteSearchForm struct {
+	query        string
+	results      []search.Result
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// Query gets the text that was searched for.
+func (csf *ConcreteSearchForm) Query() string {
+	return csf.query
+}
+
+// Results gets the people and films that match the query.
+func (csf *ConcreteSearchForm) Results() []search.Result {
+	return csf.results
+}
+
+// Notice gets the notice.
+func (csf *ConcreteSearchForm) Notice() string {
+	return csf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (csf *ConcreteSearchForm) ErrorMessage() string {
+	return csf.errorMessage
+}
+
+// SetQuery sets the text to search for.
+func (csf *ConcreteSearchForm) SetQuery(query string) {
+	csf.query = query
+}
+
+// SetResults sets the people and films that match the query.
+func (csf *ConcreteSearchForm) SetResults(results []search.Result) {
+	csf.results = results
+}
+
+// SetNotice sets the notice.
+func (csf *ConcreteSearchForm) SetNotice(notice string) {
+	csf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (csf *ConcreteSearchForm) SetErrorMessage(errorMessage string) {
+	csf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (csf *ConcreteSearchForm) Viewer() auth.Viewer {
+	return csf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (csf *ConcreteSearchForm) SetViewer(viewer auth.Viewer) {
+	csf.viewer = viewer
+}
@@ -0,0 +1,32 @@
+package search
+
+import (
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/search"
+)
+
+// The SearchForm holds view data for the search page - the query and the list of
+// people and films that match it.  It's approximately equivalent to a Struts
+// form bean.
+type SearchForm interface {
+	// Query gets the text that was searched for.
+	Query() string
+	// Results gets the people and films that match the query, best match first.
+	Results() []search.Result
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetQuery sets the text to search for.
+	SetQuery(query string)
+	// SetResults sets the people and films that match the query.
+	SetResults(results []search.Result)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
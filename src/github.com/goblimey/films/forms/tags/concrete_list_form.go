@@ -0,0 +1,88 @@
+package tags
+
+import (
+	genreModel "github.com/goblimey/films/models/genre"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// MaxWeight is the size of the most used tags in the cloud.
+const MaxWeight = 5
+
+// The ConcreteListForm satisfies the ListForm interface and holds the view data
+// for the tag cloud page.  It's approximately equivalent to a Struts form bean.
+type ConcreteListForm struct {
+	tags         []tagModel.Tag
+	genres       []genreModel.Genre
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// Tags gets the tags that are in use.
+func (clf *ConcreteListForm) Tags() []tagModel.Tag {
+	return clf.tags
+}
+
+// Genres gets the genres.
+func (clf *ConcreteListForm) Genres() []genreModel.Genre {
+	return clf.genres
+}
+
+// Weight gets the size of the given tag in the cloud.  The sizes are spread
+// evenly between the least and the most used tags.
+func (clf *ConcreteListForm) Weight(tag tagModel.Tag) int {
+	least, most := 0, 0
+	for i, t := range clf.tags {
+		if i == 0 || t.Count() < least {
+			least = t.Count()
+		}
+		if t.Count() > most {
+			most = t.Count()
+		}
+	}
+	if most <= least {
+		return 1
+	}
+	return 1 + (tag.Count()-least)*(MaxWeight-1)/(most-least)
+}
+
+// Notice gets the notice.
+func (clf *ConcreteListForm) Notice() string {
+	return clf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (clf *ConcreteListForm) ErrorMessage() string {
+	return clf.errorMessage
+}
+
+// SetTags sets the tags that are in use.
+func (clf *ConcreteListForm) SetTags(tags []tagModel.Tag) {
+	clf.tags = tags
+}
+
+// SetGenres sets the genres.
+func (clf *ConcreteListForm) SetGenres(genres []genreModel.Genre) {
+	clf.genres = genres
+}
+
+// SetNotice sets the notice.
+func (clf *ConcreteListForm) SetNotice(notice string) {
+	clf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (clf *ConcreteListForm) SetErrorMessage(errorMessage string) {
+	clf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (clf *ConcreteListForm) Viewer() auth.Viewer {
+	return clf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (clf *ConcreteListForm) SetViewer(viewer auth.Viewer) {
+	clf.viewer = viewer
+}
@@ -0,0 +1,45 @@
+package tags
+
+import (
+	"testing"
+
+	tagModel "github.com/goblimey/films/models/tag"
+)
+
+// makeTag makes a tag used the given number of times.
+func makeTag(id uint64, name string, count int) tagModel.Tag {
+	tag := tagModel.MakeInitialisedTag(id, name)
+	tag.SetCount(count)
+	return tag
+}
+
+// TestUnitWeight checks that the sizes of the tags in the cloud are spread from 1
+// for the least used to MaxWeight for the most used.
+func TestUnitWeight(t *testing.T) {
+	var form ConcreteListForm
+	rare := makeTag(1, "pre-code", 2)
+	middling := makeTag(2, "heist", 6)
+	common := makeTag(3, "film noir", 10)
+	form.SetTags([]tagModel.Tag{common, middling, rare})
+
+	var tests = []struct {
+		tag      tagModel.Tag
+		expected int
+	}{
+		{rare, 1},
+		{middling, 3},
+		{common, MaxWeight},
+	}
+	for _, test := range tests {
+		if form.Weight(test.tag) != test.expected {
+			t.Errorf("%s: expected weight %d, got %d", test.tag.Name(), test.expected,
+				form.Weight(test.tag))
+		}
+	}
+
+	// If all the tags are used equally, they are all the same size.
+	form.SetTags([]tagModel.Tag{rare})
+	if form.Weight(rare) != 1 {
+		t.Errorf("expected weight 1 for a single tag, got %d", form.Weight(rare))
+	}
+}
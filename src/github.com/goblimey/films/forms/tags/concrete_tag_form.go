@@ -0,0 +1,79 @@
+package tags
+
+import (
+	filmModel "github.com/goblimey/films/models/film"
+	personModel "github.com/goblimey/films/models/person"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteTagForm satisfies the TagForm interface and holds the view data for
+// the page of a tag.  It's approximately equivalent to a Struts form bean.
+type ConcreteTagForm struct {
+	tag          tagModel.Tag
+	films        []filmModel.Film
+	people       []personModel.Person
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// Tag gets the tag.
+func (ctf *ConcreteTagForm) Tag() tagModel.Tag {
+	return ctf.tag
+}
+
+// Films gets the films with the tag.
+func (ctf *ConcreteTagForm) Films() []filmModel.Film {
+	return ctf.films
+}
+
+// People gets the people with the tag.
+func (ctf *ConcreteTagForm) People() []personModel.Person {
+	return ctf.people
+}
+
+// Notice gets the notice.
+func (ctf *ConcreteTagForm) Notice() string {
+	return ctf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (ctf *ConcreteTagForm) ErrorMessage() string {
+	return ctf.errorMessage
+}
+
+// SetTag sets the tag.
+func (ctf *ConcreteTagForm) SetTag(tag tagModel.Tag) {
+	ctf.tag = tag
+}
+
+// SetFilms sets the films with the tag.
+func (ctf *ConcreteTagForm) SetFilms(films []filmModel.Film) {
+	ctf.films = films
+}
+
+// SetPeople sets the people with the tag.
+func (ctf *ConcreteTagForm) SetPeople(people []personModel.Person) {
+	ctf.people = people
+}
+
+// SetNotice sets the notice.
+func (ctf *ConcreteTagForm) SetNotice(notice string) {
+	ctf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (ctf *ConcreteTagForm) SetErrorMessage(errorMessage string) {
+	ctf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (ctf *ConcreteTagForm) Viewer() auth.Viewer {
+	return ctf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (ctf *ConcreteTagForm) SetViewer(viewer auth.Viewer) {
+	ctf.viewer = viewer
+}
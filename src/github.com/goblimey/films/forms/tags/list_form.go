@@ -0,0 +1,35 @@
+package tags
+
+import (
+	genreModel "github.com/goblimey/films/models/genre"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ListForm holds view data for the tag cloud page - the tags that are in use
+// and the tree of genres.  It's approximately equivalent to a Struts form bean.
+type ListForm interface {
+	// Tags gets the tags that are in use, in order of name.
+	Tags() []tagModel.Tag
+	// Genres gets the genres, in order of path.
+	Genres() []genreModel.Genre
+	// Weight gets the size of the given tag in the cloud, from 1 for the least
+	// used tags to MaxWeight for the most used.
+	Weight(tag tagModel.Tag) int
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetTags sets the tags that are in use.
+	SetTags(tags []tagModel.Tag)
+	// SetGenres sets the genres.
+	SetGenres(genres []genreModel.Genre)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
@@ -0,0 +1,37 @@
+package tags
+
+import (
+	filmModel "github.com/goblimey/films/models/film"
+	personModel "github.com/goblimey/films/models/person"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The TagForm holds view data for the page of a tag - the tag and the films and
+// people that have it.  It's approximately equivalent to a Struts form bean.
+type TagForm interface {
+	// Tag gets the tag.
+	Tag() tagModel.Tag
+	// Films gets the films with the tag.
+	Films() []filmModel.Film
+	// People gets the people with the tag.
+	People() []personModel.Person
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetTag sets the tag.
+	SetTag(tag tagModel.Tag)
+	// SetFilms sets the films with the tag.
+	SetFilms(films []filmModel.Film)
+	// SetPeople sets the people with the tag.
+	SetPeople(people []personModel.Person)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	// SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
@@ -0,0 +1,62 @@
+package watchlist
+
+import (
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ConcreteListForm satisfies the ListForm interface and holds view data
+// including the entries on a user's watchlist.  It's approximately equivalent to
+// a Struts form bean.
+type ConcreteListForm struct {
+	entries      []watchlistModel.Entry
+	notice       string
+	errorMessage string
+	viewer       auth.Viewer
+}
+
+// Entries returns the entries on the watchlist, most recently added first.
+func (clf *ConcreteListForm) Entries() []watchlistModel.Entry {
+	return clf.entries
+}
+
+// Notice gets the notice.
+func (clf *ConcreteListForm) Notice() string {
+	return clf.notice
+}
+
+// ErrorMessage gets the general error message.
+func (clf *ConcreteListForm) ErrorMessage() string {
+	return clf.errorMessage
+}
+
+// AddedOn gets the day on which the entry was added to the watchlist, for
+// display.
+func (clf *ConcreteListForm) AddedOn(entry watchlistModel.Entry) string {
+	return entry.AddedAt().Format("2 Jan 2006")
+}
+
+// SetEntries sets the entries on the watchlist.
+func (clf *ConcreteListForm) SetEntries(entries []watchlistModel.Entry) {
+	clf.entries = entries
+}
+
+// SetNotice sets the notice.
+func (clf *ConcreteListForm) SetNotice(notice string) {
+	clf.notice = notice
+}
+
+// SetErrorMessage sets the error message.
+func (clf *ConcreteListForm) SetErrorMessage(errorMessage string) {
+	clf.errorMessage = errorMessage
+}
+
+// Viewer gets the user looking at the page.
+func (clf *ConcreteListForm) Viewer() auth.Viewer {
+	return clf.viewer
+}
+
+// SetViewer sets the user looking at the page.
+func (clf *ConcreteListForm) SetViewer(viewer auth.Viewer) {
+	clf.viewer = viewer
+}
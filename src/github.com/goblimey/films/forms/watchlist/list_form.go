@@ -0,0 +1,27 @@
+package watchlist
+
+import (
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// The ListForm holds view data including the entries on a user's watchlist.  It's
+// approximately equivalent to a Struts form bean.
+type ListForm interface {
+	// Entries returns the entries on the watchlist, most recently added first.
+	Entries() []watchlistModel.Entry
+	// Notice gets the notice.
+	Notice() string
+	// ErrorMessage gets the general error message.
+	ErrorMessage() string
+	// SetEntries sets the entries on the watchlist.
+	SetEntries(entries []watchlistModel.Entry)
+	// SetNotice sets the notice.
+	SetNotice(notice string)
+	//SetErrorMessage sets the error message.
+	SetErrorMessage(errorMessage string)
+	// Viewer gets the user looking at the page.
+	Viewer() auth.Viewer
+	// SetViewer sets the user looking at the page.
+	SetViewer(viewer auth.Viewer)
+}
@@ -1,9 +1,13 @@
 package manual
 
 import (
+	"context"
 	"errors"
+	"time"
 
+	auditModel "github.com/goblimey/films/models/audit"
 	personModel "github.com/goblimey/films/models/person"
+	peopleRepo "github.com/goblimey/films/repositories/people"
 	"github.com/goblimey/films/utilities/dbsession"
 )
 
@@ -13,12 +17,13 @@ type MockRepo struct {
 }
 
 var findAllCalled = false
+var findPageCalled = false
 
 // TestComplete checks that the methods have been called as expected
 func (mr MockRepo) TestComplete() error {
 	em := ""
-	if !findAllCalled {
-		em += "expected MockRepo.FindAll() to be called "
+	if !findAllCalled && !findPageCalled {
+		em += "expected MockRepo.FindAll() or MockRepo.FindPage() to be called "
 	}
 
 	if len(em) > 0 {
@@ -33,6 +38,16 @@ func (mr MockRepo) TestComplete() error {
 func (mr MockRepo) SetSession(session dbsession.DBSession) {
 }
 
+// WithContext returns the mock itself - the context is not used.
+func (mr MockRepo) WithContext(ctx context.Context) peopleRepo.Repository {
+	return mr
+}
+
+// WithUser returns the mock itself - the user is not recorded.
+func (mr MockRepo) WithUser(user string) peopleRepo.Repository {
+	return mr
+}
+
 // FindAll returns a list of valid People - a list of Person objects each
 // of which is valid according to Person.Validate()
 func (mr MockRepo) FindAll() ([]personModel.Person, error) {
@@ -46,6 +61,17 @@ func (mr MockRepo) FindAll() ([]personModel.Person, error) {
 
 }
 
+// FindPage returns the list of People as a single page.
+func (mr MockRepo) FindPage(query dbsession.PeopleQuery) ([]personModel.Person, int64, error) {
+	findPageCalled = true
+	if mr.PersonList == nil {
+		// Return an error to test error handling
+		return nil, 0, errors.New("Test Error Message")
+	}
+
+	return mr.PersonList, int64(len(mr.PersonList)), nil
+}
+
 // FindByID fetches the row from the people table with the given uint64 id. It validates that data
 // and, if it's valid, uses it to create a Person and returns a pointer to it.  If the data is not
 // valid the function returns an error message.
@@ -63,6 +89,11 @@ func (mr MockRepo) FindByIDStr(idStr string) (personModel.Person, error) {
 
 }
 
+// CreateAll is not expected to be called.
+func (mr MockRepo) CreateAll(people []personModel.Person) ([]personModel.Person, error) {
+	return nil, errors.New("CreateAll(): not expected this method to be called")
+}
+
 // Create takes a person, creates a record in the people table containing the same data
 // and returns any error that the DB call supplies to it.  On a successful create, the
 // error will be nil.
@@ -92,3 +123,33 @@ func (mr MockRepo) DeleteByID(id uint64) (int64, error) {
 func (mr MockRepo) DeleteByIDStr(idStr string) (int64, error) {
 	return 0, errors.New("DeleteByIDStr(): not expected this method to be called")
 }
+
+// History is not expected to be called.
+func (mr MockRepo) History(id uint64) ([]auditModel.Entry, error) {
+	return nil, errors.New("History(): not expected this method to be called")
+}
+
+// Revert is not expected to be called.
+func (mr MockRepo) Revert(id uint64, entryID uint64, version int64) (personModel.Person, error) {
+	return nil, errors.New("Revert(): not expected this method to be called")
+}
+
+// FindTrash is not expected to be called.
+func (mr MockRepo) FindTrash() ([]personModel.Person, error) {
+	return nil, errors.New("FindTrash(): not expected this method to be called")
+}
+
+// Restore is not expected to be called.
+func (mr MockRepo) Restore(id uint64) (personModel.Person, error) {
+	return nil, errors.New("Restore(): not expected this method to be called")
+}
+
+// Purge is not expected to be called.
+func (mr MockRepo) Purge(id uint64) (int64, error) {
+	return 0, errors.New("Purge(): not expected this method to be called")
+}
+
+// PurgeTrash is not expected to be called.
+func (mr MockRepo) PurgeTrash(deletedBefore time.Time) (int, error) {
+	return 0, errors.New("PurgeTrash(): not expected this method to be called")
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The actions that an audit entry can record.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	// ActionRevert is an update that restores an earlier version of a record.
+	ActionRevert = "revert"
+	// ActionRestore brings a deleted record back from the trash.
+	ActionRestore = "restore"
+	// ActionPurge removes a deleted record from the trash for good.
+	ActionPurge = "purge"
+)
+
+// Entry represents one entry in the audit log - a record of a single change to a
+// record in one of the tables.  It says which record was changed, how, by whom
+// and when, and holds the values of the record before and after the change,
+// encoded by EncodeValues.  A created record has no before values and a deleted
+// one has no after values.  The version is the version of the record after the
+// change, or the version that was deleted.
+//
+// The log is append-only - entries are written in the same transaction as the
+// change that they record and are never changed or removed.
+type Entry interface {
+	// ID gets the id of the entry
+	ID() uint64
+	// TableName gets the name of the table holding the changed record
+	TableName() string
+	// RecordID gets the id of the changed record
+	RecordID() uint64
+	// Action gets the kind of change, for example ActionCreate
+	Action() string
+	// Version gets the version of the record after the change
+	Version() int64
+	// ChangedBy gets the name of the user who made the change
+	ChangedBy() string
+	// ChangedAt gets the time of the change
+	ChangedAt() time.Time
+	// Before gets the encoded values of the record before the change
+	Before() string
+	// After gets the encoded values of the record after the change
+	After() string
+	// String gets the entry as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetTableName sets the name of the table holding the changed record
+	SetTableName(tableName string)
+	// SetRecordID sets the id of the changed record
+	SetRecordID(recordID uint64)
+	// SetAction sets the kind of change
+	SetAction(action string)
+	// SetVersion sets the version of the record after the change
+	SetVersion(version int64)
+	// SetChangedBy sets the name of the user who made the change
+	SetChangedBy(changedBy string)
+	// SetChangedAt sets the time of the change
+	SetChangedAt(changedAt time.Time)
+	// SetBefore sets the encoded values of the record before the change
+	SetBefore(before string)
+	// SetAfter sets the encoded values of the record after the change
+	SetAfter(after string)
+}
+
+// EncodeValues encodes the values of the fields of a record, keyed by field name,
+// for storing in an entry.  An empty or nil map is encoded as "".
+func EncodeValues(values map[string]string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	// Marshalling a map of strings can't fail.  The keys come out sorted.
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// DecodeValues decodes the values stored in an entry by EncodeValues.  If there
+// are none, or they can't be decoded, it returns an empty map.
+func DecodeValues(encoded string) map[string]string {
+	values := make(map[string]string)
+	if encoded != "" {
+		json.Unmarshal([]byte(encoded), &values)
+	}
+	return values
+}
@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConcreteEntry represents an audit log entry and satisfies the Entry interface.
+type ConcreteEntry struct {
+	id        uint64
+	tableName string
+	recordID  uint64
+	action    string
+	version   int64
+	changedBy string
+	changedAt time.Time
+	before    string
+	after     string
+}
+
+// Define the factory functions.
+
+// MakeEntry creates and returns a new uninitialised Entry object
+func MakeEntry() Entry {
+	var concreteEntry ConcreteEntry
+	return &concreteEntry
+}
+
+// MakeInitialisedEntry creates and returns a new Entry object initialised from the
+// arguments
+func MakeInitialisedEntry(id uint64, tableName string, recordID uint64, action string,
+	version int64, changedBy string, changedAt time.Time, before string, after string) Entry {
+
+	entry := MakeEntry()
+	entry.SetID(id)
+	entry.SetTableName(tableName)
+	entry.SetRecordID(recordID)
+	entry.SetAction(action)
+	entry.SetVersion(version)
+	entry.SetChangedBy(changedBy)
+	entry.SetChangedAt(changedAt)
+	entry.SetBefore(before)
+	entry.SetAfter(after)
+	return entry
+}
+
+// Clone creates and returns a new Entry object initialised from a source Entry.
+func Clone(source Entry) Entry {
+	return MakeInitialisedEntry(source.ID(), source.TableName(), source.RecordID(),
+		source.Action(), source.Version(), source.ChangedBy(), source.ChangedAt(),
+		source.Before(), source.After())
+}
+
+// Define the getters.
+
+// ID gets the id of the entry.
+func (ce ConcreteEntry) ID() uint64 {
+	return ce.id
+}
+
+// TableName gets the name of the table holding the changed record.
+func (ce ConcreteEntry) TableName() string {
+	return ce.tableName
+}
+
+// RecordID gets the id of the changed record.
+func (ce ConcreteEntry) RecordID() uint64 {
+	return ce.recordID
+}
+
+// Action gets the kind of change.
+func (ce ConcreteEntry) Action() string {
+	return ce.action
+}
+
+// Version gets the version of the record after the change.
+func (ce ConcreteEntry) Version() int64 {
+	return ce.version
+}
+
+// ChangedBy gets the name of the user who made the change.
+func (ce ConcreteEntry) ChangedBy() string {
+	return ce.changedBy
+}
+
+// ChangedAt gets the time of the change.
+func (ce ConcreteEntry) ChangedAt() time.Time {
+	return ce.changedAt
+}
+
+// Before gets the encoded values of the record before the change.
+func (ce ConcreteEntry) Before() string {
+	return ce.before
+}
+
+// After gets the encoded values of the record after the change.
+func (ce ConcreteEntry) After() string {
+	return ce.after
+}
+
+// String gets the entry as a String.
+func (ce ConcreteEntry) String() string {
+	return fmt.Sprintf("ConcreteEntry={id=%d, %s %s %d version %d by %s}",
+		ce.id, ce.action, ce.tableName, ce.recordID, ce.version, ce.changedBy)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (ce *ConcreteEntry) SetID(id uint64) {
+	ce.id = id
+}
+
+// SetTableName sets the name of the table holding the changed record.
+func (ce *ConcreteEntry) SetTableName(tableName string) {
+	ce.tableName = tableName
+}
+
+// SetRecordID sets the id of the changed record.
+func (ce *ConcreteEntry) SetRecordID(recordID uint64) {
+	ce.recordID = recordID
+}
+
+// SetAction sets the kind of change.
+func (ce *ConcreteEntry) SetAction(action string) {
+	ce.action = action
+}
+
+// SetVersion sets the version of the record after the change.
+func (ce *ConcreteEntry) SetVersion(version int64) {
+	ce.version = version
+}
+
+// SetChangedBy sets the name of the user who made the change.
+func (ce *ConcreteEntry) SetChangedBy(changedBy string) {
+	ce.changedBy = changedBy
+}
+
+// SetChangedAt sets the time of the change.
+func (ce *ConcreteEntry) SetChangedAt(changedAt time.Time) {
+	ce.changedAt = changedAt
+}
+
+// SetBefore sets the encoded values of the record before the change.
+func (ce *ConcreteEntry) SetBefore(before string) {
+	ce.before = before
+}
+
+// SetAfter sets the encoded values of the record after the change.
+func (ce *ConcreteEntry) SetAfter(after string) {
+	ce.after = after
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+var expectedID uint64 = 2
+var expectedRecordID uint64 = 3
+var expectedVersion int64 = 4
+var expectedChangedBy = "carol"
+var expectedChangedAt = time.Date(1949, 9, 1, 12, 0, 0, 0, time.UTC)
+
+func TestUnitCreateConcreteEntryCheckFields(t *testing.T) {
+	before := EncodeValues(map[string]string{"forename": "Joseph"})
+	after := EncodeValues(map[string]string{"forename": "Joe"})
+	entry := MakeInitialisedEntry(expectedID, "people", expectedRecordID, ActionUpdate,
+		expectedVersion, expectedChangedBy, expectedChangedAt, before, after)
+	clone := Clone(entry)
+	if clone.ID() != expectedID || clone.RecordID() != expectedRecordID {
+		t.Errorf("expected IDs %d and %d actually %d and %d", expectedID, expectedRecordID,
+			clone.ID(), clone.RecordID())
+	}
+	if clone.TableName() != "people" || clone.Action() != ActionUpdate {
+		t.Errorf("expected update of people actually %s of %s", clone.Action(), clone.TableName())
+	}
+	if clone.Version() != expectedVersion {
+		t.Errorf("expected version %d actually %d", expectedVersion, clone.Version())
+	}
+	if clone.ChangedBy() != expectedChangedBy || !clone.ChangedAt().Equal(expectedChangedAt) {
+		t.Errorf("expected changed by %s at %v actually by %s at %v", expectedChangedBy,
+			expectedChangedAt, clone.ChangedBy(), clone.ChangedAt())
+	}
+	if clone.Before() != before || clone.After() != after {
+		t.Errorf("expected before %s and after %s actually %s and %s", before, after,
+			clone.Before(), clone.After())
+	}
+}
+
+func TestUnitEncodeAndDecodeValues(t *testing.T) {
+	encoded := EncodeValues(map[string]string{"surname": "Cotten", "forename": "Joseph"})
+	if encoded != `{"forename":"Joseph","surname":"Cotten"}` {
+		t.Errorf("unexpected encoding %s", encoded)
+	}
+	values := DecodeValues(encoded)
+	if len(values) != 2 || values["forename"] != "Joseph" || values["surname"] != "Cotten" {
+		t.Errorf("expected Joseph Cotten actually %v", values)
+	}
+	if EncodeValues(nil) != "" {
+		t.Errorf("expected no values to be encoded as an empty string")
+	}
+	if len(DecodeValues("")) != 0 || len(DecodeValues("junk")) != 0 {
+		t.Errorf("expected no values from an empty or junk string")
+	}
+}
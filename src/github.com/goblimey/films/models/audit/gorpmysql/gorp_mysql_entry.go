@@ -0,0 +1,159 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"time"
+
+	auditModel "github.com/goblimey/films/models/audit"
+)
+
+// The GorpMysqlEntry struct implements the Entry interface and holds a single row
+// from the AUDIT_LOG table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.  The time of the change is stored as seconds since the Unix epoch, which
+// works the same way with every database.
+type GorpMysqlEntry struct {
+	IDField        uint64
+	TableNameField string
+	RecordIDField  uint64
+	ActionField    string
+	VersionField   int64
+	ChangedByField string
+	ChangedAtField int64
+	BeforeField    string
+	AfterField     string
+}
+
+// Factory functions
+
+// MakeEntry creates and returns a new uninitialised Entry object
+func MakeEntry() auditModel.Entry {
+	var gorpMysqlEntry GorpMysqlEntry
+	return &gorpMysqlEntry
+}
+
+// MakeInitialisedEntry creates and returns a new Entry object initialised from the
+// arguments
+func MakeInitialisedEntry(id uint64, tableName string, recordID uint64, action string,
+	version int64, changedBy string, changedAt time.Time, before string,
+	after string) auditModel.Entry {
+
+	entry := MakeEntry()
+	entry.SetID(id)
+	entry.SetTableName(tableName)
+	entry.SetRecordID(recordID)
+	entry.SetAction(action)
+	entry.SetVersion(version)
+	entry.SetChangedBy(changedBy)
+	entry.SetChangedAt(changedAt)
+	entry.SetBefore(before)
+	entry.SetAfter(after)
+	return entry
+}
+
+// Clone creates and returns a new Entry object initialised from a source Entry.
+func Clone(source auditModel.Entry) auditModel.Entry {
+	return MakeInitialisedEntry(source.ID(), source.TableName(), source.RecordID(),
+		source.Action(), source.Version(), source.ChangedBy(), source.ChangedAt(),
+		source.Before(), source.After())
+}
+
+// Methods to implement the Entry interface.
+
+// ID gets the id of the entry.
+func (e GorpMysqlEntry) ID() uint64 {
+	return e.IDField
+}
+
+// TableName gets the name of the table holding the changed record
+func (e GorpMysqlEntry) TableName() string {
+	return e.TableNameField
+}
+
+// RecordID gets the id of the changed record
+func (e GorpMysqlEntry) RecordID() uint64 {
+	return e.RecordIDField
+}
+
+// Action gets the kind of change
+func (e GorpMysqlEntry) Action() string {
+	return e.ActionField
+}
+
+// Version gets the version of the record after the change
+func (e GorpMysqlEntry) Version() int64 {
+	return e.VersionField
+}
+
+// ChangedBy gets the name of the user who made the change
+func (e GorpMysqlEntry) ChangedBy() string {
+	return e.ChangedByField
+}
+
+// ChangedAt gets the time of the change, in UTC
+func (e GorpMysqlEntry) ChangedAt() time.Time {
+	return time.Unix(e.ChangedAtField, 0).UTC()
+}
+
+// Before gets the encoded values of the record before the change
+func (e GorpMysqlEntry) Before() string {
+	return e.BeforeField
+}
+
+// After gets the encoded values of the record after the change
+func (e GorpMysqlEntry) After() string {
+	return e.AfterField
+}
+
+// String renders the entry as a string
+func (e GorpMysqlEntry) String() string {
+	return fmt.Sprintf("{%d, %s %s %d version %d by %s}", e.IDField, e.ActionField,
+		e.TableNameField, e.RecordIDField, e.VersionField, e.ChangedByField)
+}
+
+// SetID sets the entry's id to the given value
+func (e *GorpMysqlEntry) SetID(id uint64) {
+	e.IDField = id
+}
+
+// SetTableName sets the name of the table holding the changed record
+func (e *GorpMysqlEntry) SetTableName(tableName string) {
+	e.TableNameField = tableName
+}
+
+// SetRecordID sets the id of the changed record
+func (e *GorpMysqlEntry) SetRecordID(recordID uint64) {
+	e.RecordIDField = recordID
+}
+
+// SetAction sets the kind of change
+func (e *GorpMysqlEntry) SetAction(action string) {
+	e.ActionField = action
+}
+
+// SetVersion sets the version of the record after the change
+func (e *GorpMysqlEntry) SetVersion(version int64) {
+	e.VersionField = version
+}
+
+// SetChangedBy sets the name of the user who made the change
+func (e *GorpMysqlEntry) SetChangedBy(changedBy string) {
+	e.ChangedByField = changedBy
+}
+
+// SetChangedAt sets the time of the change.  It's stored to the nearest second.
+func (e *GorpMysqlEntry) SetChangedAt(changedAt time.Time) {
+	e.ChangedAtField = changedAt.Unix()
+}
+
+// SetBefore sets the encoded values of the record before the change
+func (e *GorpMysqlEntry) SetBefore(before string) {
+	e.BeforeField = before
+}
+
+// SetAfter sets the encoded values of the record after the change
+func (e *GorpMysqlEntry) SetAfter(after string) {
+	e.AfterField = after
+}
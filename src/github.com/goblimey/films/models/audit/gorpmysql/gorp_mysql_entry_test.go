@@ -0,0 +1,29 @@
+package gorpmysql
+
+import (
+	"testing"
+	"time"
+
+	auditModel "github.com/goblimey/films/models/audit"
+)
+
+func TestUnitCreateGorpMysqlEntryCheckFields(t *testing.T) {
+	changedAt := time.Date(1949, 9, 1, 12, 0, 0, 500, time.UTC)
+	entry := MakeInitialisedEntry(2, "people", 3, auditModel.ActionDelete, 4, "carol",
+		changedAt, `{"forename":"Joseph"}`, "")
+	clone := Clone(entry)
+	if clone.ID() != 2 || clone.RecordID() != 3 || clone.Version() != 4 {
+		t.Errorf("expected IDs 2 and 3 and version 4 actually %d, %d and %d",
+			clone.ID(), clone.RecordID(), clone.Version())
+	}
+	if clone.Action() != auditModel.ActionDelete || clone.TableName() != "people" {
+		t.Errorf("expected delete of people actually %s of %s", clone.Action(), clone.TableName())
+	}
+	// The time is stored to the nearest second.
+	if !clone.ChangedAt().Equal(changedAt.Truncate(time.Second)) {
+		t.Errorf("expected time %v actually %v", changedAt, clone.ChangedAt())
+	}
+	if clone.ChangedBy() != "carol" || clone.Before() != `{"forename":"Joseph"}` || clone.After() != "" {
+		t.Errorf("unexpected entry %s", clone.String())
+	}
+}
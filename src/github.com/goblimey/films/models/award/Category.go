@@ -0,0 +1,20 @@
+package award
+
+// Category represents a category of an award ceremony, for example "Best
+// Picture".
+type Category interface {
+	// ID gets the id of the category
+	ID() uint64
+	// CeremonyID gets the id of the ceremony
+	CeremonyID() uint64
+	// Name gets the name of the category
+	Name() string
+	// String gets the category as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetCeremonyID sets the id of the ceremony
+	SetCeremonyID(ceremonyID uint64)
+	// SetName sets the name of the category
+	SetName(name string)
+}
@@ -0,0 +1,20 @@
+package award
+
+// Ceremony represents an award ceremony, for example the Academy Awards of 1995.
+// A ceremony has categories and each category has nominations.
+type Ceremony interface {
+	// ID gets the id of the ceremony
+	ID() uint64
+	// Name gets the name of the awards, for example "Academy Awards"
+	Name() string
+	// Year gets the year of the ceremony
+	Year() int
+	// String gets the ceremony as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetName sets the name of the awards, for example "Academy Awards"
+	SetName(name string)
+	// SetYear sets the year of the ceremony
+	SetYear(year int)
+}
@@ -0,0 +1,65 @@
+package award
+
+// Nomination represents the nomination of a film in a category of an award
+// ceremony and optionally a person for their work on it, for example Best Actor.
+// Won is true if the nominee won the award.
+//
+// A nomination also carries the names of the category and the ceremony, the
+// title and release year of the film and the name of the person, for display.
+// These are filled in by the finders and are not stored in the nominations table.
+type Nomination interface {
+	// ID gets the id of the nomination
+	ID() uint64
+	// CategoryID gets the id of the category
+	CategoryID() uint64
+	// FilmID gets the id of the film
+	FilmID() uint64
+	// PersonID gets the id of the person, or 0 for none
+	PersonID() uint64
+	// Won gets whether the nominee won the award
+	Won() bool
+	// CategoryName gets the name of the category, for display
+	CategoryName() string
+	// CeremonyID gets the id of the ceremony, for display
+	CeremonyID() uint64
+	// CeremonyName gets the name of the ceremony, for display
+	CeremonyName() string
+	// CeremonyYear gets the year of the ceremony, for display
+	CeremonyYear() int
+	// FilmTitle gets the title of the film, for display
+	FilmTitle() string
+	// FilmReleaseYear gets the release year of the film, for display
+	FilmReleaseYear() int
+	// PersonForename gets the forename of the person, for display
+	PersonForename() string
+	// PersonSurname gets the surname of the person, for display
+	PersonSurname() string
+	// String gets the nomination as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetCategoryID sets the id of the category
+	SetCategoryID(categoryID uint64)
+	// SetFilmID sets the id of the film
+	SetFilmID(filmID uint64)
+	// SetPersonID sets the id of the person, or 0 for none
+	SetPersonID(personID uint64)
+	// SetWon sets whether the nominee won the award
+	SetWon(won bool)
+	// SetCategoryName sets the name of the category, for display
+	SetCategoryName(categoryName string)
+	// SetCeremonyID sets the id of the ceremony, for display
+	SetCeremonyID(ceremonyID uint64)
+	// SetCeremonyName sets the name of the ceremony, for display
+	SetCeremonyName(ceremonyName string)
+	// SetCeremonyYear sets the year of the ceremony, for display
+	SetCeremonyYear(ceremonyYear int)
+	// SetFilmTitle sets the title of the film, for display
+	SetFilmTitle(filmTitle string)
+	// SetFilmReleaseYear sets the release year of the film, for display
+	SetFilmReleaseYear(filmReleaseYear int)
+	// SetPersonForename sets the forename of the person, for display
+	SetPersonForename(personForename string)
+	// SetPersonSurname sets the surname of the person, for display
+	SetPersonSurname(personSurname string)
+}
@@ -0,0 +1,79 @@
+package award
+
+import (
+	"fmt"
+)
+
+// ConcreteCategory represents a category of an award ceremony and satisfies the
+// Category interface.
+type ConcreteCategory struct {
+	id         uint64
+	ceremonyID uint64
+	name       string
+}
+
+// Define the factory functions.
+
+// MakeCategory creates and returns a new uninitialised Category object
+func MakeCategory() Category {
+	var concreteCategory ConcreteCategory
+	return &concreteCategory
+}
+
+// MakeInitialisedCategory creates and returns a new Category object initialised
+// from the arguments
+func MakeInitialisedCategory(id uint64, ceremonyID uint64, name string) Category {
+	category := MakeCategory()
+	category.SetID(id)
+	category.SetCeremonyID(ceremonyID)
+	category.SetName(name)
+	return category
+}
+
+// CloneCategory creates and returns a new Category object initialised from a
+// source Category.
+func CloneCategory(source Category) Category {
+	return MakeInitialisedCategory(source.ID(), source.CeremonyID(), source.Name())
+}
+
+// Define the getters.
+
+// ID gets the id of the category.
+func (cc ConcreteCategory) ID() uint64 {
+	return cc.id
+}
+
+// CeremonyID gets the id of the ceremony.
+func (cc ConcreteCategory) CeremonyID() uint64 {
+	return cc.ceremonyID
+}
+
+// Name gets the name of the category.
+func (cc ConcreteCategory) Name() string {
+	return cc.name
+}
+
+// String gets the category as a String.
+func (cc ConcreteCategory) String() string {
+	return fmt.Sprintf("ConcreteCategory={id=%d, ceremonyID=%d, name=%s}",
+		cc.id,
+		cc.ceremonyID,
+		cc.name)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cc *ConcreteCategory) SetID(id uint64) {
+	cc.id = id
+}
+
+// SetCeremonyID sets the id of the ceremony.
+func (cc *ConcreteCategory) SetCeremonyID(ceremonyID uint64) {
+	cc.ceremonyID = ceremonyID
+}
+
+// SetName sets the name of the category.
+func (cc *ConcreteCategory) SetName(name string) {
+	cc.name = name
+}
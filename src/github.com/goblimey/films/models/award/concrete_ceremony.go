@@ -0,0 +1,79 @@
+package award
+
+import (
+	"fmt"
+)
+
+// ConcreteCeremony represents an award ceremony and satisfies the Ceremony
+// interface.
+type ConcreteCeremony struct {
+	id   uint64
+	name string
+	year int
+}
+
+// Define the factory functions.
+
+// MakeCeremony creates and returns a new uninitialised Ceremony object
+func MakeCeremony() Ceremony {
+	var concreteCeremony ConcreteCeremony
+	return &concreteCeremony
+}
+
+// MakeInitialisedCeremony creates and returns a new Ceremony object initialised
+// from the arguments
+func MakeInitialisedCeremony(id uint64, name string, year int) Ceremony {
+	ceremony := MakeCeremony()
+	ceremony.SetID(id)
+	ceremony.SetName(name)
+	ceremony.SetYear(year)
+	return ceremony
+}
+
+// Clone creates and returns a new Ceremony object initialised from a source
+// Ceremony.
+func Clone(source Ceremony) Ceremony {
+	return MakeInitialisedCeremony(source.ID(), source.Name(), source.Year())
+}
+
+// Define the getters.
+
+// ID gets the id of the ceremony.
+func (cc ConcreteCeremony) ID() uint64 {
+	return cc.id
+}
+
+// Name gets the name of the awards, for example "Academy Awards".
+func (cc ConcreteCeremony) Name() string {
+	return cc.name
+}
+
+// Year gets the year of the ceremony.
+func (cc ConcreteCeremony) Year() int {
+	return cc.year
+}
+
+// String gets the ceremony as a String.
+func (cc ConcreteCeremony) String() string {
+	return fmt.Sprintf("ConcreteCeremony={id=%d, name=%s, year=%d}",
+		cc.id,
+		cc.name,
+		cc.year)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cc *ConcreteCeremony) SetID(id uint64) {
+	cc.id = id
+}
+
+// SetName sets the name of the awards, for example "Academy Awards".
+func (cc *ConcreteCeremony) SetName(name string) {
+	cc.name = name
+}
+
+// SetYear sets the year of the ceremony.
+func (cc *ConcreteCeremony) SetYear(year int) {
+	cc.year = year
+}
@@ -0,0 +1,206 @@
+package award
+
+import (
+	"fmt"
+)
+
+// ConcreteNomination represents a nomination for an award and satisfies the
+// Nomination interface.
+type ConcreteNomination struct {
+	id              uint64
+	categoryID      uint64
+	filmID          uint64
+	personID        uint64
+	won             bool
+	categoryName    string
+	ceremonyID      uint64
+	ceremonyName    string
+	ceremonyYear    int
+	filmTitle       string
+	filmReleaseYear int
+	personForename  string
+	personSurname   string
+}
+
+// Define the factory functions.
+
+// MakeNomination creates and returns a new uninitialised Nomination object
+func MakeNomination() Nomination {
+	var concreteNomination ConcreteNomination
+	return &concreteNomination
+}
+
+// MakeInitialisedNomination creates and returns a new Nomination object
+// initialised from the arguments
+func MakeInitialisedNomination(id uint64, categoryID uint64, filmID uint64,
+	personID uint64, won bool) Nomination {
+
+	nomination := MakeNomination()
+	nomination.SetID(id)
+	nomination.SetCategoryID(categoryID)
+	nomination.SetFilmID(filmID)
+	nomination.SetPersonID(personID)
+	nomination.SetWon(won)
+	return nomination
+}
+
+// CloneNomination creates and returns a new Nomination object initialised from
+// a source Nomination, including the display fields.
+func CloneNomination(source Nomination) Nomination {
+	nomination := MakeInitialisedNomination(source.ID(), source.CategoryID(),
+		source.FilmID(), source.PersonID(), source.Won())
+	nomination.SetCategoryName(source.CategoryName())
+	nomination.SetCeremonyID(source.CeremonyID())
+	nomination.SetCeremonyName(source.CeremonyName())
+	nomination.SetCeremonyYear(source.CeremonyYear())
+	nomination.SetFilmTitle(source.FilmTitle())
+	nomination.SetFilmReleaseYear(source.FilmReleaseYear())
+	nomination.SetPersonForename(source.PersonForename())
+	nomination.SetPersonSurname(source.PersonSurname())
+	return nomination
+}
+
+// Define the getters.
+
+// ID gets the id of the nomination.
+func (cn ConcreteNomination) ID() uint64 {
+	return cn.id
+}
+
+// CategoryID gets the id of the category.
+func (cn ConcreteNomination) CategoryID() uint64 {
+	return cn.categoryID
+}
+
+// FilmID gets the id of the film.
+func (cn ConcreteNomination) FilmID() uint64 {
+	return cn.filmID
+}
+
+// PersonID gets the id of the person, or 0 for none.
+func (cn ConcreteNomination) PersonID() uint64 {
+	return cn.personID
+}
+
+// Won gets whether the nominee won the award.
+func (cn ConcreteNomination) Won() bool {
+	return cn.won
+}
+
+// CategoryName gets the name of the category, for display.
+func (cn ConcreteNomination) CategoryName() string {
+	return cn.categoryName
+}
+
+// CeremonyID gets the id of the ceremony, for display.
+func (cn ConcreteNomination) CeremonyID() uint64 {
+	return cn.ceremonyID
+}
+
+// CeremonyName gets the name of the ceremony, for display.
+func (cn ConcreteNomination) CeremonyName() string {
+	return cn.ceremonyName
+}
+
+// CeremonyYear gets the year of the ceremony, for display.
+func (cn ConcreteNomination) CeremonyYear() int {
+	return cn.ceremonyYear
+}
+
+// FilmTitle gets the title of the film, for display.
+func (cn ConcreteNomination) FilmTitle() string {
+	return cn.filmTitle
+}
+
+// FilmReleaseYear gets the release year of the film, for display.
+func (cn ConcreteNomination) FilmReleaseYear() int {
+	return cn.filmReleaseYear
+}
+
+// PersonForename gets the forename of the person, for display.
+func (cn ConcreteNomination) PersonForename() string {
+	return cn.personForename
+}
+
+// PersonSurname gets the surname of the person, for display.
+func (cn ConcreteNomination) PersonSurname() string {
+	return cn.personSurname
+}
+
+// String gets the nomination as a String.
+func (cn ConcreteNomination) String() string {
+	return fmt.Sprintf(
+		"ConcreteNomination={id=%d, categoryID=%d, filmID=%d, personID=%d, won=%v}",
+		cn.id,
+		cn.categoryID,
+		cn.filmID,
+		cn.personID,
+		cn.won)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cn *ConcreteNomination) SetID(id uint64) {
+	cn.id = id
+}
+
+// SetCategoryID sets the id of the category.
+func (cn *ConcreteNomination) SetCategoryID(categoryID uint64) {
+	cn.categoryID = categoryID
+}
+
+// SetFilmID sets the id of the film.
+func (cn *ConcreteNomination) SetFilmID(filmID uint64) {
+	cn.filmID = filmID
+}
+
+// SetPersonID sets the id of the person, or 0 for none.
+func (cn *ConcreteNomination) SetPersonID(personID uint64) {
+	cn.personID = personID
+}
+
+// SetWon sets whether the nominee won the award.
+func (cn *ConcreteNomination) SetWon(won bool) {
+	cn.won = won
+}
+
+// SetCategoryName sets the name of the category, for display.
+func (cn *ConcreteNomination) SetCategoryName(categoryName string) {
+	cn.categoryName = categoryName
+}
+
+// SetCeremonyID sets the id of the ceremony, for display.
+func (cn *ConcreteNomination) SetCeremonyID(ceremonyID uint64) {
+	cn.ceremonyID = ceremonyID
+}
+
+// SetCeremonyName sets the name of the ceremony, for display.
+func (cn *ConcreteNomination) SetCeremonyName(ceremonyName string) {
+	cn.ceremonyName = ceremonyName
+}
+
+// SetCeremonyYear sets the year of the ceremony, for display.
+func (cn *ConcreteNomination) SetCeremonyYear(ceremonyYear int) {
+	cn.ceremonyYear = ceremonyYear
+}
+
+// SetFilmTitle sets the title of the film, for display.
+func (cn *ConcreteNomination) SetFilmTitle(filmTitle string) {
+	cn.filmTitle = filmTitle
+}
+
+// SetFilmReleaseYear sets the release year of the film, for display.
+func (cn *ConcreteNomination) SetFilmReleaseYear(filmReleaseYear int) {
+	cn.filmReleaseYear = filmReleaseYear
+}
+
+// SetPersonForename sets the forename of the person, for display.
+func (cn *ConcreteNomination) SetPersonForename(personForename string) {
+	cn.personForename = personForename
+}
+
+// SetPersonSurname sets the surname of the person, for display.
+func (cn *ConcreteNomination) SetPersonSurname(personSurname string) {
+	cn.personSurname = personSurname
+}
@@ -0,0 +1,72 @@
+package award
+
+import (
+	"testing"
+)
+
+var expectedID uint64 = 2
+var expectedCeremonyName = "Academy Awards"
+var expectedCeremonyYear = 1995
+
+func TestUnitCreateConcreteCeremonyCheckFields(t *testing.T) {
+	ceremony := MakeInitialisedCeremony(expectedID, expectedCeremonyName,
+		expectedCeremonyYear)
+	if ceremony.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, ceremony.ID())
+	}
+	if ceremony.Name() != expectedCeremonyName {
+		t.Errorf("expected name to be %s actually %s", expectedCeremonyName,
+			ceremony.Name())
+	}
+	if ceremony.Year() != expectedCeremonyYear {
+		t.Errorf("expected year to be %d actually %d", expectedCeremonyYear,
+			ceremony.Year())
+	}
+}
+
+func TestUnitCloneNominationCopiesDisplayFields(t *testing.T) {
+	source := MakeInitialisedNomination(1, 3, 4, 5, true)
+	source.SetCategoryName("Best Picture")
+	source.SetCeremonyID(expectedID)
+	source.SetCeremonyName(expectedCeremonyName)
+	source.SetCeremonyYear(expectedCeremonyYear)
+	source.SetFilmTitle("Forrest Gump")
+	source.SetFilmReleaseYear(1994)
+	source.SetPersonForename("Tom")
+	source.SetPersonSurname("Hanks")
+	nomination := CloneNomination(source)
+	if nomination.PersonID() != 5 {
+		t.Errorf("expected person ID to be 5 actually %d", nomination.PersonID())
+	}
+	if !nomination.Won() {
+		t.Error("expected won to be true")
+	}
+	if nomination.CategoryName() != "Best Picture" {
+		t.Errorf("expected category name to be Best Picture actually %s",
+			nomination.CategoryName())
+	}
+	if nomination.CeremonyID() != expectedID {
+		t.Errorf("expected ceremony ID to be %d actually %d", expectedID,
+			nomination.CeremonyID())
+	}
+	if nomination.CeremonyName() != expectedCeremonyName {
+		t.Errorf("expected ceremony name to be %s actually %s",
+			expectedCeremonyName, nomination.CeremonyName())
+	}
+	if nomination.CeremonyYear() != expectedCeremonyYear {
+		t.Errorf("expected ceremony year to be %d actually %d",
+			expectedCeremonyYear, nomination.CeremonyYear())
+	}
+	if nomination.FilmTitle() != "Forrest Gump" {
+		t.Errorf("expected film title to be Forrest Gump actually %s",
+			nomination.FilmTitle())
+	}
+	if nomination.FilmReleaseYear() != 1994 {
+		t.Errorf("expected film release year to be 1994 actually %d",
+			nomination.FilmReleaseYear())
+	}
+	if nomination.PersonForename() != "Tom" || nomination.PersonSurname() != "Hanks" {
+		t.Errorf("expected person to be Tom Hanks actually %s %s",
+			nomination.PersonForename(), nomination.PersonSurname())
+	}
+}
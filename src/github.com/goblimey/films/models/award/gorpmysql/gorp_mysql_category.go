@@ -0,0 +1,86 @@
+package gorpmysql
+
+import (
+	"fmt"
+
+	awardModel "github.com/goblimey/films/models/award"
+)
+
+// The GorpMysqlCategory struct implements the award Category interface and
+// holds a single row from the AWARD_CATEGORIES table, accessed via the GORP
+// library.
+//
+// The fields must be public for GORP to work and the names must not clash with
+// those of the getters.  The column names are set up when the table is added to
+// the GORP DbMap.
+type GorpMysqlCategory struct {
+	IDField         uint64
+	CeremonyIDField uint64
+	NameField       string
+}
+
+// Factory functions
+
+// MakeCategory creates and returns a new uninitialised Category object
+func MakeCategory() awardModel.Category {
+	var gorpMysqlCategory GorpMysqlCategory
+	return &gorpMysqlCategory
+}
+
+// MakeInitialisedCategory creates and returns a new Category object initialised
+// from the arguments
+func MakeInitialisedCategory(id uint64, ceremonyID uint64,
+	name string) awardModel.Category {
+
+	category := MakeCategory()
+	category.SetID(id)
+	category.SetCeremonyID(ceremonyID)
+	category.SetName(name)
+	return category
+}
+
+// CloneCategory creates and returns a new Category object initialised from a
+// source Category.
+func CloneCategory(source awardModel.Category) awardModel.Category {
+	return MakeInitialisedCategory(source.ID(), source.CeremonyID(), source.Name())
+}
+
+// Methods to implement the Category interface.
+
+// ID gets the id of the category.
+func (c GorpMysqlCategory) ID() uint64 {
+	return c.IDField
+}
+
+// CeremonyID gets the id of the ceremony.
+func (c GorpMysqlCategory) CeremonyID() uint64 {
+	return c.CeremonyIDField
+}
+
+// Name gets the name of the category.
+func (c GorpMysqlCategory) Name() string {
+	return c.NameField
+}
+
+// String gets the category as a String.
+func (c GorpMysqlCategory) String() string {
+	return fmt.Sprintf("GorpMysqlCategory={id=%d, ceremonyID=%d, name=%s}",
+		c.IDField,
+		c.CeremonyIDField,
+		c.NameField)
+}
+
+// SetID sets the id to the given value.
+func (c *GorpMysqlCategory) SetID(id uint64) {
+	c.IDField = id
+}
+
+// SetCeremonyID sets the id of the ceremony.
+func (c *GorpMysqlCategory) SetCeremonyID(ceremonyID uint64) {
+	c.CeremonyIDField = ceremonyID
+}
+
+// SetName sets the name of the category.
+func (c *GorpMysqlCategory) SetName(name string) {
+	c.NameField = name
+}
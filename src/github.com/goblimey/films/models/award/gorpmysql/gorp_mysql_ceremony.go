@@ -0,0 +1,84 @@
+package gorpmysql
+
+import (
+	"fmt"
+
+	awardModel "github.com/goblimey/films/models/award"
+)
+
+// The GorpMysqlCeremony struct implements the award Ceremony interface and
+// holds a single row from the AWARD_CEREMONIES table, accessed via the GORP
+// library.
+//
+// The fields must be public for GORP to work and the names must not clash with
+// those of the getters.  The column names are set up when the table is added to
+// the GORP DbMap.
+type GorpMysqlCeremony struct {
+	IDField   uint64
+	NameField string
+	YearField int
+}
+
+// Factory functions
+
+// MakeCeremony creates and returns a new uninitialised Ceremony object
+func MakeCeremony() awardModel.Ceremony {
+	var gorpMysqlCeremony GorpMysqlCeremony
+	return &gorpMysqlCeremony
+}
+
+// MakeInitialisedCeremony creates and returns a new Ceremony object initialised
+// from the arguments
+func MakeInitialisedCeremony(id uint64, name string, year int) awardModel.Ceremony {
+	ceremony := MakeCeremony()
+	ceremony.SetID(id)
+	ceremony.SetName(name)
+	ceremony.SetYear(year)
+	return ceremony
+}
+
+// Clone creates and returns a new Ceremony object initialised from a source
+// Ceremony.
+func Clone(source awardModel.Ceremony) awardModel.Ceremony {
+	return MakeInitialisedCeremony(source.ID(), source.Name(), source.Year())
+}
+
+// Methods to implement the Ceremony interface.
+
+// ID gets the id of the ceremony.
+func (c GorpMysqlCeremony) ID() uint64 {
+	return c.IDField
+}
+
+// Name gets the name of the awards, for example "Academy Awards".
+func (c GorpMysqlCeremony) Name() string {
+	return c.NameField
+}
+
+// Year gets the year of the ceremony.
+func (c GorpMysqlCeremony) Year() int {
+	return c.YearField
+}
+
+// String gets the ceremony as a String.
+func (c GorpMysqlCeremony) String() string {
+	return fmt.Sprintf("GorpMysqlCeremony={id=%d, name=%s, year=%d}",
+		c.IDField,
+		c.NameField,
+		c.YearField)
+}
+
+// SetID sets the id to the given value.
+func (c *GorpMysqlCeremony) SetID(id uint64) {
+	c.IDField = id
+}
+
+// SetName sets the name of the awards, for example "Academy Awards".
+func (c *GorpMysqlCeremony) SetName(name string) {
+	c.NameField = name
+}
+
+// SetYear sets the year of the ceremony.
+func (c *GorpMysqlCeremony) SetYear(year int) {
+	c.YearField = year
+}
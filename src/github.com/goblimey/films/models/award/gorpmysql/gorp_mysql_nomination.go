@@ -0,0 +1,211 @@
+package gorpmysql
+
+import (
+	"fmt"
+
+	awardModel "github.com/goblimey/films/models/award"
+)
+
+// The GorpMysqlNomination struct implements the award Nomination interface and
+// holds a single row from the NOMINATIONS table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with
+// those of the getters.  The column names are set up when the table is added to
+// the GORP DbMap.  The display fields are transient - they are filled in by the
+// joins in the finders and are not stored in the nominations table.
+type GorpMysqlNomination struct {
+	IDField              uint64
+	CategoryIDField      uint64
+	FilmIDField          uint64
+	PersonIDField        uint64
+	WonField             bool
+	CategoryNameField    string
+	CeremonyIDField      uint64
+	CeremonyNameField    string
+	CeremonyYearField    int
+	FilmTitleField       string
+	FilmReleaseYearField int
+	PersonForenameField  string
+	PersonSurnameField   string
+}
+
+// Factory functions
+
+// MakeNomination creates and returns a new uninitialised Nomination object
+func MakeNomination() awardModel.Nomination {
+	var gorpMysqlNomination GorpMysqlNomination
+	return &gorpMysqlNomination
+}
+
+// MakeInitialisedNomination creates and returns a new Nomination object
+// initialised from the arguments
+func MakeInitialisedNomination(id uint64, categoryID uint64, filmID uint64,
+	personID uint64, won bool) awardModel.Nomination {
+
+	nomination := MakeNomination()
+	nomination.SetID(id)
+	nomination.SetCategoryID(categoryID)
+	nomination.SetFilmID(filmID)
+	nomination.SetPersonID(personID)
+	nomination.SetWon(won)
+	return nomination
+}
+
+// CloneNomination creates and returns a new Nomination object initialised from
+// a source Nomination, including the display fields.
+func CloneNomination(source awardModel.Nomination) awardModel.Nomination {
+	nomination := MakeInitialisedNomination(source.ID(), source.CategoryID(),
+		source.FilmID(), source.PersonID(), source.Won())
+	nomination.SetCategoryName(source.CategoryName())
+	nomination.SetCeremonyID(source.CeremonyID())
+	nomination.SetCeremonyName(source.CeremonyName())
+	nomination.SetCeremonyYear(source.CeremonyYear())
+	nomination.SetFilmTitle(source.FilmTitle())
+	nomination.SetFilmReleaseYear(source.FilmReleaseYear())
+	nomination.SetPersonForename(source.PersonForename())
+	nomination.SetPersonSurname(source.PersonSurname())
+	return nomination
+}
+
+// Methods to implement the Nomination interface.
+
+// ID gets the id of the nomination.
+func (n GorpMysqlNomination) ID() uint64 {
+	return n.IDField
+}
+
+// CategoryID gets the id of the category.
+func (n GorpMysqlNomination) CategoryID() uint64 {
+	return n.CategoryIDField
+}
+
+// FilmID gets the id of the film.
+func (n GorpMysqlNomination) FilmID() uint64 {
+	return n.FilmIDField
+}
+
+// PersonID gets the id of the person, or 0 for none.
+func (n GorpMysqlNomination) PersonID() uint64 {
+	return n.PersonIDField
+}
+
+// Won gets whether the nominee won the award.
+func (n GorpMysqlNomination) Won() bool {
+	return n.WonField
+}
+
+// CategoryName gets the name of the category, for display.
+func (n GorpMysqlNomination) CategoryName() string {
+	return n.CategoryNameField
+}
+
+// CeremonyID gets the id of the ceremony, for display.
+func (n GorpMysqlNomination) CeremonyID() uint64 {
+	return n.CeremonyIDField
+}
+
+// CeremonyName gets the name of the ceremony, for display.
+func (n GorpMysqlNomination) CeremonyName() string {
+	return n.CeremonyNameField
+}
+
+// CeremonyYear gets the year of the ceremony, for display.
+func (n GorpMysqlNomination) CeremonyYear() int {
+	return n.CeremonyYearField
+}
+
+// FilmTitle gets the title of the film, for display.
+func (n GorpMysqlNomination) FilmTitle() string {
+	return n.FilmTitleField
+}
+
+// FilmReleaseYear gets the release year of the film, for display.
+func (n GorpMysqlNomination) FilmReleaseYear() int {
+	return n.FilmReleaseYearField
+}
+
+// PersonForename gets the forename of the person, for display.
+func (n GorpMysqlNomination) PersonForename() string {
+	return n.PersonForenameField
+}
+
+// PersonSurname gets the surname of the person, for display.
+func (n GorpMysqlNomination) PersonSurname() string {
+	return n.PersonSurnameField
+}
+
+// String gets the nomination as a String.
+func (n GorpMysqlNomination) String() string {
+	return fmt.Sprintf(
+		"GorpMysqlNomination={id=%d, categoryID=%d, filmID=%d, personID=%d, won=%v}",
+		n.IDField,
+		n.CategoryIDField,
+		n.FilmIDField,
+		n.PersonIDField,
+		n.WonField)
+}
+
+// SetID sets the id to the given value.
+func (n *GorpMysqlNomination) SetID(id uint64) {
+	n.IDField = id
+}
+
+// SetCategoryID sets the id of the category.
+func (n *GorpMysqlNomination) SetCategoryID(categoryID uint64) {
+	n.CategoryIDField = categoryID
+}
+
+// SetFilmID sets the id of the film.
+func (n *GorpMysqlNomination) SetFilmID(filmID uint64) {
+	n.FilmIDField = filmID
+}
+
+// SetPersonID sets the id of the person, or 0 for none.
+func (n *GorpMysqlNomination) SetPersonID(personID uint64) {
+	n.PersonIDField = personID
+}
+
+// SetWon sets whether the nominee won the award.
+func (n *GorpMysqlNomination) SetWon(won bool) {
+	n.WonField = won
+}
+
+// SetCategoryName sets the name of the category, for display.
+func (n *GorpMysqlNomination) SetCategoryName(categoryName string) {
+	n.CategoryNameField = categoryName
+}
+
+// SetCeremonyID sets the id of the ceremony, for display.
+func (n *GorpMysqlNomination) SetCeremonyID(ceremonyID uint64) {
+	n.CeremonyIDField = ceremonyID
+}
+
+// SetCeremonyName sets the name of the ceremony, for display.
+func (n *GorpMysqlNomination) SetCeremonyName(ceremonyName string) {
+	n.CeremonyNameField = ceremonyName
+}
+
+// SetCeremonyYear sets the year of the ceremony, for display.
+func (n *GorpMysqlNomination) SetCeremonyYear(ceremonyYear int) {
+	n.CeremonyYearField = ceremonyYear
+}
+
+// SetFilmTitle sets the title of the film, for display.
+func (n *GorpMysqlNomination) SetFilmTitle(filmTitle string) {
+	n.FilmTitleField = filmTitle
+}
+
+// SetFilmReleaseYear sets the release year of the film, for display.
+func (n *GorpMysqlNomination) SetFilmReleaseYear(filmReleaseYear int) {
+	n.FilmReleaseYearField = filmReleaseYear
+}
+
+// SetPersonForename sets the forename of the person, for display.
+func (n *GorpMysqlNomination) SetPersonForename(personForename string) {
+	n.PersonForenameField = personForename
+}
+
+// SetPersonSurname sets the surname of the person, for display.
+func (n *GorpMysqlNomination) SetPersonSurname(personSurname string) {
+	n.PersonSurnameField = personSurname
+}
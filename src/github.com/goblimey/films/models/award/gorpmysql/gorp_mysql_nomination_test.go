@@ -0,0 +1,44 @@
+package gorpmysql
+
+import (
+	"testing"
+)
+
+func TestUnitCreateGorpMysqlNominationCheckFields(t *testing.T) {
+	nomination := MakeInitialisedNomination(1, 3, 4, 0, false)
+	if nomination.ID() != 1 {
+		t.Errorf("expected ID to be 1 actually %d", nomination.ID())
+	}
+	if nomination.CategoryID() != 3 {
+		t.Errorf("expected category ID to be 3 actually %d", nomination.CategoryID())
+	}
+	if nomination.FilmID() != 4 {
+		t.Errorf("expected film ID to be 4 actually %d", nomination.FilmID())
+	}
+	if nomination.PersonID() != 0 {
+		t.Errorf("expected person ID to be 0 actually %d", nomination.PersonID())
+	}
+	if nomination.Won() {
+		t.Error("expected won to be false")
+	}
+}
+
+func TestUnitCloneGorpMysqlNominationCopiesDisplayFields(t *testing.T) {
+	source := MakeInitialisedNomination(1, 3, 4, 5, true)
+	source.SetCategoryName("Best Actor")
+	source.SetFilmTitle("Forrest Gump")
+	source.SetPersonSurname("Hanks")
+	nomination := CloneNomination(source)
+	if nomination.CategoryName() != "Best Actor" {
+		t.Errorf("expected category name to be Best Actor actually %s",
+			nomination.CategoryName())
+	}
+	if nomination.FilmTitle() != "Forrest Gump" {
+		t.Errorf("expected film title to be Forrest Gump actually %s",
+			nomination.FilmTitle())
+	}
+	if nomination.PersonSurname() != "Hanks" {
+		t.Errorf("expected person surname to be Hanks actually %s",
+			nomination.PersonSurname())
+	}
+}
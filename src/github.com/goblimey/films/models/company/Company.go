@@ -0,0 +1,30 @@
+package company
+
+// Company represents a company that makes or distributes films.  Founded is the
+// year in which the company was founded and Defunct is the year in which it
+// ceased trading.  Either year is 0 if it's not known, and Defunct is 0 for a
+// company that's still trading.
+type Company interface {
+	// ID gets the id of the company
+	ID() uint64
+	// Name gets the name of the company
+	Name() string
+	// Country gets the country in which the company is based
+	Country() string
+	// Founded gets the year in which the company was founded
+	Founded() int
+	// Defunct gets the year in which the company ceased trading
+	Defunct() int
+	// String gets the company as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetName sets the name of the company
+	SetName(name string)
+	// SetCountry sets the country in which the company is based
+	SetCountry(country string)
+	// SetFounded sets the year in which the company was founded
+	SetFounded(year int)
+	// SetDefunct sets the year in which the company ceased trading
+	SetDefunct(year int)
+}
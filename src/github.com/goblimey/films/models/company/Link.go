@@ -0,0 +1,60 @@
+package company
+
+// The relationships that a company can have with a film.
+const (
+	RelationshipProduction   = "production"
+	RelationshipDistribution = "distribution"
+)
+
+// Relationships lists the valid relationships in the order in which they are
+// offered to the user.
+var Relationships = []string{RelationshipProduction, RelationshipDistribution}
+
+// ValidRelationship returns true if the given relationship is one of the valid
+// relationships.
+func ValidRelationship(relationship string) bool {
+	for _, r := range Relationships {
+		if r == relationship {
+			return true
+		}
+	}
+	return false
+}
+
+// Link connects a company to a film that it made or distributed.
+//
+// A link also carries the title and release year of the film and the name of the
+// company, for display.  These are filled in by the finders and are not stored in
+// the film_companies table.
+type Link interface {
+	// ID gets the id of the link
+	ID() uint64
+	// FilmID gets the id of the film
+	FilmID() uint64
+	// CompanyID gets the id of the company
+	CompanyID() uint64
+	// Relationship gets the company's relationship with the film
+	Relationship() string
+	// FilmTitle gets the title of the film, for display
+	FilmTitle() string
+	// FilmReleaseYear gets the release year of the film, for display
+	FilmReleaseYear() int
+	// CompanyName gets the name of the company, for display
+	CompanyName() string
+	// String gets the link as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetFilmID sets the id of the film
+	SetFilmID(filmID uint64)
+	// SetCompanyID sets the id of the company
+	SetCompanyID(companyID uint64)
+	// SetRelationship sets the company's relationship with the film
+	SetRelationship(relationship string)
+	// SetFilmTitle sets the title of the film, for display
+	SetFilmTitle(title string)
+	// SetFilmReleaseYear sets the release year of the film, for display
+	SetFilmReleaseYear(year int)
+	// SetCompanyName sets the name of the company, for display
+	SetCompanyName(name string)
+}
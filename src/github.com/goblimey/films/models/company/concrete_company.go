@@ -0,0 +1,106 @@
+package company
+
+import (
+	"fmt"
+)
+
+// ConcreteCompany represents a company and satisfies the Company interface.
+type ConcreteCompany struct {
+	id      uint64
+	name    string
+	country string
+	founded int
+	defunct int
+}
+
+// Define the factory functions.
+
+// MakeCompany creates and returns a new uninitialised Company object
+func MakeCompany() Company {
+	var concreteCompany ConcreteCompany
+	return &concreteCompany
+}
+
+// MakeInitialisedCompany creates and returns a new Company object initialised from
+// the arguments
+func MakeInitialisedCompany(id uint64, name string, country string, founded int,
+	defunct int) Company {
+
+	company := MakeCompany()
+	company.SetID(id)
+	company.SetName(name)
+	company.SetCountry(country)
+	company.SetFounded(founded)
+	company.SetDefunct(defunct)
+	return company
+}
+
+// Clone creates and returns a new Company object initialised from a source Company.
+func Clone(source Company) Company {
+	return MakeInitialisedCompany(source.ID(), source.Name(), source.Country(),
+		source.Founded(), source.Defunct())
+}
+
+// Define the getters.
+
+// ID gets the id of the company.
+func (cc ConcreteCompany) ID() uint64 {
+	return cc.id
+}
+
+// Name gets the name of the company.
+func (cc ConcreteCompany) Name() string {
+	return cc.name
+}
+
+// Country gets the country in which the company is based.
+func (cc ConcreteCompany) Country() string {
+	return cc.country
+}
+
+// Founded gets the year in which the company was founded.
+func (cc ConcreteCompany) Founded() int {
+	return cc.founded
+}
+
+// Defunct gets the year in which the company ceased trading.
+func (cc ConcreteCompany) Defunct() int {
+	return cc.defunct
+}
+
+// String gets the company as a String.
+func (cc ConcreteCompany) String() string {
+	return fmt.Sprintf("ConcreteCompany={id=%d, name=%s, country=%s, founded=%d, defunct=%d}",
+		cc.id,
+		cc.name,
+		cc.country,
+		cc.founded,
+		cc.defunct)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cc *ConcreteCompany) SetID(id uint64) {
+	cc.id = id
+}
+
+// SetName sets the name of the company.
+func (cc *ConcreteCompany) SetName(name string) {
+	cc.name = name
+}
+
+// SetCountry sets the country in which the company is based.
+func (cc *ConcreteCompany) SetCountry(country string) {
+	cc.country = country
+}
+
+// SetFounded sets the year in which the company was founded.
+func (cc *ConcreteCompany) SetFounded(year int) {
+	cc.founded = year
+}
+
+// SetDefunct sets the year in which the company ceased trading.
+func (cc *ConcreteCompany) SetDefunct(year int) {
+	cc.defunct = year
+}
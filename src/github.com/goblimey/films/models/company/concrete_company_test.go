@@ -0,0 +1,69 @@
+package company
+
+import (
+	"testing"
+)
+
+var expectedID uint64 = 2
+var expectedName = "Ealing Studios"
+var expectedCountry = "UK"
+var expectedFounded = 1902
+var expectedDefunct = 1959
+
+func TestUnitCreateConcreteCompanyCheckFields(t *testing.T) {
+	company := MakeInitialisedCompany(expectedID, expectedName, expectedCountry,
+		expectedFounded, expectedDefunct)
+	if company.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, company.ID())
+	}
+	if company.Name() != expectedName {
+		t.Errorf("expected name to be %s actually %s", expectedName, company.Name())
+	}
+	if company.Country() != expectedCountry {
+		t.Errorf("expected country to be %s actually %s", expectedCountry,
+			company.Country())
+	}
+	if company.Founded() != expectedFounded {
+		t.Errorf("expected founded to be %d actually %d", expectedFounded,
+			company.Founded())
+	}
+	if company.Defunct() != expectedDefunct {
+		t.Errorf("expected defunct to be %d actually %d", expectedDefunct,
+			company.Defunct())
+	}
+}
+
+func TestUnitCloneLinkCopiesDisplayFields(t *testing.T) {
+	source := MakeInitialisedLink(1, 3, expectedID, RelationshipProduction)
+	source.SetFilmTitle("Kind Hearts and Coronets")
+	source.SetFilmReleaseYear(1949)
+	source.SetCompanyName(expectedName)
+	link := CloneLink(source)
+	if link.Relationship() != RelationshipProduction {
+		t.Errorf("expected relationship to be %s actually %s", RelationshipProduction,
+			link.Relationship())
+	}
+	if link.FilmTitle() != "Kind Hearts and Coronets" {
+		t.Errorf("expected film title to be Kind Hearts and Coronets actually %s",
+			link.FilmTitle())
+	}
+	if link.FilmReleaseYear() != 1949 {
+		t.Errorf("expected film release year to be 1949 actually %d",
+			link.FilmReleaseYear())
+	}
+	if link.CompanyName() != expectedName {
+		t.Errorf("expected company name to be %s actually %s", expectedName,
+			link.CompanyName())
+	}
+}
+
+func TestUnitValidRelationship(t *testing.T) {
+	for _, relationship := range Relationships {
+		if !ValidRelationship(relationship) {
+			t.Errorf("expected %s to be a valid relationship", relationship)
+		}
+	}
+	if ValidRelationship("catering") {
+		t.Errorf("expected catering not to be a valid relationship")
+	}
+}
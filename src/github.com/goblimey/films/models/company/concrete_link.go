@@ -0,0 +1,132 @@
+package company
+
+import (
+	"fmt"
+)
+
+// ConcreteLink represents a link between a company and a film and satisfies the
+// Link interface.
+type ConcreteLink struct {
+	id              uint64
+	filmID          uint64
+	companyID       uint64
+	relationship    string
+	filmTitle       string
+	filmReleaseYear int
+	companyName     string
+}
+
+// Define the factory functions.
+
+// MakeLink creates and returns a new uninitialised Link object
+func MakeLink() Link {
+	var concreteLink ConcreteLink
+	return &concreteLink
+}
+
+// MakeInitialisedLink creates and returns a new Link object initialised from the
+// arguments
+func MakeInitialisedLink(id uint64, filmID uint64, companyID uint64,
+	relationship string) Link {
+
+	link := MakeLink()
+	link.SetID(id)
+	link.SetFilmID(filmID)
+	link.SetCompanyID(companyID)
+	link.SetRelationship(relationship)
+	return link
+}
+
+// CloneLink creates and returns a new Link object initialised from a source Link,
+// including the display fields.
+func CloneLink(source Link) Link {
+	link := MakeInitialisedLink(source.ID(), source.FilmID(), source.CompanyID(),
+		source.Relationship())
+	link.SetFilmTitle(source.FilmTitle())
+	link.SetFilmReleaseYear(source.FilmReleaseYear())
+	link.SetCompanyName(source.CompanyName())
+	return link
+}
+
+// Define the getters.
+
+// ID gets the id of the link.
+func (cl ConcreteLink) ID() uint64 {
+	return cl.id
+}
+
+// FilmID gets the id of the film.
+func (cl ConcreteLink) FilmID() uint64 {
+	return cl.filmID
+}
+
+// CompanyID gets the id of the company.
+func (cl ConcreteLink) CompanyID() uint64 {
+	return cl.companyID
+}
+
+// Relationship gets the company's relationship with the film.
+func (cl ConcreteLink) Relationship() string {
+	return cl.relationship
+}
+
+// FilmTitle gets the title of the film.
+func (cl ConcreteLink) FilmTitle() string {
+	return cl.filmTitle
+}
+
+// FilmReleaseYear gets the release year of the film.
+func (cl ConcreteLink) FilmReleaseYear() int {
+	return cl.filmReleaseYear
+}
+
+// CompanyName gets the name of the company.
+func (cl ConcreteLink) CompanyName() string {
+	return cl.companyName
+}
+
+// String gets the link as a String.
+func (cl ConcreteLink) String() string {
+	return fmt.Sprintf("ConcreteLink={id=%d, filmID=%d, companyID=%d, relationship=%s}",
+		cl.id,
+		cl.filmID,
+		cl.companyID,
+		cl.relationship)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cl *ConcreteLink) SetID(id uint64) {
+	cl.id = id
+}
+
+// SetFilmID sets the id of the film.
+func (cl *ConcreteLink) SetFilmID(filmID uint64) {
+	cl.filmID = filmID
+}
+
+// SetCompanyID sets the id of the company.
+func (cl *ConcreteLink) SetCompanyID(companyID uint64) {
+	cl.companyID = companyID
+}
+
+// SetRelationship sets the company's relationship with the film.
+func (cl *ConcreteLink) SetRelationship(relationship string) {
+	cl.relationship = relationship
+}
+
+// SetFilmTitle sets the title of the film.
+func (cl *ConcreteLink) SetFilmTitle(title string) {
+	cl.filmTitle = title
+}
+
+// SetFilmReleaseYear sets the release year of the film.
+func (cl *ConcreteLink) SetFilmReleaseYear(year int) {
+	cl.filmReleaseYear = year
+}
+
+// SetCompanyName sets the name of the company.
+func (cl *ConcreteLink) SetCompanyName(name string) {
+	cl.companyName = name
+}
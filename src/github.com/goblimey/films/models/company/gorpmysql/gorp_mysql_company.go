@@ -0,0 +1,108 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"strings"
+
+	companyModel "github.com/goblimey/films/models/company"
+)
+
+// The GorpMysqlCompany struct implements the Company interface and holds a single
+// row from the COMPANIES table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.
+type GorpMysqlCompany struct {
+	IDField      uint64
+	NameField    string
+	CountryField string
+	FoundedField int
+	DefunctField int
+}
+
+// Factory functions
+
+// MakeCompany creates and returns a new uninitialised Company object
+func MakeCompany() companyModel.Company {
+	var gorpMysqlCompany GorpMysqlCompany
+	return &gorpMysqlCompany
+}
+
+// MakeInitialisedCompany creates and returns a new Company object initialised from
+// the arguments
+func MakeInitialisedCompany(id uint64, name string, country string, founded int,
+	defunct int) companyModel.Company {
+
+	company := MakeCompany()
+	company.SetID(id)
+	company.SetName(name)
+	company.SetCountry(country)
+	company.SetFounded(founded)
+	company.SetDefunct(defunct)
+	return company
+}
+
+// Clone creates and returns a new Company object initialised from a source Company.
+func Clone(source companyModel.Company) companyModel.Company {
+	return MakeInitialisedCompany(source.ID(), source.Name(), source.Country(),
+		source.Founded(), source.Defunct())
+}
+
+// Methods to implement the Company interface.
+
+// ID gets the id of the company.
+func (c GorpMysqlCompany) ID() uint64 {
+	return c.IDField
+}
+
+// Name gets the name of the company
+func (c GorpMysqlCompany) Name() string {
+	return c.NameField
+}
+
+// Country gets the country in which the company is based
+func (c GorpMysqlCompany) Country() string {
+	return c.CountryField
+}
+
+// Founded gets the year in which the company was founded
+func (c GorpMysqlCompany) Founded() int {
+	return c.FoundedField
+}
+
+// Defunct gets the year in which the company ceased trading
+func (c GorpMysqlCompany) Defunct() int {
+	return c.DefunctField
+}
+
+// String renders the company as a string
+func (c GorpMysqlCompany) String() string {
+	return fmt.Sprintf("{%d, %s, %s, %d, %d}", c.IDField, c.NameField, c.CountryField,
+		c.FoundedField, c.DefunctField)
+}
+
+// SetID sets the company's id to the given value
+func (c *GorpMysqlCompany) SetID(id uint64) {
+	c.IDField = id
+}
+
+// SetName sets the name of the company
+func (c *GorpMysqlCompany) SetName(name string) {
+	c.NameField = strings.TrimSpace(name)
+}
+
+// SetCountry sets the country in which the company is based
+func (c *GorpMysqlCompany) SetCountry(country string) {
+	c.CountryField = strings.TrimSpace(country)
+}
+
+// SetFounded sets the year in which the company was founded
+func (c *GorpMysqlCompany) SetFounded(year int) {
+	c.FoundedField = year
+}
+
+// SetDefunct sets the year in which the company ceased trading
+func (c *GorpMysqlCompany) SetDefunct(year int) {
+	c.DefunctField = year
+}
@@ -0,0 +1,43 @@
+package gorpmysql
+
+import (
+	"testing"
+)
+
+var expectedID uint64 = 2
+var expectedName = "Ealing Studios"
+var expectedCountry = "UK"
+var expectedFounded = 1902
+
+func TestUnitCreateGorpMysqlCompanyCheckFields(t *testing.T) {
+	company := MakeInitialisedCompany(expectedID, expectedName, expectedCountry,
+		expectedFounded, 0)
+	if company.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, company.ID())
+	}
+	if company.Name() != expectedName {
+		t.Errorf("expected name to be %s actually %s", expectedName, company.Name())
+	}
+	if company.Country() != expectedCountry {
+		t.Errorf("expected country to be %s actually %s", expectedCountry,
+			company.Country())
+	}
+	if company.Founded() != expectedFounded {
+		t.Errorf("expected founded to be %d actually %d", expectedFounded,
+			company.Founded())
+	}
+	if company.Defunct() != 0 {
+		t.Errorf("expected defunct to be 0 actually %d", company.Defunct())
+	}
+}
+
+func TestUnitGorpMysqlCompanyTrimsName(t *testing.T) {
+	company := MakeInitialisedCompany(expectedID, "  "+expectedName+" ", " UK ", 0, 0)
+	if company.Name() != expectedName {
+		t.Errorf("expected name to be %s actually \"%s\"", expectedName, company.Name())
+	}
+	if company.Country() != expectedCountry {
+		t.Errorf("expected country to be %s actually \"%s\"", expectedCountry,
+			company.Country())
+	}
+}
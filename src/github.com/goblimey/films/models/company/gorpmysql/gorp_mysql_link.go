@@ -0,0 +1,135 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"strings"
+
+	companyModel "github.com/goblimey/films/models/company"
+)
+
+// The GorpMysqlLink struct implements the company Link interface and holds a
+// single row from the FILM_COMPANIES table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.  The film and company display fields are transient - they are filled in
+// by the joins in the finders and are not stored in the film_companies table.
+type GorpMysqlLink struct {
+	IDField              uint64
+	FilmIDField          uint64
+	CompanyIDField       uint64
+	RelationshipField    string
+	FilmTitleField       string
+	FilmReleaseYearField int
+	CompanyNameField     string
+}
+
+// Factory functions
+
+// MakeLink creates and returns a new uninitialised Link object
+func MakeLink() companyModel.Link {
+	var gorpMysqlLink GorpMysqlLink
+	return &gorpMysqlLink
+}
+
+// MakeInitialisedLink creates and returns a new Link object initialised from the
+// arguments
+func MakeInitialisedLink(id uint64, filmID uint64, companyID uint64,
+	relationship string) companyModel.Link {
+
+	link := MakeLink()
+	link.SetID(id)
+	link.SetFilmID(filmID)
+	link.SetCompanyID(companyID)
+	link.SetRelationship(relationship)
+	return link
+}
+
+// CloneLink creates and returns a new Link object initialised from a source Link,
+// including the display fields.
+func CloneLink(source companyModel.Link) companyModel.Link {
+	link := MakeInitialisedLink(source.ID(), source.FilmID(), source.CompanyID(),
+		source.Relationship())
+	link.SetFilmTitle(source.FilmTitle())
+	link.SetFilmReleaseYear(source.FilmReleaseYear())
+	link.SetCompanyName(source.CompanyName())
+	return link
+}
+
+// Methods to implement the Link interface.
+
+// ID gets the id of the link.
+func (l GorpMysqlLink) ID() uint64 {
+	return l.IDField
+}
+
+// FilmID gets the id of the film
+func (l GorpMysqlLink) FilmID() uint64 {
+	return l.FilmIDField
+}
+
+// CompanyID gets the id of the company
+func (l GorpMysqlLink) CompanyID() uint64 {
+	return l.CompanyIDField
+}
+
+// Relationship gets the company's relationship with the film
+func (l GorpMysqlLink) Relationship() string {
+	return l.RelationshipField
+}
+
+// FilmTitle gets the title of the film
+func (l GorpMysqlLink) FilmTitle() string {
+	return l.FilmTitleField
+}
+
+// FilmReleaseYear gets the release year of the film
+func (l GorpMysqlLink) FilmReleaseYear() int {
+	return l.FilmReleaseYearField
+}
+
+// CompanyName gets the name of the company
+func (l GorpMysqlLink) CompanyName() string {
+	return l.CompanyNameField
+}
+
+// String renders the link as a string
+func (l GorpMysqlLink) String() string {
+	return fmt.Sprintf("{%d, %d, %d, %s}", l.IDField, l.FilmIDField, l.CompanyIDField,
+		l.RelationshipField)
+}
+
+// SetID sets the link's id to the given value
+func (l *GorpMysqlLink) SetID(id uint64) {
+	l.IDField = id
+}
+
+// SetFilmID sets the id of the film
+func (l *GorpMysqlLink) SetFilmID(filmID uint64) {
+	l.FilmIDField = filmID
+}
+
+// SetCompanyID sets the id of the company
+func (l *GorpMysqlLink) SetCompanyID(companyID uint64) {
+	l.CompanyIDField = companyID
+}
+
+// SetRelationship sets the company's relationship with the film
+func (l *GorpMysqlLink) SetRelationship(relationship string) {
+	l.RelationshipField = strings.TrimSpace(relationship)
+}
+
+// SetFilmTitle sets the title of the film
+func (l *GorpMysqlLink) SetFilmTitle(title string) {
+	l.FilmTitleField = title
+}
+
+// SetFilmReleaseYear sets the release year of the film
+func (l *GorpMysqlLink) SetFilmReleaseYear(year int) {
+	l.FilmReleaseYearField = year
+}
+
+// SetCompanyName sets the name of the company
+func (l *GorpMysqlLink) SetCompanyName(name string) {
+	l.CompanyNameField = name
+}
@@ -0,0 +1,74 @@
+package credit
+
+// The roles in which a person can be credited on a film.
+const (
+	RoleActor    = "actor"
+	RoleDirector = "director"
+	RoleWriter   = "writer"
+	RoleProducer = "producer"
+)
+
+// Roles lists the valid roles in the order in which they are offered to the user.
+var Roles = []string{RoleActor, RoleDirector, RoleWriter, RoleProducer}
+
+// ValidRole returns true if the given role is one of the valid roles.
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Credit represents a person being credited on a film in a given role.  For an
+// actor, it may also have the name of the character that they played and their
+// billing order (1 for top billing, 0 if not known).
+//
+// A credit also carries the title and release year of the film and the name of
+// the person, for display.  These are filled in by the finders and are not
+// stored in the credits table.
+type Credit interface {
+	// ID gets the id of the credit
+	ID() uint64
+	// PersonID gets the id of the person credited
+	PersonID() uint64
+	// FilmID gets the id of the film
+	FilmID() uint64
+	// Role gets the role in which the person is credited
+	Role() string
+	// Character gets the name of the character played by an actor
+	Character() string
+	// Billing gets the billing order of an actor
+	Billing() int
+	// FilmTitle gets the title of the film, for display
+	FilmTitle() string
+	// FilmReleaseYear gets the release year of the film, for display
+	FilmReleaseYear() int
+	// PersonForename gets the forename of the person, for display
+	PersonForename() string
+	// PersonSurname gets the surname of the person, for display
+	PersonSurname() string
+	// String gets the credit as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetPersonID sets the id of the person credited
+	SetPersonID(personID uint64)
+	// SetFilmID sets the id of the film
+	SetFilmID(filmID uint64)
+	// SetRole sets the role in which the person is credited
+	SetRole(role string)
+	// SetCharacter sets the name of the character played by an actor
+	SetCharacter(character string)
+	// SetBilling sets the billing order of an actor
+	SetBilling(billing int)
+	// SetFilmTitle sets the title of the film, for display
+	SetFilmTitle(title string)
+	// SetFilmReleaseYear sets the release year of the film, for display
+	SetFilmReleaseYear(year int)
+	// SetPersonForename sets the forename of the person, for display
+	SetPersonForename(forename string)
+	// SetPersonSurname sets the surname of the person, for display
+	SetPersonSurname(surname string)
+}
@@ -0,0 +1,169 @@
+package credit
+
+import (
+	"fmt"
+)
+
+// ConcreteCredit represents a credit and satisfies the Credit interface.
+type ConcreteCredit struct {
+	id              uint64
+	personID        uint64
+	filmID          uint64
+	role            string
+	character       string
+	billing         int
+	filmTitle       string
+	filmReleaseYear int
+	personForename  string
+	personSurname   string
+}
+
+// Define the factory functions.
+
+// MakeCredit creates and returns a new uninitialised Credit object
+func MakeCredit() Credit {
+	var concreteCredit ConcreteCredit
+	return &concreteCredit
+}
+
+// MakeInitialisedCredit creates and returns a new Credit object initialised from
+// the arguments
+func MakeInitialisedCredit(id uint64, personID uint64, filmID uint64, role string,
+	character string, billing int) Credit {
+
+	credit := MakeCredit()
+	credit.SetID(id)
+	credit.SetPersonID(personID)
+	credit.SetFilmID(filmID)
+	credit.SetRole(role)
+	credit.SetCharacter(character)
+	credit.SetBilling(billing)
+	return credit
+}
+
+// Clone creates and returns a new Credit object initialised from a source Credit,
+// including the display fields.
+func Clone(source Credit) Credit {
+	credit := MakeInitialisedCredit(source.ID(), source.PersonID(), source.FilmID(),
+		source.Role(), source.Character(), source.Billing())
+	credit.SetFilmTitle(source.FilmTitle())
+	credit.SetFilmReleaseYear(source.FilmReleaseYear())
+	credit.SetPersonForename(source.PersonForename())
+	credit.SetPersonSurname(source.PersonSurname())
+	return credit
+}
+
+// Define the getters.
+
+// ID gets the id of the credit.
+func (cc ConcreteCredit) ID() uint64 {
+	return cc.id
+}
+
+// PersonID gets the id of the person credited.
+func (cc ConcreteCredit) PersonID() uint64 {
+	return cc.personID
+}
+
+// FilmID gets the id of the film.
+func (cc ConcreteCredit) FilmID() uint64 {
+	return cc.filmID
+}
+
+// Role gets the role in which the person is credited.
+func (cc ConcreteCredit) Role() string {
+	return cc.role
+}
+
+// Character gets the name of the character played by an actor.
+func (cc ConcreteCredit) Character() string {
+	return cc.character
+}
+
+// Billing gets the billing order of an actor.
+func (cc ConcreteCredit) Billing() int {
+	return cc.billing
+}
+
+// FilmTitle gets the title of the film.
+func (cc ConcreteCredit) FilmTitle() string {
+	return cc.filmTitle
+}
+
+// FilmReleaseYear gets the release year of the film.
+func (cc ConcreteCredit) FilmReleaseYear() int {
+	return cc.filmReleaseYear
+}
+
+// PersonForename gets the forename of the person.
+func (cc ConcreteCredit) PersonForename() string {
+	return cc.personForename
+}
+
+// PersonSurname gets the surname of the person.
+func (cc ConcreteCredit) PersonSurname() string {
+	return cc.personSurname
+}
+
+// String gets the credit as a String.
+func (cc ConcreteCredit) String() string {
+	return fmt.Sprintf("ConcreteCredit={id=%d, personID=%d, filmID=%d, role=%s, character=%s, billing=%d}",
+		cc.id,
+		cc.personID,
+		cc.filmID,
+		cc.role,
+		cc.character,
+		cc.billing)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cc *ConcreteCredit) SetID(id uint64) {
+	cc.id = id
+}
+
+// SetPersonID sets the id of the person credited.
+func (cc *ConcreteCredit) SetPersonID(personID uint64) {
+	cc.personID = personID
+}
+
+// SetFilmID sets the id of the film.
+func (cc *ConcreteCredit) SetFilmID(filmID uint64) {
+	cc.filmID = filmID
+}
+
+// SetRole sets the role in which the person is credited.
+func (cc *ConcreteCredit) SetRole(role string) {
+	cc.role = role
+}
+
+// SetCharacter sets the name of the character played by an actor.
+func (cc *ConcreteCredit) SetCharacter(character string) {
+	cc.character = character
+}
+
+// SetBilling sets the billing order of an actor.
+func (cc *ConcreteCredit) SetBilling(billing int) {
+	cc.billing = billing
+}
+
+// SetFilmTitle sets the title of the film.
+func (cc *ConcreteCredit) SetFilmTitle(title string) {
+	cc.filmTitle = title
+}
+
+// SetFilmReleaseYear sets the release year of the film.
+func (cc *ConcreteCredit) SetFilmReleaseYear(year int) {
+	cc.filmReleaseYear = year
+}
+
+// SetPersonForename sets the forename of the person.
+func (cc *ConcreteCredit) SetPersonForename(forename string) {
+	cc.personForename = forename
+}
+
+// SetPersonSurname sets the surname of the person.
+func (cc *ConcreteCredit) SetPersonSurname(surname string) {
+	cc.personSurname = surname
+}
@@ -0,0 +1,62 @@
+package credit
+
+import (
+	"testing"
+)
+
+var expectedID uint64 = 2
+var expectedPersonID uint64 = 3
+var expectedFilmID uint64 = 4
+var expectedRole = RoleActor
+var expectedCharacter = "Harry Lime"
+var expectedBilling = 2
+
+func TestUnitCreateConcreteCreditCheckFields(t *testing.T) {
+	credit := MakeInitialisedCredit(expectedID, expectedPersonID, expectedFilmID,
+		expectedRole, expectedCharacter, expectedBilling)
+	if credit.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, credit.ID())
+	}
+	if credit.PersonID() != expectedPersonID {
+		t.Errorf("expected person ID to be %d actually %d", expectedPersonID,
+			credit.PersonID())
+	}
+	if credit.FilmID() != expectedFilmID {
+		t.Errorf("expected film ID to be %d actually %d", expectedFilmID, credit.FilmID())
+	}
+	if credit.Role() != expectedRole {
+		t.Errorf("expected role to be %s actually %s", expectedRole, credit.Role())
+	}
+	if credit.Character() != expectedCharacter {
+		t.Errorf("expected character to be %s actually %s", expectedCharacter,
+			credit.Character())
+	}
+	if credit.Billing() != expectedBilling {
+		t.Errorf("expected billing to be %d actually %d", expectedBilling, credit.Billing())
+	}
+}
+
+func TestUnitCloneCreditCopiesDisplayFields(t *testing.T) {
+	source := MakeInitialisedCredit(expectedID, expectedPersonID, expectedFilmID,
+		expectedRole, expectedCharacter, expectedBilling)
+	source.SetFilmTitle("The Third Man")
+	source.SetPersonSurname("Welles")
+	credit := Clone(source)
+	if credit.FilmTitle() != "The Third Man" {
+		t.Errorf("expected film title to be The Third Man actually %s", credit.FilmTitle())
+	}
+	if credit.PersonSurname() != "Welles" {
+		t.Errorf("expected person surname to be Welles actually %s", credit.PersonSurname())
+	}
+}
+
+func TestUnitValidRole(t *testing.T) {
+	for _, role := range Roles {
+		if !ValidRole(role) {
+			t.Errorf("expected %s to be a valid role", role)
+		}
+	}
+	if ValidRole("caterer") {
+		t.Errorf("expected caterer not to be a valid role")
+	}
+}
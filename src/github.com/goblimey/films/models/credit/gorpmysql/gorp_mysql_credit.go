@@ -0,0 +1,171 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"strings"
+
+	creditModel "github.com/goblimey/films/models/credit"
+)
+
+// The GorpMysqlCredit struct implements the Credit interface and holds a single row
+// from the CREDITS table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.  The film and person display fields are transient - they are filled in by
+// the joins in the finders and are not stored in the credits table.
+type GorpMysqlCredit struct {
+	IDField              uint64
+	PersonIDField        uint64
+	FilmIDField          uint64
+	RoleField            string
+	CharacterField       string
+	BillingField         int
+	FilmTitleField       string
+	FilmReleaseYearField int
+	PersonForenameField  string
+	PersonSurnameField   string
+}
+
+// Factory functions
+
+// MakeCredit creates and returns a new uninitialised Credit object
+func MakeCredit() creditModel.Credit {
+	var gorpMysqlCredit GorpMysqlCredit
+	return &gorpMysqlCredit
+}
+
+// MakeInitialisedCredit creates and returns a new Credit object initialised from
+// the arguments
+func MakeInitialisedCredit(id uint64, personID uint64, filmID uint64, role string,
+	character string, billing int) creditModel.Credit {
+
+	credit := MakeCredit()
+	credit.SetID(id)
+	credit.SetPersonID(personID)
+	credit.SetFilmID(filmID)
+	credit.SetRole(role)
+	credit.SetCharacter(character)
+	credit.SetBilling(billing)
+	return credit
+}
+
+// Clone creates and returns a new Credit object initialised from a source Credit,
+// including the display fields.
+func Clone(source creditModel.Credit) creditModel.Credit {
+	credit := MakeInitialisedCredit(source.ID(), source.PersonID(), source.FilmID(),
+		source.Role(), source.Character(), source.Billing())
+	credit.SetFilmTitle(source.FilmTitle())
+	credit.SetFilmReleaseYear(source.FilmReleaseYear())
+	credit.SetPersonForename(source.PersonForename())
+	credit.SetPersonSurname(source.PersonSurname())
+	return credit
+}
+
+// Methods to implement the Credit interface.
+
+// ID gets the id of the credit.
+func (c GorpMysqlCredit) ID() uint64 {
+	return c.IDField
+}
+
+// PersonID gets the id of the person credited
+func (c GorpMysqlCredit) PersonID() uint64 {
+	return c.PersonIDField
+}
+
+// FilmID gets the id of the film
+func (c GorpMysqlCredit) FilmID() uint64 {
+	return c.FilmIDField
+}
+
+// Role gets the role in which the person is credited
+func (c GorpMysqlCredit) Role() string {
+	return c.RoleField
+}
+
+// Character gets the name of the character played by an actor
+func (c GorpMysqlCredit) Character() string {
+	return c.CharacterField
+}
+
+// Billing gets the billing order of an actor
+func (c GorpMysqlCredit) Billing() int {
+	return c.BillingField
+}
+
+// FilmTitle gets the title of the film
+func (c GorpMysqlCredit) FilmTitle() string {
+	return c.FilmTitleField
+}
+
+// FilmReleaseYear gets the release year of the film
+func (c GorpMysqlCredit) FilmReleaseYear() int {
+	return c.FilmReleaseYearField
+}
+
+// PersonForename gets the forename of the person
+func (c GorpMysqlCredit) PersonForename() string {
+	return c.PersonForenameField
+}
+
+// PersonSurname gets the surname of the person
+func (c GorpMysqlCredit) PersonSurname() string {
+	return c.PersonSurnameField
+}
+
+// String renders the credit as a string
+func (c GorpMysqlCredit) String() string {
+	return fmt.Sprintf("{%d, %d, %d, %s, %s, %d}", c.IDField, c.PersonIDField,
+		c.FilmIDField, c.RoleField, c.CharacterField, c.BillingField)
+}
+
+// SetID sets the credit's id to the given value
+func (c *GorpMysqlCredit) SetID(id uint64) {
+	c.IDField = id
+}
+
+// SetPersonID sets the id of the person credited
+func (c *GorpMysqlCredit) SetPersonID(personID uint64) {
+	c.PersonIDField = personID
+}
+
+// SetFilmID sets the id of the film
+func (c *GorpMysqlCredit) SetFilmID(filmID uint64) {
+	c.FilmIDField = filmID
+}
+
+// SetRole sets the role in which the person is credited
+func (c *GorpMysqlCredit) SetRole(role string) {
+	c.RoleField = strings.TrimSpace(role)
+}
+
+// SetCharacter sets the name of the character played by an actor
+func (c *GorpMysqlCredit) SetCharacter(character string) {
+	c.CharacterField = strings.TrimSpace(character)
+}
+
+// SetBilling sets the billing order of an actor
+func (c *GorpMysqlCredit) SetBilling(billing int) {
+	c.BillingField = billing
+}
+
+// SetFilmTitle sets the title of the film
+func (c *GorpMysqlCredit) SetFilmTitle(title string) {
+	c.FilmTitleField = title
+}
+
+// SetFilmReleaseYear sets the release year of the film
+func (c *GorpMysqlCredit) SetFilmReleaseYear(year int) {
+	c.FilmReleaseYearField = year
+}
+
+// SetPersonForename sets the forename of the person
+func (c *GorpMysqlCredit) SetPersonForename(forename string) {
+	c.PersonForenameField = forename
+}
+
+// SetPersonSurname sets the surname of the person
+func (c *GorpMysqlCredit) SetPersonSurname(surname string) {
+	c.PersonSurnameField = surname
+}
@@ -0,0 +1,46 @@
+package gorpmysql
+
+import (
+	"testing"
+
+	creditModel "github.com/goblimey/films/models/credit"
+)
+
+var expectedID uint64 = 2
+var expectedPersonID uint64 = 3
+var expectedFilmID uint64 = 4
+var expectedCharacter = "Harry Lime"
+var expectedBilling = 2
+
+var credit creditModel.Credit
+
+func init() {
+	credit = MakeInitialisedCredit(expectedID, expectedPersonID, expectedFilmID,
+		creditModel.RoleActor, expectedCharacter, expectedBilling)
+}
+
+func TestUnitCreateGorpMysqlCreditCheckIDs(t *testing.T) {
+	if credit.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, credit.ID())
+	}
+	if credit.PersonID() != expectedPersonID {
+		t.Errorf("expected person ID to be %d actually %d", expectedPersonID,
+			credit.PersonID())
+	}
+	if credit.FilmID() != expectedFilmID {
+		t.Errorf("expected film ID to be %d actually %d", expectedFilmID, credit.FilmID())
+	}
+}
+
+func TestUnitCreateGorpMysqlCreditCheckActorFields(t *testing.T) {
+	if credit.Role() != creditModel.RoleActor {
+		t.Errorf("expected role to be %s actually %s", creditModel.RoleActor, credit.Role())
+	}
+	if credit.Character() != expectedCharacter {
+		t.Errorf("expected character to be %s actually %s", expectedCharacter,
+			credit.Character())
+	}
+	if credit.Billing() != expectedBilling {
+		t.Errorf("expected billing to be %d actually %d", expectedBilling, credit.Billing())
+	}
+}
@@ -0,0 +1,49 @@
+package diary
+
+import (
+	"time"
+)
+
+// Entry represents a film that a user watched, in their diary.  The user can
+// give the viewing a rating and say that it was a rewatch.  Unlike a review, a
+// user can have any number of entries for the same film.
+//
+// An entry also carries the title and release year of the film, for display.
+// These are filled in by the finders and are not stored in the diary table.
+type Entry interface {
+	// ID gets the id of the entry
+	ID() uint64
+	// UserID gets the id of the user whose diary it is
+	UserID() uint64
+	// FilmID gets the id of the film
+	FilmID() uint64
+	// WatchedOn gets the day on which the user watched the film
+	WatchedOn() time.Time
+	// Rating gets the rating of this viewing, from review.MinRating to
+	// review.MaxRating, or 0 if the user didn't rate it
+	Rating() int
+	// Rewatch is true if the user had seen the film before
+	Rewatch() bool
+	// FilmTitle gets the title of the film, for display
+	FilmTitle() string
+	// FilmReleaseYear gets the release year of the film, for display
+	FilmReleaseYear() int
+	// String gets the entry as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetUserID sets the id of the user whose diary it is
+	SetUserID(userID uint64)
+	// SetFilmID sets the id of the film
+	SetFilmID(filmID uint64)
+	// SetWatchedOn sets the day on which the user watched the film
+	SetWatchedOn(watchedOn time.Time)
+	// SetRating sets the rating of this viewing - 0 for none
+	SetRating(rating int)
+	// SetRewatch sets whether the user had seen the film before
+	SetRewatch(rewatch bool)
+	// SetFilmTitle sets the title of the film, for display
+	SetFilmTitle(title string)
+	// SetFilmReleaseYear sets the release year of the film, for display
+	SetFilmReleaseYear(year int)
+}
@@ -0,0 +1,146 @@
+package diary
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConcreteEntry represents a diary entry and satisfies the Entry interface.
+type ConcreteEntry struct {
+	id              uint64
+	userID          uint64
+	filmID          uint64
+	watchedOn       time.Time
+	rating          int
+	rewatch         bool
+	filmTitle       string
+	filmReleaseYear int
+}
+
+// Define the factory functions.
+
+// MakeEntry creates and returns a new uninitialised Entry object
+func MakeEntry() Entry {
+	var concreteEntry ConcreteEntry
+	return &concreteEntry
+}
+
+// MakeInitialisedEntry creates and returns a new Entry object initialised from
+// the arguments
+func MakeInitialisedEntry(id uint64, userID uint64, filmID uint64, watchedOn time.Time,
+	rating int, rewatch bool) Entry {
+
+	entry := MakeEntry()
+	entry.SetID(id)
+	entry.SetUserID(userID)
+	entry.SetFilmID(filmID)
+	entry.SetWatchedOn(watchedOn)
+	entry.SetRating(rating)
+	entry.SetRewatch(rewatch)
+	return entry
+}
+
+// Clone creates and returns a new Entry object initialised from a source Entry,
+// including the display fields.
+func Clone(source Entry) Entry {
+	entry := MakeInitialisedEntry(source.ID(), source.UserID(), source.FilmID(),
+		source.WatchedOn(), source.Rating(), source.Rewatch())
+	entry.SetFilmTitle(source.FilmTitle())
+	entry.SetFilmReleaseYear(source.FilmReleaseYear())
+	return entry
+}
+
+// Define the getters.
+
+// ID gets the id of the entry.
+func (ce ConcreteEntry) ID() uint64 {
+	return ce.id
+}
+
+// UserID gets the id of the user whose diary it is.
+func (ce ConcreteEntry) UserID() uint64 {
+	return ce.userID
+}
+
+// FilmID gets the id of the film.
+func (ce ConcreteEntry) FilmID() uint64 {
+	return ce.filmID
+}
+
+// WatchedOn gets the day on which the user watched the film.
+func (ce ConcreteEntry) WatchedOn() time.Time {
+	return ce.watchedOn
+}
+
+// Rating gets the rating of this viewing, or 0 if there isn't one.
+func (ce ConcreteEntry) Rating() int {
+	return ce.rating
+}
+
+// Rewatch is true if the user had seen the film before.
+func (ce ConcreteEntry) Rewatch() bool {
+	return ce.rewatch
+}
+
+// FilmTitle gets the title of the film.
+func (ce ConcreteEntry) FilmTitle() string {
+	return ce.filmTitle
+}
+
+// FilmReleaseYear gets the release year of the film.
+func (ce ConcreteEntry) FilmReleaseYear() int {
+	return ce.filmReleaseYear
+}
+
+// String gets the entry as a String.
+func (ce ConcreteEntry) String() string {
+	return fmt.Sprintf("ConcreteEntry={id=%d, userID=%d, filmID=%d, watchedOn=%s, rating=%d, rewatch=%v}",
+		ce.id,
+		ce.userID,
+		ce.filmID,
+		ce.watchedOn.Format("2006-01-02"),
+		ce.rating,
+		ce.rewatch)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (ce *ConcreteEntry) SetID(id uint64) {
+	ce.id = id
+}
+
+// SetUserID sets the id of the user whose diary it is.
+func (ce *ConcreteEntry) SetUserID(userID uint64) {
+	ce.userID = userID
+}
+
+// SetFilmID sets the id of the film.
+func (ce *ConcreteEntry) SetFilmID(filmID uint64) {
+	ce.filmID = filmID
+}
+
+// SetWatchedOn sets the day on which the user watched the film.
+func (ce *ConcreteEntry) SetWatchedOn(watchedOn time.Time) {
+	ce.watchedOn = watchedOn
+}
+
+// SetRating sets the rating of this viewing - 0 for none.
+func (ce *ConcreteEntry) SetRating(rating int) {
+	ce.rating = rating
+}
+
+// SetRewatch sets whether the user had seen the film before.
+func (ce *ConcreteEntry) SetRewatch(rewatch bool) {
+	ce.rewatch = rewatch
+}
+
+// SetFilmTitle sets the title of the film.
+func (ce *ConcreteEntry) SetFilmTitle(title string) {
+	ce.filmTitle = title
+}
+
+// SetFilmReleaseYear sets the release year of the film.
+func (ce *ConcreteEntry) SetFilmReleaseYear(year int) {
+	ce.filmReleaseYear = year
+}
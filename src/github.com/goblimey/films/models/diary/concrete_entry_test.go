@@ -0,0 +1,25 @@
+package diary
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnitCreateConcreteEntryCheckFields(t *testing.T) {
+	watchedOn := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	source := MakeInitialisedEntry(2, 3, 4, watchedOn, 7, true)
+	source.SetFilmTitle("Rope")
+	source.SetFilmReleaseYear(1948)
+	entry := Clone(source)
+	if entry.ID() != 2 || entry.UserID() != 3 || entry.FilmID() != 4 {
+		t.Errorf("expected IDs 2, 3 and 4, got %d, %d and %d", entry.ID(),
+			entry.UserID(), entry.FilmID())
+	}
+	if !entry.WatchedOn().Equal(watchedOn) || entry.Rating() != 7 || !entry.Rewatch() {
+		t.Errorf("expected the viewing to be copied, got %s", entry.String())
+	}
+	if entry.FilmTitle() != "Rope" || entry.FilmReleaseYear() != 1948 {
+		t.Errorf("expected the film to be copied, got %q (%d)", entry.FilmTitle(),
+			entry.FilmReleaseYear())
+	}
+}
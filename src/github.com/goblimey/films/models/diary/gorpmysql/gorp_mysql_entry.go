@@ -0,0 +1,150 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"time"
+
+	diaryModel "github.com/goblimey/films/models/diary"
+)
+
+// The GorpMysqlEntry struct implements the diary Entry interface and holds a
+// single row from the DIARY table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.  The film display fields are transient - they are filled in by the joins
+// in the finders and are not stored in the diary table.
+type GorpMysqlEntry struct {
+	IDField              uint64
+	UserIDField          uint64
+	FilmIDField          uint64
+	WatchedOnField       int64
+	RatingField          int
+	RewatchField         bool
+	FilmTitleField       string
+	FilmReleaseYearField int
+}
+
+// Factory functions
+
+// MakeEntry creates and returns a new uninitialised Entry object
+func MakeEntry() diaryModel.Entry {
+	var gorpMysqlEntry GorpMysqlEntry
+	return &gorpMysqlEntry
+}
+
+// MakeInitialisedEntry creates and returns a new Entry object initialised from
+// the arguments
+func MakeInitialisedEntry(id uint64, userID uint64, filmID uint64, watchedOn time.Time,
+	rating int, rewatch bool) diaryModel.Entry {
+
+	entry := MakeEntry()
+	entry.SetID(id)
+	entry.SetUserID(userID)
+	entry.SetFilmID(filmID)
+	entry.SetWatchedOn(watchedOn)
+	entry.SetRating(rating)
+	entry.SetRewatch(rewatch)
+	return entry
+}
+
+// Clone creates and returns a new Entry object initialised from a source Entry,
+// including the display fields.
+func Clone(source diaryModel.Entry) diaryModel.Entry {
+	entry := MakeInitialisedEntry(source.ID(), source.UserID(), source.FilmID(),
+		source.WatchedOn(), source.Rating(), source.Rewatch())
+	entry.SetFilmTitle(source.FilmTitle())
+	entry.SetFilmReleaseYear(source.FilmReleaseYear())
+	return entry
+}
+
+// Methods to implement the Entry interface.
+
+// ID gets the id of the entry.
+func (e GorpMysqlEntry) ID() uint64 {
+	return e.IDField
+}
+
+// UserID gets the id of the user whose diary it is
+func (e GorpMysqlEntry) UserID() uint64 {
+	return e.UserIDField
+}
+
+// FilmID gets the id of the film
+func (e GorpMysqlEntry) FilmID() uint64 {
+	return e.FilmIDField
+}
+
+// WatchedOn gets the day on which the user watched the film, as midnight UTC
+func (e GorpMysqlEntry) WatchedOn() time.Time {
+	return time.Unix(e.WatchedOnField, 0).UTC()
+}
+
+// Rating gets the rating of this viewing, or 0 if there isn't one
+func (e GorpMysqlEntry) Rating() int {
+	return e.RatingField
+}
+
+// Rewatch is true if the user had seen the film before
+func (e GorpMysqlEntry) Rewatch() bool {
+	return e.RewatchField
+}
+
+// FilmTitle gets the title of the film
+func (e GorpMysqlEntry) FilmTitle() string {
+	return e.FilmTitleField
+}
+
+// FilmReleaseYear gets the release year of the film
+func (e GorpMysqlEntry) FilmReleaseYear() int {
+	return e.FilmReleaseYearField
+}
+
+// String renders the entry as a string
+func (e GorpMysqlEntry) String() string {
+	return fmt.Sprintf("{%d, %d, %d, %s, %d, %v}", e.IDField, e.UserIDField,
+		e.FilmIDField, e.WatchedOn().Format("2006-01-02"), e.RatingField, e.RewatchField)
+}
+
+// SetID sets the entry's id to the given value
+func (e *GorpMysqlEntry) SetID(id uint64) {
+	e.IDField = id
+}
+
+// SetUserID sets the id of the user whose diary it is
+func (e *GorpMysqlEntry) SetUserID(userID uint64) {
+	e.UserIDField = userID
+}
+
+// SetFilmID sets the id of the film
+func (e *GorpMysqlEntry) SetFilmID(filmID uint64) {
+	e.FilmIDField = filmID
+}
+
+// SetWatchedOn sets the day on which the user watched the film.  Only the day is
+// stored, as midnight UTC on that day, so that the diary sorts and exports the
+// same way wherever the server is.
+func (e *GorpMysqlEntry) SetWatchedOn(watchedOn time.Time) {
+	year, month, day := watchedOn.Date()
+	e.WatchedOnField = time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()
+}
+
+// SetRating sets the rating of this viewing - 0 for none
+func (e *GorpMysqlEntry) SetRating(rating int) {
+	e.RatingField = rating
+}
+
+// SetRewatch sets whether the user had seen the film before
+func (e *GorpMysqlEntry) SetRewatch(rewatch bool) {
+	e.RewatchField = rewatch
+}
+
+// SetFilmTitle sets the title of the film
+func (e *GorpMysqlEntry) SetFilmTitle(title string) {
+	e.FilmTitleField = title
+}
+
+// SetFilmReleaseYear sets the release year of the film
+func (e *GorpMysqlEntry) SetFilmReleaseYear(year int) {
+	e.FilmReleaseYearField = year
+}
@@ -0,0 +1,19 @@
+package gorpmysql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnitGorpMysqlEntryStoresTheDay(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	entry := MakeInitialisedEntry(1, 2, 3, time.Date(2024, time.March, 1, 23, 30, 0, 0, paris),
+		0, false)
+	expected := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	if !entry.WatchedOn().Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, entry.WatchedOn())
+	}
+}
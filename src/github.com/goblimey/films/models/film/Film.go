@@ -0,0 +1,28 @@
+package film
+
+// Film represents a film.  It has an ID, a title, a release year, a running
+// time in minutes and a synopsis.
+type Film interface {
+	// ID gets the id of the film
+	ID() uint64
+	// Title gets the title of the film
+	Title() string
+	// ReleaseYear gets the year in which the film was released
+	ReleaseYear() int
+	// Runtime gets the running time of the film in minutes
+	Runtime() int
+	// Synopsis gets the synopsis of the film
+	Synopsis() string
+	// String gets the film as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetTitle sets the title of the film
+	SetTitle(title string)
+	// SetReleaseYear sets the year in which the film was released
+	SetReleaseYear(year int)
+	// SetRuntime sets the running time of the film in minutes
+	SetRuntime(runtime int)
+	// SetSynopsis sets the synopsis of the film
+	SetSynopsis(synopsis string)
+}
@@ -0,0 +1,105 @@
+package film
+
+import (
+	"fmt"
+)
+
+// ConcreteFilm represents a film and satisfies the Film interface.
+type ConcreteFilm struct {
+	id          uint64
+	title       string
+	releaseYear int
+	runtime     int
+	synopsis    string
+}
+
+// Define the factory functions.
+
+// MakeFilm creates and returns a new uninitialised Film object
+func MakeFilm() Film {
+	var concreteFilm ConcreteFilm
+	return &concreteFilm
+}
+
+// MakeInitialisedFilm creates and returns a new Film object initialised from
+// the arguments
+func MakeInitialisedFilm(id uint64, title string, releaseYear int, runtime int,
+	synopsis string) Film {
+
+	film := MakeFilm()
+	film.SetID(id)
+	film.SetTitle(title)
+	film.SetReleaseYear(releaseYear)
+	film.SetRuntime(runtime)
+	film.SetSynopsis(synopsis)
+	return film
+}
+
+// Clone creates and returns a new Film object initialised from a source Film.
+func Clone(source Film) Film {
+	return MakeInitialisedFilm(source.ID(), source.Title(), source.ReleaseYear(),
+		source.Runtime(), source.Synopsis())
+}
+
+// Define the getters.
+
+// ID gets the id of the film.
+func (cf ConcreteFilm) ID() uint64 {
+	return cf.id
+}
+
+// Title gets the title of the film.
+func (cf ConcreteFilm) Title() string {
+	return cf.title
+}
+
+// ReleaseYear gets the year in which the film was released.
+func (cf ConcreteFilm) ReleaseYear() int {
+	return cf.releaseYear
+}
+
+// Runtime gets the running time of the film in minutes.
+func (cf ConcreteFilm) Runtime() int {
+	return cf.runtime
+}
+
+// Synopsis gets the synopsis of the film.
+func (cf ConcreteFilm) Synopsis() string {
+	return cf.synopsis
+}
+
+// String gets the film as a String.
+func (cf ConcreteFilm) String() string {
+	return fmt.Sprintf("ConcreteFilm={id=%d, title=%s, releaseYear=%d, runtime=%d}",
+		cf.id,
+		cf.title,
+		cf.releaseYear,
+		cf.runtime)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cf *ConcreteFilm) SetID(id uint64) {
+	cf.id = id
+}
+
+// SetTitle sets the title of the film.
+func (cf *ConcreteFilm) SetTitle(title string) {
+	cf.title = title
+}
+
+// SetReleaseYear sets the year in which the film was released.
+func (cf *ConcreteFilm) SetReleaseYear(year int) {
+	cf.releaseYear = year
+}
+
+// SetRuntime sets the running time of the film in minutes.
+func (cf *ConcreteFilm) SetRuntime(runtime int) {
+	cf.runtime = runtime
+}
+
+// SetSynopsis sets the synopsis of the film.
+func (cf *ConcreteFilm) SetSynopsis(synopsis string) {
+	cf.synopsis = synopsis
+}
@@ -0,0 +1,63 @@
+package film
+
+import (
+	"testing"
+)
+
+var expectedID uint64 = 2
+var expectedTitle = "The Third Man"
+var expectedReleaseYear = 1949
+var expectedRuntime = 104
+var expectedSynopsis = "Pulp novelist Holly Martins travels to post-war Vienna."
+
+func TestUnitCreateConcreteFilmCheckID(t *testing.T) {
+	film := MakeInitialisedFilm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+	if film.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, film.ID())
+	}
+}
+
+func TestUnitCreateFilmCheckTitle(t *testing.T) {
+	film := MakeInitialisedFilm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+	if film.Title() != expectedTitle {
+		t.Errorf("expected title to be %s actually %s", expectedTitle, film.Title())
+	}
+}
+
+func TestUnitCreateFilmCheckReleaseYear(t *testing.T) {
+	film := MakeInitialisedFilm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+	if film.ReleaseYear() != expectedReleaseYear {
+		t.Errorf("expected release year to be %d actually %d", expectedReleaseYear,
+			film.ReleaseYear())
+	}
+}
+
+func TestUnitCreateFilmCheckRuntime(t *testing.T) {
+	film := MakeInitialisedFilm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+	if film.Runtime() != expectedRuntime {
+		t.Errorf("expected runtime to be %d actually %d", expectedRuntime, film.Runtime())
+	}
+}
+
+func TestUnitCreateFilmCheckSynopsis(t *testing.T) {
+	film := MakeInitialisedFilm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+	if film.Synopsis() != expectedSynopsis {
+		t.Errorf("expected synopsis to be %s actually %s", expectedSynopsis, film.Synopsis())
+	}
+}
+
+func TestUnitCloneFilm(t *testing.T) {
+	source := MakeInitialisedFilm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+	film := Clone(source)
+	source.SetTitle("changed")
+	if film.Title() != expectedTitle {
+		t.Errorf("expected the clone to be independent of the source, title is %s",
+			film.Title())
+	}
+}
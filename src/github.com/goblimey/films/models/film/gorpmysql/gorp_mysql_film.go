@@ -0,0 +1,108 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"strings"
+
+	filmModel "github.com/goblimey/films/models/film"
+)
+
+// The GorpMysqlFilm struct implements the Film interface and holds a single row from
+// the FILMS table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.
+type GorpMysqlFilm struct {
+	IDField          uint64
+	TitleField       string
+	ReleaseYearField int
+	RuntimeField     int
+	SynopsisField    string
+}
+
+// Factory functions
+
+// MakeFilm creates and returns a new uninitialised Film object
+func MakeFilm() filmModel.Film {
+	var gorpMysqlFilm GorpMysqlFilm
+	return &gorpMysqlFilm
+}
+
+// MakeInitialisedFilm creates and returns a new Film object initialised from
+// the arguments
+func MakeInitialisedFilm(id uint64, title string, releaseYear int, runtime int,
+	synopsis string) filmModel.Film {
+
+	film := MakeFilm()
+	film.SetID(id)
+	film.SetTitle(title)
+	film.SetReleaseYear(releaseYear)
+	film.SetRuntime(runtime)
+	film.SetSynopsis(synopsis)
+	return film
+}
+
+// Clone creates and returns a new Film object initialised from a source Film.
+func Clone(source filmModel.Film) filmModel.Film {
+	return MakeInitialisedFilm(source.ID(), source.Title(), source.ReleaseYear(),
+		source.Runtime(), source.Synopsis())
+}
+
+// Methods to implement the Film interface.
+
+// ID gets the id of the film.
+func (f GorpMysqlFilm) ID() uint64 {
+	return f.IDField
+}
+
+// Title gets the title of the film
+func (f GorpMysqlFilm) Title() string {
+	return f.TitleField
+}
+
+// ReleaseYear gets the year in which the film was released
+func (f GorpMysqlFilm) ReleaseYear() int {
+	return f.ReleaseYearField
+}
+
+// Runtime gets the running time of the film in minutes
+func (f GorpMysqlFilm) Runtime() int {
+	return f.RuntimeField
+}
+
+// Synopsis gets the synopsis of the film
+func (f GorpMysqlFilm) Synopsis() string {
+	return f.SynopsisField
+}
+
+// String renders the film as a string
+func (f GorpMysqlFilm) String() string {
+	return fmt.Sprintf("{%d, %s, %d, %d}", f.IDField, f.TitleField,
+		f.ReleaseYearField, f.RuntimeField)
+}
+
+// SetID sets the film's id to the given value
+func (f *GorpMysqlFilm) SetID(id uint64) {
+	f.IDField = id
+}
+
+// SetTitle sets the film's title to the given value
+func (f *GorpMysqlFilm) SetTitle(title string) {
+	f.TitleField = strings.TrimSpace(title)
+}
+
+// SetReleaseYear sets the film's release year to the given value
+func (f *GorpMysqlFilm) SetReleaseYear(year int) {
+	f.ReleaseYearField = year
+}
+
+// SetRuntime sets the film's running time in minutes to the given value
+func (f *GorpMysqlFilm) SetRuntime(runtime int) {
+	f.RuntimeField = runtime
+}
+
+// SetSynopsis sets the film's synopsis to the given value
+func (f *GorpMysqlFilm) SetSynopsis(synopsis string) {
+	f.SynopsisField = strings.TrimSpace(synopsis)
+}
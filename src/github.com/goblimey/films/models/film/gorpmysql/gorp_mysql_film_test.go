@@ -0,0 +1,59 @@
+package gorpmysql
+
+import (
+	"testing"
+
+	filmModel "github.com/goblimey/films/models/film"
+)
+
+var expectedID uint64 = 2
+var expectedTitle = "The Third Man"
+var expectedReleaseYear = 1949
+var expectedRuntime = 104
+var expectedSynopsis = "Pulp novelist Holly Martins travels to post-war Vienna."
+
+var film filmModel.Film
+
+func init() {
+	film = MakeInitialisedFilm(expectedID, expectedTitle, expectedReleaseYear,
+		expectedRuntime, expectedSynopsis)
+}
+
+func TestUnitCreateGorpMysqlFilmCheckID(t *testing.T) {
+	if film.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, film.ID())
+	}
+}
+
+func TestUnitCreateGorpMysqlFilmCheckTitle(t *testing.T) {
+	if film.Title() != expectedTitle {
+		t.Errorf("expected title to be %s actually %s", expectedTitle, film.Title())
+	}
+}
+
+func TestUnitCreateGorpMysqlFilmCheckReleaseYear(t *testing.T) {
+	if film.ReleaseYear() != expectedReleaseYear {
+		t.Errorf("expected release year to be %d actually %d", expectedReleaseYear,
+			film.ReleaseYear())
+	}
+}
+
+func TestUnitCreateGorpMysqlFilmCheckRuntime(t *testing.T) {
+	if film.Runtime() != expectedRuntime {
+		t.Errorf("expected runtime to be %d actually %d", expectedRuntime, film.Runtime())
+	}
+}
+
+func TestUnitCreateGorpMysqlFilmCheckSynopsis(t *testing.T) {
+	if film.Synopsis() != expectedSynopsis {
+		t.Errorf("expected synopsis to be %s actually %s", expectedSynopsis, film.Synopsis())
+	}
+}
+
+func TestUnitGorpMysqlFilmTrimsTitle(t *testing.T) {
+	f := MakeFilm()
+	f.SetTitle("  " + expectedTitle + " \t")
+	if f.Title() != expectedTitle {
+		t.Errorf("expected title to be %s actually %s", expectedTitle, f.Title())
+	}
+}
@@ -0,0 +1,78 @@
+package genre
+
+// PathSeparator separates the names of a genre and its ancestors in its path.
+const PathSeparator = " > "
+
+// Genre represents a genre in the taxonomy.  The genres form a tree - a genre
+// such as Psychological Thriller has a parent, Thriller, and a top-level genre
+// has parent ID 0.  Films and people are attached to genres by Links.
+//
+// A genre also carries its path, for example "Thriller > Psychological Thriller",
+// for display.  That's filled in by SetPaths and is not stored in the genres
+// table.
+type Genre interface {
+	// ID gets the id of the genre
+	ID() uint64
+	// Name gets the name of the genre
+	Name() string
+	// ParentID gets the id of the parent genre, 0 for a top-level genre
+	ParentID() uint64
+	// Path gets the names of the genre's ancestors and the genre, for display
+	Path() string
+	// String gets the genre as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetName sets the name of the genre
+	SetName(name string)
+	// SetParentID sets the id of the parent genre
+	SetParentID(parentID uint64)
+	// SetPath sets the path of the genre, for display
+	SetPath(path string)
+}
+
+// Descendants returns the ID of the genre with the given ID and the IDs of all
+// of the genres below it in the tree, so that a search for Thriller finds the
+// psychological thrillers too.  The result is empty if the genre is not in the
+// list.
+func Descendants(genres []Genre, id uint64) []uint64 {
+	children := make(map[uint64][]uint64)
+	found := false
+	for _, genre := range genres {
+		children[genre.ParentID()] = append(children[genre.ParentID()], genre.ID())
+		found = found || genre.ID() == id
+	}
+	if !found {
+		return []uint64{}
+	}
+	result := []uint64{id}
+	seen := map[uint64]bool{id: true}
+	for i := 0; i < len(result); i++ {
+		for _, child := range children[result[i]] {
+			// Guard against a loop in a hand-edited table.
+			if !seen[child] {
+				seen[child] = true
+				result = append(result, child)
+			}
+		}
+	}
+	return result
+}
+
+// SetPaths fills in the path of each of the given genres.  A genre whose parent
+// is not in the list is treated as a top-level genre.
+func SetPaths(genres []Genre) {
+	byID := make(map[uint64]Genre)
+	for _, genre := range genres {
+		byID[genre.ID()] = genre
+	}
+	for _, genre := range genres {
+		path := genre.Name()
+		seen := map[uint64]bool{genre.ID(): true}
+		for parent, ok := byID[genre.ParentID()]; ok && !seen[parent.ID()]; parent, ok = byID[parent.ParentID()] {
+			seen[parent.ID()] = true
+			path = parent.Name() + PathSeparator + path
+		}
+		genre.SetPath(path)
+	}
+}
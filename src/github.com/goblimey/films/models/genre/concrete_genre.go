@@ -0,0 +1,91 @@
+package genre
+
+import (
+	"fmt"
+)
+
+// ConcreteGenre represents a genre and satisfies the Genre interface.
+type ConcreteGenre struct {
+	id       uint64
+	name     string
+	parentID uint64
+	path     string
+}
+
+// Define the factory functions.
+
+// MakeGenre creates and returns a new uninitialised Genre object
+func MakeGenre() Genre {
+	var concreteGenre ConcreteGenre
+	return &concreteGenre
+}
+
+// MakeInitialisedGenre creates and returns a new Genre object initialised from
+// the arguments
+func MakeInitialisedGenre(id uint64, name string, parentID uint64) Genre {
+	genre := MakeGenre()
+	genre.SetID(id)
+	genre.SetName(name)
+	genre.SetParentID(parentID)
+	return genre
+}
+
+// Clone creates and returns a new Genre object initialised from a source Genre,
+// including the path.
+func Clone(source Genre) Genre {
+	genre := MakeInitialisedGenre(source.ID(), source.Name(), source.ParentID())
+	genre.SetPath(source.Path())
+	return genre
+}
+
+// Define the getters.
+
+// ID gets the id of the genre.
+func (cg ConcreteGenre) ID() uint64 {
+	return cg.id
+}
+
+// Name gets the name of the genre.
+func (cg ConcreteGenre) Name() string {
+	return cg.name
+}
+
+// ParentID gets the id of the parent genre.
+func (cg ConcreteGenre) ParentID() uint64 {
+	return cg.parentID
+}
+
+// Path gets the path of the genre.
+func (cg ConcreteGenre) Path() string {
+	return cg.path
+}
+
+// String gets the genre as a String.
+func (cg ConcreteGenre) String() string {
+	return fmt.Sprintf("ConcreteGenre={id=%d, name=%s, parentID=%d}",
+		cg.id,
+		cg.name,
+		cg.parentID)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cg *ConcreteGenre) SetID(id uint64) {
+	cg.id = id
+}
+
+// SetName sets the name of the genre.
+func (cg *ConcreteGenre) SetName(name string) {
+	cg.name = name
+}
+
+// SetParentID sets the id of the parent genre.
+func (cg *ConcreteGenre) SetParentID(parentID uint64) {
+	cg.parentID = parentID
+}
+
+// SetPath sets the path of the genre.
+func (cg *ConcreteGenre) SetPath(path string) {
+	cg.path = path
+}
@@ -0,0 +1,80 @@
+package genre
+
+import (
+	"reflect"
+	"testing"
+)
+
+var expectedID uint64 = 2
+var expectedName = "Psychological Thriller"
+var expectedParentID uint64 = 1
+
+func TestUnitCreateConcreteGenreCheckFields(t *testing.T) {
+	genre := MakeInitialisedGenre(expectedID, expectedName, expectedParentID)
+	if genre.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, genre.ID())
+	}
+	if genre.Name() != expectedName {
+		t.Errorf("expected name to be %s actually %s", expectedName, genre.Name())
+	}
+	if genre.ParentID() != expectedParentID {
+		t.Errorf("expected parent ID to be %d actually %d", expectedParentID,
+			genre.ParentID())
+	}
+}
+
+func TestUnitCreateConcreteLinkCheckFields(t *testing.T) {
+	link := MakeInitialisedLink(3, expectedID, "films", 4)
+	if link.ID() != 3 || link.GenreID() != expectedID || link.Subject() != "films" ||
+		link.SubjectID() != 4 {
+		t.Errorf("unexpected link %s", link.String())
+	}
+}
+
+// makeTree makes Thriller > Psychological Thriller > Gaslight and Comedy.
+func makeTree() []Genre {
+	return []Genre{
+		MakeInitialisedGenre(1, "Thriller", 0),
+		MakeInitialisedGenre(2, "Psychological Thriller", 1),
+		MakeInitialisedGenre(3, "Comedy", 0),
+		MakeInitialisedGenre(4, "Gaslight", 2),
+	}
+}
+
+func TestUnitDescendants(t *testing.T) {
+	genres := makeTree()
+	var tests = []struct {
+		id       uint64
+		expected []uint64
+	}{
+		{1, []uint64{1, 2, 4}},
+		{2, []uint64{2, 4}},
+		{3, []uint64{3}},
+		{5, []uint64{}},
+	}
+	for _, test := range tests {
+		actual := Descendants(genres, test.id)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("genre %d: expected %v actually %v", test.id, test.expected, actual)
+		}
+	}
+}
+
+func TestUnitSetPaths(t *testing.T) {
+	genres := makeTree()
+	SetPaths(genres)
+	expected := []string{
+		"Thriller",
+		"Thriller > Psychological Thriller",
+		"Comedy",
+		"Thriller > Psychological Thriller > Gaslight",
+	}
+	for i, genre := range genres {
+		if genre.Path() != expected[i] {
+			t.Errorf("expected path to be %s actually %s", expected[i], genre.Path())
+		}
+	}
+	if Clone(genres[3]).Path() != expected[3] {
+		t.Errorf("expected the clone to have the path")
+	}
+}
@@ -0,0 +1,92 @@
+package genre
+
+import (
+	"fmt"
+)
+
+// ConcreteLink represents a link between a genre and a film or person and
+// satisfies the Link interface.
+type ConcreteLink struct {
+	id        uint64
+	genreID   uint64
+	subject   string
+	subjectID uint64
+}
+
+// Define the factory functions.
+
+// MakeLink creates and returns a new uninitialised Link object
+func MakeLink() Link {
+	var concreteLink ConcreteLink
+	return &concreteLink
+}
+
+// MakeInitialisedLink creates and returns a new Link object initialised from the
+// arguments
+func MakeInitialisedLink(id uint64, genreID uint64, subject string, subjectID uint64) Link {
+	link := MakeLink()
+	link.SetID(id)
+	link.SetGenreID(genreID)
+	link.SetSubject(subject)
+	link.SetSubjectID(subjectID)
+	return link
+}
+
+// CloneLink creates and returns a new Link object initialised from a source Link.
+func CloneLink(source Link) Link {
+	return MakeInitialisedLink(source.ID(), source.GenreID(), source.Subject(),
+		source.SubjectID())
+}
+
+// Define the getters.
+
+// ID gets the id of the link.
+func (cl ConcreteLink) ID() uint64 {
+	return cl.id
+}
+
+// GenreID gets the id of the genre.
+func (cl ConcreteLink) GenreID() uint64 {
+	return cl.genreID
+}
+
+// Subject gets the name of the table holding the film or person.
+func (cl ConcreteLink) Subject() string {
+	return cl.subject
+}
+
+// SubjectID gets the id of the film or person.
+func (cl ConcreteLink) SubjectID() uint64 {
+	return cl.subjectID
+}
+
+// String gets the link as a String.
+func (cl ConcreteLink) String() string {
+	return fmt.Sprintf("ConcreteLink={id=%d, genreID=%d, subject=%s, subjectID=%d}",
+		cl.id,
+		cl.genreID,
+		cl.subject,
+		cl.subjectID)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cl *ConcreteLink) SetID(id uint64) {
+	cl.id = id
+}
+
+// SetGenreID sets the id of the genre.
+func (cl *ConcreteLink) SetGenreID(genreID uint64) {
+	cl.genreID = genreID
+}
+
+// SetSubject sets the name of the table holding the film or person.
+func (cl *ConcreteLink) SetSubject(subject string) {
+	cl.subject = subject
+}
+
+// SetSubjectID sets the id of the film or person.
+func (cl *ConcreteLink) SetSubjectID(subjectID uint64) {
+	cl.subjectID = subjectID
+}
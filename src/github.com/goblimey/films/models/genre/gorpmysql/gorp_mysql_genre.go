@@ -0,0 +1,95 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"strings"
+
+	genreModel "github.com/goblimey/films/models/genre"
+)
+
+// The GorpMysqlGenre struct implements the Genre interface and holds a single row
+// from the GENRES table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.  The path is transient - it's filled in by genre.SetPaths and is not stored
+// in the genres table.
+type GorpMysqlGenre struct {
+	IDField       uint64
+	NameField     string
+	ParentIDField uint64
+	PathField     string
+}
+
+// Factory functions
+
+// MakeGenre creates and returns a new uninitialised Genre object
+func MakeGenre() genreModel.Genre {
+	var gorpMysqlGenre GorpMysqlGenre
+	return &gorpMysqlGenre
+}
+
+// MakeInitialisedGenre creates and returns a new Genre object initialised from
+// the arguments
+func MakeInitialisedGenre(id uint64, name string, parentID uint64) genreModel.Genre {
+	genre := MakeGenre()
+	genre.SetID(id)
+	genre.SetName(name)
+	genre.SetParentID(parentID)
+	return genre
+}
+
+// Clone creates and returns a new Genre object initialised from a source Genre,
+// including the path.
+func Clone(source genreModel.Genre) genreModel.Genre {
+	genre := MakeInitialisedGenre(source.ID(), source.Name(), source.ParentID())
+	genre.SetPath(source.Path())
+	return genre
+}
+
+// Methods to implement the Genre interface.
+
+// ID gets the id of the genre.
+func (g GorpMysqlGenre) ID() uint64 {
+	return g.IDField
+}
+
+// Name gets the name of the genre
+func (g GorpMysqlGenre) Name() string {
+	return g.NameField
+}
+
+// ParentID gets the id of the parent genre
+func (g GorpMysqlGenre) ParentID() uint64 {
+	return g.ParentIDField
+}
+
+// Path gets the path of the genre
+func (g GorpMysqlGenre) Path() string {
+	return g.PathField
+}
+
+// String renders the genre as a string
+func (g GorpMysqlGenre) String() string {
+	return fmt.Sprintf("{%d, %s, %d}", g.IDField, g.NameField, g.ParentIDField)
+}
+
+// SetID sets the genre's id to the given value
+func (g *GorpMysqlGenre) SetID(id uint64) {
+	g.IDField = id
+}
+
+// SetName sets the name of the genre
+func (g *GorpMysqlGenre) SetName(name string) {
+	g.NameField = strings.TrimSpace(name)
+}
+
+// SetParentID sets the id of the parent genre
+func (g *GorpMysqlGenre) SetParentID(parentID uint64) {
+	g.ParentIDField = parentID
+}
+
+// SetPath sets the path of the genre
+func (g *GorpMysqlGenre) SetPath(path string) {
+	g.PathField = path
+}
@@ -0,0 +1,31 @@
+package gorpmysql
+
+import (
+	"testing"
+)
+
+var expectedID uint64 = 2
+var expectedName = "Psychological Thriller"
+var expectedParentID uint64 = 1
+
+func TestUnitCreateGorpMysqlGenreCheckFields(t *testing.T) {
+	genre := MakeInitialisedGenre(expectedID, " "+expectedName+" ", expectedParentID)
+	if genre.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, genre.ID())
+	}
+	if genre.Name() != expectedName {
+		t.Errorf("expected name to be %s actually %s", expectedName, genre.Name())
+	}
+	if genre.ParentID() != expectedParentID {
+		t.Errorf("expected parent ID to be %d actually %d", expectedParentID,
+			genre.ParentID())
+	}
+}
+
+func TestUnitCreateGorpMysqlLinkCheckFields(t *testing.T) {
+	link := CloneLink(MakeInitialisedLink(3, expectedID, "people", 4))
+	if link.ID() != 3 || link.GenreID() != expectedID || link.Subject() != "people" ||
+		link.SubjectID() != 4 {
+		t.Errorf("unexpected link %s", link.String())
+	}
+}
@@ -0,0 +1,95 @@
+package gorpmysql
+
+import (
+	"fmt"
+
+	genreModel "github.com/goblimey/films/models/genre"
+)
+
+// The GorpMysqlLink struct implements the genre Link interface and holds a single
+// row from the GENRE_LINKS table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.
+type GorpMysqlLink struct {
+	IDField        uint64
+	GenreIDField   uint64
+	SubjectField   string
+	SubjectIDField uint64
+}
+
+// Factory functions
+
+// MakeLink creates and returns a new uninitialised Link object
+func MakeLink() genreModel.Link {
+	var gorpMysqlLink GorpMysqlLink
+	return &gorpMysqlLink
+}
+
+// MakeInitialisedLink creates and returns a new Link object initialised from the
+// arguments
+func MakeInitialisedLink(id uint64, genreID uint64, subject string,
+	subjectID uint64) genreModel.Link {
+
+	link := MakeLink()
+	link.SetID(id)
+	link.SetGenreID(genreID)
+	link.SetSubject(subject)
+	link.SetSubjectID(subjectID)
+	return link
+}
+
+// CloneLink creates and returns a new Link object initialised from a source Link.
+func CloneLink(source genreModel.Link) genreModel.Link {
+	return MakeInitialisedLink(source.ID(), source.GenreID(), source.Subject(),
+		source.SubjectID())
+}
+
+// Methods to implement the Link interface.
+
+// ID gets the id of the link.
+func (l GorpMysqlLink) ID() uint64 {
+	return l.IDField
+}
+
+// GenreID gets the id of the genre
+func (l GorpMysqlLink) GenreID() uint64 {
+	return l.GenreIDField
+}
+
+// Subject gets the name of the table holding the film or person
+func (l GorpMysqlLink) Subject() string {
+	return l.SubjectField
+}
+
+// SubjectID gets the id of the film or person
+func (l GorpMysqlLink) SubjectID() uint64 {
+	return l.SubjectIDField
+}
+
+// String renders the link as a string
+func (l GorpMysqlLink) String() string {
+	return fmt.Sprintf("{%d, %d, %s, %d}", l.IDField, l.GenreIDField, l.SubjectField,
+		l.SubjectIDField)
+}
+
+// SetID sets the link's id to the given value
+func (l *GorpMysqlLink) SetID(id uint64) {
+	l.IDField = id
+}
+
+// SetGenreID sets the id of the genre
+func (l *GorpMysqlLink) SetGenreID(genreID uint64) {
+	l.GenreIDField = genreID
+}
+
+// SetSubject sets the name of the table holding the film or person
+func (l *GorpMysqlLink) SetSubject(subject string) {
+	l.SubjectField = subject
+}
+
+// SetSubjectID sets the id of the film or person
+func (l *GorpMysqlLink) SetSubjectID(subjectID uint64) {
+	l.SubjectIDField = subjectID
+}
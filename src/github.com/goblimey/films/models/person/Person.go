@@ -1,6 +1,14 @@
 package person
 
-// Person represents a person.  It has an ID, a forename and a surname.
+import (
+	"time"
+)
+
+// Person represents a person.  It has an ID, a forename and a surname.  The version
+// counts the updates to the stored record and is used to detect an update that
+// would overwrite someone else's changes.  A deleted person is kept in the trash
+// until they are restored or purged - DeletedAt gives the time of the delete, or
+// the zero time if the person has not been deleted.
 type Person interface { 
 	// ID() gets the id of the person
 	ID() uint64	
@@ -8,6 +16,10 @@ type Person interface {
 	Forename() string 
 	// Surname gets the surname of the person
 	Surname() string
+	// Version gets the version of the stored record that the person was read from
+	Version() int64
+	// DeletedAt gets the time that the person was moved to the trash
+	DeletedAt() time.Time
 	// String gets the person as a String
 	String() string
 	// SetID sets the id to the given value
@@ -16,4 +28,8 @@ type Person interface {
 	SetForename(forename string)
 	// SetSurname sets the surname of the person
 	SetSurname(surname string)
+	// SetVersion sets the version
+	SetVersion(version int64)
+	// SetDeletedAt sets the time that the person was moved to the trash
+	SetDeletedAt(deletedAt time.Time)
 }
\ No newline at end of file
@@ -2,13 +2,16 @@ package person
 
 import (
 	"fmt"
+	"time"
 )
 
 // ConcretePerson represents a person and satisfies the Person interface.
 type ConcretePerson struct {
-	id       uint64
-	forename string
-	surname  string
+	id        uint64
+	forename  string
+	surname   string
+	version   int64
+	deletedAt time.Time
 }
 
 // Define the factory functions.
@@ -31,7 +34,10 @@ func MakeInitialisedPerson(id uint64, forename string, surname string) Person {
 
 // Clone creates and returns a new Person object initialised from a source Person.
 func Clone(source Person) Person {
-	return MakeInitialisedPerson(source.ID(), source.Forename(), source.Surname())
+	person := MakeInitialisedPerson(source.ID(), source.Forename(), source.Surname())
+	person.SetVersion(source.Version())
+	person.SetDeletedAt(source.DeletedAt())
+	return person
 }
 
 // Define the getters.
@@ -51,6 +57,17 @@ func (cp ConcretePerson) Surname() string {
 	return cp.surname
 }
 
+// Version gets the version of the stored record.
+func (cp ConcretePerson) Version() int64 {
+	return cp.version
+}
+
+// DeletedAt gets the time that the person was moved to the trash, or the zero
+// time if they have not been deleted.
+func (cp ConcretePerson) DeletedAt() time.Time {
+	return cp.deletedAt
+}
+
 // String gets the person as a String.
 func (cp ConcretePerson) String() string {
 	return fmt.Sprintf("ConcretePerson={id=%d, forename=%s,surname=%s}",
@@ -75,3 +92,13 @@ func (cp *ConcretePerson) SetForename(forename string) {
 func (cp *ConcretePerson) SetSurname(surname string) {
 	cp.surname = surname
 }
+
+// SetVersion sets the version of the stored record.
+func (cp *ConcretePerson) SetVersion(version int64) {
+	cp.version = version
+}
+
+// SetDeletedAt sets the time that the person was moved to the trash.
+func (cp *ConcretePerson) SetDeletedAt(deletedAt time.Time) {
+	cp.deletedAt = deletedAt
+}
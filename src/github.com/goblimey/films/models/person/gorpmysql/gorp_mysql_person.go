@@ -3,6 +3,7 @@ package gorpmysql
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	personModel "github.com/goblimey/films/models/person"
 )
@@ -10,11 +11,14 @@ import (
 // The GorpMysqlPerson struct implements the Person interface and holds a single row from
 // the PEOPLE table, accessed via the GORP library.
 //
-// The fields must be public for GORP to work and the names must not clash with those of the getters
+// The fields must be public for GORP to work and the names must not clash with those of the getters.
+// The time of a delete is stored as seconds since the Unix epoch, 0 meaning not deleted.
 type GorpMysqlPerson struct {
-	IDField       uint64 `db: "id, primarykey, autoincrement"`
-	ForenameField string `db: "forename"`
-	SurnameField  string `db: "surname"`
+	IDField        uint64 `db: "id, primarykey, autoincrement"`
+	ForenameField  string `db: "forename"`
+	SurnameField   string `db: "surname"`
+	VersionField   int64  `db: "version"`
+	DeletedAtField int64  `db: "deleted_at"`
 }
 
 // Factory functions
@@ -37,7 +41,10 @@ func MakeInitialisedPerson(id uint64, forename string, surname string) personMod
 
 // Clone creates and returns a new Person object initialised from a source Person.
 func Clone(source personModel.Person) personModel.Person {
-	return MakeInitialisedPerson(source.ID(), source.Forename(), source.Surname())
+	person := MakeInitialisedPerson(source.ID(), source.Forename(), source.Surname())
+	person.SetVersion(source.Version())
+	person.SetDeletedAt(source.DeletedAt())
+	return person
 }
 
 // Methods to implement the Person interface.
@@ -57,6 +64,20 @@ func (p GorpMysqlPerson) Surname() string {
 	return p.SurnameField
 }
 
+// Version gets the version of the row, which GORP increments on each update
+func (p GorpMysqlPerson) Version() int64 {
+	return p.VersionField
+}
+
+// DeletedAt gets the time that the person was moved to the trash, in UTC, or the
+// zero time if they have not been deleted
+func (p GorpMysqlPerson) DeletedAt() time.Time {
+	if p.DeletedAtField == 0 {
+		return time.Time{}
+	}
+	return time.Unix(p.DeletedAtField, 0).UTC()
+}
+
 // String renders the person as a string
 func (p GorpMysqlPerson) String() string {
 	return fmt.Sprintf("{%d, %s, %s}", p.IDField, p.ForenameField, p.SurnameField)
@@ -76,3 +97,18 @@ func (p *GorpMysqlPerson) SetForename(forename string) {
 func (p *GorpMysqlPerson) SetSurname(surname string) {
 	p.SurnameField = strings.TrimSpace(surname)
 }
+
+// SetVersion sets the version of the row to the given value
+func (p *GorpMysqlPerson) SetVersion(version int64) {
+	p.VersionField = version
+}
+
+// SetDeletedAt sets the time that the person was moved to the trash.  It's stored to
+// the nearest second.  The zero time means not deleted.
+func (p *GorpMysqlPerson) SetDeletedAt(deletedAt time.Time) {
+	if deletedAt.IsZero() {
+		p.DeletedAtField = 0
+		return
+	}
+	p.DeletedAtField = deletedAt.Unix()
+}
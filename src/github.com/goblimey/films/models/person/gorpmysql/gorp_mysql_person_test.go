@@ -2,6 +2,7 @@ package gorpmysql
 
 import (
 	"testing"
+	"time"
 
 	personModel "github.com/goblimey/films/models/person"
 )
@@ -33,3 +34,20 @@ func TestIntegrationCreatePersonCheckSurname(t *testing.T) {
 		t.Errorf("expected surname to be %s actually %s", expectedSurname, person.Surname())
 	}
 }
+
+func TestUnitDeletedAtIsStoredToTheSecond(t *testing.T) {
+	person := MakeInitialisedPerson(expectedID, expectedForename, expectedSurname)
+	if !person.DeletedAt().IsZero() {
+		t.Errorf("expected a new person not to be deleted, actually deleted at %v", person.DeletedAt())
+	}
+	deletedAt := time.Date(2016, 3, 1, 12, 30, 15, 500, time.UTC)
+	person.SetDeletedAt(deletedAt)
+	clone := Clone(person)
+	if !clone.DeletedAt().Equal(deletedAt.Truncate(time.Second)) {
+		t.Errorf("expected deleted at %v actually %v", deletedAt, clone.DeletedAt())
+	}
+	person.SetDeletedAt(time.Time{})
+	if !person.DeletedAt().IsZero() {
+		t.Errorf("expected the person to be restored, actually deleted at %v", person.DeletedAt())
+	}
+}
@@ -0,0 +1,58 @@
+package review
+
+import (
+	"time"
+)
+
+// The range of ratings that a user can give a film.
+const (
+	MinRating = 1
+	MaxRating = 10
+)
+
+// ValidRating returns true if the given rating is between MinRating and MaxRating.
+func ValidRating(rating int) bool {
+	return rating >= MinRating && rating <= MaxRating
+}
+
+// Review represents a user's rating of a film and, optionally, what they wrote
+// about it.  Each user has at most one review of each film, which they can change.
+//
+// A review also carries the username of the reviewer, for display.  This is filled
+// in by the finders and is not stored in the reviews table.
+type Review interface {
+	// ID gets the id of the review
+	ID() uint64
+	// FilmID gets the id of the film that was reviewed
+	FilmID() uint64
+	// UserID gets the id of the user who wrote the review
+	UserID() uint64
+	// Rating gets the rating, from MinRating to MaxRating
+	Rating() int
+	// Title gets the title of the review, which may be empty
+	Title() string
+	// Body gets the text of the review, which may be empty
+	Body() string
+	// UpdatedAt gets the time at which the review was written or last changed
+	UpdatedAt() time.Time
+	// Username gets the name of the user who wrote the review
+	Username() string
+	// String gets the review as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetFilmID sets the id of the film that was reviewed
+	SetFilmID(filmID uint64)
+	// SetUserID sets the id of the user who wrote the review
+	SetUserID(userID uint64)
+	// SetRating sets the rating
+	SetRating(rating int)
+	// SetTitle sets the title of the review
+	SetTitle(title string)
+	// SetBody sets the text of the review
+	SetBody(body string)
+	// SetUpdatedAt sets the time at which the review was written or last changed
+	SetUpdatedAt(updatedAt time.Time)
+	// SetUsername sets the name of the user who wrote the review
+	SetUsername(username string)
+}
@@ -0,0 +1,63 @@
+package review
+
+// Summary holds the ratings of a film, kept up to date as reviews are written,
+// changed and deleted, so that displaying them doesn't mean reading every review.
+// It holds the number of ratings of each value, from which the count and the
+// average are worked out.  The version guards against two requests changing the
+// summary at the same time.
+type Summary interface {
+	// ID gets the id of the summary
+	ID() uint64
+	// FilmID gets the id of the film whose ratings are summarised
+	FilmID() uint64
+	// Version gets the version of the summary, which goes up on each update
+	Version() int64
+	// Histogram gets the number of ratings of each value - the first element is
+	// the number of ratings of MinRating and the last the number of MaxRating.
+	Histogram() []int
+	// Count gets the number of ratings
+	Count() int
+	// Average gets the average rating, 0 if there are none
+	Average() float64
+	// Add counts a rating.  A rating outside the valid range is ignored.
+	Add(rating int)
+	// Remove takes away a rating counted earlier.  A rating outside the valid
+	// range, or one that was never counted, is ignored.
+	Remove(rating int)
+	// String gets the summary as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetFilmID sets the id of the film whose ratings are summarised
+	SetFilmID(filmID uint64)
+	// SetVersion sets the version of the summary
+	SetVersion(version int64)
+	// SetHistogram sets the number of ratings of each value.  Missing values are
+	// taken as 0 and extra ones are ignored.
+	SetHistogram(histogram []int)
+}
+
+// CountOf returns the number of ratings in the given histogram, as returned by
+// Summary.Histogram.
+func CountOf(histogram []int) int {
+	count := 0
+	for _, n := range histogram {
+		count += n
+	}
+	return count
+}
+
+// AverageOf returns the average rating in the given histogram, as returned by
+// Summary.Histogram, or 0 if it's empty.
+func AverageOf(histogram []int) float64 {
+	count := 0
+	total := 0
+	for i, n := range histogram {
+		count += n
+		total += n * (MinRating + i)
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
@@ -0,0 +1,146 @@
+package review
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConcreteReview represents a review and satisfies the Review interface.
+type ConcreteReview struct {
+	id        uint64
+	filmID    uint64
+	userID    uint64
+	rating    int
+	title     string
+	body      string
+	updatedAt time.Time
+	username  string
+}
+
+// Define the factory functions.
+
+// MakeReview creates and returns a new uninitialised Review object
+func MakeReview() Review {
+	var concreteReview ConcreteReview
+	return &concreteReview
+}
+
+// MakeInitialisedReview creates and returns a new Review object initialised from
+// the arguments
+func MakeInitialisedReview(id uint64, filmID uint64, userID uint64, rating int,
+	title string, body string) Review {
+
+	review := MakeReview()
+	review.SetID(id)
+	review.SetFilmID(filmID)
+	review.SetUserID(userID)
+	review.SetRating(rating)
+	review.SetTitle(title)
+	review.SetBody(body)
+	return review
+}
+
+// Clone creates and returns a new Review object initialised from a source Review,
+// including the time and the username.
+func Clone(source Review) Review {
+	review := MakeInitialisedReview(source.ID(), source.FilmID(), source.UserID(),
+		source.Rating(), source.Title(), source.Body())
+	review.SetUpdatedAt(source.UpdatedAt())
+	review.SetUsername(source.Username())
+	return review
+}
+
+// Define the getters.
+
+// ID gets the id of the review.
+func (cr ConcreteReview) ID() uint64 {
+	return cr.id
+}
+
+// FilmID gets the id of the film that was reviewed.
+func (cr ConcreteReview) FilmID() uint64 {
+	return cr.filmID
+}
+
+// UserID gets the id of the user who wrote the review.
+func (cr ConcreteReview) UserID() uint64 {
+	return cr.userID
+}
+
+// Rating gets the rating.
+func (cr ConcreteReview) Rating() int {
+	return cr.rating
+}
+
+// Title gets the title of the review.
+func (cr ConcreteReview) Title() string {
+	return cr.title
+}
+
+// Body gets the text of the review.
+func (cr ConcreteReview) Body() string {
+	return cr.body
+}
+
+// UpdatedAt gets the time at which the review was written or last changed.
+func (cr ConcreteReview) UpdatedAt() time.Time {
+	return cr.updatedAt
+}
+
+// Username gets the name of the user who wrote the review.
+func (cr ConcreteReview) Username() string {
+	return cr.username
+}
+
+// String gets the review as a String.  The body is left out, because it may be
+// long.
+func (cr ConcreteReview) String() string {
+	return fmt.Sprintf("ConcreteReview={id=%d, filmID=%d, userID=%d, rating=%d, title=%s}",
+		cr.id,
+		cr.filmID,
+		cr.userID,
+		cr.rating,
+		cr.title)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cr *ConcreteReview) SetID(id uint64) {
+	cr.id = id
+}
+
+// SetFilmID sets the id of the film that was reviewed.
+func (cr *ConcreteReview) SetFilmID(filmID uint64) {
+	cr.filmID = filmID
+}
+
+// SetUserID sets the id of the user who wrote the review.
+func (cr *ConcreteReview) SetUserID(userID uint64) {
+	cr.userID = userID
+}
+
+// SetRating sets the rating.
+func (cr *ConcreteReview) SetRating(rating int) {
+	cr.rating = rating
+}
+
+// SetTitle sets the title of the review.
+func (cr *ConcreteReview) SetTitle(title string) {
+	cr.title = title
+}
+
+// SetBody sets the text of the review.
+func (cr *ConcreteReview) SetBody(body string) {
+	cr.body = body
+}
+
+// SetUpdatedAt sets the time at which the review was written or last changed.
+func (cr *ConcreteReview) SetUpdatedAt(updatedAt time.Time) {
+	cr.updatedAt = updatedAt
+}
+
+// SetUsername sets the name of the user who wrote the review.
+func (cr *ConcreteReview) SetUsername(username string) {
+	cr.username = username
+}
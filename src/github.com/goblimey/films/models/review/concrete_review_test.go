@@ -0,0 +1,74 @@
+package review
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnitCreateConcreteReviewCheckFields(t *testing.T) {
+	updatedAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	source := MakeInitialisedReview(2, 3, 4, 8, "Still thrilling", "The bell tower.")
+	source.SetUpdatedAt(updatedAt)
+	source.SetUsername("vera")
+	review := Clone(source)
+	if review.ID() != 2 || review.FilmID() != 3 || review.UserID() != 4 {
+		t.Errorf("expected IDs 2, 3 and 4, got %d, %d and %d", review.ID(),
+			review.FilmID(), review.UserID())
+	}
+	if review.Rating() != 8 {
+		t.Errorf("expected rating 8, got %d", review.Rating())
+	}
+	if review.Title() != "Still thrilling" || review.Body() != "The bell tower." {
+		t.Errorf("expected title and body to be copied, got %q and %q", review.Title(),
+			review.Body())
+	}
+	if !review.UpdatedAt().Equal(updatedAt) || review.Username() != "vera" {
+		t.Errorf("expected time and username to be copied, got %v and %q",
+			review.UpdatedAt(), review.Username())
+	}
+}
+
+func TestUnitValidRating(t *testing.T) {
+	var tests = []struct {
+		rating int
+		valid  bool
+	}{
+		{0, false},
+		{1, true},
+		{10, true},
+		{11, false},
+	}
+	for _, test := range tests {
+		if ValidRating(test.rating) != test.valid {
+			t.Errorf("%d: expected %v", test.rating, test.valid)
+		}
+	}
+}
+
+func TestUnitSummaryAddAndRemove(t *testing.T) {
+	summary := MakeSummary()
+	if summary.Count() != 0 || summary.Average() != 0 {
+		t.Errorf("expected an empty summary, got %s", summary.String())
+	}
+	summary.Add(10)
+	summary.Add(7)
+	summary.Add(7)
+	summary.Add(11) // ignored
+	summary.Remove(3)
+	if summary.Count() != 3 {
+		t.Errorf("expected 3 ratings, got %d", summary.Count())
+	}
+	if summary.Average() != 8 {
+		t.Errorf("expected an average of 8, got %f", summary.Average())
+	}
+	histogram := summary.Histogram()
+	if len(histogram) != 10 || histogram[6] != 2 || histogram[9] != 1 {
+		t.Errorf("expected two 7s and a 10, got %v", histogram)
+	}
+	// Changing the returned histogram doesn't change the summary.
+	histogram[0] = 5
+	clone := CloneSummary(summary)
+	if clone.Count() != 3 {
+		t.Errorf("expected the clone to have 3 ratings, got %d", clone.Count())
+	}
+}
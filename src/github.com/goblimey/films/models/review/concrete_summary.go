@@ -0,0 +1,126 @@
+package review
+
+import (
+	"fmt"
+)
+
+// ConcreteSummary represents the ratings of a film and satisfies the Summary
+// interface.
+type ConcreteSummary struct {
+	id        uint64
+	filmID    uint64
+	version   int64
+	histogram [MaxRating - MinRating + 1]int
+}
+
+// Define the factory functions.
+
+// MakeSummary creates and returns a new Summary object with no ratings
+func MakeSummary() Summary {
+	var concreteSummary ConcreteSummary
+	return &concreteSummary
+}
+
+// MakeInitialisedSummary creates and returns a new Summary object initialised from
+// the arguments
+func MakeInitialisedSummary(id uint64, filmID uint64, version int64,
+	histogram []int) Summary {
+
+	summary := MakeSummary()
+	summary.SetID(id)
+	summary.SetFilmID(filmID)
+	summary.SetVersion(version)
+	summary.SetHistogram(histogram)
+	return summary
+}
+
+// CloneSummary creates and returns a new Summary object initialised from a source
+// Summary.
+func CloneSummary(source Summary) Summary {
+	return MakeInitialisedSummary(source.ID(), source.FilmID(), source.Version(),
+		source.Histogram())
+}
+
+// Define the getters.
+
+// ID gets the id of the summary.
+func (cs ConcreteSummary) ID() uint64 {
+	return cs.id
+}
+
+// FilmID gets the id of the film whose ratings are summarised.
+func (cs ConcreteSummary) FilmID() uint64 {
+	return cs.filmID
+}
+
+// Version gets the version of the summary.
+func (cs ConcreteSummary) Version() int64 {
+	return cs.version
+}
+
+// Histogram gets the number of ratings of each value.
+func (cs ConcreteSummary) Histogram() []int {
+	histogram := make([]int, len(cs.histogram))
+	copy(histogram, cs.histogram[:])
+	return histogram
+}
+
+// Count gets the number of ratings.
+func (cs ConcreteSummary) Count() int {
+	return CountOf(cs.histogram[:])
+}
+
+// Average gets the average rating.
+func (cs ConcreteSummary) Average() float64 {
+	return AverageOf(cs.histogram[:])
+}
+
+// String gets the summary as a String.
+func (cs ConcreteSummary) String() string {
+	return fmt.Sprintf("ConcreteSummary={id=%d, filmID=%d, version=%d, histogram=%v}",
+		cs.id,
+		cs.filmID,
+		cs.version,
+		cs.histogram)
+}
+
+// Define the setters.
+
+// Add counts a rating.
+func (cs *ConcreteSummary) Add(rating int) {
+	if ValidRating(rating) {
+		cs.histogram[rating-MinRating]++
+	}
+}
+
+// Remove takes away a rating.
+func (cs *ConcreteSummary) Remove(rating int) {
+	if ValidRating(rating) && cs.histogram[rating-MinRating] > 0 {
+		cs.histogram[rating-MinRating]--
+	}
+}
+
+// SetID sets the id to the given value.
+func (cs *ConcreteSummary) SetID(id uint64) {
+	cs.id = id
+}
+
+// SetFilmID sets the id of the film whose ratings are summarised.
+func (cs *ConcreteSummary) SetFilmID(filmID uint64) {
+	cs.filmID = filmID
+}
+
+// SetVersion sets the version of the summary.
+func (cs *ConcreteSummary) SetVersion(version int64) {
+	cs.version = version
+}
+
+// SetHistogram sets the number of ratings of each value.
+func (cs *ConcreteSummary) SetHistogram(histogram []int) {
+	for i := range cs.histogram {
+		cs.histogram[i] = 0
+		if i < len(histogram) {
+			cs.histogram[i] = histogram[i]
+		}
+	}
+}
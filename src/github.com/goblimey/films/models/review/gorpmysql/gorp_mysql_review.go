@@ -0,0 +1,149 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	reviewModel "github.com/goblimey/films/models/review"
+)
+
+// The GorpMysqlReview struct implements the Review interface and holds a single
+// row from the REVIEWS table, accessed via the GORP library.  The username is not
+// in the table - the finders fill it in from the users table.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.
+type GorpMysqlReview struct {
+	IDField        uint64
+	FilmIDField    uint64
+	UserIDField    uint64
+	RatingField    int
+	TitleField     string
+	BodyField      string
+	UpdatedAtField int64
+	UsernameField  string
+}
+
+// Factory functions
+
+// MakeReview creates and returns a new uninitialised Review object
+func MakeReview() reviewModel.Review {
+	var gorpMysqlReview GorpMysqlReview
+	return &gorpMysqlReview
+}
+
+// MakeInitialisedReview creates and returns a new Review object initialised from
+// the arguments
+func MakeInitialisedReview(id uint64, filmID uint64, userID uint64, rating int,
+	title string, body string) reviewModel.Review {
+
+	review := MakeReview()
+	review.SetID(id)
+	review.SetFilmID(filmID)
+	review.SetUserID(userID)
+	review.SetRating(rating)
+	review.SetTitle(title)
+	review.SetBody(body)
+	return review
+}
+
+// Clone creates and returns a new Review object initialised from a source Review,
+// including the time and the username.
+func Clone(source reviewModel.Review) reviewModel.Review {
+	review := MakeInitialisedReview(source.ID(), source.FilmID(), source.UserID(),
+		source.Rating(), source.Title(), source.Body())
+	review.SetUpdatedAt(source.UpdatedAt())
+	review.SetUsername(source.Username())
+	return review
+}
+
+// Methods to implement the Review interface.
+
+// ID gets the id of the review.
+func (r GorpMysqlReview) ID() uint64 {
+	return r.IDField
+}
+
+// FilmID gets the id of the film that was reviewed
+func (r GorpMysqlReview) FilmID() uint64 {
+	return r.FilmIDField
+}
+
+// UserID gets the id of the user who wrote the review
+func (r GorpMysqlReview) UserID() uint64 {
+	return r.UserIDField
+}
+
+// Rating gets the rating
+func (r GorpMysqlReview) Rating() int {
+	return r.RatingField
+}
+
+// Title gets the title of the review
+func (r GorpMysqlReview) Title() string {
+	return r.TitleField
+}
+
+// Body gets the text of the review
+func (r GorpMysqlReview) Body() string {
+	return r.BodyField
+}
+
+// UpdatedAt gets the time at which the review was written or last changed, in UTC
+func (r GorpMysqlReview) UpdatedAt() time.Time {
+	return time.Unix(r.UpdatedAtField, 0).UTC()
+}
+
+// Username gets the name of the user who wrote the review
+func (r GorpMysqlReview) Username() string {
+	return r.UsernameField
+}
+
+// String renders the review as a string, without the body
+func (r GorpMysqlReview) String() string {
+	return fmt.Sprintf("{%d, %d, %d, %d, %s}", r.IDField, r.FilmIDField, r.UserIDField,
+		r.RatingField, r.TitleField)
+}
+
+// SetID sets the review's id to the given value
+func (r *GorpMysqlReview) SetID(id uint64) {
+	r.IDField = id
+}
+
+// SetFilmID sets the id of the film that was reviewed
+func (r *GorpMysqlReview) SetFilmID(filmID uint64) {
+	r.FilmIDField = filmID
+}
+
+// SetUserID sets the id of the user who wrote the review
+func (r *GorpMysqlReview) SetUserID(userID uint64) {
+	r.UserIDField = userID
+}
+
+// SetRating sets the rating
+func (r *GorpMysqlReview) SetRating(rating int) {
+	r.RatingField = rating
+}
+
+// SetTitle sets the title of the review
+func (r *GorpMysqlReview) SetTitle(title string) {
+	r.TitleField = strings.TrimSpace(title)
+}
+
+// SetBody sets the text of the review
+func (r *GorpMysqlReview) SetBody(body string) {
+	r.BodyField = strings.TrimSpace(body)
+}
+
+// SetUpdatedAt sets the time at which the review was written or last changed.
+// It's stored to the nearest second.
+func (r *GorpMysqlReview) SetUpdatedAt(updatedAt time.Time) {
+	r.UpdatedAtField = updatedAt.Unix()
+}
+
+// SetUsername sets the name of the user who wrote the review
+func (r *GorpMysqlReview) SetUsername(username string) {
+	r.UsernameField = username
+}
@@ -0,0 +1,35 @@
+package gorpmysql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnitGorpMysqlReviewStoresTimeToTheSecond(t *testing.T) {
+	review := MakeInitialisedReview(1, 2, 3, 9, "  A classic ", "\nSee it.\n")
+	review.SetUpdatedAt(time.Date(2024, time.March, 1, 12, 0, 0, 500, time.UTC))
+	expected := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	if !review.UpdatedAt().Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, review.UpdatedAt())
+	}
+	if review.Title() != "A classic" || review.Body() != "See it." {
+		t.Errorf("expected the title and body to be trimmed, got %q and %q",
+			review.Title(), review.Body())
+	}
+}
+
+func TestUnitGorpMysqlSummaryHistogram(t *testing.T) {
+	summary := MakeInitialisedSummary(1, 2, 1, []int{1, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	summary.Add(10)
+	summary.Add(10)
+	summary.Remove(1)
+	summary.Remove(1) // nothing left to remove
+	gorpSummary := summary.(*GorpMysqlSummary)
+	if gorpSummary.Rating1Field != 0 || gorpSummary.Rating10Field != 2 {
+		t.Errorf("expected no 1s and two 10s, got %s", summary.String())
+	}
+	if summary.Count() != 2 || summary.Average() != 10 {
+		t.Errorf("expected 2 ratings averaging 10, got %d averaging %f",
+			summary.Count(), summary.Average())
+	}
+}
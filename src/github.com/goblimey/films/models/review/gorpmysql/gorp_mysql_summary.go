@@ -0,0 +1,151 @@
+package gorpmysql
+
+import (
+	"fmt"
+
+	reviewModel "github.com/goblimey/films/models/review"
+)
+
+// The GorpMysqlSummary struct implements the Summary interface and holds a single
+// row from the FILM_RATINGS table, accessed via the GORP library.  The table has a
+// column for the number of ratings of each value, rating_1 to rating_10.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.
+type GorpMysqlSummary struct {
+	IDField       uint64
+	FilmIDField   uint64
+	VersionField  int64
+	Rating1Field  int
+	Rating2Field  int
+	Rating3Field  int
+	Rating4Field  int
+	Rating5Field  int
+	Rating6Field  int
+	Rating7Field  int
+	Rating8Field  int
+	Rating9Field  int
+	Rating10Field int
+}
+
+// Factory functions
+
+// MakeSummary creates and returns a new Summary object with no ratings
+func MakeSummary() reviewModel.Summary {
+	var gorpMysqlSummary GorpMysqlSummary
+	return &gorpMysqlSummary
+}
+
+// MakeInitialisedSummary creates and returns a new Summary object initialised from
+// the arguments
+func MakeInitialisedSummary(id uint64, filmID uint64, version int64,
+	histogram []int) reviewModel.Summary {
+
+	summary := MakeSummary()
+	summary.SetID(id)
+	summary.SetFilmID(filmID)
+	summary.SetVersion(version)
+	summary.SetHistogram(histogram)
+	return summary
+}
+
+// CloneSummary creates and returns a new Summary object initialised from a source
+// Summary.
+func CloneSummary(source reviewModel.Summary) reviewModel.Summary {
+	return MakeInitialisedSummary(source.ID(), source.FilmID(), source.Version(),
+		source.Histogram())
+}
+
+// Methods to implement the Summary interface.
+
+// ID gets the id of the summary.
+func (s GorpMysqlSummary) ID() uint64 {
+	return s.IDField
+}
+
+// FilmID gets the id of the film whose ratings are summarised
+func (s GorpMysqlSummary) FilmID() uint64 {
+	return s.FilmIDField
+}
+
+// Version gets the version of the summary
+func (s GorpMysqlSummary) Version() int64 {
+	return s.VersionField
+}
+
+// Histogram gets the number of ratings of each value
+func (s GorpMysqlSummary) Histogram() []int {
+	counts := s.counts()
+	histogram := make([]int, len(counts))
+	for i, count := range counts {
+		histogram[i] = *count
+	}
+	return histogram
+}
+
+// Count gets the number of ratings
+func (s GorpMysqlSummary) Count() int {
+	return reviewModel.CountOf(s.Histogram())
+}
+
+// Average gets the average rating
+func (s GorpMysqlSummary) Average() float64 {
+	return reviewModel.AverageOf(s.Histogram())
+}
+
+// String renders the summary as a string
+func (s GorpMysqlSummary) String() string {
+	return fmt.Sprintf("{%d, %d, %d, %v}", s.IDField, s.FilmIDField, s.VersionField,
+		s.Histogram())
+}
+
+// Add counts a rating
+func (s *GorpMysqlSummary) Add(rating int) {
+	if reviewModel.ValidRating(rating) {
+		*s.counts()[rating-reviewModel.MinRating]++
+	}
+}
+
+// Remove takes away a rating
+func (s *GorpMysqlSummary) Remove(rating int) {
+	if reviewModel.ValidRating(rating) {
+		count := s.counts()[rating-reviewModel.MinRating]
+		if *count > 0 {
+			*count--
+		}
+	}
+}
+
+// SetID sets the summary's id to the given value
+func (s *GorpMysqlSummary) SetID(id uint64) {
+	s.IDField = id
+}
+
+// SetFilmID sets the id of the film whose ratings are summarised
+func (s *GorpMysqlSummary) SetFilmID(filmID uint64) {
+	s.FilmIDField = filmID
+}
+
+// SetVersion sets the version of the summary
+func (s *GorpMysqlSummary) SetVersion(version int64) {
+	s.VersionField = version
+}
+
+// SetHistogram sets the number of ratings of each value
+func (s *GorpMysqlSummary) SetHistogram(histogram []int) {
+	for i, count := range s.counts() {
+		*count = 0
+		if i < len(histogram) {
+			*count = histogram[i]
+		}
+	}
+}
+
+// counts returns pointers to the fields holding the number of ratings of each
+// value, in order of rating.
+func (s *GorpMysqlSummary) counts() []*int {
+	return []*int{&s.Rating1Field, &s.Rating2Field, &s.Rating3Field, &s.Rating4Field,
+		&s.Rating5Field, &s.Rating6Field, &s.Rating7Field, &s.Rating8Field,
+		&s.Rating9Field, &s.Rating10Field}
+}
@@ -0,0 +1,25 @@
+package tag
+
+// Link attaches a tag to a film or a person.  The subject is the name of the
+// table that holds the film or person, "films" or "people", and the subject ID is
+// its ID in that table.
+type Link interface {
+	// ID gets the id of the link
+	ID() uint64
+	// TagID gets the id of the tag
+	TagID() uint64
+	// Subject gets the name of the table holding the film or person
+	Subject() string
+	// SubjectID gets the id of the film or person
+	SubjectID() uint64
+	// String gets the link as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetTagID sets the id of the tag
+	SetTagID(tagID uint64)
+	// SetSubject sets the name of the table holding the film or person
+	SetSubject(subject string)
+	// SetSubjectID sets the id of the film or person
+	SetSubjectID(subjectID uint64)
+}
@@ -0,0 +1,38 @@
+package tag
+
+import (
+	"strings"
+)
+
+// MaxNameLength is the longest tag name that the tags table can hold.
+const MaxNameLength = 64
+
+// Tag represents a free-form tag, such as "film noir" or "oscar winner".  Tags
+// are shared - everything tagged with the same name gets the same tag.  Films and
+// people are attached to tags by Links.
+//
+// A tag also carries the number of things tagged with it, for display.  That's
+// filled in by the finders and is not stored in the tags table.
+type Tag interface {
+	// ID gets the id of the tag
+	ID() uint64
+	// Name gets the name of the tag
+	Name() string
+	// Count gets the number of films and people with the tag, for display
+	Count() int
+	// String gets the tag as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetName sets the name of the tag
+	SetName(name string)
+	// SetCount sets the number of films and people with the tag, for display
+	SetCount(count int)
+}
+
+// Normalise returns the name that a tag typed in by a user is stored under - in
+// lower case, with the spaces around it trimmed and the runs of spaces inside it
+// reduced to one, so that "Film  Noir" and "film noir" are the same tag.
+func Normalise(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,76 @@
+package tag
+
+import (
+	"fmt"
+)
+
+// ConcreteTag represents a tag and satisfies the Tag interface.
+type ConcreteTag struct {
+	id    uint64
+	name  string
+	count int
+}
+
+// Define the factory functions.
+
+// MakeTag creates and returns a new uninitialised Tag object
+func MakeTag() Tag {
+	var concreteTag ConcreteTag
+	return &concreteTag
+}
+
+// MakeInitialisedTag creates and returns a new Tag object initialised from the
+// arguments
+func MakeInitialisedTag(id uint64, name string) Tag {
+	tag := MakeTag()
+	tag.SetID(id)
+	tag.SetName(name)
+	return tag
+}
+
+// Clone creates and returns a new Tag object initialised from a source Tag,
+// including the count.
+func Clone(source Tag) Tag {
+	tag := MakeInitialisedTag(source.ID(), source.Name())
+	tag.SetCount(source.Count())
+	return tag
+}
+
+// Define the getters.
+
+// ID gets the id of the tag.
+func (ct ConcreteTag) ID() uint64 {
+	return ct.id
+}
+
+// Name gets the name of the tag.
+func (ct ConcreteTag) Name() string {
+	return ct.name
+}
+
+// Count gets the number of films and people with the tag.
+func (ct ConcreteTag) Count() int {
+	return ct.count
+}
+
+// String gets the tag as a String.
+func (ct ConcreteTag) String() string {
+	return fmt.Sprintf("ConcreteTag={id=%d, name=%s}", ct.id, ct.name)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (ct *ConcreteTag) SetID(id uint64) {
+	ct.id = id
+}
+
+// SetName sets the name of the tag.
+func (ct *ConcreteTag) SetName(name string) {
+	ct.name = name
+}
+
+// SetCount sets the number of films and people with the tag.
+func (ct *ConcreteTag) SetCount(count int) {
+	ct.count = count
+}
@@ -0,0 +1,49 @@
+package tag
+
+import (
+	"testing"
+)
+
+var expectedID uint64 = 2
+var expectedName = "film noir"
+
+func TestUnitCreateConcreteTagCheckFields(t *testing.T) {
+	tag := MakeInitialisedTag(expectedID, expectedName)
+	tag.SetCount(3)
+	tag = Clone(tag)
+	if tag.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, tag.ID())
+	}
+	if tag.Name() != expectedName {
+		t.Errorf("expected name to be %s actually %s", expectedName, tag.Name())
+	}
+	if tag.Count() != 3 {
+		t.Errorf("expected count to be 3 actually %d", tag.Count())
+	}
+}
+
+func TestUnitCreateConcreteLinkCheckFields(t *testing.T) {
+	link := MakeInitialisedLink(3, expectedID, "films", 4)
+	if link.ID() != 3 || link.TagID() != expectedID || link.Subject() != "films" ||
+		link.SubjectID() != 4 {
+		t.Errorf("unexpected link %s", link.String())
+	}
+}
+
+func TestUnitNormalise(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+	}{
+		{"film noir", "film noir"},
+		{"  Film   Noir ", "film noir"},
+		{"\tPre-Code\n", "pre-code"},
+		{"   ", ""},
+	}
+	for _, test := range tests {
+		if Normalise(test.name) != test.expected {
+			t.Errorf("%q: expected %q actually %q", test.name, test.expected,
+				Normalise(test.name))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package gorpmysql
+
+import (
+	"fmt"
+
+	tagModel "github.com/goblimey/films/models/tag"
+)
+
+// The GorpMysqlTag struct implements the Tag interface and holds a single row from
+// the TAGS table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.  The count is transient - it's filled in by the finders and is not stored
+// in the tags table.
+type GorpMysqlTag struct {
+	IDField    uint64
+	NameField  string
+	CountField int
+}
+
+// Factory functions
+
+// MakeTag creates and returns a new uninitialised Tag object
+func MakeTag() tagModel.Tag {
+	var gorpMysqlTag GorpMysqlTag
+	return &gorpMysqlTag
+}
+
+// MakeInitialisedTag creates and returns a new Tag object initialised from the
+// arguments
+func MakeInitialisedTag(id uint64, name string) tagModel.Tag {
+	tag := MakeTag()
+	tag.SetID(id)
+	tag.SetName(name)
+	return tag
+}
+
+// Clone creates and returns a new Tag object initialised from a source Tag,
+// including the count.
+func Clone(source tagModel.Tag) tagModel.Tag {
+	tag := MakeInitialisedTag(source.ID(), source.Name())
+	tag.SetCount(source.Count())
+	return tag
+}
+
+// Methods to implement the Tag interface.
+
+// ID gets the id of the tag.
+func (t GorpMysqlTag) ID() uint64 {
+	return t.IDField
+}
+
+// Name gets the name of the tag
+func (t GorpMysqlTag) Name() string {
+	return t.NameField
+}
+
+// Count gets the number of films and people with the tag
+func (t GorpMysqlTag) Count() int {
+	return t.CountField
+}
+
+// String renders the tag as a string
+func (t GorpMysqlTag) String() string {
+	return fmt.Sprintf("{%d, %s}", t.IDField, t.NameField)
+}
+
+// SetID sets the tag's id to the given value
+func (t *GorpMysqlTag) SetID(id uint64) {
+	t.IDField = id
+}
+
+// SetName sets the name of the tag.  The name is normalised, so that the same tag
+// typed in different ways is stored once.
+func (t *GorpMysqlTag) SetName(name string) {
+	t.NameField = tagModel.Normalise(name)
+}
+
+// SetCount sets the number of films and people with the tag
+func (t *GorpMysqlTag) SetCount(count int) {
+	t.CountField = count
+}
@@ -0,0 +1,25 @@
+package gorpmysql
+
+import (
+	"testing"
+)
+
+var expectedID uint64 = 2
+
+func TestUnitCreateGorpMysqlTagNormalisesName(t *testing.T) {
+	tag := MakeInitialisedTag(expectedID, " Film  Noir ")
+	if tag.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, tag.ID())
+	}
+	if tag.Name() != "film noir" {
+		t.Errorf("expected name to be film noir actually %s", tag.Name())
+	}
+}
+
+func TestUnitCreateGorpMysqlLinkCheckFields(t *testing.T) {
+	link := CloneLink(MakeInitialisedLink(3, expectedID, "people", 4))
+	if link.ID() != 3 || link.TagID() != expectedID || link.Subject() != "people" ||
+		link.SubjectID() != 4 {
+		t.Errorf("unexpected link %s", link.String())
+	}
+}
@@ -0,0 +1,46 @@
+package user
+
+// The roles that a user can have.  A viewer can only look at the data, an editor
+// can also create and change it and an admin can also delete it.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// Roles lists the roles, from the least powerful to the most.
+var Roles = []string{RoleViewer, RoleEditor, RoleAdmin}
+
+// ValidRole returns true if the given string is one of the roles.
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// User represents someone who can log in to the server.  It has an ID, a
+// username, a bcrypt hash of the password and a role, which says what the user
+// can do.  The password itself is never stored.
+type User interface {
+	// ID gets the id of the user
+	ID() uint64
+	// Username gets the name that the user logs in with
+	Username() string
+	// PasswordHash gets the bcrypt hash of the user's password
+	PasswordHash() string
+	// Role gets the user's role
+	Role() string
+	// String gets the user as a String, without the password hash
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetUsername sets the name that the user logs in with
+	SetUsername(username string)
+	// SetPasswordHash sets the bcrypt hash of the user's password
+	SetPasswordHash(hash string)
+	// SetRole sets the user's role
+	SetRole(role string)
+}
@@ -0,0 +1,88 @@
+package user
+
+import (
+	"fmt"
+)
+
+// ConcreteUser represents a user and satisfies the User interface.
+type ConcreteUser struct {
+	id           uint64
+	username     string
+	passwordHash string
+	role         string
+}
+
+// Define the factory functions.
+
+// MakeUser creates and returns a new uninitialised User object
+func MakeUser() User {
+	var concreteUser ConcreteUser
+	return &concreteUser
+}
+
+// MakeInitialisedUser creates and returns a new User object initialised from
+// the arguments
+func MakeInitialisedUser(id uint64, username string, passwordHash string, role string) User {
+	user := MakeUser()
+	user.SetID(id)
+	user.SetUsername(username)
+	user.SetPasswordHash(passwordHash)
+	user.SetRole(role)
+	return user
+}
+
+// Clone creates and returns a new User object initialised from a source User.
+func Clone(source User) User {
+	return MakeInitialisedUser(source.ID(), source.Username(), source.PasswordHash(), source.Role())
+}
+
+// Define the getters.
+
+// ID gets the id of the user.
+func (cu ConcreteUser) ID() uint64 {
+	return cu.id
+}
+
+// Username gets the name that the user logs in with.
+func (cu ConcreteUser) Username() string {
+	return cu.username
+}
+
+// PasswordHash gets the bcrypt hash of the user's password.
+func (cu ConcreteUser) PasswordHash() string {
+	return cu.passwordHash
+}
+
+// Role gets the user's role.
+func (cu ConcreteUser) Role() string {
+	return cu.role
+}
+
+// String gets the user as a String.  The password hash is left out so that it
+// doesn't end up in the log.
+func (cu ConcreteUser) String() string {
+	return fmt.Sprintf("ConcreteUser={id=%d, username=%s, role=%s}", cu.id, cu.username,
+		cu.role)
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (cu *ConcreteUser) SetID(id uint64) {
+	cu.id = id
+}
+
+// SetUsername sets the name that the user logs in with.
+func (cu *ConcreteUser) SetUsername(username string) {
+	cu.username = username
+}
+
+// SetPasswordHash sets the bcrypt hash of the user's password.
+func (cu *ConcreteUser) SetPasswordHash(hash string) {
+	cu.passwordHash = hash
+}
+
+// SetRole sets the user's role.
+func (cu *ConcreteUser) SetRole(role string) {
+	cu.role = role
+}
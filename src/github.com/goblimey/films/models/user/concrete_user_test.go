@@ -0,0 +1,58 @@
+package user
+
+import (
+	"strings"
+	"testing"
+)
+
+var expectedID uint64 = 3
+var expectedUsername = "harry"
+var expectedPasswordHash = "$2a$10$abcdefghijklmnopqrstuv"
+var expectedRole = RoleEditor
+
+func TestUnitCreateConcreteUserCheckFields(t *testing.T) {
+	user := MakeInitialisedUser(expectedID, expectedUsername, expectedPasswordHash, expectedRole)
+	if user.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, user.ID())
+	}
+	if user.Username() != expectedUsername {
+		t.Errorf("expected username to be %s actually %s", expectedUsername, user.Username())
+	}
+	if user.PasswordHash() != expectedPasswordHash {
+		t.Errorf("expected password hash to be %s actually %s", expectedPasswordHash,
+			user.PasswordHash())
+	}
+	if user.Role() != expectedRole {
+		t.Errorf("expected role to be %s actually %s", expectedRole, user.Role())
+	}
+}
+
+func TestUnitUserStringHidesPasswordHash(t *testing.T) {
+	user := MakeInitialisedUser(expectedID, expectedUsername, expectedPasswordHash, expectedRole)
+	if strings.Contains(user.String(), expectedPasswordHash) {
+		t.Errorf("expected the password hash to be left out, got %s", user.String())
+	}
+}
+
+func TestUnitCloneUser(t *testing.T) {
+	source := MakeInitialisedUser(expectedID, expectedUsername, expectedPasswordHash, expectedRole)
+	user := Clone(source)
+	source.SetUsername("changed")
+	if user.Username() != expectedUsername {
+		t.Errorf("expected the clone to be independent of the source, username is %s",
+			user.Username())
+	}
+}
+
+func TestUnitValidRole(t *testing.T) {
+	for _, role := range Roles {
+		if !ValidRole(role) {
+			t.Errorf("expected %s to be valid", role)
+		}
+	}
+	for _, role := range []string{"", "Admin", "superuser"} {
+		if ValidRole(role) {
+			t.Errorf("expected %q to be invalid", role)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"strings"
+
+	userModel "github.com/goblimey/films/models/user"
+)
+
+// The GorpMysqlUser struct implements the User interface and holds a single row
+// from the USERS table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.
+type GorpMysqlUser struct {
+	IDField           uint64
+	UsernameField     string
+	PasswordHashField string
+	RoleField         string
+}
+
+// Factory functions
+
+// MakeUser creates and returns a new uninitialised User object
+func MakeUser() userModel.User {
+	var gorpMysqlUser GorpMysqlUser
+	return &gorpMysqlUser
+}
+
+// MakeInitialisedUser creates and returns a new User object initialised from
+// the arguments
+func MakeInitialisedUser(id uint64, username string, passwordHash string, role string) userModel.User {
+	user := MakeUser()
+	user.SetID(id)
+	user.SetUsername(username)
+	user.SetPasswordHash(passwordHash)
+	user.SetRole(role)
+	return user
+}
+
+// Clone creates and returns a new User object initialised from a source User.
+func Clone(source userModel.User) userModel.User {
+	return MakeInitialisedUser(source.ID(), source.Username(), source.PasswordHash(), source.Role())
+}
+
+// Methods to implement the User interface.
+
+// ID gets the id of the user.
+func (u GorpMysqlUser) ID() uint64 {
+	return u.IDField
+}
+
+// Username gets the name that the user logs in with
+func (u GorpMysqlUser) Username() string {
+	return u.UsernameField
+}
+
+// PasswordHash gets the bcrypt hash of the user's password
+func (u GorpMysqlUser) PasswordHash() string {
+	return u.PasswordHashField
+}
+
+// Role gets the user's role
+func (u GorpMysqlUser) Role() string {
+	return u.RoleField
+}
+
+// String renders the user as a string, without the password hash
+func (u GorpMysqlUser) String() string {
+	return fmt.Sprintf("{%d, %s, %s}", u.IDField, u.UsernameField, u.RoleField)
+}
+
+// SetID sets the user's id to the given value
+func (u *GorpMysqlUser) SetID(id uint64) {
+	u.IDField = id
+}
+
+// SetUsername sets the name that the user logs in with, trimming any spaces
+func (u *GorpMysqlUser) SetUsername(username string) {
+	u.UsernameField = strings.TrimSpace(username)
+}
+
+// SetPasswordHash sets the bcrypt hash of the user's password
+func (u *GorpMysqlUser) SetPasswordHash(hash string) {
+	u.PasswordHashField = hash
+}
+
+// SetRole sets the user's role
+func (u *GorpMysqlUser) SetRole(role string) {
+	u.RoleField = role
+}
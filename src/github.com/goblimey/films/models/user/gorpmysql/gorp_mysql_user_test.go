@@ -0,0 +1,37 @@
+package gorpmysql
+
+import (
+	"strings"
+	"testing"
+
+	userModel "github.com/goblimey/films/models/user"
+)
+
+var expectedID uint64 = 3
+var expectedUsername = "harry"
+var expectedPasswordHash = "$2a$10$abcdefghijklmnopqrstuv"
+var expectedRole = userModel.RoleEditor
+
+func TestUnitCreateGorpMysqlUserCheckFields(t *testing.T) {
+	user := MakeInitialisedUser(expectedID, " "+expectedUsername+" ", expectedPasswordHash, expectedRole)
+	if user.ID() != expectedID {
+		t.Errorf("expected ID to be %d actually %d", expectedID, user.ID())
+	}
+	if user.Username() != expectedUsername {
+		t.Errorf("expected username to be %s actually %q", expectedUsername, user.Username())
+	}
+	if user.PasswordHash() != expectedPasswordHash {
+		t.Errorf("expected password hash to be %s actually %s", expectedPasswordHash,
+			user.PasswordHash())
+	}
+	if user.Role() != expectedRole {
+		t.Errorf("expected role to be %s actually %s", expectedRole, user.Role())
+	}
+}
+
+func TestUnitGorpMysqlUserStringHidesPasswordHash(t *testing.T) {
+	user := MakeInitialisedUser(expectedID, expectedUsername, expectedPasswordHash, expectedRole)
+	if strings.Contains(user.String(), expectedPasswordHash) {
+		t.Errorf("expected the password hash to be left out, got %s", user.String())
+	}
+}
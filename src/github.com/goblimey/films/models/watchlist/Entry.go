@@ -0,0 +1,39 @@
+package watchlist
+
+import (
+	"time"
+)
+
+// Entry represents a film on a user's watchlist - the films that they want to
+// watch.  A film is on each user's list at most once.
+//
+// An entry also carries the title and release year of the film, for display.
+// These are filled in by the finders and are not stored in the watchlist table.
+type Entry interface {
+	// ID gets the id of the entry
+	ID() uint64
+	// UserID gets the id of the user whose list it is
+	UserID() uint64
+	// FilmID gets the id of the film
+	FilmID() uint64
+	// AddedAt gets the time at which the film was put on the list
+	AddedAt() time.Time
+	// FilmTitle gets the title of the film, for display
+	FilmTitle() string
+	// FilmReleaseYear gets the release year of the film, for display
+	FilmReleaseYear() int
+	// String gets the entry as a String
+	String() string
+	// SetID sets the id to the given value
+	SetID(id uint64)
+	// SetUserID sets the id of the user whose list it is
+	SetUserID(userID uint64)
+	// SetFilmID sets the id of the film
+	SetFilmID(filmID uint64)
+	// SetAddedAt sets the time at which the film was put on the list
+	SetAddedAt(addedAt time.Time)
+	// SetFilmTitle sets the title of the film, for display
+	SetFilmTitle(title string)
+	// SetFilmReleaseYear sets the release year of the film, for display
+	SetFilmReleaseYear(year int)
+}
@@ -0,0 +1,119 @@
+package watchlist
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConcreteEntry represents a film on a watchlist and satisfies the Entry
+// interface.
+type ConcreteEntry struct {
+	id              uint64
+	userID          uint64
+	filmID          uint64
+	addedAt         time.Time
+	filmTitle       string
+	filmReleaseYear int
+}
+
+// Define the factory functions.
+
+// MakeEntry creates and returns a new uninitialised Entry object
+func MakeEntry() Entry {
+	var concreteEntry ConcreteEntry
+	return &concreteEntry
+}
+
+// MakeInitialisedEntry creates and returns a new Entry object initialised from
+// the arguments
+func MakeInitialisedEntry(id uint64, userID uint64, filmID uint64, addedAt time.Time) Entry {
+	entry := MakeEntry()
+	entry.SetID(id)
+	entry.SetUserID(userID)
+	entry.SetFilmID(filmID)
+	entry.SetAddedAt(addedAt)
+	return entry
+}
+
+// Clone creates and returns a new Entry object initialised from a source Entry,
+// including the display fields.
+func Clone(source Entry) Entry {
+	entry := MakeInitialisedEntry(source.ID(), source.UserID(), source.FilmID(),
+		source.AddedAt())
+	entry.SetFilmTitle(source.FilmTitle())
+	entry.SetFilmReleaseYear(source.FilmReleaseYear())
+	return entry
+}
+
+// Define the getters.
+
+// ID gets the id of the entry.
+func (ce ConcreteEntry) ID() uint64 {
+	return ce.id
+}
+
+// UserID gets the id of the user whose list it is.
+func (ce ConcreteEntry) UserID() uint64 {
+	return ce.userID
+}
+
+// FilmID gets the id of the film.
+func (ce ConcreteEntry) FilmID() uint64 {
+	return ce.filmID
+}
+
+// AddedAt gets the time at which the film was put on the list.
+func (ce ConcreteEntry) AddedAt() time.Time {
+	return ce.addedAt
+}
+
+// FilmTitle gets the title of the film.
+func (ce ConcreteEntry) FilmTitle() string {
+	return ce.filmTitle
+}
+
+// FilmReleaseYear gets the release year of the film.
+func (ce ConcreteEntry) FilmReleaseYear() int {
+	return ce.filmReleaseYear
+}
+
+// String gets the entry as a String.
+func (ce ConcreteEntry) String() string {
+	return fmt.Sprintf("ConcreteEntry={id=%d, userID=%d, filmID=%d, addedAt=%s}",
+		ce.id,
+		ce.userID,
+		ce.filmID,
+		ce.addedAt.Format(time.RFC3339))
+}
+
+// Define the setters.
+
+// SetID sets the id to the given value.
+func (ce *ConcreteEntry) SetID(id uint64) {
+	ce.id = id
+}
+
+// SetUserID sets the id of the user whose list it is.
+func (ce *ConcreteEntry) SetUserID(userID uint64) {
+	ce.userID = userID
+}
+
+// SetFilmID sets the id of the film.
+func (ce *ConcreteEntry) SetFilmID(filmID uint64) {
+	ce.filmID = filmID
+}
+
+// SetAddedAt sets the time at which the film was put on the list.
+func (ce *ConcreteEntry) SetAddedAt(addedAt time.Time) {
+	ce.addedAt = addedAt
+}
+
+// SetFilmTitle sets the title of the film.
+func (ce *ConcreteEntry) SetFilmTitle(title string) {
+	ce.filmTitle = title
+}
+
+// SetFilmReleaseYear sets the release year of the film.
+func (ce *ConcreteEntry) SetFilmReleaseYear(year int) {
+	ce.filmReleaseYear = year
+}
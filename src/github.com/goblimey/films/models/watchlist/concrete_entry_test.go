@@ -0,0 +1,25 @@
+package watchlist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnitCreateConcreteEntryCheckFields(t *testing.T) {
+	addedAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	source := MakeInitialisedEntry(2, 3, 4, addedAt)
+	source.SetFilmTitle("Rope")
+	source.SetFilmReleaseYear(1948)
+	entry := Clone(source)
+	if entry.ID() != 2 || entry.UserID() != 3 || entry.FilmID() != 4 {
+		t.Errorf("expected IDs 2, 3 and 4, got %d, %d and %d", entry.ID(),
+			entry.UserID(), entry.FilmID())
+	}
+	if !entry.AddedAt().Equal(addedAt) {
+		t.Errorf("expected %v, got %v", addedAt, entry.AddedAt())
+	}
+	if entry.FilmTitle() != "Rope" || entry.FilmReleaseYear() != 1948 {
+		t.Errorf("expected the film to be copied, got %q (%d)", entry.FilmTitle(),
+			entry.FilmReleaseYear())
+	}
+}
@@ -0,0 +1,124 @@
+package gorpmysql
+
+import (
+	"fmt"
+	"time"
+
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+)
+
+// The GorpMysqlEntry struct implements the watchlist Entry interface and holds a
+// single row from the WATCHLIST table, accessed via the GORP library.
+//
+// The fields must be public for GORP to work and the names must not clash with those
+// of the getters.  The column names are set up when the table is added to the GORP
+// DbMap.  The film display fields are transient - they are filled in by the joins
+// in the finders and are not stored in the watchlist table.
+type GorpMysqlEntry struct {
+	IDField              uint64
+	UserIDField          uint64
+	FilmIDField          uint64
+	AddedAtField         int64
+	FilmTitleField       string
+	FilmReleaseYearField int
+}
+
+// Factory functions
+
+// MakeEntry creates and returns a new uninitialised Entry object
+func MakeEntry() watchlistModel.Entry {
+	var gorpMysqlEntry GorpMysqlEntry
+	return &gorpMysqlEntry
+}
+
+// MakeInitialisedEntry creates and returns a new Entry object initialised from
+// the arguments
+func MakeInitialisedEntry(id uint64, userID uint64, filmID uint64,
+	addedAt time.Time) watchlistModel.Entry {
+
+	entry := MakeEntry()
+	entry.SetID(id)
+	entry.SetUserID(userID)
+	entry.SetFilmID(filmID)
+	entry.SetAddedAt(addedAt)
+	return entry
+}
+
+// Clone creates and returns a new Entry object initialised from a source Entry,
+// including the display fields.
+func Clone(source watchlistModel.Entry) watchlistModel.Entry {
+	entry := MakeInitialisedEntry(source.ID(), source.UserID(), source.FilmID(),
+		source.AddedAt())
+	entry.SetFilmTitle(source.FilmTitle())
+	entry.SetFilmReleaseYear(source.FilmReleaseYear())
+	return entry
+}
+
+// Methods to implement the Entry interface.
+
+// ID gets the id of the entry.
+func (e GorpMysqlEntry) ID() uint64 {
+	return e.IDField
+}
+
+// UserID gets the id of the user whose list it is
+func (e GorpMysqlEntry) UserID() uint64 {
+	return e.UserIDField
+}
+
+// FilmID gets the id of the film
+func (e GorpMysqlEntry) FilmID() uint64 {
+	return e.FilmIDField
+}
+
+// AddedAt gets the time at which the film was put on the list, in UTC
+func (e GorpMysqlEntry) AddedAt() time.Time {
+	return time.Unix(e.AddedAtField, 0).UTC()
+}
+
+// FilmTitle gets the title of the film
+func (e GorpMysqlEntry) FilmTitle() string {
+	return e.FilmTitleField
+}
+
+// FilmReleaseYear gets the release year of the film
+func (e GorpMysqlEntry) FilmReleaseYear() int {
+	return e.FilmReleaseYearField
+}
+
+// String renders the entry as a string
+func (e GorpMysqlEntry) String() string {
+	return fmt.Sprintf("{%d, %d, %d, %d}", e.IDField, e.UserIDField, e.FilmIDField,
+		e.AddedAtField)
+}
+
+// SetID sets the entry's id to the given value
+func (e *GorpMysqlEntry) SetID(id uint64) {
+	e.IDField = id
+}
+
+// SetUserID sets the id of the user whose list it is
+func (e *GorpMysqlEntry) SetUserID(userID uint64) {
+	e.UserIDField = userID
+}
+
+// SetFilmID sets the id of the film
+func (e *GorpMysqlEntry) SetFilmID(filmID uint64) {
+	e.FilmIDField = filmID
+}
+
+// SetAddedAt sets the time at which the film was put on the list.  It's stored
+// to the nearest second.
+func (e *GorpMysqlEntry) SetAddedAt(addedAt time.Time) {
+	e.AddedAtField = addedAt.Unix()
+}
+
+// SetFilmTitle sets the title of the film
+func (e *GorpMysqlEntry) SetFilmTitle(title string) {
+	e.FilmTitleField = title
+}
+
+// SetFilmReleaseYear sets the release year of the film
+func (e *GorpMysqlEntry) SetFilmReleaseYear(year int) {
+	e.FilmReleaseYearField = year
+}
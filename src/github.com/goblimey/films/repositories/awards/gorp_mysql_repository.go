@@ -0,0 +1,435 @@
+// Package awards provides Create, Read, Update and Delete (CRUD) operations on
+// the award ceremonies, their categories and the nominations of films and people
+// in those categories.  The tables are referenced via a database session that is
+// supplied by the parent.
+//
+// The GorpMysqlRepo satisfies the Repository interface.
+package awards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	awardModel "github.com/goblimey/films/models/award"
+	gorpAwardModel "github.com/goblimey/films/models/award/gorpmysql"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// GorpMysqlRepo satifies the Repository interface.
+type GorpMysqlRepo struct {
+	session dbsession.DBSession
+	ctx     context.Context
+}
+
+// MakeRepo is a factory function that creates a GorpMysqlRepo and returns it as a
+// Repository.
+func MakeRepo(session dbsession.DBSession) Repository {
+	return &GorpMysqlRepo{session: session}
+}
+
+// SetSession sets the session.
+func (gmar *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
+	gmar.session = session
+}
+
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmar GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmar.ctx = ctx
+	gmar.session = gmar.session.WithContext(ctx)
+	return &gmar
+}
+
+// FindAllCeremonies returns all of the award ceremonies, most recent year first.
+func (gmar GorpMysqlRepo) FindAllCeremonies() ([]awardModel.Ceremony, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "FindAllCeremonies()"
+	logger.Debug(m)
+	return gmar.session.FindAllCeremonies()
+}
+
+// FindCeremonyByID fetches the row from the award_ceremonies table with the given
+// uint64 id.
+func (gmar GorpMysqlRepo) FindCeremonyByID(id uint64) (awardModel.Ceremony, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "FindCeremonyByID()"
+	logger.Debug(m, "id", id)
+	return gmar.session.FindCeremonyByID(id)
+}
+
+// FindCeremonyByIDStr fetches the row from the award_ceremonies table with the
+// given string id.  The method checks that the given ID is numeric before it makes
+// the call.
+func (gmar GorpMysqlRepo) FindCeremonyByIDStr(idStr string) (awardModel.Ceremony, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "FindCeremonyByIDStr()"
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
+		logger.Error(m, "error", em)
+		return nil, errors.New(em)
+	}
+	return gmar.FindCeremonyByID(id)
+}
+
+// CreateCeremony takes a ceremony, creates a record in the award_ceremonies table
+// containing the same data with an auto-incremented ID and returns the created
+// ceremony, including the assigned ID.  If there is already a ceremony with the
+// same name and year, it returns an error.
+func (gmar GorpMysqlRepo) CreateCeremony(ceremony awardModel.Ceremony) (awardModel.Ceremony, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "CreateCeremony()"
+	logger.Debug(m)
+	err := gmar.checkCeremony(ceremony)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	ceremony.SetID(0) // provokes the auto-increment
+	err = gmar.insert(ceremony)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	logger.Info("created award ceremony", "ceremony", ceremony.String())
+	return ceremony, nil
+}
+
+// UpdateCeremony takes a ceremony record, updates the record in the
+// award_ceremonies table with the same ID and returns the row count or any error
+// that the DB call supplies to it.  If another ceremony has the same name and
+// year, it returns an error.
+func (gmar GorpMysqlRepo) UpdateCeremony(ceremony awardModel.Ceremony) (uint64, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "UpdateCeremony()"
+	err := gmar.checkCeremony(ceremony)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	err = gmar.update(ceremony)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return 1, nil
+}
+
+// DeleteCeremonyByID takes the given uint64 ID and deletes the record with that ID
+// from the award_ceremonies table.  Its categories and their nominations are
+// deleted in the same transaction.  On a successful delete, it should return 1,
+// having deleted one ceremony.
+func (gmar GorpMysqlRepo) DeleteCeremonyByID(id uint64) (int64, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "DeleteCeremonyByID()"
+	logger.Debug(m, "id", id)
+	// Need a Ceremony record for the delete method, so fake one up.
+	var ceremony gorpAwardModel.GorpMysqlCeremony
+	ceremony.SetID(id)
+	categories, err := gmar.session.FindCategoriesByCeremony(id)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	dependents := make([]interface{}, 0)
+	for _, category := range categories {
+		nominations, err := gmar.session.FindNominationsByCategory(category.ID())
+		if err != nil {
+			logger.Error(m, "error", err)
+			return 0, err
+		}
+		for _, nomination := range nominations {
+			dependents = append(dependents, nomination)
+		}
+		dependents = append(dependents, category)
+	}
+	rowsDeleted, err := gmar.delete(&ceremony, dependents)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
+
+// DeleteCeremonyByIDStr takes the given String ID and deletes the ceremony with
+// that ID.  The method checks that the given ID is numeric before it makes the
+// call.  If not, it returns an error.
+func (gmar GorpMysqlRepo) DeleteCeremonyByIDStr(idStr string) (int64, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "DeleteCeremonyByIDStr()"
+	logger.Debug(m, "id", idStr)
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+	return gmar.DeleteCeremonyByID(id)
+}
+
+// FindCategoryByID fetches the row from the award_categories table with the given
+// uint64 id.
+func (gmar GorpMysqlRepo) FindCategoryByID(id uint64) (awardModel.Category, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "FindCategoryByID()"
+	logger.Debug(m, "id", id)
+	return gmar.session.FindCategoryByID(id)
+}
+
+// FindCategoriesByCeremony returns the categories of the ceremony with the given
+// ID, in order of name.
+func (gmar GorpMysqlRepo) FindCategoriesByCeremony(ceremonyID uint64) ([]awardModel.Category, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "FindCategoriesByCeremony()"
+	logger.Debug(m, "ceremony_id", ceremonyID)
+	return gmar.session.FindCategoriesByCeremony(ceremonyID)
+}
+
+// AddCategory takes a category, creates a record in the award_categories table
+// containing the same data with an auto-incremented ID and returns the created
+// category.  If the ceremony already has a category with the same name, it
+// returns an error.
+func (gmar GorpMysqlRepo) AddCategory(category awardModel.Category) (awardModel.Category, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "AddCategory()"
+	logger.Debug(m, "category", category.String())
+	existing, err := gmar.session.FindCategoriesByCeremony(category.CeremonyID())
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	for _, c := range existing {
+		if c.Name() == category.Name() {
+			em := fmt.Sprintf("the ceremony already has a category %s", c.Name())
+			logger.Error(m, "error", em)
+			return nil, errors.New(em)
+		}
+	}
+	category.SetID(0) // provokes the auto-increment
+	err = gmar.insert(category)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	logger.Info("added award category", "category", category.String())
+	return category, nil
+}
+
+// RemoveCategory deletes the category with the given uint64 ID.  Its nominations
+// are deleted in the same transaction.  On a successful delete, it should return
+// 1, having deleted one category.
+func (gmar GorpMysqlRepo) RemoveCategory(id uint64) (int64, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "RemoveCategory()"
+	logger.Debug(m, "id", id)
+	// Need a Category record for the delete method, so fake one up.
+	var category gorpAwardModel.GorpMysqlCategory
+	category.SetID(id)
+	nominations, err := gmar.session.FindNominationsByCategory(id)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	dependents := make([]interface{}, 0, len(nominations))
+	for _, nomination := range nominations {
+		dependents = append(dependents, nomination)
+	}
+	rowsDeleted, err := gmar.delete(&category, dependents)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
+
+// FindNominationByID fetches the nomination with the given uint64 id.
+func (gmar GorpMysqlRepo) FindNominationByID(id uint64) (awardModel.Nomination, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "FindNominationByID()"
+	logger.Debug(m, "id", id)
+	return gmar.session.FindNominationByID(id)
+}
+
+// FindNominationsByCeremony returns the nominations at the ceremony with the given
+// ID.
+func (gmar GorpMysqlRepo) FindNominationsByCeremony(ceremonyID uint64) ([]awardModel.Nomination, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "FindNominationsByCeremony()"
+	logger.Debug(m, "ceremony_id", ceremonyID)
+	return gmar.session.FindNominationsByCeremony(ceremonyID)
+}
+
+// FindNominationsByFilm returns the nominations of the film with the given ID.
+func (gmar GorpMysqlRepo) FindNominationsByFilm(filmID uint64) ([]awardModel.Nomination, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "FindNominationsByFilm()"
+	logger.Debug(m, "film_id", filmID)
+	return gmar.session.FindNominationsByFilm(filmID)
+}
+
+// FindNominationsByPerson returns the nominations of the person with the given ID.
+func (gmar GorpMysqlRepo) FindNominationsByPerson(personID uint64) ([]awardModel.Nomination, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "FindNominationsByPerson()"
+	logger.Debug(m, "person_id", personID)
+	return gmar.session.FindNominationsByPerson(personID)
+}
+
+// AddNomination takes a nomination, creates a record in the nominations table
+// containing the same data with an auto-incremented ID and returns the created
+// nomination.  If the same film and person are already nominated in the category,
+// it returns an error.
+func (gmar GorpMysqlRepo) AddNomination(nomination awardModel.Nomination) (awardModel.Nomination, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "AddNomination()"
+	logger.Debug(m, "nomination", nomination.String())
+	existing, err := gmar.session.FindNominationsByCategory(nomination.CategoryID())
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	for _, n := range existing {
+		if n.FilmID() == nomination.FilmID() && n.PersonID() == nomination.PersonID() {
+			em := fmt.Sprintf("%s is already nominated for %s", n.FilmTitle(), n.CategoryName())
+			logger.Error(m, "error", em)
+			return nil, errors.New(em)
+		}
+	}
+	nomination.SetID(0) // provokes the auto-increment
+	err = gmar.insert(nomination)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	logger.Info("added nomination", "nomination", nomination.String())
+	return nomination, nil
+}
+
+// UpdateNomination takes a nomination, updates the record in the nominations
+// table with the same ID and returns the row count or any error that the DB call
+// supplies to it.
+func (gmar GorpMysqlRepo) UpdateNomination(nomination awardModel.Nomination) (uint64, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "UpdateNomination()"
+	err := gmar.update(nomination)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return 1, nil
+}
+
+// RemoveNomination deletes the nomination with the given uint64 ID.  On a
+// successful delete, it should return 1, having deleted one row.
+func (gmar GorpMysqlRepo) RemoveNomination(id uint64) (int64, error) {
+	logger := logging.FromContext(gmar.ctx)
+	m := "RemoveNomination()"
+	logger.Debug(m, "id", id)
+	// Need a Nomination record for the delete method, so fake one up.
+	var nomination gorpAwardModel.GorpMysqlNomination
+	nomination.SetID(id)
+	rowsDeleted, err := gmar.delete(&nomination, nil)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
+
+// checkCeremony returns an error if a ceremony other than the given one has the
+// same name and year.
+func (gmar GorpMysqlRepo) checkCeremony(ceremony awardModel.Ceremony) error {
+	existing, err := gmar.session.FindAllCeremonies()
+	if err != nil {
+		return err
+	}
+	for _, c := range existing {
+		if c.ID() != ceremony.ID() && c.Name() == ceremony.Name() && c.Year() == ceremony.Year() {
+			return fmt.Errorf("there is already a ceremony %s %d", c.Name(), c.Year())
+		}
+	}
+	return nil
+}
+
+// insert inserts the given record within a transaction, setting its
+// auto-incremented ID.
+func (gmar GorpMysqlRepo) insert(record interface{}) error {
+	tx, err := gmar.session.StartTransaction()
+	if err != nil {
+		return err
+	}
+	err = tx.Insert(record)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+// update updates the record with the same ID as the given one within a
+// transaction.  If that doesn't update exactly one row, it returns an error.
+func (gmar GorpMysqlRepo) update(record interface{}) error {
+	tx, err := gmar.session.StartTransaction()
+	if err != nil {
+		return err
+	}
+	rowsUpdated, err := tx.Update(record)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rowsUpdated != 1 {
+		tx.Rollback()
+		return fmt.Errorf("update failed - %d rows would have been updated, expected 1", rowsUpdated)
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+// delete deletes the given dependent records and then the given record within a
+// transaction and returns the number of rows that deleting the record affected.
+// If that's not exactly one, nothing is deleted and it returns an error.
+func (gmar GorpMysqlRepo) delete(record interface{}, dependents []interface{}) (int64, error) {
+	tx, err := gmar.session.StartTransaction()
+	if err != nil {
+		return 0, err
+	}
+	for _, dependent := range dependents {
+		_, err = tx.Delete(dependent)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	rowsDeleted, err := tx.Delete(record)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if rowsDeleted != 1 {
+		tx.Rollback()
+		return 0, fmt.Errorf("delete failed - %d rows would have been deleted, expected 1", rowsDeleted)
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
@@ -0,0 +1,321 @@
+package awards
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"testing"
+
+	gorpAwardModel "github.com/goblimey/films/models/award/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film/gorpmysql"
+	personModel "github.com/goblimey/films/models/person/gorpmysql"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	dbsession "github.com/goblimey/films/utilities/dbsession"
+)
+
+// This is an integration test for the GorpMysqlRepo connecting to a MySQL DB via GORP.
+// The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.
+
+var expectedName = "Academy Awards"
+var expectedYear = 1995
+
+// Create a ceremony with two categories, nominate films and a person, mark a
+// winner and check that the nominations come back in order, then delete the
+// ceremony and check that its categories and nominations have gone too.
+func TestIntCreateCeremonyAndNominate(t *testing.T) {
+	log.SetPrefix("TestIntCreateCeremonyAndNominate")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+	people := peopleRepo.MakeRepo(session)
+
+	ceremony, err := repo.CreateCeremony(gorpAwardModel.MakeInitialisedCeremony(0,
+		"Academy Award", expectedYear))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	ceremony.SetName(expectedName)
+	_, err = repo.UpdateCeremony(ceremony)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	fetched, err := repo.FindCeremonyByIDStr(strconv.FormatUint(ceremony.ID(), 10))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if fetched.Name() != expectedName || fetched.Year() != expectedYear {
+		t.Errorf("expected %s %d actually %s", expectedName, expectedYear, fetched.String())
+	}
+	// The same ceremony can't be recorded twice.
+	_, err = repo.CreateCeremony(gorpAwardModel.MakeInitialisedCeremony(0, expectedName,
+		expectedYear))
+	if err == nil {
+		t.Errorf("expected the second ceremony to be refused")
+	}
+
+	picture, err := repo.AddCategory(gorpAwardModel.MakeInitialisedCategory(0,
+		ceremony.ID(), "Best Picture"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	actor, err := repo.AddCategory(gorpAwardModel.MakeInitialisedCategory(0,
+		ceremony.ID(), "Best Actor"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.AddCategory(gorpAwardModel.MakeInitialisedCategory(0,
+		ceremony.ID(), "Best Actor"))
+	if err == nil {
+		t.Errorf("expected the second Best Actor category to be refused")
+	}
+	categories, err := repo.FindCategoriesByCeremony(ceremony.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(categories) != 2 || categories[0].Name() != "Best Actor" {
+		t.Fatalf("expected Best Actor and Best Picture, actually %v", categories)
+	}
+
+	gump, err := films.Create(filmModel.MakeInitialisedFilm(0, "Forrest Gump", 1994, 142, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(gump.ID())
+	pulp, err := films.Create(filmModel.MakeInitialisedFilm(0, "Pulp Fiction", 1994, 154, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(pulp.ID())
+	hanks, err := people.Create(personModel.MakeInitialisedPerson(0, "Tom", "Hanks"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer func() {
+		people.DeleteByID(hanks.ID())
+		people.Purge(hanks.ID())
+	}()
+
+	_, err = repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0, picture.ID(),
+		pulp.ID(), 0, false))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	winner, err := repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0,
+		picture.ID(), gump.ID(), 0, false))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0, actor.ID(),
+		gump.ID(), hanks.ID(), true))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0, picture.ID(),
+		gump.ID(), 0, false))
+	if err == nil {
+		t.Errorf("expected the second nomination of Forrest Gump to be refused")
+	}
+	winner.SetWon(true)
+	_, err = repo.UpdateNomination(winner)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	nominations, err := repo.FindNominationsByCeremony(ceremony.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := []string{"Best Actor Forrest Gump", "Best Picture Forrest Gump",
+		"Best Picture Pulp Fiction"}
+	if len(nominations) != len(expected) {
+		t.Fatalf("expected %d nominations, actually %d", len(expected), len(nominations))
+	}
+	for i, nomination := range nominations {
+		got := nomination.CategoryName() + " " + nomination.FilmTitle()
+		if got != expected[i] {
+			t.Errorf("%d: expected %s actually %s", i, expected[i], got)
+		}
+	}
+	if !nominations[1].Won() || nominations[2].Won() {
+		t.Errorf("expected Forrest Gump to have won Best Picture, actually %v", nominations)
+	}
+	if nominations[0].PersonSurname() != "Hanks" || nominations[1].PersonSurname() != "" {
+		t.Errorf("expected only the Best Actor nomination to name a person, actually %v",
+			nominations)
+	}
+	nominations, err = repo.FindNominationsByPerson(hanks.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(nominations) != 1 || nominations[0].CeremonyName() != expectedName ||
+		nominations[0].CeremonyYear() != expectedYear {
+		t.Errorf("expected Hanks to have one nomination at the %s %d, actually %v",
+			expectedName, expectedYear, nominations)
+	}
+	nominations, err = repo.FindNominationsByFilm(gump.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(nominations) != 2 {
+		t.Errorf("expected 2 nominations of Forrest Gump, actually %d", len(nominations))
+	}
+
+	// A person in the trash drops out of the film's nominations.
+	_, err = people.DeleteByID(hanks.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	nominations, _ = repo.FindNominationsByFilm(gump.ID())
+	if len(nominations) != 1 {
+		t.Errorf("expected 1 nomination of Forrest Gump, actually %d", len(nominations))
+	}
+
+	rows, err := repo.DeleteCeremonyByID(ceremony.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected one ceremony to be deleted, actually %d", rows)
+	}
+	_, err = repo.FindCategoryByID(actor.ID())
+	if err == nil {
+		t.Errorf("expected the category to be deleted along with the ceremony")
+	}
+	_, err = repo.FindNominationByID(winner.ID())
+	if err == nil {
+		t.Errorf("expected the nomination to be deleted along with the ceremony")
+	}
+	nominations, _ = repo.FindNominationsByPerson(hanks.ID())
+	if len(nominations) != 0 {
+		t.Errorf("expected the nomination of the person in the trash to be deleted, actually %v",
+			nominations)
+	}
+}
+
+// Nominate a film and a person, remove a nomination and a category, then delete
+// the film and check that its nominations go with it.  Purge the person and check
+// that their nomination stays on the ceremony as a nomination of the film alone.
+func TestIntRemoveNominationsWithFilmAndPerson(t *testing.T) {
+	log.SetPrefix("TestIntRemoveNominationsWithFilmAndPerson")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+	people := peopleRepo.MakeRepo(session)
+
+	ceremony, err := repo.CreateCeremony(gorpAwardModel.MakeInitialisedCeremony(0,
+		"BAFTA Awards", 1950))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer repo.DeleteCeremonyByID(ceremony.ID())
+	film, err := films.Create(filmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	person, err := people.Create(personModel.MakeInitialisedPerson(0, "Carol", "Reed"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	film2, err := films.Create(filmModel.MakeInitialisedFilm(0, "Odd Man Out", 1947, 116, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(film2.ID())
+
+	filmCategory, err := repo.AddCategory(gorpAwardModel.MakeInitialisedCategory(0,
+		ceremony.ID(), "Best British Film"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	directorCategory, err := repo.AddCategory(gorpAwardModel.MakeInitialisedCategory(0,
+		ceremony.ID(), "Best Director"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	removed, err := repo.AddCategory(gorpAwardModel.MakeInitialisedCategory(0,
+		ceremony.ID(), "Best Film from any Source"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	filmNomination, err := repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0,
+		filmCategory.ID(), film.ID(), 0, true))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	personNomination, err := repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0,
+		directorCategory.ID(), film2.ID(), person.ID(), false))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	removedNomination, err := repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0,
+		removed.ID(), film2.ID(), 0, false))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	other, err := repo.AddNomination(gorpAwardModel.MakeInitialisedNomination(0,
+		filmCategory.ID(), film2.ID(), 0, false))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	rows, err := repo.RemoveNomination(other.ID())
+	if err != nil || rows != 1 {
+		t.Fatalf("expected one nomination to be removed, actually %d, %v", rows, err)
+	}
+	_, err = repo.FindNominationByID(other.ID())
+	if err == nil {
+		t.Errorf("expected the nomination to be removed")
+	}
+	rows, err = repo.RemoveCategory(removed.ID())
+	if err != nil || rows != 1 {
+		t.Fatalf("expected one category to be removed, actually %d, %v", rows, err)
+	}
+	_, err = repo.FindNominationByID(removedNomination.ID())
+	if err == nil {
+		t.Errorf("expected the nomination to be removed along with its category")
+	}
+
+	_, err = films.DeleteByID(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.FindNominationByID(filmNomination.ID())
+	if err == nil {
+		t.Errorf("expected the nomination to be deleted along with the film")
+	}
+
+	_, err = people.DeleteByID(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = people.Purge(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	nomination, err := repo.FindNominationByID(personNomination.ID())
+	if err != nil {
+		t.Fatalf("expected the nomination to survive the purge - %s", err.Error())
+	}
+	if nomination.PersonID() != 0 || nomination.FilmID() != film2.ID() {
+		t.Errorf("expected a nomination of Odd Man Out alone, got %s", nomination.String())
+	}
+	nominations, err := repo.FindNominationsByCeremony(ceremony.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(nominations) != 1 || nominations[0].ID() != personNomination.ID() {
+		t.Errorf("expected the ceremony to list the nomination of Odd Man Out, got %v",
+			nominations)
+	}
+}
@@ -0,0 +1,141 @@
+package awards
+
+import (
+	"context"
+
+	awardModel "github.com/goblimey/films/models/award"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// Repository is the interface defining a repository (AKA a Data Access Object) for
+// the award_ceremonies table, the award_categories table, which holds the
+// categories of each ceremony, and the nominations table, which holds the films and
+// people nominated in each category.
+type Repository interface {
+	SetSession(session dbsession.DBSession)
+
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		FindAllCeremonies returns all of the award ceremonies, most recent year
+		first.
+	*/
+	FindAllCeremonies() ([]awardModel.Ceremony, error)
+
+	/*
+		FindCeremonyByID fetches the row from the award_ceremonies table with the
+		given uint64 id.
+	*/
+	FindCeremonyByID(id uint64) (awardModel.Ceremony, error)
+
+	/*
+		FindCeremonyByIDStr fetches the row from the award_ceremonies table with the
+		given string id.  The ID in the database is numeric and the method checks
+		that the given ID is also numeric before it makes the call.  If not, it
+		returns an error.
+	*/
+	FindCeremonyByIDStr(idStr string) (awardModel.Ceremony, error)
+
+	/*
+		CreateCeremony takes a ceremony and creates a record in the award_ceremonies
+		table containing the same data and with an auto-incremented ID.  If there is
+		already a ceremony with the same name and year, it returns an error.
+	*/
+	CreateCeremony(ceremony awardModel.Ceremony) (awardModel.Ceremony, error)
+
+	/*
+		UpdateCeremony takes a ceremony, updates the record in the award_ceremonies
+		table with the same ID and returns the row count and error that the DB call
+		supplies to it.  On a successful update, the number of rows returned should
+		be 1.
+	*/
+	UpdateCeremony(ceremony awardModel.Ceremony) (uint64, error)
+
+	/*
+	 * DeleteCeremonyByID takes the given uint64 ID and deletes the record with that ID
+	 * from the award_ceremonies table, along with its categories and their
+	 * nominations.  On a successful delete, it should return 1, having deleted one
+	 * ceremony.
+	 */
+	DeleteCeremonyByID(id uint64) (int64, error)
+
+	/*
+	 * DeleteCeremonyByIDStr takes the given String ID and deletes the ceremony with
+	 * that ID.  The method checks that the given ID is numeric before it makes the
+	 * call.  If not, it returns an error.
+	 */
+	DeleteCeremonyByIDStr(idStr string) (int64, error)
+
+	/*
+		FindCategoryByID fetches the row from the award_categories table with the
+		given uint64 id.
+	*/
+	FindCategoryByID(id uint64) (awardModel.Category, error)
+
+	/*
+		FindCategoriesByCeremony returns the categories of the ceremony with the
+		given ID, in order of name.
+	*/
+	FindCategoriesByCeremony(ceremonyID uint64) ([]awardModel.Category, error)
+
+	/*
+		AddCategory takes a category and creates a record in the award_categories
+		table containing the same data and with an auto-incremented ID.  If the
+		ceremony already has a category with that name, it returns an error.
+	*/
+	AddCategory(category awardModel.Category) (awardModel.Category, error)
+
+	/*
+		RemoveCategory deletes the category with the given uint64 ID, along with its
+		nominations.  On a successful delete, it should return 1.
+	*/
+	RemoveCategory(id uint64) (int64, error)
+
+	/*
+		FindNominationByID fetches the nomination with the given uint64 id, along
+		with the names of its category and ceremony, its film and its person.
+	*/
+	FindNominationByID(id uint64) (awardModel.Nomination, error)
+
+	/*
+		FindNominationsByCeremony returns the nominations at the ceremony with the
+		given ID, in order of category with the winners of each category first.
+	*/
+	FindNominationsByCeremony(ceremonyID uint64) ([]awardModel.Nomination, error)
+
+	/*
+		FindNominationsByFilm returns the nominations of the film with the given ID,
+		most recent ceremony first.
+	*/
+	FindNominationsByFilm(filmID uint64) ([]awardModel.Nomination, error)
+
+	/*
+		FindNominationsByPerson returns the nominations of the person with the given
+		ID, most recent ceremony first.
+	*/
+	FindNominationsByPerson(personID uint64) ([]awardModel.Nomination, error)
+
+	/*
+		AddNomination takes a nomination and creates a record in the nominations
+		table containing the same data and with an auto-incremented ID.  If the same
+		film and person are already nominated in the category, it returns an error.
+	*/
+	AddNomination(nomination awardModel.Nomination) (awardModel.Nomination, error)
+
+	/*
+		UpdateNomination takes a nomination, updates the record in the nominations
+		table with the same ID and returns the row count and error that the DB call
+		supplies to it.  It's used to mark the winners.
+	*/
+	UpdateNomination(nomination awardModel.Nomination) (uint64, error)
+
+	/*
+		RemoveNomination deletes the nomination with the given uint64 ID.  On a
+		successful delete, it should return 1.
+	*/
+	RemoveNomination(id uint64) (int64, error)
+}
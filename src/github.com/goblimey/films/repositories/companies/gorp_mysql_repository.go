@@ -0,0 +1,316 @@
+// Package companies provides Create, Read, Update and Delete (CRUD) operations on
+// the companies resource, which records the companies that make and distribute
+// films, and on the links between those companies and their films.  The tables
+// are referenced via a database session that is supplied by the parent.
+//
+// The GorpMysqlRepo satisfies the Repository interface.
+package companies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	companyModel "github.com/goblimey/films/models/company"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// GorpMysqlRepo satifies the Repository interface.
+type GorpMysqlRepo struct {
+	session dbsession.DBSession
+	ctx     context.Context
+}
+
+// MakeRepo is a factory function that creates a GorpMysqlRepo and returns it as a
+// Repository.
+func MakeRepo(session dbsession.DBSession) Repository {
+	return &GorpMysqlRepo{session: session}
+}
+
+// SetSession sets the session.
+func (gmcr *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
+	gmcr.session = session
+}
+
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmcr GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmcr.ctx = ctx
+	gmcr.session = gmcr.session.WithContext(ctx)
+	return &gmcr
+}
+
+// FindAll returns all of the companies in order of name.
+func (gmcr GorpMysqlRepo) FindAll() ([]companyModel.Company, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindAll()"
+	logger.Debug(m)
+	return gmcr.session.FindAllCompanies()
+}
+
+// FindByID fetches the row from the companies table with the given uint64 id.
+func (gmcr GorpMysqlRepo) FindByID(id uint64) (companyModel.Company, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindByID()"
+	logger.Debug(m, "id", id)
+	return gmcr.session.FindCompanyByID(id)
+}
+
+// FindByIDStr fetches the row from the companies table with the given string id.
+// The method checks that the given ID is numeric before it makes the call.
+func (gmcr GorpMysqlRepo) FindByIDStr(idStr string) (companyModel.Company, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindByIDStr()"
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
+		logger.Error(m, "error", em)
+		return nil, errors.New(em)
+	}
+	return gmcr.FindByID(id)
+}
+
+// Create takes a company, creates a record in the companies table containing the
+// same data with an auto-incremented ID and returns any error that the DB call
+// returns.  On a successful create, the method returns the created company,
+// including the assigned ID.  This is all done within a transaction to ensure
+// atomicity.
+func (gmcr GorpMysqlRepo) Create(company companyModel.Company) (companyModel.Company, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "Create()"
+	logger.Debug(m)
+	tx, err := gmcr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	company.SetID(0) // provokes the auto-increment
+	err = tx.Insert(company)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	logger.Info("created company", "company", company.String())
+	return company, nil
+}
+
+// Update takes a company record, updates the record in the companies table with
+// the same ID and returns the row count or any error that the DB call supplies to
+// it.  The update is done within a transaction.
+func (gmcr GorpMysqlRepo) Update(company companyModel.Company) (uint64, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "Update()"
+	tx, err := gmcr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	rowsUpdated, err := tx.Update(company)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if rowsUpdated != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("update failed - %d rows would have been updated, expected 1", rowsUpdated)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+
+	// Success!
+	return 1, nil
+}
+
+// DeleteByID takes the given uint64 ID and deletes the record with that ID from the
+// companies table.  The function returns the row count and error that the database
+// supplies to it.  On a successful delete, it should return 1, having deleted one
+// row.  The company's links to films are deleted in the same transaction.
+func (gmcr GorpMysqlRepo) DeleteByID(id uint64) (int64, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "DeleteByID()"
+	logger.Debug(m, "id", id)
+	// Need a Company record for the delete method, so fake one up.
+	var company gorpCompanyModel.GorpMysqlCompany
+	company.SetID(id)
+	links, err := gmcr.session.FindCompanyLinksByCompany(id)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	tx, err := gmcr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	for _, link := range links {
+		_, err = tx.Delete(link)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(m, "error", err)
+			return 0, err
+		}
+	}
+	rowsDeleted, err := tx.Delete(&company)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if rowsDeleted != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("delete failed - %d rows would have been deleted, expected 1", rowsDeleted)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
+
+// DeleteByIDStr takes the given String ID and deletes the record with that ID from
+// the companies table.  The method checks that the given ID is numeric before it
+// makes the call.  If not, it returns an error.
+func (gmcr GorpMysqlRepo) DeleteByIDStr(idStr string) (int64, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "DeleteByIDStr()"
+	logger.Debug(m, "id", idStr)
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+	return gmcr.DeleteByID(id)
+}
+
+// FindLinkByID fetches the link between a company and a film with the given uint64
+// id.
+func (gmcr GorpMysqlRepo) FindLinkByID(id uint64) (companyModel.Link, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindLinkByID()"
+	logger.Debug(m, "id", id)
+	return gmcr.session.FindCompanyLinkByID(id)
+}
+
+// FindLinksByCompany returns the links between the company with the given ID and
+// its films.
+func (gmcr GorpMysqlRepo) FindLinksByCompany(companyID uint64) ([]companyModel.Link, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindLinksByCompany()"
+	logger.Debug(m, "company_id", companyID)
+	return gmcr.session.FindCompanyLinksByCompany(companyID)
+}
+
+// FindLinksByFilm returns the links between the film with the given ID and its
+// companies.
+func (gmcr GorpMysqlRepo) FindLinksByFilm(filmID uint64) ([]companyModel.Link, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindLinksByFilm()"
+	logger.Debug(m, "film_id", filmID)
+	return gmcr.session.FindCompanyLinksByFilm(filmID)
+}
+
+// AddLink takes a link, creates a record in the film_companies table containing the
+// same data with an auto-incremented ID and returns the created link.  If the
+// company already has the same relationship with the film, it returns an error.
+func (gmcr GorpMysqlRepo) AddLink(link companyModel.Link) (companyModel.Link, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "AddLink()"
+	logger.Debug(m, "link", link.String())
+	existing, err := gmcr.session.FindCompanyLinksByFilm(link.FilmID())
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	for _, l := range existing {
+		if l.CompanyID() == link.CompanyID() && l.Relationship() == link.Relationship() {
+			em := fmt.Sprintf("%s is already credited with the %s of the film",
+				l.CompanyName(), link.Relationship())
+			logger.Error(m, "error", em)
+			return nil, errors.New(em)
+		}
+	}
+	tx, err := gmcr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	link.SetID(0) // provokes the auto-increment
+	err = tx.Insert(link)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	logger.Info("linked company to film", "link", link.String())
+	return link, nil
+}
+
+// RemoveLink deletes the link between a company and a film with the given uint64
+// ID.  The function returns the row count and error that the database supplies to
+// it.  On a successful delete, it should return 1, having deleted one row.
+func (gmcr GorpMysqlRepo) RemoveLink(id uint64) (int64, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "RemoveLink()"
+	logger.Debug(m, "id", id)
+	// Need a Link record for the delete method, so fake one up.
+	var link gorpCompanyModel.GorpMysqlLink
+	link.SetID(id)
+	tx, err := gmcr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	rowsDeleted, err := tx.Delete(&link)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if rowsDeleted != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("delete failed - %d rows would have been deleted, expected 1", rowsDeleted)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
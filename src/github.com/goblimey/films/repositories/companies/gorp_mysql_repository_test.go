@@ -0,0 +1,175 @@
+package companies
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"testing"
+
+	companyModel "github.com/goblimey/films/models/company"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film/gorpmysql"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	dbsession "github.com/goblimey/films/utilities/dbsession"
+)
+
+// This is an integration test for the GorpMysqlRepo connecting to a MySQL DB via GORP.
+// The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.
+
+var expectedName = "Ealing Studios"
+var expectedCountry = "UK"
+var expectedFounded = 1902
+
+// Create a company, update it, link it to two films and check that its films come
+// back in order of release year, then delete it and check that the links have gone
+// too.
+func TestIntCreateCompanyAndLinkFilms(t *testing.T) {
+	log.SetPrefix("TestIntCreateCompanyAndLinkFilms")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+
+	company, err := repo.Create(gorpCompanyModel.MakeInitialisedCompany(0, expectedName,
+		expectedCountry, expectedFounded, 0))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	company.SetDefunct(1959)
+	_, err = repo.Update(company)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	fetched, err := repo.FindByIDStr(strconv.FormatUint(company.ID(), 10))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if fetched.Name() != expectedName || fetched.Country() != expectedCountry ||
+		fetched.Founded() != expectedFounded || fetched.Defunct() != 1959 {
+		t.Errorf("expected %s, %s, %d, 1959 actually %s", expectedName, expectedCountry,
+			expectedFounded, fetched.String())
+	}
+
+	film1, err := films.Create(filmModel.MakeInitialisedFilm(0, "The Ladykillers", 1955, 91, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(film1.ID())
+	film2, err := films.Create(filmModel.MakeInitialisedFilm(0, "Kind Hearts and Coronets", 1949, 106, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(film2.ID())
+
+	for _, film := range []uint64{film1.ID(), film2.ID()} {
+		_, err = repo.AddLink(gorpCompanyModel.MakeInitialisedLink(0, film, company.ID(),
+			companyModel.RelationshipProduction))
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	// The same relationship can't be recorded twice.
+	_, err = repo.AddLink(gorpCompanyModel.MakeInitialisedLink(0, film1.ID(), company.ID(),
+		companyModel.RelationshipProduction))
+	if err == nil {
+		t.Errorf("expected the second production link to be refused")
+	}
+
+	links, err := repo.FindLinksByCompany(company.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(links) != 2 {
+		t.Fatalf("expected 2 films for the company, actual %d", len(links))
+	}
+	if links[0].FilmTitle() != "Kind Hearts and Coronets" {
+		t.Errorf("expected Kind Hearts and Coronets first, actually %s", links[0].FilmTitle())
+	}
+	links, err = repo.FindLinksByFilm(film1.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(links) != 1 || links[0].CompanyName() != expectedName {
+		t.Fatalf("expected the film to be linked to %s, actually %v", expectedName, links)
+	}
+	linkID := links[0].ID()
+
+	rows, err := repo.DeleteByID(company.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected one company to be deleted, actually %d", rows)
+	}
+	_, err = repo.FindLinkByID(linkID)
+	if err == nil {
+		t.Errorf("expected the link to be deleted along with the company")
+	}
+}
+
+// Link a company to a film, remove the link and check that it's gone, then delete
+// the film and check that the company's other link goes with it.
+func TestIntRemoveLinkAndDeleteFilm(t *testing.T) {
+	log.SetPrefix("TestIntRemoveLinkAndDeleteFilm")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+
+	company, err := repo.Create(gorpCompanyModel.MakeInitialisedCompany(0, "Rank Organisation",
+		"UK", 1937, 0))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer repo.DeleteByID(company.ID())
+	film, err := films.Create(filmModel.MakeInitialisedFilm(0, "Brief Encounter", 1945, 86, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	production, err := repo.AddLink(gorpCompanyModel.MakeInitialisedLink(0, film.ID(),
+		company.ID(), companyModel.RelationshipProduction))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.AddLink(gorpCompanyModel.MakeInitialisedLink(0, film.ID(),
+		company.ID(), companyModel.RelationshipDistribution))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	rows, err := repo.RemoveLink(production.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected one link to be deleted, actually %d", rows)
+	}
+	links, err := repo.FindLinksByFilm(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(links) != 1 || links[0].Relationship() != companyModel.RelationshipDistribution {
+		t.Fatalf("expected just the distribution link, actually %v", links)
+	}
+
+	_, err = films.DeleteByID(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	links, err = repo.FindLinksByCompany(company.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(links) != 0 {
+		t.Errorf("expected no links after the film was deleted, actually %d", len(links))
+	}
+}
@@ -0,0 +1,98 @@
+package companies
+
+import (
+	"context"
+
+	companyModel "github.com/goblimey/films/models/company"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// Repository is the interface defining a repository (AKA a Data Access Object) for
+// the companies table and the film_companies table, which links companies to the
+// films that they made or distributed.
+type Repository interface {
+	SetSession(session dbsession.DBSession)
+
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		FindAll returns all of the companies in order of name.
+	*/
+	FindAll() ([]companyModel.Company, error)
+
+	/*
+		FindByID fetches the row from the companies table with the given uint64 id.
+	*/
+	FindByID(id uint64) (companyModel.Company, error)
+
+	/*
+		FindByIDStr fetches the row from the companies table with the given string
+		id.  The ID in the database is numeric and the method checks that the given
+		ID is also numeric before it makes the call.  If not, it returns an error.
+	*/
+	FindByIDStr(idStr string) (companyModel.Company, error)
+
+	/*
+		Create takes a company and creates a record in the companies table containing
+		the same data and with an auto-incremented ID.  It returns the resulting
+		company or any error that the DB call supplies to it.
+	*/
+	Create(company companyModel.Company) (companyModel.Company, error)
+
+	/*
+		Update takes a company, updates the record in the companies table with the
+		same ID and returns the row count and error that the DB call supplies to it.
+		On a successful update, the number of rows returned should be 1.
+	*/
+	Update(company companyModel.Company) (uint64, error)
+
+	/*
+	 * DeleteByID takes the given uint64 ID and deletes the record with that ID from the
+	 * companies table, along with the company's links to films.  The method returns the
+	 * row count and error that the database supplies to it.  On a successful delete, it
+	 * should return 1, having deleted one company.
+	 */
+	DeleteByID(id uint64) (int64, error)
+
+	/*
+	 * DeleteByIDStr takes the given String ID and deletes the record with that ID from the
+	 * companies table.  The ID in the database is numeric and the method checks that the
+	 * given ID is also numeric before it makes the call.  If not, it returns an error.
+	 */
+	DeleteByIDStr(idStr string) (int64, error)
+
+	/*
+		FindLinkByID fetches the link between a company and a film with the given
+		uint64 id, along with the film title and the company name.
+	*/
+	FindLinkByID(id uint64) (companyModel.Link, error)
+
+	/*
+		FindLinksByCompany returns the links between the company with the given ID
+		and its films - its filmography - in order of release year.
+	*/
+	FindLinksByCompany(companyID uint64) ([]companyModel.Link, error)
+
+	/*
+		FindLinksByFilm returns the links between the film with the given ID and the
+		companies that made or distributed it, in order of relationship and name.
+	*/
+	FindLinksByFilm(filmID uint64) ([]companyModel.Link, error)
+
+	/*
+		AddLink takes a link and creates a record in the film_companies table
+		containing the same data and with an auto-incremented ID.  If the company
+		already has that relationship with the film, it returns an error.
+	*/
+	AddLink(link companyModel.Link) (companyModel.Link, error)
+
+	/*
+		RemoveLink deletes the link between a company and a film with the given
+		uint64 ID.  On a successful delete, it should return 1.
+	*/
+	RemoveLink(id uint64) (int64, error)
+}
@@ -0,0 +1,200 @@
+// Package credits provides Create, Read, Update and Delete (CRUD) operations on the
+// credits resource, which records a person's role on a film.  That resource is
+// referenced via a database session that is supplied by the parent.
+//
+// The GorpMysqlRepo satisfies the Repository interface.
+package credits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	creditModel "github.com/goblimey/films/models/credit"
+	gorpCreditModel "github.com/goblimey/films/models/credit/gorpmysql"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// GorpMysqlRepo satifies the Repository interface.
+type GorpMysqlRepo struct {
+	session dbsession.DBSession
+	ctx     context.Context
+}
+
+// MakeRepo is a factory function that creates a GorpMysqlRepo and returns it as a
+// Repository.
+func MakeRepo(session dbsession.DBSession) Repository {
+	return &GorpMysqlRepo{session: session}
+}
+
+// SetSession sets the session.
+func (gmcr *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
+	gmcr.session = session
+}
+
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmcr GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmcr.ctx = ctx
+	gmcr.session = gmcr.session.WithContext(ctx)
+	return &gmcr
+}
+
+// FindByID fetches the row from the credits table with the given uint64 id.
+func (gmcr GorpMysqlRepo) FindByID(id uint64) (creditModel.Credit, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindByID()"
+	logger.Debug(m, "id", id)
+	return gmcr.session.FindCreditByID(id)
+}
+
+// FindByIDStr fetches the row from the credits table with the given string id.  The
+// method checks that the given ID is numeric before it makes the call.
+func (gmcr GorpMysqlRepo) FindByIDStr(idStr string) (creditModel.Credit, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindByIDStr()"
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
+		logger.Error(m, "error", em)
+		return nil, errors.New(em)
+	}
+	return gmcr.FindByID(id)
+}
+
+// FindByPerson returns the credits of the person with the given ID.
+func (gmcr GorpMysqlRepo) FindByPerson(personID uint64) ([]creditModel.Credit, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindByPerson()"
+	logger.Debug(m, "person_id", personID)
+	return gmcr.session.FindCreditsByPerson(personID)
+}
+
+// FindByFilm returns the credits on the film with the given ID.
+func (gmcr GorpMysqlRepo) FindByFilm(filmID uint64) ([]creditModel.Credit, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "FindByFilm()"
+	logger.Debug(m, "film_id", filmID)
+	return gmcr.session.FindCreditsByFilm(filmID)
+}
+
+// Create takes a credit, creates a record in the credits table containing the same
+// data with an auto-incremented ID and returns any error that the DB call returns.
+// On a successful create, the method returns the created credit, including the
+// assigned ID.  This is all done within a transaction to ensure atomicity.
+func (gmcr GorpMysqlRepo) Create(credit creditModel.Credit) (creditModel.Credit, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "Create()"
+	logger.Debug(m)
+	tx, err := gmcr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	credit.SetID(0) // provokes the auto-increment
+	err = tx.Insert(credit)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	logger.Info("created credit", "credit", credit.String())
+	return credit, nil
+}
+
+// Update takes a credit record, updates the record in the credits table with the
+// same ID and returns the row count or any error that the DB call supplies to it.
+// The update is done within a transaction.
+func (gmcr GorpMysqlRepo) Update(credit creditModel.Credit) (uint64, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "Update()"
+	tx, err := gmcr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	rowsUpdated, err := tx.Update(credit)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if rowsUpdated != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("update failed - %d rows would have been updated, expected 1", rowsUpdated)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+
+	// Success!
+	return 1, nil
+}
+
+// DeleteByID takes the given uint64 ID and deletes the record with that ID from the
+// credits table.  The function returns the row count and error that the database
+// supplies to it.  On a successful delete, it should return 1, having deleted one row.
+func (gmcr GorpMysqlRepo) DeleteByID(id uint64) (int64, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "DeleteByID()"
+	logger.Debug(m, "id", id)
+	// Need a Credit record for the delete method, so fake one up.
+	var credit gorpCreditModel.GorpMysqlCredit
+	credit.SetID(id)
+	tx, err := gmcr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	rowsDeleted, err := tx.Delete(&credit)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if rowsDeleted != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("delete failed - %d rows would have been deleted, expected 1", rowsDeleted)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
+
+// DeleteByIDStr takes the given String ID and deletes the record with that ID from
+// the credits table.  The method checks that the given ID is numeric before it makes
+// the call.  If not, it returns an error.
+func (gmcr GorpMysqlRepo) DeleteByIDStr(idStr string) (int64, error) {
+	logger := logging.FromContext(gmcr.ctx)
+	m := "DeleteByIDStr()"
+	logger.Debug(m, "id", idStr)
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+	return gmcr.DeleteByID(id)
+}
@@ -0,0 +1,221 @@
+package credits
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"testing"
+
+	creditModel "github.com/goblimey/films/models/credit"
+	gorpCreditModel "github.com/goblimey/films/models/credit/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film/gorpmysql"
+	personModel "github.com/goblimey/films/models/person/gorpmysql"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	dbsession "github.com/goblimey/films/utilities/dbsession"
+)
+
+// This is an integration test for the GorpMysqlRepo connecting to a MySQL DB via GORP.
+// The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.
+
+var expectedCharacter = "Harry Lime"
+var expectedBilling = 2
+
+// Credit a person on a film, read the credit back from both sides and check the
+// contents, then delete the person and check that the credit has gone too.
+func TestIntCreateCreditAndFetchFromBothSides(t *testing.T) {
+	log.SetPrefix("TestIntCreateCreditAndFetchFromBothSides")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	people := peopleRepo.MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+
+	person, err := people.Create(personModel.MakeInitialisedPerson(0, "Orson", "Welles"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	film, err := films.Create(filmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(film.ID())
+
+	c := gorpCreditModel.MakeInitialisedCredit(0, person.ID(), film.ID(),
+		creditModel.RoleActor, expectedCharacter, expectedBilling)
+	credit, err := repo.Create(c)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// The person's filmography.
+	credits, err := repo.FindByPerson(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(credits) != 1 {
+		t.Fatalf("expected 1 credit for the person, actual %d", len(credits))
+	}
+	if credits[0].FilmTitle() != "The Third Man" {
+		t.Errorf("expected film title to be The Third Man actually %s",
+			credits[0].FilmTitle())
+	}
+	if credits[0].Character() != expectedCharacter {
+		t.Errorf("expected character to be %s actually %s", expectedCharacter,
+			credits[0].Character())
+	}
+
+	// The film's cast.
+	credits, err = repo.FindByFilm(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(credits) != 1 {
+		t.Fatalf("expected 1 credit on the film, actual %d", len(credits))
+	}
+	if credits[0].PersonSurname() != "Welles" {
+		t.Errorf("expected surname to be Welles actually %s", credits[0].PersonSurname())
+	}
+	if credits[0].Billing() != expectedBilling {
+		t.Errorf("expected billing to be %d actually %d", expectedBilling,
+			credits[0].Billing())
+	}
+
+	// Deleting the person moves them to the trash.  The credit is kept, in case
+	// they are restored, but it's not shown on the film.
+	_, err = people.DeleteByID(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.FindByID(credit.ID())
+	if err != nil {
+		t.Errorf("expected the credit to be kept while the person is in the trash")
+	}
+	credits, err = repo.FindByFilm(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(credits) != 0 {
+		t.Errorf("expected no credits on the film, actually %d", len(credits))
+	}
+
+	// Purging the person removes the credit.
+	_, err = people.Purge(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.FindByID(credit.ID())
+	if err == nil {
+		t.Errorf("expected the credit to be deleted along with the person")
+	}
+}
+
+// Create a credit, delete it by its string ID and check that it's gone.
+func TestIntCreateCreditAndDeleteByIDStr(t *testing.T) {
+	log.SetPrefix("TestIntCreateCreditAndDeleteByIDStr")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	people := peopleRepo.MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+
+	person, err := people.Create(personModel.MakeInitialisedPerson(0, "Carol", "Reed"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer people.DeleteByID(person.ID())
+	film, err := films.Create(filmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(film.ID())
+
+	c := gorpCreditModel.MakeInitialisedCredit(0, person.ID(), film.ID(),
+		creditModel.RoleDirector, "", 0)
+	credit, err := repo.Create(c)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	rows, err := repo.DeleteByIDStr(strconv.FormatUint(credit.ID(), 10))
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected one record to be deleted, actually %d", rows)
+	}
+
+	credits, err := repo.FindByFilm(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(credits) != 0 {
+		t.Errorf("expected no credits on the film, actual %d", len(credits))
+	}
+}
+
+// Delete a film that credits a person in the trash and check that the credit goes
+// with it.
+func TestIntDeleteFilmCreditingPersonInTrash(t *testing.T) {
+	log.SetPrefix("TestIntDeleteFilmCreditingPersonInTrash")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	people := peopleRepo.MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+
+	person, err := people.Create(personModel.MakeInitialisedPerson(0, "Trevor", "Howard"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer people.Purge(person.ID())
+	film, err := films.Create(filmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	credit, err := repo.Create(gorpCreditModel.MakeInitialisedCredit(0, person.ID(), film.ID(),
+		creditModel.RoleActor, "Major Calloway", 3))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = people.DeleteByID(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = films.DeleteByID(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = people.Restore(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = people.DeleteByID(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = session.FindCreditByID(credit.ID())
+	if err == nil {
+		t.Errorf("expected the credit to be deleted along with the film")
+	}
+	credits, err := session.FindCreditsByPerson(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(credits) != 0 {
+		t.Errorf("expected no credits for the person, actual %d", len(credits))
+	}
+}
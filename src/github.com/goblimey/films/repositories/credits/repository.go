@@ -0,0 +1,73 @@
+package credits
+
+import (
+	"context"
+
+	creditModel "github.com/goblimey/films/models/credit"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// Repository is the interface defining a repository (AKA a Data Access Object) for
+// the credits table, which links people to films.
+type Repository interface {
+	SetSession(session dbsession.DBSession)
+
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		FindByID fetches the row from the credits table with the given uint64 id,
+		along with the film title and the person's name.
+	*/
+	FindByID(id uint64) (creditModel.Credit, error)
+
+	/*
+		FindByIDStr fetches the row from the credits table with the given string id.
+		The ID in the database is numeric and the method checks that the given ID is
+		also numeric before it makes the call.  If not, it returns an error.
+	*/
+	FindByIDStr(idStr string) (creditModel.Credit, error)
+
+	/*
+		FindByPerson returns the credits of the person with the given ID - their
+		filmography - in order of release year.
+	*/
+	FindByPerson(personID uint64) ([]creditModel.Credit, error)
+
+	/*
+		FindByFilm returns the credits on the film with the given ID - the cast
+		and crew - in order of role and billing.
+	*/
+	FindByFilm(filmID uint64) ([]creditModel.Credit, error)
+
+	/*
+		Create takes a credit and creates a record in the credits table containing the
+		same data and with an auto-incremented ID.  It returns the resulting credit or
+		any error that the DB call supplies to it.
+	*/
+	Create(credit creditModel.Credit) (creditModel.Credit, error)
+
+	/*
+		Update takes a credit, updates the record in the credits table with the same ID
+		and returns the row count and error that the DB call supplies to it.  On a
+		successful update, the number of rows returned should be 1.
+	*/
+	Update(credit creditModel.Credit) (uint64, error)
+
+	/*
+	 * DeleteByID takes the given uint64 ID and deletes the record with that ID from the
+	 * credits table.  The method returns the row count and error that the database supplies
+	 * to it.  On a successful delete, it should return 1, having deleted one row.
+	 */
+	DeleteByID(id uint64) (int64, error)
+
+	/*
+	 * DeleteByIDStr takes the given String ID and deletes the record with that ID from the
+	 * credits table.  The ID in the database is numeric and the method checks that the given
+	 * ID is also numeric before it makes the call.  If not, it returns an error.
+	 */
+	DeleteByIDStr(idStr string) (int64, error)
+}
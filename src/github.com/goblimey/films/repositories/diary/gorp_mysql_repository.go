@@ -0,0 +1,131 @@
+// Package diary provides operations on the users' diaries - the films that each
+// user has watched, when they watched them, and optionally what they thought.
+// The diary table is referenced via a database session that is supplied by the
+// parent.
+//
+// The GorpMysqlRepo satisfies the Repository interface.
+package diary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	diaryModel "github.com/goblimey/films/models/diary"
+	gorpDiaryModel "github.com/goblimey/films/models/diary/gorpmysql"
+	reviewModel "github.com/goblimey/films/models/review"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// GorpMysqlRepo satifies the Repository interface.
+type GorpMysqlRepo struct {
+	session dbsession.DBSession
+	ctx     context.Context
+}
+
+// MakeRepo is a factory function that creates a GorpMysqlRepo and returns it as a
+// Repository.
+func MakeRepo(session dbsession.DBSession) Repository {
+	return &GorpMysqlRepo{session: session}
+}
+
+// SetSession sets the session.
+func (gmdr *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
+	gmdr.session = session
+}
+
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmdr GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmdr.ctx = ctx
+	gmdr.session = gmdr.session.WithContext(ctx)
+	return &gmdr
+}
+
+// FindByID fetches the diary entry with the given uint64 id.
+func (gmdr GorpMysqlRepo) FindByID(id uint64) (diaryModel.Entry, error) {
+	logger := logging.FromContext(gmdr.ctx)
+	m := "FindByID()"
+	logger.Debug(m, "id", id)
+	return gmdr.session.FindDiaryEntryByID(id)
+}
+
+// FindByUser returns the diary of the user with the given ID.
+func (gmdr GorpMysqlRepo) FindByUser(userID uint64) ([]diaryModel.Entry, error) {
+	logger := logging.FromContext(gmdr.ctx)
+	m := "FindByUser()"
+	logger.Debug(m, "user_id", userID)
+	return gmdr.session.FindDiaryByUser(userID)
+}
+
+// Create records a viewing in the user's diary.
+func (gmdr GorpMysqlRepo) Create(entry diaryModel.Entry) (diaryModel.Entry, error) {
+	logger := logging.FromContext(gmdr.ctx)
+	m := "Create()"
+	logger.Debug(m, "entry", entry.String())
+	if entry.Rating() != 0 && !reviewModel.ValidRating(entry.Rating()) {
+		em := fmt.Sprintf("the rating must be between %d and %d",
+			reviewModel.MinRating, reviewModel.MaxRating)
+		logger.Error(m, "error", em)
+		return nil, errors.New(em)
+	}
+	tx, err := gmdr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	entry.SetID(0) // provokes the auto-increment
+	err = tx.Insert(entry)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	logger.Info("recorded viewing", "entry", entry.String())
+	return entry, nil
+}
+
+// DeleteByID deletes the diary entry with the given uint64 ID.
+func (gmdr GorpMysqlRepo) DeleteByID(id uint64) (int64, error) {
+	logger := logging.FromContext(gmdr.ctx)
+	m := "DeleteByID()"
+	logger.Debug(m, "id", id)
+	// Need an Entry record for the delete method, so fake one up.
+	var entry gorpDiaryModel.GorpMysqlEntry
+	entry.SetID(id)
+	tx, err := gmdr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	rowsDeleted, err := tx.Delete(&entry)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if rowsDeleted != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("delete failed - %d rows would have been deleted, expected 1", rowsDeleted)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
@@ -0,0 +1,116 @@
+package diary
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	gorpDiaryModel "github.com/goblimey/films/models/diary/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film/gorpmysql"
+	userModel "github.com/goblimey/films/models/user"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	dbsession "github.com/goblimey/films/utilities/dbsession"
+)
+
+// This is an integration test for the GorpMysqlRepo connecting to a MySQL DB via GORP.
+// The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.
+
+// A user logs two viewings of the same film and one of another.  The diary
+// should list them most recent first.  Deleting a film deletes its entries.
+func TestIntKeepDiary(t *testing.T) {
+	log.SetPrefix("TestIntKeepDiary")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+	users := usersRepo.MakeRepo(session)
+
+	psycho, err := films.Create(filmModel.MakeInitialisedFilm(0, "Psycho", 1960, 109, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(psycho.ID())
+	birds, err := films.Create(filmModel.MakeInitialisedFilm(0, "The Birds", 1963, 119, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(birds.ID())
+	vera, err := users.Add(fmt.Sprintf("vera%d", time.Now().UnixNano()), "pw12345678",
+		userModel.RoleViewer)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)
+	}
+	first, err := repo.Create(gorpDiaryModel.MakeInitialisedEntry(0, vera.ID(), psycho.ID(),
+		day(1), 0, false))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.Create(gorpDiaryModel.MakeInitialisedEntry(0, vera.ID(), psycho.ID(),
+		day(20), 9, true))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.Create(gorpDiaryModel.MakeInitialisedEntry(0, vera.ID(), birds.ID(),
+		day(10), 6, false))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	// A rating out of range is refused.
+	_, err = repo.Create(gorpDiaryModel.MakeInitialisedEntry(0, vera.ID(), birds.ID(),
+		day(11), 11, false))
+	if err == nil {
+		t.Errorf("expected a rating of 11 to be refused")
+	}
+
+	entries, err := repo.FindByUser(vera.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if !entries[0].WatchedOn().Equal(day(20)) || !entries[0].Rewatch() ||
+		entries[0].Rating() != 9 || entries[0].FilmTitle() != "Psycho" {
+		t.Errorf("expected the rewatch of Psycho first, got %s", entries[0].String())
+	}
+	if entries[1].FilmTitle() != "The Birds" || entries[2].ID() != first.ID() {
+		t.Errorf("expected The Birds then the first viewing of Psycho, got %s and %s",
+			entries[1].String(), entries[2].String())
+	}
+
+	rows, err := repo.DeleteByID(first.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected 1 row deleted, got %d", rows)
+	}
+	_, err = repo.FindByID(first.ID())
+	if err == nil {
+		t.Errorf("expected the first viewing to be gone")
+	}
+
+	// Deleting a film deletes its entries.
+	_, err = films.DeleteByID(psycho.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	entries, err = repo.FindByUser(vera.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(entries) != 1 || entries[0].FilmID() != birds.ID() {
+		t.Errorf("expected just The Birds, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,44 @@
+package diary
+
+import (
+	"context"
+
+	diaryModel "github.com/goblimey/films/models/diary"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// Repository is the interface for the diary repository, which holds the films
+// that each user has watched and when.
+type Repository interface {
+	SetSession(session dbsession.DBSession)
+
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		FindByID fetches the diary entry with the given uint64 id, along with the
+		title of the film.
+	*/
+	FindByID(id uint64) (diaryModel.Entry, error)
+
+	/*
+		FindByUser returns the diary of the user with the given ID, most recent
+		viewing first.
+	*/
+	FindByUser(userID uint64) ([]diaryModel.Entry, error)
+
+	/*
+		Create records a viewing in the user's diary.  The rating must be 0 (not
+		rated) or a valid rating.  It returns the entry, including its ID.
+	*/
+	Create(entry diaryModel.Entry) (diaryModel.Entry, error)
+
+	/*
+		DeleteByID deletes the diary entry with the given uint64 ID.  On a
+		successful delete, it should return 1.
+	*/
+	DeleteByID(id uint64) (int64, error)
+}
@@ -0,0 +1,350 @@
+// Package films provides Create, Read, Update and Delete (CRUD) operations on the
+// films resource.  That resource is referenced via a database session that is
+// supplied by the parent.  For example it could be a MySQL table accessed via GORP,
+// but it could also be a mock session.
+//
+// The GorpMysqlRepo satisfies the Repository interface.
+package films
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	filmModel "github.com/goblimey/films/models/film"
+	gorpFilmModel "github.com/goblimey/films/models/film/gorpmysql"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+	"github.com/goblimey/films/utilities/search"
+)
+
+// GorpMysqlRepo satifies the Repository interface.
+type GorpMysqlRepo struct {
+	session dbsession.DBSession
+	index   search.Index
+	ctx     context.Context
+}
+
+// MakeRepo is a factory function that creates a GorpMysqlRepo and returns it as a
+// Repository.
+func MakeRepo(session dbsession.DBSession) Repository {
+	return &GorpMysqlRepo{session: session}
+}
+
+// MakeIndexedRepo is a factory function that creates a GorpMysqlRepo which keeps
+// the given search index up to date as films are created, updated and deleted,
+// and returns it as a Repository.
+func MakeIndexedRepo(session dbsession.DBSession, index search.Index) Repository {
+	return &GorpMysqlRepo{session: session, index: index}
+}
+
+// SetSession sets the session.
+func (gmfr *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
+	gmfr.session = session
+}
+
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmfr GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmfr.ctx = ctx
+	gmfr.session = gmfr.session.WithContext(ctx)
+	return &gmfr
+}
+
+// FindAll returns a list of all valid Film records from the database in a slice.
+// The result may be an empty slice.  If the database lookup fails, the error is
+// returned instead.
+func (gmfr GorpMysqlRepo) FindAll() ([]filmModel.Film, error) {
+	logger := logging.FromContext(gmfr.ctx)
+	m := "FindAll()"
+	logger.Debug(m)
+	films, err := gmfr.session.FindAllFilms()
+	return films, err
+}
+
+// FindMatching returns the valid films that match the query, for example the
+// films in a genre, in order of ID.
+func (gmfr GorpMysqlRepo) FindMatching(query dbsession.FilmQuery) ([]filmModel.Film, error) {
+	logger := logging.FromContext(gmfr.ctx)
+	m := "FindMatching()"
+	logger.Debug(m, "genre_id", query.GenreID, "tag_id", query.TagID)
+	return gmfr.session.FindFilms(query)
+}
+
+// FindByID fetches the row from the films table with the given uint64 id. It
+// validates that data and, if it's valid, returns the film.  If the data is not
+// valid the function returns an error message.
+func (gmfr GorpMysqlRepo) FindByID(id uint64) (filmModel.Film, error) {
+	logger := logging.FromContext(gmfr.ctx)
+	m := "FindByID()"
+	logger.Debug(m, "id", id)
+
+	film, err := gmfr.session.FindFilmByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(film.Title())) < 1 {
+		return nil, errors.New("invalid film - no title")
+	}
+	return film, nil
+}
+
+// FindByIDStr fetches the row from the films table with the given string id. It
+// validates that data and, if it's valid, returns the film.  If the data is not valid
+// the function returns an error message.  The ID in the database is numeric and the
+// method checks that the given ID is also numeric before it makes the call.  This
+// avoids hitting the DB when the id is obviously junk.
+func (gmfr GorpMysqlRepo) FindByIDStr(idStr string) (filmModel.Film, error) {
+	logger := logging.FromContext(gmfr.ctx)
+	m := "FindByIDStr()"
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
+		logger.Error(m, "error", em)
+		return nil, errors.New(em)
+	}
+	return gmfr.FindByID(id)
+}
+
+// Create takes a film, creates a record in the films table containing the same
+// data with an auto-incremented ID and returns any error that the DB call returns.
+// On a successful create, the method returns the created film, including the
+// assigned ID.  This is all done within a transaction to ensure atomicity.
+func (gmfr GorpMysqlRepo) Create(film filmModel.Film) (filmModel.Film, error) {
+	logger := logging.FromContext(gmfr.ctx)
+	m := "Create()"
+	logger.Debug(m)
+	tx, err := gmfr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	film.SetID(0) // provokes the auto-increment
+	err = tx.Insert(film)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if gmfr.index != nil {
+		gmfr.index.AddFilm(film)
+	}
+	logger.Info("created film", "film", film.String())
+	return film, nil
+}
+
+// Update takes a film record, updates the record in the films table with the same
+// ID and returns the row count or any error that the DB call supplies to it.  The
+// update is done within a transaction.
+func (gmfr GorpMysqlRepo) Update(film filmModel.Film) (uint64, error) {
+	logger := logging.FromContext(gmfr.ctx)
+	m := "Update()"
+	tx, err := gmfr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	rowsUpdated, err := tx.Update(film)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if rowsUpdated != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("update failed - %d rows would have been updated, expected 1", rowsUpdated)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+
+	if gmfr.index != nil {
+		gmfr.index.AddFilm(film)
+	}
+
+	// Success!
+	return 1, nil
+}
+
+// DeleteByID takes the given uint64 ID and deletes the record with that ID from the
+// films table.  The function returns the row count and error that the database
+// supplies to it.  On a successful delete, it should return 1, having deleted one row.
+// Its genres and tags, its credits, its links to companies, its reviews and
+// ratings, its entries in the users' watchlists and diaries and its award
+// nominations are found and deleted within the same transaction.
+func (gmfr GorpMysqlRepo) DeleteByID(id uint64) (int64, error) {
+	logger := logging.FromContext(gmfr.ctx)
+	m := "DeleteByID()"
+	logger.Debug(m, "id", id)
+	// Need a Film record for the delete method, so fake one up.
+	var film gorpFilmModel.GorpMysqlFilm
+	film.SetID(id)
+	tx, err := gmfr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	// Find the records that refer to the film within the transaction, so that
+	// none is left pointing at a missing record.
+	links, err := taxonomyLinks(tx, id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	companyLinks, err := tx.FindAllCompanyLinksByFilm(id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	for _, link := range companyLinks {
+		links = append(links, link)
+	}
+	reviews, err := tx.FindAllReviewsByFilm(id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	for _, review := range reviews {
+		links = append(links, review)
+	}
+	summary, err := tx.FindRatingSummary(id)
+	if err == nil {
+		links = append(links, summary)
+	} else if err != sql.ErrNoRows {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	watchlist, err := tx.FindAllWatchlistByFilm(id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	for _, entry := range watchlist {
+		links = append(links, entry)
+	}
+	diary, err := tx.FindAllDiaryByFilm(id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	for _, entry := range diary {
+		links = append(links, entry)
+	}
+	// The credits and award nominations include those of the people in the trash.
+	credits, err := tx.FindAllCreditsByFilm(id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	nominations, err := tx.FindAllNominationsByFilm(id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	for _, nomination := range nominations {
+		links = append(links, nomination)
+	}
+	for _, credit := range credits {
+		_, err = tx.Delete(credit)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(m, "error", err)
+			return 0, err
+		}
+	}
+	if len(links) > 0 {
+		_, err = tx.Delete(links...)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(m, "error", err)
+			return 0, err
+		}
+	}
+	rowsDeleted, err := tx.Delete(&film)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if rowsDeleted != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("delete failed - %d rows would have been deleted, expected 1", rowsDeleted)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if gmfr.index != nil {
+		gmfr.index.Remove(search.KindFilm, id)
+	}
+	return rowsDeleted, nil
+}
+
+// DeleteByIDStr takes the given String ID and deletes the record with that ID from the
+// films table.  The ID in the database is numeric and the method checks that the given
+// ID is also numeric before it makes the call.  If not, it returns an error.  If the ID
+// looks sensible, the function attempts the delete and returns the row count and error
+// that the database supplies to it.  On a successful delete, it should return 1.
+func (gmfr GorpMysqlRepo) DeleteByIDStr(idStr string) (int64, error) {
+	logger := logging.FromContext(gmfr.ctx)
+	m := "DeleteByIDStr()"
+	logger.Debug(m, "id", idStr)
+	// Check the id.
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+	return gmfr.DeleteByID(id)
+}
+
+// taxonomyLinks returns the links that attach genres and tags to the film with
+// the given ID, found within the given transaction.
+func taxonomyLinks(tx dbsession.Transaction, id uint64) ([]interface{}, error) {
+	genreLinks, err := tx.FindGenreLinks(dbsession.SubjectFilm, id)
+	if err != nil {
+		return nil, err
+	}
+	tagLinks, err := tx.FindTagLinks(dbsession.SubjectFilm, id)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]interface{}, 0, len(genreLinks)+len(tagLinks))
+	for _, link := range genreLinks {
+		links = append(links, link)
+	}
+	for _, link := range tagLinks {
+		links = append(links, link)
+	}
+	return links, nil
+}
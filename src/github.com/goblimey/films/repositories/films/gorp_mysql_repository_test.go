@@ -0,0 +1,219 @@
+package films
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"testing"
+
+	filmModel "github.com/goblimey/films/models/film/gorpmysql"
+	dbsession "github.com/goblimey/films/utilities/dbsession"
+)
+
+// This is an integration test for the GorpMysqlRepo connecting to a MySQL DB via GORP.
+// The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.
+
+var expectedTitle1 = "The Third Man"
+var expectedReleaseYear1 = 1949
+var expectedRuntime1 = 104
+var expectedSynopsis1 = "Holly Martins arrives in Vienna."
+var expectedTitle2 = "Brief Encounter"
+var expectedReleaseYear2 = 1945
+var expectedRuntime2 = 86
+var expectedSynopsis2 = "A chance meeting at a railway station."
+
+// Create a film in the database, read it back, test the contents.
+func TestIntCreateFilmStoreFetchBackAndCheckContents(t *testing.T) {
+	log.SetPrefix("TestIntCreateFilmStoreFetchBackAndCheckContents")
+	// Create a repository containing a session
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	repo := MakeRepo(dbsession)
+
+	clearDown(repo, t)
+
+	f := filmModel.MakeInitialisedFilm(0, expectedTitle1, expectedReleaseYear1,
+		expectedRuntime1, expectedSynopsis1)
+
+	// Store the film in the DB
+	film, err := repo.Create(f)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	log.Printf("created film %s\n", film.String())
+
+	retrievedFilm, err := repo.FindByID(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	log.Printf("retrieved film %s\n", retrievedFilm.String())
+
+	if retrievedFilm.ID() != film.ID() {
+		t.Errorf("expected ID to be %d actually %d", film.ID(), retrievedFilm.ID())
+	}
+	if retrievedFilm.Title() != expectedTitle1 {
+		t.Errorf("expected title to be %s actually %s", expectedTitle1,
+			retrievedFilm.Title())
+	}
+	if retrievedFilm.ReleaseYear() != expectedReleaseYear1 {
+		t.Errorf("expected release year to be %d actually %d", expectedReleaseYear1,
+			retrievedFilm.ReleaseYear())
+	}
+	if retrievedFilm.Runtime() != expectedRuntime1 {
+		t.Errorf("expected runtime to be %d actually %d", expectedRuntime1,
+			retrievedFilm.Runtime())
+	}
+	if retrievedFilm.Synopsis() != expectedSynopsis1 {
+		t.Errorf("expected synopsis to be %s actually %s", expectedSynopsis1,
+			retrievedFilm.Synopsis())
+	}
+
+	// Delete film and check response
+	rows, err := repo.DeleteByID(film.ID())
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected delete to return 1, actual %d", rows)
+	}
+	clearDown(repo, t)
+}
+
+// Create two films, remove one, check that we get back just the other
+func TestIntCreateTwoFilmsAndDeleteOneByIDStr(t *testing.T) {
+	log.SetPrefix("TestIntCreateTwoFilmsAndDeleteOneByIDStr")
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	repo := MakeRepo(dbsession)
+
+	clearDown(repo, t)
+
+	f1 := filmModel.MakeInitialisedFilm(0, expectedTitle1, expectedReleaseYear1,
+		expectedRuntime1, expectedSynopsis1)
+	film1, err := repo.Create(f1)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	f2 := filmModel.MakeInitialisedFilm(0, expectedTitle2, expectedReleaseYear2,
+		expectedRuntime2, expectedSynopsis2)
+	film2, err := repo.Create(f2)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	rows, err := repo.DeleteByIDStr(fmt.Sprintf("%d", film1.ID()))
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected one record to be deleted, actually %d", rows)
+	}
+
+	// We should have one record in the DB and it should match film2
+	films, err := repo.FindAll()
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	if len(films) != 1 {
+		t.Errorf("expected one record, actual %d", len(films))
+	}
+
+	for _, film := range films {
+		if film.ID() != film2.ID() {
+			t.Errorf("expected id to be %d actually %d", film2.ID(), film.ID())
+		}
+		if film.Title() != expectedTitle2 {
+			t.Errorf("expected title to be %s actually %s", expectedTitle2, film.Title())
+		}
+	}
+
+	clearDown(repo, t)
+}
+
+// Create a film record, update the record, read it back and check that it's updated
+func TestIntCreateFilmAndUpdate(t *testing.T) {
+	log.SetPrefix("TestIntCreateFilmAndUpdate")
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	repo := MakeRepo(dbsession)
+
+	clearDown(repo, t)
+
+	f := filmModel.MakeInitialisedFilm(0, expectedTitle1, expectedReleaseYear1,
+		expectedRuntime1, expectedSynopsis1)
+	film, err := repo.Create(f)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Update the film in the DB.
+	film.SetTitle(expectedTitle2)
+	film.SetReleaseYear(expectedReleaseYear2)
+	film.SetRuntime(expectedRuntime2)
+	film.SetSynopsis(expectedSynopsis2)
+	rows, err := repo.Update(film)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected 1 row to be updated, actually %d rows", rows)
+	}
+
+	// fetch the updated record back and check it.
+	filmFetched, err := repo.FindByIDStr(strconv.FormatUint(film.ID(), 10))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if filmFetched.Title() != expectedTitle2 {
+		t.Errorf("expected title to be %s actually %s", expectedTitle2, filmFetched.Title())
+	}
+	if filmFetched.ReleaseYear() != expectedReleaseYear2 {
+		t.Errorf("expected release year to be %d actually %d", expectedReleaseYear2,
+			filmFetched.ReleaseYear())
+	}
+	if filmFetched.Runtime() != expectedRuntime2 {
+		t.Errorf("expected runtime to be %d actually %d", expectedRuntime2,
+			filmFetched.Runtime())
+	}
+	if filmFetched.Synopsis() != expectedSynopsis2 {
+		t.Errorf("expected synopsis to be %s actually %s", expectedSynopsis2,
+			filmFetched.Synopsis())
+	}
+
+	clearDown(repo, t)
+}
+
+// clearDown() - helper function to remove all films from the DB
+func clearDown(repo Repository, t *testing.T) {
+	films, err := repo.FindAll()
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	for _, film := range films {
+		rows, err := repo.DeleteByID(film.ID())
+		if err != nil {
+			t.Errorf(err.Error())
+			continue
+		}
+		if rows != 1 {
+			t.Errorf("while clearing down, expected 1 row, actual %d", rows)
+		}
+	}
+}
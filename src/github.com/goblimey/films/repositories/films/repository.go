@@ -0,0 +1,78 @@
+package films
+
+import (
+	"context"
+
+	filmModel "github.com/goblimey/films/models/film"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// Repository is the interface defining a repository (AKA a Data Access Object) for
+// the films table.
+type Repository interface {
+	SetSession(session dbsession.DBSession)
+
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		FindAll() returns a slice of valid Films.  Any invalid records are left
+		out of the slice.
+	*/
+	FindAll() ([]filmModel.Film, error)
+
+	/*
+		FindMatching() returns a slice of the valid Films that match the query, for
+		example the films with a given tag.
+	*/
+	FindMatching(query dbsession.FilmQuery) ([]filmModel.Film, error)
+
+	/*
+		FindByID fetches the row from the films table with the given uint64 id. It validates
+		that data and, if it's valid, returns the Film.  If the data is not valid the function
+		returns an error message.
+	*/
+	FindByID(id uint64) (filmModel.Film, error)
+
+	/*
+		FindByIDStr fetches the row from the films table with the given string id. It
+		validates that data and, if it's valid, returns the Film.  If the data is not valid
+		the function returns an error message.  The ID in the database is numeric and the
+		method checks that the given ID is also numeric before it makes the call.  If not,
+		it returns an error.
+	*/
+	FindByIDStr(idStr string) (filmModel.Film, error)
+
+	/*
+		Create takes a film and creates a record in the films table containing the same
+		data and with an auto-incremented ID.  It returns the resulting film or any error
+		that the DB call supplies to it.
+	*/
+	Create(film filmModel.Film) (filmModel.Film, error)
+
+	/*
+		Update takes a film, updates the record in the films table with the same ID and
+		returns the row count and error that the DB call supplies to it.  On a successful
+		update, the number of rows returned should be 1.
+	*/
+	Update(film filmModel.Film) (uint64, error)
+
+	/*
+	 * DeleteByID takes the given uint64 ID and deletes the record with that ID from the films
+	 * table.  The method returns the row count and error that the database supplies to it.  On
+	 * a successful delete, it should return 1, having deleted one row.
+	 */
+	DeleteByID(id uint64) (int64, error)
+
+	/*
+	 * DeleteByIDStr takes the given String ID and deletes the record with that ID from the films
+	 * table. The ID in the database is numeric and the method checks that the given ID is also
+	 * numeric before it makes the call.  If not, it returns an error.  If the ID looks sensible,
+	 * the function attempts the delete and returns the row count and error that the database
+	 * supplies to it.  On a successful delete, it should return 1, having deleted one row.
+	 */
+	DeleteByIDStr(idStr string) (int64, error)
+}
@@ -3,30 +3,89 @@
 // supplied by the parent.  For example it could be a MySQL table accessed via GORP,
 // but it could also be a mock session.
 //
+// Every change to a person is recorded in the audit log, in the same transaction as
+// the change itself, so the log can't miss a change or record one that didn't
+// happen.
+//
+// Deleting a person moves them to the trash, from where they can be restored.  The
+// finders don't see people in the trash.  Purging a person removes them and their
+// credits for good.
+//
 // The GorpMysqlRepo satisfies the DAO interface.
 package people
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
+	"time"
 
+	auditModel "github.com/goblimey/films/models/audit"
+	gorpAuditModel "github.com/goblimey/films/models/audit/gorpmysql"
 	personModel "github.com/goblimey/films/models/person"
-	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
 	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+	"github.com/goblimey/films/utilities/search"
 )
 
+// ErrConflict is returned by Update and DeleteByID when the person has been
+// changed by someone else since the given version was read.
+var ErrConflict = errors.New("the person has been changed by someone else")
+
+// ErrNoSuchEntry is returned by Revert when the person has no audit log entry with
+// the given ID.
+var ErrNoSuchEntry = errors.New("no such entry in the person's history")
+
+// ErrCannotRevert is returned by Revert when the audit log entry doesn't hold a
+// version of the person that can be restored, because it records a delete.
+var ErrCannotRevert = errors.New("cannot revert to a deleted person")
+
+// ErrNotInTrash is returned by Restore and Purge when there is no person with the
+// given ID in the trash.
+var ErrNotInTrash = errors.New("the person is not in the trash")
+
+// TableName is the name of the table holding the people, as recorded in the audit
+// log.
+const TableName = "people"
+
 // GorpMysqlRepo satifies the Repository interface.
 type GorpMysqlRepo struct {
 	session dbsession.DBSession
+	index   search.Index
+	user    string
+	ctx     context.Context
 }
 
 // MakeDAO is a factory function that creates a GorpMysqlRepo and returns it as a
 // Repository.
 func MakeRepo(session dbsession.DBSession) Repository {
-	return &GorpMysqlRepo{session}
+	return &GorpMysqlRepo{session: session}
+}
+
+// MakeIndexedRepo is a factory function that creates a GorpMysqlRepo which keeps
+// the given search index up to date as people are created, updated and deleted,
+// and returns it as a Repository.
+func MakeIndexedRepo(session dbsession.DBSession, index search.Index) Repository {
+	return &GorpMysqlRepo{session: session, index: index}
+}
+
+// MakeMemoryRepo is a factory function that creates a Repository which holds the
+// people in memory, for running without a database server.  It's a GorpMysqlRepo
+// with an in-memory session, so it validates the data and checks the row counts in
+// the same way.
+func MakeMemoryRepo() Repository {
+	return &GorpMysqlRepo{session: dbsession.MakeMemoryDBSession()}
+}
+
+// WithUser returns a copy of the repository that records the given user in the
+// audit log as the person making the changes.  The original is not affected, so a
+// repository shared by all requests can be given the user of each one.
+func (gmpd GorpMysqlRepo) WithUser(user string) Repository {
+	gmpd.user = user
+	return &gmpd
 }
 
 // SetSession sets the session.
@@ -34,22 +93,43 @@ func (gmpd *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
 	gmpd.session = session
 }
 
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmpd GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmpd.ctx = ctx
+	gmpd.session = gmpd.session.WithContext(ctx)
+	return &gmpd
+}
+
 // FindAll returns a list of all valid Person records from the database in a slice.
 // The result may be an empty slice.  If the database lookup fails, the error is
 // returned instead.
 func (gmpd GorpMysqlRepo) FindAll() ([]personModel.Person, error) {
+	logger := logging.FromContext(gmpd.ctx)
 	m := "FindAll()"
-	log.Printf("%s:\n", m)
+	logger.Debug(m)
 	people, err := gmpd.session.FindAllPeople()
 	return people, err
 }
 
+// FindPage returns one page of the valid Person records from the database, filtered
+// and sorted as the query says, and the number of valid records that match the
+// filter.  If the database lookup fails, the error is returned instead.
+func (gmpd GorpMysqlRepo) FindPage(query dbsession.PeopleQuery) ([]personModel.Person, int64, error) {
+	logger := logging.FromContext(gmpd.ctx)
+	m := "FindPage()"
+	logger.Debug(m, "query", fmt.Sprintf("%+v", query))
+	return gmpd.session.FindPeople(query)
+}
+
 // FindByID fetches the row from the people table with the given uint64 id. It
 // validates that data and, if it's valid, returns the person.  If the data is not
 // valid the function returns an error message.
 func (gmpd GorpMysqlRepo) FindByID(id uint64) (personModel.Person, error) {
+	logger := logging.FromContext(gmpd.ctx)
 	m := "FindByID()"
-	log.Printf("%s: ID %d", m, id)
+	logger.Debug(m, "id", id)
 
 	var person personModel.Person
 	person, err := gmpd.session.FindPersonByID(id)
@@ -71,11 +151,12 @@ func (gmpd GorpMysqlRepo) FindByID(id uint64) (personModel.Person, error) {
 // checks that the given ID is also numeric before it makes the call.  This avoids hitting
 // the DB when the id is obviously junk.
 func (gmpd GorpMysqlRepo) FindByIDStr(idStr string) (personModel.Person, error) {
+	logger := logging.FromContext(gmpd.ctx)
 	m := "FindByIDStr()"
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
 		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
-		log.Printf("%s: %s", m, em)
+		logger.Error(m, "error", em)
 		return nil, fmt.Errorf("ID %s is not an unsigned integer", idStr)
 	}
 	return gmpd.FindByID(id)
@@ -86,15 +167,19 @@ func (gmpd GorpMysqlRepo) FindByIDStr(idStr string) (personModel.Person, error)
 // On a successful create, the method returns the created person, including
 // the assigned ID.  This is all done within a transaction to ensure atomicity.
 func (gmpd GorpMysqlRepo) Create(person personModel.Person) (personModel.Person, error) {
+	logger := logging.FromContext(gmpd.ctx)
 	m := "Create()"
-	log.Printf("%s:", m)
+	logger.Debug(m)
 	tx, err := gmpd.session.StartTransaction()
 	if err != nil {
-		log.Printf("%s: %s", m, err.Error())
+		logger.Error(m, "error", err)
 		return nil, err
 	}
 	person.SetID(0) // provokes the auto-increment
 	err = tx.Insert(person)
+	if err == nil {
+		err = tx.Insert(gmpd.auditEntry(auditModel.ActionCreate, person.ID(), nil, person))
+	}
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -106,81 +191,196 @@ func (gmpd GorpMysqlRepo) Create(person personModel.Person) (personModel.Person,
 		return nil, err
 	}
 
-	log.Printf("%s: created person %s", m, person.String())
+	if gmpd.index != nil {
+		gmpd.index.AddPerson(person)
+	}
+	logger.Info("created person", "person", person.String())
 	return person, nil
 }
 
+// CreateAll takes a list of people and creates a record in the people table for
+// each of them, all within one transaction.  If any insert fails, the transaction
+// is rolled back, so that either all of the people are created or none of them
+// are.  On success, the method returns the people with their assigned IDs.
+func (gmpd GorpMysqlRepo) CreateAll(people []personModel.Person) ([]personModel.Person, error) {
+	logger := logging.FromContext(gmpd.ctx)
+	m := "CreateAll()"
+	logger.Debug(m, "count", len(people))
+	tx, err := gmpd.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	for _, person := range people {
+		person.SetID(0) // provokes the auto-increment
+		err = tx.Insert(person)
+		if err == nil {
+			err = tx.Insert(gmpd.auditEntry(auditModel.ActionCreate, person.ID(), nil, person))
+		}
+		if err != nil {
+			tx.Rollback()
+			logger.Error(m, "error", err)
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	if gmpd.index != nil {
+		for _, person := range people {
+			gmpd.index.AddPerson(person)
+		}
+	}
+	logger.Info("created people", "count", len(people))
+	return people, nil
+}
+
 // Update takes a person record, updates the record in the people table with the same ID
 // and returns the updated person or any error that the DB call supplies to it.  The update
-// is done within a transaction
+// is done within a transaction.  The person's version must match the stored one,
+// otherwise the method returns ErrConflict.  On success the version is incremented.
 func (gmpd GorpMysqlRepo) Update(person personModel.Person) (uint64, error) {
+	return gmpd.update(person, auditModel.ActionUpdate)
+}
+
+// Revert restores the person with the given ID to the values recorded in the
+// given entry of their history - the values after the change that the entry
+// records.  The version is the one that the caller expects the person to have
+// now, as for Update.  The change is recorded in the audit log as a revert.  On
+// success the method returns the person as updated.
+func (gmpd GorpMysqlRepo) Revert(id uint64, entryID uint64, version int64) (personModel.Person, error) {
+	logger := logging.FromContext(gmpd.ctx)
+	m := "Revert()"
+	logger.Debug(m, "id", id, "entry", entryID, "version", version)
+	entries, err := gmpd.History(id)
+	if err != nil {
+		return nil, err
+	}
+	var entry auditModel.Entry
+	for _, e := range entries {
+		if e.ID() == entryID {
+			entry = e
+		}
+	}
+	if entry == nil {
+		logger.Info(m, "error", ErrNoSuchEntry)
+		return nil, ErrNoSuchEntry
+	}
+	if entry.After() == "" {
+		logger.Info(m, "error", ErrCannotRevert)
+		return nil, ErrCannotRevert
+	}
+
+	person, err := gmpd.session.FindPersonByID(id)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	values := auditModel.DecodeValues(entry.After())
+	person.SetForename(values["forename"])
+	person.SetSurname(values["surname"])
+	person.SetVersion(version)
+	_, err = gmpd.update(person, auditModel.ActionRevert)
+	if err != nil {
+		return nil, err
+	}
+	return person, nil
+}
+
+// update updates the person and records the change in the audit log with the
+// given action.
+func (gmpd GorpMysqlRepo) update(person personModel.Person, action string) (uint64, error) {
+	logger := logging.FromContext(gmpd.ctx)
 	m := "Update()"
+	// Get the stored version for the audit log.  If it's not the one that the
+	// caller has, the update would fail anyway.  A person in the trash is not
+	// found, so they can't be updated until they are restored.
+	before, err := gmpd.session.FindPersonByID(person.ID())
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if before.Version() != person.Version() {
+		logger.Info(m, "error", ErrConflict)
+		return 0, ErrConflict
+	}
 	tx, err := gmpd.session.StartTransaction()
 	if err != nil {
-		log.Printf("%s: %s", m, err.Error())
+		logger.Error(m, "error", err)
 		return 0, err
 	}
 	rowsUpdated, err := tx.Update(person)
 	if err != nil {
 		tx.Rollback()
-		log.Printf("%s: %s", m, err.Error())
+		logger.Error(m, "error", err)
+		if dbsession.IsConflict(err) {
+			return 0, ErrConflict
+		}
 		return 0, err
 	}
 	if rowsUpdated != 1 {
 		tx.Rollback()
 		em := fmt.Sprintf("update failed - %d rows would have been updated, expected 1", rowsUpdated)
-		log.Printf("%s: %s", m, em)
+		logger.Error(m, "error", em)
 		return 0, errors.New(em)
 	}
+	err = tx.Insert(gmpd.auditEntry(action, person.ID(), before, person))
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
 
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
-		log.Printf("%s: %s", m, err.Error())
+		logger.Error(m, "error", err)
+		if dbsession.IsConflict(err) {
+			return 0, ErrConflict
+		}
 		return 0, err
 	}
 
+	if gmpd.index != nil {
+		gmpd.index.AddPerson(person)
+	}
+
 	// Success!
 	return 1, nil
 }
 
-// DeleteByID takes the given uint64 ID and deletes the record with that ID from the people table.
-// The function returns the row count and error that the database supplies to it.  On a successful
-// delete, it should return 1, having deleted one row.
+// DeleteByID takes the given uint64 ID and moves the person with that ID to the trash.
+// The function returns the row count and error that the database supplies to it.  On a
+// successful delete, it should return 1, having changed one row.  The person's credits
+// are kept, so that they come back if the person is restored.  If the person is changed
+// by someone else during the delete, the method returns ErrConflict.
 func (gmpd GorpMysqlRepo) DeleteByID(id uint64) (int64, error) {
+	logger := logging.FromContext(gmpd.ctx)
 	m := "DeleteByID()"
-	log.Printf("%s: ID %d", m, id)
-	// Need a Person record for the delete method, so fake one up.
-	var person gorpPersonModel.GorpMysqlPerson
-	person.SetID(id)
-	tx, err := gmpd.session.StartTransaction()
-	if err != nil {
-		log.Printf("%s: %s", m, err.Error())
-		return 0, err
+	logger.Debug(m, "id", id)
+	person, err := gmpd.session.FindPersonByID(id)
+	if err == sql.ErrNoRows {
+		em := fmt.Sprintf("delete failed - there is no person with ID %d", id)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
 	}
-	rowsDeleted, err := tx.Delete(&person)
 	if err != nil {
-		tx.Rollback()
-		log.Printf("%s: %s", m, err.Error())
+		logger.Error(m, "error", err)
 		return 0, err
 	}
-	if rowsDeleted != 1 {
-		tx.Rollback()
-		em := fmt.Sprintf("delete failed - %d rows would have been deleted, expected 1", rowsDeleted)
-		log.Printf("%s: %s", m, em)
-		return 0, errors.New(em)
-	}
-
-	err = tx.Commit()
+	err = gmpd.setDeletedAt(person, time.Now())
 	if err != nil {
-		tx.Rollback()
-		log.Printf("%s: %s", m, err.Error())
 		return 0, err
 	}
-	if err != nil {
-		log.Printf("%s: %s", m, err.Error())
+	if gmpd.index != nil {
+		gmpd.index.Remove(search.KindPerson, id)
 	}
-	return rowsDeleted, nil
+	return 1, nil
 }
 
 // DeleteByIDStr takes the given String ID and deletes the record with that ID from the people table.
@@ -189,14 +389,293 @@ func (gmpd GorpMysqlRepo) DeleteByID(id uint64) (int64, error) {
 // the delete and returns the row count and error that the database supplies to it.  On a successful
 // delete, it should return 1, having deleted one row.
 func (gmpd GorpMysqlRepo) DeleteByIDStr(idStr string) (int64, error) {
+	logger := logging.FromContext(gmpd.ctx)
 	m := "DeleteByIDStr()"
-	log.Printf("%s: ID %s", m, idStr)
+	logger.Debug(m, "id", idStr)
 	// Check the id.
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
 		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
-		log.Printf("%s: %s", m, em)
+		logger.Error(m, "error", em)
 		return 0, errors.New(em)
 	}
 	return gmpd.DeleteByID(id)
 }
+
+// FindTrash returns the people in the trash, most recently deleted first.
+func (gmpd GorpMysqlRepo) FindTrash() ([]personModel.Person, error) {
+	logger := logging.FromContext(gmpd.ctx)
+	m := "FindTrash()"
+	logger.Debug(m)
+	return gmpd.session.FindDeletedPeople()
+}
+
+// Restore takes the person with the given ID out of the trash and returns them.  If
+// they are not in the trash, it returns ErrNotInTrash.
+func (gmpd GorpMysqlRepo) Restore(id uint64) (personModel.Person, error) {
+	logger := logging.FromContext(gmpd.ctx)
+	m := "Restore()"
+	logger.Debug(m, "id", id)
+	person, err := gmpd.session.FindDeletedPersonByID(id)
+	if err == sql.ErrNoRows {
+		logger.Info(m, "error", ErrNotInTrash)
+		return nil, ErrNotInTrash
+	}
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	err = gmpd.setDeletedAt(person, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if gmpd.index != nil {
+		gmpd.index.AddPerson(person)
+	}
+	return person, nil
+}
+
+// Purge removes the person with the given ID from the trash for good, along with
+// their credits, and returns the number of people removed, which should be 1.  If
+// the person is not in the trash, it returns ErrNotInTrash.  The person's award
+// nominations are kept as nominations of the film alone, and their history is
+// kept.
+func (gmpd GorpMysqlRepo) Purge(id uint64) (int64, error) {
+	logger := logging.FromContext(gmpd.ctx)
+	m := "Purge()"
+	logger.Debug(m, "id", id)
+	person, err := gmpd.session.FindDeletedPersonByID(id)
+	if err == sql.ErrNoRows {
+		logger.Info(m, "error", ErrNotInTrash)
+		return 0, ErrNotInTrash
+	}
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	tx, err := gmpd.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	// Find the person's credits within the transaction so that they can be removed
+	// with the person, leaving none pointing at a missing record.
+	credits, err := tx.FindAllCreditsByPerson(id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	// Their genres and tags go too.
+	links, err := taxonomyLinks(tx, id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	// Their award nominations stay on the ceremony's page, without the person.
+	nominations, err := tx.FindAllNominationsByPerson(id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	for _, credit := range credits {
+		_, err = tx.Delete(credit)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(m, "error", err)
+			return 0, err
+		}
+	}
+	if len(links) > 0 {
+		_, err = tx.Delete(links...)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(m, "error", err)
+			return 0, err
+		}
+	}
+	for _, nomination := range nominations {
+		nomination.SetPersonID(0)
+		_, err = tx.Update(nomination)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(m, "error", err)
+			return 0, err
+		}
+	}
+	rowsDeleted, err := tx.Delete(person)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		if dbsession.IsConflict(err) {
+			return 0, ErrConflict
+		}
+		return 0, err
+	}
+	if rowsDeleted != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("purge failed - %d rows would have been deleted, expected 1", rowsDeleted)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+	err = tx.Insert(gmpd.auditEntry(auditModel.ActionPurge, id, person, nil))
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		if dbsession.IsConflict(err) {
+			return 0, ErrConflict
+		}
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
+
+// PurgeTrash purges the people who were moved to the trash before the given time
+// and returns the number purged.  Anyone restored in the meantime is left alone.
+// If a purge fails, the method stops and returns the error along with the number
+// purged so far.
+func (gmpd GorpMysqlRepo) PurgeTrash(deletedBefore time.Time) (int, error) {
+	logger := logging.FromContext(gmpd.ctx)
+	m := "PurgeTrash()"
+	logger.Debug(m, "deleted_before", deletedBefore.Format(time.RFC3339))
+	people, err := gmpd.session.FindDeletedPeople()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	purged := 0
+	for _, person := range people {
+		if !person.DeletedAt().Before(deletedBefore) {
+			continue
+		}
+		_, err = gmpd.Purge(person.ID())
+		if err == ErrNotInTrash || err == ErrConflict {
+			// Restored since the trash was read.
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	logger.Info("purged people", "count", purged)
+	return purged, nil
+}
+
+// History returns the audit log entries for the person with the given ID, newest
+// first.  The history of a deleted person is still available.
+func (gmpd GorpMysqlRepo) History(id uint64) ([]auditModel.Entry, error) {
+	logger := logging.FromContext(gmpd.ctx)
+	m := "History()"
+	logger.Debug(m, "id", id)
+	return gmpd.session.FindAuditEntries(TableName, id)
+}
+
+// setDeletedAt moves the person into the trash, if the time is not zero, or out of
+// it, and records the change in the audit log.  A person in the trash has no after
+// values in the log, as if they had gone.
+func (gmpd GorpMysqlRepo) setDeletedAt(person personModel.Person, deletedAt time.Time) error {
+	logger := logging.FromContext(gmpd.ctx)
+	m := "setDeletedAt()"
+	action, before, after := auditModel.ActionDelete, person, personModel.Person(nil)
+	if deletedAt.IsZero() {
+		action, before, after = auditModel.ActionRestore, nil, person
+	}
+	person.SetDeletedAt(deletedAt)
+	tx, err := gmpd.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return err
+	}
+	rowsUpdated, err := tx.Update(person)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		if dbsession.IsConflict(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	if rowsUpdated != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("%s failed - %d rows would have been changed, expected 1",
+			action, rowsUpdated)
+		logger.Error(m, "error", em)
+		return errors.New(em)
+	}
+	err = tx.Insert(gmpd.auditEntry(action, person.ID(), before, after))
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		if dbsession.IsConflict(err) {
+			return ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// auditEntry creates an audit log entry recording a change to the person with the
+// given ID, made by the repository's user.  before is nil for a create and after
+// is nil for a delete.
+func (gmpd GorpMysqlRepo) auditEntry(action string, id uint64,
+	before personModel.Person, after personModel.Person) auditModel.Entry {
+
+	version := int64(0)
+	switch {
+	case after != nil:
+		version = after.Version()
+	case before != nil:
+		version = before.Version()
+	}
+	return gorpAuditModel.MakeInitialisedEntry(0, TableName, id, action, version,
+		gmpd.user, time.Now(), personValues(before), personValues(after))
+}
+
+// personValues encodes the values of the person's fields for the audit log.
+func personValues(person personModel.Person) string {
+	if person == nil {
+		return ""
+	}
+	return auditModel.EncodeValues(map[string]string{
+		"forename": person.Forename(),
+		"surname":  person.Surname(),
+	})
+}
+
+// taxonomyLinks returns the links that attach genres and tags to the person with
+// the given ID, found within the given transaction.
+func taxonomyLinks(tx dbsession.Transaction, id uint64) ([]interface{}, error) {
+	genreLinks, err := tx.FindGenreLinks(dbsession.SubjectPerson, id)
+	if err != nil {
+		return nil, err
+	}
+	tagLinks, err := tx.FindTagLinks(dbsession.SubjectPerson, id)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]interface{}, 0, len(genreLinks)+len(tagLinks))
+	for _, link := range genreLinks {
+		links = append(links, link)
+	}
+	for _, link := range tagLinks {
+		links = append(links, link)
+	}
+	return links, nil
+}
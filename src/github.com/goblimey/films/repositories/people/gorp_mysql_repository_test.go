@@ -3,14 +3,21 @@ package people
 import (
 	"fmt"
 	"log"
+	"os"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	auditModel "github.com/goblimey/films/models/audit"
+	personInterface "github.com/goblimey/films/models/person"
 	personModel "github.com/goblimey/films/models/person/gorpmysql"
 	dbsession "github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/search"
 )
 
 // This is an integration test for the GorpMysqlRepo connecting to a MySQL DB via GORP.
+// The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.
 
 var expectedForename1 = "foo"
 var expectedSurname1 = "bar"
@@ -21,7 +28,7 @@ var expectedSurname2 = "barfoo"
 func TestIntCreatePersonStoreFetchBackAndCheckContents(t *testing.T) {
 	log.SetPrefix("TestIntegrationCreatePersonAndCheckContents")
 	// Create a dao containing a session
-	dbsession, err := dbsession.MakeGorpMysqlDBSession()
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -76,7 +83,7 @@ func TestIntCreatePersonStoreFetchBackAndCheckContents(t *testing.T) {
 func TestIntCreateTwoPersonsAndReadBack(t *testing.T) {
 	log.SetPrefix("TestCreatePersonAndReadBack")
 	// Create a dao containing a session
-	dbsession, err := dbsession.MakeGorpMysqlDBSession()
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -173,7 +180,7 @@ func TestIntCreateTwoPersonsAndReadBack(t *testing.T) {
 func TestIntCreateTwoPeopleAndDeleteOneByIDStr(t *testing.T) {
 	log.SetPrefix("TestIntegrationCreateTwoPeopleAndDeleteOneByIDStr")
 	// Create a dao containing a session
-	dbsession, err := dbsession.MakeGorpMysqlDBSession()
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -237,7 +244,7 @@ func TestIntCreateTwoPeopleAndDeleteOneByIDStr(t *testing.T) {
 func TestIntCreatePersonAndUpdate(t *testing.T) {
 	log.SetPrefix("TestIntegrationCreatePersonAndUpdate")
 	// Create a dao containing a session
-	dbsession, err := dbsession.MakeGorpMysqlDBSession()
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -283,7 +290,418 @@ func TestIntCreatePersonAndUpdate(t *testing.T) {
 	clearDown(dao, t)
 }
 
-// clearDown() - helper function to remove all people from the DB
+// Update a person from two copies read at the same time.  The second update must be
+// refused rather than overwriting the first.
+func TestIntUpdateConflict(t *testing.T) {
+	log.SetPrefix("TestIntUpdateConflict")
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	dao := MakeRepo(dbsession)
+
+	clearDown(dao, t)
+
+	person, err := dao.Create(personModel.MakeInitialisedPerson(0, expectedForename1, expectedSurname1))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	first, _ := dao.FindByID(person.ID())
+	second, _ := dao.FindByID(person.ID())
+
+	first.SetForename(expectedForename2)
+	_, err = dao.Update(first)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if first.Version() != second.Version()+1 {
+		t.Errorf("expected the version to go from %d to %d, actually %d",
+			second.Version(), second.Version()+1, first.Version())
+	}
+
+	second.SetSurname(expectedSurname2)
+	_, err = dao.Update(second)
+	if err != ErrConflict {
+		t.Errorf("expected ErrConflict, actually %v", err)
+	}
+
+	fetched, _ := dao.FindByID(person.ID())
+	if fetched.Forename() != expectedForename2 || fetched.Surname() != expectedSurname1 {
+		t.Errorf("expected %s %s, actually %s %s", expectedForename2, expectedSurname1,
+			fetched.Forename(), fetched.Surname())
+	}
+
+	// A delete doesn't need the caller's version, so it works.
+	rows, err := dao.DeleteByID(person.ID())
+	if err != nil || rows != 1 {
+		t.Errorf("expected 1 row to be deleted, actually %d, %v", rows, err)
+	}
+
+	clearDown(dao, t)
+}
+
+// Create three people and fetch them a page at a time, sorted and filtered.
+func TestIntFindPage(t *testing.T) {
+	log.SetPrefix("TestIntFindPage")
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	dao := MakeRepo(dbsession)
+
+	clearDown(dao, t)
+
+	for _, name := range [][]string{{"Orson", "Welles"}, {"Joseph", "Cotten"}, {"Dorothy", "Comingore"}} {
+		_, err := dao.Create(personModel.MakeInitialisedPerson(0, name[0], name[1]))
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	query := dbsessionQuery("", true, 0, 2)
+	people, total, err := dao.FindPage(query)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if total != 3 || len(people) != 2 {
+		t.Fatalf("expected 2 people of 3, actually %d of %d", len(people), total)
+	}
+	if people[0].Surname() != "Welles" || people[1].Surname() != "Cotten" {
+		t.Errorf("expected Welles then Cotten, actually %s then %s",
+			people[0].Surname(), people[1].Surname())
+	}
+
+	people, _, _ = dao.FindPage(dbsessionQuery("", true, 2, 2))
+	if len(people) != 1 || people[0].Surname() != "Comingore" {
+		t.Errorf("expected Comingore alone on the last page, actually %v", people)
+	}
+
+	// "o_" must not match everyone - the underscore is not a wildcard.
+	people, total, _ = dao.FindPage(dbsessionQuery("O_", false, 0, 10))
+	if total != 0 || len(people) != 0 {
+		t.Errorf("expected nobody to match O_, actually %d", total)
+	}
+
+	people, total, _ = dao.FindPage(dbsessionQuery("OTT", false, 0, 10))
+	if total != 1 || len(people) != 1 || people[0].Surname() != "Cotten" {
+		t.Errorf("expected Cotten to match OTT, actually %d people", total)
+	}
+
+	clearDown(dao, t)
+}
+
+// dbsessionQuery creates a query for people sorted by surname.
+func dbsessionQuery(name string, descending bool, offset, limit int) dbsession.PeopleQuery {
+	return dbsession.PeopleQuery{
+		Name:       name,
+		Sort:       dbsession.SortBySurname,
+		Descending: descending,
+		Offset:     offset,
+		Limit:      limit,
+	}
+}
+
+// Create, update and delete a person through an indexed repository and check that
+// the search index keeps in step.
+func TestIntSearchIndexKeptInStep(t *testing.T) {
+	log.SetPrefix("TestIntSearchIndexKeptInStep")
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	clearDown(MakeRepo(dbsession), t)
+
+	index := search.MakeIndex()
+	dao := MakeIndexedRepo(dbsession, index)
+
+	person, err := dao.Create(personModel.MakeInitialisedPerson(0, "Everett", "Sloane"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(index.Search("sloane", 0)) != 1 {
+		t.Errorf("expected to find the new person")
+	}
+
+	person.SetSurname("Sloan")
+	_, err = dao.Update(person)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	results := index.Search("sloan", 0)
+	if len(results) != 1 || results[0].Title != "Everett Sloan" {
+		t.Errorf("expected to find the updated person, actually %v", results)
+	}
+
+	_, err = dao.DeleteByID(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if index.Len() != 0 {
+		t.Errorf("expected the index to be empty, actually %d entries", index.Len())
+	}
+}
+
+// Create two people in one transaction and read them back.
+func TestIntCreateAll(t *testing.T) {
+	log.SetPrefix("TestIntCreateAll")
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	dao := MakeRepo(dbsession)
+
+	clearDown(dao, t)
+
+	people := []personInterface.Person{
+		personModel.MakeInitialisedPerson(0, expectedForename1, expectedSurname1),
+		personModel.MakeInitialisedPerson(0, expectedForename2, expectedSurname2),
+	}
+	created, err := dao.CreateAll(people)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(created) != 2 || created[0].ID() == 0 || created[1].ID() == created[0].ID() {
+		t.Errorf("expected two people with different IDs, actually %v", created)
+	}
+
+	fetched, err := dao.FindAll()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(fetched) != 2 {
+		t.Errorf("expected 2 people, actually %d", len(fetched))
+	}
+
+	clearDown(dao, t)
+}
+
+// Create, update and delete a person and check that each change is in their
+// history, with the values before and after and the user who made it.
+func TestIntAuditTrail(t *testing.T) {
+	log.SetPrefix("TestIntAuditTrail")
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	clearDown(MakeRepo(dbsession), t)
+
+	dao := MakeRepo(dbsession).WithUser("carol")
+
+	person, err := dao.Create(personModel.MakeInitialisedPerson(0, "Agnes", "Moorhead"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	person.SetForename("Agnès")
+	_, err = dao.Update(person)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = dao.DeleteByID(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	entries, err := dao.History(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, actually %d", len(entries))
+	}
+	// Newest first.
+	expected := []struct {
+		action  string
+		version int64
+		before  string
+		after   string
+	}{
+		{auditModel.ActionDelete, 3, "Agnès", ""},
+		{auditModel.ActionUpdate, 2, "Agnes", "Agnès"},
+		{auditModel.ActionCreate, 1, "", "Agnes"},
+	}
+	for i, want := range expected {
+		entry := entries[i]
+		before := auditModel.DecodeValues(entry.Before())["forename"]
+		after := auditModel.DecodeValues(entry.After())["forename"]
+		if entry.Action() != want.action || entry.Version() != want.version ||
+			before != want.before || after != want.after {
+			t.Errorf("entry %d: expected %s version %d from \"%s\" to \"%s\", actually %s version %d from \"%s\" to \"%s\"",
+				i, want.action, want.version, want.before, want.after,
+				entry.Action(), entry.Version(), before, after)
+		}
+		if entry.ChangedBy() != "carol" || entry.RecordID() != person.ID() {
+			t.Errorf("entry %d: expected a change by carol to person %d, actually by %s to %d",
+				i, person.ID(), entry.ChangedBy(), entry.RecordID())
+		}
+	}
+}
+
+// Change a person and then revert them to the values they were created with.
+func TestIntRevert(t *testing.T) {
+	log.SetPrefix("TestIntRevert")
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	dao := MakeRepo(dbsession)
+
+	clearDown(dao, t)
+
+	person, err := dao.Create(personModel.MakeInitialisedPerson(0, "Ray", "Collins"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	person.SetSurname("Colins")
+	_, err = dao.Update(person)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	entries, _ := dao.History(person.ID())
+	created := entries[len(entries)-1]
+
+	// Reverting from a stale version is refused.
+	_, err = dao.Revert(person.ID(), created.ID(), person.Version()-1)
+	if err != ErrConflict {
+		t.Errorf("expected ErrConflict, actually %v", err)
+	}
+	_, err = dao.Revert(person.ID(), created.ID()+100, person.Version())
+	if err != ErrNoSuchEntry {
+		t.Errorf("expected ErrNoSuchEntry, actually %v", err)
+	}
+
+	reverted, err := dao.Revert(person.ID(), created.ID(), person.Version())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if reverted.Surname() != "Collins" || reverted.Version() != 3 {
+		t.Errorf("expected Collins at version 3, actually %s at version %d",
+			reverted.Surname(), reverted.Version())
+	}
+	fetched, _ := dao.FindByID(person.ID())
+	if fetched.Surname() != "Collins" {
+		t.Errorf("expected Collins, actually %s", fetched.Surname())
+	}
+	entries, _ = dao.History(person.ID())
+	if len(entries) != 3 || entries[0].Action() != auditModel.ActionRevert {
+		t.Errorf("expected the revert to be recorded, actually %d entries", len(entries))
+	}
+
+	clearDown(dao, t)
+	entries, _ = dao.History(person.ID())
+	_, err = dao.Revert(person.ID(), entries[0].ID(), 0)
+	if err != ErrCannotRevert {
+		t.Errorf("expected ErrCannotRevert for a delete, actually %v", err)
+	}
+}
+
+// Delete a person, check that they are only in the trash, restore them, then
+// delete and purge them.
+func TestIntTrash(t *testing.T) {
+	log.SetPrefix("TestIntTrash")
+	dbsession, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer dbsession.Close()
+
+	dao := MakeRepo(dbsession)
+
+	clearDown(dao, t)
+
+	person, err := dao.Create(personModel.MakeInitialisedPerson(0, "Everett", "Sloane"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = dao.DeleteByID(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	people, _ := dao.FindAll()
+	if len(people) != 0 {
+		t.Errorf("expected nobody outside the trash, actually %d people", len(people))
+	}
+	_, err = dao.FindByID(person.ID())
+	if err == nil {
+		t.Errorf("expected a person in the trash not to be found")
+	}
+	_, err = dao.Update(person)
+	if err == nil {
+		t.Errorf("expected a person in the trash not to be updated")
+	}
+	trash, err := dao.FindTrash()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(trash) != 1 || trash[0].ID() != person.ID() || trash[0].DeletedAt().IsZero() {
+		t.Fatalf("expected person %d in the trash, actually %v", person.ID(), trash)
+	}
+
+	restored, err := dao.Restore(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if restored.Surname() != "Sloane" || !restored.DeletedAt().IsZero() {
+		t.Errorf("expected Sloane out of the trash, actually %s deleted at %v",
+			restored.Surname(), restored.DeletedAt())
+	}
+	_, err = dao.FindByID(person.ID())
+	if err != nil {
+		t.Errorf("expected the restored person to be found - %s", err.Error())
+	}
+	_, err = dao.Restore(person.ID())
+	if err != ErrNotInTrash {
+		t.Errorf("expected ErrNotInTrash, actually %v", err)
+	}
+	_, err = dao.Purge(person.ID())
+	if err != ErrNotInTrash {
+		t.Errorf("expected ErrNotInTrash, actually %v", err)
+	}
+
+	// Only people deleted before the given time are purged.
+	_, err = dao.DeleteByID(person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	purged, err := dao.PurgeTrash(time.Now().Add(-time.Hour))
+	if err != nil || purged != 0 {
+		t.Errorf("expected nobody to be purged, actually %d, %v", purged, err)
+	}
+	purged, err = dao.PurgeTrash(time.Now().Add(time.Hour))
+	if err != nil || purged != 1 {
+		t.Errorf("expected one person to be purged, actually %d, %v", purged, err)
+	}
+	trash, _ = dao.FindTrash()
+	if len(trash) != 0 {
+		t.Errorf("expected the trash to be empty, actually %d people", len(trash))
+	}
+
+	entries, _ := dao.History(person.ID())
+	actions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		actions = append(actions, entry.Action())
+	}
+	expected := "purge delete restore delete create"
+	if strings.Join(actions, " ") != expected {
+		t.Errorf("expected history %s, actually %s", expected, strings.Join(actions, " "))
+	}
+}
+
+// clearDown() - helper function to remove all people from the DB, including the
+// trash
 func clearDown(repo Repository, t *testing.T) {
 	people, err := repo.FindAll()
 	if err != nil {
@@ -300,4 +718,8 @@ func clearDown(repo Repository, t *testing.T) {
 			t.Errorf("while clearing down, expected 1 row, actual %d", rows)
 		}
 	}
+	_, err = repo.PurgeTrash(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Errorf(err.Error())
+	}
 }
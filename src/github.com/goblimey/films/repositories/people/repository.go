@@ -1,6 +1,10 @@
 package people
 
 import (
+	"context"
+	"time"
+
+	auditModel "github.com/goblimey/films/models/audit"
 	personModel "github.com/goblimey/films/models/person"
 	"github.com/goblimey/films/utilities/dbsession"
 )
@@ -10,12 +14,30 @@ import (
 type Repository interface {
 	SetSession(session dbsession.DBSession)
 
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		WithUser returns a copy of the repository that records the given user in the
+		audit log as the person making any changes.
+	*/
+	WithUser(user string) Repository
+
 	/*
 		FindAll() returns a pointer to a map of valid People indexed by ID.  Any
 		invalid records are left out of the map
 	*/
 	FindAll() ([]personModel.Person, error)
 
+	/*
+		FindPage() returns one page of the valid People, filtered and sorted as the query
+		says, and the total number of valid People that match the filter.
+	*/
+	FindPage(query dbsession.PeopleQuery) ([]personModel.Person, int64, error)
+
 	/*
 		FindByid fetches the row from the people table with the given uint64 id. It validates that data
 		and, if it's valid, uses it to create a Person and returns a pointer to it.  If the data is not
@@ -39,6 +61,13 @@ type Repository interface {
 	*/
 	Create(person personModel.Person) (personModel.Person, error)
 
+	/*
+		CreateAll creates a record in the people table for each of the given people,
+		all in one transaction.  If any of them fails, none of them are created and
+		the error is returned.  Otherwise it returns the people with their IDs set.
+	*/
+	CreateAll(people []personModel.Person) ([]personModel.Person, error)
+
 	/*
 		Update takes a person structure, updates the record in the people table with the
 		same ID and returns the row count and error that the DB call supplies to it.  On
@@ -46,18 +75,54 @@ type Repository interface {
 	*/
 	Update(person personModel.Person) (uint64, error)
 	/*
-	 * DeleteById takes the given uint64 ID and deletes the record with that ID from the people
-	 * table.  The method returns the row count and error that the database supplies to it.  On
-	 * a successful delete, it should return 1, having deleted one row.
+	 * DeleteById takes the given uint64 ID and moves the person with that ID to the trash.
+	 * The method returns the row count and error that the database supplies to it.  On
+	 * a successful delete, it should return 1, having changed one row.
 	 */
 	DeleteByID(id uint64) (int64, error)
 
 	/*
-	 * DeleteByIdStr takes the given String ID and deletes the record with that ID from the people
-	 * table. The ID in the database is numeric and the method checks that the given ID is also
+	 * DeleteByIdStr takes the given String ID and moves the person with that ID to the trash.
+	 * The ID in the database is numeric and the method checks that the given ID is also
 	 * numeric before it makes the call.  If not, it returns an error.  If the ID looks sensible,
 	 * the function attempts the delete and returns the row count and error that the database
-	 * supplies to it.  On a successful delete, it should return 1, having deleted one row.
+	 * supplies to it.  On a successful delete, it should return 1, having changed one row.
 	 */
 	DeleteByIDStr(idStr string) (int64, error)
+
+	/*
+		FindTrash returns the people in the trash, most recently deleted first.
+	*/
+	FindTrash() ([]personModel.Person, error)
+
+	/*
+		Restore takes the person with the given ID out of the trash and returns them.
+	*/
+	Restore(id uint64) (personModel.Person, error)
+
+	/*
+		Purge removes the person with the given ID from the trash for good, along with
+		their credits.  Their award nominations are kept as nominations of the film
+		alone.  On success it returns 1, having deleted one row.
+	*/
+	Purge(id uint64) (int64, error)
+
+	/*
+		PurgeTrash purges the people who were moved to the trash before the given time
+		and returns the number purged.
+	*/
+	PurgeTrash(deletedBefore time.Time) (int, error)
+
+	/*
+		History returns the audit log entries recording the changes to the person with
+		the given ID, newest first.
+	*/
+	History(id uint64) ([]auditModel.Entry, error)
+
+	/*
+		Revert restores the person with the given ID to the values after the change
+		recorded by the given entry in their history.  The version must match the
+		stored one, as for Update.  It returns the person as updated.
+	*/
+	Revert(id uint64, entryID uint64, version int64) (personModel.Person, error)
 }
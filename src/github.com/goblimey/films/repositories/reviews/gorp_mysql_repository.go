@@ -0,0 +1,225 @@
+// Package reviews provides operations on the users' ratings and reviews of films.
+// Each film's ratings are summarised in a separate table, which is changed in the
+// same transaction as the reviews, so that the film's page can show the average
+// and the histogram of the ratings without reading every review.  The tables are
+// referenced via a database session that is supplied by the parent.
+//
+// The GorpMysqlRepo satisfies the Repository interface.
+package reviews
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	reviewModel "github.com/goblimey/films/models/review"
+	gorpReviewModel "github.com/goblimey/films/models/review/gorpmysql"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// maxAttempts is the number of times that a change to a film's ratings is tried.
+// If two users rate the same film at the same time, one of them finds that the
+// summary has changed under it, and tries again with the new summary.
+const maxAttempts = 3
+
+// GorpMysqlRepo satifies the Repository interface.
+type GorpMysqlRepo struct {
+	session dbsession.DBSession
+	ctx     context.Context
+}
+
+// MakeRepo is a factory function that creates a GorpMysqlRepo and returns it as a
+// Repository.
+func MakeRepo(session dbsession.DBSession) Repository {
+	return &GorpMysqlRepo{session: session}
+}
+
+// SetSession sets the session.
+func (gmrr *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
+	gmrr.session = session
+}
+
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmrr GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmrr.ctx = ctx
+	gmrr.session = gmrr.session.WithContext(ctx)
+	return &gmrr
+}
+
+// FindByID fetches the review with the given uint64 id.
+func (gmrr GorpMysqlRepo) FindByID(id uint64) (reviewModel.Review, error) {
+	logger := logging.FromContext(gmrr.ctx)
+	m := "FindByID()"
+	logger.Debug(m, "id", id)
+	return gmrr.session.FindReviewByID(id)
+}
+
+// FindByFilm returns the reviews of the film with the given ID.
+func (gmrr GorpMysqlRepo) FindByFilm(filmID uint64) ([]reviewModel.Review, error) {
+	logger := logging.FromContext(gmrr.ctx)
+	m := "FindByFilm()"
+	logger.Debug(m, "film_id", filmID)
+	return gmrr.session.FindReviewsByFilm(filmID)
+}
+
+// FindByFilmAndUser fetches the review of the film with the given ID by the user
+// with the given ID.
+func (gmrr GorpMysqlRepo) FindByFilmAndUser(filmID uint64, userID uint64) (reviewModel.Review, error) {
+	logger := logging.FromContext(gmrr.ctx)
+	m := "FindByFilmAndUser()"
+	logger.Debug(m, "film_id", filmID, "user_id", userID)
+	return gmrr.session.FindReviewByFilmAndUser(filmID, userID)
+}
+
+// FindSummary fetches the summary of the ratings of the film with the given ID,
+// or an empty summary if the film has never been rated.
+func (gmrr GorpMysqlRepo) FindSummary(filmID uint64) (reviewModel.Summary, error) {
+	logger := logging.FromContext(gmrr.ctx)
+	m := "FindSummary()"
+	logger.Debug(m, "film_id", filmID)
+	summary, err := gmrr.session.FindRatingSummary(filmID)
+	if err == sql.ErrNoRows {
+		summary = gorpReviewModel.MakeSummary()
+		summary.SetFilmID(filmID)
+		return summary, nil
+	}
+	return summary, err
+}
+
+// Save creates or replaces the user's review of the film and changes the summary
+// of the film's ratings to match, all in one transaction.
+func (gmrr GorpMysqlRepo) Save(review reviewModel.Review) (reviewModel.Review, error) {
+	logger := logging.FromContext(gmrr.ctx)
+	m := "Save()"
+	logger.Debug(m, "review", review.String())
+	if !reviewModel.ValidRating(review.Rating()) {
+		em := fmt.Sprintf("the rating must be between %d and %d",
+			reviewModel.MinRating, reviewModel.MaxRating)
+		logger.Error(m, "error", em)
+		return nil, errors.New(em)
+	}
+	err := gmrr.retry(m, func() error {
+		existing, err := gmrr.session.FindReviewByFilmAndUser(review.FilmID(), review.UserID())
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		summary, err := gmrr.FindSummary(review.FilmID())
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			summary.Remove(existing.Rating())
+		}
+		summary.Add(review.Rating())
+		review.SetUpdatedAt(time.Now())
+
+		tx, err := gmrr.session.StartTransaction()
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			review.SetID(existing.ID())
+			_, err = tx.Update(review)
+		} else {
+			review.SetID(0) // provokes the auto-increment
+			err = tx.Insert(review)
+		}
+		if err == nil {
+			err = gmrr.saveSummary(tx, summary)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	logger.Info("saved review", "review", review.String())
+	return review, nil
+}
+
+// DeleteByID deletes the review with the given ID and takes its rating out of the
+// summary of the film's ratings, in one transaction.
+func (gmrr GorpMysqlRepo) DeleteByID(id uint64) (int64, error) {
+	logger := logging.FromContext(gmrr.ctx)
+	m := "DeleteByID()"
+	logger.Debug(m, "id", id)
+	var rowsDeleted int64
+	err := gmrr.retry(m, func() error {
+		review, err := gmrr.session.FindReviewByID(id)
+		if err != nil {
+			return err
+		}
+		summary, err := gmrr.FindSummary(review.FilmID())
+		if err != nil {
+			return err
+		}
+		summary.Remove(review.Rating())
+
+		tx, err := gmrr.session.StartTransaction()
+		if err != nil {
+			return err
+		}
+		rowsDeleted, err = tx.Delete(review)
+		if err == nil && rowsDeleted != 1 {
+			err = fmt.Errorf("delete failed - %d rows would have been deleted, expected 1",
+				rowsDeleted)
+		}
+		if err == nil {
+			err = gmrr.saveSummary(tx, summary)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
+
+// saveSummary adds the creation or update of the given summary to the
+// transaction.  If the summary has changed since it was read, or another request
+// has just given the film its first summary, it returns an error that satisfies
+// dbsession.IsConflict.
+func (gmrr GorpMysqlRepo) saveSummary(tx dbsession.Transaction, summary reviewModel.Summary) error {
+	if summary.ID() == 0 {
+		return tx.Insert(summary)
+	}
+	rowsUpdated, err := tx.Update(summary)
+	if err != nil {
+		return err
+	}
+	if rowsUpdated != 1 {
+		return fmt.Errorf("update failed - %d rows would have been updated, expected 1",
+			rowsUpdated)
+	}
+	return nil
+}
+
+// retry calls attempt until it succeeds, up to maxAttempts times.  It only tries
+// again after a conflict, where another request changed a film's ratings at the
+// same time.  Any other error is returned straight away.
+func (gmrr GorpMysqlRepo) retry(m string, attempt func() error) error {
+	logger := logging.FromContext(gmrr.ctx)
+	var err error
+	for i := 0; i < maxAttempts; i++ {
+		err = attempt()
+		if !dbsession.IsConflict(err) {
+			return err
+		}
+		logger.Debug(m, "attempt", i+1, "error", err)
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package reviews
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	filmModel "github.com/goblimey/films/models/film/gorpmysql"
+	gorpReviewModel "github.com/goblimey/films/models/review/gorpmysql"
+	userModel "github.com/goblimey/films/models/user"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	dbsession "github.com/goblimey/films/utilities/dbsession"
+)
+
+// This is an integration test for the GorpMysqlRepo connecting to a MySQL DB via GORP.
+// The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.
+
+// Two users rate a film, one changes their mind and the other deletes their
+// review.  The summary of the film's ratings should follow each change.
+func TestIntRateAndReviewFilm(t *testing.T) {
+	log.SetPrefix("TestIntRateAndReviewFilm")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+	users := usersRepo.MakeRepo(session)
+
+	film, err := films.Create(filmModel.MakeInitialisedFilm(0, "Vertigo", 1958, 128, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(film.ID())
+	suffix := time.Now().UnixNano()
+	vera, err := users.Add(fmt.Sprintf("vera%d", suffix), "pw12345678", userModel.RoleViewer)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	rita, err := users.Add(fmt.Sprintf("rita%d", suffix), "pw12345678", userModel.RoleEditor)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	summary, err := repo.FindSummary(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if summary.Count() != 0 {
+		t.Errorf("expected no ratings, got %s", summary.String())
+	}
+
+	_, err = repo.Save(gorpReviewModel.MakeInitialisedReview(0, film.ID(), vera.ID(), 6,
+		"Dizzying", "The staircase."))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	ritasReview, err := repo.Save(gorpReviewModel.MakeInitialisedReview(0, film.ID(), rita.ID(), 9,
+		"", ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	// Vera changes her mind.  Her review is replaced rather than added to.
+	_, err = repo.Save(gorpReviewModel.MakeInitialisedReview(0, film.ID(), vera.ID(), 10,
+		"A masterpiece", "The staircase, the bell tower."))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	// A rating out of range is refused.
+	_, err = repo.Save(gorpReviewModel.MakeInitialisedReview(0, film.ID(), rita.ID(), 11,
+		"", ""))
+	if err == nil {
+		t.Errorf("expected a rating of 11 to be refused")
+	}
+
+	reviews, err := repo.FindByFilm(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("expected 2 reviews, got %d", len(reviews))
+	}
+	veras, err := repo.FindByFilmAndUser(film.ID(), vera.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if veras.Rating() != 10 || veras.Title() != "A masterpiece" ||
+		veras.Username() != vera.Username() {
+		t.Errorf("expected vera's new review, got %s by %s", veras.String(), veras.Username())
+	}
+	summary, err = repo.FindSummary(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if summary.Count() != 2 || summary.Average() != 9.5 {
+		t.Errorf("expected 2 ratings averaging 9.5, got %s", summary.String())
+	}
+
+	rows, err := repo.DeleteByID(ritasReview.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected 1 row deleted, got %d", rows)
+	}
+	summary, err = repo.FindSummary(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	histogram := summary.Histogram()
+	if summary.Count() != 1 || histogram[9] != 1 || histogram[8] != 0 {
+		t.Errorf("expected just vera's 10, got %s", summary.String())
+	}
+
+	// Deleting the film deletes its reviews and ratings.
+	_, err = films.DeleteByID(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	reviews, err = repo.FindByFilm(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	summary, err = repo.FindSummary(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(reviews) != 0 || summary.ID() != 0 {
+		t.Errorf("expected the reviews and ratings to be deleted, got %d and %s",
+			len(reviews), summary.String())
+	}
+}
@@ -0,0 +1,61 @@
+package reviews
+
+import (
+	"context"
+
+	reviewModel "github.com/goblimey/films/models/review"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// Repository is the interface for the reviews repository, which holds the users'
+// ratings and reviews of films and the summary of each film's ratings.
+type Repository interface {
+	SetSession(session dbsession.DBSession)
+
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		FindByID fetches the review with the given uint64 id, along with the
+		username of the reviewer.
+	*/
+	FindByID(id uint64) (reviewModel.Review, error)
+
+	/*
+		FindByFilm returns the reviews of the film with the given ID, most recent
+		first.
+	*/
+	FindByFilm(filmID uint64) ([]reviewModel.Review, error)
+
+	/*
+		FindByFilmAndUser fetches the review of the film with the given ID by the
+		user with the given ID.  If they haven't reviewed the film, it returns
+		sql.ErrNoRows.
+	*/
+	FindByFilmAndUser(filmID uint64, userID uint64) (reviewModel.Review, error)
+
+	/*
+		FindSummary fetches the summary of the ratings of the film with the given
+		ID.  A film that has never been rated gets an empty summary.
+	*/
+	FindSummary(filmID uint64) (reviewModel.Summary, error)
+
+	/*
+		Save records the given review of a film by a user.  A user only has one
+		review of each film, so if they have already reviewed the film, their
+		review is replaced, otherwise a new one is created.  The summary of the
+		film's ratings is changed in the same transaction.  It returns the saved
+		review, including its ID.
+	*/
+	Save(review reviewModel.Review) (reviewModel.Review, error)
+
+	/*
+		DeleteByID deletes the review with the given uint64 ID and takes its rating
+		out of the summary of the film's ratings.  On a successful delete, it should
+		return 1.
+	*/
+	DeleteByID(id uint64) (int64, error)
+}
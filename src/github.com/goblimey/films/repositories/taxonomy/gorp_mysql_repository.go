@@ -0,0 +1,452 @@
+// Package taxonomy manages the genres and tags that are attached to films and
+// people.  The genres form a tree, for example Thriller > Psychological Thriller,
+// and a search for a genre finds everything in the genres below it too.  Tags are
+// free-form names that users type in.  Each is attached through a link table, so
+// a film or person can have many genres and tags and each genre or tag can be
+// attached to many films and people.  The tables are referenced via a database
+// session that is supplied by the parent.
+//
+// The GorpMysqlRepo satisfies the Repository interface.
+package taxonomy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	genreModel "github.com/goblimey/films/models/genre"
+	gorpGenreModel "github.com/goblimey/films/models/genre/gorpmysql"
+	tagModel "github.com/goblimey/films/models/tag"
+	gorpTagModel "github.com/goblimey/films/models/tag/gorpmysql"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// MaxGenreNameLength is the longest genre name that the genres table can hold.
+const MaxGenreNameLength = 64
+
+// GorpMysqlRepo satifies the Repository interface.
+type GorpMysqlRepo struct {
+	session dbsession.DBSession
+	ctx     context.Context
+}
+
+// MakeRepo is a factory function that creates a GorpMysqlRepo and returns it as a
+// Repository.
+func MakeRepo(session dbsession.DBSession) Repository {
+	return &GorpMysqlRepo{session: session}
+}
+
+// SetSession sets the session.
+func (gmtr *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
+	gmtr.session = session
+}
+
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmtr GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmtr.ctx = ctx
+	gmtr.session = gmtr.session.WithContext(ctx)
+	return &gmtr
+}
+
+// FindAllGenres returns all of the genres with their paths, in order of path.
+func (gmtr GorpMysqlRepo) FindAllGenres() ([]genreModel.Genre, error) {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "FindAllGenres()"
+	genres, err := gmtr.session.FindAllGenres()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	genreModel.SetPaths(genres)
+	sortByPath(genres)
+	return genres, nil
+}
+
+// FindGenreByID fetches the genre with the given ID, with its path.
+func (gmtr GorpMysqlRepo) FindGenreByID(id uint64) (genreModel.Genre, error) {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "FindGenreByID()"
+	logger.Debug(m, "id", id)
+	genres, err := gmtr.FindAllGenres()
+	if err != nil {
+		return nil, err
+	}
+	for _, genre := range genres {
+		if genre.ID() == id {
+			return genre, nil
+		}
+	}
+	em := fmt.Sprintf("no genre with ID %d", id)
+	logger.Debug(m, "error", em)
+	return nil, errors.New(em)
+}
+
+// AddGenre creates a genre with the given name below the genre with the given
+// parent ID.  This is done within a transaction to ensure atomicity.
+func (gmtr GorpMysqlRepo) AddGenre(name string, parentID uint64) (genreModel.Genre, error) {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "AddGenre()"
+	logger.Debug(m, "name", name, "parent_id", parentID)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("the genre must have a name")
+	}
+	if len(name) > MaxGenreNameLength {
+		em := fmt.Sprintf("the genre name must be at most %d characters", MaxGenreNameLength)
+		return nil, errors.New(em)
+	}
+	genres, err := gmtr.session.FindAllGenres()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	parentFound := parentID == 0
+	for _, genre := range genres {
+		if genre.ID() == parentID {
+			parentFound = true
+		}
+		if genre.ParentID() == parentID && strings.EqualFold(genre.Name(), name) {
+			em := fmt.Sprintf("there is already a genre %s there", genre.Name())
+			return nil, errors.New(em)
+		}
+	}
+	if !parentFound {
+		em := fmt.Sprintf("no genre with ID %d", parentID)
+		return nil, errors.New(em)
+	}
+
+	genre := gorpGenreModel.MakeInitialisedGenre(0, name, parentID)
+	tx, err := gmtr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	err = tx.Insert(genre)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	logger.Info("created genre", "genre", genre.String())
+	return gmtr.FindGenreByID(genre.ID())
+}
+
+// GenresOf returns the genres of the given subject, with their paths, in order of
+// path.
+func (gmtr GorpMysqlRepo) GenresOf(subject string, subjectID uint64) ([]genreModel.Genre, error) {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "GenresOf()"
+	logger.Debug(m, "subject", subject, "subject_id", subjectID)
+	links, err := gmtr.session.FindGenreLinks(subject, subjectID)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	genres, err := gmtr.FindAllGenres()
+	if err != nil {
+		return nil, err
+	}
+	linked := make(map[uint64]bool)
+	for _, link := range links {
+		linked[link.GenreID()] = true
+	}
+	result := make([]genreModel.Genre, 0, len(links))
+	for _, genre := range genres {
+		if linked[genre.ID()] {
+			result = append(result, genre)
+		}
+	}
+	return result, nil
+}
+
+// AddGenreTo puts the given subject into the genre with the given ID.
+func (gmtr GorpMysqlRepo) AddGenreTo(genreID uint64, subject string, subjectID uint64) error {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "AddGenreTo()"
+	logger.Debug(m, "genre_id", genreID, "subject", subject, "subject_id", subjectID)
+	err := checkSubject(subject)
+	if err != nil {
+		return err
+	}
+	_, err = gmtr.FindGenreByID(genreID)
+	if err != nil {
+		return err
+	}
+	links, err := gmtr.session.FindGenreLinks(subject, subjectID)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return err
+	}
+	for _, link := range links {
+		if link.GenreID() == genreID {
+			return nil
+		}
+	}
+	return gmtr.insert(gorpGenreModel.MakeInitialisedLink(0, genreID, subject, subjectID))
+}
+
+// RemoveGenreFrom takes the given subject out of the genre with the given ID.
+func (gmtr GorpMysqlRepo) RemoveGenreFrom(genreID uint64, subject string, subjectID uint64) error {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "RemoveGenreFrom()"
+	logger.Debug(m, "genre_id", genreID, "subject", subject, "subject_id", subjectID)
+	links, err := gmtr.session.FindGenreLinks(subject, subjectID)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return err
+	}
+	doomed := make([]interface{}, 0, 1)
+	for _, link := range links {
+		if link.GenreID() == genreID {
+			doomed = append(doomed, link)
+		}
+	}
+	if len(doomed) == 0 {
+		em := fmt.Sprintf("the %s record with ID %d is not in the genre with ID %d",
+			subject, subjectID, genreID)
+		return errors.New(em)
+	}
+	return gmtr.delete(doomed)
+}
+
+// FindAllTags returns the tags that are in use, in order of name, with their
+// counts.
+func (gmtr GorpMysqlRepo) FindAllTags() ([]tagModel.Tag, error) {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "FindAllTags()"
+	tags, err := gmtr.session.FindAllTags()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// FindTagByID fetches the tag with the given ID, with its count.
+func (gmtr GorpMysqlRepo) FindTagByID(id uint64) (tagModel.Tag, error) {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "FindTagByID()"
+	logger.Debug(m, "id", id)
+	tag, err := gmtr.session.FindTagByID(id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no tag with ID %d", id)
+	}
+	return tag, err
+}
+
+// FindTagByIDStr fetches the tag with the given string ID.  The method checks
+// that the given ID is numeric before it makes the call.
+func (gmtr GorpMysqlRepo) FindTagByIDStr(idStr string) (tagModel.Tag, error) {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "FindTagByIDStr()"
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		em := fmt.Sprintf("ID %s is not an unsigned integer", idStr)
+		logger.Error(m, "error", em)
+		return nil, errors.New(em)
+	}
+	return gmtr.FindTagByID(id)
+}
+
+// TagsOf returns the tags of the given subject, in order of name.
+func (gmtr GorpMysqlRepo) TagsOf(subject string, subjectID uint64) ([]tagModel.Tag, error) {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "TagsOf()"
+	logger.Debug(m, "subject", subject, "subject_id", subjectID)
+	links, err := gmtr.session.FindTagLinks(subject, subjectID)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	tags := make([]tagModel.Tag, 0, len(links))
+	for _, link := range links {
+		tag, err := gmtr.session.FindTagByID(link.TagID())
+		if err != nil {
+			logger.Error(m, "error", err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Name() < tags[j].Name() })
+	return tags, nil
+}
+
+// AddTagTo attaches the tag with the given name to the given subject, creating
+// the tag if necessary.  The tag and the link are created in one transaction.
+func (gmtr GorpMysqlRepo) AddTagTo(name string, subject string, subjectID uint64) (tagModel.Tag, error) {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "AddTagTo()"
+	logger.Debug(m, "name", name, "subject", subject, "subject_id", subjectID)
+	err := checkSubject(subject)
+	if err != nil {
+		return nil, err
+	}
+	name = tagModel.Normalise(name)
+	if name == "" {
+		return nil, errors.New("the tag must have a name")
+	}
+	if len(name) > tagModel.MaxNameLength {
+		em := fmt.Sprintf("the tag must be at most %d characters", tagModel.MaxNameLength)
+		return nil, errors.New(em)
+	}
+
+	tag, err := gmtr.session.FindTagByName(name)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	if err == nil {
+		links, err := gmtr.session.FindTagLinks(subject, subjectID)
+		if err != nil {
+			logger.Error(m, "error", err)
+			return nil, err
+		}
+		for _, link := range links {
+			if link.TagID() == tag.ID() {
+				return tag, nil
+			}
+		}
+	}
+
+	tx, err := gmtr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	if tag == nil {
+		tag = gorpTagModel.MakeInitialisedTag(0, name)
+		err = tx.Insert(tag)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(m, "error", err)
+			return nil, err
+		}
+		logger.Info("created tag", "tag", tag.String())
+	}
+	err = tx.Insert(gorpTagModel.MakeInitialisedLink(0, tag.ID(), subject, subjectID))
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	tag.SetCount(tag.Count() + 1)
+	return tag, nil
+}
+
+// RemoveTagFrom removes the tag with the given ID from the given subject.  The tag
+// itself is kept, but it's not shown once nothing has it.
+func (gmtr GorpMysqlRepo) RemoveTagFrom(tagID uint64, subject string, subjectID uint64) error {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "RemoveTagFrom()"
+	logger.Debug(m, "tag_id", tagID, "subject", subject, "subject_id", subjectID)
+	links, err := gmtr.session.FindTagLinks(subject, subjectID)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return err
+	}
+	doomed := make([]interface{}, 0, 1)
+	for _, link := range links {
+		if link.TagID() == tagID {
+			doomed = append(doomed, link)
+		}
+	}
+	if len(doomed) == 0 {
+		em := fmt.Sprintf("the %s record with ID %d does not have the tag with ID %d",
+			subject, subjectID, tagID)
+		return errors.New(em)
+	}
+	return gmtr.delete(doomed)
+}
+
+// insert adds the given record within a transaction.
+func (gmtr GorpMysqlRepo) insert(record interface{}) error {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "insert()"
+	tx, err := gmtr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return err
+	}
+	err = tx.Insert(record)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return err
+	}
+	return nil
+}
+
+// delete removes the given records within a transaction.
+func (gmtr GorpMysqlRepo) delete(records []interface{}) error {
+	logger := logging.FromContext(gmtr.ctx)
+	m := "delete()"
+	tx, err := gmtr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return err
+	}
+	_, err = tx.Delete(records...)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return err
+	}
+	return nil
+}
+
+// checkSubject returns an error if genres and tags can't be attached to the given
+// subject.
+func checkSubject(subject string) error {
+	switch subject {
+	case dbsession.SubjectFilm, dbsession.SubjectPerson:
+		return nil
+	}
+	return fmt.Errorf("genres and tags cannot be attached to %s", subject)
+}
+
+// sortByPath sorts the genres by path, ignoring case, so that each genre comes
+// just after its parent.  The paths are compared a name at a time, so that
+// "Thriller > Spy" comes before "Thriller-Comedy".
+func sortByPath(genres []genreModel.Genre) {
+	sort.SliceStable(genres, func(i, j int) bool {
+		a := strings.Split(strings.ToLower(genres[i].Path()), genreModel.PathSeparator)
+		b := strings.Split(strings.ToLower(genres[j].Path()), genreModel.PathSeparator)
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+}
@@ -0,0 +1,205 @@
+package taxonomy
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	filmModel "github.com/goblimey/films/models/film/gorpmysql"
+	personModel "github.com/goblimey/films/models/person/gorpmysql"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+	dbsession "github.com/goblimey/films/utilities/dbsession"
+)
+
+// This is an integration test for the GorpMysqlRepo connecting to a MySQL DB via GORP.
+// The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.  Genres can't be
+// deleted, so each run uses new names.
+
+// Build a small tree of genres, put a film in the child genre, and check that the
+// film is found by filtering on either genre.
+func TestIntGenreTreeAndFilter(t *testing.T) {
+	log.SetPrefix("TestIntGenreTreeAndFilter")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+
+	rootName := fmt.Sprintf("Thriller %d", time.Now().UnixNano())
+	root, err := repo.AddGenre(rootName, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	child, err := repo.AddGenre("Psychological Thriller", root.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	expectedPath := rootName + " > Psychological Thriller"
+
+	// Two genres with the same parent can't have the same name, and the parent
+	// must exist.
+	_, err = repo.AddGenre("psychological thriller", root.ID())
+	if err == nil {
+		t.Errorf("expected an error creating a second genre with the same name")
+	}
+	_, err = repo.AddGenre("Orphan", child.ID()+1000)
+	if err == nil {
+		t.Errorf("expected an error creating a genre with a missing parent")
+	}
+
+	genre, err := repo.FindGenreByID(child.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if genre.Path() != expectedPath {
+		t.Errorf("expected path %s actually %s", expectedPath, genre.Path())
+	}
+
+	film, err := films.Create(filmModel.MakeInitialisedFilm(0, "Vertigo", 1958, 128, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(film.ID())
+
+	// Putting the film into the genre twice only links it once.
+	for i := 0; i < 2; i++ {
+		err = repo.AddGenreTo(child.ID(), dbsession.SubjectFilm, film.ID())
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	genres, err := repo.GenresOf(dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(genres) != 1 || genres[0].Path() != expectedPath {
+		t.Fatalf("expected the film to be in %s, got %v", expectedPath, genres)
+	}
+
+	// Filtering on the parent genre finds the film in the child genre.
+	for _, genreID := range []uint64{root.ID(), child.ID()} {
+		found, err := films.FindMatching(dbsession.FilmQuery{GenreID: genreID})
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if len(found) != 1 || found[0].ID() != film.ID() {
+			t.Errorf("genre %d: expected to find just the film, got %d films",
+				genreID, len(found))
+		}
+	}
+
+	err = repo.RemoveGenreFrom(child.ID(), dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = repo.RemoveGenreFrom(child.ID(), dbsession.SubjectFilm, film.ID())
+	if err == nil {
+		t.Errorf("expected an error removing the film from a genre that it's not in")
+	}
+	found, err := films.FindMatching(dbsession.FilmQuery{GenreID: root.ID()})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(found) != 0 {
+		t.Errorf("expected no films in the genre, got %d", len(found))
+	}
+}
+
+// Tag a film and a person with the same tag, spelled differently, and check the
+// count, the filter and that the tag drops out of the list once it's unused.
+func TestIntTagFilmAndPerson(t *testing.T) {
+	log.SetPrefix("TestIntTagFilmAndPerson")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+	people := peopleRepo.MakeRepo(session)
+
+	film, err := films.Create(filmModel.MakeInitialisedFilm(0, "Touch of Evil", 1958, 95, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(film.ID())
+	person, err := people.Create(personModel.MakeInitialisedPerson(0, "Orson", "Welles"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer func() {
+		people.DeleteByID(person.ID())
+		people.Purge(person.ID())
+	}()
+
+	name := fmt.Sprintf("noir %d", time.Now().UnixNano())
+	tag, err := repo.AddTagTo(name, dbsession.SubjectFilm, film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if tag.Name() != name {
+		t.Errorf("expected tag %s actually %s", name, tag.Name())
+	}
+	// The same tag, spelled differently, and the same tag twice.
+	for i := 0; i < 2; i++ {
+		sameTag, err := repo.AddTagTo("  NOIR  "+name[5:], dbsession.SubjectPerson, person.ID())
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if sameTag.ID() != tag.ID() {
+			t.Errorf("expected tag ID %d actually %d", tag.ID(), sameTag.ID())
+		}
+	}
+
+	tag, err = repo.FindTagByID(tag.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if tag.Count() != 2 {
+		t.Errorf("expected the tag to be used twice, actually %d", tag.Count())
+	}
+	tags, err := repo.TagsOf(dbsession.SubjectPerson, person.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(tags) != 1 || tags[0].ID() != tag.ID() {
+		t.Errorf("expected the person to have the tag, got %v", tags)
+	}
+
+	found, total, err := people.FindPage(dbsession.PeopleQuery{TagID: tag.ID(),
+		Sort: dbsession.SortBySurname})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if total != 1 || found[0].ID() != person.ID() {
+		t.Errorf("expected to find just the person with the tag, got %d", total)
+	}
+
+	// Once nothing has the tag, it's not listed.
+	for _, subject := range []string{dbsession.SubjectFilm, dbsession.SubjectPerson} {
+		id := film.ID()
+		if subject == dbsession.SubjectPerson {
+			id = person.ID()
+		}
+		err = repo.RemoveTagFrom(tag.ID(), subject, id)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	tags, err = repo.FindAllTags()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for _, unused := range tags {
+		if unused.ID() == tag.ID() {
+			t.Errorf("expected the unused tag %s not to be listed", unused.Name())
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package taxonomy
+
+import (
+	"context"
+
+	genreModel "github.com/goblimey/films/models/genre"
+	tagModel "github.com/goblimey/films/models/tag"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// Repository is the interface defining a repository (AKA a Data Access Object) for
+// the genres and tags and the tables that link them to films and people.  The
+// subject of a link is dbsession.SubjectFilm or dbsession.SubjectPerson.
+type Repository interface {
+	SetSession(session dbsession.DBSession)
+
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		FindAllGenres returns all of the genres, each with its path filled in, in
+		order of path, so that each genre comes just after its parent.
+	*/
+	FindAllGenres() ([]genreModel.Genre, error)
+
+	/*
+		FindGenreByID fetches the genre with the given ID, with its path filled in.
+	*/
+	FindGenreByID(id uint64) (genreModel.Genre, error)
+
+	/*
+		AddGenre creates a genre with the given name below the genre with the given
+		parent ID, or at the top of the tree if the parent ID is 0.  Two genres with
+		the same parent can't have the same name.
+	*/
+	AddGenre(name string, parentID uint64) (genreModel.Genre, error)
+
+	/*
+		GenresOf returns the genres of the given subject, with their paths filled
+		in, in order of path.
+	*/
+	GenresOf(subject string, subjectID uint64) ([]genreModel.Genre, error)
+
+	/*
+		AddGenreTo puts the given subject into the genre with the given ID.  If it's
+		already in that genre, nothing changes.
+	*/
+	AddGenreTo(genreID uint64, subject string, subjectID uint64) error
+
+	/*
+		RemoveGenreFrom takes the given subject out of the genre with the given ID.
+		If the subject is not in the genre, it returns an error.
+	*/
+	RemoveGenreFrom(genreID uint64, subject string, subjectID uint64) error
+
+	/*
+		FindAllTags returns the tags that are attached to at least one film or
+		person, in order of name, each with the number of things that it's attached
+		to.
+	*/
+	FindAllTags() ([]tagModel.Tag, error)
+
+	/*
+		FindTagByID fetches the tag with the given ID, with the number of things that
+		it's attached to.
+	*/
+	FindTagByID(id uint64) (tagModel.Tag, error)
+
+	/*
+		FindTagByIDStr fetches the tag with the given string ID.  The ID in the
+		database is numeric and the method checks that the given ID is also numeric
+		before it makes the call.  If not, it returns an error.
+	*/
+	FindTagByIDStr(idStr string) (tagModel.Tag, error)
+
+	/*
+		TagsOf returns the tags of the given subject, in order of name.
+	*/
+	TagsOf(subject string, subjectID uint64) ([]tagModel.Tag, error)
+
+	/*
+		AddTagTo attaches the tag with the given name to the given subject and
+		returns the tag.  The name is normalised - see tag.Normalise - and the tag
+		is created if nobody has used it before.  If the subject already has the
+		tag, nothing changes.
+	*/
+	AddTagTo(name string, subject string, subjectID uint64) (tagModel.Tag, error)
+
+	/*
+		RemoveTagFrom removes the tag with the given ID from the given subject.  If
+		the subject doesn't have the tag, it returns an error.
+	*/
+	RemoveTagFrom(tagID uint64, subject string, subjectID uint64) error
+}
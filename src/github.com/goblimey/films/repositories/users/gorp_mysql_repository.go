@@ -0,0 +1,197 @@
+// Package users provides the operations on the users resource - the people who
+// can log in to the server.  That resource is referenced via a database session
+// that is supplied by the parent.
+//
+// The GorpMysqlRepo satisfies the Repository interface.
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	userModel "github.com/goblimey/films/models/user"
+	gorpUserModel "github.com/goblimey/films/models/user/gorpmysql"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrBadLogin is returned by Authenticate when the username or the password is
+// wrong.
+var ErrBadLogin = errors.New("the username or password is wrong")
+
+// ErrUsernameTaken is returned by Add when there is already a user with the
+// given username.
+var ErrUsernameTaken = errors.New("that username is taken")
+
+// MinPasswordLength is the length of the shortest password that Add accepts.
+const MinPasswordLength = 8
+
+// maxPasswordLength is the length of the longest password that Add accepts.
+// bcrypt only uses the first 72 bytes.
+const maxPasswordLength = 72
+
+// validUsername matches an acceptable username - up to 64 letters, digits, dots,
+// underscores and hyphens.
+var validUsername = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// dummyHash is compared with the password when there is no such user, so that a
+// failed login takes about as long whether or not the user exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("no such user"), bcrypt.DefaultCost)
+
+// GorpMysqlRepo satifies the Repository interface.
+type GorpMysqlRepo struct {
+	session dbsession.DBSession
+	ctx     context.Context
+}
+
+// MakeRepo is a factory function that creates a GorpMysqlRepo and returns it as a
+// Repository.
+func MakeRepo(session dbsession.DBSession) Repository {
+	return &GorpMysqlRepo{session: session}
+}
+
+// SetSession sets the session.
+func (gmur *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
+	gmur.session = session
+}
+
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmur GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmur.ctx = ctx
+	gmur.session = gmur.session.WithContext(ctx)
+	return &gmur
+}
+
+// Add creates a user with the given username, password and role, storing a
+// bcrypt hash of the password.
+func (gmur GorpMysqlRepo) Add(username string, password string, role string) (userModel.User, error) {
+	logger := logging.FromContext(gmur.ctx)
+	m := "Add()"
+	logger.Debug(m, "username", username, "role", role)
+	if !userModel.ValidRole(role) {
+		return nil, badRole(role)
+	}
+	if !validUsername.MatchString(username) {
+		return nil, errors.New("the username must be up to 64 letters, digits, dots, underscores and hyphens")
+	}
+	if len(password) < MinPasswordLength {
+		return nil, fmt.Errorf("the password must be at least %d characters", MinPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return nil, fmt.Errorf("the password must be no more than %d bytes", maxPasswordLength)
+	}
+
+	_, err := gmur.session.FindUserByUsername(username)
+	if err == nil {
+		return nil, ErrUsernameTaken
+	}
+	if err != sql.ErrNoRows {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	user := gorpUserModel.MakeInitialisedUser(0, username, string(hash), role)
+
+	tx, err := gmur.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	err = tx.Insert(user)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	logger.Info("created user", "user", user.String())
+	return user, nil
+}
+
+// Authenticate checks the given username and password and returns the user, or
+// ErrBadLogin.
+func (gmur GorpMysqlRepo) Authenticate(username string, password string) (userModel.User, error) {
+	logger := logging.FromContext(gmur.ctx)
+	m := "Authenticate()"
+	user, err := gmur.session.FindUserByUsername(username)
+	if err == sql.ErrNoRows {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		logger.Info(m+" - no such user", "username", username)
+		return nil, ErrBadLogin
+	}
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash()), []byte(password))
+	if err != nil {
+		logger.Info(m+" - wrong password", "username", username)
+		return nil, ErrBadLogin
+	}
+	return user, nil
+}
+
+// FindByUsername fetches the user with the given username.
+func (gmur GorpMysqlRepo) FindByUsername(username string) (userModel.User, error) {
+	return gmur.session.FindUserByUsername(username)
+}
+
+// SetRole changes the role of the user with the given username.
+func (gmur GorpMysqlRepo) SetRole(username string, role string) (userModel.User, error) {
+	logger := logging.FromContext(gmur.ctx)
+	m := "SetRole()"
+	if !userModel.ValidRole(role) {
+		return nil, badRole(role)
+	}
+	user, err := gmur.session.FindUserByUsername(username)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	user.SetRole(role)
+
+	tx, err := gmur.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	_, err = tx.Update(user)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	logger.Info("changed role", "username", username, "role", role)
+	return user, nil
+}
+
+// badRole returns the error for a role that doesn't exist.
+func badRole(role string) error {
+	return fmt.Errorf("there is no role %q - the role must be one of %s", role,
+		strings.Join(userModel.Roles, ", "))
+}
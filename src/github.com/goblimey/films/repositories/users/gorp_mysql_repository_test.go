@@ -0,0 +1,104 @@
+package users
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	userModel "github.com/goblimey/films/models/user"
+	dbsession "github.com/goblimey/films/utilities/dbsession"
+)
+
+// This is an integration test for the GorpMysqlRepo connecting to a database via
+// GORP.  The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.
+
+// Add a user, then check that they can log in with the right password and not
+// with a wrong one, and that nobody else can take the username.
+func TestIntAddAndAuthenticate(t *testing.T) {
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+	repo := MakeRepo(session)
+
+	// The users are never deleted, so make the name unique to this run.
+	username := fmt.Sprintf("test%d", time.Now().UnixNano())
+
+	user, err := repo.Add(username, "correct horse", userModel.RoleEditor)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if user.ID() == 0 {
+		t.Errorf("expected an ID to be assigned")
+	}
+	if user.PasswordHash() == "correct horse" {
+		t.Errorf("expected the password to be hashed")
+	}
+
+	found, err := repo.Authenticate(username, "correct horse")
+	if err != nil {
+		t.Fatalf("expected the login to succeed - %s", err.Error())
+	}
+	if found.ID() != user.ID() || found.Role() != userModel.RoleEditor {
+		t.Errorf("expected editor %d, got %s %d", user.ID(), found.Role(), found.ID())
+	}
+	_, err = repo.Authenticate(username, "wrong horse")
+	if err != ErrBadLogin {
+		t.Errorf("expected ErrBadLogin for the wrong password, got %v", err)
+	}
+	_, err = repo.Authenticate(username+"x", "correct horse")
+	if err != ErrBadLogin {
+		t.Errorf("expected ErrBadLogin for an unknown user, got %v", err)
+	}
+	_, err = repo.Add(username, "another password", userModel.RoleEditor)
+	if err != ErrUsernameTaken {
+		t.Errorf("expected ErrUsernameTaken, got %v", err)
+	}
+
+	_, err = repo.SetRole(username, userModel.RoleAdmin)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	found, err = repo.FindByUsername(username)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if found.Role() != userModel.RoleAdmin {
+		t.Errorf("expected the role to be changed to admin, got %s", found.Role())
+	}
+	_, err = repo.SetRole(username, "superuser")
+	if err == nil {
+		t.Errorf("expected an error for a role that doesn't exist")
+	}
+}
+
+// Check that Add refuses bad usernames, short passwords and unknown roles.
+func TestIntAddRejectsBadDetails(t *testing.T) {
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+	repo := MakeRepo(session)
+
+	var tests = []struct {
+		username string
+		password string
+		role     string
+	}{
+		{"", "long enough", userModel.RoleViewer},
+		{"has space", "long enough", userModel.RoleViewer},
+		{"shortpass", "short", userModel.RoleViewer},
+		{"longpass", strings.Repeat("x", 73), userModel.RoleViewer},
+		{"badrole", "long enough", "superuser"},
+	}
+	for _, test := range tests {
+		_, err := repo.Add(test.username, test.password, test.role)
+		if err == nil {
+			t.Errorf("%q/%q/%q: expected an error", test.username, test.password, test.role)
+		}
+	}
+}
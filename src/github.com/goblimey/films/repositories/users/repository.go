@@ -0,0 +1,47 @@
+package users
+
+import (
+	"context"
+
+	userModel "github.com/goblimey/films/models/user"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// Repository is the interface defining a repository (AKA a Data Access Object) for
+// the users table, which holds the people who can log in.
+type Repository interface {
+	SetSession(session dbsession.DBSession)
+
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		Add creates a user with the given username, password and role.  The
+		password is stored as a bcrypt hash.  If the username is taken, it returns
+		ErrUsernameTaken.  If the username, password or role is not acceptable, it
+		returns an error saying why.
+	*/
+	Add(username string, password string, role string) (userModel.User, error)
+
+	/*
+		Authenticate checks the given username and password and returns the user.
+		If there is no such user or the password is wrong, it returns ErrBadLogin,
+		without saying which.
+	*/
+	Authenticate(username string, password string) (userModel.User, error)
+
+	/*
+		FindByUsername fetches the user with the given username.  If there is no
+		such user, it returns sql.ErrNoRows.
+	*/
+	FindByUsername(username string) (userModel.User, error)
+
+	/*
+		SetRole changes the role of the user with the given username and returns
+		the user.  If there is no such user, it returns sql.ErrNoRows.
+	*/
+	SetRole(username string, role string) (userModel.User, error)
+}
@@ -0,0 +1,146 @@
+// Package watchlist provides operations on the users' watchlists - the films that
+// each user wants to watch.  The watchlist table is referenced via a database
+// session that is supplied by the parent.
+//
+// The GorpMysqlRepo satisfies the Repository interface.
+package watchlist
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	gorpWatchlistModel "github.com/goblimey/films/models/watchlist/gorpmysql"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// GorpMysqlRepo satifies the Repository interface.
+type GorpMysqlRepo struct {
+	session dbsession.DBSession
+	ctx     context.Context
+}
+
+// MakeRepo is a factory function that creates a GorpMysqlRepo and returns it as a
+// Repository.
+func MakeRepo(session dbsession.DBSession) Repository {
+	return &GorpMysqlRepo{session: session}
+}
+
+// SetSession sets the session.
+func (gmwr *GorpMysqlRepo) SetSession(session dbsession.DBSession) {
+	gmwr.session = session
+}
+
+// WithContext returns a copy of the repository that logs against the given
+// context, which carries the logger of the request being served.  The session is
+// given the context too.
+func (gmwr GorpMysqlRepo) WithContext(ctx context.Context) Repository {
+	gmwr.ctx = ctx
+	gmwr.session = gmwr.session.WithContext(ctx)
+	return &gmwr
+}
+
+// FindByID fetches the watchlist entry with the given uint64 id.
+func (gmwr GorpMysqlRepo) FindByID(id uint64) (watchlistModel.Entry, error) {
+	logger := logging.FromContext(gmwr.ctx)
+	m := "FindByID()"
+	logger.Debug(m, "id", id)
+	return gmwr.session.FindWatchlistEntryByID(id)
+}
+
+// FindByUser returns the watchlist of the user with the given ID.
+func (gmwr GorpMysqlRepo) FindByUser(userID uint64) ([]watchlistModel.Entry, error) {
+	logger := logging.FromContext(gmwr.ctx)
+	m := "FindByUser()"
+	logger.Debug(m, "user_id", userID)
+	return gmwr.session.FindWatchlistByUser(userID)
+}
+
+// FindByUserAndFilm fetches the entry for the film with the given ID on the
+// watchlist of the user with the given ID.
+func (gmwr GorpMysqlRepo) FindByUserAndFilm(userID uint64, filmID uint64) (watchlistModel.Entry, error) {
+	logger := logging.FromContext(gmwr.ctx)
+	m := "FindByUserAndFilm()"
+	logger.Debug(m, "user_id", userID, "film_id", filmID)
+	return gmwr.session.FindWatchlistEntryByUserAndFilm(userID, filmID)
+}
+
+// Add puts the film on the user's watchlist.  If it's already there, it returns
+// an error.
+func (gmwr GorpMysqlRepo) Add(entry watchlistModel.Entry) (watchlistModel.Entry, error) {
+	logger := logging.FromContext(gmwr.ctx)
+	m := "Add()"
+	logger.Debug(m, "entry", entry.String())
+	existing, err := gmwr.session.FindWatchlistEntryByUserAndFilm(entry.UserID(), entry.FilmID())
+	if err == nil {
+		em := fmt.Sprintf("%s is already on the watchlist", existing.FilmTitle())
+		logger.Error(m, "error", em)
+		return nil, errors.New(em)
+	}
+	if err != sql.ErrNoRows {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	tx, err := gmwr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	entry.SetID(0) // provokes the auto-increment
+	entry.SetAddedAt(time.Now())
+	err = tx.Insert(entry)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+
+	logger.Info("added film to watchlist", "entry", entry.String())
+	return entry, nil
+}
+
+// DeleteByID takes the entry with the given uint64 ID off the watchlist.
+func (gmwr GorpMysqlRepo) DeleteByID(id uint64) (int64, error) {
+	logger := logging.FromContext(gmwr.ctx)
+	m := "DeleteByID()"
+	logger.Debug(m, "id", id)
+	// Need an Entry record for the delete method, so fake one up.
+	var entry gorpWatchlistModel.GorpMysqlEntry
+	entry.SetID(id)
+	tx, err := gmwr.session.StartTransaction()
+	if err != nil {
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	rowsDeleted, err := tx.Delete(&entry)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	if rowsDeleted != 1 {
+		tx.Rollback()
+		em := fmt.Sprintf("delete failed - %d rows would have been deleted, expected 1", rowsDeleted)
+		logger.Error(m, "error", em)
+		return 0, errors.New(em)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		logger.Error(m, "error", err)
+		return 0, err
+	}
+	return rowsDeleted, nil
+}
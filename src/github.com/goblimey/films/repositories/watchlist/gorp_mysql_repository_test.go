@@ -0,0 +1,108 @@
+package watchlist
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	filmModel "github.com/goblimey/films/models/film/gorpmysql"
+	userModel "github.com/goblimey/films/models/user"
+	gorpWatchlistModel "github.com/goblimey/films/models/watchlist/gorpmysql"
+	filmsRepo "github.com/goblimey/films/repositories/films"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	dbsession "github.com/goblimey/films/utilities/dbsession"
+)
+
+// This is an integration test for the GorpMysqlRepo connecting to a MySQL DB via GORP.
+// The database is given by FILMS_TEST_DIALECT and FILMS_TEST_DSN.
+
+// A user adds two films to their watchlist, tries to add one of them again and
+// then removes it.  Deleting the other film takes it off the list.
+func TestIntAddToAndRemoveFromWatchlist(t *testing.T) {
+	log.SetPrefix("TestIntAddToAndRemoveFromWatchlist")
+	session, err := dbsession.MakeDBSession(os.Getenv("FILMS_TEST_DIALECT"), os.Getenv("FILMS_TEST_DSN"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer session.Close()
+
+	repo := MakeRepo(session)
+	films := filmsRepo.MakeRepo(session)
+	users := usersRepo.MakeRepo(session)
+
+	rope, err := films.Create(filmModel.MakeInitialisedFilm(0, "Rope", 1948, 80, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(rope.ID())
+	marnie, err := films.Create(filmModel.MakeInitialisedFilm(0, "Marnie", 1964, 130, ""))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer films.DeleteByID(marnie.ID())
+	vera, err := users.Add(fmt.Sprintf("vera%d", time.Now().UnixNano()), "pw12345678",
+		userModel.RoleViewer)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = repo.Add(gorpWatchlistModel.MakeInitialisedEntry(0, vera.ID(), rope.ID(), time.Time{}))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.Add(gorpWatchlistModel.MakeInitialisedEntry(0, vera.ID(), marnie.ID(), time.Time{}))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = repo.Add(gorpWatchlistModel.MakeInitialisedEntry(0, vera.ID(), rope.ID(), time.Time{}))
+	if err == nil {
+		t.Errorf("expected Rope to be refused the second time")
+	}
+
+	entries, err := repo.FindByUser(vera.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.AddedAt().IsZero() || entry.FilmTitle() == "" {
+			t.Errorf("expected the time added and the title of the film, got %s", entry.String())
+		}
+	}
+
+	entry, err := repo.FindByUserAndFilm(vera.ID(), rope.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if entry.FilmTitle() != "Rope" || entry.FilmReleaseYear() != 1948 {
+		t.Errorf("expected Rope (1948), got %s", entry.String())
+	}
+	rows, err := repo.DeleteByID(entry.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("expected 1 row deleted, got %d", rows)
+	}
+	_, err = repo.FindByUserAndFilm(vera.ID(), rope.ID())
+	if err == nil {
+		t.Errorf("expected Rope to be gone from the watchlist")
+	}
+
+	// Deleting the film takes it off the watchlist.
+	_, err = films.DeleteByID(marnie.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	entries, err = repo.FindByUser(vera.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected an empty watchlist, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,52 @@
+package watchlist
+
+import (
+	"context"
+
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// Repository is the interface for the watchlist repository, which holds the films
+// that each user wants to watch.
+type Repository interface {
+	SetSession(session dbsession.DBSession)
+
+	/*
+		WithContext returns a copy of the repository that logs against the given
+		context, which carries the logger of the request being served.
+	*/
+	WithContext(ctx context.Context) Repository
+
+	/*
+		FindByID fetches the watchlist entry with the given uint64 id, along with
+		the title of the film.
+	*/
+	FindByID(id uint64) (watchlistModel.Entry, error)
+
+	/*
+		FindByUser returns the watchlist of the user with the given ID, most
+		recently added first.
+	*/
+	FindByUser(userID uint64) ([]watchlistModel.Entry, error)
+
+	/*
+		FindByUserAndFilm fetches the entry for the film with the given ID on the
+		watchlist of the user with the given ID.  If the film is not on their list,
+		it returns sql.ErrNoRows.
+	*/
+	FindByUserAndFilm(userID uint64, filmID uint64) (watchlistModel.Entry, error)
+
+	/*
+		Add puts the film in the given entry on the user's watchlist, recording the
+		time.  A film can only be on a user's list once.  It returns the entry,
+		including its ID.
+	*/
+	Add(entry watchlistModel.Entry) (watchlistModel.Entry, error)
+
+	/*
+		DeleteByID takes the entry with the given uint64 ID off the watchlist.  On a
+		successful delete, it should return 1.
+	*/
+	DeleteByID(id uint64) (int64, error)
+}
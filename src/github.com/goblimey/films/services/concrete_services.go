@@ -1,30 +1,201 @@
 package services
 
 import (
+	"time"
+
+	awardsRepo "github.com/goblimey/films/repositories/awards"
+	companiesRepo "github.com/goblimey/films/repositories/companies"
+	creditsRepo "github.com/goblimey/films/repositories/credits"
+	diaryRepo "github.com/goblimey/films/repositories/diary"
+	filmsRepo "github.com/goblimey/films/repositories/films"
 	peopleRepo "github.com/goblimey/films/repositories/people"
+	reviewsRepo "github.com/goblimey/films/repositories/reviews"
+	taxonomyRepo "github.com/goblimey/films/repositories/taxonomy"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	watchlistRepo "github.com/goblimey/films/repositories/watchlist"
 	"github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/search"
 )
 
 type ConcreteServices struct {
-	peopleRepo  peopleRepo.Repository
-	templateMap *map[string]template.Template
+	peopleRepo    peopleRepo.Repository
+	filmRepo      filmsRepo.Repository
+	creditRepo    creditsRepo.Repository
+	taxonomyRepo  taxonomyRepo.Repository
+	companyRepo   companiesRepo.Repository
+	reviewRepo    reviewsRepo.Repository
+	watchlistRepo watchlistRepo.Repository
+	diaryRepo     diaryRepo.Repository
+	awardRepo     awardsRepo.Repository
+	userRepo      usersRepo.Repository
+	sessions      auth.Sessions
+	session       dbsession.DBSession
+	searchIndex   search.Index
+	templateMap   *map[string]template.Template
+	retention     time.Duration
 }
 
 func (cs ConcreteServices) GetPeopleRepository() peopleRepo.Repository {
 	return cs.peopleRepo
 }
 
+// GetFilmRepository returns the repository for the films resource.
+func (cs ConcreteServices) GetFilmRepository() filmsRepo.Repository {
+	return cs.filmRepo
+}
+
+// GetCreditRepository returns the repository for the credits that link people
+// to films.
+func (cs ConcreteServices) GetCreditRepository() creditsRepo.Repository {
+	return cs.creditRepo
+}
+
+// GetTaxonomyRepository returns the repository for the genres and tags.
+func (cs ConcreteServices) GetTaxonomyRepository() taxonomyRepo.Repository {
+	return cs.taxonomyRepo
+}
+
+// GetCompanyRepository returns the repository for the companies that make and
+// distribute films.
+func (cs ConcreteServices) GetCompanyRepository() companiesRepo.Repository {
+	return cs.companyRepo
+}
+
+// GetReviewRepository returns the repository for the users' ratings and reviews
+// of films.
+func (cs ConcreteServices) GetReviewRepository() reviewsRepo.Repository {
+	return cs.reviewRepo
+}
+
+// GetWatchlistRepository returns the repository for the films that the users
+// want to watch.
+func (cs ConcreteServices) GetWatchlistRepository() watchlistRepo.Repository {
+	return cs.watchlistRepo
+}
+
+// GetDiaryRepository returns the repository for the films that the users have
+// watched.
+func (cs ConcreteServices) GetDiaryRepository() diaryRepo.Repository {
+	return cs.diaryRepo
+}
+
+// GetAwardRepository returns the repository for the award ceremonies, their
+// categories and nominations.
+func (cs ConcreteServices) GetAwardRepository() awardsRepo.Repository {
+	return cs.awardRepo
+}
+
+// GetUserRepository returns the repository for the users who can log in.
+func (cs ConcreteServices) GetUserRepository() usersRepo.Repository {
+	return cs.userRepo
+}
+
+// GetSessions returns the session cookie handler, which logs users in and out.
+func (cs ConcreteServices) GetSessions() auth.Sessions {
+	return cs.sessions
+}
+
+// GetDBSession returns the database session shared by the repositories.
+func (cs ConcreteServices) GetDBSession() dbsession.DBSession {
+	return cs.session
+}
+
+// GetSearchIndex returns the full-text index of the people and films.
+func (cs ConcreteServices) GetSearchIndex() search.Index {
+	return cs.searchIndex
+}
+
 // Template returns an HTML template, given a CRUD operation (Index, Edit etc).
+// The templates for resources other than people have the resource name as a
+// prefix, for example "FilmIndex".
 func (cs ConcreteServices) Template(operation string) template.Template {
 	return (*cs.templateMap)[operation]
 }
 
+// GetTrashRetention returns how long deleted people are kept in the trash before
+// they are purged.  0 means until they are purged by hand.
+func (cs ConcreteServices) GetTrashRetention() time.Duration {
+	return cs.retention
+}
+
 func (cs *ConcreteServices) SetPeopleRepository(repo peopleRepo.Repository) {
 	cs.peopleRepo = repo
 }
 
+// SetFilmRepository sets the repository for the films resource.
+func (cs *ConcreteServices) SetFilmRepository(repo filmsRepo.Repository) {
+	cs.filmRepo = repo
+}
+
+// SetCreditRepository sets the repository for the credits.
+func (cs *ConcreteServices) SetCreditRepository(repo creditsRepo.Repository) {
+	cs.creditRepo = repo
+}
+
+// SetTaxonomyRepository sets the repository for the genres and tags.
+func (cs *ConcreteServices) SetTaxonomyRepository(repo taxonomyRepo.Repository) {
+	cs.taxonomyRepo = repo
+}
+
+// SetCompanyRepository sets the repository for the companies.
+func (cs *ConcreteServices) SetCompanyRepository(repo companiesRepo.Repository) {
+	cs.companyRepo = repo
+}
+
+// SetReviewRepository sets the repository for the ratings and reviews.
+func (cs *ConcreteServices) SetReviewRepository(repo reviewsRepo.Repository) {
+	cs.reviewRepo = repo
+}
+
+// SetWatchlistRepository sets the repository for the watchlists.
+func (cs *ConcreteServices) SetWatchlistRepository(repo watchlistRepo.Repository) {
+	cs.watchlistRepo = repo
+}
+
+// SetDiaryRepository sets the repository for the diaries.
+func (cs *ConcreteServices) SetDiaryRepository(repo diaryRepo.Repository) {
+	cs.diaryRepo = repo
+}
+
+// SetAwardRepository sets the repository for the awards.
+func (cs *ConcreteServices) SetAwardRepository(repo awardsRepo.Repository) {
+	cs.awardRepo = repo
+}
+
+// SetUserRepository sets the repository for the users.
+func (cs *ConcreteServices) SetUserRepository(repo usersRepo.Repository) {
+	cs.userRepo = repo
+}
+
+// SetSessions sets the session cookie handler.
+func (cs *ConcreteServices) SetSessions(sessions auth.Sessions) {
+	cs.sessions = sessions
+}
+
+// SetDBSession sets the database session shared by the repositories.  It does
+// not pass the session to the repositories - the caller does that when it
+// creates them.
+func (cs *ConcreteServices) SetDBSession(session dbsession.DBSession) {
+	cs.session = session
+}
+
+// SetSearchIndex sets the full-text index of the people and films.  Like the
+// session, it's not passed to the repositories - the caller does that when it
+// creates them.
+func (cs *ConcreteServices) SetSearchIndex(index search.Index) {
+	cs.searchIndex = index
+}
+
 func (cs *ConcreteServices) SetTemplates(
 	templateMap *map[string]template.Template) {
 
 	cs.templateMap = templateMap
 }
+
+// SetTrashRetention sets how long deleted people are kept in the trash.  It's
+// used to tell the user when they will go - the purging is done elsewhere.
+func (cs *ConcreteServices) SetTrashRetention(retention time.Duration) {
+	cs.retention = retention
+}
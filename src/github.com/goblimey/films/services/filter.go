@@ -0,0 +1,35 @@
+package services
+
+import (
+	restful "github.com/emicklei/go-restful"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// attributeName is the name of the request attribute that holds the services.
+const attributeName = "services"
+
+// Filter returns a go-restful filter that gets the services and attaches them to
+// the request, where the handler can find them using FromRequest.  If the
+// services are not available, the filter calls unavailable to send an error
+// response and the handler is not run.
+func Filter(getServices Getter,
+	unavailable func(resp *restful.Response)) restful.FilterFunction {
+
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		svc, err := getServices()
+		if err != nil {
+			logging.FromRequest(req.Request).Error("cannot get the services", "error", err)
+			unavailable(resp)
+			return
+		}
+		req.SetAttribute(attributeName, svc)
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+// FromRequest returns the services attached to the request by the filter, or
+// nil if there are none.
+func FromRequest(req *restful.Request) Services {
+	svc, _ := req.Attribute(attributeName).(Services)
+	return svc
+}
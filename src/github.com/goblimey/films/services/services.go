@@ -1,16 +1,114 @@
 package services
 
 import (
+	"time"
+
+	awardsRepo "github.com/goblimey/films/repositories/awards"
+	companiesRepo "github.com/goblimey/films/repositories/companies"
+	creditsRepo "github.com/goblimey/films/repositories/credits"
+	diaryRepo "github.com/goblimey/films/repositories/diary"
+	filmsRepo "github.com/goblimey/films/repositories/films"
 	peopleRepo "github.com/goblimey/films/repositories/people"
+	reviewsRepo "github.com/goblimey/films/repositories/reviews"
+	taxonomyRepo "github.com/goblimey/films/repositories/taxonomy"
+	usersRepo "github.com/goblimey/films/repositories/users"
+	watchlistRepo "github.com/goblimey/films/repositories/watchlist"
 	"github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/dbsession"
+	"github.com/goblimey/films/utilities/search"
 )
 
 type Services interface {
 	GetPeopleRepository() peopleRepo.Repository
 
+	GetFilmRepository() filmsRepo.Repository
+
+	GetCreditRepository() creditsRepo.Repository
+
+	// GetTaxonomyRepository returns the repository for the genres and tags.
+	GetTaxonomyRepository() taxonomyRepo.Repository
+
+	// GetCompanyRepository returns the repository for the companies that make
+	// and distribute films.
+	GetCompanyRepository() companiesRepo.Repository
+
+	// GetReviewRepository returns the repository for the users' ratings and
+	// reviews of films.
+	GetReviewRepository() reviewsRepo.Repository
+
+	// GetWatchlistRepository returns the repository for the films that the users
+	// want to watch.
+	GetWatchlistRepository() watchlistRepo.Repository
+
+	// GetDiaryRepository returns the repository for the films that the users
+	// have watched.
+	GetDiaryRepository() diaryRepo.Repository
+
+	// GetAwardRepository returns the repository for the award ceremonies, their
+	// categories and nominations.
+	GetAwardRepository() awardsRepo.Repository
+
+	// GetUserRepository returns the repository for the users who can log in.
+	GetUserRepository() usersRepo.Repository
+
+	// GetSessions returns the session cookie handler, which logs users in and out.
+	GetSessions() auth.Sessions
+
+	// GetDBSession returns the database session shared by the repositories.
+	GetDBSession() dbsession.DBSession
+
+	// GetSearchIndex returns the full-text index of the people and films.
+	GetSearchIndex() search.Index
+
 	Template(operation string) template.Template
 
+	// GetTrashRetention returns how long deleted people are kept in the trash
+	// before they are purged.  0 means until they are purged by hand.
+	GetTrashRetention() time.Duration
+
 	SetPeopleRepository(dao peopleRepo.Repository)
 
+	SetFilmRepository(repo filmsRepo.Repository)
+
+	SetCreditRepository(repo creditsRepo.Repository)
+
+	// SetTaxonomyRepository sets the repository for the genres and tags.
+	SetTaxonomyRepository(repo taxonomyRepo.Repository)
+
+	// SetCompanyRepository sets the repository for the companies.
+	SetCompanyRepository(repo companiesRepo.Repository)
+
+	// SetReviewRepository sets the repository for the ratings and reviews.
+	SetReviewRepository(repo reviewsRepo.Repository)
+
+	// SetWatchlistRepository sets the repository for the watchlists.
+	SetWatchlistRepository(repo watchlistRepo.Repository)
+
+	// SetDiaryRepository sets the repository for the diaries.
+	SetDiaryRepository(repo diaryRepo.Repository)
+
+	// SetAwardRepository sets the repository for the awards.
+	SetAwardRepository(repo awardsRepo.Repository)
+
+	// SetUserRepository sets the repository for the users.
+	SetUserRepository(repo usersRepo.Repository)
+
+	// SetSessions sets the session cookie handler.
+	SetSessions(sessions auth.Sessions)
+
+	// SetDBSession sets the database session shared by the repositories.
+	SetDBSession(session dbsession.DBSession)
+
+	// SetSearchIndex sets the full-text index of the people and films.
+	SetSearchIndex(index search.Index)
+
 	SetTemplates(templateMap *map[string]template.Template)
+
+	// SetTrashRetention sets how long deleted people are kept in the trash.
+	SetTrashRetention(retention time.Duration)
 }
+
+// Getter returns the application's services, or an error if they are not
+// available, for example because the database can't be reached.
+type Getter func() (Services, error)
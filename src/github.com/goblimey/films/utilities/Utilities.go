@@ -2,19 +2,19 @@ package utilities
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"strings"
 
 	restful "github.com/emicklei/go-restful"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/logging"
 )
 
 // Dead displays a hand-crafted error page.  It's the page of last resort.
 func Dead(response *restful.Response) {
-	log.SetPrefix("Dead() ")
-	log.Println()
 	defer noPanic()
-	fmt.Sprintf("foo", "1", "2")
 	html := fmt.Sprintf("%s%s%s%s%s%s\n",
 		"<html><head></head><body>",
 		"<p><b><font color=\"red\">",
@@ -25,7 +25,7 @@ func Dead(response *restful.Response) {
 
 	_, err := fmt.Fprintln(response.ResponseWriter, html)
 	if err != nil {
-		log.Printf("error while attempting to display the error page of last resort - %s", err.Error())
+		slog.Error("error while attempting to display the error page of last resort", "error", err)
 		http.Error(response.ResponseWriter, err.Error(), http.StatusInternalServerError)
 	}
 }
@@ -33,7 +33,7 @@ func Dead(response *restful.Response) {
 // Recover from any panic and log an error.
 func noPanic() {
 	if p := recover(); p != nil {
-		log.Printf("unrecoverable internal error %v\n", p)
+		slog.Error("unrecoverable internal error", "panic", fmt.Sprint(p))
 	}
 }
 
@@ -59,3 +59,64 @@ func Map2String(m map[string]string) string {
 
 	return strings.Join(result, "")
 }
+
+// MethodOverride wraps a handler so that it sees the method given by the
+// "_method" parameter of a POST request instead of POST.  Browsers can only send
+// GET and POST, so the HTML forms simulate PUT and DELETE requests that way.
+// The method has to be changed before the request is routed, otherwise it would
+// be matched against the POST routes.
+func MethodOverride(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			switch r.FormValue("_method") {
+			case "PUT", "DELETE":
+				r.Method = r.FormValue("_method")
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Requester returns the name recorded in the audit log as the maker of the changes
+// requested by the given request - the username of the viewer who is logged in.
+// Changes can only be made by someone who is logged in, but if there is nobody,
+// it's the address of the client.
+func Requester(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	if viewer := auth.ViewerFrom(r); viewer.LoggedIn() {
+		return viewer.Username
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// SeeOther redirects the browser to the given URI with status 303 (See Other)
+// after a successful change, so that reloading the page that follows doesn't
+// submit the form again.  The notice is carried across the redirect in the
+// flash cookie and displayed once by the next page - see Flash.
+func SeeOther(req *restful.Request, resp *restful.Response, sessions auth.Sessions,
+	uri string, notice string) {
+
+	logger := logging.FromRequest(req.Request)
+	if sessions != nil && notice != "" {
+		err := sessions.SetFlash(resp.ResponseWriter, notice)
+		if err != nil {
+			logger.Warn("the notice is lost", "notice", notice, "error", err)
+		}
+	}
+	http.Redirect(resp.ResponseWriter, req.Request, uri, http.StatusSeeOther)
+}
+
+// Flash returns the notice carried across a redirect by SeeOther, or an empty
+// string if there is none.
+func Flash(req *restful.Request, resp *restful.Response, sessions auth.Sessions) string {
+	if sessions == nil {
+		return ""
+	}
+	return sessions.Flash(resp.ResponseWriter, req.Request)
+}
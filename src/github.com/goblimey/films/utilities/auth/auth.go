@@ -0,0 +1,132 @@
+// Package auth handles logging in.  A user who logs in is given a session cookie
+// holding their username, signed and encrypted with the session key so that it
+// can't be read or forged.  A filter looks at the cookie on each request and
+// attaches the viewer - the user making the request - to it, with their role.
+// Requests that change anything are refused unless someone is logged in.  The
+// controllers decide what each role can do, using the viewer's Can methods.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	userModel "github.com/goblimey/films/models/user"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// Viewer is the user making a request.  The zero value is a visitor who has not
+// logged in.
+type Viewer struct {
+	Username string
+	// Role is one of the roles defined in the user model.
+	Role string
+	// Token is the CSRF token of the browser session, which every form must
+	// send back - see the csrf package.
+	Token string
+}
+
+// LoggedIn returns true if the viewer has logged in.
+func (v Viewer) LoggedIn() bool {
+	return v.Username != ""
+}
+
+// CanEdit returns true if the viewer can create and change records - an editor
+// or an admin.
+func (v Viewer) CanEdit() bool {
+	return v.LoggedIn() && (v.Role == userModel.RoleEditor || v.Role == userModel.RoleAdmin)
+}
+
+// CanDelete returns true if the viewer can delete records, restore them from the
+// trash and purge them - an admin.
+func (v Viewer) CanDelete() bool {
+	return v.IsAdmin()
+}
+
+// IsAdmin returns true if the viewer is an admin, who can do anything,
+// including managing the users.
+func (v Viewer) IsAdmin() bool {
+	return v.LoggedIn() && v.Role == userModel.RoleAdmin
+}
+
+// Refusal returns the message that explains why the viewer can't do what they
+// asked, for example "create people".
+func (v Viewer) Refusal(what string) string {
+	if !v.LoggedIn() {
+		return fmt.Sprintf("you must log in to %s", what)
+	}
+	article := "a"
+	if strings.IndexAny(v.Role, "aeiou") == 0 {
+		article = "an"
+	}
+	return fmt.Sprintf("%s is %s %s and can't %s", v.Username, article, v.Role, what)
+}
+
+// RoleFinder looks up the role of the user with the given username.  It returns
+// an error if there is no such user.
+type RoleFinder func(req *restful.Request, username string) (string, error)
+
+// contextKey is the type of the key under which the viewer is stored in the
+// request's context.
+type contextKey int
+
+// viewerKey is the key under which the viewer is stored.
+const viewerKey contextKey = 0
+
+// WithViewer returns a copy of the request carrying the given viewer.
+func WithViewer(r *http.Request, viewer Viewer) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), viewerKey, viewer))
+}
+
+// ViewerFrom returns the viewer attached to the request, or the zero Viewer if
+// there is none.
+func ViewerFrom(r *http.Request) Viewer {
+	if r == nil {
+		return Viewer{}
+	}
+	viewer, _ := r.Context().Value(viewerKey).(Viewer)
+	return viewer
+}
+
+// Changes returns true if a request with the given method can change the data.
+func Changes(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// Filter returns a go-restful filter that attaches the viewer given by the
+// session cookie to the request.  The role is looked up on each request, so a
+// change of role takes effect straight away, and a user who has been removed
+// is treated as a visitor.  If the request can change the data and nobody
+// is logged in, the filter calls unauthorised to send the response and the
+// handler is not run.  If unauthorised is nil, every request is let through -
+// the login page needs that.
+func Filter(sessions Sessions, roles RoleFinder,
+	unauthorised func(req *restful.Request, resp *restful.Response)) restful.FilterFunction {
+
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		logger := logging.FromRequest(req.Request)
+		var viewer Viewer
+		username := sessions.Username(req.Request)
+		if username != "" {
+			role, err := roles(req, username)
+			if err != nil {
+				logger.Warn("ignoring the session", "username", username, "error", err)
+			} else {
+				viewer = Viewer{Username: username, Role: role}
+			}
+		}
+		if !viewer.LoggedIn() && Changes(req.Request.Method) && unauthorised != nil {
+			logger.Info("refusing - nobody is logged in")
+			unauthorised(req, resp)
+			return
+		}
+		req.Request = WithViewer(req.Request, viewer)
+		chain.ProcessFilter(req, resp)
+	}
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	userModel "github.com/goblimey/films/models/user"
+)
+
+const testKey = "a session key of at least 32 characters"
+
+// loginCookie returns the session cookie that logs in the given user.
+func loginCookie(t *testing.T, sessions Sessions, username string) *http.Cookie {
+	recorder := httptest.NewRecorder()
+	err := sessions.Login(recorder, username)
+	if err != nil {
+		t.Fatalf("login failed - %s", err.Error())
+	}
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == CookieName {
+			return cookie
+		}
+	}
+	t.Fatalf("expected a session cookie, got %v", recorder.Result().Cookies())
+	return nil
+}
+
+// TestUnitSessionCookie checks that the session cookie logs the user in, that
+// it can't be read or altered and that a server with another key rejects it.
+func TestUnitSessionCookie(t *testing.T) {
+	sessions := MakeSessions(testKey, time.Hour, true)
+	cookie := loginCookie(t, sessions, "orson")
+	if !cookie.HttpOnly || !cookie.Secure || cookie.MaxAge != 3600 {
+		t.Errorf("expected a secure, HTTP only cookie lasting an hour, got %v", cookie)
+	}
+
+	req := httptest.NewRequest("GET", "/people", nil)
+	req.AddCookie(cookie)
+	if sessions.Username(req) != "orson" {
+		t.Errorf("expected orson to be logged in, got %q", sessions.Username(req))
+	}
+
+	// Another server with the same key accepts the cookie.
+	if MakeSessions(testKey, time.Hour, true).Username(req) != "orson" {
+		t.Errorf("expected the cookie to work with the same key")
+	}
+	// A server with another key doesn't.
+	if MakeSessions("", time.Hour, true).Username(req) != "" {
+		t.Errorf("expected the cookie to be rejected with a different key")
+	}
+
+	forged := httptest.NewRequest("GET", "/people", nil)
+	forged.AddCookie(&http.Cookie{Name: CookieName, Value: cookie.Value + "x"})
+	if sessions.Username(forged) != "" {
+		t.Errorf("expected an altered cookie to be rejected")
+	}
+
+	if sessions.Username(httptest.NewRequest("GET", "/people", nil)) != "" {
+		t.Errorf("expected nobody to be logged in without a cookie")
+	}
+}
+
+// TestUnitLogout checks that Logout sends a session cookie that expires at once
+// and a new token cookie.
+func TestUnitLogout(t *testing.T) {
+	sessions := MakeSessions(testKey, time.Hour, false)
+	recorder := httptest.NewRecorder()
+	sessions.Logout(recorder)
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 2 || cookies[0].Name != CookieName || cookies[0].MaxAge >= 0 ||
+		cookies[1].Name != TokenCookieName || cookies[1].Value == "" {
+
+		t.Errorf("expected an expired session cookie and a token cookie, got %v", cookies)
+	}
+}
+
+// TestUnitFilter checks that the filter lets anybody read, attaches the viewer to
+// the request with their role and refuses changes from visitors who haven't
+// logged in, or whose user has been removed.
+func TestUnitFilter(t *testing.T) {
+	sessions := MakeSessions(testKey, time.Hour, false)
+	roles := func(req *restful.Request, username string) (string, error) {
+		if username == "rita" {
+			return userModel.RoleEditor, nil
+		}
+		return "", errors.New("no such user")
+	}
+	var seen *Viewer
+	ws := new(restful.WebService)
+	ws.Path("/things").Filter(Filter(sessions, roles, func(req *restful.Request, resp *restful.Response) {
+		resp.WriteHeader(http.StatusUnauthorized)
+	}))
+	handler := func(req *restful.Request, resp *restful.Response) {
+		viewer := ViewerFrom(req.Request)
+		seen = &viewer
+	}
+	ws.Route(ws.GET("").To(handler))
+	ws.Route(ws.DELETE("/{id}").To(handler))
+	container := restful.NewContainer()
+	container.Add(ws)
+
+	var tests = []struct {
+		method string
+		uri    string
+		user   string
+		status int
+		viewer string
+	}{
+		{"GET", "/things", "", http.StatusOK, ""},
+		{"GET", "/things", "rita", http.StatusOK, "rita"},
+		{"GET", "/things", "removed", http.StatusOK, ""},
+		{"DELETE", "/things/1", "", http.StatusUnauthorized, ""},
+		{"DELETE", "/things/1", "rita", http.StatusOK, "rita"},
+		{"DELETE", "/things/1", "removed", http.StatusUnauthorized, ""},
+	}
+	for _, test := range tests {
+		seen = nil
+		req := httptest.NewRequest(test.method, test.uri, nil)
+		if test.user != "" {
+			req.AddCookie(loginCookie(t, sessions, test.user))
+		}
+		recorder := httptest.NewRecorder()
+		container.ServeHTTP(recorder, req)
+		if recorder.Code != test.status {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.uri,
+				test.status, recorder.Code)
+		}
+		if test.status != http.StatusOK {
+			if seen != nil {
+				t.Errorf("%s %s: expected the handler not to run", test.method, test.uri)
+			}
+			continue
+		}
+		if seen == nil || seen.Username != test.viewer {
+			t.Errorf("%s %s: expected viewer %q, got %v", test.method, test.uri, test.viewer, seen)
+			continue
+		}
+		if seen.LoggedIn() && seen.Role != userModel.RoleEditor {
+			t.Errorf("%s %s: expected role %s, got %s", test.method, test.uri,
+				userModel.RoleEditor, seen.Role)
+		}
+	}
+}
+
+// TestUnitViewerPermissions checks what each role can do.
+func TestUnitViewerPermissions(t *testing.T) {
+	var tests = []struct {
+		viewer    Viewer
+		canEdit   bool
+		canDelete bool
+	}{
+		{Viewer{}, false, false},
+		{Viewer{Role: userModel.RoleAdmin}, false, false},
+		{Viewer{Username: "v", Role: userModel.RoleViewer}, false, false},
+		{Viewer{Username: "e", Role: userModel.RoleEditor}, true, false},
+		{Viewer{Username: "a", Role: userModel.RoleAdmin}, true, true},
+	}
+	for _, test := range tests {
+		if test.viewer.CanEdit() != test.canEdit {
+			t.Errorf("%+v: expected CanEdit %t", test.viewer, test.canEdit)
+		}
+		if test.viewer.CanDelete() != test.canDelete {
+			t.Errorf("%+v: expected CanDelete %t", test.viewer, test.canDelete)
+		}
+		if test.viewer.IsAdmin() != test.canDelete {
+			t.Errorf("%+v: expected IsAdmin %t", test.viewer, test.canDelete)
+		}
+	}
+}
+
+// TestUnitRefusal checks the message that explains why the viewer can't do
+// something.
+func TestUnitRefusal(t *testing.T) {
+	var tests = []struct {
+		viewer   Viewer
+		expected string
+	}{
+		{Viewer{}, "you must log in to delete people"},
+		{Viewer{Username: "rita", Role: userModel.RoleEditor}, "rita is an editor and can't delete people"},
+		{Viewer{Username: "orson", Role: userModel.RoleViewer}, "orson is a viewer and can't delete people"},
+	}
+	for _, test := range tests {
+		got := test.viewer.Refusal("delete people")
+		if got != test.expected {
+			t.Errorf("%+v: expected %q, got %q", test.viewer, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/goblimey/films/utilities/logging"
+	"github.com/gorilla/securecookie"
+)
+
+// CookieName is the name of the session cookie.
+const CookieName = "films_session"
+
+// TokenCookieName is the name of the cookie holding the CSRF token.
+const TokenCookieName = "films_csrf"
+
+// FlashCookieName is the name of the cookie holding the flash notice.
+const FlashCookieName = "films_flash"
+
+// The Sessions interface defines the operations on the session cookie.
+type Sessions interface {
+	// Login sends a session cookie that logs in the given user, and a new CSRF
+	// token, so that a token issued before the login can't be used after it.
+	Login(w http.ResponseWriter, username string) error
+	// Logout sends a cookie that replaces the session cookie and expires straight
+	// away, and a new CSRF token.
+	Logout(w http.ResponseWriter)
+	// Username returns the user logged in by the request's session cookie, or an
+	// empty string if there is no valid cookie.
+	Username(r *http.Request) string
+	// Token returns the CSRF token of the browser session, which every form
+	// must send back.  If the request doesn't have one, it makes a new token
+	// and sends a cookie holding it.
+	Token(w http.ResponseWriter, r *http.Request) (string, error)
+	// SetFlash sends a cookie holding a notice for the next page to display,
+	// for example after a redirect.
+	SetFlash(w http.ResponseWriter, notice string) error
+	// Flash returns the notice held in the request's flash cookie, or an empty
+	// string if there is none, and sends a cookie that removes it so that the
+	// notice is only displayed once.
+	Flash(w http.ResponseWriter, r *http.Request) string
+}
+
+// ConcreteSessions keeps the session in a cookie signed and encrypted with keys
+// derived from the session key.  It satisfies the Sessions interface.
+type ConcreteSessions struct {
+	codec    *securecookie.SecureCookie
+	lifetime time.Duration
+	secure   bool
+}
+
+// MakeSessions is a factory function that creates a Sessions using the given
+// session key.  A session lasts for the given lifetime.  If secure is true, the
+// browser only sends the cookie over HTTPS.  If the key is empty, a random one is
+// used, so the sessions don't survive a restart.
+func MakeSessions(key string, lifetime time.Duration, secure bool) Sessions {
+	var hashKey, blockKey []byte
+	if key == "" {
+		hashKey = securecookie.GenerateRandomKey(32)
+		blockKey = securecookie.GenerateRandomKey(32)
+	} else {
+		hashKey = deriveKey(key, "films session signing")
+		blockKey = deriveKey(key, "films session encryption")
+	}
+	codec := securecookie.New(hashKey, blockKey)
+	codec.MaxAge(int(lifetime / time.Second))
+	return &ConcreteSessions{codec: codec, lifetime: lifetime, secure: secure}
+}
+
+// deriveKey makes a 32 byte key for the given purpose from the session key, so
+// that the signing and encryption keys are different.
+func deriveKey(key string, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// Login sends a session cookie that logs in the given user.  It also sends a
+// cookie holding a new CSRF token, which replaces the one that the browser had
+// before logging in.
+func (s *ConcreteSessions) Login(w http.ResponseWriter, username string) error {
+	value, err := s.codec.Encode(CookieName, username)
+	if err != nil {
+		slog.Error("cannot encode the session cookie", "error", err)
+		return err
+	}
+	_, err = s.newToken(w, slog.Default())
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, s.cookie(CookieName, value, int(s.lifetime/time.Second)))
+	return nil
+}
+
+// Logout sends a cookie that replaces the session cookie and expires straight
+// away, and a cookie holding a new CSRF token.
+func (s *ConcreteSessions) Logout(w http.ResponseWriter) {
+	http.SetCookie(w, s.cookie(CookieName, "", -1))
+	_, err := s.newToken(w, slog.Default())
+	if err != nil {
+		// Remove the old token instead.  The next page makes a new one.
+		http.SetCookie(w, s.cookie(TokenCookieName, "", -1))
+	}
+}
+
+// Username returns the user logged in by the request's session cookie, or an
+// empty string if there is no cookie or it's been tampered with or has expired.
+func (s *ConcreteSessions) Username(r *http.Request) string {
+	logger := logging.FromRequest(r)
+	cookie, err := r.Cookie(CookieName)
+	if err != nil {
+		return ""
+	}
+	var username string
+	err = s.codec.Decode(CookieName, cookie.Value, &username)
+	if err != nil {
+		logger.Warn("ignoring the session cookie", "error", err)
+		return ""
+	}
+	return username
+}
+
+// Token returns the CSRF token held in the request's token cookie.  If there is
+// no cookie, or it's been tampered with or has expired, it makes a new random
+// token and sends a cookie holding it, which lasts until the browser closes.
+func (s *ConcreteSessions) Token(w http.ResponseWriter, r *http.Request) (string, error) {
+	logger := logging.FromRequest(r)
+	cookie, err := r.Cookie(TokenCookieName)
+	if err == nil {
+		var token string
+		err = s.codec.Decode(TokenCookieName, cookie.Value, &token)
+		if err == nil && token != "" {
+			return token, nil
+		}
+		logger.Warn("replacing the token cookie", "error", err)
+	}
+	return s.newToken(w, logger)
+}
+
+// newToken makes a new random token and sends a cookie holding it, which lasts
+// until the browser closes.
+func (s *ConcreteSessions) newToken(w http.ResponseWriter, logger *slog.Logger) (string, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		logger.Error("cannot make a token", "error", err)
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(key)
+	value, err := s.codec.Encode(TokenCookieName, token)
+	if err != nil {
+		logger.Error("cannot encode the token cookie", "error", err)
+		return "", err
+	}
+	http.SetCookie(w, s.cookie(TokenCookieName, value, 0))
+	return token, nil
+}
+
+// SetFlash sends a cookie holding the notice, signed and encrypted like the
+// session cookie.  It lasts until the browser closes or the notice is displayed.
+func (s *ConcreteSessions) SetFlash(w http.ResponseWriter, notice string) error {
+	value, err := s.codec.Encode(FlashCookieName, notice)
+	if err != nil {
+		slog.Error("cannot encode the flash cookie", "error", err)
+		return err
+	}
+	http.SetCookie(w, s.cookie(FlashCookieName, value, 0))
+	return nil
+}
+
+// Flash returns the notice held in the request's flash cookie and sends a
+// cookie that removes it.  If there is no cookie, or it's been tampered with or
+// has expired, it returns an empty string.
+func (s *ConcreteSessions) Flash(w http.ResponseWriter, r *http.Request) string {
+	logger := logging.FromRequest(r)
+	cookie, err := r.Cookie(FlashCookieName)
+	if err != nil {
+		return ""
+	}
+	http.SetCookie(w, s.cookie(FlashCookieName, "", -1))
+	var notice string
+	err = s.codec.Decode(FlashCookieName, cookie.Value, &notice)
+	if err != nil {
+		logger.Warn("ignoring the flash cookie", "error", err)
+		return ""
+	}
+	return notice
+}
+
+// cookie returns a cookie with the given name, value and maximum age in seconds
+// - zero means that it lasts until the browser closes.  The cookie is not
+// available to JavaScript and is not sent with requests from other sites, apart
+// from following a link.
+func (s *ConcreteSessions) cookie(name string, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
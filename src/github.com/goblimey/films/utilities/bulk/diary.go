@@ -0,0 +1,30 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	diaryModel "github.com/goblimey/films/models/diary"
+)
+
+// ExportDiary writes the given diary entries as CSV, with a header line:
+//
+//    date,title,year,rating,rewatch
+//    2024-05-17,Brief Encounter,1945,8,true
+//
+// The rating is empty if the user didn't rate the viewing.
+func ExportDiary(writer io.Writer, entries []diaryModel.Entry) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Write([]string{"date", "title", "year", "rating", "rewatch"})
+	for _, entry := range entries {
+		rating := ""
+		if entry.Rating() != 0 {
+			rating = strconv.Itoa(entry.Rating())
+		}
+		csvWriter.Write([]string{entry.WatchedOn().Format("2006-01-02"), entry.FilmTitle(),
+			strconv.Itoa(entry.FilmReleaseYear()), rating, strconv.FormatBool(entry.Rewatch())})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,35 @@
+package bulk
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	diaryModel "github.com/goblimey/films/models/diary"
+	gorpDiaryModel "github.com/goblimey/films/models/diary/gorpmysql"
+)
+
+// TestUnitExportDiary checks that a diary is written as CSV, with an empty
+// rating for a viewing that wasn't rated and the title quoted where necessary.
+func TestUnitExportDiary(t *testing.T) {
+	rated := gorpDiaryModel.MakeInitialisedEntry(1, 2, 3,
+		time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC), 8, true)
+	rated.SetFilmTitle("Brief Encounter")
+	rated.SetFilmReleaseYear(1945)
+	unrated := gorpDiaryModel.MakeInitialisedEntry(4, 2, 5,
+		time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), 0, false)
+	unrated.SetFilmTitle("Lock, Stock and Two Smoking Barrels")
+	unrated.SetFilmReleaseYear(1998)
+
+	var buffer bytes.Buffer
+	err := ExportDiary(&buffer, []diaryModel.Entry{rated, unrated})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := "date,title,year,rating,rewatch\n" +
+		"2024-05-17,Brief Encounter,1945,8,true\n" +
+		"2024-05-01,\"Lock, Stock and Two Smoking Barrels\",1998,,false\n"
+	if buffer.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buffer.String())
+	}
+}
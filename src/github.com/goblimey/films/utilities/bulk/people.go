@@ -0,0 +1,314 @@
+// Package bulk imports people from a file, and exports them to one, in one of
+// two formats.  CSV has a header line naming the columns, which must include
+// forename and surname:
+//
+//    id,forename,surname
+//    1,Orson,Welles
+//
+// JSON Lines has one JSON object per line:
+//
+//    {"id": 1, "forename": "Orson", "surname": "Welles"}
+//
+// The export writes the id, but the import ignores it and gives each person a new
+// ID.  Each row is validated in the same way as the create form.
+//
+// A user's diary can be exported too, as CSV - see ExportDiary.
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	forms "github.com/goblimey/films/forms/people"
+	personModel "github.com/goblimey/films/models/person"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+	peopleRepo "github.com/goblimey/films/repositories/people"
+)
+
+// The formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Options control an import.
+type Options struct {
+	// DryRun says to validate the rows and report on them, but not to create
+	// anyone.
+	DryRun bool
+
+	// AllOrNothing says to create all of the people in one transaction, and
+	// only if every row is valid.  Otherwise each valid row is created on its
+	// own and the invalid rows are skipped.
+	AllOrNothing bool
+}
+
+// RowError describes a row that could not be imported.  Errors maps each bad
+// field to a message.  A row that can't be read at all has an error for the field
+// "row".
+type RowError struct {
+	Line     int               `json:"line"`
+	Forename string            `json:"forename"`
+	Surname  string            `json:"surname"`
+	Errors   map[string]string `json:"errors"`
+}
+
+// Report describes the result of an import.
+type Report struct {
+	// Rows is the number of rows read, not counting the CSV header.
+	Rows int `json:"rows"`
+	// Valid is the number of rows that passed validation.
+	Valid int `json:"valid"`
+	// Created is the number of people created.
+	Created int `json:"created"`
+	// DryRun is true if the import was only a dry run.
+	DryRun bool `json:"dryRun"`
+	// RowErrors describes the rows that were not imported.
+	RowErrors []RowError `json:"rowErrors"`
+}
+
+// OK returns true if every row was valid and, unless this was a dry run, every
+// valid row was created.
+func (r Report) OK() bool {
+	return len(r.RowErrors) == 0 && (r.DryRun || r.Created == r.Valid)
+}
+
+// String returns a summary of the report followed by a line for each bad row.
+func (r Report) String() string {
+	var b strings.Builder
+	if r.DryRun {
+		b.WriteString("dry run - ")
+	}
+	fmt.Fprintf(&b, "%d rows, %d valid, %d created\n", r.Rows, r.Valid, r.Created)
+	for _, rowError := range r.RowErrors {
+		fields := make([]string, 0, len(rowError.Errors))
+		for _, field := range []string{"row", "forename", "surname", "database"} {
+			if message, ok := rowError.Errors[field]; ok {
+				fields = append(fields, message)
+			}
+		}
+		fmt.Fprintf(&b, "line %d: %s\n", rowError.Line, strings.Join(fields, ", "))
+	}
+	return b.String()
+}
+
+// row is a person read from the input, with its line number.
+type row struct {
+	line     int
+	forename string
+	surname  string
+	err      string
+}
+
+// personJSON is the representation of a person in the JSON Lines format.
+type personJSON struct {
+	ID       uint64 `json:"id,omitempty"`
+	Forename string `json:"forename"`
+	Surname  string `json:"surname"`
+}
+
+// FormatFromName gets the format from the extension of a file name - ".csv" for
+// CSV and ".jsonl", ".ndjson" or ".json" for JSON Lines.
+func FormatFromName(name string) (string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson", ".json":
+		return FormatJSONL, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %s from its name - use %s or %s",
+		name, FormatCSV, FormatJSONL)
+}
+
+// ImportPeople reads people in the given format and creates them using the
+// repository, as the options say.  It returns a report on the rows.  If the input
+// can't be read at all, it returns an error and no report.  If an all-or-nothing
+// import fails in the database, nobody is created and it returns the report and
+// the error.
+func ImportPeople(reader io.Reader, format string, repo peopleRepo.Repository,
+	options Options) (*Report, error) {
+
+	var rows []row
+	var err error
+	switch format {
+	case FormatCSV:
+		rows, err = readCSV(reader)
+	case FormatJSONL:
+		rows, err = readJSONL(reader)
+	default:
+		err = fmt.Errorf("unknown format %q - use %s or %s", format, FormatCSV, FormatJSONL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := Report{Rows: len(rows), DryRun: options.DryRun, RowErrors: []RowError{}}
+	valid := make([]personModel.Person, 0, len(rows))
+	validLines := make([]int, 0, len(rows))
+	for _, r := range rows {
+		if r.err != "" {
+			report.RowErrors = append(report.RowErrors,
+				RowError{r.line, r.forename, r.surname, map[string]string{"row": r.err}})
+			continue
+		}
+		var form forms.ConcretePersonForm
+		form.SetPerson(gorpPersonModel.MakeInitialisedPerson(0, r.forename, r.surname))
+		if !form.Validate() {
+			fieldErrors := make(map[string]string)
+			for field, message := range form.FieldErrors() {
+				fieldErrors[strings.ToLower(field)] = message
+			}
+			report.RowErrors = append(report.RowErrors,
+				RowError{r.line, r.forename, r.surname, fieldErrors})
+			continue
+		}
+		valid = append(valid, form.Person())
+		validLines = append(validLines, r.line)
+	}
+	report.Valid = len(valid)
+	slog.Info("checked the people", "rows", report.Rows, "valid", report.Valid)
+
+	if options.DryRun {
+		return &report, nil
+	}
+
+	if options.AllOrNothing {
+		if len(report.RowErrors) > 0 || len(valid) == 0 {
+			return &report, nil
+		}
+		_, err = repo.CreateAll(valid)
+		if err != nil {
+			return &report, fmt.Errorf("nobody was imported - %s", err.Error())
+		}
+		report.Created = len(valid)
+		return &report, nil
+	}
+
+	for i, person := range valid {
+		_, err = repo.Create(person)
+		if err != nil {
+			slog.Error("cannot import the person", "line", validLines[i], "error", err)
+			report.RowErrors = append(report.RowErrors, RowError{validLines[i],
+				person.Forename(), person.Surname(), map[string]string{"database": err.Error()}})
+			continue
+		}
+		report.Created++
+	}
+	return &report, nil
+}
+
+// readCSV reads the rows of a CSV file.  The header line must name the forename
+// and surname columns.  Other columns are ignored.  A line that can't be parsed
+// gives a row with an error.
+func readCSV(reader io.Reader) ([]row, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, errors.New("the CSV is empty - it needs a header line naming the columns")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the CSV header - %s", err.Error())
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		// A file saved by a spreadsheet may start with a byte order mark.
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"forename", "surname"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the CSV header has no %s column", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		i := columns[name]
+		if i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	rows := make([]row, 0)
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if parseError, ok := err.(*csv.ParseError); ok {
+			rows = append(rows, row{line: parseError.StartLine, err: parseError.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the CSV - %s", err.Error())
+		}
+		line, _ := csvReader.FieldPos(0)
+		rows = append(rows, row{line: line, forename: field(record, "forename"),
+			surname: field(record, "surname")})
+	}
+	return rows, nil
+}
+
+// readJSONL reads the rows of a JSON Lines file.  Blank lines are skipped.  A
+// line that isn't a JSON object gives a row with an error.
+func readJSONL(reader io.Reader) ([]row, error) {
+	scanner := bufio.NewScanner(reader)
+	rows := make([]row, 0)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var person personJSON
+		err := json.Unmarshal([]byte(text), &person)
+		if err != nil {
+			rows = append(rows, row{line: line, err: "not a JSON object - " + err.Error()})
+			continue
+		}
+		rows = append(rows, row{line: line, forename: person.Forename, surname: person.Surname})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read the JSON Lines - %s", err.Error())
+	}
+	return rows, nil
+}
+
+// ExportPeople writes all valid people from the repository in the given format.
+func ExportPeople(writer io.Writer, format string, repo peopleRepo.Repository) error {
+	people, err := repo.FindAll()
+	if err != nil {
+		return fmt.Errorf("error getting the list of people - %s", err.Error())
+	}
+
+	switch format {
+	case FormatCSV:
+		csvWriter := csv.NewWriter(writer)
+		csvWriter.Write([]string{"id", "forename", "surname"})
+		for _, person := range people {
+			csvWriter.Write([]string{strconv.FormatUint(person.ID(), 10),
+				person.Forename(), person.Surname()})
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	case FormatJSONL:
+		encoder := json.NewEncoder(writer)
+		for _, person := range people {
+			err = encoder.Encode(personJSON{person.ID(), person.Forename(), person.Surname()})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q - use %s or %s", format, FormatCSV, FormatJSONL)
+}
@@ -0,0 +1,154 @@
+package bulk
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	peopleRepo "github.com/goblimey/films/repositories/people"
+)
+
+const testCSV = "Surname,Forename,Notes\n" +
+	"Welles,Orson,director\n" +
+	", Joseph,\n" +
+	"\"Cotten\",\"Joseph\"\n" +
+	"Moore\"head,Agnes\n"
+
+// TestUnitImportCSV checks that the valid rows of a CSV file are created and the
+// invalid ones are reported.
+func TestUnitImportCSV(t *testing.T) {
+	repo := peopleRepo.MakeMemoryRepo()
+
+	report, err := ImportPeople(strings.NewReader(testCSV), FormatCSV, repo, Options{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if report.Rows != 4 || report.Valid != 2 || report.Created != 2 {
+		t.Errorf("Expected 4 rows, 2 valid and 2 created, got %+v", report)
+	}
+	if len(report.RowErrors) != 2 {
+		t.Fatalf("Expected 2 row errors, got %v", report.RowErrors)
+	}
+	if report.RowErrors[0].Line != 3 || report.RowErrors[0].Errors["surname"] == "" {
+		t.Errorf("Expected an error for the surname on line 3, got %+v", report.RowErrors[0])
+	}
+	if report.RowErrors[1].Line != 5 || report.RowErrors[1].Errors["row"] == "" {
+		t.Errorf("Expected a row error on line 5, got %+v", report.RowErrors[1])
+	}
+	if report.OK() {
+		t.Errorf("Expected the report not to be OK")
+	}
+
+	people, _ := repo.FindAll()
+	if len(people) != 2 {
+		t.Errorf("Expected 2 people, got %d", len(people))
+	}
+}
+
+// TestUnitImportAllOrNothingAndDryRun checks that an all-or-nothing import with
+// an invalid row creates nobody, that a dry run creates nobody, and that an
+// all-or-nothing import of valid rows creates everyone.
+func TestUnitImportAllOrNothingAndDryRun(t *testing.T) {
+	repo := peopleRepo.MakeMemoryRepo()
+
+	report, err := ImportPeople(strings.NewReader(testCSV), FormatCSV, repo,
+		Options{AllOrNothing: true})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if report.Created != 0 || report.OK() {
+		t.Errorf("Expected nobody to be created, got %+v", report)
+	}
+
+	jsonl := `{"forename": "Ray", "surname": "Collins"}` + "\n\n" +
+		`{"forename": "Ruth", "surname": "Warrick", "id": 7}` + "\n"
+	report, _ = ImportPeople(strings.NewReader(jsonl), FormatJSONL, repo,
+		Options{DryRun: true, AllOrNothing: true})
+	if report.Valid != 2 || report.Created != 0 || !report.OK() {
+		t.Errorf("Expected 2 valid rows and nobody created, got %+v", report)
+	}
+
+	report, _ = ImportPeople(strings.NewReader(jsonl), FormatJSONL, repo,
+		Options{AllOrNothing: true})
+	if report.Created != 2 || !report.OK() {
+		t.Errorf("Expected 2 people created, got %+v", report)
+	}
+
+	people, _ := repo.FindAll()
+	if len(people) != 2 {
+		t.Fatalf("Expected 2 people, got %d", len(people))
+	}
+	if people[1].ID() == 7 {
+		t.Errorf("Expected the ID in the file to be ignored")
+	}
+}
+
+// TestUnitImportBadInput checks the errors for input that can't be imported at
+// all, and the report on a line of JSON Lines that isn't an object.
+func TestUnitImportBadInput(t *testing.T) {
+	repo := peopleRepo.MakeMemoryRepo()
+
+	for _, csv := range []string{"", "forename,name\nOrson,Welles\n"} {
+		_, err := ImportPeople(strings.NewReader(csv), FormatCSV, repo, Options{})
+		if err == nil {
+			t.Errorf("%q: expected an error", csv)
+		}
+	}
+
+	_, err := ImportPeople(strings.NewReader(""), "xml", repo, Options{})
+	if err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+
+	report, err := ImportPeople(strings.NewReader("[1, 2]\n"), FormatJSONL, repo, Options{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(report.RowErrors) != 1 || report.RowErrors[0].Errors["row"] == "" {
+		t.Errorf("Expected a row error, got %+v", report.RowErrors)
+	}
+}
+
+// TestUnitExportRoundTrip checks that exported people can be imported again in
+// both formats.
+func TestUnitExportRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatJSONL} {
+		repo := peopleRepo.MakeMemoryRepo()
+		ImportPeople(strings.NewReader(testCSV), FormatCSV, repo, Options{})
+
+		var buffer bytes.Buffer
+		err := ExportPeople(&buffer, format, repo)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		copy := peopleRepo.MakeMemoryRepo()
+		report, err := ImportPeople(&buffer, format, copy, Options{AllOrNothing: true})
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if report.Created != 2 {
+			t.Errorf("%s: expected 2 people, got %+v", format, report)
+		}
+	}
+}
+
+// TestUnitFormatFromName checks that the format is taken from the extension.
+func TestUnitFormatFromName(t *testing.T) {
+	var tests = []struct {
+		name   string
+		format string
+	}{
+		{"people.csv", FormatCSV},
+		{"people.CSV", FormatCSV},
+		{"people.jsonl", FormatJSONL},
+		{"people.ndjson", FormatJSONL},
+		{"people.txt", ""},
+	}
+	for _, test := range tests {
+		format, _ := FormatFromName(test.name)
+		if format != test.format {
+			t.Errorf("%s: expected %q, got %q", test.name, test.format, format)
+		}
+	}
+}
@@ -0,0 +1,492 @@
+// Package config gathers the settings for the films server.  Each setting has a
+// default which can be overridden by a YAML config file, which can be overridden
+// by an environment variable, which can be overridden by a command line flag.
+// The settings are checked by Validate before the server uses them.
+//
+// The database credentials are part of the DSN.  They have no default, so they
+// never need to be in the source.  Supply them in a config file kept out of
+// version control, in the FILMS_DSN environment variable or with the -dsn flag.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// The dialects - the kinds of database that the server can use.
+const (
+	DialectMySQL  = "mysql"
+	DialectSqlite = "sqlite"
+	DialectMemory = "memory"
+)
+
+// The log levels.
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// The log formats.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Config holds the settings.  The field tags give the names of the settings in
+// the config file.
+type Config struct {
+	// Dialect is the kind of database - mysql, sqlite or memory.
+	Dialect string `yaml:"dialect"`
+
+	// DSN is the data source name that says how to connect to the database.
+	// For MySQL it's something like "user:password@tcp(localhost:3306)/films",
+	// for SQLite it's the name of the database file.  The memory dialect
+	// doesn't need one.
+	DSN string `yaml:"dsn"`
+
+	// ListenAddress is the address that the server listens on, eg ":4000".
+	ListenAddress string `yaml:"listen_address"`
+
+	// ViewsDir is the directory containing the templates and the error page.
+	ViewsDir string `yaml:"views_dir"`
+
+	// StaticDir is the directory containing the stylesheets and html
+	// directories, which are served as they are.
+	StaticDir string `yaml:"static_dir"`
+
+	// LogLevel is debug, info, warn or error.
+	LogLevel string `yaml:"log_level"`
+
+	// LogFormat is text, for people to read, or json, for log collectors.
+	LogFormat string `yaml:"log_format"`
+
+	// MaxOpenConns is the most connections that the pool will open to the
+	// database.  0 means no limit.
+	MaxOpenConns int `yaml:"max_open_conns"`
+
+	// MaxIdleConns is the most idle connections that the pool will keep.  0
+	// means the database/sql default.
+	MaxIdleConns int `yaml:"max_idle_conns"`
+
+	// ConnMaxLifetime is how long a connection can be reused, for example
+	// "5m".  An empty string or 0 means forever.
+	ConnMaxLifetime string `yaml:"conn_max_lifetime"`
+
+	// TrashRetention is how long deleted people are kept in the trash before
+	// the server purges them for good, for example "720h".  An empty string or
+	// 0 means they stay until they are purged by hand.
+	TrashRetention string `yaml:"trash_retention"`
+
+	// SessionKey is the secret used to sign and encrypt the session cookies.
+	// It must be at least MinSessionKeyLength characters.  If it's empty, the
+	// server makes up a random key each time it starts, so everybody is logged
+	// out when it restarts.
+	SessionKey string `yaml:"session_key"`
+
+	// SessionLifetime is how long a user stays logged in, for example "12h".
+	SessionLifetime string `yaml:"session_lifetime"`
+
+	// SecureCookies makes the browser send the session cookie only over HTTPS.
+	// Set it when the server is behind an HTTPS proxy.
+	SecureCookies bool `yaml:"secure_cookies"`
+}
+
+// MinSessionKeyLength is the length of the shortest session key allowed.
+const MinSessionKeyLength = 32
+
+// setting describes one setting - its environment variable, its command line
+// flag and how to store it in the Config.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+// setString returns a function that stores a string setting.
+func setString(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+// setBool returns a function that stores a true or false setting.
+func setBool(field func(c *Config) *bool) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("\"%s\" is not true or false", value)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+// setInt returns a function that stores a whole number setting.
+func setInt(field func(c *Config) *int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("\"%s\" is not a whole number", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+var settings = []setting{
+	{"FILMS_DIALECT", "dialect", "the kind of database - mysql, sqlite or memory",
+		setString(func(c *Config) *string { return &c.Dialect })},
+	{"FILMS_DSN", "dsn", "how to connect to the database - for sqlite, the database file",
+		setString(func(c *Config) *string { return &c.DSN })},
+	{"FILMS_LISTEN_ADDRESS", "listen", "the address to listen on, eg :4000",
+		setString(func(c *Config) *string { return &c.ListenAddress })},
+	{"FILMS_VIEWS_DIR", "views", "the directory containing the templates",
+		setString(func(c *Config) *string { return &c.ViewsDir })},
+	{"FILMS_STATIC_DIR", "static", "the directory containing the stylesheets and html directories",
+		setString(func(c *Config) *string { return &c.StaticDir })},
+	{"FILMS_LOG_LEVEL", "loglevel", "debug, info, warn or error",
+		setString(func(c *Config) *string { return &c.LogLevel })},
+	{"FILMS_LOG_FORMAT", "logformat", "text or json",
+		setString(func(c *Config) *string { return &c.LogFormat })},
+	{"FILMS_MAX_OPEN_CONNS", "maxopenconns", "the most database connections to open - 0 for no limit",
+		setInt(func(c *Config) *int { return &c.MaxOpenConns })},
+	{"FILMS_MAX_IDLE_CONNS", "maxidleconns", "the most idle database connections to keep",
+		setInt(func(c *Config) *int { return &c.MaxIdleConns })},
+	{"FILMS_CONN_MAX_LIFETIME", "connmaxlifetime", "how long to reuse a database connection, eg 5m",
+		setString(func(c *Config) *string { return &c.ConnMaxLifetime })},
+	{"FILMS_TRASH_RETENTION", "trashretention", "how long to keep deleted people in the trash, eg 720h - 0 for ever",
+		setString(func(c *Config) *string { return &c.TrashRetention })},
+	{"FILMS_SESSION_KEY", "sessionkey", "the secret that protects the session cookies - at least 32 characters",
+		setString(func(c *Config) *string { return &c.SessionKey })},
+	{"FILMS_SESSION_LIFETIME", "sessionlifetime", "how long a user stays logged in, eg 12h",
+		setString(func(c *Config) *string { return &c.SessionLifetime })},
+	{"FILMS_SECURE_COOKIES", "securecookies", "true to send the session cookie only over HTTPS",
+		setBool(func(c *Config) *bool { return &c.SecureCookies })},
+}
+
+// ConfigFileEnv is the environment variable that can give the name of the config
+// file.  The -config flag overrides it.
+const ConfigFileEnv = "FILMS_CONFIG"
+
+// Defaults returns a Config containing the default settings.
+func Defaults() *Config {
+	return &Config{
+		Dialect:         DialectMySQL,
+		ListenAddress:   ":4000",
+		ViewsDir:        "views",
+		StaticDir:       "views",
+		LogLevel:        LogLevelInfo,
+		LogFormat:       LogFormatText,
+		MaxOpenConns:    10,
+		MaxIdleConns:    5,
+		TrashRetention:  "720h", // 30 days
+		SessionLifetime: "12h",
+	}
+}
+
+// Load gathers the settings from the defaults, the config file, the environment
+// and the given command line arguments, in increasing order of precedence.  It
+// uses getenv to read the environment, so the caller would normally supply
+// os.Getenv.  Load does not validate the result - call Validate for that.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	cfg, rest, err := LoadCommand(args, getenv)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected argument %s", rest[0])
+	}
+	return cfg, nil
+}
+
+// LoadCommand is like Load, but the flags may be followed by a command and its
+// arguments, for example "-dialect sqlite import people.csv".  It returns the
+// command and its arguments, which are empty if there is no command.
+func LoadCommand(args []string, getenv func(string) string) (*Config, []string, error) {
+
+	cfg := Defaults()
+
+	// Define the flags.  They are all strings, converted by the setting, and
+	// only the ones that were given are used.
+	flags := flag.NewFlagSet("films", flag.ContinueOnError)
+	configFile := flags.String("config", "",
+		"the YAML config file (also "+ConfigFileEnv+")")
+	flagValues := make(map[string]*string)
+	for _, s := range settings {
+		flagValues[s.flag] = flags.String(s.flag, "", s.usage+" (also "+s.env+")")
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	given := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	// The config file.
+	fileName := *configFile
+	if fileName == "" {
+		fileName = getenv(ConfigFileEnv)
+	}
+	if fileName != "" {
+		err = cfg.readFile(fileName)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The environment.
+	for _, s := range settings {
+		value := getenv(s.env)
+		if value != "" {
+			err = s.set(cfg, value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s - %s", s.env, err.Error())
+			}
+		}
+	}
+
+	// The flags that were given.
+	for _, s := range settings {
+		if given[s.flag] {
+			err = s.set(cfg, *flagValues[s.flag])
+			if err != nil {
+				return nil, nil, fmt.Errorf("-%s - %s", s.flag, err.Error())
+			}
+		}
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// readFile reads the YAML config file with the given name into the Config.  Only
+// the settings in the file are changed.  Unknown settings are rejected, so that a
+// misspelt name is not silently ignored.
+func (c *Config) readFile(fileName string) error {
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("cannot read config file - %s", err.Error())
+	}
+	err = yaml.UnmarshalStrict(contents, c)
+	if err != nil {
+		return fmt.Errorf("cannot read config file %s - %s", fileName, err.Error())
+	}
+	return nil
+}
+
+// Validate checks the settings and returns an error describing all of the
+// problems that it finds, or nil if there are none.  If the dialect is sqlite
+// and there is no DSN, it uses the file films.db.
+func (c *Config) Validate() error {
+	problems := c.databaseProblems()
+
+	_, port, err := net.SplitHostPort(c.ListenAddress)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf(
+			"the listen address \"%s\" should look like host:port or :port - %s",
+			c.ListenAddress, err.Error()))
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		problems = append(problems, fmt.Sprintf(
+			"the port in the listen address \"%s\" must be a number from 0 to 65535",
+			c.ListenAddress))
+	}
+
+	// The views directory must contain the files that the server parses at
+	// startup.  Check for a couple of them to catch a wrong directory early.
+	if problem := checkDir("views", c.ViewsDir); problem != "" {
+		problems = append(problems, problem)
+	} else {
+		for _, f := range []string{"templates/_base.ghtml", "html/error.html"} {
+			path := filepath.Join(c.ViewsDir, filepath.FromSlash(f))
+			if _, err := os.Stat(path); err != nil {
+				problems = append(problems, fmt.Sprintf(
+					"the views directory %s does not contain %s", c.ViewsDir, f))
+			}
+		}
+	}
+
+	if problem := checkDir("static", c.StaticDir); problem != "" {
+		problems = append(problems, problem)
+	}
+
+	if _, err := c.TrashRetentionDuration(); err != nil {
+		problems = append(problems, fmt.Sprintf(
+			"the trash retention \"%s\" should be a duration such as 720h",
+			c.TrashRetention))
+	}
+
+	if c.SessionKey != "" && len(c.SessionKey) < MinSessionKeyLength {
+		problems = append(problems, fmt.Sprintf(
+			"the session key must be at least %d characters", MinSessionKeyLength))
+	}
+	if d, err := c.SessionLifetimeDuration(); err != nil || d == 0 {
+		problems = append(problems, fmt.Sprintf(
+			"the session lifetime \"%s\" should be a duration such as 12h",
+			c.SessionLifetime))
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// ValidateDatabase checks just the settings that a command such as import needs
+// - the database, the connection pool and the log level.  It returns an error
+// describing all of the problems that it finds, or nil if there are none.
+func (c *Config) ValidateDatabase() error {
+	problems := c.databaseProblems()
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// databaseProblems checks the database, connection pool and log level settings
+// and returns a description of each problem that it finds.  If the dialect is
+// sqlite and there is no DSN, it uses the file films.db.
+func (c *Config) databaseProblems() []string {
+
+	var problems []string
+
+	switch c.Dialect {
+	case DialectMySQL:
+		if c.DSN == "" {
+			problems = append(problems, "the mysql dialect needs a DSN such as "+
+				"user:password@tcp(localhost:3306)/films - set dsn in the config file, "+
+				"FILMS_DSN or -dsn")
+		} else if _, err := mysql.ParseDSN(c.DSN); err != nil {
+			problems = append(problems, fmt.Sprintf("the DSN is not valid for mysql - %s",
+				err.Error()))
+		}
+	case DialectSqlite:
+		if c.DSN == "" {
+			c.DSN = "films.db"
+		}
+	case DialectMemory:
+		if c.DSN != "" {
+			problems = append(problems, "the memory dialect does not use a DSN")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf(
+			"the dialect must be one of %s, %s or %s, not \"%s\"",
+			DialectMySQL, DialectSqlite, DialectMemory, c.Dialect))
+	}
+
+	switch c.LogLevel {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		problems = append(problems, fmt.Sprintf(
+			"the log level must be one of %s, %s, %s or %s, not \"%s\"",
+			LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError, c.LogLevel))
+	}
+
+	switch c.LogFormat {
+	case LogFormatText, LogFormatJSON:
+	default:
+		problems = append(problems, fmt.Sprintf(
+			"the log format must be %s or %s, not \"%s\"",
+			LogFormatText, LogFormatJSON, c.LogFormat))
+	}
+
+	if c.MaxOpenConns < 0 {
+		problems = append(problems, "the maximum number of open connections can't be negative")
+	}
+	if c.MaxIdleConns < 0 {
+		problems = append(problems, "the maximum number of idle connections can't be negative")
+	}
+	if _, err := c.ConnMaxLifetimeDuration(); err != nil {
+		problems = append(problems, fmt.Sprintf(
+			"the connection lifetime \"%s\" should be a duration such as 30s or 5m",
+			c.ConnMaxLifetime))
+	}
+
+	return problems
+}
+
+// checkDir checks that the given directory exists.  It returns a description of
+// the problem, or an empty string if there is none.
+func checkDir(name string, dir string) string {
+	if dir == "" {
+		return fmt.Sprintf("the %s directory is not set", name)
+	}
+	fileInfo, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("cannot find the %s directory %s", name, dir)
+		}
+		return fmt.Sprintf("cannot use the %s directory %s - %s", name, dir, err.Error())
+	}
+	if !fileInfo.IsDir() {
+		return fmt.Sprintf("the %s directory %s is not a directory", name, dir)
+	}
+	return ""
+}
+
+// ConnMaxLifetimeDuration returns the connection lifetime as a time.Duration.  An
+// empty setting gives 0, meaning forever.
+func (c Config) ConnMaxLifetimeDuration() (time.Duration, error) {
+	return parseDuration(c.ConnMaxLifetime)
+}
+
+// TrashRetentionDuration returns the trash retention period as a time.Duration.
+// An empty setting gives 0, meaning forever.
+func (c Config) TrashRetentionDuration() (time.Duration, error) {
+	return parseDuration(c.TrashRetention)
+}
+
+// SessionLifetimeDuration returns the session lifetime as a time.Duration.
+func (c Config) SessionLifetimeDuration() (time.Duration, error) {
+	return parseDuration(c.SessionLifetime)
+}
+
+// parseDuration parses a duration setting, which must not be negative.  An empty
+// setting gives 0.
+func parseDuration(setting string) (time.Duration, error) {
+	if setting == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(setting)
+	if err == nil && d < 0 {
+		err = errors.New("negative duration")
+	}
+	return d, err
+}
+
+// String returns the settings in a form suitable for logging.  The password in a
+// MySQL DSN and the session key are hidden.
+func (c Config) String() string {
+	dsn := c.DSN
+	if c.Dialect == DialectMySQL {
+		if dsnConfig, err := mysql.ParseDSN(dsn); err == nil && dsnConfig.Passwd != "" {
+			dsnConfig.Passwd = "****"
+			dsn = dsnConfig.FormatDSN()
+		}
+	}
+	sessionKey := ""
+	if c.SessionKey != "" {
+		sessionKey = "****"
+	}
+	return fmt.Sprintf("dialect=%s dsn=%s listen=%s views=%s static=%s loglevel=%s logformat=%s "+
+		"maxopenconns=%d maxidleconns=%d connmaxlifetime=%s trashretention=%s "+
+		"sessionkey=%s sessionlifetime=%s securecookies=%t",
+		c.Dialect, dsn, c.ListenAddress, c.ViewsDir, c.StaticDir, c.LogLevel, c.LogFormat,
+		c.MaxOpenConns, c.MaxIdleConns, c.ConnMaxLifetime, c.TrashRetention,
+		sessionKey, c.SessionLifetime, c.SecureCookies)
+}
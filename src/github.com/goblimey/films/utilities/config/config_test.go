@@ -0,0 +1,289 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// makeEnv returns a getenv function that reads the given map.
+func makeEnv(env map[string]string) func(string) string {
+	return func(name string) string {
+		return env[name]
+	}
+}
+
+// makeViews creates a views directory containing the files that Validate looks
+// for and returns its name.  The caller should remove it.
+func makeViews(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "films")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for _, f := range []string{"templates/_base.ghtml", "html/error.html"} {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		err = ioutil.WriteFile(path, []byte(""), 0644)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	return dir
+}
+
+// TestUnitLoadPrecedence checks that the environment overrides the config file and
+// the command line overrides the environment.
+func TestUnitLoadPrecedence(t *testing.T) {
+	dir := makeViews(t)
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "films.yaml")
+	contents := "dialect: sqlite\n" +
+		"dsn: file.db\n" +
+		"listen_address: \":5000\"\n" +
+		"log_level: debug\n" +
+		"log_format: json\n"
+	err := ioutil.WriteFile(configFile, []byte(contents), 0600)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	env := map[string]string{
+		ConfigFileEnv:          configFile,
+		"FILMS_DSN":            "env.db",
+		"FILMS_LISTEN_ADDRESS": ":6000",
+	}
+	args := []string{"-listen", ":7000"}
+
+	cfg, err := Load(args, makeEnv(env))
+	if err != nil {
+		t.Fatalf("Load failed - %s", err.Error())
+	}
+
+	if cfg.Dialect != "sqlite" {
+		t.Errorf("Expected dialect sqlite from the file, got %s", cfg.Dialect)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("Expected log level debug from the file, got %s", cfg.LogLevel)
+	}
+	if cfg.LogFormat != "json" {
+		t.Errorf("Expected log format json from the file, got %s", cfg.LogFormat)
+	}
+	if cfg.DSN != "env.db" {
+		t.Errorf("Expected DSN env.db from the environment, got %s", cfg.DSN)
+	}
+	if cfg.ListenAddress != ":7000" {
+		t.Errorf("Expected listen address :7000 from the command line, got %s",
+			cfg.ListenAddress)
+	}
+	if cfg.ViewsDir != "views" {
+		t.Errorf("Expected the default views directory, got %s", cfg.ViewsDir)
+	}
+}
+
+// TestUnitLoadRejectsUnknownSetting checks that a misspelt setting in the config
+// file is reported.
+func TestUnitLoadRejectsUnknownSetting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "films")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "films.yaml")
+	ioutil.WriteFile(configFile, []byte("dialekt: sqlite\n"), 0600)
+
+	_, err = Load([]string{"-config", configFile}, makeEnv(nil))
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if !strings.Contains(err.Error(), "dialekt") {
+		t.Errorf("Expected the error to mention dialekt, got %s", err.Error())
+	}
+}
+
+// TestUnitValidateGood checks that Validate accepts a good configuration and
+// supplies the default SQLite file.
+func TestUnitValidateGood(t *testing.T) {
+	dir := makeViews(t)
+	defer os.RemoveAll(dir)
+
+	cfg := Defaults()
+	cfg.Dialect = DialectSqlite
+	cfg.ViewsDir = dir
+	cfg.StaticDir = dir
+
+	err := cfg.Validate()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+	if cfg.DSN != "films.db" {
+		t.Errorf("Expected DSN films.db, got %s", cfg.DSN)
+	}
+}
+
+// TestUnitValidateReportsAllProblems checks that Validate reports every problem
+// in one error.
+func TestUnitValidateReportsAllProblems(t *testing.T) {
+	cfg := Defaults()
+	cfg.Dialect = DialectMySQL
+	cfg.DSN = ""
+	cfg.ListenAddress = "4000"
+	cfg.ViewsDir = "/no/such/directory"
+	cfg.StaticDir = "/no/such/directory"
+	cfg.LogLevel = "chatty"
+	cfg.LogFormat = "xml"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	for _, want := range []string{"needs a DSN", "listen address", "views directory",
+		"static directory", "log level", "log format"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention \"%s\", got %s", want, err.Error())
+		}
+	}
+}
+
+// TestUnitStringHidesPassword checks that the password in a MySQL DSN is not
+// logged.
+func TestUnitStringHidesPassword(t *testing.T) {
+	cfg := Defaults()
+	cfg.DSN = "webuser:secret@tcp(localhost:3306)/films"
+
+	if strings.Contains(cfg.String(), "secret") {
+		t.Errorf("Expected the password to be hidden, got %s", cfg.String())
+	}
+}
+
+// TestUnitValidatePoolSettings checks that bad connection pool settings are
+// reported and a good lifetime is converted to a duration.
+func TestUnitValidatePoolSettings(t *testing.T) {
+	env := map[string]string{
+		"FILMS_DIALECT":           DialectMemory,
+		"FILMS_MAX_OPEN_CONNS":    "-1",
+		"FILMS_CONN_MAX_LIFETIME": "an hour",
+	}
+	cfg, err := Load(nil, makeEnv(env))
+	if err != nil {
+		t.Fatalf("Load failed - %s", err.Error())
+	}
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	for _, want := range []string{"open connections", "an hour"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention \"%s\", got %s", want, err.Error())
+		}
+	}
+
+	cfg.ConnMaxLifetime = "30m"
+	d, err := cfg.ConnMaxLifetimeDuration()
+	if err != nil || d.Minutes() != 30 {
+		t.Errorf("Expected 30 minutes, got %v, %v", d, err)
+	}
+}
+
+// TestUnitTrashRetention checks the default trash retention period, that 0 turns
+// the purging off and that Validate rejects a setting that isn't a duration.
+func TestUnitTrashRetention(t *testing.T) {
+	cfg, err := Load(nil, makeEnv(nil))
+	if err != nil {
+		t.Fatalf("Load failed - %s", err.Error())
+	}
+	d, err := cfg.TrashRetentionDuration()
+	if err != nil || d.Hours() != 720 {
+		t.Errorf("Expected 720 hours, got %v, %v", d, err)
+	}
+
+	cfg, err = Load([]string{"-trashretention", "0"}, makeEnv(nil))
+	if err != nil {
+		t.Fatalf("Load failed - %s", err.Error())
+	}
+	d, err = cfg.TrashRetentionDuration()
+	if err != nil || d != 0 {
+		t.Errorf("Expected 0, got %v, %v", d, err)
+	}
+
+	cfg, err = Load(nil, makeEnv(map[string]string{"FILMS_TRASH_RETENTION": "30 days"}))
+	if err != nil {
+		t.Fatalf("Load failed - %s", err.Error())
+	}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "30 days") {
+		t.Errorf("Expected the error to mention \"30 days\", got %v", err)
+	}
+}
+
+// TestUnitSessionSettings checks the default session lifetime, that a short
+// session key is rejected, that the key is hidden in the log and that the secure
+// cookies setting must be true or false.
+func TestUnitSessionSettings(t *testing.T) {
+	cfg, err := Load(nil, makeEnv(nil))
+	if err != nil {
+		t.Fatalf("Load failed - %s", err.Error())
+	}
+	d, err := cfg.SessionLifetimeDuration()
+	if err != nil || d.Hours() != 12 {
+		t.Errorf("Expected 12 hours, got %v, %v", d, err)
+	}
+	if cfg.SecureCookies {
+		t.Errorf("Expected secure cookies to be off by default")
+	}
+
+	cfg, err = Load([]string{"-sessionkey", "tooshort", "-securecookies", "true"}, makeEnv(nil))
+	if err != nil {
+		t.Fatalf("Load failed - %s", err.Error())
+	}
+	if !cfg.SecureCookies {
+		t.Errorf("Expected secure cookies to be on")
+	}
+	if strings.Contains(cfg.String(), "tooshort") {
+		t.Errorf("Expected the session key to be hidden, got %s", cfg.String())
+	}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "session key") {
+		t.Errorf("Expected an error about the session key, got %v", err)
+	}
+
+	_, err = Load(nil, makeEnv(map[string]string{"FILMS_SECURE_COOKIES": "maybe"}))
+	if err == nil || !strings.Contains(err.Error(), "FILMS_SECURE_COOKIES") {
+		t.Errorf("Expected an error about FILMS_SECURE_COOKIES, got %v", err)
+	}
+}
+
+// TestUnitLoadCommand checks that LoadCommand returns the command after the
+// settings, that Load rejects it, and that ValidateDatabase doesn't need the
+// views directory.
+func TestUnitLoadCommand(t *testing.T) {
+	args := []string{"-dialect", "sqlite", "import", "-dry-run", "people.csv"}
+	cfg, command, err := LoadCommand(args, makeEnv(nil))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := []string{"import", "-dry-run", "people.csv"}
+	if strings.Join(command, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected command %v, got %v", expected, command)
+	}
+
+	cfg.ViewsDir = "no/such/directory"
+	if cfg.Validate() == nil {
+		t.Errorf("Expected Validate to reject the views directory")
+	}
+	err = cfg.ValidateDatabase()
+	if err != nil {
+		t.Errorf("Expected ValidateDatabase to pass, got %s", err.Error())
+	}
+	if cfg.DSN != "films.db" {
+		t.Errorf("Expected the default sqlite DSN, got %s", cfg.DSN)
+	}
+
+	_, err = Load(args, makeEnv(nil))
+	if err == nil {
+		t.Errorf("Expected Load to reject the command")
+	}
+}
@@ -0,0 +1,117 @@
+// Package csrf protects the HTML forms against cross-site request forgery.
+// Each browser session has a random token, kept in a signed cookie - see
+// auth.Sessions.  The template wrapper puts the token into every form that
+// posts, and the filter refuses any change that doesn't send it back, so
+// another site can't make a visitor's browser submit one of our forms.
+package csrf
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"html"
+	"io"
+	"regexp"
+
+	restful "github.com/emicklei/go-restful"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// FieldName is the name of the form field that carries the token.
+const FieldName = "_csrf"
+
+// HeaderName is the name of the request header that can carry the token instead,
+// for clients that don't send a form.
+const HeaderName = "X-CSRF-Token"
+
+// Refusal is the error message shown when a change is refused.
+const Refusal = "the form has expired or came from another site - go back, reload the page and try again"
+
+// Filter returns a go-restful filter that attaches the session's token to the
+// viewer, so that the templates can put it into the forms.  It must come after
+// the auth filter, which attaches the viewer.  If the request can change the
+// data and doesn't carry the token, the filter calls refuse to send the
+// response and the handler is not run.
+func Filter(sessions auth.Sessions,
+	refuse func(req *restful.Request, resp *restful.Response, errormessage string)) restful.FilterFunction {
+
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		logger := logging.FromRequest(req.Request)
+		token, err := sessions.Token(resp.ResponseWriter, req.Request)
+		if err != nil {
+			logger.Warn("refusing - cannot find the CSRF token", "error", err)
+			refuse(req, resp, Refusal)
+			return
+		}
+		viewer := auth.ViewerFrom(req.Request)
+		viewer.Token = token
+		req.Request = auth.WithViewer(req.Request, viewer)
+
+		if auth.Changes(req.Request.Method) && !Valid(token, sent(req)) {
+			logger.Warn("refusing - the CSRF token is missing or wrong")
+			refuse(req, resp, Refusal)
+			return
+		}
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+// sent returns the token sent with the request, in the header or the form.
+func sent(req *restful.Request) string {
+	token := req.Request.Header.Get(HeaderName)
+	if token == "" {
+		token = req.Request.FormValue(FieldName)
+	}
+	return token
+}
+
+// Valid returns true if the sent token matches the session's token.
+func Valid(token string, sent string) bool {
+	if token == "" || sent == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(sent)) == 1
+}
+
+// viewed is satisfied by the forms that carry the viewer.
+type viewed interface {
+	Viewer() auth.Viewer
+}
+
+// postForm matches the start tag of a form that posts.
+var postForm = regexp.MustCompile(`(?i)<form\s[^>]*method\s*=\s*['"]?post['"]?[^>]*>`)
+
+// ConcreteTemplate wraps a template and puts the viewer's token into every form
+// that posts.  It satisfies the retrofit Template interface.
+type ConcreteTemplate struct {
+	template retroTemplate.Template
+}
+
+// MakeTemplate is a factory function that wraps the given template so that its
+// forms carry the token.
+func MakeTemplate(template retroTemplate.Template) retroTemplate.Template {
+	return &ConcreteTemplate{template: template}
+}
+
+// Execute executes the wrapped template.  If the data carries a viewer with a
+// token, a hidden field holding the token is added to each form that posts.
+func (ct *ConcreteTemplate) Execute(wr io.Writer, data interface{}) error {
+	form, ok := data.(viewed)
+	if !ok || form.Viewer().Token == "" {
+		return ct.template.Execute(wr, data)
+	}
+
+	var buffer bytes.Buffer
+	err := ct.template.Execute(&buffer, data)
+	if err != nil {
+		return err
+	}
+	field := []byte("\n\t\t<input name='" + FieldName + "' value='" +
+		html.EscapeString(form.Viewer().Token) + "' type='hidden'/>")
+	page := postForm.ReplaceAllFunc(buffer.Bytes(), func(tag []byte) []byte {
+		return append(append([]byte{}, tag...), field...)
+	})
+	_, err = wr.Write(page)
+	return err
+}
@@ -0,0 +1,214 @@
+package csrf
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/goblimey/films/utilities/auth"
+)
+
+// makeContainer creates a container with a route for GET and PUT that is
+// protected by the filter.  The handler records the viewer that it was given.
+func makeContainer(sessions auth.Sessions, viewer *auth.Viewer) *restful.Container {
+	handler := func(req *restful.Request, resp *restful.Response) {
+		*viewer = auth.ViewerFrom(req.Request)
+	}
+	refuse := func(req *restful.Request, resp *restful.Response, errormessage string) {
+		resp.WriteHeader(http.StatusForbidden)
+		resp.Write([]byte(errormessage))
+	}
+	ws := new(restful.WebService)
+	ws.Path("/people").Filter(Filter(sessions, refuse))
+	ws.Route(ws.GET("").To(handler))
+	ws.Route(ws.PUT("").Consumes("application/x-www-form-urlencoded").To(handler))
+	container := restful.NewContainer()
+	container.Add(ws)
+	return container
+}
+
+// TestUnitFilter checks that the filter gives a new visitor a token cookie and
+// attaches the token to the viewer, and that it only lets a change through if
+// the token is sent back in the form or the header.
+func TestUnitFilter(t *testing.T) {
+	sessions := auth.MakeSessions("a session key of at least 32 characters", time.Hour, false)
+	var viewer auth.Viewer
+	container := makeContainer(sessions, &viewer)
+
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest("GET", "/people", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET: expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != auth.TokenCookieName {
+		t.Fatalf("expected a token cookie, got %v", cookies)
+	}
+	cookie := cookies[0]
+	token := viewer.Token
+	if token == "" {
+		t.Fatalf("expected the viewer to carry the token")
+	}
+
+	// With the cookie, the token stays the same and no new cookie is sent.
+	req := httptest.NewRequest("GET", "/people", nil)
+	req.AddCookie(cookie)
+	recorder = httptest.NewRecorder()
+	container.ServeHTTP(recorder, req)
+	if viewer.Token != token || len(recorder.Result().Cookies()) != 0 {
+		t.Errorf("expected the same token and no cookie, got %q and %v", viewer.Token,
+			recorder.Result().Cookies())
+	}
+
+	var tests = []struct {
+		description string
+		field       string
+		header      string
+		cookie      bool
+		status      int
+	}{
+		{"token in the form", token, "", true, http.StatusOK},
+		{"token in the header", "", token, true, http.StatusOK},
+		{"no token", "", "", true, http.StatusForbidden},
+		{"wrong token", token + "x", "", true, http.StatusForbidden},
+		{"no cookie", token, "", false, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		body := url.Values{"forename": {"Orson"}, FieldName: {test.field}}
+		req := httptest.NewRequest("PUT", "/people", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if test.header != "" {
+			req.Header.Set(HeaderName, test.header)
+		}
+		if test.cookie {
+			req.AddCookie(cookie)
+		}
+		recorder := httptest.NewRecorder()
+		container.ServeHTTP(recorder, req)
+		if recorder.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.description, test.status,
+				recorder.Code)
+		}
+		if test.status == http.StatusForbidden && recorder.Body.String() != Refusal {
+			t.Errorf("%s: expected the refusal, got %q", test.description,
+				recorder.Body.String())
+		}
+	}
+}
+
+// TestUnitLoginReplacesToken checks that logging in or out gives the browser a
+// new token, so that a token issued before then is refused.
+func TestUnitLoginReplacesToken(t *testing.T) {
+	sessions := auth.MakeSessions("a session key of at least 32 characters", time.Hour, false)
+	var viewer auth.Viewer
+	container := makeContainer(sessions, &viewer)
+
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest("GET", "/people", nil))
+	oldToken := viewer.Token
+
+	var tests = []struct {
+		description string
+		change      func(w http.ResponseWriter)
+	}{
+		{"login", func(w http.ResponseWriter) {
+			err := sessions.Login(w, "orson")
+			if err != nil {
+				t.Fatalf("login failed - %s", err.Error())
+			}
+		}},
+		{"logout", sessions.Logout},
+	}
+
+	for _, test := range tests {
+		recorder = httptest.NewRecorder()
+		test.change(recorder)
+		var cookies []*http.Cookie
+		for _, cookie := range recorder.Result().Cookies() {
+			if cookie.Name == auth.TokenCookieName {
+				cookies = append(cookies, cookie)
+			}
+		}
+		if len(cookies) != 1 {
+			t.Fatalf("%s: expected a token cookie, got %v", test.description,
+				recorder.Result().Cookies())
+		}
+
+		// The new cookie carries a different token.
+		req := httptest.NewRequest("GET", "/people", nil)
+		req.AddCookie(cookies[0])
+		container.ServeHTTP(httptest.NewRecorder(), req)
+		if viewer.Token == "" || viewer.Token == oldToken {
+			t.Errorf("%s: expected a new token, got %q", test.description, viewer.Token)
+		}
+		newToken := viewer.Token
+
+		// The token from before is refused and the new one accepted.
+		for _, sent := range []string{oldToken, newToken} {
+			body := url.Values{"forename": {"Orson"}, FieldName: {sent}}
+			req := httptest.NewRequest("PUT", "/people", strings.NewReader(body.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(cookies[0])
+			recorder := httptest.NewRecorder()
+			container.ServeHTTP(recorder, req)
+			want := http.StatusOK
+			if sent == oldToken {
+				want = http.StatusForbidden
+			}
+			if recorder.Code != want {
+				t.Errorf("%s: expected status %d, got %d", test.description, want,
+					recorder.Code)
+			}
+		}
+	}
+}
+
+// form is a minimal form that carries a viewer.
+type form struct {
+	viewer auth.Viewer
+}
+
+func (f form) Viewer() auth.Viewer {
+	return f.viewer
+}
+
+// TestUnitTemplate checks that the wrapped template puts the token into the
+// forms that post, and only those.
+func TestUnitTemplate(t *testing.T) {
+	tp := template.Must(template.New("page").Parse(
+		"<form action='/people' method='post'><input name='_method' value='PUT'/></form>\n" +
+			"<form action=\"/people/1/delete\" METHOD=\"POST\" style='display: inline;'></form>\n" +
+			"<form action='/search' method='get'></form>\n"))
+	wrapped := MakeTemplate(tp)
+
+	var buffer bytes.Buffer
+	err := wrapped.Execute(&buffer, form{auth.Viewer{Token: "abc<d"}})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	page := buffer.String()
+	field := "<input name='_csrf' value='abc&lt;d' type='hidden'/>"
+	if strings.Count(page, field) != 2 {
+		t.Errorf("expected the token in two forms, got %s", page)
+	}
+	if !strings.Contains(page, "method='get'></form>") {
+		t.Errorf("expected no token in the get form, got %s", page)
+	}
+
+	// Without a token, the page is left alone.
+	buffer.Reset()
+	err = wrapped.Execute(&buffer, form{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if strings.Contains(buffer.String(), FieldName) {
+		t.Errorf("expected no token, got %s", buffer.String())
+	}
+}
@@ -1,10 +1,162 @@
 package dbsession
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
 	gorp "gopkg.in/gorp.v1"
+
+	auditModel "github.com/goblimey/films/models/audit"
+	awardModel "github.com/goblimey/films/models/award"
+	companyModel "github.com/goblimey/films/models/company"
+	creditModel "github.com/goblimey/films/models/credit"
+	diaryModel "github.com/goblimey/films/models/diary"
+	filmModel "github.com/goblimey/films/models/film"
+	genreModel "github.com/goblimey/films/models/genre"
 	personModel "github.com/goblimey/films/models/person"
+	reviewModel "github.com/goblimey/films/models/review"
+	tagModel "github.com/goblimey/films/models/tag"
+	userModel "github.com/goblimey/films/models/user"
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	"github.com/goblimey/films/utilities/migrations"
 )
 
+// Transaction represents a database transaction.  The GORP sessions wrap a
+// *gorp.Transaction, which supplies the Insert, Update, Delete, Commit and
+// Rollback methods.  Other sessions, such as the in-memory session, supply their
+// own implementation.
+type Transaction interface {
+
+	// Insert adds the given records, setting the auto-incremented ID in each.
+	Insert(list ...interface{}) error
+
+	// Update updates the records with the same IDs as the given ones and
+	// returns the number of records updated.
+	Update(list ...interface{}) (int64, error)
+
+	// Delete removes the records with the same IDs as the given ones and
+	// returns the number of records deleted.
+	Delete(list ...interface{}) (int64, error)
+
+	// Commit commits the transaction.
+	Commit() error
+
+	// Rollback abandons the transaction.
+	Rollback() error
+
+	// FindAllCreditsByFilm returns all of the credits on the film with the given
+	// ID, including those of the people in the trash, in order of ID.  The display
+	// fields may not be filled in.  It's used to find the credits to delete along
+	// with the film.
+	FindAllCreditsByFilm(filmID uint64) ([]creditModel.Credit, error)
+
+	// FindAllNominationsByFilm returns all of the award nominations of the film
+	// with the given ID, including those of the people in the trash, in order of
+	// ID.  The display fields may not be filled in.  It's used to find the
+	// nominations to delete along with the film.
+	FindAllNominationsByFilm(filmID uint64) ([]awardModel.Nomination, error)
+
+	// FindAllCreditsByPerson returns all of the credits of the person with the
+	// given ID, in order of ID.  The display fields may not be filled in.  It's
+	// used to find the credits to delete along with the person.
+	FindAllCreditsByPerson(personID uint64) ([]creditModel.Credit, error)
+
+	// FindAllNominationsByPerson returns all of the award nominations of the
+	// person with the given ID, in order of ID.  The display fields may not be
+	// filled in.  It's used to find the nominations to keep without the person
+	// when the person is purged.
+	FindAllNominationsByPerson(personID uint64) ([]awardModel.Nomination, error)
+
+	// FindGenreLinks returns the links that attach genres to the given subject,
+	// in order of ID.  It's used to find the links to delete along with the
+	// subject.
+	FindGenreLinks(subject string, subjectID uint64) ([]genreModel.Link, error)
+
+	// FindTagLinks returns the links that attach tags to the given subject, in
+	// order of ID.  It's used to find the links to delete along with the subject.
+	FindTagLinks(subject string, subjectID uint64) ([]tagModel.Link, error)
+
+	// FindAllCompanyLinksByFilm returns all of the links between the film with
+	// the given ID and its companies, in order of ID.  The display fields may not
+	// be filled in.  It's used to find the links to delete along with the film.
+	FindAllCompanyLinksByFilm(filmID uint64) ([]companyModel.Link, error)
+
+	// FindAllReviewsByFilm returns all of the reviews of the film with the given
+	// ID, in order of ID.  The display fields may not be filled in.  It's used to
+	// find the reviews to delete along with the film.
+	FindAllReviewsByFilm(filmID uint64) ([]reviewModel.Review, error)
+
+	// FindRatingSummary fetches the summary of the ratings of the film with the
+	// given ID.  If there is none, it returns sql.ErrNoRows.  It's used to find
+	// the summary to delete along with the film.
+	FindRatingSummary(filmID uint64) (reviewModel.Summary, error)
+
+	// FindAllWatchlistByFilm returns all of the entries for the film with the
+	// given ID on the watchlists, in order of ID.  The display fields may not be
+	// filled in.  It's used to find the entries to delete along with the film.
+	FindAllWatchlistByFilm(filmID uint64) ([]watchlistModel.Entry, error)
+
+	// FindAllDiaryByFilm returns all of the entries for the film with the given
+	// ID in the diaries, in order of ID.  The display fields may not be filled
+	// in.  It's used to find the entries to delete along with the film.
+	FindAllDiaryByFilm(filmID uint64) ([]diaryModel.Entry, error)
+}
+
+// IsConflict returns true if the error shows that an update or delete failed
+// because the record had been changed since it was read, or that an insert failed
+// because another transaction had just inserted a record with the same unique
+// key, for example the first two ratings of a film arriving together.  Both the
+// GORP sessions and the in-memory session return a gorp.OptimisticLockError in
+// the first case.  In the second, each database returns its own error.
+func IsConflict(err error) bool {
+	if lockError, ok := err.(gorp.OptimisticLockError); ok {
+		return lockError.RowExists
+	}
+	return err == errDuplicateKey || isMysqlDuplicateKey(err) || isSqliteDuplicateKey(err)
+}
+
+// The fields that the people can be sorted on.
+const (
+	SortBySurname  = "surname"
+	SortByForename = "forename"
+	SortByID       = "id"
+)
+
+// The subjects that genres and tags can be attached to.  Each is the name of the
+// table that holds the subject, as in the audit log.
+const (
+	SubjectFilm   = "films"
+	SubjectPerson = "people"
+)
+
+// PeopleQuery says which of the people FindPeople should fetch.  Name is matched,
+// ignoring case, against any part of the forename or the surname.  An empty Name
+// matches everybody.  GenreID and TagID, if not 0, only match the people with that
+// tag or in that genre or any genre below it.  Sort is SortBySurname,
+// SortByForename or SortByID, and people with the same forename or surname are in
+// order of ID.  Offset is the number of people to skip and Limit is the number to
+// fetch - 0 means no limit.
+type PeopleQuery struct {
+	Name       string
+	GenreID    uint64
+	TagID      uint64
+	Sort       string
+	Descending bool
+	Offset     int
+	Limit      int
+}
+
+// FilmQuery says which of the films FindFilms should fetch.  GenreID and TagID,
+// if not 0, only match the films with that tag or in that genre or any genre below
+// it.  The zero value matches all films.
+type FilmQuery struct {
+	GenreID uint64
+	TagID   uint64
+}
+
 // DBSession represents a database session.
 type DBSession interface {
 
@@ -12,21 +164,377 @@ type DBSession interface {
 	Start a new transaction.  A transaction is a resource overhead and the caller should
 	call Close() when it's finished to release this resource.
 	*/
-	StartTransaction() (*gorp.Transaction, error)
+	StartTransaction() (Transaction, error)
 	
 	// Close the DBSession and release the resources associated with it.
 	Close()
 
+	/*
+	WithContext() returns a copy of the session that logs against the given context,
+	which carries the logger of the request being served.  The copy shares the
+	database connections with the original.
+	*/
+	WithContext(ctx context.Context) DBSession
+
 	/*
 	FindAllPeople() gets all records in the people table (whether valid or not) and returns a 
 	pointer to a slice containing them.  The method does not create an explicit transaction.
+	People in the trash are left out.
 	*/
 	FindAllPeople() ([]personModel.Person, error)
 
+	/*
+		FindPeople() gets one page of the valid records in the people table, filtered and
+		sorted as the query says, and the total number of valid records that match the
+		filter.  The filtering, sorting and paging are done by the database.  People in
+		the trash are left out.
+	*/
+	FindPeople(query PeopleQuery) ([]personModel.Person, int64, error)
+
 	/*
 	 FindPersonByid fetches the row from the people table with the given uint64 id. The
 	 data fetched may or may not be valid.  The method returns a Person containing
-	 that data, or an error message.
+	 that data, or an error message.  A person in the trash is not found.
 	*/
 	FindPersonByID(id uint64) (personModel.Person, error)
-}
\ No newline at end of file
+
+	/*
+	FindDeletedPeople() gets the people in the trash - the records in the people table
+	that have been deleted but not yet purged - most recently deleted first.
+	*/
+	FindDeletedPeople() ([]personModel.Person, error)
+
+	/*
+	 FindDeletedPersonByID fetches the person in the trash with the given uint64 id.  If
+	 there is no such person, or they have not been deleted, it returns sql.ErrNoRows.
+	*/
+	FindDeletedPersonByID(id uint64) (personModel.Person, error)
+
+	/*
+	FindAllFilms() gets all valid records in the films table and returns a slice
+	containing them.  The method does not create an explicit transaction.
+	*/
+	FindAllFilms() ([]filmModel.Film, error)
+
+	/*
+	FindFilms() gets the valid records in the films table that match the query, in
+	order of ID.
+	*/
+	FindFilms(query FilmQuery) ([]filmModel.Film, error)
+
+	/*
+	 FindFilmByID fetches the row from the films table with the given uint64 id. The
+	 data fetched may or may not be valid.  The method returns a Film containing
+	 that data, or an error message.
+	*/
+	FindFilmByID(id uint64) (filmModel.Film, error)
+
+	/*
+	 FindCreditByID fetches the row from the credits table with the given uint64 id,
+	 along with the film title and the person's name.  The method returns a Credit
+	 containing that data, or an error message.
+	*/
+	FindCreditByID(id uint64) (creditModel.Credit, error)
+
+	/*
+	FindCreditsByPerson() gets the credits of the person with the given ID, in
+	order of release year, along with the titles of the films.
+	*/
+	FindCreditsByPerson(personID uint64) ([]creditModel.Credit, error)
+
+	/*
+	FindCreditsByFilm() gets the credits on the film with the given ID, in order
+	of role and billing, along with the names of the people.  The credits of people
+	in the trash are left out.
+	*/
+	FindCreditsByFilm(filmID uint64) ([]creditModel.Credit, error)
+
+	/*
+	FindAuditEntries() gets the audit log entries for the record with the given ID
+	in the given table, newest first.  The entries are still there after the record
+	is deleted.
+	*/
+	FindAuditEntries(tableName string, recordID uint64) ([]auditModel.Entry, error)
+
+	/*
+	FindUserByUsername() fetches the user with the given username.  If there is no
+	such user, it returns sql.ErrNoRows.
+	*/
+	FindUserByUsername(username string) (userModel.User, error)
+
+	/*
+	FindAllGenres() gets all of the records in the genres table, in order of ID.
+	*/
+	FindAllGenres() ([]genreModel.Genre, error)
+
+	/*
+	FindGenreLinks() gets the links that attach genres to the given subject, in
+	order of ID.  The subject is SubjectFilm or SubjectPerson.
+	*/
+	FindGenreLinks(subject string, subjectID uint64) ([]genreModel.Link, error)
+
+	/*
+	FindAllTags() gets the tags that are attached to at least one film or person,
+	in order of name, each with the number of things that it's attached to.
+	*/
+	FindAllTags() ([]tagModel.Tag, error)
+
+	/*
+	FindTagByID() fetches the tag with the given ID, with the number of things that
+	it's attached to.  If there is no such tag, it returns sql.ErrNoRows.
+	*/
+	FindTagByID(id uint64) (tagModel.Tag, error)
+
+	/*
+	FindTagByName() fetches the tag with the given name, which must already be
+	normalised.  If there is no such tag, it returns sql.ErrNoRows.
+	*/
+	FindTagByName(name string) (tagModel.Tag, error)
+
+	/*
+	FindTagLinks() gets the links that attach tags to the given subject, in order
+	of ID.  The subject is SubjectFilm or SubjectPerson.
+	*/
+	FindTagLinks(subject string, subjectID uint64) ([]tagModel.Link, error)
+
+	/*
+	FindAllCompanies() gets all of the records in the companies table, in order of
+	name.
+	*/
+	FindAllCompanies() ([]companyModel.Company, error)
+
+	/*
+	FindCompanyByID() fetches the company with the given ID.  If there is no such
+	company, it returns sql.ErrNoRows.
+	*/
+	FindCompanyByID(id uint64) (companyModel.Company, error)
+
+	/*
+	FindCompanyLinkByID() fetches the link between a company and a film with the
+	given ID, along with the film title and the company name.  If there is no such
+	link, it returns sql.ErrNoRows.
+	*/
+	FindCompanyLinkByID(id uint64) (companyModel.Link, error)
+
+	/*
+	FindCompanyLinksByFilm() gets the links between the film with the given ID and
+	the companies that made or distributed it, in order of relationship and company
+	name.
+	*/
+	FindCompanyLinksByFilm(filmID uint64) ([]companyModel.Link, error)
+
+	/*
+	FindCompanyLinksByCompany() gets the links between the company with the given ID
+	and its films, in order of release year, along with the titles of the films.
+	*/
+	FindCompanyLinksByCompany(companyID uint64) ([]companyModel.Link, error)
+
+	/*
+	FindReviewByID fetches the row from the reviews table with the given uint64 id,
+	along with the username of the reviewer.  If there is no such review, it returns
+	sql.ErrNoRows.
+	*/
+	FindReviewByID(id uint64) (reviewModel.Review, error)
+
+	/*
+	FindReviewByFilmAndUser fetches the review of the film with the given ID by the
+	user with the given ID.  If they haven't reviewed the film, it returns
+	sql.ErrNoRows.
+	*/
+	FindReviewByFilmAndUser(filmID uint64, userID uint64) (reviewModel.Review, error)
+
+	/*
+	FindReviewsByFilm() gets the reviews of the film with the given ID, most recently
+	written or changed first, along with the usernames of the reviewers.
+	*/
+	FindReviewsByFilm(filmID uint64) ([]reviewModel.Review, error)
+
+	/*
+	FindRatingSummary fetches the summary of the ratings of the film with the given
+	ID.  If the film has never been rated, it returns sql.ErrNoRows.
+	*/
+	FindRatingSummary(filmID uint64) (reviewModel.Summary, error)
+
+	/*
+	FindWatchlistEntryByID fetches the row from the watchlist table with the given
+	uint64 id, along with the title of the film.  If there is no such entry, it
+	returns sql.ErrNoRows.
+	*/
+	FindWatchlistEntryByID(id uint64) (watchlistModel.Entry, error)
+
+	/*
+	FindWatchlistEntryByUserAndFilm fetches the entry for the film with the given ID
+	on the watchlist of the user with the given ID.  If the film is not on their
+	list, it returns sql.ErrNoRows.
+	*/
+	FindWatchlistEntryByUserAndFilm(userID uint64, filmID uint64) (watchlistModel.Entry, error)
+
+	/*
+	FindWatchlistByUser() gets the watchlist of the user with the given ID, most
+	recently added first, along with the titles of the films.
+	*/
+	FindWatchlistByUser(userID uint64) ([]watchlistModel.Entry, error)
+
+	/*
+	FindWatchlistByFilm() gets the entries for the film with the given ID on
+	everybody's watchlists.
+	*/
+	FindWatchlistByFilm(filmID uint64) ([]watchlistModel.Entry, error)
+
+	/*
+	FindDiaryEntryByID fetches the row from the diary table with the given uint64
+	id, along with the title of the film.  If there is no such entry, it returns
+	sql.ErrNoRows.
+	*/
+	FindDiaryEntryByID(id uint64) (diaryModel.Entry, error)
+
+	/*
+	FindDiaryByUser() gets the diary of the user with the given ID, most recent
+	viewing first, along with the titles of the films.
+	*/
+	FindDiaryByUser(userID uint64) ([]diaryModel.Entry, error)
+
+	/*
+	FindDiaryByFilm() gets the entries for the film with the given ID in everybody's
+	diaries.
+	*/
+	FindDiaryByFilm(filmID uint64) ([]diaryModel.Entry, error)
+
+	/*
+	FindAllCeremonies() gets all of the records in the award_ceremonies table, most
+	recent year first and then in order of name.
+	*/
+	FindAllCeremonies() ([]awardModel.Ceremony, error)
+
+	/*
+	FindCeremonyByID fetches the award ceremony with the given ID.  If there is no
+	such ceremony, it returns sql.ErrNoRows.
+	*/
+	FindCeremonyByID(id uint64) (awardModel.Ceremony, error)
+
+	/*
+	FindCategoryByID fetches the award category with the given ID.  If there is no
+	such category, it returns sql.ErrNoRows.
+	*/
+	FindCategoryByID(id uint64) (awardModel.Category, error)
+
+	/*
+	FindCategoriesByCeremony() gets the categories of the award ceremony with the
+	given ID, in order of name.
+	*/
+	FindCategoriesByCeremony(ceremonyID uint64) ([]awardModel.Category, error)
+
+	/*
+	FindNominationByID fetches the row from the nominations table with the given
+	uint64 id, along with its display fields.  If there is no such nomination, it
+	returns sql.ErrNoRows.
+	*/
+	FindNominationByID(id uint64) (awardModel.Nomination, error)
+
+	/*
+	FindNominationsByCategory() gets all of the nominations in the award category
+	with the given ID, in order of ID, including those of people in the trash.
+	*/
+	FindNominationsByCategory(categoryID uint64) ([]awardModel.Nomination, error)
+
+	/*
+	FindNominationsByCeremony() gets the nominations in all of the categories of the
+	award ceremony with the given ID, in order of category name, with the winners
+	of each category first.  Nominations of people in the trash are left out.
+	*/
+	FindNominationsByCeremony(ceremonyID uint64) ([]awardModel.Nomination, error)
+
+	/*
+	FindNominationsByFilm() gets the nominations of the film with the given ID, most
+	recent ceremony first.  Nominations of people in the trash are left out.
+	*/
+	FindNominationsByFilm(filmID uint64) ([]awardModel.Nomination, error)
+
+	/*
+	FindNominationsByPerson() gets the nominations of the person with the given ID,
+	most recent ceremony first.
+	*/
+	FindNominationsByPerson(personID uint64) ([]awardModel.Nomination, error)
+}
+
+// The dialects that MakeDBSession can create a session for.
+const (
+	DialectMySQL  = "mysql"
+	DialectSqlite = "sqlite"
+	DialectMemory = "memory"
+)
+
+// DefaultSqliteFile is the file used by the SQLite dialect if no DSN is given.
+const DefaultSqliteFile = "films.db"
+
+// PoolLimits are the limits on the pool of connections to the database.  A zero
+// value leaves the database/sql default in place, which for MaxOpenConns and
+// ConnMaxLifetime means no limit.
+type PoolLimits struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// MakeDBSession is a factory function that creates a DBSession for the given
+// dialect and data source name and returns it.  For MySQL the DSN is something
+// like "user:password@tcp(localhost:3306)/films".  For SQLite it's the name of
+// the file that holds the data.  The memory dialect ignores it.  Each call
+// creates a new session, so each in-memory session starts with empty tables.
+// The session has its own pool of connections with the default limits.  It
+// should be shared by everything that uses the database and closed when it's
+// finished with.  A MySQL or SQLite database must have had all of the
+// migrations applied - if not, the result is a migrations.BehindError.
+func MakeDBSession(dialect string, dsn string) (DBSession, error) {
+	return MakeDBSessionWithPool(dialect, dsn, PoolLimits{})
+}
+
+// MakeDBSessionWithPool is like MakeDBSession, but it sets limits on the pool of
+// connections.  SQLite only allows one writer, so its pool always has a single
+// connection.
+func MakeDBSessionWithPool(dialect string, dsn string, limits PoolLimits) (DBSession, error) {
+	switch dialect {
+	case DialectMySQL:
+		return MakeGorpMysqlDBSession(dsn, limits)
+	case DialectSqlite:
+		if dsn == "" {
+			dsn = DefaultSqliteFile
+		}
+		return MakeGorpSqliteDBSession(dsn)
+	case DialectMemory:
+		return MakeMemoryDBSession(), nil
+	default:
+		return nil, fmt.Errorf("unknown database dialect \"%s\"", dialect)
+	}
+}
+
+// MakeMigrator is a factory function that opens the database for the given
+// dialect and data source name and returns a Migrator that manages its schema.
+// The Migrator should be closed when it's finished with.  The memory dialect has
+// no schema, so there is nothing to migrate.
+func MakeMigrator(dialect string, dsn string) (migrations.Migrator, error) {
+	var db *sql.DB
+	var err error
+	switch dialect {
+	case DialectMySQL:
+		db, err = openMysql(dsn, PoolLimits{})
+	case DialectSqlite:
+		if dsn == "" {
+			dsn = DefaultSqliteFile
+		}
+		db, err = openSqlite(dsn)
+	case DialectMemory:
+		return nil, errors.New("it has no schema")
+	default:
+		return nil, fmt.Errorf("unknown database dialect \"%s\"", dialect)
+	}
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := migrations.MakeMigrator(db, dialect)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return migrator, nil
+}
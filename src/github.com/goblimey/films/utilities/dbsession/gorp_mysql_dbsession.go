@@ -1,70 +1,499 @@
 package dbsession
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"strings"
 
+	auditModel "github.com/goblimey/films/models/audit"
+	gorpAuditModel "github.com/goblimey/films/models/audit/gorpmysql"
+	awardModel "github.com/goblimey/films/models/award"
+	gorpAwardModel "github.com/goblimey/films/models/award/gorpmysql"
+	companyModel "github.com/goblimey/films/models/company"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	creditModel "github.com/goblimey/films/models/credit"
+	gorpCreditModel "github.com/goblimey/films/models/credit/gorpmysql"
+	diaryModel "github.com/goblimey/films/models/diary"
+	gorpDiaryModel "github.com/goblimey/films/models/diary/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film"
+	gorpFilmModel "github.com/goblimey/films/models/film/gorpmysql"
+	genreModel "github.com/goblimey/films/models/genre"
+	gorpGenreModel "github.com/goblimey/films/models/genre/gorpmysql"
 	personModel "github.com/goblimey/films/models/person"
 	gorpModel "github.com/goblimey/films/models/person/gorpmysql"
+	reviewModel "github.com/goblimey/films/models/review"
+	gorpReviewModel "github.com/goblimey/films/models/review/gorpmysql"
+	tagModel "github.com/goblimey/films/models/tag"
+	gorpTagModel "github.com/goblimey/films/models/tag/gorpmysql"
+	userModel "github.com/goblimey/films/models/user"
+	gorpUserModel "github.com/goblimey/films/models/user/gorpmysql"
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	gorpWatchlistModel "github.com/goblimey/films/models/watchlist/gorpmysql"
+	"github.com/goblimey/films/utilities/logging"
+	"github.com/goblimey/films/utilities/migrations"
 	gorp "gopkg.in/gorp.v1"
 	// This import must be present to satisfy a dependency in the GORP library.
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
+// mysqlDuplicateKey is the number of the MySQL error returned when an insert
+// breaks a unique index.
+const mysqlDuplicateKey = 1062
+
 // The GorpMysqlDBSession type represents a MySQL database session accessed via GORP.
 // It satisfies the DBSession interface.
 type GorpMysqlDBSession struct {
 	dbmap *gorp.DbMap
+	ctx   context.Context
 }
 
 // MakeGorpMysqlDBSession is a factory function that creates a GorpMysqlDBSession and returns it as a pointer to a DBSession.
-func MakeGorpMysqlDBSession() (DBSession, error) {
-	log.SetPrefix("DBSessionFactory.MakeGorpMysqlDBSession() ")
-	db, err := sql.Open("mysql", "webuser:secret@tcp(localhost:3306)/films")
+// The DSN says how to connect to the database, for example "user:password@tcp(localhost:3306)/films".
+// The session holds a pool of connections with the given limits.  It's safe for concurrent use,
+// so one session can serve the whole application.  If any of the migrations have not been applied
+// to the database, it returns a migrations.BehindError.
+func MakeGorpMysqlDBSession(dsn string, limits PoolLimits) (DBSession, error) {
+	db, err := openMysql(dsn, limits)
+	if err != nil {
+		return nil, err
+	}
+	// construct a gorp DbMap
+	dbmap := &gorp.DbMap{Db: db, Dialect: gorp.MySQLDialect{"InnoDB", "UTF8"}}
+	err = addTables(dbmap, migrations.DialectMySQL)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// Create a concrete DBSession and an interface reference to it.
+	var session DBSession = &GorpMysqlDBSession{dbmap: dbmap}
+
+	// Return the interface reference.
+	return session, nil
+}
+
+// openMysql opens a MySQL database handle with a pool of connections with the
+// given limits and checks that it works.
+func openMysql(dsn string, limits PoolLimits) (*sql.DB, error) {
+	if dsn == "" {
+		return nil, errors.New("no DSN for the MySQL database")
+	}
+	db, err := sql.Open("mysql", dsn)
 	if err != nil {
-		log.Printf("failed to get DB handle - %s\n" + err.Error())
+		slog.Error("failed to get DB handle", "error", err)
 		return nil, errors.New("failed to get DB handle - " + err.Error())
 	}
+	db.SetMaxOpenConns(limits.MaxOpenConns)
+	if limits.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(limits.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(limits.ConnMaxLifetime)
 	// check that the handle works
 	err = db.Ping()
 	if err != nil {
-		log.Printf("cannot connect to DB.  %s\n", err.Error())
+		slog.Error("cannot connect to DB", "error", err)
+		db.Close()
 		return nil, err
 	}
-	// construct a gorp DbMap
-	dbmap := &gorp.DbMap{Db: db, Dialect: gorp.MySQLDialect{"InnoDB", "UTF8"}}
+	return db, nil
+}
+
+// addTables maps the models onto the tables and checks that the schema is up to
+// date.  All of the GORP sessions use the same mapping.  The tables are created
+// and changed by the migrations, not by GORP.
+func addTables(dbmap *gorp.DbMap, dialect string) error {
 	table := dbmap.AddTableWithName(gorpModel.GorpMysqlPerson{}, "people").SetKeys(true, "IDField")
 	if table == nil {
 		em := "cannot add table people"
-		log.Println(em)
-		return nil, errors.New(em)
+		slog.Error(em)
+		return errors.New(em)
 	}
 
 	table.ColMap("IDField").Rename("id")
 	table.ColMap("ForenameField").Rename("forename")
 	table.ColMap("SurnameField").Rename("surname")
+	// GORP checks and increments the version on each update and delete, so an
+	// update based on a stale copy of the row fails with an OptimisticLockError.
+	table.SetVersionCol("VersionField").Rename("version")
+	// 0 for a live person, otherwise the time that they were moved to the trash.
+	table.ColMap("DeletedAtField").Rename("deleted_at").SetNotNull(true)
 
-	// Create any missing tables.
-	err = dbmap.CreateTablesIfNotExists()
-	if err != nil {
-		em := fmt.Sprintf("cannot create table - %s\n", err.Error())
-		log.Printf("em")
-		return nil, errors.New(em)
+	filmTable := dbmap.AddTableWithName(gorpFilmModel.GorpMysqlFilm{}, "films").SetKeys(true, "IDField")
+	if filmTable == nil {
+		em := "cannot add table films"
+		slog.Error(em)
+		return errors.New(em)
 	}
 
-	// Create a concrete DBSession and an interface reference to it.
-	var session DBSession = &GorpMysqlDBSession{dbmap}
+	filmTable.ColMap("IDField").Rename("id")
+	filmTable.ColMap("TitleField").Rename("title").SetMaxSize(255)
+	filmTable.ColMap("ReleaseYearField").Rename("release_year")
+	filmTable.ColMap("RuntimeField").Rename("runtime")
+	filmTable.ColMap("SynopsisField").Rename("synopsis").SetMaxSize(2000)
 
-	// Return the interface reference.
-	return session, nil
+	creditTable := dbmap.AddTableWithName(gorpCreditModel.GorpMysqlCredit{}, "credits").SetKeys(true, "IDField")
+	if creditTable == nil {
+		em := "cannot add table credits"
+		slog.Error(em)
+		return errors.New(em)
+	}
+
+	creditTable.ColMap("IDField").Rename("id")
+	creditTable.ColMap("PersonIDField").Rename("person_id")
+	creditTable.ColMap("FilmIDField").Rename("film_id")
+	creditTable.ColMap("RoleField").Rename("role").SetMaxSize(20)
+	// "character" is a reserved word in MySQL.
+	creditTable.ColMap("CharacterField").Rename("character_name").SetMaxSize(255)
+	creditTable.ColMap("BillingField").Rename("billing")
+	// The display fields are filled in by the joins in the finders.
+	creditTable.ColMap("FilmTitleField").SetTransient(true)
+	creditTable.ColMap("FilmReleaseYearField").SetTransient(true)
+	creditTable.ColMap("PersonForenameField").SetTransient(true)
+	creditTable.ColMap("PersonSurnameField").SetTransient(true)
+
+	auditTable := dbmap.AddTableWithName(gorpAuditModel.GorpMysqlEntry{}, "audit_log").SetKeys(true, "IDField")
+	if auditTable == nil {
+		em := "cannot add table audit_log"
+		slog.Error(em)
+		return errors.New(em)
+	}
+
+	auditTable.ColMap("IDField").Rename("id")
+	auditTable.ColMap("TableNameField").Rename("table_name").SetMaxSize(64)
+	auditTable.ColMap("RecordIDField").Rename("record_id")
+	auditTable.ColMap("ActionField").Rename("action").SetMaxSize(20)
+	auditTable.ColMap("VersionField").Rename("version")
+	auditTable.ColMap("ChangedByField").Rename("changed_by").SetMaxSize(255)
+	auditTable.ColMap("ChangedAtField").Rename("changed_at")
+	// "before" is a reserved word in MySQL.
+	auditTable.ColMap("BeforeField").Rename("before_values").SetMaxSize(2000)
+	auditTable.ColMap("AfterField").Rename("after_values").SetMaxSize(2000)
+
+	userTable := dbmap.AddTableWithName(gorpUserModel.GorpMysqlUser{}, "users").SetKeys(true, "IDField")
+	if userTable == nil {
+		em := "cannot add table users"
+		slog.Error(em)
+		return errors.New(em)
+	}
+
+	userTable.ColMap("IDField").Rename("id")
+	userTable.ColMap("UsernameField").Rename("username")
+	userTable.ColMap("PasswordHashField").Rename("password_hash")
+	userTable.ColMap("RoleField").Rename("role")
+
+	genreTable := dbmap.AddTableWithName(gorpGenreModel.GorpMysqlGenre{}, "genres").SetKeys(true, "IDField")
+	if genreTable == nil {
+		em := "cannot add table genres"
+		slog.Error(em)
+		return errors.New(em)
+	}
+
+	genreTable.ColMap("IDField").Rename("id")
+	genreTable.ColMap("NameField").Rename("name").SetMaxSize(64)
+	genreTable.ColMap("ParentIDField").Rename("parent_id")
+	// The path is filled in from the other genres.
+	genreTable.ColMap("PathField").SetTransient(true)
+
+	genreLinkTable := dbmap.AddTableWithName(gorpGenreModel.GorpMysqlLink{}, "genre_links").SetKeys(true, "IDField")
+	if genreLinkTable == nil {
+		em := "cannot add table genre_links"
+		slog.Error(em)
+		return errors.New(em)
+	}
+
+	genreLinkTable.ColMap("IDField").Rename("id")
+	genreLinkTable.ColMap("GenreIDField").Rename("genre_id")
+	genreLinkTable.ColMap("SubjectField").Rename("subject").SetMaxSize(20)
+	genreLinkTable.ColMap("SubjectIDField").Rename("subject_id")
+
+	tagTable := dbmap.AddTableWithName(gorpTagModel.GorpMysqlTag{}, "tags").SetKeys(true, "IDField")
+	if tagTable == nil {
+		em := "cannot add table tags"
+		slog.Error(em)
+		return errors.New(em)
+	}
+
+	tagTable.ColMap("IDField").Rename("id")
+	tagTable.ColMap("NameField").Rename("name").SetMaxSize(64)
+	// The count is filled in by the joins in the finders.
+	tagTable.ColMap("CountField").SetTransient(true)
+
+	tagLinkTable := dbmap.AddTableWithName(gorpTagModel.GorpMysqlLink{}, "tag_links").SetKeys(true, "IDField")
+	if tagLinkTable == nil {
+		em := "cannot add table tag_links"
+		slog.Error(em)
+		return errors.New(em)
+	}
+
+	tagLinkTable.ColMap("IDField").Rename("id")
+	tagLinkTable.ColMap("TagIDField").Rename("tag_id")
+	tagLinkTable.ColMap("SubjectField").Rename("subject").SetMaxSize(20)
+	tagLinkTable.ColMap("SubjectIDField").Rename("subject_id")
+
+	companyTable := dbmap.AddTableWithName(gorpCompanyModel.GorpMysqlCompany{}, "companies").SetKeys(true, "IDField")
+	if companyTable == nil {
+		em := "cannot add table companies"
+		slog.Error(em)
+		return errors.New(em)
+	}
+
+	companyTable.ColMap("IDField").Rename("id")
+	companyTable.ColMap("NameField").Rename("name").SetMaxSize(255)
+	companyTable.ColMap("CountryField").Rename("country").SetMaxSize(64)
+	companyTable.ColMap("FoundedField").Rename("founded")
+	companyTable.ColMap("DefunctField").Rename("defunct")
+
+	companyLinkTable := dbmap.AddTableWithName(gorpCompanyModel.GorpMysqlLink{}, "film_companies").SetKeys(true, "IDField")
+	if companyLinkTable == nil {
+		em := "cannot add table film_companies"
+		slog.Error(em)
+		return errors.New(em)
+	}
+
+	companyLinkTable.ColMap("IDField").Rename("id")
+	companyLinkTable.ColMap("FilmIDField").Rename("film_id")
+	companyLinkTable.ColMap("CompanyIDField").Rename("company_id")
+	companyLinkTable.ColMap("RelationshipField").Rename("relationship").SetMaxSize(20)
+	// The display fields are filled in by the joins in the finders.
+	companyLinkTable.ColMap("FilmTitleField").SetTransient(true)
+	companyLinkTable.ColMap("FilmReleaseYearField").SetTransient(true)
+	companyLinkTable.ColMap("CompanyNameField").SetTransient(true)
+
+	reviewTable := dbmap.AddTableWithName(gorpReviewModel.GorpMysqlReview{}, "reviews").SetKeys(true, "IDField")
+	if reviewTable == nil {
+		em := "cannot add table reviews"
+		slog.Error(em)
+		return errors.New(em)
+	}
+	reviewTable.ColMap("IDField").Rename("id")
+	reviewTable.ColMap("FilmIDField").Rename("film_id")
+	reviewTable.ColMap("UserIDField").Rename("user_id")
+	reviewTable.ColMap("RatingField").Rename("rating")
+	reviewTable.ColMap("TitleField").Rename("title").SetMaxSize(255)
+	reviewTable.ColMap("BodyField").Rename("body")
+	reviewTable.ColMap("UpdatedAtField").Rename("updated_at")
+	// Filled in by the finders from the users table.
+	reviewTable.ColMap("UsernameField").SetTransient(true)
+
+	summaryTable := dbmap.AddTableWithName(gorpReviewModel.GorpMysqlSummary{}, "film_ratings").SetKeys(true, "IDField")
+	if summaryTable == nil {
+		em := "cannot add table film_ratings"
+		slog.Error(em)
+		return errors.New(em)
+	}
+	summaryTable.ColMap("IDField").Rename("id")
+	summaryTable.ColMap("FilmIDField").Rename("film_id")
+	// Two reviews of the same film written at the same time both change the
+	// summary.  The version makes the second update fail rather than lose the
+	// first one's rating.
+	summaryTable.SetVersionCol("VersionField").Rename("version")
+	for rating := reviewModel.MinRating; rating <= reviewModel.MaxRating; rating++ {
+		summaryTable.ColMap(fmt.Sprintf("Rating%dField", rating)).Rename(fmt.Sprintf("rating_%d", rating))
+	}
+
+	watchlistTable := dbmap.AddTableWithName(gorpWatchlistModel.GorpMysqlEntry{}, "watchlist").SetKeys(true, "IDField")
+	if watchlistTable == nil {
+		em := "cannot add table watchlist"
+		slog.Error(em)
+		return errors.New(em)
+	}
+	watchlistTable.ColMap("IDField").Rename("id")
+	watchlistTable.ColMap("UserIDField").Rename("user_id")
+	watchlistTable.ColMap("FilmIDField").Rename("film_id")
+	watchlistTable.ColMap("AddedAtField").Rename("added_at")
+	// The display fields are filled in by the joins in the finders.
+	watchlistTable.ColMap("FilmTitleField").SetTransient(true)
+	watchlistTable.ColMap("FilmReleaseYearField").SetTransient(true)
+
+	diaryTable := dbmap.AddTableWithName(gorpDiaryModel.GorpMysqlEntry{}, "diary").SetKeys(true, "IDField")
+	if diaryTable == nil {
+		em := "cannot add table diary"
+		slog.Error(em)
+		return errors.New(em)
+	}
+	diaryTable.ColMap("IDField").Rename("id")
+	diaryTable.ColMap("UserIDField").Rename("user_id")
+	diaryTable.ColMap("FilmIDField").Rename("film_id")
+	diaryTable.ColMap("WatchedOnField").Rename("watched_on")
+	diaryTable.ColMap("RatingField").Rename("rating")
+	diaryTable.ColMap("RewatchField").Rename("rewatch")
+	diaryTable.ColMap("FilmTitleField").SetTransient(true)
+	diaryTable.ColMap("FilmReleaseYearField").SetTransient(true)
+
+	ceremonyTable := dbmap.AddTableWithName(gorpAwardModel.GorpMysqlCeremony{}, "award_ceremonies").SetKeys(true, "IDField")
+	if ceremonyTable == nil {
+		em := "cannot add table award_ceremonies"
+		slog.Error(em)
+		return errors.New(em)
+	}
+	ceremonyTable.ColMap("IDField").Rename("id")
+	ceremonyTable.ColMap("NameField").Rename("name").SetMaxSize(255)
+	ceremonyTable.ColMap("YearField").Rename("year")
+
+	categoryTable := dbmap.AddTableWithName(gorpAwardModel.GorpMysqlCategory{}, "award_categories").SetKeys(true, "IDField")
+	if categoryTable == nil {
+		em := "cannot add table award_categories"
+		slog.Error(em)
+		return errors.New(em)
+	}
+	categoryTable.ColMap("IDField").Rename("id")
+	categoryTable.ColMap("CeremonyIDField").Rename("ceremony_id")
+	categoryTable.ColMap("NameField").Rename("name").SetMaxSize(255)
+
+	nominationTable := dbmap.AddTableWithName(gorpAwardModel.GorpMysqlNomination{}, "nominations").SetKeys(true, "IDField")
+	if nominationTable == nil {
+		em := "cannot add table nominations"
+		slog.Error(em)
+		return errors.New(em)
+	}
+	nominationTable.ColMap("IDField").Rename("id")
+	nominationTable.ColMap("CategoryIDField").Rename("category_id")
+	nominationTable.ColMap("FilmIDField").Rename("film_id")
+	nominationTable.ColMap("PersonIDField").Rename("person_id")
+	nominationTable.ColMap("WonField").Rename("won")
+	// The display fields are filled in by the joins in the finders.
+	for _, field := range []string{"CategoryNameField", "CeremonyIDField",
+		"CeremonyNameField", "CeremonyYearField", "FilmTitleField",
+		"FilmReleaseYearField", "PersonForenameField", "PersonSurnameField"} {
+
+		nominationTable.ColMap(field).SetTransient(true)
+	}
+
+	// Refuse to work with a schema that's behind the mapping.
+	migrator, err := migrations.MakeMigrator(dbmap.Db, dialect)
+	if err != nil {
+		return err
+	}
+	err = migrator.Check()
+	if err != nil {
+		slog.Error(err.Error())
+		return err
+	}
+	return nil
 }
 
 // StartTransaction starts a transaction.
-func (dbs GorpMysqlDBSession) StartTransaction() (*gorp.Transaction, error) {
-	return dbs.dbmap.Begin()
+func (dbs GorpMysqlDBSession) StartTransaction() (Transaction, error) {
+	tx, err := dbs.dbmap.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return gorpTransaction{tx}, nil
+}
+
+// gorpTransaction is a transaction on a GORP session.  The *gorp.Transaction
+// supplies most of the methods and the rest are finders that run within the
+// transaction.
+type gorpTransaction struct {
+	*gorp.Transaction
+}
+
+// FindAllCreditsByFilm returns all of the credits on the film with the given ID,
+// including those of the people in the trash, in order of ID.  The display fields
+// are not filled in.
+func (tx gorpTransaction) FindAllCreditsByFilm(filmID uint64) ([]creditModel.Credit, error) {
+	return selectCredits(tx.Transaction,
+		"select id, person_id, film_id, role, character_name, billing from credits "+
+			"where film_id = ? order by id",
+		filmID)
+}
+
+// FindAllNominationsByFilm returns all of the award nominations of the film with
+// the given ID, including those of the people in the trash, in order of ID.  The
+// display fields are not filled in.
+func (tx gorpTransaction) FindAllNominationsByFilm(filmID uint64) ([]awardModel.Nomination, error) {
+	return selectNominations(tx.Transaction,
+		"select id, category_id, film_id, person_id, won from nominations "+
+			"where film_id = ? order by id",
+		filmID)
+}
+
+// FindAllCreditsByPerson returns all of the credits of the person with the given
+// ID, in order of ID.  The display fields are not filled in.
+func (tx gorpTransaction) FindAllCreditsByPerson(personID uint64) ([]creditModel.Credit, error) {
+	return selectCredits(tx.Transaction,
+		"select id, person_id, film_id, role, character_name, billing from credits "+
+			"where person_id = ? order by id",
+		personID)
+}
+
+// FindAllNominationsByPerson returns all of the award nominations of the person
+// with the given ID, in order of ID.  The display fields are not filled in.
+func (tx gorpTransaction) FindAllNominationsByPerson(personID uint64) ([]awardModel.Nomination, error) {
+	return selectNominations(tx.Transaction,
+		"select id, category_id, film_id, person_id, won from nominations "+
+			"where person_id = ? order by id",
+		personID)
+}
+
+// FindGenreLinks returns the links that attach genres to the given subject in a
+// (possibly empty) slice, in order of ID.
+func (tx gorpTransaction) FindGenreLinks(subject string, subjectID uint64) ([]genreModel.Link, error) {
+	return selectGenreLinks(tx.Transaction, subject, subjectID)
+}
+
+// FindTagLinks returns the links that attach tags to the given subject in a
+// (possibly empty) slice, in order of ID.
+func (tx gorpTransaction) FindTagLinks(subject string, subjectID uint64) ([]tagModel.Link, error) {
+	return selectTagLinks(tx.Transaction, subject, subjectID)
+}
+
+// FindAllCompanyLinksByFilm returns all of the links between the film with the
+// given ID and its companies, in order of ID.  The display fields are not filled
+// in.
+func (tx gorpTransaction) FindAllCompanyLinksByFilm(filmID uint64) ([]companyModel.Link, error) {
+	return selectCompanyLinks(tx.Transaction,
+		"select id, film_id, company_id, relationship from film_companies "+
+			"where film_id = ? order by id",
+		filmID)
+}
+
+// FindAllReviewsByFilm returns all of the reviews of the film with the given ID,
+// in order of ID.  The display fields are not filled in.
+func (tx gorpTransaction) FindAllReviewsByFilm(filmID uint64) ([]reviewModel.Review, error) {
+	return selectReviews(tx.Transaction,
+		"select id, film_id, user_id, rating, title, body, updated_at from reviews "+
+			"where film_id = ? order by id",
+		filmID)
+}
+
+// FindRatingSummary fetches the row from the film_ratings table for the film with
+// the given ID.
+func (tx gorpTransaction) FindRatingSummary(filmID uint64) (reviewModel.Summary, error) {
+	var gorpMysqlSummary gorpReviewModel.GorpMysqlSummary
+	err := tx.SelectOne(&gorpMysqlSummary, summarySelect+" where film_id = ?", filmID)
+	if err != nil {
+		return nil, err
+	}
+	return &gorpMysqlSummary, nil
+}
+
+// FindAllWatchlistByFilm returns all of the entries for the film with the given
+// ID on the watchlists, in order of ID.  The display fields are not filled in.
+func (tx gorpTransaction) FindAllWatchlistByFilm(filmID uint64) ([]watchlistModel.Entry, error) {
+	return selectWatchlist(tx.Transaction,
+		"select id, user_id, film_id, added_at from watchlist where film_id = ? order by id",
+		filmID)
+}
+
+// FindAllDiaryByFilm returns all of the entries for the film with the given ID
+// in the diaries, in order of ID.  The display fields are not filled in.
+func (tx gorpTransaction) FindAllDiaryByFilm(filmID uint64) ([]diaryModel.Entry, error) {
+	return selectDiary(tx.Transaction,
+		"select id, user_id, film_id, watched_on, rating, rewatch from diary "+
+			"where film_id = ? order by id",
+		filmID)
+}
+
+// isMysqlDuplicateKey returns true if the error is the one that MySQL returns
+// when an insert breaks a unique index.
+func isMysqlDuplicateKey(err error) bool {
+	var mysqlError *mysql.MySQLError
+	return errors.As(err, &mysqlError) && mysqlError.Number == mysqlDuplicateKey
 }
 
 // Close closes the GORP DBMap and releases the database connection.
@@ -73,6 +502,13 @@ func (dbs GorpMysqlDBSession) Close() {
 	dbs.dbmap.Db.Close()
 }
 
+// WithContext returns a copy of the session that logs against the given context.
+// The copy shares the DBMap, and so the connection pool, with the original.
+func (dbs GorpMysqlDBSession) WithContext(ctx context.Context) DBSession {
+	dbs.ctx = ctx
+	return &dbs
+}
+
 // FindAllPeople returns a slice of all valid Person records from the database in a
 // (possibly empty) slice.  If the database lookup fails, the error is returned
 // instead.
@@ -84,7 +520,7 @@ func (dbs GorpMysqlDBSession) FindAllPeople() ([]personModel.Person, error) {
 	 * the error.
 	 */
 	var GorpMysqlPersons []gorpModel.GorpMysqlPerson
-	_, err := dbs.dbmap.Select(&GorpMysqlPersons, "select id, surname, forename from people")
+	_, err := dbs.dbmap.Select(&GorpMysqlPersons, personSelect+" where deleted_at = 0")
 	if err != nil {
 		return nil, err
 	}
@@ -109,18 +545,872 @@ func (dbs GorpMysqlDBSession) FindAllPeople() ([]personModel.Person, error) {
 	return validPeople, nil
 }
 
+// peopleSortColumns maps the fields that the people can be sorted on to the
+// expressions in the order by clause.  The names are sorted ignoring case.
+var peopleSortColumns = map[string]string{
+	SortBySurname:  "lower(surname)",
+	SortByForename: "lower(forename)",
+	SortByID:       "id",
+}
+
+// FindPeople returns one page of the valid people, filtered and sorted as the
+// query says, and the number of valid people that match the filter.  The work is
+// done by the database, so only one page of people is fetched.  The name is
+// matched ignoring case.  The SQL works with MySQL and with SQLite.
+func (dbs GorpMysqlDBSession) FindPeople(query PeopleQuery) ([]personModel.Person, int64, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindPeople()"
+	column, ok := peopleSortColumns[query.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("cannot sort people by %s", query.Sort)
+	}
+	direction := "asc"
+	if query.Descending {
+		direction = "desc"
+	}
+
+	where := "where deleted_at = 0 and trim(forename) <> '' and trim(surname) <> ''"
+	args := make([]interface{}, 0, 4)
+	if query.Name != "" {
+		// The name is a literal string, so escape the wildcards.
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(query.Name)) + "%"
+		where += " and (lower(forename) like ? escape '!' or lower(surname) like ? escape '!')"
+		args = append(args, pattern, pattern)
+	}
+	filter, filterArgs, err := dbs.taxonomyFilter(SubjectPerson, query.GenreID, query.TagID)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, 0, err
+	}
+	where += filter
+	args = append(args, filterArgs...)
+
+	total, err := dbs.dbmap.SelectInt("select count(*) from people "+where, args...)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, 0, err
+	}
+
+	statement := fmt.Sprintf("%s %s order by %s %s, id %s",
+		personSelect, where, column, direction, direction)
+	if query.Limit > 0 {
+		statement += " limit ? offset ?"
+		args = append(args, query.Limit, query.Offset)
+	} else if query.Offset > 0 {
+		// Both databases need a limit with an offset.
+		statement += " limit ? offset ?"
+		args = append(args, int64(math.MaxInt64), query.Offset)
+	}
+
+	var gorpMysqlPersons []gorpModel.GorpMysqlPerson
+	_, err = dbs.dbmap.Select(&gorpMysqlPersons, statement, args...)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, 0, err
+	}
+
+	people := make([]personModel.Person, 0, len(gorpMysqlPersons))
+	for i := range gorpMysqlPersons {
+		p := &gorpMysqlPersons[i]
+		p.SetForename(strings.TrimSpace(p.Forename()))
+		p.SetSurname(strings.TrimSpace(p.Surname()))
+		people = append(people, p)
+	}
+	return people, total, nil
+}
+
+// personSelect is the start of the query used by the person finders.
+const personSelect = "select id, surname, forename, version, deleted_at from people"
+
+// likeEscaper escapes the characters that are special in a like pattern, using
+// "!" as the escape character.  Both MySQL and SQLite accept that, whereas a
+// backslash has to be written differently for each of them.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
 // FindPersonByID fetches the row from the people table with the given uint64 id. The
 // data fetched may or may not be valid.  The method returns a Person containing
 // that data, or an error message.
 func (dbs GorpMysqlDBSession) FindPersonByID(id uint64) (personModel.Person, error) {
+	logger := logging.FromContext(dbs.ctx)
 	m := "FindPersonByID()"
-	log.Printf("%s: ID %d", m, id)
+	logger.Debug(m, "id", id)
 	var GorpMysqlPerson gorpModel.GorpMysqlPerson
-	err := dbs.dbmap.SelectOne(&GorpMysqlPerson, "select id, surname, forename from people where id = ?", id)
+	err := dbs.dbmap.SelectOne(&GorpMysqlPerson, personSelect+" where id = ? and deleted_at = 0", id)
 	if err != nil {
-		log.Printf("%s: %s", m, err.Error())
+		logger.Error(m, "error", err)
 		return nil, err
 	}
-	log.Printf("%s: found person %s", m, GorpMysqlPerson.String())
+	logger.Debug(m, "person", GorpMysqlPerson.String())
 	return &GorpMysqlPerson, nil
 }
+
+// FindDeletedPeople returns the people in the trash in a (possibly empty) slice,
+// most recently deleted first.  They are not validated - an invalid person can be
+// deleted too.
+func (dbs GorpMysqlDBSession) FindDeletedPeople() ([]personModel.Person, error) {
+	var gorpMysqlPersons []gorpModel.GorpMysqlPerson
+	_, err := dbs.dbmap.Select(&gorpMysqlPersons,
+		personSelect+" where deleted_at <> 0 order by deleted_at desc, id desc")
+	if err != nil {
+		return nil, err
+	}
+	people := make([]personModel.Person, 0, len(gorpMysqlPersons))
+	for i := range gorpMysqlPersons {
+		people = append(people, &gorpMysqlPersons[i])
+	}
+	return people, nil
+}
+
+// FindDeletedPersonByID fetches the person in the trash with the given uint64 id.
+// If there is no such person, or they are not in the trash, it returns
+// sql.ErrNoRows.
+func (dbs GorpMysqlDBSession) FindDeletedPersonByID(id uint64) (personModel.Person, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindDeletedPersonByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlPerson gorpModel.GorpMysqlPerson
+	err := dbs.dbmap.SelectOne(&gorpMysqlPerson, personSelect+" where id = ? and deleted_at <> 0", id)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlPerson, nil
+}
+
+// FindAllFilms returns a slice of all valid Film records from the database in a
+// (possibly empty) slice.  A film is valid if it has a title.  If the database
+// lookup fails, the error is returned instead.
+func (dbs GorpMysqlDBSession) FindAllFilms() ([]filmModel.Film, error) {
+	return dbs.FindFilms(FilmQuery{})
+}
+
+// FindFilms returns the valid films that match the query in a (possibly empty)
+// slice, in order of ID.
+func (dbs GorpMysqlDBSession) FindFilms(query FilmQuery) ([]filmModel.Film, error) {
+	filter, args, err := dbs.taxonomyFilter(SubjectFilm, query.GenreID, query.TagID)
+	if err != nil {
+		return nil, err
+	}
+	var gorpMysqlFilms []gorpFilmModel.GorpMysqlFilm
+	_, err = dbs.dbmap.Select(&gorpMysqlFilms,
+		"select id, title, release_year, runtime, synopsis from films where 1 = 1"+
+			filter+" order by id", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	validFilms := make([]filmModel.Film, 0, len(gorpMysqlFilms))
+
+	// Validate and copy the Film records.  As with people, each record must be
+	// cloned rather than taking the address of the loop variable.
+	for _, f := range gorpMysqlFilms {
+		f.SetTitle(strings.TrimSpace(f.Title()))
+		if len(f.Title()) > 0 {
+			validFilms = append(validFilms, gorpFilmModel.Clone(&f))
+		}
+	}
+
+	return validFilms, nil
+}
+
+// FindFilmByID fetches the row from the films table with the given uint64 id. The
+// data fetched may or may not be valid.  The method returns a Film containing
+// that data, or an error message.
+func (dbs GorpMysqlDBSession) FindFilmByID(id uint64) (filmModel.Film, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindFilmByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlFilm gorpFilmModel.GorpMysqlFilm
+	err := dbs.dbmap.SelectOne(&gorpMysqlFilm,
+		"select id, title, release_year, runtime, synopsis from films where id = ?", id)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	logger.Debug(m, "film", gorpMysqlFilm.String())
+	return &gorpMysqlFilm, nil
+}
+
+// creditSelect is the start of the query used by the credit finders.  It joins the
+// credits table with the films and people tables to fetch the display fields.
+const creditSelect = "select c.id, c.person_id, c.film_id, c.role, c.character_name, c.billing, " +
+	"f.title as FilmTitleField, f.release_year as FilmReleaseYearField, " +
+	"p.forename as PersonForenameField, p.surname as PersonSurnameField " +
+	"from credits c join films f on f.id = c.film_id join people p on p.id = c.person_id"
+
+// FindCreditByID fetches the row from the credits table with the given uint64 id,
+// along with the film title and the person's name.
+func (dbs GorpMysqlDBSession) FindCreditByID(id uint64) (creditModel.Credit, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindCreditByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlCredit gorpCreditModel.GorpMysqlCredit
+	err := dbs.dbmap.SelectOne(&gorpMysqlCredit, creditSelect+" where c.id = ?", id)
+	if err != nil {
+		logger.Error(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlCredit, nil
+}
+
+// FindCreditsByPerson returns the credits of the person with the given ID in a
+// (possibly empty) slice, in order of release year.
+func (dbs GorpMysqlDBSession) FindCreditsByPerson(personID uint64) ([]creditModel.Credit, error) {
+	return dbs.findCredits(creditSelect+
+		" where c.person_id = ? order by f.release_year, f.title, c.role", personID)
+}
+
+// FindCreditsByFilm returns the credits on the film with the given ID in a
+// (possibly empty) slice, in order of role and billing.  The credits of people in
+// the trash are left out.
+func (dbs GorpMysqlDBSession) FindCreditsByFilm(filmID uint64) ([]creditModel.Credit, error) {
+	return dbs.findCredits(creditSelect+
+		" where c.film_id = ? and p.deleted_at = 0 order by c.role, c.billing, p.surname, p.forename",
+		filmID)
+}
+
+// FindAuditEntries returns the audit log entries for the record with the given ID
+// in the given table in a (possibly empty) slice, newest first.
+func (dbs GorpMysqlDBSession) FindAuditEntries(tableName string, recordID uint64) ([]auditModel.Entry, error) {
+	var gorpMysqlEntries []gorpAuditModel.GorpMysqlEntry
+	_, err := dbs.dbmap.Select(&gorpMysqlEntries,
+		"select id, table_name, record_id, action, version, changed_by, changed_at, "+
+			"before_values, after_values from audit_log "+
+			"where table_name = ? and record_id = ? order by id desc",
+		tableName, recordID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]auditModel.Entry, 0, len(gorpMysqlEntries))
+	for i := range gorpMysqlEntries {
+		entries = append(entries, gorpAuditModel.Clone(&gorpMysqlEntries[i]))
+	}
+	return entries, nil
+}
+
+// FindUserByUsername fetches the row from the users table with the given
+// username.  If there is no such user, it returns sql.ErrNoRows.
+func (dbs GorpMysqlDBSession) FindUserByUsername(username string) (userModel.User, error) {
+	var gorpMysqlUser gorpUserModel.GorpMysqlUser
+	err := dbs.dbmap.SelectOne(&gorpMysqlUser,
+		"select id, username, password_hash, role from users where username = ?", username)
+	if err != nil {
+		return nil, err
+	}
+	return &gorpMysqlUser, nil
+}
+
+// FindAllGenres returns all of the genres in a (possibly empty) slice, in order of
+// ID.
+func (dbs GorpMysqlDBSession) FindAllGenres() ([]genreModel.Genre, error) {
+	var gorpMysqlGenres []gorpGenreModel.GorpMysqlGenre
+	_, err := dbs.dbmap.Select(&gorpMysqlGenres,
+		"select id, name, parent_id from genres order by id")
+	if err != nil {
+		return nil, err
+	}
+	genres := make([]genreModel.Genre, 0, len(gorpMysqlGenres))
+	for i := range gorpMysqlGenres {
+		genres = append(genres, &gorpMysqlGenres[i])
+	}
+	return genres, nil
+}
+
+// FindGenreLinks returns the links that attach genres to the given subject in a
+// (possibly empty) slice, in order of ID.
+func (dbs GorpMysqlDBSession) FindGenreLinks(subject string, subjectID uint64) ([]genreModel.Link, error) {
+	return selectGenreLinks(dbs.dbmap, subject, subjectID)
+}
+
+// selectGenreLinks fetches the links that attach genres to the given subject using
+// the given executor - the DBMap or a transaction - and returns the result in a
+// slice.
+func selectGenreLinks(executor gorp.SqlExecutor, subject string, subjectID uint64) ([]genreModel.Link, error) {
+	var gorpMysqlLinks []gorpGenreModel.GorpMysqlLink
+	_, err := executor.Select(&gorpMysqlLinks,
+		"select id, genre_id, subject, subject_id from genre_links "+
+			"where subject = ? and subject_id = ? order by id",
+		subject, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]genreModel.Link, 0, len(gorpMysqlLinks))
+	for i := range gorpMysqlLinks {
+		links = append(links, &gorpMysqlLinks[i])
+	}
+	return links, nil
+}
+
+// tagSelect is the start of the query used by the tag finders.  It counts the
+// links to each tag.
+const tagSelect = "select t.id, t.name, count(l.id) as CountField " +
+	"from tags t left join tag_links l on l.tag_id = t.id"
+
+// FindAllTags returns the tags that are attached to something in a (possibly
+// empty) slice, in order of name, with the number of things that each is attached
+// to.
+func (dbs GorpMysqlDBSession) FindAllTags() ([]tagModel.Tag, error) {
+	var gorpMysqlTags []gorpTagModel.GorpMysqlTag
+	_, err := dbs.dbmap.Select(&gorpMysqlTags,
+		tagSelect+" group by t.id, t.name having count(l.id) > 0 order by t.name")
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]tagModel.Tag, 0, len(gorpMysqlTags))
+	for i := range gorpMysqlTags {
+		tags = append(tags, &gorpMysqlTags[i])
+	}
+	return tags, nil
+}
+
+// FindTagByID fetches the tag with the given ID, with the number of things that
+// it's attached to.  If there is no such tag, it returns sql.ErrNoRows.
+func (dbs GorpMysqlDBSession) FindTagByID(id uint64) (tagModel.Tag, error) {
+	return dbs.findTag(tagSelect+" where t.id = ? group by t.id, t.name", id)
+}
+
+// FindTagByName fetches the tag with the given name, with the number of things
+// that it's attached to.  If there is no such tag, it returns sql.ErrNoRows.
+func (dbs GorpMysqlDBSession) FindTagByName(name string) (tagModel.Tag, error) {
+	return dbs.findTag(tagSelect+" where t.name = ? group by t.id, t.name", name)
+}
+
+// FindTagLinks returns the links that attach tags to the given subject in a
+// (possibly empty) slice, in order of ID.
+func (dbs GorpMysqlDBSession) FindTagLinks(subject string, subjectID uint64) ([]tagModel.Link, error) {
+	return selectTagLinks(dbs.dbmap, subject, subjectID)
+}
+
+// selectTagLinks fetches the links that attach tags to the given subject using the
+// given executor - the DBMap or a transaction - and returns the result in a slice.
+func selectTagLinks(executor gorp.SqlExecutor, subject string, subjectID uint64) ([]tagModel.Link, error) {
+	var gorpMysqlLinks []gorpTagModel.GorpMysqlLink
+	_, err := executor.Select(&gorpMysqlLinks,
+		"select id, tag_id, subject, subject_id from tag_links "+
+			"where subject = ? and subject_id = ? order by id",
+		subject, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]tagModel.Link, 0, len(gorpMysqlLinks))
+	for i := range gorpMysqlLinks {
+		links = append(links, &gorpMysqlLinks[i])
+	}
+	return links, nil
+}
+
+// findTag runs the given query, which fetches one tag, and returns the result.
+func (dbs GorpMysqlDBSession) findTag(query string, args ...interface{}) (tagModel.Tag, error) {
+	logger := logging.FromContext(dbs.ctx)
+	var gorpMysqlTag gorpTagModel.GorpMysqlTag
+	err := dbs.dbmap.SelectOne(&gorpMysqlTag, query, args...)
+	if err != nil {
+		logger.Debug("findTag()", "args", args, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlTag, nil
+}
+
+// FindAllCompanies returns all of the companies in a (possibly empty) slice, in
+// order of name.
+func (dbs GorpMysqlDBSession) FindAllCompanies() ([]companyModel.Company, error) {
+	var gorpMysqlCompanies []gorpCompanyModel.GorpMysqlCompany
+	_, err := dbs.dbmap.Select(&gorpMysqlCompanies,
+		"select id, name, country, founded, defunct from companies order by name, id")
+	if err != nil {
+		return nil, err
+	}
+	companies := make([]companyModel.Company, 0, len(gorpMysqlCompanies))
+	for i := range gorpMysqlCompanies {
+		companies = append(companies, &gorpMysqlCompanies[i])
+	}
+	return companies, nil
+}
+
+// FindCompanyByID fetches the row from the companies table with the given uint64
+// id.  If there is no such company, it returns sql.ErrNoRows.
+func (dbs GorpMysqlDBSession) FindCompanyByID(id uint64) (companyModel.Company, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindCompanyByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlCompany gorpCompanyModel.GorpMysqlCompany
+	err := dbs.dbmap.SelectOne(&gorpMysqlCompany,
+		"select id, name, country, founded, defunct from companies where id = ?", id)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlCompany, nil
+}
+
+// companyLinkSelect is the start of the query used by the finders of the links
+// between companies and films.  It joins the film_companies table with the films
+// and companies tables to fetch the display fields.
+const companyLinkSelect = "select l.id, l.film_id, l.company_id, l.relationship, " +
+	"f.title as FilmTitleField, f.release_year as FilmReleaseYearField, " +
+	"c.name as CompanyNameField " +
+	"from film_companies l join films f on f.id = l.film_id " +
+	"join companies c on c.id = l.company_id"
+
+// FindCompanyLinkByID fetches the row from the film_companies table with the given
+// uint64 id, along with the film title and the company name.
+func (dbs GorpMysqlDBSession) FindCompanyLinkByID(id uint64) (companyModel.Link, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindCompanyLinkByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlLink gorpCompanyModel.GorpMysqlLink
+	err := dbs.dbmap.SelectOne(&gorpMysqlLink, companyLinkSelect+" where l.id = ?", id)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlLink, nil
+}
+
+// FindCompanyLinksByFilm returns the links between the film with the given ID and
+// its companies in a (possibly empty) slice, in order of relationship and company
+// name.
+func (dbs GorpMysqlDBSession) FindCompanyLinksByFilm(filmID uint64) ([]companyModel.Link, error) {
+	return dbs.findCompanyLinks(companyLinkSelect+
+		" where l.film_id = ? order by l.relationship, c.name, l.id", filmID)
+}
+
+// FindCompanyLinksByCompany returns the links between the company with the given
+// ID and its films in a (possibly empty) slice, in order of release year.
+func (dbs GorpMysqlDBSession) FindCompanyLinksByCompany(companyID uint64) ([]companyModel.Link, error) {
+	return dbs.findCompanyLinks(companyLinkSelect+
+		" where l.company_id = ? order by f.release_year, f.title, l.relationship", companyID)
+}
+
+// findCompanyLinks runs the given query, which fetches links between companies and
+// films, and returns the result in a slice.
+func (dbs GorpMysqlDBSession) findCompanyLinks(query string, args ...interface{}) ([]companyModel.Link, error) {
+	return selectCompanyLinks(dbs.dbmap, query, args...)
+}
+
+// selectCompanyLinks runs the given query, which fetches links between companies
+// and films, using the given executor - the DBMap or a transaction - and returns
+// the result in a slice.
+func selectCompanyLinks(executor gorp.SqlExecutor, query string, args ...interface{}) ([]companyModel.Link, error) {
+	var gorpMysqlLinks []gorpCompanyModel.GorpMysqlLink
+	_, err := executor.Select(&gorpMysqlLinks, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]companyModel.Link, 0, len(gorpMysqlLinks))
+	for i := range gorpMysqlLinks {
+		links = append(links, &gorpMysqlLinks[i])
+	}
+	return links, nil
+}
+
+// reviewSelect is the start of the query used by the finders of reviews.  It
+// joins the reviews table with the users table to fetch the username.
+const reviewSelect = "select r.id, r.film_id, r.user_id, r.rating, r.title, r.body, " +
+	"r.updated_at, u.username as UsernameField " +
+	"from reviews r join users u on u.id = r.user_id"
+
+// FindReviewByID fetches the row from the reviews table with the given uint64 id,
+// along with the username of the reviewer.
+func (dbs GorpMysqlDBSession) FindReviewByID(id uint64) (reviewModel.Review, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindReviewByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlReview gorpReviewModel.GorpMysqlReview
+	err := dbs.dbmap.SelectOne(&gorpMysqlReview, reviewSelect+" where r.id = ?", id)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlReview, nil
+}
+
+// FindReviewByFilmAndUser fetches the review of the film with the given ID by the
+// user with the given ID, along with their username.
+func (dbs GorpMysqlDBSession) FindReviewByFilmAndUser(filmID uint64, userID uint64) (reviewModel.Review, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindReviewByFilmAndUser()"
+	logger.Debug(m, "filmID", filmID, "userID", userID)
+	var gorpMysqlReview gorpReviewModel.GorpMysqlReview
+	err := dbs.dbmap.SelectOne(&gorpMysqlReview,
+		reviewSelect+" where r.film_id = ? and r.user_id = ?", filmID, userID)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlReview, nil
+}
+
+// FindReviewsByFilm returns the reviews of the film with the given ID in a
+// (possibly empty) slice, most recent first.
+func (dbs GorpMysqlDBSession) FindReviewsByFilm(filmID uint64) ([]reviewModel.Review, error) {
+	return selectReviews(dbs.dbmap,
+		reviewSelect+" where r.film_id = ? order by r.updated_at desc, r.id desc", filmID)
+}
+
+// selectReviews runs the given query, which fetches reviews, using the given
+// executor - the DBMap or a transaction - and returns the result in a slice.
+func selectReviews(executor gorp.SqlExecutor, query string, args ...interface{}) ([]reviewModel.Review, error) {
+	var gorpMysqlReviews []gorpReviewModel.GorpMysqlReview
+	_, err := executor.Select(&gorpMysqlReviews, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	reviews := make([]reviewModel.Review, 0, len(gorpMysqlReviews))
+	for i := range gorpMysqlReviews {
+		reviews = append(reviews, &gorpMysqlReviews[i])
+	}
+	return reviews, nil
+}
+
+// summarySelect is the start of the query used by the finders of rating
+// summaries.
+const summarySelect = "select id, film_id, version, rating_1, rating_2, rating_3, " +
+	"rating_4, rating_5, rating_6, rating_7, rating_8, rating_9, rating_10 " +
+	"from film_ratings"
+
+// FindRatingSummary fetches the row from the film_ratings table for the film with
+// the given ID.
+func (dbs GorpMysqlDBSession) FindRatingSummary(filmID uint64) (reviewModel.Summary, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindRatingSummary()"
+	logger.Debug(m, "filmID", filmID)
+	var gorpMysqlSummary gorpReviewModel.GorpMysqlSummary
+	err := dbs.dbmap.SelectOne(&gorpMysqlSummary, summarySelect+" where film_id = ?", filmID)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlSummary, nil
+}
+
+// watchlistSelect is the start of the query used by the finders of watchlist
+// entries.  It joins the watchlist table with the films table to fetch the
+// display fields.
+const watchlistSelect = "select w.id, w.user_id, w.film_id, w.added_at, " +
+	"f.title as FilmTitleField, f.release_year as FilmReleaseYearField " +
+	"from watchlist w join films f on f.id = w.film_id"
+
+// FindWatchlistEntryByID fetches the row from the watchlist table with the given
+// uint64 id, along with the title of the film.
+func (dbs GorpMysqlDBSession) FindWatchlistEntryByID(id uint64) (watchlistModel.Entry, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindWatchlistEntryByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlEntry gorpWatchlistModel.GorpMysqlEntry
+	err := dbs.dbmap.SelectOne(&gorpMysqlEntry, watchlistSelect+" where w.id = ?", id)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlEntry, nil
+}
+
+// FindWatchlistEntryByUserAndFilm fetches the entry for the film with the given
+// ID on the watchlist of the user with the given ID.
+func (dbs GorpMysqlDBSession) FindWatchlistEntryByUserAndFilm(userID uint64, filmID uint64) (watchlistModel.Entry, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindWatchlistEntryByUserAndFilm()"
+	logger.Debug(m, "userID", userID, "filmID", filmID)
+	var gorpMysqlEntry gorpWatchlistModel.GorpMysqlEntry
+	err := dbs.dbmap.SelectOne(&gorpMysqlEntry,
+		watchlistSelect+" where w.user_id = ? and w.film_id = ?", userID, filmID)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlEntry, nil
+}
+
+// FindWatchlistByUser returns the watchlist of the user with the given ID in a
+// (possibly empty) slice, most recently added first.
+func (dbs GorpMysqlDBSession) FindWatchlistByUser(userID uint64) ([]watchlistModel.Entry, error) {
+	return dbs.findWatchlist(watchlistSelect+
+		" where w.user_id = ? order by w.added_at desc, w.id desc", userID)
+}
+
+// FindWatchlistByFilm returns the entries for the film with the given ID on all
+// of the watchlists in a (possibly empty) slice.
+func (dbs GorpMysqlDBSession) FindWatchlistByFilm(filmID uint64) ([]watchlistModel.Entry, error) {
+	return dbs.findWatchlist(watchlistSelect+" where w.film_id = ? order by w.id", filmID)
+}
+
+// findWatchlist runs the given query, which fetches watchlist entries, and
+// returns the result in a slice.
+func (dbs GorpMysqlDBSession) findWatchlist(query string, args ...interface{}) ([]watchlistModel.Entry, error) {
+	return selectWatchlist(dbs.dbmap, query, args...)
+}
+
+// selectWatchlist runs the given query, which fetches watchlist entries, using the
+// given executor - the DBMap or a transaction - and returns the result in a slice.
+func selectWatchlist(executor gorp.SqlExecutor, query string, args ...interface{}) ([]watchlistModel.Entry, error) {
+	var gorpMysqlEntries []gorpWatchlistModel.GorpMysqlEntry
+	_, err := executor.Select(&gorpMysqlEntries, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]watchlistModel.Entry, 0, len(gorpMysqlEntries))
+	for i := range gorpMysqlEntries {
+		entries = append(entries, &gorpMysqlEntries[i])
+	}
+	return entries, nil
+}
+
+// diarySelect is the start of the query used by the finders of diary entries.  It
+// joins the diary table with the films table to fetch the display fields.
+const diarySelect = "select d.id, d.user_id, d.film_id, d.watched_on, d.rating, d.rewatch, " +
+	"f.title as FilmTitleField, f.release_year as FilmReleaseYearField " +
+	"from diary d join films f on f.id = d.film_id"
+
+// FindDiaryEntryByID fetches the row from the diary table with the given uint64
+// id, along with the title of the film.
+func (dbs GorpMysqlDBSession) FindDiaryEntryByID(id uint64) (diaryModel.Entry, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindDiaryEntryByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlEntry gorpDiaryModel.GorpMysqlEntry
+	err := dbs.dbmap.SelectOne(&gorpMysqlEntry, diarySelect+" where d.id = ?", id)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlEntry, nil
+}
+
+// FindDiaryByUser returns the diary of the user with the given ID in a (possibly
+// empty) slice, most recent viewing first.
+func (dbs GorpMysqlDBSession) FindDiaryByUser(userID uint64) ([]diaryModel.Entry, error) {
+	return dbs.findDiary(diarySelect+
+		" where d.user_id = ? order by d.watched_on desc, d.id desc", userID)
+}
+
+// FindDiaryByFilm returns the entries for the film with the given ID in all of
+// the diaries in a (possibly empty) slice.
+func (dbs GorpMysqlDBSession) FindDiaryByFilm(filmID uint64) ([]diaryModel.Entry, error) {
+	return dbs.findDiary(diarySelect+" where d.film_id = ? order by d.id", filmID)
+}
+
+// findDiary runs the given query, which fetches diary entries, and returns the
+// result in a slice.
+func (dbs GorpMysqlDBSession) findDiary(query string, args ...interface{}) ([]diaryModel.Entry, error) {
+	return selectDiary(dbs.dbmap, query, args...)
+}
+
+// selectDiary runs the given query, which fetches diary entries, using the given
+// executor - the DBMap or a transaction - and returns the result in a slice.
+func selectDiary(executor gorp.SqlExecutor, query string, args ...interface{}) ([]diaryModel.Entry, error) {
+	var gorpMysqlEntries []gorpDiaryModel.GorpMysqlEntry
+	_, err := executor.Select(&gorpMysqlEntries, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]diaryModel.Entry, 0, len(gorpMysqlEntries))
+	for i := range gorpMysqlEntries {
+		entries = append(entries, &gorpMysqlEntries[i])
+	}
+	return entries, nil
+}
+
+// FindAllCeremonies returns all of the award ceremonies in a (possibly empty)
+// slice, most recent year first and then in order of name.
+func (dbs GorpMysqlDBSession) FindAllCeremonies() ([]awardModel.Ceremony, error) {
+	var gorpMysqlCeremonies []gorpAwardModel.GorpMysqlCeremony
+	_, err := dbs.dbmap.Select(&gorpMysqlCeremonies,
+		"select id, name, year from award_ceremonies order by year desc, name, id")
+	if err != nil {
+		return nil, err
+	}
+	ceremonies := make([]awardModel.Ceremony, 0, len(gorpMysqlCeremonies))
+	for i := range gorpMysqlCeremonies {
+		ceremonies = append(ceremonies, &gorpMysqlCeremonies[i])
+	}
+	return ceremonies, nil
+}
+
+// FindCeremonyByID fetches the row from the award_ceremonies table with the given
+// uint64 id.  If there is no such ceremony, it returns sql.ErrNoRows.
+func (dbs GorpMysqlDBSession) FindCeremonyByID(id uint64) (awardModel.Ceremony, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindCeremonyByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlCeremony gorpAwardModel.GorpMysqlCeremony
+	err := dbs.dbmap.SelectOne(&gorpMysqlCeremony,
+		"select id, name, year from award_ceremonies where id = ?", id)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlCeremony, nil
+}
+
+// FindCategoryByID fetches the row from the award_categories table with the given
+// uint64 id.  If there is no such category, it returns sql.ErrNoRows.
+func (dbs GorpMysqlDBSession) FindCategoryByID(id uint64) (awardModel.Category, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindCategoryByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlCategory gorpAwardModel.GorpMysqlCategory
+	err := dbs.dbmap.SelectOne(&gorpMysqlCategory,
+		"select id, ceremony_id, name from award_categories where id = ?", id)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlCategory, nil
+}
+
+// FindCategoriesByCeremony returns the categories of the award ceremony with the
+// given ID in a (possibly empty) slice, in order of name.
+func (dbs GorpMysqlDBSession) FindCategoriesByCeremony(ceremonyID uint64) ([]awardModel.Category, error) {
+	var gorpMysqlCategories []gorpAwardModel.GorpMysqlCategory
+	_, err := dbs.dbmap.Select(&gorpMysqlCategories,
+		"select id, ceremony_id, name from award_categories "+
+			"where ceremony_id = ? order by name, id", ceremonyID)
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]awardModel.Category, 0, len(gorpMysqlCategories))
+	for i := range gorpMysqlCategories {
+		categories = append(categories, &gorpMysqlCategories[i])
+	}
+	return categories, nil
+}
+
+// nominationSelect is the start of the query used by the finders of nominations.
+// It joins the nominations table with the categories, ceremonies and films tables
+// to fetch the display fields.  The person is optional, so the people table is
+// outer joined and the names are empty if there is no person.
+const nominationSelect = "select n.id, n.category_id, n.film_id, n.person_id, n.won, " +
+	"c.name as CategoryNameField, e.id as CeremonyIDField, e.name as CeremonyNameField, " +
+	"e.year as CeremonyYearField, f.title as FilmTitleField, " +
+	"f.release_year as FilmReleaseYearField, " +
+	"coalesce(p.forename, '') as PersonForenameField, " +
+	"coalesce(p.surname, '') as PersonSurnameField " +
+	"from nominations n join award_categories c on c.id = n.category_id " +
+	"join award_ceremonies e on e.id = c.ceremony_id " +
+	"join films f on f.id = n.film_id " +
+	"left join people p on p.id = n.person_id"
+
+// notTrashed is the condition that leaves out the nominations of people in the
+// trash.
+const notTrashed = "(n.person_id = 0 or p.deleted_at = 0)"
+
+// FindNominationByID fetches the row from the nominations table with the given
+// uint64 id, along with its display fields.
+func (dbs GorpMysqlDBSession) FindNominationByID(id uint64) (awardModel.Nomination, error) {
+	logger := logging.FromContext(dbs.ctx)
+	m := "FindNominationByID()"
+	logger.Debug(m, "id", id)
+	var gorpMysqlNomination gorpAwardModel.GorpMysqlNomination
+	err := dbs.dbmap.SelectOne(&gorpMysqlNomination, nominationSelect+" where n.id = ?", id)
+	if err != nil {
+		logger.Debug(m, "error", err)
+		return nil, err
+	}
+	return &gorpMysqlNomination, nil
+}
+
+// FindNominationsByCategory returns all of the nominations in the award category
+// with the given ID in a (possibly empty) slice, in order of ID.
+func (dbs GorpMysqlDBSession) FindNominationsByCategory(categoryID uint64) ([]awardModel.Nomination, error) {
+	return dbs.findNominations(nominationSelect+" where n.category_id = ? order by n.id", categoryID)
+}
+
+// FindNominationsByCeremony returns the nominations at the award ceremony with
+// the given ID in a (possibly empty) slice, in order of category name with the
+// winners of each category first.
+func (dbs GorpMysqlDBSession) FindNominationsByCeremony(ceremonyID uint64) ([]awardModel.Nomination, error) {
+	return dbs.findNominations(nominationSelect+" where c.ceremony_id = ? and "+notTrashed+
+		" order by c.name, n.won desc, f.title, n.id", ceremonyID)
+}
+
+// FindNominationsByFilm returns the nominations of the film with the given ID in
+// a (possibly empty) slice, most recent ceremony first.
+func (dbs GorpMysqlDBSession) FindNominationsByFilm(filmID uint64) ([]awardModel.Nomination, error) {
+	return dbs.findNominations(nominationSelect+" where n.film_id = ? and "+notTrashed+
+		" order by e.year desc, e.name, c.name, n.id", filmID)
+}
+
+// FindNominationsByPerson returns the nominations of the person with the given ID
+// in a (possibly empty) slice, most recent ceremony first.
+func (dbs GorpMysqlDBSession) FindNominationsByPerson(personID uint64) ([]awardModel.Nomination, error) {
+	return dbs.findNominations(nominationSelect+
+		" where n.person_id = ? order by e.year desc, e.name, c.name, n.id", personID)
+}
+
+// findNominations runs the given query, which fetches nominations, and returns
+// the result in a slice.
+func (dbs GorpMysqlDBSession) findNominations(query string, args ...interface{}) ([]awardModel.Nomination, error) {
+	return selectNominations(dbs.dbmap, query, args...)
+}
+
+// selectNominations runs the given query, which fetches nominations, using the
+// given executor - the DBMap or a transaction - and returns the result in a slice.
+func selectNominations(executor gorp.SqlExecutor, query string, args ...interface{}) ([]awardModel.Nomination, error) {
+	var gorpMysqlNominations []gorpAwardModel.GorpMysqlNomination
+	_, err := executor.Select(&gorpMysqlNominations, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	nominations := make([]awardModel.Nomination, 0, len(gorpMysqlNominations))
+	for i := range gorpMysqlNominations {
+		nominations = append(nominations, &gorpMysqlNominations[i])
+	}
+	return nominations, nil
+}
+
+// taxonomyFilter returns the extra conditions for the where clause of a query on
+// the table holding the given subject, and their arguments, that leave out the
+// records not in the genre with the given ID, or any genre below it, and the
+// records without the tag with the given ID.  An ID of 0 doesn't filter.  The SQL
+// works with MySQL and with SQLite.
+func (dbs GorpMysqlDBSession) taxonomyFilter(subject string, genreID uint64,
+	tagID uint64) (string, []interface{}, error) {
+
+	filter := ""
+	args := make([]interface{}, 0)
+	if genreID != 0 {
+		genres, err := dbs.FindAllGenres()
+		if err != nil {
+			return "", nil, err
+		}
+		ids := genreModel.Descendants(genres, genreID)
+		if len(ids) == 0 {
+			// No such genre, so nothing is in it.
+			ids = []uint64{genreID}
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+		filter += " and id in (select subject_id from genre_links " +
+			"where subject = ? and genre_id in (" + placeholders + "))"
+		args = append(args, subject)
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	if tagID != 0 {
+		filter += " and id in (select subject_id from tag_links where subject = ? and tag_id = ?)"
+		args = append(args, subject, tagID)
+	}
+	return filter, args, nil
+}
+
+// findCredits runs the given query, which fetches credits, and returns the result
+// in a slice.
+func (dbs GorpMysqlDBSession) findCredits(query string, args ...interface{}) ([]creditModel.Credit, error) {
+	return selectCredits(dbs.dbmap, query, args...)
+}
+
+// selectCredits runs the given query, which fetches credits, using the given
+// executor - the DBMap or a transaction - and returns the result in a slice.
+func selectCredits(executor gorp.SqlExecutor, query string, args ...interface{}) ([]creditModel.Credit, error) {
+	var gorpMysqlCredits []gorpCreditModel.GorpMysqlCredit
+	_, err := executor.Select(&gorpMysqlCredits, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	credits := make([]creditModel.Credit, 0, len(gorpMysqlCredits))
+	for _, c := range gorpMysqlCredits {
+		credits = append(credits, gorpCreditModel.Clone(&c))
+	}
+	return credits, nil
+}
@@ -0,0 +1,79 @@
+package dbsession
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/goblimey/films/utilities/migrations"
+	gorp "gopkg.in/gorp.v1"
+	// This import registers the sqlite3 driver used with GORP's SqliteDialect.
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// The GorpSqliteDBSession type represents an SQLite database session accessed via
+// GORP.  It satisfies the DBSession interface.  It uses the same table mapping and
+// the same queries as the GorpMysqlDBSession, so it borrows that type's methods.
+type GorpSqliteDBSession struct {
+	GorpMysqlDBSession
+}
+
+// MakeGorpSqliteDBSession is a factory function that creates a GorpSqliteDBSession
+// holding its data in the given file and returns it as a DBSession.  The file is
+// created if it doesn't exist, but the tables are created by the migrations.  If
+// any of the migrations have not been applied, it returns a migrations.BehindError.
+func MakeGorpSqliteDBSession(path string) (DBSession, error) {
+	db, err := openSqlite(path)
+	if err != nil {
+		return nil, err
+	}
+	// construct a gorp DbMap
+	dbmap := &gorp.DbMap{Db: db, Dialect: gorp.SqliteDialect{}}
+	err = addTables(dbmap, migrations.DialectSqlite)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// Create a concrete DBSession and an interface reference to it.
+	var session DBSession = &GorpSqliteDBSession{GorpMysqlDBSession{dbmap: dbmap}}
+
+	// Return the interface reference.
+	return session, nil
+}
+
+// isSqliteDuplicateKey returns true if the error is the one that SQLite returns
+// when an insert breaks a unique index.
+func isSqliteDuplicateKey(err error) bool {
+	var sqliteError sqlite3.Error
+	return errors.As(err, &sqliteError) && sqliteError.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// WithContext returns a copy of the session that logs against the given context.
+func (dbs GorpSqliteDBSession) WithContext(ctx context.Context) DBSession {
+	dbs.ctx = ctx
+	return &dbs
+}
+
+// openSqlite opens an SQLite database handle on the given file and checks that
+// it works.
+func openSqlite(path string) (*sql.DB, error) {
+	// Wait for a while rather than failing immediately if another connection
+	// holds a lock on the file.
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000")
+	if err != nil {
+		slog.Error("failed to get DB handle", "error", err)
+		return nil, errors.New("failed to get DB handle - " + err.Error())
+	}
+	// SQLite allows only one writer at a time, so use a single connection.
+	db.SetMaxOpenConns(1)
+	// check that the handle works
+	err = db.Ping()
+	if err != nil {
+		slog.Error("cannot open DB file", "path", path, "error", err)
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
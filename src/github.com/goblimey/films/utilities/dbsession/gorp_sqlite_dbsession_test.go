@@ -0,0 +1,428 @@
+package dbsession
+
+import (
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gorpAwardModel "github.com/goblimey/films/models/award/gorpmysql"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	gorpCreditModel "github.com/goblimey/films/models/credit/gorpmysql"
+	gorpDiaryModel "github.com/goblimey/films/models/diary/gorpmysql"
+	gorpFilmModel "github.com/goblimey/films/models/film/gorpmysql"
+	gorpGenreModel "github.com/goblimey/films/models/genre/gorpmysql"
+	gorpModel "github.com/goblimey/films/models/person/gorpmysql"
+	gorpReviewModel "github.com/goblimey/films/models/review/gorpmysql"
+	gorpTagModel "github.com/goblimey/films/models/tag/gorpmysql"
+	gorpUserModel "github.com/goblimey/films/models/user/gorpmysql"
+	gorpWatchlistModel "github.com/goblimey/films/models/watchlist/gorpmysql"
+	"github.com/goblimey/films/utilities/migrations"
+)
+
+// TestUnitSqliteStoreAndFetchBack checks that the migrations create an SQLite
+// database in the given file, that MakeDBSession opens a session on it, and that a person stored there can be fetched back.
+func TestUnitSqliteStoreAndFetchBack(t *testing.T) {
+	dir, err := ioutil.TempDir("", "films")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "films.db")
+	migrate(t, path)
+
+	session, err := MakeDBSession(DialectSqlite, path)
+	if err != nil {
+		t.Fatalf("cannot create session - %s", err.Error())
+	}
+	defer session.Close()
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the database file %s to be created - %s", path, err.Error())
+	}
+
+	person := gorpModel.MakeInitialisedPerson(0, "Joseph", "Cotten")
+	tx, _ := session.StartTransaction()
+	err = tx.Insert(person)
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	tx.Commit()
+
+	if person.ID() == 0 {
+		t.Errorf("Expected an ID to be assigned")
+	}
+
+	fetched, err := session.FindPersonByID(person.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the person - %s", err.Error())
+	}
+	if fetched.Surname() != "Cotten" {
+		t.Errorf("Expected surname Cotten, got %s", fetched.Surname())
+	}
+}
+
+// TestUnitSqliteTransactionFindsAllCreditsAndNominations checks that a transaction
+// finds all of the credits and award nominations on a film, including those of
+// the people in the trash, which the session's FindCreditsByFilm and
+// FindNominationsByFilm leave out.
+func TestUnitSqliteTransactionFindsAllCreditsAndNominations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "films")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "films.db")
+	migrate(t, path)
+
+	session, err := MakeDBSession(DialectSqlite, path)
+	if err != nil {
+		t.Fatalf("cannot create session - %s", err.Error())
+	}
+	defer session.Close()
+
+	joseph := gorpModel.MakeInitialisedPerson(0, "Joseph", "Cotten")
+	orson := gorpModel.MakeInitialisedPerson(0, "Orson", "Welles")
+	orson.SetDeletedAt(time.Now())
+	film := gorpFilmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, "")
+	tx, _ := session.StartTransaction()
+	err = tx.Insert(joseph, orson, film)
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	err = tx.Insert(
+		gorpCreditModel.MakeInitialisedCredit(0, joseph.ID(), film.ID(), "actor", "Holly Martins", 1),
+		gorpCreditModel.MakeInitialisedCredit(0, orson.ID(), film.ID(), "actor", "Harry Lime", 2))
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	ceremony := gorpAwardModel.MakeInitialisedCeremony(0, "BAFTA Awards", 1950)
+	err = tx.Insert(ceremony)
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	category := gorpAwardModel.MakeInitialisedCategory(0, ceremony.ID(), "Best Actor")
+	err = tx.Insert(category)
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	err = tx.Insert(
+		gorpAwardModel.MakeInitialisedNomination(0, category.ID(), film.ID(), joseph.ID(), false),
+		gorpAwardModel.MakeInitialisedNomination(0, category.ID(), film.ID(), orson.ID(), true))
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	tx.Commit()
+
+	credits, _ := session.FindCreditsByFilm(film.ID())
+	if len(credits) != 1 {
+		t.Errorf("Expected Orson's credit to be left out, got %d credits", len(credits))
+	}
+	tx, _ = session.StartTransaction()
+	credits, err = tx.FindAllCreditsByFilm(film.ID())
+	tx.Rollback()
+	if err != nil {
+		t.Fatalf("cannot fetch the credits - %s", err.Error())
+	}
+	if len(credits) != 2 || credits[1].PersonID() != orson.ID() {
+		t.Errorf("Expected both credits, got %v", credits)
+	}
+
+	nominations, _ := session.FindNominationsByFilm(film.ID())
+	if len(nominations) != 1 {
+		t.Errorf("Expected Orson's nomination to be left out, got %d nominations",
+			len(nominations))
+	}
+	tx, _ = session.StartTransaction()
+	nominations, err = tx.FindAllNominationsByFilm(film.ID())
+	tx.Rollback()
+	if err != nil {
+		t.Fatalf("cannot fetch the nominations - %s", err.Error())
+	}
+	if len(nominations) != 2 || nominations[1].PersonID() != orson.ID() || !nominations[1].Won() {
+		t.Errorf("Expected both nominations, got %v", nominations)
+	}
+}
+
+// TestUnitSqliteTransactionFindsAPersonsRows checks that a transaction finds the
+// credits, award nominations and genre and tag links of a person in the trash,
+// which are the rows that purging the person changes.
+func TestUnitSqliteTransactionFindsAPersonsRows(t *testing.T) {
+	dir, err := ioutil.TempDir("", "films")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "films.db")
+	migrate(t, path)
+
+	session, err := MakeDBSession(DialectSqlite, path)
+	if err != nil {
+		t.Fatalf("cannot create session - %s", err.Error())
+	}
+	defer session.Close()
+
+	orson := gorpModel.MakeInitialisedPerson(0, "Orson", "Welles")
+	orson.SetDeletedAt(time.Now())
+	film := gorpFilmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, "")
+	ceremony := gorpAwardModel.MakeInitialisedCeremony(0, "BAFTA Awards", 1950)
+	genre := gorpGenreModel.MakeInitialisedGenre(0, "Director", 0)
+	tag := gorpTagModel.MakeInitialisedTag(0, "auteur")
+	tx, _ := session.StartTransaction()
+	err = tx.Insert(orson, film, ceremony, genre, tag)
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	category := gorpAwardModel.MakeInitialisedCategory(0, ceremony.ID(), "Best Actor")
+	err = tx.Insert(category)
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	err = tx.Insert(
+		gorpCreditModel.MakeInitialisedCredit(0, orson.ID(), film.ID(), "actor", "Harry Lime", 2),
+		gorpAwardModel.MakeInitialisedNomination(0, category.ID(), film.ID(), orson.ID(), true),
+		gorpGenreModel.MakeInitialisedLink(0, genre.ID(), SubjectPerson, orson.ID()),
+		gorpTagModel.MakeInitialisedLink(0, tag.ID(), SubjectPerson, orson.ID()))
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	tx.Commit()
+
+	tx, _ = session.StartTransaction()
+	defer tx.Rollback()
+	credits, err := tx.FindAllCreditsByPerson(orson.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the credits - %s", err.Error())
+	}
+	if len(credits) != 1 || credits[0].FilmID() != film.ID() {
+		t.Errorf("Expected Orson's credit, got %v", credits)
+	}
+	nominations, err := tx.FindAllNominationsByPerson(orson.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the nominations - %s", err.Error())
+	}
+	if len(nominations) != 1 || !nominations[0].Won() {
+		t.Errorf("Expected Orson's nomination, got %v", nominations)
+	}
+	genreLinks, err := tx.FindGenreLinks(SubjectPerson, orson.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the genre links - %s", err.Error())
+	}
+	if len(genreLinks) != 1 || genreLinks[0].GenreID() != genre.ID() {
+		t.Errorf("Expected Orson's genre, got %v", genreLinks)
+	}
+	tagLinks, err := tx.FindTagLinks(SubjectPerson, orson.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the tag links - %s", err.Error())
+	}
+	if len(tagLinks) != 1 || tagLinks[0].TagID() != tag.ID() {
+		t.Errorf("Expected Orson's tag, got %v", tagLinks)
+	}
+}
+
+// TestUnitSqliteTransactionFindsAFilmsRows checks that a transaction finds the
+// rows that are deleted along with a film.
+func TestUnitSqliteTransactionFindsAFilmsRows(t *testing.T) {
+	dir, err := ioutil.TempDir("", "films")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "films.db")
+	migrate(t, path)
+
+	session, err := MakeDBSession(DialectSqlite, path)
+	if err != nil {
+		t.Fatalf("cannot create session - %s", err.Error())
+	}
+	defer session.Close()
+
+	film := gorpFilmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, "")
+	company := gorpCompanyModel.MakeInitialisedCompany(0, "London Films", "GB", 1932, 0)
+	user := gorpUserModel.MakeInitialisedUser(0, "vera", "hash", "viewer")
+	tx, _ := session.StartTransaction()
+	err = tx.Insert(film, company, user)
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	err = tx.Insert(gorpCompanyModel.MakeInitialisedLink(0, film.ID(), company.ID(), "production"),
+		gorpReviewModel.MakeInitialisedReview(0, film.ID(), user.ID(), 9, "Zither", ""),
+		gorpReviewModel.MakeInitialisedSummary(0, film.ID(), 0,
+			[]int{0, 0, 0, 0, 0, 0, 0, 0, 1, 0}),
+		gorpWatchlistModel.MakeInitialisedEntry(0, user.ID(), film.ID(), time.Now()),
+		gorpDiaryModel.MakeInitialisedEntry(0, user.ID(), film.ID(), time.Now(), 9, false))
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	tx.Commit()
+
+	tx, _ = session.StartTransaction()
+	defer tx.Rollback()
+	companyLinks, err := tx.FindAllCompanyLinksByFilm(film.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the links to companies - %s", err.Error())
+	}
+	if len(companyLinks) != 1 || companyLinks[0].CompanyID() != company.ID() {
+		t.Errorf("Expected the link to the company, got %v", companyLinks)
+	}
+	reviews, err := tx.FindAllReviewsByFilm(film.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the reviews - %s", err.Error())
+	}
+	if len(reviews) != 1 || reviews[0].UserID() != user.ID() || reviews[0].Rating() != 9 {
+		t.Errorf("Expected the review, got %v", reviews)
+	}
+	summary, err := tx.FindRatingSummary(film.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the summary - %s", err.Error())
+	}
+	if summary.FilmID() != film.ID() {
+		t.Errorf("Expected the summary of film %d, got %v", film.ID(), summary)
+	}
+	_, err = tx.FindRatingSummary(film.ID() + 1)
+	if err != sql.ErrNoRows {
+		t.Errorf("Expected %v for a film without ratings, got %v", sql.ErrNoRows, err)
+	}
+	watchlist, err := tx.FindAllWatchlistByFilm(film.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the watchlist entries - %s", err.Error())
+	}
+	if len(watchlist) != 1 || watchlist[0].UserID() != user.ID() {
+		t.Errorf("Expected the watchlist entry, got %v", watchlist)
+	}
+	diary, err := tx.FindAllDiaryByFilm(film.ID())
+	if err != nil {
+		t.Fatalf("cannot fetch the diary entries - %s", err.Error())
+	}
+	if len(diary) != 1 || diary[0].UserID() != user.ID() || diary[0].Rating() != 9 {
+		t.Errorf("Expected the diary entry, got %v", diary)
+	}
+}
+
+// TestUnitSqliteSummaryInsertedTwice checks that inserting a second rating summary
+// for a film, as happens when its first two ratings arrive together, breaks the
+// unique index with an error that counts as a conflict, so that it's tried again.
+func TestUnitSqliteSummaryInsertedTwice(t *testing.T) {
+	dir, err := ioutil.TempDir("", "films")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "films.db")
+	migrate(t, path)
+
+	session, err := MakeDBSession(DialectSqlite, path)
+	if err != nil {
+		t.Fatalf("cannot create session - %s", err.Error())
+	}
+	defer session.Close()
+
+	film := gorpFilmModel.MakeInitialisedFilm(0, "Vertigo", 1958, 128, "")
+	tx, _ := session.StartTransaction()
+	err = tx.Insert(film)
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	err = tx.Insert(gorpReviewModel.MakeInitialisedSummary(0, film.ID(), 0,
+		[]int{0, 0, 0, 0, 0, 0, 0, 1, 0, 0}))
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	tx.Commit()
+
+	tx, _ = session.StartTransaction()
+	err = tx.Insert(gorpReviewModel.MakeInitialisedSummary(0, film.ID(), 0,
+		[]int{0, 0, 1, 0, 0, 0, 0, 0, 0, 0}))
+	tx.Rollback()
+	if !IsConflict(err) {
+		t.Errorf("Expected a conflict inserting the second summary, got %v", err)
+	}
+	if IsConflict(errors.New("some other error")) {
+		t.Errorf("Expected another error not to be a conflict")
+	}
+}
+
+// TestUnitSqliteSchemaBehind checks that MakeDBSession refuses to open a
+// database whose people table was created before the version and deleted_at
+// columns were added, and that migrating it adds the columns, starting existing
+// people at version 1 and out of the trash.
+func TestUnitSqliteSchemaBehind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "films")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "films.db")
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = db.Exec("create table people (id integer not null primary key autoincrement, " +
+		"forename varchar(255), surname varchar(255))")
+	if err == nil {
+		_, err = db.Exec("insert into people (forename, surname) values ('Joseph', 'Cotten')")
+	}
+	db.Close()
+	if err != nil {
+		t.Fatalf("cannot set up the old table - %s", err.Error())
+	}
+
+	_, err = MakeDBSession(DialectSqlite, path)
+	if !migrations.IsBehind(err) {
+		t.Fatalf("Expected the schema to be behind, got %v", err)
+	}
+
+	migrate(t, path)
+
+	session, err := MakeDBSession(DialectSqlite, path)
+	if err != nil {
+		t.Fatalf("cannot create session - %s", err.Error())
+	}
+	defer session.Close()
+
+	person, err := session.FindPersonByID(1)
+	if err != nil {
+		t.Fatalf("cannot fetch the person - %s", err.Error())
+	}
+	if person.Version() != 1 {
+		t.Errorf("Expected version 1, got %d", person.Version())
+	}
+	if !person.DeletedAt().IsZero() {
+		t.Errorf("Expected the person not to be deleted, got %v", person.DeletedAt())
+	}
+}
+
+// TestUnitMakeMigratorForMemory checks that MakeMigrator refuses the memory
+// dialect, which has no schema.
+func TestUnitMakeMigratorForMemory(t *testing.T) {
+	_, err := MakeMigrator(DialectMemory, "")
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+// TestUnitMakeDBSessionWithUnknownDialect checks that MakeDBSession rejects a
+// dialect that it doesn't know.
+func TestUnitMakeDBSessionWithUnknownDialect(t *testing.T) {
+	_, err := MakeDBSession("postgres", "")
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+// migrate applies all of the migrations to the SQLite database in the given file.
+func migrate(t *testing.T, path string) {
+	migrator, err := MakeMigrator(DialectSqlite, path)
+	if err != nil {
+		t.Fatalf("cannot create migrator - %s", err.Error())
+	}
+	defer migrator.Close()
+	_, err = migrator.Up()
+	if err != nil {
+		t.Fatalf("cannot migrate - %s", err.Error())
+	}
+}
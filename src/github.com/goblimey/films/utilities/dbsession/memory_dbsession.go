@@ -0,0 +1,1664 @@
+package dbsession
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	auditModel "github.com/goblimey/films/models/audit"
+	gorpAuditModel "github.com/goblimey/films/models/audit/gorpmysql"
+	awardModel "github.com/goblimey/films/models/award"
+	gorpAwardModel "github.com/goblimey/films/models/award/gorpmysql"
+	companyModel "github.com/goblimey/films/models/company"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	creditModel "github.com/goblimey/films/models/credit"
+	gorpCreditModel "github.com/goblimey/films/models/credit/gorpmysql"
+	diaryModel "github.com/goblimey/films/models/diary"
+	gorpDiaryModel "github.com/goblimey/films/models/diary/gorpmysql"
+	filmModel "github.com/goblimey/films/models/film"
+	gorpFilmModel "github.com/goblimey/films/models/film/gorpmysql"
+	genreModel "github.com/goblimey/films/models/genre"
+	gorpGenreModel "github.com/goblimey/films/models/genre/gorpmysql"
+	personModel "github.com/goblimey/films/models/person"
+	gorpModel "github.com/goblimey/films/models/person/gorpmysql"
+	reviewModel "github.com/goblimey/films/models/review"
+	gorpReviewModel "github.com/goblimey/films/models/review/gorpmysql"
+	tagModel "github.com/goblimey/films/models/tag"
+	gorpTagModel "github.com/goblimey/films/models/tag/gorpmysql"
+	userModel "github.com/goblimey/films/models/user"
+	gorpUserModel "github.com/goblimey/films/models/user/gorpmysql"
+	watchlistModel "github.com/goblimey/films/models/watchlist"
+	gorpWatchlistModel "github.com/goblimey/films/models/watchlist/gorpmysql"
+	"github.com/goblimey/films/utilities/logging"
+	gorp "gopkg.in/gorp.v1"
+)
+
+// The MemoryDBSession type represents a database session whose tables are held in
+// memory.  It satisfies the DBSession interface and behaves in the same way as the
+// GORP sessions - IDs are auto-incremented, the finders filter out the same invalid
+// records and updates and deletes report the number of records affected.  It's
+// safe for concurrent use.  The data is lost when the server stops, so it's only
+// useful for trying things out and for running the tests without a database server.
+type MemoryDBSession struct {
+	mutex  *sync.Mutex
+	tables map[string]*memoryTable
+	ctx    context.Context
+}
+
+// memoryTable holds the records of one table, keyed by ID, along with the last ID
+// issued.  IDs are never reused, even if the record is deleted or the insert is
+// rolled back, which is what MySQL does with an auto-increment column.
+type memoryTable struct {
+	rows   map[uint64]interface{}
+	lastID uint64
+}
+
+// memoryRecord is satisfied by all of the models.
+type memoryRecord interface {
+	ID() uint64
+	SetID(id uint64)
+}
+
+// versionedRecord is satisfied by the models whose tables have a version column.
+// Updates and deletes of those records are checked against the stored version in
+// the same way that GORP checks them.
+type versionedRecord interface {
+	Version() int64
+	SetVersion(version int64)
+}
+
+// versionedTables lists the tables that have a version column, as set up by
+// addTables for the GORP sessions.  Other records may have a version, but it's
+// just data.
+var versionedTables = map[string]bool{"people": true, "film_ratings": true}
+
+// errDuplicateKey is returned when a transaction commits the rating summary of
+// a film that another transaction has already given one, as the unique index on
+// film_ratings.film_id does for the GORP sessions.
+var errDuplicateKey = errors.New("duplicate key - the film already has a rating summary")
+
+// versioned returns the record as a versionedRecord if its table has a version
+// column.
+func versioned(table string, record memoryRecord) (versionedRecord, bool) {
+	if !versionedTables[table] {
+		return nil, false
+	}
+	v, ok := record.(versionedRecord)
+	return v, ok
+}
+
+// The operations that a memoryTransaction can hold.
+const (
+	memoryInsert = iota
+	memoryUpdate
+	memoryDelete
+)
+
+// memoryChange is a change made within a transaction but not yet committed.
+type memoryChange struct {
+	operation int
+	table     string
+	record    memoryRecord
+}
+
+// memoryTransaction is a transaction on a MemoryDBSession.  The changes are held
+// in the transaction until it's committed, when they are all applied together.
+type memoryTransaction struct {
+	session  *MemoryDBSession
+	changes  []memoryChange
+	finished bool
+}
+
+// MakeMemoryDBSession is a factory function that creates a MemoryDBSession with
+// empty tables and returns it as a DBSession.
+func MakeMemoryDBSession() DBSession {
+	tables := make(map[string]*memoryTable)
+	for _, name := range []string{"people", "films", "credits", "audit_log", "users",
+		"genres", "genre_links", "tags", "tag_links", "companies", "film_companies", "reviews",
+		"film_ratings", "watchlist", "diary", "award_ceremonies", "award_categories",
+		"nominations"} {
+		tables[name] = &memoryTable{rows: make(map[uint64]interface{})}
+	}
+	return &MemoryDBSession{mutex: new(sync.Mutex), tables: tables}
+}
+
+// StartTransaction starts a transaction.
+func (dbs *MemoryDBSession) StartTransaction() (Transaction, error) {
+	return &memoryTransaction{session: dbs}, nil
+}
+
+// Close does nothing - there are no resources to release.
+func (dbs *MemoryDBSession) Close() {
+}
+
+// WithContext returns a copy of the session that logs against the given context.
+// The copy shares the tables and the lock with the original.
+func (dbs *MemoryDBSession) WithContext(ctx context.Context) DBSession {
+	return &MemoryDBSession{mutex: dbs.mutex, tables: dbs.tables, ctx: ctx}
+}
+
+// FindAllPeople returns a slice of all valid Person records in a (possibly empty)
+// slice, in order of ID.  A person is valid if they have a forename and a surname.
+func (dbs *MemoryDBSession) FindAllPeople() ([]personModel.Person, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	return dbs.validPeople(), nil
+}
+
+// FindPeople returns one page of the valid people, filtered and sorted as the
+// query says, and the number of valid people that match the filter.  Names are
+// compared ignoring case, as they are by the GORP sessions.
+func (dbs *MemoryDBSession) FindPeople(query PeopleQuery) ([]personModel.Person, int64, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	name := strings.ToLower(query.Name)
+	inTaxonomy := dbs.taxonomyFilter(SubjectPerson, query.GenreID, query.TagID)
+	matching := make([]personModel.Person, 0)
+	for _, person := range dbs.validPeople() {
+		if !inTaxonomy(person.ID()) {
+			continue
+		}
+		if strings.Contains(strings.ToLower(person.Forename()), name) ||
+			strings.Contains(strings.ToLower(person.Surname()), name) {
+			matching = append(matching, person)
+		}
+	}
+
+	var key func(person personModel.Person) string
+	switch query.Sort {
+	case SortBySurname:
+		key = func(person personModel.Person) string { return strings.ToLower(person.Surname()) }
+	case SortByForename:
+		key = func(person personModel.Person) string { return strings.ToLower(person.Forename()) }
+	case SortByID:
+		key = func(person personModel.Person) string { return "" }
+	default:
+		return nil, 0, fmt.Errorf("cannot sort people by %s", query.Sort)
+	}
+	// The people are already in order of ID, so a stable sort keeps people with
+	// the same name in that order.
+	sort.SliceStable(matching, func(i, j int) bool {
+		if query.Descending {
+			i, j = j, i
+		}
+		ki, kj := key(matching[i]), key(matching[j])
+		if ki != kj {
+			return ki < kj
+		}
+		return matching[i].ID() < matching[j].ID()
+	})
+
+	total := int64(len(matching))
+	start := query.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start > len(matching) {
+		start = len(matching)
+	}
+	end := len(matching)
+	if query.Limit > 0 && start+query.Limit < end {
+		end = start + query.Limit
+	}
+	return matching[start:end], total, nil
+}
+
+// validPeople returns the valid people that are not in the trash, in order of ID.
+// The caller must hold the lock.
+func (dbs *MemoryDBSession) validPeople() []personModel.Person {
+	validPeople := make([]personModel.Person, 0)
+	for _, row := range dbs.sortedRows("people") {
+		if !row.(personModel.Person).DeletedAt().IsZero() {
+			continue
+		}
+		person := gorpModel.Clone(row.(personModel.Person))
+		person.SetForename(strings.TrimSpace(person.Forename()))
+		person.SetSurname(strings.TrimSpace(person.Surname()))
+		if len(person.Forename()) > 0 && len(person.Surname()) > 0 {
+			validPeople = append(validPeople, person)
+		}
+	}
+	return validPeople
+}
+
+// FindPersonByID fetches the person with the given uint64 id. The data fetched may
+// or may not be valid.  If there is no such person, or they are in the trash, the
+// method returns the same error as the GORP sessions, sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindPersonByID(id uint64) (personModel.Person, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["people"].rows[id]
+	if !ok || !row.(personModel.Person).DeletedAt().IsZero() {
+		logger.Debug("FindPersonByID()", "id", id, "error", sql.ErrNoRows)
+		return nil, sql.ErrNoRows
+	}
+	return gorpModel.Clone(row.(personModel.Person)), nil
+}
+
+// FindDeletedPeople returns the people in the trash in a (possibly empty) slice,
+// most recently deleted first.
+func (dbs *MemoryDBSession) FindDeletedPeople() ([]personModel.Person, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	people := make([]personModel.Person, 0)
+	for _, row := range dbs.sortedRows("people") {
+		if !row.(personModel.Person).DeletedAt().IsZero() {
+			people = append(people, gorpModel.Clone(row.(personModel.Person)))
+		}
+	}
+	// The people are in order of ID, so the reverse order breaks ties in the
+	// same way as the GORP sessions.
+	sort.SliceStable(people, func(i, j int) bool {
+		a, b := people[i].DeletedAt(), people[j].DeletedAt()
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return people[i].ID() > people[j].ID()
+	})
+	return people, nil
+}
+
+// FindDeletedPersonByID fetches the person in the trash with the given uint64 id.
+// If there is no such person, or they are not in the trash, it returns
+// sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindDeletedPersonByID(id uint64) (personModel.Person, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["people"].rows[id]
+	if !ok || row.(personModel.Person).DeletedAt().IsZero() {
+		logger.Debug("FindDeletedPersonByID()", "id", id, "error", sql.ErrNoRows)
+		return nil, sql.ErrNoRows
+	}
+	return gorpModel.Clone(row.(personModel.Person)), nil
+}
+
+// FindAllFilms returns a slice of all valid Film records in a (possibly empty)
+// slice, in order of ID.  A film is valid if it has a title.
+func (dbs *MemoryDBSession) FindAllFilms() ([]filmModel.Film, error) {
+	return dbs.FindFilms(FilmQuery{})
+}
+
+// FindFilms returns the valid films that match the query in a (possibly empty)
+// slice, in order of ID.
+func (dbs *MemoryDBSession) FindFilms(query FilmQuery) ([]filmModel.Film, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	inTaxonomy := dbs.taxonomyFilter(SubjectFilm, query.GenreID, query.TagID)
+	validFilms := make([]filmModel.Film, 0)
+	for _, row := range dbs.sortedRows("films") {
+		if !inTaxonomy(row.(filmModel.Film).ID()) {
+			continue
+		}
+		film := gorpFilmModel.Clone(row.(filmModel.Film))
+		film.SetTitle(strings.TrimSpace(film.Title()))
+		if len(film.Title()) > 0 {
+			validFilms = append(validFilms, film)
+		}
+	}
+	return validFilms, nil
+}
+
+// FindFilmByID fetches the film with the given uint64 id. The data fetched may or
+// may not be valid.  If there is no such film, the method returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindFilmByID(id uint64) (filmModel.Film, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["films"].rows[id]
+	if !ok {
+		logger.Debug("FindFilmByID()", "id", id, "error", sql.ErrNoRows)
+		return nil, sql.ErrNoRows
+	}
+	return gorpFilmModel.Clone(row.(filmModel.Film)), nil
+}
+
+// FindCreditByID fetches the credit with the given uint64 id, along with the film
+// title and the person's name.  As with the join in the GORP sessions, a credit
+// whose film or person is missing is not found.
+func (dbs *MemoryDBSession) FindCreditByID(id uint64) (creditModel.Credit, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["credits"].rows[id]
+	if ok {
+		credit, found := dbs.joinCredit(row.(creditModel.Credit))
+		if found {
+			return credit, nil
+		}
+	}
+	logger.Debug("FindCreditByID()", "id", id, "error", sql.ErrNoRows)
+	return nil, sql.ErrNoRows
+}
+
+// FindCreditsByPerson returns the credits of the person with the given ID in a
+// (possibly empty) slice, in order of release year.
+func (dbs *MemoryDBSession) FindCreditsByPerson(personID uint64) ([]creditModel.Credit, error) {
+	credits := dbs.findCredits(func(c creditModel.Credit) bool {
+		return c.PersonID() == personID
+	})
+	sort.SliceStable(credits, func(i, j int) bool {
+		a, b := credits[i], credits[j]
+		if a.FilmReleaseYear() != b.FilmReleaseYear() {
+			return a.FilmReleaseYear() < b.FilmReleaseYear()
+		}
+		if a.FilmTitle() != b.FilmTitle() {
+			return a.FilmTitle() < b.FilmTitle()
+		}
+		return a.Role() < b.Role()
+	})
+	return credits, nil
+}
+
+// FindCreditsByFilm returns the credits on the film with the given ID in a
+// (possibly empty) slice, in order of role and billing.  The credits of people in
+// the trash are left out.
+func (dbs *MemoryDBSession) FindCreditsByFilm(filmID uint64) ([]creditModel.Credit, error) {
+	credits := dbs.findCredits(func(c creditModel.Credit) bool {
+		if c.FilmID() != filmID {
+			return false
+		}
+		person, ok := dbs.tables["people"].rows[c.PersonID()]
+		return ok && person.(personModel.Person).DeletedAt().IsZero()
+	})
+	sort.SliceStable(credits, func(i, j int) bool {
+		a, b := credits[i], credits[j]
+		if a.Role() != b.Role() {
+			return a.Role() < b.Role()
+		}
+		if a.Billing() != b.Billing() {
+			return a.Billing() < b.Billing()
+		}
+		if a.PersonSurname() != b.PersonSurname() {
+			return a.PersonSurname() < b.PersonSurname()
+		}
+		return a.PersonForename() < b.PersonForename()
+	})
+	return credits, nil
+}
+
+// findCredits returns the credits that satisfy the given condition, in order of
+// ID, with the display fields filled in.  The condition is checked with the lock
+// held.
+func (dbs *MemoryDBSession) findCredits(wanted func(creditModel.Credit) bool) []creditModel.Credit {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	credits := make([]creditModel.Credit, 0)
+	for _, row := range dbs.sortedRows("credits") {
+		if !wanted(row.(creditModel.Credit)) {
+			continue
+		}
+		credit, found := dbs.joinCredit(row.(creditModel.Credit))
+		if found {
+			credits = append(credits, credit)
+		}
+	}
+	return credits
+}
+
+// FindAuditEntries returns the audit log entries for the record with the given ID
+// in the given table in a (possibly empty) slice, newest first.
+func (dbs *MemoryDBSession) FindAuditEntries(tableName string, recordID uint64) ([]auditModel.Entry, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	rows := dbs.sortedRows("audit_log")
+	entries := make([]auditModel.Entry, 0)
+	for i := len(rows) - 1; i >= 0; i-- {
+		entry := rows[i].(auditModel.Entry)
+		if entry.TableName() == tableName && entry.RecordID() == recordID {
+			entries = append(entries, gorpAuditModel.Clone(entry))
+		}
+	}
+	return entries, nil
+}
+
+// FindUserByUsername fetches the user with the given username.  If there is no
+// such user, it returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindUserByUsername(username string) (userModel.User, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	for _, row := range dbs.tables["users"].rows {
+		user := row.(userModel.User)
+		if user.Username() == username {
+			return gorpUserModel.Clone(user), nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// FindAllGenres returns all of the genres in a (possibly empty) slice, in order of
+// ID.
+func (dbs *MemoryDBSession) FindAllGenres() ([]genreModel.Genre, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	return dbs.genres(), nil
+}
+
+// FindGenreLinks returns the links that attach genres to the given subject in a
+// (possibly empty) slice, in order of ID.
+func (dbs *MemoryDBSession) FindGenreLinks(subject string, subjectID uint64) ([]genreModel.Link, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	links := make([]genreModel.Link, 0)
+	for _, row := range dbs.sortedRows("genre_links") {
+		link := row.(genreModel.Link)
+		if link.Subject() == subject && link.SubjectID() == subjectID {
+			links = append(links, gorpGenreModel.CloneLink(link))
+		}
+	}
+	return links, nil
+}
+
+// FindAllTags returns the tags that are attached to something in a (possibly
+// empty) slice, in order of name, with the number of things that each is attached
+// to.
+func (dbs *MemoryDBSession) FindAllTags() ([]tagModel.Tag, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	tags := make([]tagModel.Tag, 0)
+	for _, row := range dbs.sortedRows("tags") {
+		tag := dbs.countedTag(row.(tagModel.Tag))
+		if tag.Count() > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Name() < tags[j].Name() })
+	return tags, nil
+}
+
+// FindTagByID fetches the tag with the given ID, with the number of things that
+// it's attached to.  If there is no such tag, it returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindTagByID(id uint64) (tagModel.Tag, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["tags"].rows[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return dbs.countedTag(row.(tagModel.Tag)), nil
+}
+
+// FindTagByName fetches the tag with the given name, with the number of things
+// that it's attached to.  If there is no such tag, it returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindTagByName(name string) (tagModel.Tag, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	for _, row := range dbs.tables["tags"].rows {
+		if row.(tagModel.Tag).Name() == name {
+			return dbs.countedTag(row.(tagModel.Tag)), nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// FindTagLinks returns the links that attach tags to the given subject in a
+// (possibly empty) slice, in order of ID.
+func (dbs *MemoryDBSession) FindTagLinks(subject string, subjectID uint64) ([]tagModel.Link, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	links := make([]tagModel.Link, 0)
+	for _, row := range dbs.sortedRows("tag_links") {
+		link := row.(tagModel.Link)
+		if link.Subject() == subject && link.SubjectID() == subjectID {
+			links = append(links, gorpTagModel.CloneLink(link))
+		}
+	}
+	return links, nil
+}
+
+// FindAllCompanies returns all of the companies in a (possibly empty) slice, in
+// order of name.
+func (dbs *MemoryDBSession) FindAllCompanies() ([]companyModel.Company, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	companies := make([]companyModel.Company, 0)
+	for _, row := range dbs.sortedRows("companies") {
+		companies = append(companies, gorpCompanyModel.Clone(row.(companyModel.Company)))
+	}
+	// The companies are in order of ID, so a stable sort breaks ties in the same
+	// way as the GORP sessions.
+	sort.SliceStable(companies, func(i, j int) bool {
+		return companies[i].Name() < companies[j].Name()
+	})
+	return companies, nil
+}
+
+// FindCompanyByID fetches the company with the given uint64 id.  If there is no
+// such company, it returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindCompanyByID(id uint64) (companyModel.Company, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["companies"].rows[id]
+	if !ok {
+		logger.Debug("FindCompanyByID()", "id", id, "error", sql.ErrNoRows)
+		return nil, sql.ErrNoRows
+	}
+	return gorpCompanyModel.Clone(row.(companyModel.Company)), nil
+}
+
+// FindCompanyLinkByID fetches the link between a company and a film with the given
+// uint64 id, along with the film title and the company name.  As with the join in
+// the GORP sessions, a link whose film or company is missing is not found.
+func (dbs *MemoryDBSession) FindCompanyLinkByID(id uint64) (companyModel.Link, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["film_companies"].rows[id]
+	if ok {
+		link, found := dbs.joinCompanyLink(row.(companyModel.Link))
+		if found {
+			return link, nil
+		}
+	}
+	logger.Debug("FindCompanyLinkByID()", "id", id, "error", sql.ErrNoRows)
+	return nil, sql.ErrNoRows
+}
+
+// FindCompanyLinksByFilm returns the links between the film with the given ID and
+// its companies in a (possibly empty) slice, in order of relationship and company
+// name.
+func (dbs *MemoryDBSession) FindCompanyLinksByFilm(filmID uint64) ([]companyModel.Link, error) {
+	links := dbs.findCompanyLinks(func(l companyModel.Link) bool {
+		return l.FilmID() == filmID
+	})
+	sort.SliceStable(links, func(i, j int) bool {
+		a, b := links[i], links[j]
+		if a.Relationship() != b.Relationship() {
+			return a.Relationship() < b.Relationship()
+		}
+		return a.CompanyName() < b.CompanyName()
+	})
+	return links, nil
+}
+
+// FindCompanyLinksByCompany returns the links between the company with the given
+// ID and its films in a (possibly empty) slice, in order of release year.
+func (dbs *MemoryDBSession) FindCompanyLinksByCompany(companyID uint64) ([]companyModel.Link, error) {
+	links := dbs.findCompanyLinks(func(l companyModel.Link) bool {
+		return l.CompanyID() == companyID
+	})
+	sort.SliceStable(links, func(i, j int) bool {
+		a, b := links[i], links[j]
+		if a.FilmReleaseYear() != b.FilmReleaseYear() {
+			return a.FilmReleaseYear() < b.FilmReleaseYear()
+		}
+		if a.FilmTitle() != b.FilmTitle() {
+			return a.FilmTitle() < b.FilmTitle()
+		}
+		return a.Relationship() < b.Relationship()
+	})
+	return links, nil
+}
+
+// findCompanyLinks returns the links between companies and films that satisfy the
+// given condition, in order of ID, with the display fields filled in.
+func (dbs *MemoryDBSession) findCompanyLinks(wanted func(companyModel.Link) bool) []companyModel.Link {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	links := make([]companyModel.Link, 0)
+	for _, row := range dbs.sortedRows("film_companies") {
+		if !wanted(row.(companyModel.Link)) {
+			continue
+		}
+		link, found := dbs.joinCompanyLink(row.(companyModel.Link))
+		if found {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// FindReviewByID fetches the review with the given uint64 id, along with the
+// username of the reviewer.  As with the join in the GORP sessions, a review whose
+// user is missing is not found.
+func (dbs *MemoryDBSession) FindReviewByID(id uint64) (reviewModel.Review, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["reviews"].rows[id]
+	if ok {
+		review, found := dbs.joinReview(row.(reviewModel.Review))
+		if found {
+			return review, nil
+		}
+	}
+	logger.Debug("FindReviewByID()", "id", id, "error", sql.ErrNoRows)
+	return nil, sql.ErrNoRows
+}
+
+// FindReviewByFilmAndUser fetches the review of the film with the given ID by the
+// user with the given ID.  If there is no such review, it returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindReviewByFilmAndUser(filmID uint64, userID uint64) (reviewModel.Review, error) {
+	logger := logging.FromContext(dbs.ctx)
+	reviews := dbs.findReviews(func(r reviewModel.Review) bool {
+		return r.FilmID() == filmID && r.UserID() == userID
+	})
+	if len(reviews) == 0 {
+		logger.Debug("FindReviewByFilmAndUser()", "filmID", filmID, "userID", userID,
+			"error", sql.ErrNoRows)
+		return nil, sql.ErrNoRows
+	}
+	return reviews[0], nil
+}
+
+// FindReviewsByFilm returns the reviews of the film with the given ID in a
+// (possibly empty) slice, most recent first.
+func (dbs *MemoryDBSession) FindReviewsByFilm(filmID uint64) ([]reviewModel.Review, error) {
+	reviews := dbs.findReviews(func(r reviewModel.Review) bool {
+		return r.FilmID() == filmID
+	})
+	sort.SliceStable(reviews, func(i, j int) bool {
+		a, b := reviews[i], reviews[j]
+		if !a.UpdatedAt().Equal(b.UpdatedAt()) {
+			return a.UpdatedAt().After(b.UpdatedAt())
+		}
+		return a.ID() > b.ID()
+	})
+	return reviews, nil
+}
+
+// FindRatingSummary fetches the summary of the ratings of the film with the given
+// ID.  If there is none, it returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindRatingSummary(filmID uint64) (reviewModel.Summary, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	for _, row := range dbs.sortedRows("film_ratings") {
+		summary := row.(reviewModel.Summary)
+		if summary.FilmID() == filmID {
+			return gorpReviewModel.CloneSummary(summary), nil
+		}
+	}
+	logger.Debug("FindRatingSummary()", "filmID", filmID, "error", sql.ErrNoRows)
+	return nil, sql.ErrNoRows
+}
+
+// findReviews returns the reviews that satisfy the given condition, in order of
+// ID, with the usernames filled in.
+func (dbs *MemoryDBSession) findReviews(wanted func(reviewModel.Review) bool) []reviewModel.Review {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	reviews := make([]reviewModel.Review, 0)
+	for _, row := range dbs.sortedRows("reviews") {
+		if !wanted(row.(reviewModel.Review)) {
+			continue
+		}
+		review, found := dbs.joinReview(row.(reviewModel.Review))
+		if found {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews
+}
+
+// FindWatchlistEntryByID fetches the watchlist entry with the given uint64 id,
+// along with the title of the film.  As with the join in the GORP sessions, an
+// entry whose film is missing is not found.
+func (dbs *MemoryDBSession) FindWatchlistEntryByID(id uint64) (watchlistModel.Entry, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["watchlist"].rows[id]
+	if ok {
+		entry, found := dbs.joinWatchlistEntry(row.(watchlistModel.Entry))
+		if found {
+			return entry, nil
+		}
+	}
+	logger.Debug("FindWatchlistEntryByID()", "id", id, "error", sql.ErrNoRows)
+	return nil, sql.ErrNoRows
+}
+
+// FindWatchlistEntryByUserAndFilm fetches the entry for the film with the given
+// ID on the watchlist of the user with the given ID.  If there is no such entry,
+// it returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindWatchlistEntryByUserAndFilm(userID uint64, filmID uint64) (watchlistModel.Entry, error) {
+	logger := logging.FromContext(dbs.ctx)
+	entries := dbs.findWatchlist(func(e watchlistModel.Entry) bool {
+		return e.UserID() == userID && e.FilmID() == filmID
+	})
+	if len(entries) == 0 {
+		logger.Debug("FindWatchlistEntryByUserAndFilm()", "userID", userID, "filmID", filmID,
+			"error", sql.ErrNoRows)
+		return nil, sql.ErrNoRows
+	}
+	return entries[0], nil
+}
+
+// FindWatchlistByUser returns the watchlist of the user with the given ID in a
+// (possibly empty) slice, most recently added first.
+func (dbs *MemoryDBSession) FindWatchlistByUser(userID uint64) ([]watchlistModel.Entry, error) {
+	entries := dbs.findWatchlist(func(e watchlistModel.Entry) bool {
+		return e.UserID() == userID
+	})
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.AddedAt().Equal(b.AddedAt()) {
+			return a.AddedAt().After(b.AddedAt())
+		}
+		return a.ID() > b.ID()
+	})
+	return entries, nil
+}
+
+// FindWatchlistByFilm returns the entries for the film with the given ID on all
+// of the watchlists in a (possibly empty) slice.
+func (dbs *MemoryDBSession) FindWatchlistByFilm(filmID uint64) ([]watchlistModel.Entry, error) {
+	return dbs.findWatchlist(func(e watchlistModel.Entry) bool {
+		return e.FilmID() == filmID
+	}), nil
+}
+
+// findWatchlist returns the watchlist entries that satisfy the given condition, in
+// order of ID, with the film titles filled in.
+func (dbs *MemoryDBSession) findWatchlist(wanted func(watchlistModel.Entry) bool) []watchlistModel.Entry {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	entries := make([]watchlistModel.Entry, 0)
+	for _, row := range dbs.sortedRows("watchlist") {
+		if !wanted(row.(watchlistModel.Entry)) {
+			continue
+		}
+		entry, found := dbs.joinWatchlistEntry(row.(watchlistModel.Entry))
+		if found {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// FindDiaryEntryByID fetches the diary entry with the given uint64 id, along with
+// the title of the film.  As with the join in the GORP sessions, an entry whose
+// film is missing is not found.
+func (dbs *MemoryDBSession) FindDiaryEntryByID(id uint64) (diaryModel.Entry, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["diary"].rows[id]
+	if ok {
+		entry, found := dbs.joinDiaryEntry(row.(diaryModel.Entry))
+		if found {
+			return entry, nil
+		}
+	}
+	logger.Debug("FindDiaryEntryByID()", "id", id, "error", sql.ErrNoRows)
+	return nil, sql.ErrNoRows
+}
+
+// FindDiaryByUser returns the diary of the user with the given ID in a (possibly
+// empty) slice, most recent viewing first.
+func (dbs *MemoryDBSession) FindDiaryByUser(userID uint64) ([]diaryModel.Entry, error) {
+	entries := dbs.findDiary(func(e diaryModel.Entry) bool {
+		return e.UserID() == userID
+	})
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.WatchedOn().Equal(b.WatchedOn()) {
+			return a.WatchedOn().After(b.WatchedOn())
+		}
+		return a.ID() > b.ID()
+	})
+	return entries, nil
+}
+
+// FindDiaryByFilm returns the entries for the film with the given ID in all of
+// the diaries in a (possibly empty) slice.
+func (dbs *MemoryDBSession) FindDiaryByFilm(filmID uint64) ([]diaryModel.Entry, error) {
+	return dbs.findDiary(func(e diaryModel.Entry) bool {
+		return e.FilmID() == filmID
+	}), nil
+}
+
+// findDiary returns the diary entries that satisfy the given condition, in order
+// of ID, with the film titles filled in.
+func (dbs *MemoryDBSession) findDiary(wanted func(diaryModel.Entry) bool) []diaryModel.Entry {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	entries := make([]diaryModel.Entry, 0)
+	for _, row := range dbs.sortedRows("diary") {
+		if !wanted(row.(diaryModel.Entry)) {
+			continue
+		}
+		entry, found := dbs.joinDiaryEntry(row.(diaryModel.Entry))
+		if found {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// FindAllCeremonies returns all of the award ceremonies in a (possibly empty)
+// slice, most recent year first and then in order of name.
+func (dbs *MemoryDBSession) FindAllCeremonies() ([]awardModel.Ceremony, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	ceremonies := make([]awardModel.Ceremony, 0)
+	for _, row := range dbs.sortedRows("award_ceremonies") {
+		ceremonies = append(ceremonies, gorpAwardModel.Clone(row.(awardModel.Ceremony)))
+	}
+	sort.SliceStable(ceremonies, func(i, j int) bool {
+		a, b := ceremonies[i], ceremonies[j]
+		if a.Year() != b.Year() {
+			return a.Year() > b.Year()
+		}
+		return a.Name() < b.Name()
+	})
+	return ceremonies, nil
+}
+
+// FindCeremonyByID fetches the award ceremony with the given uint64 id.  If there
+// is no such ceremony, it returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindCeremonyByID(id uint64) (awardModel.Ceremony, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["award_ceremonies"].rows[id]
+	if !ok {
+		logger.Debug("FindCeremonyByID()", "id", id, "error", sql.ErrNoRows)
+		return nil, sql.ErrNoRows
+	}
+	return gorpAwardModel.Clone(row.(awardModel.Ceremony)), nil
+}
+
+// FindCategoryByID fetches the award category with the given uint64 id.  If there
+// is no such category, it returns sql.ErrNoRows.
+func (dbs *MemoryDBSession) FindCategoryByID(id uint64) (awardModel.Category, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["award_categories"].rows[id]
+	if !ok {
+		logger.Debug("FindCategoryByID()", "id", id, "error", sql.ErrNoRows)
+		return nil, sql.ErrNoRows
+	}
+	return gorpAwardModel.CloneCategory(row.(awardModel.Category)), nil
+}
+
+// FindCategoriesByCeremony returns the categories of the award ceremony with the
+// given ID in a (possibly empty) slice, in order of name.
+func (dbs *MemoryDBSession) FindCategoriesByCeremony(ceremonyID uint64) ([]awardModel.Category, error) {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	categories := make([]awardModel.Category, 0)
+	for _, row := range dbs.sortedRows("award_categories") {
+		category := row.(awardModel.Category)
+		if category.CeremonyID() == ceremonyID {
+			categories = append(categories, gorpAwardModel.CloneCategory(category))
+		}
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].Name() < categories[j].Name()
+	})
+	return categories, nil
+}
+
+// FindNominationByID fetches the nomination with the given uint64 id, along with
+// its display fields.  As with the join in the GORP sessions, a nomination whose
+// category, ceremony or film is missing is not found.
+func (dbs *MemoryDBSession) FindNominationByID(id uint64) (awardModel.Nomination, error) {
+	logger := logging.FromContext(dbs.ctx)
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	row, ok := dbs.tables["nominations"].rows[id]
+	if ok {
+		nomination, found := dbs.joinNomination(row.(awardModel.Nomination))
+		if found {
+			return nomination, nil
+		}
+	}
+	logger.Debug("FindNominationByID()", "id", id, "error", sql.ErrNoRows)
+	return nil, sql.ErrNoRows
+}
+
+// FindNominationsByCategory returns all of the nominations in the award category
+// with the given ID in a (possibly empty) slice, in order of ID.
+func (dbs *MemoryDBSession) FindNominationsByCategory(categoryID uint64) ([]awardModel.Nomination, error) {
+	return dbs.findNominations(func(n awardModel.Nomination) bool {
+		return n.CategoryID() == categoryID
+	}), nil
+}
+
+// FindNominationsByCeremony returns the nominations at the award ceremony with
+// the given ID in a (possibly empty) slice, in order of category name with the
+// winners of each category first.
+func (dbs *MemoryDBSession) FindNominationsByCeremony(ceremonyID uint64) ([]awardModel.Nomination, error) {
+	nominations := dbs.findNominations(func(n awardModel.Nomination) bool {
+		return n.CeremonyID() == ceremonyID && dbs.notTrashed(n)
+	})
+	sort.SliceStable(nominations, func(i, j int) bool {
+		a, b := nominations[i], nominations[j]
+		if a.CategoryName() != b.CategoryName() {
+			return a.CategoryName() < b.CategoryName()
+		}
+		if a.Won() != b.Won() {
+			return a.Won()
+		}
+		return a.FilmTitle() < b.FilmTitle()
+	})
+	return nominations, nil
+}
+
+// FindNominationsByFilm returns the nominations of the film with the given ID in
+// a (possibly empty) slice, most recent ceremony first.
+func (dbs *MemoryDBSession) FindNominationsByFilm(filmID uint64) ([]awardModel.Nomination, error) {
+	nominations := dbs.findNominations(func(n awardModel.Nomination) bool {
+		return n.FilmID() == filmID && dbs.notTrashed(n)
+	})
+	sortByCeremony(nominations)
+	return nominations, nil
+}
+
+// FindNominationsByPerson returns the nominations of the person with the given ID
+// in a (possibly empty) slice, most recent ceremony first.
+func (dbs *MemoryDBSession) FindNominationsByPerson(personID uint64) ([]awardModel.Nomination, error) {
+	nominations := dbs.findNominations(func(n awardModel.Nomination) bool {
+		return n.PersonID() == personID
+	})
+	sortByCeremony(nominations)
+	return nominations, nil
+}
+
+// findNominations returns the nominations that satisfy the given condition, in
+// order of ID, with the display fields filled in.  The condition is checked with
+// the lock held, after the join.
+func (dbs *MemoryDBSession) findNominations(wanted func(awardModel.Nomination) bool) []awardModel.Nomination {
+	dbs.mutex.Lock()
+	defer dbs.mutex.Unlock()
+
+	nominations := make([]awardModel.Nomination, 0)
+	for _, row := range dbs.sortedRows("nominations") {
+		nomination, found := dbs.joinNomination(row.(awardModel.Nomination))
+		if found && wanted(nomination) {
+			nominations = append(nominations, nomination)
+		}
+	}
+	return nominations
+}
+
+// notTrashed returns false if the given nomination is of a person who is in the
+// trash or missing.  The caller must hold the lock.
+func (dbs *MemoryDBSession) notTrashed(nomination awardModel.Nomination) bool {
+	if nomination.PersonID() == 0 {
+		return true
+	}
+	person, ok := dbs.tables["people"].rows[nomination.PersonID()]
+	return ok && person.(personModel.Person).DeletedAt().IsZero()
+}
+
+// sortByCeremony puts the given nominations in order, most recent ceremony first,
+// then by ceremony name and category name.
+func sortByCeremony(nominations []awardModel.Nomination) {
+	sort.SliceStable(nominations, func(i, j int) bool {
+		a, b := nominations[i], nominations[j]
+		if a.CeremonyYear() != b.CeremonyYear() {
+			return a.CeremonyYear() > b.CeremonyYear()
+		}
+		if a.CeremonyName() != b.CeremonyName() {
+			return a.CeremonyName() < b.CeremonyName()
+		}
+		return a.CategoryName() < b.CategoryName()
+	})
+}
+
+// genres returns copies of all of the genres, in order of ID.  The caller must
+// hold the lock.
+func (dbs *MemoryDBSession) genres() []genreModel.Genre {
+	genres := make([]genreModel.Genre, 0)
+	for _, row := range dbs.sortedRows("genres") {
+		genres = append(genres, gorpGenreModel.Clone(row.(genreModel.Genre)))
+	}
+	return genres
+}
+
+// countedTag returns a copy of the given tag with the number of things that it's
+// attached to filled in.  The caller must hold the lock.
+func (dbs *MemoryDBSession) countedTag(source tagModel.Tag) tagModel.Tag {
+	tag := gorpTagModel.Clone(source)
+	count := 0
+	for _, row := range dbs.tables["tag_links"].rows {
+		if row.(tagModel.Link).TagID() == tag.ID() {
+			count++
+		}
+	}
+	tag.SetCount(count)
+	return tag
+}
+
+// taxonomyFilter returns a function that says whether the record of the given
+// subject with a given ID is in the genre with the given ID, or any genre below
+// it, and has the tag with the given ID.  An ID of 0 doesn't filter.  The caller
+// must hold the lock while the function is used.
+func (dbs *MemoryDBSession) taxonomyFilter(subject string, genreID uint64,
+	tagID uint64) func(id uint64) bool {
+
+	var inGenre, tagged map[uint64]bool
+	if genreID != 0 {
+		wanted := make(map[uint64]bool)
+		for _, id := range genreModel.Descendants(dbs.genres(), genreID) {
+			wanted[id] = true
+		}
+		inGenre = make(map[uint64]bool)
+		for _, row := range dbs.tables["genre_links"].rows {
+			link := row.(genreModel.Link)
+			if link.Subject() == subject && wanted[link.GenreID()] {
+				inGenre[link.SubjectID()] = true
+			}
+		}
+	}
+	if tagID != 0 {
+		tagged = make(map[uint64]bool)
+		for _, row := range dbs.tables["tag_links"].rows {
+			link := row.(tagModel.Link)
+			if link.Subject() == subject && link.TagID() == tagID {
+				tagged[link.SubjectID()] = true
+			}
+		}
+	}
+	return func(id uint64) bool {
+		return (inGenre == nil || inGenre[id]) && (tagged == nil || tagged[id])
+	}
+}
+
+// joinCredit returns a copy of the given credit with the film title and the
+// person's name filled in.  If the film or the person is missing, it returns
+// false.  The caller must hold the lock.
+func (dbs *MemoryDBSession) joinCredit(source creditModel.Credit) (creditModel.Credit, bool) {
+	filmRow, ok := dbs.tables["films"].rows[source.FilmID()]
+	if !ok {
+		return nil, false
+	}
+	personRow, ok := dbs.tables["people"].rows[source.PersonID()]
+	if !ok {
+		return nil, false
+	}
+	film := filmRow.(filmModel.Film)
+	person := personRow.(personModel.Person)
+	credit := gorpCreditModel.Clone(source)
+	credit.SetFilmTitle(film.Title())
+	credit.SetFilmReleaseYear(film.ReleaseYear())
+	credit.SetPersonForename(person.Forename())
+	credit.SetPersonSurname(person.Surname())
+	return credit, true
+}
+
+// joinCompanyLink returns a copy of the given link with the film title and the
+// company name filled in.  If the film or the company is missing, it returns
+// false.  The caller must hold the lock.
+func (dbs *MemoryDBSession) joinCompanyLink(source companyModel.Link) (companyModel.Link, bool) {
+	filmRow, ok := dbs.tables["films"].rows[source.FilmID()]
+	if !ok {
+		return nil, false
+	}
+	companyRow, ok := dbs.tables["companies"].rows[source.CompanyID()]
+	if !ok {
+		return nil, false
+	}
+	film := filmRow.(filmModel.Film)
+	link := gorpCompanyModel.CloneLink(source)
+	link.SetFilmTitle(film.Title())
+	link.SetFilmReleaseYear(film.ReleaseYear())
+	link.SetCompanyName(companyRow.(companyModel.Company).Name())
+	return link, true
+}
+
+// joinReview returns a copy of the given review with the username of the reviewer
+// filled in.  If the user is missing, it returns false.  The caller must hold the
+// lock.
+func (dbs *MemoryDBSession) joinReview(source reviewModel.Review) (reviewModel.Review, bool) {
+	userRow, ok := dbs.tables["users"].rows[source.UserID()]
+	if !ok {
+		return nil, false
+	}
+	review := gorpReviewModel.Clone(source)
+	review.SetUsername(userRow.(userModel.User).Username())
+	return review, true
+}
+
+// joinWatchlistEntry returns a copy of the given watchlist entry with the film's
+// title and release year filled in.  If the film is missing, it returns false.
+// The caller must hold the lock.
+func (dbs *MemoryDBSession) joinWatchlistEntry(source watchlistModel.Entry) (watchlistModel.Entry, bool) {
+	filmRow, ok := dbs.tables["films"].rows[source.FilmID()]
+	if !ok {
+		return nil, false
+	}
+	film := filmRow.(filmModel.Film)
+	entry := gorpWatchlistModel.Clone(source)
+	entry.SetFilmTitle(film.Title())
+	entry.SetFilmReleaseYear(film.ReleaseYear())
+	return entry, true
+}
+
+// joinDiaryEntry returns a copy of the given diary entry with the film's title and
+// release year filled in.  If the film is missing, it returns false.  The caller
+// must hold the lock.
+func (dbs *MemoryDBSession) joinDiaryEntry(source diaryModel.Entry) (diaryModel.Entry, bool) {
+	filmRow, ok := dbs.tables["films"].rows[source.FilmID()]
+	if !ok {
+		return nil, false
+	}
+	film := filmRow.(filmModel.Film)
+	entry := gorpDiaryModel.Clone(source)
+	entry.SetFilmTitle(film.Title())
+	entry.SetFilmReleaseYear(film.ReleaseYear())
+	return entry, true
+}
+
+// joinNomination returns a copy of the given nomination with the names of the
+// category and the ceremony, the film's title and release year and the person's
+// name filled in.  If the category, the ceremony or the film is missing, it
+// returns false.  As with the outer join in the GORP sessions, a missing person
+// leaves the name empty.  The caller must hold the lock.
+func (dbs *MemoryDBSession) joinNomination(source awardModel.Nomination) (awardModel.Nomination, bool) {
+	categoryRow, ok := dbs.tables["award_categories"].rows[source.CategoryID()]
+	if !ok {
+		return nil, false
+	}
+	category := categoryRow.(awardModel.Category)
+	ceremonyRow, ok := dbs.tables["award_ceremonies"].rows[category.CeremonyID()]
+	if !ok {
+		return nil, false
+	}
+	filmRow, ok := dbs.tables["films"].rows[source.FilmID()]
+	if !ok {
+		return nil, false
+	}
+	ceremony := ceremonyRow.(awardModel.Ceremony)
+	film := filmRow.(filmModel.Film)
+	nomination := gorpAwardModel.CloneNomination(source)
+	nomination.SetCategoryName(category.Name())
+	nomination.SetCeremonyID(ceremony.ID())
+	nomination.SetCeremonyName(ceremony.Name())
+	nomination.SetCeremonyYear(ceremony.Year())
+	nomination.SetFilmTitle(film.Title())
+	nomination.SetFilmReleaseYear(film.ReleaseYear())
+	nomination.SetPersonForename("")
+	nomination.SetPersonSurname("")
+	if personRow, ok := dbs.tables["people"].rows[source.PersonID()]; ok {
+		person := personRow.(personModel.Person)
+		nomination.SetPersonForename(person.Forename())
+		nomination.SetPersonSurname(person.Surname())
+	}
+	return nomination, true
+}
+
+// sortedRows returns the rows of the given table in order of ID.  The caller must
+// hold the lock.
+func (dbs *MemoryDBSession) sortedRows(table string) []interface{} {
+	rows := dbs.tables[table].rows
+	ids := make([]uint64, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	result := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, rows[id])
+	}
+	return result
+}
+
+// Insert adds the given records to the transaction.  Each record is given the
+// next ID for its table immediately, as GORP does, and a versioned record is
+// given version 1.
+func (tx *memoryTransaction) Insert(list ...interface{}) error {
+	if tx.finished {
+		return sql.ErrTxDone
+	}
+	for _, item := range list {
+		table, record, err := tableFor(item)
+		if err != nil {
+			return err
+		}
+		tx.session.mutex.Lock()
+		tx.session.tables[table].lastID++
+		record.SetID(tx.session.tables[table].lastID)
+		tx.session.mutex.Unlock()
+		if v, ok := versioned(table, record); ok && v.Version() == 0 {
+			v.SetVersion(1)
+		}
+		tx.changes = append(tx.changes, memoryChange{memoryInsert, table, cloneRecord(record)})
+	}
+	return nil
+}
+
+// FindAllCreditsByFilm returns all of the credits on the film with the given ID,
+// including those of the people in the trash, in order of ID.  The changes held
+// in the transaction are not taken into account.
+func (tx *memoryTransaction) FindAllCreditsByFilm(filmID uint64) ([]creditModel.Credit, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.findCredits(func(c creditModel.Credit) bool {
+		return c.FilmID() == filmID
+	}), nil
+}
+
+// FindAllNominationsByFilm returns all of the award nominations of the film with
+// the given ID, including those of the people in the trash, in order of ID.  The
+// changes held in the transaction are not taken into account.
+func (tx *memoryTransaction) FindAllNominationsByFilm(filmID uint64) ([]awardModel.Nomination, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.findNominations(func(n awardModel.Nomination) bool {
+		return n.FilmID() == filmID
+	}), nil
+}
+
+// FindAllCreditsByPerson returns all of the credits of the person with the given
+// ID, in order of ID.  The changes held in the transaction are not taken into
+// account.
+func (tx *memoryTransaction) FindAllCreditsByPerson(personID uint64) ([]creditModel.Credit, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.findCredits(func(c creditModel.Credit) bool {
+		return c.PersonID() == personID
+	}), nil
+}
+
+// FindAllNominationsByPerson returns all of the award nominations of the person
+// with the given ID, in order of ID.  The changes held in the transaction are not
+// taken into account.
+func (tx *memoryTransaction) FindAllNominationsByPerson(personID uint64) ([]awardModel.Nomination, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.findNominations(func(n awardModel.Nomination) bool {
+		return n.PersonID() == personID
+	}), nil
+}
+
+// FindGenreLinks returns the links that attach genres to the given subject in a
+// (possibly empty) slice, in order of ID.  The changes held in the transaction are
+// not taken into account.
+func (tx *memoryTransaction) FindGenreLinks(subject string, subjectID uint64) ([]genreModel.Link, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.FindGenreLinks(subject, subjectID)
+}
+
+// FindTagLinks returns the links that attach tags to the given subject in a
+// (possibly empty) slice, in order of ID.  The changes held in the transaction are
+// not taken into account.
+func (tx *memoryTransaction) FindTagLinks(subject string, subjectID uint64) ([]tagModel.Link, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.FindTagLinks(subject, subjectID)
+}
+
+// FindAllCompanyLinksByFilm returns all of the links between the film with the
+// given ID and its companies, in order of ID.  The changes held in the
+// transaction are not taken into account.
+func (tx *memoryTransaction) FindAllCompanyLinksByFilm(filmID uint64) ([]companyModel.Link, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.findCompanyLinks(func(l companyModel.Link) bool {
+		return l.FilmID() == filmID
+	}), nil
+}
+
+// FindAllReviewsByFilm returns all of the reviews of the film with the given ID,
+// in order of ID.  The changes held in the transaction are not taken into
+// account.
+func (tx *memoryTransaction) FindAllReviewsByFilm(filmID uint64) ([]reviewModel.Review, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.findReviews(func(r reviewModel.Review) bool {
+		return r.FilmID() == filmID
+	}), nil
+}
+
+// FindRatingSummary fetches the summary of the ratings of the film with the given
+// ID.  If there is none, it returns sql.ErrNoRows.  The changes held in the
+// transaction are not taken into account.
+func (tx *memoryTransaction) FindRatingSummary(filmID uint64) (reviewModel.Summary, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.FindRatingSummary(filmID)
+}
+
+// FindAllWatchlistByFilm returns all of the entries for the film with the given
+// ID on the watchlists, in order of ID.  The changes held in the transaction are
+// not taken into account.
+func (tx *memoryTransaction) FindAllWatchlistByFilm(filmID uint64) ([]watchlistModel.Entry, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.findWatchlist(func(e watchlistModel.Entry) bool {
+		return e.FilmID() == filmID
+	}), nil
+}
+
+// FindAllDiaryByFilm returns all of the entries for the film with the given ID
+// in the diaries, in order of ID.  The changes held in the transaction are not
+// taken into account.
+func (tx *memoryTransaction) FindAllDiaryByFilm(filmID uint64) ([]diaryModel.Entry, error) {
+	if tx.finished {
+		return nil, sql.ErrTxDone
+	}
+	return tx.session.findDiary(func(e diaryModel.Entry) bool {
+		return e.FilmID() == filmID
+	}), nil
+}
+
+// Update adds updates of the given records to the transaction and returns the
+// number of records that will be updated.  A record that does not exist is not
+// counted.  If a versioned record is out of date, the method returns a
+// gorp.OptimisticLockError, otherwise it increments the version.
+func (tx *memoryTransaction) Update(list ...interface{}) (int64, error) {
+	return tx.change(memoryUpdate, list)
+}
+
+// Delete adds deletions of the given records to the transaction and returns the
+// number of records that will be deleted.  A record that does not exist is not
+// counted.
+func (tx *memoryTransaction) Delete(list ...interface{}) (int64, error) {
+	return tx.change(memoryDelete, list)
+}
+
+// change adds updates or deletions to the transaction and returns the number of
+// records affected.
+func (tx *memoryTransaction) change(operation int, list []interface{}) (int64, error) {
+	if tx.finished {
+		return 0, sql.ErrTxDone
+	}
+	var count int64
+	for _, item := range list {
+		table, record, err := tableFor(item)
+		if err != nil {
+			return 0, err
+		}
+		stored, found := tx.current(table, record.ID())
+		if v, ok := versioned(table, record); ok && v.Version() > 0 {
+			// GORP only changes the row if it still has the caller's version.
+			if !found || stored.(versionedRecord).Version() != v.Version() {
+				return 0, lockError(table, record.ID(), v.Version(), found)
+			}
+			if operation == memoryUpdate {
+				v.SetVersion(v.Version() + 1)
+			}
+		} else if ok {
+			// With no version, GORP's where clause matches nothing.
+			continue
+		}
+		if found {
+			tx.changes = append(tx.changes, memoryChange{operation, table, cloneRecord(record)})
+			count++
+		}
+	}
+	return count, nil
+}
+
+// current returns the record with the given ID in the given table, as seen from
+// within the transaction, and false if there is no such record.
+func (tx *memoryTransaction) current(table string, id uint64) (memoryRecord, bool) {
+	tx.session.mutex.Lock()
+	row, found := tx.session.tables[table].rows[id]
+	tx.session.mutex.Unlock()
+	var record memoryRecord
+	if found {
+		record = row.(memoryRecord)
+	}
+
+	// Apply this transaction's own changes.
+	for _, change := range tx.changes {
+		if change.table != table || change.record.ID() != id {
+			continue
+		}
+		switch change.operation {
+		case memoryInsert, memoryUpdate:
+			record, found = change.record, true
+		case memoryDelete:
+			record, found = nil, false
+		}
+	}
+	return record, found
+}
+
+// lockError returns the error that GORP returns when a versioned record is out of
+// date or has gone.
+func lockError(table string, id uint64, version int64, exists bool) error {
+	return gorp.OptimisticLockError{
+		TableName:    table,
+		Keys:         []interface{}{id},
+		RowExists:    exists,
+		LocalVersion: version,
+	}
+}
+
+// Commit applies the changes in the transaction to the tables.  If another
+// transaction has changed a versioned record since this one read it, none of the
+// changes are applied and the method returns a gorp.OptimisticLockError.  (A
+// database would have made the second transaction wait instead.)  Likewise if
+// another transaction has given a film the rating summary that this one inserts.
+func (tx *memoryTransaction) Commit() error {
+	if tx.finished {
+		return sql.ErrTxDone
+	}
+	tx.finished = true
+
+	tx.session.mutex.Lock()
+	defer tx.session.mutex.Unlock()
+	err := tx.checkVersions()
+	if err == nil {
+		err = tx.checkUnique()
+	}
+	if err != nil {
+		tx.changes = nil
+		return err
+	}
+	for _, change := range tx.changes {
+		rows := tx.session.tables[change.table].rows
+		switch change.operation {
+		case memoryInsert:
+			rows[change.record.ID()] = change.record
+		case memoryUpdate:
+			// Don't bring back a record that another transaction has deleted.
+			if _, ok := rows[change.record.ID()]; ok {
+				rows[change.record.ID()] = change.record
+			}
+		case memoryDelete:
+			delete(rows, change.record.ID())
+		}
+	}
+	tx.changes = nil
+	return nil
+}
+
+// checkVersions checks that the versioned records that the transaction updates or
+// deletes have not been changed by another transaction.  The caller must hold the
+// lock.
+func (tx *memoryTransaction) checkVersions() error {
+	// The version that each record had when the transaction first changed it.
+	type rowKey struct {
+		table string
+		id    uint64
+	}
+	expected := make(map[rowKey]int64)
+	for _, change := range tx.changes {
+		key := rowKey{change.table, change.record.ID()}
+		v, ok := versioned(change.table, change.record)
+		if _, seen := expected[key]; seen || !ok {
+			continue
+		}
+		switch change.operation {
+		case memoryInsert:
+			expected[key] = 0
+		case memoryUpdate:
+			expected[key] = v.Version() - 1
+		case memoryDelete:
+			expected[key] = v.Version()
+		}
+	}
+	for key, version := range expected {
+		if version == 0 {
+			// Inserted by this transaction.
+			continue
+		}
+		row, found := tx.session.tables[key.table].rows[key.id]
+		if !found || row.(versionedRecord).Version() != version {
+			return lockError(key.table, key.id, version, found)
+		}
+	}
+	return nil
+}
+
+// checkUnique checks that the rating summaries that the transaction inserts are
+// for films that don't already have one.  The caller must hold the lock.
+func (tx *memoryTransaction) checkUnique() error {
+	for _, change := range tx.changes {
+		summary, ok := change.record.(reviewModel.Summary)
+		if change.operation != memoryInsert || !ok {
+			continue
+		}
+		for _, row := range tx.session.tables["film_ratings"].rows {
+			if row.(reviewModel.Summary).FilmID() == summary.FilmID() {
+				return errDuplicateKey
+			}
+		}
+	}
+	return nil
+}
+
+// Rollback abandons the changes in the transaction.
+func (tx *memoryTransaction) Rollback() error {
+	if tx.finished {
+		return sql.ErrTxDone
+	}
+	tx.finished = true
+	tx.changes = nil
+	return nil
+}
+
+// tableFor returns the name of the table that holds the given record.
+func tableFor(item interface{}) (string, memoryRecord, error) {
+	switch record := item.(type) {
+	case personModel.Person:
+		return "people", record, nil
+	case filmModel.Film:
+		return "films", record, nil
+	case creditModel.Credit:
+		return "credits", record, nil
+	case auditModel.Entry:
+		return "audit_log", record, nil
+	case userModel.User:
+		return "users", record, nil
+	case genreModel.Genre:
+		return "genres", record, nil
+	case genreModel.Link:
+		return "genre_links", record, nil
+	case tagModel.Tag:
+		return "tags", record, nil
+	case tagModel.Link:
+		return "tag_links", record, nil
+	case companyModel.Company:
+		return "companies", record, nil
+	case companyModel.Link:
+		return "film_companies", record, nil
+	case reviewModel.Review:
+		return "reviews", record, nil
+	case reviewModel.Summary:
+		return "film_ratings", record, nil
+	case watchlistModel.Entry:
+		return "watchlist", record, nil
+	case diaryModel.Entry:
+		return "diary", record, nil
+	case awardModel.Ceremony:
+		return "award_ceremonies", record, nil
+	case awardModel.Category:
+		return "award_categories", record, nil
+	case awardModel.Nomination:
+		return "nominations", record, nil
+	}
+	return "", nil, fmt.Errorf("no table for records of type %T", item)
+}
+
+// cloneRecord returns a copy of the given record, so that the caller can't change
+// the stored data.
+func cloneRecord(record memoryRecord) memoryRecord {
+	switch r := record.(type) {
+	case personModel.Person:
+		return gorpModel.Clone(r)
+	case filmModel.Film:
+		return gorpFilmModel.Clone(r)
+	case creditModel.Credit:
+		return gorpCreditModel.Clone(r)
+	case auditModel.Entry:
+		return gorpAuditModel.Clone(r)
+	case userModel.User:
+		return gorpUserModel.Clone(r)
+	case genreModel.Genre:
+		return gorpGenreModel.Clone(r)
+	case genreModel.Link:
+		return gorpGenreModel.CloneLink(r)
+	case tagModel.Tag:
+		return gorpTagModel.Clone(r)
+	case tagModel.Link:
+		return gorpTagModel.CloneLink(r)
+	case companyModel.Company:
+		return gorpCompanyModel.Clone(r)
+	case companyModel.Link:
+		return gorpCompanyModel.CloneLink(r)
+	case reviewModel.Review:
+		return gorpReviewModel.Clone(r)
+	case reviewModel.Summary:
+		return gorpReviewModel.CloneSummary(r)
+	case watchlistModel.Entry:
+		return gorpWatchlistModel.Clone(r)
+	case diaryModel.Entry:
+		return gorpDiaryModel.Clone(r)
+	case awardModel.Ceremony:
+		return gorpAwardModel.Clone(r)
+	case awardModel.Category:
+		return gorpAwardModel.CloneCategory(r)
+	case awardModel.Nomination:
+		return gorpAwardModel.CloneNomination(r)
+	}
+	return record
+}
@@ -0,0 +1,677 @@
+package dbsession
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	gorpAwardModel "github.com/goblimey/films/models/award/gorpmysql"
+	gorpCompanyModel "github.com/goblimey/films/models/company/gorpmysql"
+	gorpCreditModel "github.com/goblimey/films/models/credit/gorpmysql"
+	gorpDiaryModel "github.com/goblimey/films/models/diary/gorpmysql"
+	gorpFilmModel "github.com/goblimey/films/models/film/gorpmysql"
+	gorpGenreModel "github.com/goblimey/films/models/genre/gorpmysql"
+	gorpModel "github.com/goblimey/films/models/person/gorpmysql"
+	gorpReviewModel "github.com/goblimey/films/models/review/gorpmysql"
+	gorpTagModel "github.com/goblimey/films/models/tag/gorpmysql"
+	gorpUserModel "github.com/goblimey/films/models/user/gorpmysql"
+	gorpWatchlistModel "github.com/goblimey/films/models/watchlist/gorpmysql"
+)
+
+// TestUnitMemoryInsertAutoIncrements checks that inserted records get increasing
+// IDs, and that an ID is not reused after a rollback.
+func TestUnitMemoryInsertAutoIncrements(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	p1 := gorpModel.MakeInitialisedPerson(0, "Joseph", "Cotten")
+	tx, _ := session.StartTransaction()
+	err := tx.Insert(p1)
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	tx.Commit()
+	if p1.ID() != 1 {
+		t.Errorf("Expected ID 1, got %d", p1.ID())
+	}
+
+	tx, _ = session.StartTransaction()
+	tx.Insert(gorpModel.MakeInitialisedPerson(0, "Orson", "Welles"))
+	tx.Rollback()
+
+	p3 := gorpModel.MakeInitialisedPerson(0, "Alida", "Valli")
+	tx, _ = session.StartTransaction()
+	tx.Insert(p3)
+	tx.Commit()
+	if p3.ID() != 3 {
+		t.Errorf("Expected ID 3, got %d", p3.ID())
+	}
+
+	people, _ := session.FindAllPeople()
+	if len(people) != 2 {
+		t.Fatalf("Expected 2 people, got %d", len(people))
+	}
+	if people[0].ID() != 1 || people[1].ID() != 3 {
+		t.Errorf("Expected IDs 1 and 3, got %d and %d", people[0].ID(), people[1].ID())
+	}
+}
+
+// TestUnitMemoryFindAllPeopleFiltersInvalid checks that FindAllPeople leaves out
+// people with no forename or no surname, but FindPersonByID still finds them.
+func TestUnitMemoryFindAllPeopleFiltersInvalid(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	invalid := gorpModel.MakeInitialisedPerson(0, " ", "Howard")
+	tx, _ := session.StartTransaction()
+	tx.Insert(gorpModel.MakeInitialisedPerson(0, "Trevor", "Howard"), invalid)
+	tx.Commit()
+
+	people, err := session.FindAllPeople()
+	if err != nil {
+		t.Fatalf("FindAllPeople failed - %s", err.Error())
+	}
+	if len(people) != 1 {
+		t.Fatalf("Expected 1 person, got %d", len(people))
+	}
+
+	_, err = session.FindPersonByID(invalid.ID())
+	if err != nil {
+		t.Errorf("Expected to find the invalid person, got %s", err.Error())
+	}
+}
+
+// TestUnitMemoryFindPeople checks that FindPeople filters on the name, sorts in
+// either direction and returns one page along with the total.
+func TestUnitMemoryFindPeople(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	tx, _ := session.StartTransaction()
+	tx.Insert(gorpModel.MakeInitialisedPerson(0, "Orson", "Welles"),
+		gorpModel.MakeInitialisedPerson(0, "Joseph", "Cotten"),
+		gorpModel.MakeInitialisedPerson(0, "Agnes", "Moorehead"),
+		gorpModel.MakeInitialisedPerson(0, "", "Nobody"))
+	tx.Commit()
+
+	people, total, err := session.FindPeople(PeopleQuery{Sort: SortBySurname})
+	if err != nil {
+		t.Fatalf("FindPeople failed - %s", err.Error())
+	}
+	if total != 3 || len(people) != 3 {
+		t.Fatalf("Expected 3 people, got %d of %d", len(people), total)
+	}
+	if people[0].Surname() != "Cotten" || people[2].Surname() != "Welles" {
+		t.Errorf("Expected Cotten first and Welles last, got %s and %s",
+			people[0].Surname(), people[2].Surname())
+	}
+
+	people, total, _ = session.FindPeople(
+		PeopleQuery{Sort: SortByForename, Descending: true, Offset: 1, Limit: 1})
+	if total != 3 || len(people) != 1 || people[0].Forename() != "Joseph" {
+		t.Errorf("Expected Joseph on a page of 1 of 3, got %v of %d", people, total)
+	}
+
+	// The name matches part of either name, ignoring case.
+	people, total, _ = session.FindPeople(PeopleQuery{Name: "OR", Sort: SortByID})
+	if total != 2 || len(people) != 2 {
+		t.Errorf("Expected 2 people matching OR, got %d", total)
+	}
+
+	_, _, err = session.FindPeople(PeopleQuery{Sort: "junk"})
+	if err == nil {
+		t.Errorf("Expected an error for an unknown sort field")
+	}
+}
+
+// TestUnitMemoryUpdateAndDeleteCountRows checks that Update and Delete count only
+// the records that exist, and that changes are not seen until the commit.
+func TestUnitMemoryUpdateAndDeleteCountRows(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	film := gorpFilmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, "")
+	tx, _ := session.StartTransaction()
+	tx.Insert(film)
+	tx.Commit()
+
+	missing := gorpFilmModel.MakeInitialisedFilm(42, "Brief Encounter", 1945, 86, "")
+	tx, _ = session.StartTransaction()
+	rows, err := tx.Update(missing)
+	if err != nil || rows != 0 {
+		t.Errorf("Expected 0 rows updated and no error, got %d, %v", rows, err)
+	}
+	film.SetRuntime(108)
+	rows, err = tx.Update(film)
+	if err != nil || rows != 1 {
+		t.Errorf("Expected 1 row updated and no error, got %d, %v", rows, err)
+	}
+
+	stored, _ := session.FindFilmByID(film.ID())
+	if stored.Runtime() != 104 {
+		t.Errorf("Expected the update not to be seen before the commit")
+	}
+	tx.Commit()
+	stored, _ = session.FindFilmByID(film.ID())
+	if stored.Runtime() != 108 {
+		t.Errorf("Expected runtime 108 after the commit, got %d", stored.Runtime())
+	}
+
+	tx, _ = session.StartTransaction()
+	rows, _ = tx.Delete(film)
+	if rows != 1 {
+		t.Errorf("Expected 1 row deleted, got %d", rows)
+	}
+	rows, _ = tx.Delete(film)
+	if rows != 0 {
+		t.Errorf("Expected 0 rows deleted the second time, got %d", rows)
+	}
+	tx.Commit()
+
+	_, err = session.FindFilmByID(film.ID())
+	if err == nil {
+		t.Errorf("Expected the film to be deleted")
+	}
+}
+
+// TestUnitMemoryVersionChecks checks that the versions of people are checked and
+// incremented in the same way as GORP does it, and that an update based on a stale
+// copy fails with a conflict.
+func TestUnitMemoryVersionChecks(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	person := gorpModel.MakeInitialisedPerson(0, "Joseph", "Cotten")
+	tx, _ := session.StartTransaction()
+	tx.Insert(person)
+	tx.Commit()
+	if person.Version() != 1 {
+		t.Errorf("Expected version 1 after the insert, got %d", person.Version())
+	}
+
+	// Two copies of the same version.
+	first, _ := session.FindPersonByID(person.ID())
+	second, _ := session.FindPersonByID(person.ID())
+
+	first.SetForename("Joe")
+	tx, _ = session.StartTransaction()
+	rows, err := tx.Update(first)
+	if err != nil || rows != 1 {
+		t.Fatalf("Expected 1 row updated and no error, got %d, %v", rows, err)
+	}
+	tx.Commit()
+	if first.Version() != 2 {
+		t.Errorf("Expected version 2 after the update, got %d", first.Version())
+	}
+
+	second.SetSurname("Cotton")
+	tx, _ = session.StartTransaction()
+	_, err = tx.Update(second)
+	if !IsConflict(err) {
+		t.Errorf("Expected a conflict updating the stale copy, got %v", err)
+	}
+	_, err = tx.Delete(second)
+	if !IsConflict(err) {
+		t.Errorf("Expected a conflict deleting the stale copy, got %v", err)
+	}
+	tx.Rollback()
+
+	// Two transactions that both read version 2.  The second to commit loses.
+	tx1, _ := session.StartTransaction()
+	tx2, _ := session.StartTransaction()
+	tx1.Update(gorpModel.Clone(first))
+	tx2.Update(gorpModel.Clone(first))
+	err = tx1.Commit()
+	if err != nil {
+		t.Errorf("Expected the first commit to work, got %v", err)
+	}
+	err = tx2.Commit()
+	if !IsConflict(err) {
+		t.Errorf("Expected a conflict committing the second transaction, got %v", err)
+	}
+
+	stored, _ := session.FindPersonByID(person.ID())
+	if stored.Version() != 3 || stored.Forename() != "Joe" || stored.Surname() != "Cotten" {
+		t.Errorf("Expected Joe Cotten at version 3, got %s at version %d",
+			stored.String(), stored.Version())
+	}
+}
+
+// TestUnitMemoryCreditsAreJoined checks that the credit finders fill in the film
+// title and the person's name, and leave out credits whose film is missing.
+func TestUnitMemoryCreditsAreJoined(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	person := gorpModel.MakeInitialisedPerson(0, "Orson", "Welles")
+	film := gorpFilmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, "")
+	tx, _ := session.StartTransaction()
+	tx.Insert(person, film)
+	tx.Insert(gorpCreditModel.MakeInitialisedCredit(0, person.ID(), film.ID(), "actor", "Harry Lime", 2))
+	tx.Insert(gorpCreditModel.MakeInitialisedCredit(0, person.ID(), 99, "director", "", 0))
+	tx.Commit()
+
+	credits, _ := session.FindCreditsByPerson(person.ID())
+	if len(credits) != 1 {
+		t.Fatalf("Expected 1 credit, got %d", len(credits))
+	}
+	if credits[0].FilmTitle() != "The Third Man" {
+		t.Errorf("Expected film title The Third Man, got %s", credits[0].FilmTitle())
+	}
+	if credits[0].PersonSurname() != "Welles" {
+		t.Errorf("Expected surname Welles, got %s", credits[0].PersonSurname())
+	}
+}
+
+// TestUnitMemoryCompanyLinksAreJoined checks that the finders of the links between
+// companies and films fill in the display fields and put a company's films in
+// order of release year.
+func TestUnitMemoryCompanyLinksAreJoined(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	company := gorpCompanyModel.MakeInitialisedCompany(0, "Ealing Studios", "UK", 1902, 0)
+	film1 := gorpFilmModel.MakeInitialisedFilm(0, "The Ladykillers", 1955, 91, "")
+	film2 := gorpFilmModel.MakeInitialisedFilm(0, "Kind Hearts and Coronets", 1949, 106, "")
+	tx, _ := session.StartTransaction()
+	tx.Insert(company, film1, film2)
+	tx.Insert(gorpCompanyModel.MakeInitialisedLink(0, film1.ID(), company.ID(), "production"))
+	tx.Insert(gorpCompanyModel.MakeInitialisedLink(0, film2.ID(), company.ID(), "production"))
+	tx.Insert(gorpCompanyModel.MakeInitialisedLink(0, 99, company.ID(), "distribution"))
+	tx.Commit()
+
+	links, _ := session.FindCompanyLinksByCompany(company.ID())
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(links))
+	}
+	if links[0].FilmTitle() != "Kind Hearts and Coronets" || links[0].FilmReleaseYear() != 1949 {
+		t.Errorf("Expected Kind Hearts and Coronets (1949) first, got %s (%d)",
+			links[0].FilmTitle(), links[0].FilmReleaseYear())
+	}
+	links, _ = session.FindCompanyLinksByFilm(film1.ID())
+	if len(links) != 1 || links[0].CompanyName() != "Ealing Studios" {
+		t.Errorf("Expected one link to Ealing Studios, got %v", links)
+	}
+}
+
+// TestUnitMemoryRatingSummaryIsVersioned checks that the reviews are found with the
+// usernames of the reviewers and that an update of a stale rating summary is
+// refused, as it would be by GORP.
+func TestUnitMemoryRatingSummaryIsVersioned(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	user := gorpUserModel.MakeInitialisedUser(0, "vera", "hash", "viewer")
+	film := gorpFilmModel.MakeInitialisedFilm(0, "Vertigo", 1958, 128, "")
+	tx, _ := session.StartTransaction()
+	tx.Insert(user, film)
+	tx.Insert(gorpReviewModel.MakeInitialisedReview(0, film.ID(), user.ID(), 8, "", ""))
+	tx.Insert(gorpReviewModel.MakeInitialisedReview(0, film.ID(), 99, 2, "", ""))
+	tx.Insert(gorpReviewModel.MakeInitialisedSummary(0, film.ID(), 0,
+		[]int{0, 0, 0, 0, 0, 0, 0, 1, 0, 0}))
+	tx.Commit()
+
+	reviews, _ := session.FindReviewsByFilm(film.ID())
+	if len(reviews) != 1 || reviews[0].Username() != "vera" {
+		t.Errorf("Expected vera's review, got %v", reviews)
+	}
+
+	first, err := session.FindRatingSummary(film.ID())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	second, _ := session.FindRatingSummary(film.ID())
+	first.Add(10)
+	tx, _ = session.StartTransaction()
+	tx.Update(first)
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	second.Add(1)
+	tx, _ = session.StartTransaction()
+	_, err = tx.Update(second)
+	if !IsConflict(err) {
+		t.Errorf("Expected the stale summary to be refused, got %v", err)
+	}
+	tx.Rollback()
+	summary, _ := session.FindRatingSummary(film.ID())
+	if summary.Count() != 2 {
+		t.Errorf("Expected 2 ratings, got %s", summary.String())
+	}
+}
+
+// TestUnitMemorySummaryInsertedTwice checks that when two transactions both give
+// a film its first rating summary, the second to commit gets a conflict, as it
+// would from the unique index in a database, so that it can be tried again.
+func TestUnitMemorySummaryInsertedTwice(t *testing.T) {
+	session := MakeMemoryDBSession()
+	film := gorpFilmModel.MakeInitialisedFilm(0, "Vertigo", 1958, 128, "")
+	tx, _ := session.StartTransaction()
+	tx.Insert(film)
+	tx.Commit()
+
+	tx1, _ := session.StartTransaction()
+	tx2, _ := session.StartTransaction()
+	tx1.Insert(gorpReviewModel.MakeInitialisedSummary(0, film.ID(), 0,
+		[]int{0, 0, 0, 0, 0, 0, 0, 1, 0, 0}))
+	tx2.Insert(gorpReviewModel.MakeInitialisedSummary(0, film.ID(), 0,
+		[]int{0, 0, 1, 0, 0, 0, 0, 0, 0, 0}))
+	err := tx1.Commit()
+	if err != nil {
+		t.Fatalf("Expected the first commit to work, got %v", err)
+	}
+	err = tx2.Commit()
+	if !IsConflict(err) {
+		t.Errorf("Expected a conflict committing the second summary, got %v", err)
+	}
+
+	summary, _ := session.FindRatingSummary(film.ID())
+	if summary.Count() != 1 || summary.Histogram()[7] != 1 {
+		t.Errorf("Expected the first summary, got %s", summary.String())
+	}
+}
+
+// TestUnitMemoryDiaryIsByDate checks that a user's diary comes back most recent
+// viewing first, with the film titles, and leaves out other users' entries.
+func TestUnitMemoryDiaryIsByDate(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	rope := gorpFilmModel.MakeInitialisedFilm(0, "Rope", 1948, 80, "")
+	vertigo := gorpFilmModel.MakeInitialisedFilm(0, "Vertigo", 1958, 128, "")
+	tx, _ := session.StartTransaction()
+	tx.Insert(rope, vertigo)
+	march := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tx.Insert(gorpDiaryModel.MakeInitialisedEntry(0, 1, vertigo.ID(), march, 0, false))
+	tx.Insert(gorpDiaryModel.MakeInitialisedEntry(0, 1, rope.ID(), march.AddDate(0, 1, 0), 7, false))
+	tx.Insert(gorpDiaryModel.MakeInitialisedEntry(0, 2, rope.ID(), march.AddDate(0, 2, 0), 0, false))
+	tx.Insert(gorpDiaryModel.MakeInitialisedEntry(0, 1, vertigo.ID(), march, 9, true))
+	tx.Commit()
+
+	entries, _ := session.FindDiaryByUser(1)
+	expected := []string{"Rope", "Vertigo", "Vertigo"}
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.FilmTitle() != expected[i] {
+			t.Errorf("%d: expected %s, got %s", i, expected[i], entry.FilmTitle())
+		}
+	}
+	// On the same day, the entry added later comes first.
+	if !entries[1].Rewatch() {
+		t.Errorf("Expected the rewatch to come first, got %s", entries[1].String())
+	}
+}
+
+// TestUnitMemoryNominationsAreJoined checks that the nominations at a ceremony
+// come back with their display fields, in order of category with the winners
+// first, and that a nomination of a person in the trash is left out.
+func TestUnitMemoryNominationsAreJoined(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	hanks := gorpModel.MakeInitialisedPerson(0, "Tom", "Hanks")
+	freeman := gorpModel.MakeInitialisedPerson(0, "Morgan", "Freeman")
+	gump := gorpFilmModel.MakeInitialisedFilm(0, "Forrest Gump", 1994, 142, "")
+	shawshank := gorpFilmModel.MakeInitialisedFilm(0, "The Shawshank Redemption", 1994, 142, "")
+	ceremony := gorpAwardModel.MakeInitialisedCeremony(0, "Academy Awards", 1995)
+	tx, _ := session.StartTransaction()
+	tx.Insert(hanks, freeman, gump, shawshank, ceremony)
+	actor := gorpAwardModel.MakeInitialisedCategory(0, ceremony.ID(), "Best Actor")
+	picture := gorpAwardModel.MakeInitialisedCategory(0, ceremony.ID(), "Best Picture")
+	tx.Insert(picture, actor)
+	tx.Insert(gorpAwardModel.MakeInitialisedNomination(0, picture.ID(), shawshank.ID(), 0, false))
+	tx.Insert(gorpAwardModel.MakeInitialisedNomination(0, picture.ID(), gump.ID(), 0, true))
+	tx.Insert(gorpAwardModel.MakeInitialisedNomination(0, actor.ID(), gump.ID(), hanks.ID(), true))
+	tx.Insert(gorpAwardModel.MakeInitialisedNomination(0, actor.ID(), shawshank.ID(), freeman.ID(), false))
+	tx.Commit()
+
+	freeman.SetDeletedAt(time.Now())
+	tx, _ = session.StartTransaction()
+	tx.Update(freeman)
+	tx.Commit()
+
+	nominations, _ := session.FindNominationsByCeremony(ceremony.ID())
+	expected := []string{"Best Actor Forrest Gump", "Best Picture Forrest Gump",
+		"Best Picture The Shawshank Redemption"}
+	if len(nominations) != len(expected) {
+		t.Fatalf("Expected %d nominations, got %d", len(expected), len(nominations))
+	}
+	for i, nomination := range nominations {
+		got := nomination.CategoryName() + " " + nomination.FilmTitle()
+		if got != expected[i] {
+			t.Errorf("%d: expected %s, got %s", i, expected[i], got)
+		}
+	}
+	if nominations[0].PersonSurname() != "Hanks" || nominations[0].CeremonyYear() != 1995 {
+		t.Errorf("Expected Hanks at the 1995 ceremony, got %s", nominations[0].String())
+	}
+	// The person's own page still lists the nomination.
+	nominations, _ = session.FindNominationsByPerson(freeman.ID())
+	if len(nominations) != 1 {
+		t.Errorf("Expected 1 nomination of Freeman, got %d", len(nominations))
+	}
+}
+
+// TestUnitMemoryTaxonomyFilters checks that a film in a genre is found by a
+// search for any genre above it, that the tag filter works alongside the genre
+// filter and that the tags are counted.
+func TestUnitMemoryTaxonomyFilters(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	thriller := gorpGenreModel.MakeInitialisedGenre(0, "Thriller", 0)
+	film1 := gorpFilmModel.MakeInitialisedFilm(0, "Gaslight", 1944, 114, "")
+	film2 := gorpFilmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, "")
+	noir := gorpTagModel.MakeInitialisedTag(0, "film noir")
+	unused := gorpTagModel.MakeInitialisedTag(0, "unused")
+	tx, _ := session.StartTransaction()
+	tx.Insert(thriller, film1, film2, noir, unused)
+	psychological := gorpGenreModel.MakeInitialisedGenre(0, "Psychological Thriller", thriller.ID())
+	tx.Insert(psychological)
+	tx.Insert(gorpGenreModel.MakeInitialisedLink(0, psychological.ID(), SubjectFilm, film1.ID()))
+	tx.Insert(gorpGenreModel.MakeInitialisedLink(0, thriller.ID(), SubjectFilm, film2.ID()))
+	tx.Insert(gorpTagModel.MakeInitialisedLink(0, noir.ID(), SubjectFilm, film2.ID()))
+	tx.Commit()
+
+	var tests = []struct {
+		query    FilmQuery
+		expected int
+	}{
+		{FilmQuery{}, 2},
+		{FilmQuery{GenreID: thriller.ID()}, 2},
+		{FilmQuery{GenreID: psychological.ID()}, 1},
+		{FilmQuery{GenreID: thriller.ID(), TagID: noir.ID()}, 1},
+		{FilmQuery{GenreID: psychological.ID(), TagID: noir.ID()}, 0},
+		{FilmQuery{GenreID: 99}, 0},
+	}
+	for _, test := range tests {
+		films, err := session.FindFilms(test.query)
+		if err != nil {
+			t.Fatalf("FindFilms(%+v) failed - %s", test.query, err.Error())
+		}
+		if len(films) != test.expected {
+			t.Errorf("FindFilms(%+v): expected %d films, got %d", test.query,
+				test.expected, len(films))
+		}
+	}
+
+	tags, _ := session.FindAllTags()
+	if len(tags) != 1 || tags[0].Name() != "film noir" || tags[0].Count() != 1 {
+		t.Errorf("Expected just film noir, used once, got %v", tags)
+	}
+	tag, err := session.FindTagByName("unused")
+	if err != nil || tag.Count() != 0 {
+		t.Errorf("Expected to find the unused tag with count 0, got %v, %v", tag, err)
+	}
+}
+
+// TestUnitMemoryTrash checks that the finders leave out people in the trash, and
+// their credits and nominations on films, and that FindDeletedPeople finds them, most recently
+// deleted first.
+func TestUnitMemoryTrash(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	joseph := gorpModel.MakeInitialisedPerson(0, "Joseph", "Cotten")
+	orson := gorpModel.MakeInitialisedPerson(0, "Orson", "Welles")
+	alida := gorpModel.MakeInitialisedPerson(0, "Alida", "Valli")
+	film := gorpFilmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, "")
+	ceremony := gorpAwardModel.MakeInitialisedCeremony(0, "BAFTA Awards", 1950)
+	tx, _ := session.StartTransaction()
+	tx.Insert(joseph, orson, alida, film, ceremony)
+	tx.Insert(gorpCreditModel.MakeInitialisedCredit(0, orson.ID(), film.ID(), "actor", "Harry Lime", 2))
+	category := gorpAwardModel.MakeInitialisedCategory(0, ceremony.ID(), "Best Actor")
+	tx.Insert(category)
+	tx.Insert(gorpAwardModel.MakeInitialisedNomination(0, category.ID(), film.ID(), orson.ID(), false))
+	genre := gorpGenreModel.MakeInitialisedGenre(0, "Thriller", 0)
+	tag := gorpTagModel.MakeInitialisedTag(0, "auteur")
+	tx.Insert(genre, tag)
+	tx.Insert(gorpGenreModel.MakeInitialisedLink(0, genre.ID(), SubjectPerson, orson.ID()),
+		gorpTagModel.MakeInitialisedLink(0, tag.ID(), SubjectPerson, orson.ID()))
+	tx.Commit()
+
+	now := time.Now()
+	orson.SetDeletedAt(now.Add(-time.Hour))
+	alida.SetDeletedAt(now)
+	tx, _ = session.StartTransaction()
+	tx.Update(orson, alida)
+	tx.Commit()
+
+	people, _ := session.FindAllPeople()
+	if len(people) != 1 || people[0].ID() != joseph.ID() {
+		t.Errorf("Expected just Joseph, got %v", people)
+	}
+	_, total, _ := session.FindPeople(PeopleQuery{Sort: SortByID})
+	if total != 1 {
+		t.Errorf("Expected 1 person, got %d", total)
+	}
+	_, err := session.FindPersonByID(orson.ID())
+	if err == nil {
+		t.Errorf("Expected Orson not to be found")
+	}
+	credits, _ := session.FindCreditsByFilm(film.ID())
+	if len(credits) != 0 {
+		t.Errorf("Expected Orson's credit to be left out, got %d credits", len(credits))
+	}
+	credits, _ = session.FindCreditsByPerson(orson.ID())
+	if len(credits) != 1 {
+		t.Errorf("Expected Orson to keep his credit, got %d credits", len(credits))
+	}
+	nominations, _ := session.FindNominationsByFilm(film.ID())
+	if len(nominations) != 0 {
+		t.Errorf("Expected Orson's nomination to be left out, got %v", nominations)
+	}
+	tx, _ = session.StartTransaction()
+	credits, _ = tx.FindAllCreditsByFilm(film.ID())
+	nominations, _ = tx.FindAllNominationsByFilm(film.ID())
+	tx.Rollback()
+	if len(credits) != 1 || credits[0].PersonID() != orson.ID() {
+		t.Errorf("Expected the transaction to find Orson's credit, got %v", credits)
+	}
+	if len(nominations) != 1 || nominations[0].PersonID() != orson.ID() {
+		t.Errorf("Expected the transaction to find Orson's nomination, got %v", nominations)
+	}
+	tx, _ = session.StartTransaction()
+	credits, _ = tx.FindAllCreditsByPerson(orson.ID())
+	nominations, _ = tx.FindAllNominationsByPerson(orson.ID())
+	genreLinks, _ := tx.FindGenreLinks(SubjectPerson, orson.ID())
+	tagLinks, _ := tx.FindTagLinks(SubjectPerson, orson.ID())
+	tx.Rollback()
+	if len(credits) != 1 || credits[0].FilmID() != film.ID() {
+		t.Errorf("Expected the transaction to find Orson's credit, got %v", credits)
+	}
+	if len(nominations) != 1 || nominations[0].FilmID() != film.ID() {
+		t.Errorf("Expected the transaction to find Orson's nomination, got %v", nominations)
+	}
+	if len(genreLinks) != 1 || genreLinks[0].GenreID() != genre.ID() {
+		t.Errorf("Expected the transaction to find Orson's genre, got %v", genreLinks)
+	}
+	if len(tagLinks) != 1 || tagLinks[0].TagID() != tag.ID() {
+		t.Errorf("Expected the transaction to find Orson's tag, got %v", tagLinks)
+	}
+	_, err = tx.FindAllCreditsByPerson(orson.ID())
+	if err != sql.ErrTxDone {
+		t.Errorf("Expected %v from a finished transaction, got %v", sql.ErrTxDone, err)
+	}
+
+	deleted, _ := session.FindDeletedPeople()
+	if len(deleted) != 2 || deleted[0].ID() != alida.ID() || deleted[1].ID() != orson.ID() {
+		t.Errorf("Expected Alida then Orson, got %v", deleted)
+	}
+	_, err = session.FindDeletedPersonByID(joseph.ID())
+	if err == nil {
+		t.Errorf("Expected Joseph not to be in the trash")
+	}
+	person, err := session.FindDeletedPersonByID(orson.ID())
+	if err != nil || person.Surname() != "Welles" {
+		t.Errorf("Expected Orson in the trash, got %v, %v", person, err)
+	}
+}
+
+// TestUnitMemoryTransactionFindsAFilmsRows checks that a transaction finds the
+// rows that are deleted along with a film.
+func TestUnitMemoryTransactionFindsAFilmsRows(t *testing.T) {
+	session := MakeMemoryDBSession()
+
+	film := gorpFilmModel.MakeInitialisedFilm(0, "The Third Man", 1949, 104, "")
+	company := gorpCompanyModel.MakeInitialisedCompany(0, "London Films", "GB", 1932, 0)
+	user := gorpUserModel.MakeInitialisedUser(0, "vera", "hash", "viewer")
+	tx, _ := session.StartTransaction()
+	tx.Insert(film, company, user)
+	tx.Insert(gorpCompanyModel.MakeInitialisedLink(0, film.ID(), company.ID(), "production"),
+		gorpReviewModel.MakeInitialisedReview(0, film.ID(), user.ID(), 9, "Zither", ""),
+		gorpReviewModel.MakeInitialisedSummary(0, film.ID(), 0,
+			[]int{0, 0, 0, 0, 0, 0, 0, 0, 1, 0}),
+		gorpWatchlistModel.MakeInitialisedEntry(0, user.ID(), film.ID(), time.Now()),
+		gorpDiaryModel.MakeInitialisedEntry(0, user.ID(), film.ID(), time.Now(), 9, false))
+	tx.Commit()
+
+	tx, _ = session.StartTransaction()
+	companyLinks, _ := tx.FindAllCompanyLinksByFilm(film.ID())
+	reviews, _ := tx.FindAllReviewsByFilm(film.ID())
+	summary, err := tx.FindRatingSummary(film.ID())
+	_, errNone := tx.FindRatingSummary(film.ID() + 1)
+	watchlist, _ := tx.FindAllWatchlistByFilm(film.ID())
+	diary, _ := tx.FindAllDiaryByFilm(film.ID())
+	tx.Rollback()
+	if len(companyLinks) != 1 || companyLinks[0].CompanyID() != company.ID() {
+		t.Errorf("Expected the transaction to find the link to the company, got %v",
+			companyLinks)
+	}
+	if len(reviews) != 1 || reviews[0].UserID() != user.ID() {
+		t.Errorf("Expected the transaction to find the review, got %v", reviews)
+	}
+	if err != nil || summary.FilmID() != film.ID() {
+		t.Errorf("Expected the transaction to find the summary, got %v, %v", summary, err)
+	}
+	if errNone != sql.ErrNoRows {
+		t.Errorf("Expected %v for a film without ratings, got %v", sql.ErrNoRows, errNone)
+	}
+	if len(watchlist) != 1 || watchlist[0].UserID() != user.ID() {
+		t.Errorf("Expected the transaction to find the watchlist entry, got %v", watchlist)
+	}
+	if len(diary) != 1 || diary[0].UserID() != user.ID() {
+		t.Errorf("Expected the transaction to find the diary entry, got %v", diary)
+	}
+}
+
+// TestUnitMemoryConcurrentInserts checks that concurrent transactions get
+// distinct IDs and that none of the inserts are lost.
+func TestUnitMemoryConcurrentInserts(t *testing.T) {
+	session := MakeMemoryDBSession()
+	const inserts = 50
+
+	var wg sync.WaitGroup
+	for i := 0; i < inserts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, _ := session.StartTransaction()
+			tx.Insert(gorpModel.MakeInitialisedPerson(0, "Bernard", "Lee"))
+			tx.Commit()
+		}()
+	}
+	wg.Wait()
+
+	people, _ := session.FindAllPeople()
+	if len(people) != inserts {
+		t.Fatalf("Expected %d people, got %d", inserts, len(people))
+	}
+	for i, p := range people {
+		if p.ID() != uint64(i+1) {
+			t.Errorf("Expected ID %d, got %d", i+1, p.ID())
+		}
+	}
+}
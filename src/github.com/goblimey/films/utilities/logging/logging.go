@@ -0,0 +1,153 @@
+// Package logging sets up the structured log.  Each request gets its own logger,
+// which carries the request ID, the method and the path.  The logger travels in
+// the request's context, so the controllers, the repositories and the database
+// session can all log against the request that they are serving.  When the
+// request has been handled, one line records its status and how long it took.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/goblimey/films/utilities/config"
+)
+
+// RequestIDHeader is the header holding the request ID.  If a proxy in front of
+// the server has already given the request an ID, that's used, otherwise the
+// server makes one up.  Either way it's sent back in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// New creates a logger that writes to w in the given format, text or json, and
+// drops messages below the given level.
+func New(w io.Writer, format string, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	switch level {
+	case config.LogLevelDebug:
+		lvl = slog.LevelDebug
+	case config.LogLevelInfo:
+		lvl = slog.LevelInfo
+	case config.LogLevelWarn:
+		lvl = slog.LevelWarn
+	case config.LogLevelError:
+		lvl = slog.LevelError
+	default:
+		return nil, fmt.Errorf("there is no log level %q", level)
+	}
+	options := &slog.HandlerOptions{Level: lvl, AddSource: lvl == slog.LevelDebug}
+	switch format {
+	case config.LogFormatText:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case config.LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return nil, fmt.Errorf("there is no log format %q", format)
+}
+
+// contextKey is the type of the key under which the logger is stored in a
+// context.
+type contextKey int
+
+// loggerKey is the key under which the logger is stored.
+const loggerKey contextKey = 0
+
+// WithLogger returns a copy of the context carrying the given logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger carried by the context, or the default logger
+// if there is none - for example when there is no request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// FromRequest returns the logger for the given request.
+func FromRequest(r *http.Request) *slog.Logger {
+	if r == nil {
+		return slog.Default()
+	}
+	return FromContext(r.Context())
+}
+
+// validID matches the request IDs that are accepted from a proxy.  Anything
+// else is replaced, so that a client can't put rubbish into the log.
+var validID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// newID makes up a request ID.
+func newID() string {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return fmt.Sprintf("t%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// statusRecorder remembers the status sent by the handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status and sends it.
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Write sends part of the body.  If the status hasn't been sent, it's 200.
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// Handler wraps a handler so that each request carries a logger derived from
+// the given one, with the request ID, the method and the path.  When the
+// request has been handled, it logs the status and the latency.
+func Handler(logger *slog.Logger, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if !validID.MatchString(id) {
+			id = newID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		requestLogger := logger.With(
+			slog.String("request_id", id),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+		)
+		recorder := &statusRecorder{ResponseWriter: w}
+		handler.ServeHTTP(recorder, r.WithContext(WithLogger(r.Context(), requestLogger)))
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		requestLogger.Log(r.Context(), level, "request",
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("remote", r.RemoteAddr),
+		)
+	})
+}
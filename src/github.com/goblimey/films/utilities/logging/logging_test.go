@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goblimey/films/utilities/config"
+)
+
+// TestUnitNew checks that New writes in the given format, drops the messages
+// below the given level and refuses a format or level that it doesn't know.
+func TestUnitNew(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := New(&buffer, config.LogFormatJSON, config.LogLevelInfo)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	logger.Debug("hidden")
+	logger.Info("shown", "id", 42)
+	var line map[string]interface{}
+	err = json.Unmarshal(buffer.Bytes(), &line)
+	if err != nil {
+		t.Fatalf("expected one JSON line, got %q - %s", buffer.String(), err.Error())
+	}
+	if line["msg"] != "shown" || line["id"] != float64(42) {
+		t.Errorf("expected the info message with its ID, got %v", line)
+	}
+
+	buffer.Reset()
+	logger, err = New(&buffer, config.LogFormatText, config.LogLevelWarn)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	logger.Info("hidden")
+	logger.Warn("shown")
+	if !strings.Contains(buffer.String(), "level=WARN msg=shown") ||
+		strings.Contains(buffer.String(), "hidden") {
+		t.Errorf("expected only the warning in text, got %q", buffer.String())
+	}
+
+	_, err = New(&buffer, "xml", config.LogLevelInfo)
+	if err == nil {
+		t.Errorf("expected an error for the format xml")
+	}
+	_, err = New(&buffer, config.LogFormatText, "loud")
+	if err == nil {
+		t.Errorf("expected an error for the level loud")
+	}
+}
+
+// TestUnitHandler checks that the handler gives each request an ID, passes a
+// logger carrying it to the wrapped handler and logs the status at the end.
+func TestUnitHandler(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := New(&buffer, config.LogFormatJSON, config.LogLevelInfo)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	handler := Handler(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromRequest(r).Info("handling")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	var tests = []struct {
+		description string
+		sent        string
+		kept        bool
+	}{
+		{"no ID", "", false},
+		{"ID from a proxy", "abc-123", true},
+		{"rubbish ID", "a b\nc", false},
+	}
+
+	for _, test := range tests {
+		buffer.Reset()
+		req := httptest.NewRequest("GET", "/people/1", nil)
+		if test.sent != "" {
+			req.Header.Set(RequestIDHeader, test.sent)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		id := recorder.Header().Get(RequestIDHeader)
+		if !validID.MatchString(id) || (id == test.sent) != test.kept {
+			t.Errorf("%s: unexpected request ID %q", test.description, id)
+		}
+
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("%s: expected two lines, got %q", test.description, buffer.String())
+		}
+		for i, msg := range []string{"handling", "request"} {
+			var line map[string]interface{}
+			err = json.Unmarshal([]byte(lines[i]), &line)
+			if err != nil {
+				t.Fatalf("%s: %s", test.description, err.Error())
+			}
+			if line["msg"] != msg || line["request_id"] != id ||
+				line["method"] != "GET" || line["path"] != "/people/1" {
+				t.Errorf("%s: unexpected line %v", test.description, line)
+			}
+			if msg == "request" && line["status"] != float64(http.StatusNotFound) {
+				t.Errorf("%s: expected status %d, got %v", test.description,
+					http.StatusNotFound, line["status"])
+			}
+		}
+	}
+}
+
+// TestUnitFromContext checks that the default logger is used when there is no
+// request logger.
+func TestUnitFromContext(t *testing.T) {
+	if FromContext(nil) == nil || FromRequest(nil) == nil {
+		t.Errorf("expected the default logger")
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	if FromRequest(req) == nil {
+		t.Errorf("expected the default logger")
+	}
+}
@@ -0,0 +1,509 @@
+/*
+Package migrations manages the database schema.  Each change to the schema is a
+migration, with the SQL that makes the change (up) and the SQL that undoes it
+(down), for each dialect.  The migrations are applied in order and each one is
+recorded in the schema_migrations table when it's applied, so the schema can be
+brought up to date from any earlier version.
+
+A migration is never changed once it's released - a new change to the schema
+gets a new migration at the end of the list.
+*/
+package migrations
+
+// The dialects that have migrations.  The in-memory database has no schema.
+const (
+	DialectMySQL  = "mysql"
+	DialectSqlite = "sqlite"
+)
+
+// A Migration is one change to the schema.
+type Migration struct {
+	// ID gives the order in which the migrations are applied.  The schema
+	// version is the ID of the last migration applied.
+	ID int
+	// Name describes the change.
+	Name string
+	// Present is a query that succeeds if the change has already been made.
+	// Databases set up before there were migrations had their tables created by
+	// the server, so they can have some of the changes without any record of
+	// them.  When such a change is present, the migration is recorded without
+	// being run.  If Present is empty the migration is always run.
+	Present string
+	// Up holds the statements that make the change, for each dialect.
+	Up map[string][]string
+	// Down holds the statements that undo the change, for each dialect.
+	Down map[string][]string
+}
+
+// All is the list of migrations, in order.
+var All = []Migration{
+	{
+		ID:      1,
+		Name:    "create people",
+		Present: "select count(*) from people",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table people (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"forename varchar(255), surname varchar(255)) " +
+					"engine=InnoDB default charset=utf8",
+			},
+			DialectSqlite: {
+				"create table people (" +
+					"id integer not null primary key autoincrement, " +
+					"forename varchar(255), surname varchar(255))",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL:  {"drop table people"},
+			DialectSqlite: {"drop table people"},
+		},
+	},
+	{
+		ID:      2,
+		Name:    "create films",
+		Present: "select count(*) from films",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table films (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"title varchar(255), release_year int, runtime int, synopsis varchar(2000)) " +
+					"engine=InnoDB default charset=utf8",
+			},
+			DialectSqlite: {
+				"create table films (" +
+					"id integer not null primary key autoincrement, " +
+					"title varchar(255), release_year integer, runtime integer, synopsis varchar(2000))",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL:  {"drop table films"},
+			DialectSqlite: {"drop table films"},
+		},
+	},
+	{
+		ID:      3,
+		Name:    "create credits",
+		Present: "select count(*) from credits",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table credits (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"person_id bigint unsigned, film_id bigint unsigned, role varchar(20), " +
+					"character_name varchar(255), billing int) " +
+					"engine=InnoDB default charset=utf8",
+			},
+			DialectSqlite: {
+				"create table credits (" +
+					"id integer not null primary key autoincrement, " +
+					"person_id integer, film_id integer, role varchar(20), " +
+					"character_name varchar(255), billing integer)",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL:  {"drop table credits"},
+			DialectSqlite: {"drop table credits"},
+		},
+	},
+	{
+		// Existing people start at version 1, which is what GORP gives a new row.
+		ID:      4,
+		Name:    "add version to people",
+		Present: "select count(version) from people",
+		Up: map[string][]string{
+			DialectMySQL:  {"alter table people add column version bigint not null default 1"},
+			DialectSqlite: {"alter table people add column version bigint not null default 1"},
+		},
+		Down: map[string][]string{
+			DialectMySQL:  {"alter table people drop column version"},
+			DialectSqlite: {"alter table people drop column version"},
+		},
+	},
+	{
+		ID:      5,
+		Name:    "create audit_log",
+		Present: "select count(*) from audit_log",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table audit_log (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"table_name varchar(64), record_id bigint unsigned, action varchar(20), " +
+					"version bigint, changed_by varchar(255), changed_at bigint, " +
+					"before_values varchar(2000), after_values varchar(2000)) " +
+					"engine=InnoDB default charset=utf8",
+			},
+			DialectSqlite: {
+				"create table audit_log (" +
+					"id integer not null primary key autoincrement, " +
+					"table_name varchar(64), record_id integer, action varchar(20), " +
+					"version bigint, changed_by varchar(255), changed_at bigint, " +
+					"before_values varchar(2000), after_values varchar(2000))",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL:  {"drop table audit_log"},
+			DialectSqlite: {"drop table audit_log"},
+		},
+	},
+	{
+		// 0 for a live person, otherwise the time that they were moved to the
+		// trash.  Existing people are not in the trash.
+		ID:      6,
+		Name:    "add deleted_at to people",
+		Present: "select count(deleted_at) from people",
+		Up: map[string][]string{
+			DialectMySQL:  {"alter table people add column deleted_at bigint not null default 0"},
+			DialectSqlite: {"alter table people add column deleted_at bigint not null default 0"},
+		},
+		Down: map[string][]string{
+			DialectMySQL:  {"alter table people drop column deleted_at"},
+			DialectSqlite: {"alter table people drop column deleted_at"},
+		},
+	},
+	{
+		// The credits are always fetched by film or by person.
+		ID:   7,
+		Name: "index credits",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create index credits_film_id on credits (film_id)",
+				"create index credits_person_id on credits (person_id)",
+			},
+			DialectSqlite: {
+				"create index credits_film_id on credits (film_id)",
+				"create index credits_person_id on credits (person_id)",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL: {
+				"drop index credits_person_id on credits",
+				"drop index credits_film_id on credits",
+			},
+			DialectSqlite: {
+				"drop index credits_person_id",
+				"drop index credits_film_id",
+			},
+		},
+	},
+	{
+		// The people who can log in.  The password is stored as a bcrypt hash.
+		ID:   8,
+		Name: "create users",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table users (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"username varchar(64) not null, password_hash varchar(255) not null, " +
+					"unique key users_username (username)) " +
+					"engine=InnoDB default charset=utf8",
+			},
+			DialectSqlite: {
+				"create table users (" +
+					"id integer not null primary key autoincrement, " +
+					"username varchar(64) not null unique, password_hash varchar(255) not null)",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL:  {"drop table users"},
+			DialectSqlite: {"drop table users"},
+		},
+	}, {
+		// What each user can do.  The users created before there were roles start
+		// as viewers, like any new user - an administrator must be promoted
+		// explicitly with the "user role" command.
+		ID:   9,
+		Name: "add role to users",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"alter table users add column role varchar(20) not null default 'viewer'",
+			},
+			DialectSqlite: {
+				"alter table users add column role varchar(20) not null default 'viewer'",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL:  {"alter table users drop column role"},
+			DialectSqlite: {"alter table users drop column role"},
+		},
+	},
+	{
+		// The genres form a tree - parent_id is 0 for a top-level genre.  Tags
+		// are free-form and are shared by everything tagged with the same name.
+		// A link attaches a genre or a tag to a film or a person.  The subject
+		// is the name of the table that holds the record, as in the audit log.
+		ID:   10,
+		Name: "create genres and tags",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table genres (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"name varchar(64) not null, parent_id bigint unsigned not null default 0) " +
+					"engine=InnoDB default charset=utf8",
+				"create table tags (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"name varchar(64) not null, unique key tags_name (name)) " +
+					"engine=InnoDB default charset=utf8",
+				"create table genre_links (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"genre_id bigint unsigned not null, subject varchar(20) not null, " +
+					"subject_id bigint unsigned not null) " +
+					"engine=InnoDB default charset=utf8",
+				"create table tag_links (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"tag_id bigint unsigned not null, subject varchar(20) not null, " +
+					"subject_id bigint unsigned not null) " +
+					"engine=InnoDB default charset=utf8",
+				"create index genre_links_subject on genre_links (subject, subject_id)",
+				"create index genre_links_genre_id on genre_links (genre_id)",
+				"create index tag_links_subject on tag_links (subject, subject_id)",
+				"create index tag_links_tag_id on tag_links (tag_id)",
+			},
+			DialectSqlite: {
+				"create table genres (" +
+					"id integer not null primary key autoincrement, " +
+					"name varchar(64) not null, parent_id integer not null default 0)",
+				"create table tags (" +
+					"id integer not null primary key autoincrement, " +
+					"name varchar(64) not null unique)",
+				"create table genre_links (" +
+					"id integer not null primary key autoincrement, " +
+					"genre_id integer not null, subject varchar(20) not null, " +
+					"subject_id integer not null)",
+				"create table tag_links (" +
+					"id integer not null primary key autoincrement, " +
+					"tag_id integer not null, subject varchar(20) not null, " +
+					"subject_id integer not null)",
+				"create index genre_links_subject on genre_links (subject, subject_id)",
+				"create index genre_links_genre_id on genre_links (genre_id)",
+				"create index tag_links_subject on tag_links (subject, subject_id)",
+				"create index tag_links_tag_id on tag_links (tag_id)",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL: {
+				"drop table tag_links",
+				"drop table genre_links",
+				"drop table tags",
+				"drop table genres",
+			},
+			DialectSqlite: {
+				"drop table tag_links",
+				"drop table genre_links",
+				"drop table tags",
+				"drop table genres",
+			},
+		},
+	},
+	{
+		// A company is linked to each film that it made or distributed.  The
+		// relationship says which.  Founded and defunct are years, 0 if not known,
+		// and defunct is 0 for a company that's still trading.
+		ID:   11,
+		Name: "create companies",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table companies (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"name varchar(255) not null, country varchar(64) not null default '', " +
+					"founded int not null default 0, defunct int not null default 0) " +
+					"engine=InnoDB default charset=utf8",
+				"create table film_companies (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"film_id bigint unsigned not null, company_id bigint unsigned not null, " +
+					"relationship varchar(20) not null) " +
+					"engine=InnoDB default charset=utf8",
+				"create index film_companies_film_id on film_companies (film_id)",
+				"create index film_companies_company_id on film_companies (company_id)",
+			},
+			DialectSqlite: {
+				"create table companies (" +
+					"id integer not null primary key autoincrement, " +
+					"name varchar(255) not null, country varchar(64) not null default '', " +
+					"founded integer not null default 0, defunct integer not null default 0)",
+				"create table film_companies (" +
+					"id integer not null primary key autoincrement, " +
+					"film_id integer not null, company_id integer not null, " +
+					"relationship varchar(20) not null)",
+				"create index film_companies_film_id on film_companies (film_id)",
+				"create index film_companies_company_id on film_companies (company_id)",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL: {
+				"drop table film_companies",
+				"drop table companies",
+			},
+			DialectSqlite: {
+				"drop table film_companies",
+				"drop table companies",
+			},
+		},
+	},
+	{
+		// Each user can rate a film and write a review of it, once.  The
+		// film_ratings table holds the number of ratings of each value for each
+		// film, kept up to date as the reviews change, so that the film's page
+		// doesn't have to read every review.  updated_at is in seconds since the
+		// epoch, like the times in the audit log.
+		ID:   12,
+		Name: "create reviews",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table reviews (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"film_id bigint unsigned not null, user_id bigint unsigned not null, " +
+					"rating int not null, title varchar(255) not null default '', " +
+					"body text not null, updated_at bigint not null default 0) " +
+					"engine=InnoDB default charset=utf8",
+				"create unique index reviews_film_id_user_id on reviews (film_id, user_id)",
+				"create table film_ratings (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"film_id bigint unsigned not null, version bigint, " +
+					"rating_1 int not null default 0, rating_2 int not null default 0, " +
+					"rating_3 int not null default 0, rating_4 int not null default 0, " +
+					"rating_5 int not null default 0, rating_6 int not null default 0, " +
+					"rating_7 int not null default 0, rating_8 int not null default 0, " +
+					"rating_9 int not null default 0, rating_10 int not null default 0) " +
+					"engine=InnoDB default charset=utf8",
+				"create unique index film_ratings_film_id on film_ratings (film_id)",
+			},
+			DialectSqlite: {
+				"create table reviews (" +
+					"id integer not null primary key autoincrement, " +
+					"film_id integer not null, user_id integer not null, " +
+					"rating integer not null, title varchar(255) not null default '', " +
+					"body text not null, updated_at bigint not null default 0)",
+				"create unique index reviews_film_id_user_id on reviews (film_id, user_id)",
+				"create table film_ratings (" +
+					"id integer not null primary key autoincrement, " +
+					"film_id integer not null, version bigint, " +
+					"rating_1 integer not null default 0, rating_2 integer not null default 0, " +
+					"rating_3 integer not null default 0, rating_4 integer not null default 0, " +
+					"rating_5 integer not null default 0, rating_6 integer not null default 0, " +
+					"rating_7 integer not null default 0, rating_8 integer not null default 0, " +
+					"rating_9 integer not null default 0, rating_10 integer not null default 0)",
+				"create unique index film_ratings_film_id on film_ratings (film_id)",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL: {
+				"drop table film_ratings",
+				"drop table reviews",
+			},
+			DialectSqlite: {
+				"drop table film_ratings",
+				"drop table reviews",
+			},
+		},
+	},
+	{
+		// Each user has a watchlist of the films that they want to see, with a film
+		// on it at most once, and a diary of the films that they have seen, which
+		// can have the same film many times.  The times are in seconds since the
+		// epoch.  watched_on is midnight UTC on the day.  A diary rating of 0
+		// means that the user didn't rate the viewing.
+		ID:   13,
+		Name: "create watchlist and diary",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table watchlist (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"user_id bigint unsigned not null, film_id bigint unsigned not null, " +
+					"added_at bigint not null default 0) " +
+					"engine=InnoDB default charset=utf8",
+				"create unique index watchlist_user_id_film_id on watchlist (user_id, film_id)",
+				"create table diary (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"user_id bigint unsigned not null, film_id bigint unsigned not null, " +
+					"watched_on bigint not null, rating int not null default 0, " +
+					"rewatch boolean not null default false) " +
+					"engine=InnoDB default charset=utf8",
+				"create index diary_user_id_watched_on on diary (user_id, watched_on)",
+			},
+			DialectSqlite: {
+				"create table watchlist (" +
+					"id integer not null primary key autoincrement, " +
+					"user_id integer not null, film_id integer not null, " +
+					"added_at bigint not null default 0)",
+				"create unique index watchlist_user_id_film_id on watchlist (user_id, film_id)",
+				"create table diary (" +
+					"id integer not null primary key autoincrement, " +
+					"user_id integer not null, film_id integer not null, " +
+					"watched_on bigint not null, rating integer not null default 0, " +
+					"rewatch boolean not null default 0)",
+				"create index diary_user_id_watched_on on diary (user_id, watched_on)",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL: {
+				"drop table diary",
+				"drop table watchlist",
+			},
+			DialectSqlite: {
+				"drop table diary",
+				"drop table watchlist",
+			},
+		},
+	},
+	{
+		// An award ceremony, such as the Academy Awards of 1995, has categories
+		// and each category has nominations.  A nomination is of a film and
+		// optionally a person for their work on it - person_id is 0 for none.  won
+		// is true for the winners.
+		ID:   14,
+		Name: "create awards",
+		Up: map[string][]string{
+			DialectMySQL: {
+				"create table award_ceremonies (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"name varchar(255) not null, year int not null) " +
+					"engine=InnoDB default charset=utf8",
+				"create unique index award_ceremonies_name_year on award_ceremonies (name, year)",
+				"create table award_categories (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"ceremony_id bigint unsigned not null, name varchar(255) not null) " +
+					"engine=InnoDB default charset=utf8",
+				"create unique index award_categories_ceremony_id_name on award_categories (ceremony_id, name)",
+				"create table nominations (" +
+					"id bigint unsigned not null auto_increment primary key, " +
+					"category_id bigint unsigned not null, film_id bigint unsigned not null, " +
+					"person_id bigint unsigned not null default 0, " +
+					"won boolean not null default false) " +
+					"engine=InnoDB default charset=utf8",
+				"create index nominations_category_id on nominations (category_id)",
+				"create index nominations_film_id on nominations (film_id)",
+				"create index nominations_person_id on nominations (person_id)",
+			},
+			DialectSqlite: {
+				"create table award_ceremonies (" +
+					"id integer not null primary key autoincrement, " +
+					"name varchar(255) not null, year integer not null)",
+				"create unique index award_ceremonies_name_year on award_ceremonies (name, year)",
+				"create table award_categories (" +
+					"id integer not null primary key autoincrement, " +
+					"ceremony_id integer not null, name varchar(255) not null)",
+				"create unique index award_categories_ceremony_id_name on award_categories (ceremony_id, name)",
+				"create table nominations (" +
+					"id integer not null primary key autoincrement, " +
+					"category_id integer not null, film_id integer not null, " +
+					"person_id integer not null default 0, " +
+					"won boolean not null default 0)",
+				"create index nominations_category_id on nominations (category_id)",
+				"create index nominations_film_id on nominations (film_id)",
+				"create index nominations_person_id on nominations (person_id)",
+			},
+		},
+		Down: map[string][]string{
+			DialectMySQL: {
+				"drop table nominations",
+				"drop table award_categories",
+				"drop table award_ceremonies",
+			},
+			DialectSqlite: {
+				"drop table nominations",
+				"drop table award_categories",
+				"drop table award_ceremonies",
+			},
+		},
+	},
+}
@@ -0,0 +1,291 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrNothingToUndo is returned by Down when no migrations have been applied.
+var ErrNothingToUndo = errors.New("no migrations have been applied")
+
+// BehindError is returned by Check when some of the migrations have not been
+// applied to the database.
+type BehindError struct {
+	Version int
+	Latest  int
+	Pending int
+}
+
+func (e BehindError) Error() string {
+	return fmt.Sprintf("the database schema is at version %d but version %d is needed.  "+
+		"Run \"films migrate up\"", e.Version, e.Latest)
+}
+
+// IsBehind returns true if the error says that the schema is behind.
+func IsBehind(err error) bool {
+	_, ok := err.(BehindError)
+	return ok
+}
+
+// Status says whether a migration has been applied, and when.
+type Status struct {
+	Migration Migration
+	// AppliedAt is the time that the migration was applied, or the zero time if
+	// it's pending.
+	AppliedAt time.Time
+}
+
+// Applied returns true if the migration has been applied.
+func (s Status) Applied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+// The Migrator interface defines the operations on the schema of a database.
+type Migrator interface {
+	// Status returns the status of each migration, in order.
+	Status() ([]Status, error)
+	// Version returns the ID of the last migration applied, or 0 if none have
+	// been applied.
+	Version() (int, error)
+	// Up applies the pending migrations in order and returns them.  If one fails
+	// it stops, returning the ones that succeeded and the error.
+	Up() ([]Migration, error)
+	// Down undoes the last migration applied and returns it.
+	Down() (Migration, error)
+	// Check returns a BehindError if any of the migrations are pending.
+	Check() error
+	// Close closes the database handle.
+	Close()
+}
+
+// ConcreteMigrator applies the migrations to a database via database/sql.  It
+// satisfies the Migrator interface.
+type ConcreteMigrator struct {
+	db         *sql.DB
+	dialect    string
+	migrations []Migration
+}
+
+// createTable creates the schema_migrations table.  It's the same in every
+// dialect.
+const createTable = "create table if not exists schema_migrations (" +
+	"version integer not null primary key, name varchar(255) not null, " +
+	"applied_at bigint not null)"
+
+// tableExists holds a query for each dialect that counts the schema_migrations
+// tables.
+var tableExists = map[string]string{
+	DialectMySQL: "select count(*) from information_schema.tables " +
+		"where table_schema = database() and table_name = 'schema_migrations'",
+	DialectSqlite: "select count(*) from sqlite_master " +
+		"where type = 'table' and name = 'schema_migrations'",
+}
+
+// MakeMigrator is a factory function that creates a Migrator for the given
+// database handle and dialect and returns it.
+func MakeMigrator(db *sql.DB, dialect string) (Migrator, error) {
+	return makeMigrator(db, dialect, All)
+}
+
+// makeMigrator creates a Migrator that applies the given list of migrations.
+func makeMigrator(db *sql.DB, dialect string, migrations []Migration) (Migrator, error) {
+	if _, ok := tableExists[dialect]; !ok {
+		return nil, fmt.Errorf("there are no migrations for the %s dialect", dialect)
+	}
+	return &ConcreteMigrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// Status returns the status of each migration, in order.
+func (m *ConcreteMigrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	status := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		status[i] = Status{Migration: migration, AppliedAt: applied[migration.ID]}
+	}
+	return status, nil
+}
+
+// Version returns the ID of the last migration applied, or 0 if none have been
+// applied.
+func (m *ConcreteMigrator) Version() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for id := range applied {
+		if id > version {
+			version = id
+		}
+	}
+	return version, nil
+}
+
+// Up applies the pending migrations in order and returns them.  A migration
+// whose change is already present is recorded without being run.  Each one is
+// applied in its own transaction, but MySQL commits each statement that changes
+// a table straight away, so a MySQL migration that fails part way through may
+// have to be tidied up by hand.
+func (m *ConcreteMigrator) Up() ([]Migration, error) {
+	_, err := m.db.Exec(createTable)
+	if err != nil {
+		em := fmt.Sprintf("cannot create the schema_migrations table - %s", err.Error())
+		slog.Error(em)
+		return nil, errors.New(em)
+	}
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]Migration, 0)
+	for _, s := range status {
+		if s.Applied() {
+			continue
+		}
+		statements := s.Migration.Up[m.dialect]
+		if m.present(s.Migration) {
+			slog.Info("migration is already present - recording it", "id", s.Migration.ID, "name", s.Migration.Name)
+			statements = nil
+		} else {
+			slog.Info("applying migration", "id", s.Migration.ID, "name", s.Migration.Name)
+		}
+		err = m.run(s.Migration, statements, "insert into schema_migrations (version, name, applied_at) values (?, ?, ?)",
+			s.Migration.ID, s.Migration.Name, time.Now().Unix())
+		if err != nil {
+			return done, err
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// Down undoes the last migration applied and returns it.  If none have been
+// applied it returns ErrNothingToUndo.
+func (m *ConcreteMigrator) Down() (Migration, error) {
+	version, err := m.Version()
+	if err != nil {
+		return Migration{}, err
+	}
+	if version == 0 {
+		return Migration{}, ErrNothingToUndo
+	}
+	for _, migration := range m.migrations {
+		if migration.ID == version {
+			slog.Info("undoing migration", "id", migration.ID, "name", migration.Name)
+			err = m.run(migration, migration.Down[m.dialect], "delete from schema_migrations where version = ?",
+				migration.ID)
+			return migration, err
+		}
+	}
+	em := fmt.Sprintf("cannot undo migration %d - it's not one of the migrations known to this version of films",
+		version)
+	slog.Error(em)
+	return Migration{}, errors.New(em)
+}
+
+// Check returns a BehindError if any of the migrations are pending.
+func (m *ConcreteMigrator) Check() error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	behind := BehindError{}
+	for _, s := range status {
+		if s.Applied() {
+			behind.Version = s.Migration.ID
+		} else {
+			behind.Pending++
+		}
+		behind.Latest = s.Migration.ID
+	}
+	if behind.Pending > 0 {
+		return behind
+	}
+	return nil
+}
+
+// Close closes the database handle.
+func (m *ConcreteMigrator) Close() {
+	m.db.Close()
+}
+
+// applied returns the time at which each applied migration was applied, by ID.
+// If there is no schema_migrations table, no migrations have been applied.
+func (m *ConcreteMigrator) applied() (map[int]time.Time, error) {
+	applied := make(map[int]time.Time)
+
+	var tables int
+	err := m.db.QueryRow(tableExists[m.dialect]).Scan(&tables)
+	if err != nil {
+		em := fmt.Sprintf("cannot look for the schema_migrations table - %s", err.Error())
+		slog.Error(em)
+		return nil, errors.New(em)
+	}
+	if tables == 0 {
+		return applied, nil
+	}
+
+	rows, err := m.db.Query("select version, applied_at from schema_migrations")
+	if err != nil {
+		em := fmt.Sprintf("cannot read the schema_migrations table - %s", err.Error())
+		slog.Error(em)
+		return nil, errors.New(em)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var appliedAt int64
+		err = rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, err
+		}
+		applied[version] = time.Unix(appliedAt, 0)
+	}
+	return applied, rows.Err()
+}
+
+// present returns true if the migration's change has already been made.
+func (m *ConcreteMigrator) present(migration Migration) bool {
+	if migration.Present == "" {
+		return false
+	}
+	rows, err := m.db.Query(migration.Present)
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+// run runs the statements and then the bookkeeping statement, which records the
+// migration or removes the record, in one transaction.
+func (m *ConcreteMigrator) run(migration Migration, statements []string, bookkeeping string, args ...interface{}) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		_, err = tx.Exec(statement)
+		if err != nil {
+			tx.Rollback()
+			em := fmt.Sprintf("migration %d %s failed - %s", migration.ID, migration.Name, err.Error())
+			slog.Error(em)
+			return errors.New(em)
+		}
+	}
+	_, err = tx.Exec(bookkeeping, args...)
+	if err != nil {
+		tx.Rollback()
+		em := fmt.Sprintf("cannot record migration %d %s - %s", migration.ID, migration.Name, err.Error())
+		slog.Error(em)
+		return errors.New(em)
+	}
+	return tx.Commit()
+}
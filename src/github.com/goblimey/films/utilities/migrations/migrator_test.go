@@ -0,0 +1,250 @@
+package migrations
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	// This import registers the sqlite3 driver.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openDB opens an SQLite database in a new temporary directory.  The caller
+// should close the database and remove the directory.
+func openDB(t *testing.T) (*sql.DB, string) {
+	dir, err := ioutil.TempDir("", "films")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "films.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf(err.Error())
+	}
+	db.SetMaxOpenConns(1)
+	return db, dir
+}
+
+// TestUnitUpAndDown checks that Up applies all of the migrations to an empty
+// database and Down undoes them one at a time.
+func TestUnitUpAndDown(t *testing.T) {
+	db, dir := openDB(t)
+	defer os.RemoveAll(dir)
+	migrator, err := MakeMigrator(db, DialectSqlite)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer migrator.Close()
+
+	err = migrator.Check()
+	behind, ok := err.(BehindError)
+	if !ok {
+		t.Fatalf("Expected a BehindError, got %v", err)
+	}
+	if behind.Version != 0 || behind.Latest != len(All) || behind.Pending != len(All) {
+		t.Errorf("Expected version 0 with %d pending, got %+v", len(All), behind)
+	}
+
+	done, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("up failed - %s", err.Error())
+	}
+	if len(done) != len(All) {
+		t.Errorf("Expected %d migrations to be applied, got %d", len(All), len(done))
+	}
+	err = migrator.Check()
+	if err != nil {
+		t.Errorf("Expected the schema to be up to date, got %s", err.Error())
+	}
+	version, _ := migrator.Version()
+	if version != All[len(All)-1].ID {
+		t.Errorf("Expected version %d, got %d", All[len(All)-1].ID, version)
+	}
+	status, _ := migrator.Status()
+	for _, s := range status {
+		if !s.Applied() {
+			t.Errorf("Expected migration %d to be applied", s.Migration.ID)
+		}
+	}
+
+	// The tables are usable.
+	_, err = db.Exec("insert into people (forename, surname) values ('Orson', 'Welles')")
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+	var personVersion, deletedAt int64
+	err = db.QueryRow("select version, deleted_at from people").Scan(&personVersion, &deletedAt)
+	if err != nil {
+		t.Fatalf("select failed - %s", err.Error())
+	}
+	if personVersion != 1 || deletedAt != 0 {
+		t.Errorf("Expected version 1 and deleted_at 0, got %d and %d", personVersion, deletedAt)
+	}
+
+	// A second Up does nothing.
+	done, err = migrator.Up()
+	if err != nil || len(done) != 0 {
+		t.Errorf("Expected nothing to be applied, got %d migrations and error %v", len(done), err)
+	}
+
+	undone, err := migrator.Down()
+	if err != nil {
+		t.Fatalf("down failed - %s", err.Error())
+	}
+	if undone.ID != All[len(All)-1].ID {
+		t.Errorf("Expected migration %d to be undone, got %d", All[len(All)-1].ID, undone.ID)
+	}
+	err = migrator.Check()
+	behind, ok = err.(BehindError)
+	if !ok || behind.Pending != 1 {
+		t.Errorf("Expected one migration to be pending, got %v", err)
+	}
+
+	for i := len(All) - 1; i > 0; i-- {
+		_, err = migrator.Down()
+		if err != nil {
+			t.Fatalf("down failed - %s", err.Error())
+		}
+	}
+	_, err = migrator.Down()
+	if err != ErrNothingToUndo {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+	var tables int
+	db.QueryRow("select count(*) from sqlite_master where type = 'table' and name <> 'schema_migrations' " +
+		"and name <> 'sqlite_sequence'").Scan(&tables)
+	if tables != 0 {
+		t.Errorf("Expected all of the tables to be dropped, %d are left", tables)
+	}
+}
+
+// TestUnitExistingUsersBecomeViewers checks that the migration that adds roles
+// leaves the users who already exist with the least privilege.  An administrator
+// has to be promoted explicitly.
+func TestUnitExistingUsersBecomeViewers(t *testing.T) {
+	db, dir := openDB(t)
+	defer os.RemoveAll(dir)
+	var before []Migration
+	for _, migration := range All {
+		if migration.Name == "add role to users" {
+			break
+		}
+		before = append(before, migration)
+	}
+	migrator, err := makeMigrator(db, DialectSqlite, before)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = migrator.Up()
+	if err != nil {
+		t.Fatalf("up failed - %s", err.Error())
+	}
+	_, err = db.Exec("insert into users (username, password_hash) values ('ada', 'x')")
+	if err != nil {
+		t.Fatalf("insert failed - %s", err.Error())
+	}
+
+	migrator, err = MakeMigrator(db, DialectSqlite)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer migrator.Close()
+	_, err = migrator.Up()
+	if err != nil {
+		t.Fatalf("up failed - %s", err.Error())
+	}
+	var role string
+	err = db.QueryRow("select role from users where username = 'ada'").Scan(&role)
+	if err != nil {
+		t.Fatalf("select failed - %s", err.Error())
+	}
+	if role != "viewer" {
+		t.Errorf("Expected the existing user to be a viewer, got %s", role)
+	}
+}
+
+// TestUnitUpAdoptsExistingTables checks that Up records the changes that were
+// made to a database before there were migrations, and applies the rest.
+func TestUnitUpAdoptsExistingTables(t *testing.T) {
+	db, dir := openDB(t)
+	defer os.RemoveAll(dir)
+	migrator, err := MakeMigrator(db, DialectSqlite)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer migrator.Close()
+
+	// A people table with a version column, as the server used to create it.
+	_, err = db.Exec("create table people (id integer not null primary key autoincrement, " +
+		"forename varchar(255), surname varchar(255), version bigint)")
+	if err == nil {
+		_, err = db.Exec("insert into people (forename, surname, version) values ('Joseph', 'Cotten', 3)")
+	}
+	if err != nil {
+		t.Fatalf("cannot set up the old table - %s", err.Error())
+	}
+
+	done, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("up failed - %s", err.Error())
+	}
+	if len(done) != len(All) {
+		t.Errorf("Expected %d migrations to be recorded, got %d", len(All), len(done))
+	}
+
+	var version, deletedAt int64
+	err = db.QueryRow("select version, deleted_at from people").Scan(&version, &deletedAt)
+	if err != nil {
+		t.Fatalf("select failed - %s", err.Error())
+	}
+	if version != 3 || deletedAt != 0 {
+		t.Errorf("Expected version 3 and deleted_at 0, got %d and %d", version, deletedAt)
+	}
+	_, err = db.Exec("select count(*) from films")
+	if err != nil {
+		t.Errorf("Expected the films table to be created - %s", err.Error())
+	}
+}
+
+// TestUnitFailedMigrationIsNotRecorded checks that when a migration fails, Up
+// stops, rolls back the failed migration and leaves it pending.
+func TestUnitFailedMigrationIsNotRecorded(t *testing.T) {
+	db, dir := openDB(t)
+	defer os.RemoveAll(dir)
+	list := []Migration{
+		{ID: 1, Name: "good", Up: map[string][]string{DialectSqlite: {"create table good (id integer)"}}},
+		{ID: 2, Name: "bad", Up: map[string][]string{DialectSqlite: {"create table bad (id integer)", "junk"}}},
+	}
+	migrator, err := makeMigrator(db, DialectSqlite, list)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer migrator.Close()
+
+	done, err := migrator.Up()
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+	if len(done) != 1 || done[0].ID != 1 {
+		t.Errorf("Expected just migration 1 to be applied, got %v", done)
+	}
+	version, _ := migrator.Version()
+	if version != 1 {
+		t.Errorf("Expected version 1, got %d", version)
+	}
+	_, err = db.Exec("select count(*) from bad")
+	if err == nil {
+		t.Errorf("Expected the bad migration to be rolled back")
+	}
+}
+
+// TestUnitMakeMigratorWithUnknownDialect checks that MakeMigrator rejects a
+// dialect that has no migrations.
+func TestUnitMakeMigratorWithUnknownDialect(t *testing.T) {
+	_, err := MakeMigrator(nil, "postgres")
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+}
@@ -0,0 +1,98 @@
+package utilities
+
+import (
+	"fmt"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	forbiddenForms "github.com/goblimey/films/forms/forbidden"
+	retroTemplate "github.com/goblimey/films/retrofit/template"
+	"github.com/goblimey/films/utilities/auth"
+	"github.com/goblimey/films/utilities/logging"
+)
+
+// Templates finds the HTML templates by name, giving nil if there is no such
+// template.  The services are one - see services.Services.
+type Templates interface {
+	Template(name string) retroTemplate.Template
+}
+
+// TemplateMap is a map of HTML templates keyed by name, which can be used as
+// Templates.
+type TemplateMap map[string]retroTemplate.Template
+
+// Template returns the template with the given name, or nil.
+func (tm TemplateMap) Template(name string) retroTemplate.Template {
+	return tm[name]
+}
+
+// Allowed returns true if the viewer can do what they asked, according to
+// permitted, which is one of the Can methods of auth.Viewer.  If not, it displays
+// the forbidden page.
+func Allowed(req *restful.Request, resp *restful.Response, templates Templates,
+	permitted func(auth.Viewer) bool, what string) bool {
+
+	logger := logging.FromRequest(req.Request)
+	viewer := auth.ViewerFrom(req.Request)
+	if permitted(viewer) {
+		return true
+	}
+	em := viewer.Refusal(what)
+	logger.Error(em)
+	Forbidden(req, resp, templates, em)
+	return false
+}
+
+// Forbidden displays the forbidden page with status 403 and the given error
+// message.
+func Forbidden(req *restful.Request, resp *restful.Response, templates Templates,
+	errormessage string) {
+
+	logger := logging.FromRequest(req.Request)
+	var form forbiddenForms.ConcreteForbiddenForm
+	form.SetErrorMessage(errormessage)
+	form.SetViewer(auth.ViewerFrom(req.Request))
+	resp.WriteHeader(http.StatusForbidden)
+	page := templates.Template("Forbidden")
+	if page == nil {
+		Dead(resp)
+		return
+	}
+	err := page.Execute(resp.ResponseWriter, &form)
+	if err != nil {
+		logger.Error("error displaying the forbidden page", "error", err)
+		Dead(resp)
+	}
+}
+
+// Display displays the page with the given template name and data, setting the
+// viewer first.  If the page can't be displayed, it falls back to the static
+// error page.
+func Display(req *restful.Request, resp *restful.Response, templates Templates,
+	name string, form interface{}, setViewer func(auth.Viewer)) {
+
+	logger := logging.FromRequest(req.Request)
+	page := templates.Template(name)
+	if page == nil {
+		Dead(resp)
+		return
+	}
+	setViewer(auth.ViewerFrom(req.Request))
+	err := page.Execute(resp.ResponseWriter, form)
+	if err != nil {
+		// Fall back to the static error page.
+		logger.Error(err.Error())
+		page = templates.Template("Error")
+		if page == nil {
+			Dead(resp)
+			return
+		}
+		err = page.Execute(resp.ResponseWriter, form)
+		if err != nil {
+			// Can't display the static error page either.  Bale out.
+			em := fmt.Sprintf("fatal error - failed to display error page for error %s\n", err.Error())
+			logger.Error(em)
+			panic(em)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package sanitize turns text written by users, such as film reviews, into HTML
+// that is safe to put into a page.  The text is treated as plain text - any
+// markup in it is escaped, so it's displayed rather than obeyed.
+package sanitize
+
+import (
+	"html"
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+// Paragraphs returns the given text as HTML.  Each run of lines separated by a
+// blank line becomes a paragraph, and a single line break within a paragraph
+// becomes a <br>.  Everything else is escaped and control characters (apart
+// from the line breaks) are removed.
+func Paragraphs(text string) template.HTML {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+
+	var result strings.Builder
+	lines := make([]string, 0)
+	// endParagraph writes out the lines collected so far as a paragraph.
+	endParagraph := func() {
+		if len(lines) > 0 {
+			result.WriteString("<p>")
+			result.WriteString(strings.Join(lines, "<br>"))
+			result.WriteString("</p>")
+			lines = lines[:0]
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			endParagraph()
+			continue
+		}
+		lines = append(lines, html.EscapeString(line))
+	}
+	endParagraph()
+	return template.HTML(result.String())
+}
@@ -0,0 +1,34 @@
+package sanitize
+
+import (
+	"testing"
+)
+
+// Plain text is split into paragraphs and lines.
+func TestUnitParagraphs(t *testing.T) {
+	text := "First line\r\nsecond line\r\n \t\r\n\r\n  Second paragraph  \n\n"
+	expected := "<p>First line<br>second line</p><p>Second paragraph</p>"
+	got := string(Paragraphs(text))
+	if got != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, got)
+	}
+}
+
+// Markup is escaped and control characters are removed.
+func TestUnitParagraphsEscapesMarkup(t *testing.T) {
+	text := "<script>alert('x')</script>\x00 & <b onclick=\"y\">bold</b>\x1b"
+	expected := "<p>&lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt; &amp; " +
+		"&lt;b onclick=&#34;y&#34;&gt;bold&lt;/b&gt;</p>"
+	got := string(Paragraphs(text))
+	if got != expected {
+		t.Errorf("Expected \"%s\", got \"%s\"", expected, got)
+	}
+}
+
+// Empty text produces no HTML.
+func TestUnitParagraphsEmpty(t *testing.T) {
+	got := string(Paragraphs(" \n\n \r\n"))
+	if got != "" {
+		t.Errorf("Expected an empty string, got \"%s\"", got)
+	}
+}
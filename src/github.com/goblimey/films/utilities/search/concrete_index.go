@@ -0,0 +1,255 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	filmModel "github.com/goblimey/films/models/film"
+	personModel "github.com/goblimey/films/models/person"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// The scores for a query word matching a word in the index.  A prefix match
+// scores more the more of the word it covers, but always less than an exact
+// match.  A misspelling scores less for each mistake.
+const (
+	exactScore      = 3.0
+	prefixScore     = 2.0
+	typoScore       = 1.5
+	typoPrefixScore = 0.75
+)
+
+// key identifies an entry in the index.
+type key struct {
+	kind string
+	id   uint64
+}
+
+// entry is a person or film in the index.
+type entry struct {
+	title string
+	words []string
+}
+
+// ConcreteIndex satisfies the Index interface.  It's an inverted index - a map
+// from each word to the entries that contain it - plus a sorted list of the
+// words, to find the words with a given prefix.
+type ConcreteIndex struct {
+	mutex    sync.RWMutex
+	entries  map[key]entry
+	postings map[string]map[key]bool
+	words    []string
+}
+
+// MakeIndex is a factory function that creates an empty ConcreteIndex and
+// returns it as an Index.
+func MakeIndex() Index {
+	return &ConcreteIndex{
+		entries:  make(map[key]entry),
+		postings: make(map[string]map[key]bool),
+	}
+}
+
+// Build creates an index holding all of the valid people and films in the
+// database.  If the database lookup fails, the error is returned instead.
+func Build(session dbsession.DBSession) (Index, error) {
+	index := MakeIndex()
+	people, err := session.FindAllPeople()
+	if err != nil {
+		return nil, err
+	}
+	for _, person := range people {
+		index.AddPerson(person)
+	}
+	films, err := session.FindAllFilms()
+	if err != nil {
+		return nil, err
+	}
+	for _, film := range films {
+		index.AddFilm(film)
+	}
+	return index, nil
+}
+
+// AddPerson adds the person to the index, replacing any earlier entry for them.
+// An invalid person is removed from the index instead.
+func (ci *ConcreteIndex) AddPerson(person personModel.Person) {
+	if strings.TrimSpace(person.Forename()) == "" || strings.TrimSpace(person.Surname()) == "" {
+		ci.Remove(KindPerson, person.ID())
+		return
+	}
+	title := personTitle(person)
+	ci.add(key{KindPerson, person.ID()}, entry{title, Words(title)})
+}
+
+// AddFilm adds the film to the index, replacing any earlier entry for it.  A film
+// with no title is removed from the index instead.
+func (ci *ConcreteIndex) AddFilm(film filmModel.Film) {
+	if strings.TrimSpace(film.Title()) == "" {
+		ci.Remove(KindFilm, film.ID())
+		return
+	}
+	ci.add(key{KindFilm, film.ID()}, entry{filmTitle(film), Words(film.Title())})
+}
+
+// Remove removes the entry of the given kind with the given ID, if there is one.
+func (ci *ConcreteIndex) Remove(kind string, id uint64) {
+	ci.mutex.Lock()
+	defer ci.mutex.Unlock()
+	ci.remove(key{kind, id})
+}
+
+// Len returns the number of entries in the index.
+func (ci *ConcreteIndex) Len() int {
+	ci.mutex.RLock()
+	defer ci.mutex.RUnlock()
+	return len(ci.entries)
+}
+
+// Search returns the entries that match every word in the query, best match
+// first, up to the given limit.  Entries with the same score are in order of
+// their titles.
+func (ci *ConcreteIndex) Search(query string, limit int) []Result {
+	queryWords := Words(query)
+	if len(queryWords) == 0 {
+		return []Result{}
+	}
+
+	ci.mutex.RLock()
+	defer ci.mutex.RUnlock()
+
+	// Each query word must match, so start with the entries that match the
+	// first word and drop any that don't match the others.
+	var scores map[key]float64
+	for _, queryWord := range queryWords {
+		matches := ci.match(queryWord)
+		if scores == nil {
+			scores = matches
+			continue
+		}
+		for k, score := range scores {
+			wordScore, ok := matches[k]
+			if !ok {
+				delete(scores, k)
+				continue
+			}
+			scores[k] = score + wordScore
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for k, score := range scores {
+		results = append(results, Result{k.kind, k.id, ci.entries[k].title, score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		titleA, titleB := strings.ToLower(a.Title), strings.ToLower(b.Title)
+		if titleA != titleB {
+			return titleA < titleB
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.ID < b.ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// match returns the entries that contain a word matching the query word, with
+// the score of the best matching word in each.  The caller must hold the lock.
+func (ci *ConcreteIndex) match(queryWord string) map[key]float64 {
+	scores := make(map[key]float64)
+	record := func(word string, score float64) {
+		for k := range ci.postings[word] {
+			if score > scores[k] {
+				scores[k] = score
+			}
+		}
+	}
+
+	// The words with the query word as a prefix are together in the sorted list.
+	for i := sort.SearchStrings(ci.words, queryWord); i < len(ci.words); i++ {
+		word := ci.words[i]
+		if !strings.HasPrefix(word, queryWord) {
+			break
+		}
+		if word == queryWord {
+			record(word, exactScore)
+		} else {
+			record(word, prefixScore+float64(len(queryWord))/float64(len(word)))
+		}
+	}
+
+	// Look for misspellings of the whole word or of a prefix.
+	query := []rune(queryWord)
+	max := maxTypos(query)
+	if max == 0 {
+		return scores
+	}
+	for _, word := range ci.words {
+		if strings.HasPrefix(word, queryWord) {
+			continue // already matched
+		}
+		runes := []rune(word)
+		d := distance(query, runes, max)
+		if d <= max {
+			record(word, typoScore-0.5*float64(d))
+			continue
+		}
+		if len(runes) > len(query) {
+			d = distance(query, runes[:len(query)], max)
+			if d <= max {
+				record(word, typoPrefixScore-0.25*float64(d))
+			}
+		}
+	}
+	return scores
+}
+
+// add replaces the entry with the given key.
+func (ci *ConcreteIndex) add(k key, e entry) {
+	ci.mutex.Lock()
+	defer ci.mutex.Unlock()
+	ci.remove(k)
+	ci.entries[k] = e
+	for _, word := range e.words {
+		keys, ok := ci.postings[word]
+		if !ok {
+			keys = make(map[key]bool)
+			ci.postings[word] = keys
+			i := sort.SearchStrings(ci.words, word)
+			ci.words = append(ci.words, "")
+			copy(ci.words[i+1:], ci.words[i:])
+			ci.words[i] = word
+		}
+		keys[k] = true
+	}
+}
+
+// remove removes the entry with the given key and any words that no other entry
+// contains.  The caller must hold the lock.
+func (ci *ConcreteIndex) remove(k key) {
+	e, ok := ci.entries[k]
+	if !ok {
+		return
+	}
+	delete(ci.entries, k)
+	for _, word := range e.words {
+		keys := ci.postings[word]
+		delete(keys, k)
+		if len(keys) == 0 {
+			delete(ci.postings, word)
+			i := sort.SearchStrings(ci.words, word)
+			if i < len(ci.words) && ci.words[i] == word {
+				ci.words = append(ci.words[:i], ci.words[i+1:]...)
+			}
+		}
+	}
+}
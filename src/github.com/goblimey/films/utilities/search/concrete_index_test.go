@@ -0,0 +1,168 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	gorpFilmModel "github.com/goblimey/films/models/film/gorpmysql"
+	gorpPersonModel "github.com/goblimey/films/models/person/gorpmysql"
+	"github.com/goblimey/films/utilities/dbsession"
+)
+
+// makeTestIndex creates an index holding a few people and a film.
+func makeTestIndex() Index {
+	index := MakeIndex()
+	index.AddPerson(gorpPersonModel.MakeInitialisedPerson(1, "Orson", "Welles"))
+	index.AddPerson(gorpPersonModel.MakeInitialisedPerson(2, "Luis", "Buñuel"))
+	index.AddPerson(gorpPersonModel.MakeInitialisedPerson(3, "Joseph", "Cotten"))
+	index.AddPerson(gorpPersonModel.MakeInitialisedPerson(4, "Wells", "Fargo"))
+	film := gorpFilmModel.MakeInitialisedFilm(1, "Citizen Kane", 1941, 119, "")
+	index.AddFilm(film)
+	return index
+}
+
+// titles gets the titles of the results.
+func titles(results []Result) []string {
+	list := make([]string, 0, len(results))
+	for _, result := range results {
+		list = append(list, result.Title)
+	}
+	return list
+}
+
+// TestUnitWords checks that text is split into words folded to lower case with no
+// accents.
+func TestUnitWords(t *testing.T) {
+	var tests = []struct {
+		text     string
+		expected []string
+	}{
+		{"Luis Buñuel", []string{"luis", "bunuel"}},
+		{"  ÉMILE-Zola ", []string{"emile", "zola"}},
+		{"Maureen O'Hara", []string{"maureen", "ohara"}},
+		{"Gert Fröbe, Straße", []string{"gert", "frobe", "strasse"}},
+		{"", []string{}},
+	}
+	for _, test := range tests {
+		words := Words(test.text)
+		if len(words) == 0 && len(test.expected) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(words, test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.text, test.expected, words)
+		}
+	}
+}
+
+// TestUnitDistance checks the edit distance, including a swap of neighbouring
+// letters.
+func TestUnitDistance(t *testing.T) {
+	var tests = []struct {
+		a, b     string
+		expected int
+	}{
+		{"welles", "welles", 0},
+		{"wells", "welles", 1},
+		{"wlelse", "welles", 2},
+		{"cotten", "cottne", 1},
+		{"orson", "xyz", 3},
+	}
+	for _, test := range tests {
+		d := distance([]rune(test.a), []rune(test.b), 2)
+		if d != test.expected {
+			t.Errorf("%s %s: expected %d, got %d", test.a, test.b, test.expected, d)
+		}
+	}
+}
+
+// TestUnitSearch checks prefix, case, accent and typo matching and the ranking.
+func TestUnitSearch(t *testing.T) {
+	index := makeTestIndex()
+
+	var tests = []struct {
+		query    string
+		expected []string
+	}{
+		// An exact match beats a misspelling.
+		{"wells", []string{"Wells Fargo", "Orson Welles"}},
+		{"WEL", []string{"Wells Fargo", "Orson Welles"}},
+		{"bunuel", []string{"Luis Buñuel"}},
+		{"BUÑ", []string{"Luis Buñuel"}},
+		{"cottne", []string{"Joseph Cotten"}},
+		{"orson wel", []string{"Orson Welles"}},
+		{"kane", []string{"Citizen Kane (1941)"}},
+		// Every word must match.
+		{"orson cotten", []string{}},
+		// Short words must be spelled right.
+		{"ors", []string{"Orson Welles"}},
+		{"osr", []string{}},
+		{"", []string{}},
+	}
+	for _, test := range tests {
+		results := index.Search(test.query, 0)
+		got := titles(results)
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.query, test.expected, got)
+		}
+	}
+
+	results := index.Search("w", 1)
+	if len(results) != 1 {
+		t.Errorf("Expected the limit to give 1 result, got %d", len(results))
+	}
+	if results[0].URI() != "/people/1" && results[0].URI() != "/people/4" {
+		t.Errorf("Expected the URI of a person, got %s", results[0].URI())
+	}
+}
+
+// TestUnitIndexUpdateAndRemove checks that replacing and removing entries keeps
+// the index in step.
+func TestUnitIndexUpdateAndRemove(t *testing.T) {
+	index := makeTestIndex()
+
+	index.AddPerson(gorpPersonModel.MakeInitialisedPerson(1, "Rita", "Hayworth"))
+	if len(index.Search("orson", 0)) != 0 {
+		t.Errorf("Expected the old name to be gone")
+	}
+	if len(index.Search("hay", 0)) != 1 {
+		t.Errorf("Expected to find the new name")
+	}
+
+	// An invalid person is removed.
+	index.AddPerson(gorpPersonModel.MakeInitialisedPerson(3, "", "Cotten"))
+	if len(index.Search("cotten", 0)) != 0 {
+		t.Errorf("Expected the invalid person to be removed")
+	}
+
+	index.Remove(KindFilm, 1)
+	index.Remove(KindFilm, 42)
+	if index.Len() != 3 {
+		t.Errorf("Expected 3 entries, got %d", index.Len())
+	}
+	if len(index.Search("kane", 0)) != 0 {
+		t.Errorf("Expected the film to be removed")
+	}
+}
+
+// TestUnitBuild checks that an index built from the database holds the valid
+// people and the films.
+func TestUnitBuild(t *testing.T) {
+	session := dbsession.MakeMemoryDBSession()
+	tx, _ := session.StartTransaction()
+	tx.Insert(gorpPersonModel.MakeInitialisedPerson(0, "Agnes", "Moorehead"),
+		gorpPersonModel.MakeInitialisedPerson(0, "", "Nobody"),
+		gorpFilmModel.MakeInitialisedFilm(0, "The Magnificent Ambersons", 1942, 88, ""))
+	tx.Commit()
+
+	index, err := Build(session)
+	if err != nil {
+		t.Fatalf("Build failed - %s", err.Error())
+	}
+	if index.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", index.Len())
+	}
+	results := index.Search("magnificant", 0)
+	if len(results) != 1 || results[0].Kind != KindFilm {
+		t.Errorf("Expected to find the film, got %v", results)
+	}
+}
@@ -0,0 +1,83 @@
+// Package search provides a full-text index of the people and films.  The index
+// is held in memory, so it works the same way with every database dialect.  It's
+// built from the database when the server starts and the repositories keep it up
+// to date as records are created, updated and deleted.
+//
+// A search matches words by prefix, ignoring case and accents, so "wel" and
+// "WELLES" both find Orson Welles and "bunuel" finds Luis Buñuel.  A word of
+// four or more letters may also be misspelled - "wells" finds Welles too.  Each
+// word in the query must match, and the results are ranked with exact matches
+// first, then prefix matches, then misspellings.
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	filmModel "github.com/goblimey/films/models/film"
+	personModel "github.com/goblimey/films/models/person"
+)
+
+// KindPerson is the kind of a result that is a person.
+const KindPerson = "person"
+
+// KindFilm is the kind of a result that is a film.
+const KindFilm = "film"
+
+// Index is the interface defining a full-text index of people and films.  It's
+// safe for concurrent use.
+type Index interface {
+	// AddPerson adds the person to the index, replacing any earlier entry with
+	// the same ID.  A person with no forename or no surname is invalid and is
+	// removed from the index instead.
+	AddPerson(person personModel.Person)
+
+	// AddFilm adds the film to the index, replacing any earlier entry with the
+	// same ID.  A film with no title is removed from the index instead.
+	AddFilm(film filmModel.Film)
+
+	// Remove removes the entry of the given kind with the given ID, if there is
+	// one.
+	Remove(kind string, id uint64)
+
+	// Search returns the entries that match every word in the query, best match
+	// first, up to the given limit.  A limit of zero or less means no limit.
+	Search(query string, limit int) []Result
+
+	// Len returns the number of entries in the index.
+	Len() int
+}
+
+// Result is one entry found by a search.
+type Result struct {
+	// Kind is KindPerson or KindFilm.
+	Kind string
+	// ID is the ID of the person or film.
+	ID uint64
+	// Title is the name of the person or the title and year of the film.
+	Title string
+	// Score says how well the entry matches - the higher the better.
+	Score float64
+}
+
+// URI returns the URI of the page that displays the person or film.
+func (r Result) URI() string {
+	if r.Kind == KindFilm {
+		return fmt.Sprintf("/films/%d", r.ID)
+	}
+	return fmt.Sprintf("/people/%d", r.ID)
+}
+
+// personTitle gets the title of the result for a person.
+func personTitle(person personModel.Person) string {
+	return strings.TrimSpace(person.Forename()) + " " + strings.TrimSpace(person.Surname())
+}
+
+// filmTitle gets the title of the result for a film.
+func filmTitle(film filmModel.Film) string {
+	title := strings.TrimSpace(film.Title())
+	if film.ReleaseYear() > 0 {
+		return fmt.Sprintf("%s (%d)", title, film.ReleaseYear())
+	}
+	return title
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldLetters replaces the letters that don't decompose into a plain letter and
+// an accent.
+var foldLetters = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "ł", "l", "đ", "d", "ð", "d", "þ", "th", "ı", "i",
+)
+
+// Words splits the text into words, folded to lower case without accents, so
+// "Luis Buñuel" gives "luis" and "bunuel".  Anything that is not a letter or a
+// digit separates words, except for apostrophes, which are dropped, so "O'Hara"
+// gives "ohara".
+func Words(text string) []string {
+	removeAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(removeAccents, strings.ToLower(text))
+	if err != nil {
+		folded = strings.ToLower(text)
+	}
+	folded = foldLetters.Replace(folded)
+	folded = strings.NewReplacer("'", "", "’", "").Replace(folded)
+	return strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// distance returns the number of single letter insertions, deletions,
+// substitutions and swaps of neighbouring letters needed to turn a into b (the
+// optimal string alignment distance).  It gives up and returns max+1 once the
+// distance must be more than max.
+func distance(a, b []rune, max int) int {
+	if abs(len(a)-len(b)) > max {
+		return max + 1
+	}
+	// Three rows of the table - the current row and the two before.
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prev2[j-2]+1 < d {
+				d = prev2[j-2] + 1
+			}
+			cur[j] = d
+			if d < rowMin {
+				rowMin = d
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// maxTypos returns the number of mistakes allowed in a query word - none in a
+// short word, one in a word of four to seven letters and two in a longer word.
+func maxTypos(word []rune) int {
+	switch {
+	case len(word) < 4:
+		return 0
+	case len(word) < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -2,7 +2,10 @@
 
 # Run tests.  This script assumes that the current directory is the one in which
 # it lives.  With no argument, run all tests.  With argument "unit" run just the
-# unit tests.  With argument "int" run just the integration tests.
+# unit tests.  With argument "int" run just the integration tests.  The integration
+# tests use the database given by FILMS_TEST_DIALECT (mysql, sqlite or memory) and
+# FILMS_TEST_DSN.  The default is MySQL, with the DSN taken from FILMS_DSN.  For
+# sqlite with no FILMS_TEST_DSN, a temporary file is used and removed afterwards.
 
 testcmd='go test -test.v'
 if test ! -z $1
@@ -21,6 +24,20 @@ fi
 
 startDir=`pwd`
 
+FILMS_TEST_DIALECT=${FILMS_TEST_DIALECT:-mysql}
+export FILMS_TEST_DIALECT
+if test "$FILMS_TEST_DIALECT" = "mysql" -a -z "$FILMS_TEST_DSN"
+then
+	FILMS_TEST_DSN="$FILMS_DSN"
+fi
+if test "$FILMS_TEST_DIALECT" = "sqlite" -a -z "$FILMS_TEST_DSN"
+then
+	sqliteDir=`mktemp -d`
+	trap 'rm -rf $sqliteDir' EXIT
+	FILMS_TEST_DSN="$sqliteDir/films_test.db"
+fi
+export FILMS_TEST_DSN
+
 . ./setenv.sh
 
 
@@ -32,6 +49,8 @@ cd ${startDir}/src/$dir
 mockgen --package gomock net/http ResponseWriter >mock_response_writer.go
 mockgen --package gomock github.com/goblimey/films/retrofit/template Template >mock_template.go
 mockgen --package gomock github.com/goblimey/films/repositories/people Repository >mock_people_repository.go
+mockgen --package gomock -mock_names Repository=MockFilmRepository github.com/goblimey/films/repositories/films Repository >mock_film_repository.go
+mockgen --package gomock -mock_names Repository=MockCreditRepository github.com/goblimey/films/repositories/credits Repository >mock_credit_repository.go
 
 mkdir -p ${startDir}/src/github.com/goblimey/films/mocks/pegomock
 dir='github.com/goblimey/films/mocks/pegomock'
@@ -44,6 +63,14 @@ pegomock generate --package pegomock --output=mock_response_writer.go net/http R
 
 go build github.com/goblimey/films
 
+# Bring the schema of the test database up to date.
+
+if test "$FILMS_TEST_DIALECT" != "memory"
+then
+	cd ${startDir}
+	go run github.com/goblimey/films -dialect "$FILMS_TEST_DIALECT" -dsn "$FILMS_TEST_DSN" migrate up || exit 1
+fi
+
 # Test
 
 dir='github.com/goblimey/films/models/person'
@@ -56,17 +83,302 @@ echo ${dir}
 cd ${startDir}/src/$dir
 ${testcmd}
 
+dir='github.com/goblimey/films/models/film'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/film/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/credit'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/credit/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/audit'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/audit/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/user'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/user/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/genre'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/genre/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/tag'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/tag/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/company'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/company/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/review'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/review/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/watchlist'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/diary'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/diary/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/award'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/models/award/gorpmysql'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
 dir='github.com/goblimey/films/forms/people'
 echo ${dir}
 cd ${startDir}/src/$dir
 ${testcmd}
 
+dir='github.com/goblimey/films/forms/films'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/forms/credits'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/forms/login'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/forms/tags'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/forms/companies'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/forms/reviews'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/forms/diary'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/forms/awards'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/utilities/config'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/utilities/migrations'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/utilities/dbsession'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/utilities/bulk'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/utilities/search'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/utilities/auth'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/utilities/csrf'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/utilities/logging'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/utilities/sanitize'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
 dir='github.com/goblimey/films/repositories/people'
 echo ${dir}
 cd ${startDir}/src/$dir
 ${testcmd}
 
+dir='github.com/goblimey/films/repositories/films'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/repositories/credits'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/repositories/users'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/repositories/taxonomy'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/repositories/companies'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/repositories/reviews'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/repositories/watchlist'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/repositories/diary'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/repositories/awards'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
 dir='github.com/goblimey/films/controllers/people'
 echo ${dir}
 cd ${startDir}/src/$dir
 ${testcmd}
+
+dir='github.com/goblimey/films/controllers/films'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/controllers/api/people'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/controllers/search'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/controllers/api/search'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/controllers/login'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/controllers/tags'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/controllers/companies'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/controllers/watchlist'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/controllers/diary'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}
+
+dir='github.com/goblimey/films/controllers/awards'
+echo ${dir}
+cd ${startDir}/src/$dir
+${testcmd}